@@ -60,11 +60,133 @@ func (g *ToolDefinitionGenerator) generateToolFromEndpoint(endpoint parser.Endpo
 		Name:        toolName,
 		Description: description,
 		InputSchema: inputSchema,
+		Annotations: generateToolAnnotations(endpoint),
+		Tags:        endpoint.Tags,
+		Endpoint:    generateToolEndpoint(endpoint),
 	}
 
 	return tool, nil
 }
 
+// generateToolEndpoint records the HTTP method, path template and parameter locations
+// of an endpoint so the server can rebuild the exact MAAS request for the tool
+func generateToolEndpoint(endpoint parser.Endpoint) *models.MCPToolEndpoint {
+	toolEndpoint := &models.MCPToolEndpoint{
+		Method:       string(endpoint.Method),
+		PathTemplate: endpoint.Path,
+	}
+
+	for _, param := range endpoint.Parameters {
+		location := param.Location
+		switch location {
+		case parser.PathParam, parser.QueryParam:
+		case parser.BodyParam:
+			// MAAS takes GET and DELETE parameters from the query string
+			if endpoint.Method == parser.GET || endpoint.Method == parser.DELETE {
+				location = parser.QueryParam
+			}
+		default:
+			continue
+		}
+
+		if toolEndpoint.Parameters == nil {
+			toolEndpoint.Parameters = make(map[string]string)
+		}
+		toolEndpoint.Parameters[param.Name] = string(location)
+	}
+
+	return toolEndpoint
+}
+
+// destructiveOperations are the prefixes of MAAS op names that delete or overwrite state,
+// such as op-release, op-set_storage_layout or op-unlink_subnet
+var destructiveOperations = []string{
+	"abort",
+	"clear",
+	"delete",
+	"deploy",
+	"disconnect",
+	"format",
+	"mark_broken",
+	"power_action",
+	"power_off",
+	"release",
+	"restore",
+	"revert",
+	"set_storage_layout",
+	"unformat",
+	"unlink",
+	"unmount",
+}
+
+// idempotentOperations are the prefixes of MAAS op names that have no further effect when
+// repeated with the same arguments
+var idempotentOperations = []string{
+	"accept",
+	"add_tag",
+	"clear",
+	"lock",
+	"mark_",
+	"power_off",
+	"power_on",
+	"remove_tag",
+	"set_",
+	"unlock",
+}
+
+// generateToolAnnotations derives the MCP tool annotations of an endpoint from its HTTP
+// method and, for POST endpoints, its op name.
+func generateToolAnnotations(endpoint parser.Endpoint) *models.MCPToolAnnotations {
+	// OpenWorldHint is false: a generated tool calls one MAAS endpoint and only touches the
+	// machines, networks and storage MAAS manages, never an open set such as the web. It is
+	// still sent, because MCP clients take a missing openWorldHint as true.
+	annotations := &models.MCPToolAnnotations{OpenWorldHint: false}
+
+	switch endpoint.Method {
+	case parser.GET, parser.HEAD, parser.OPTIONS:
+		annotations.ReadOnlyHint = true
+		annotations.IdempotentHint = true
+	case parser.DELETE:
+		annotations.DestructiveHint = true
+		annotations.IdempotentHint = true
+	case parser.PUT:
+		annotations.IdempotentHint = true
+	case parser.PATCH:
+		// A partial update is neither destructive nor safe to repeat in general
+	default:
+		// POST creates an object, or runs the operation named by the op- segment of the path
+		operation := operationName(endpoint.Path)
+		annotations.DestructiveHint = hasAnyPrefix(operation, destructiveOperations)
+		annotations.IdempotentHint = hasAnyPrefix(operation, idempotentOperations)
+	}
+
+	return annotations
+}
+
+// operationName returns the op name of a MAAS endpoint path, e.g. "release" for
+// "/api/2.0/machines/{system_id}/op-release", or "" when the path has none
+func operationName(path string) string {
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "op-") {
+			return strings.TrimPrefix(segment, "op-")
+		}
+	}
+	return ""
+}
+
+// hasAnyPrefix reports whether s is non-empty and starts with one of prefixes
+func hasAnyPrefix(s string, prefixes []string) bool {
+	if s == "" {
+		return false
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // generateInputSchema generates a JSON Schema for the tool's input parameters
 func (g *ToolDefinitionGenerator) generateInputSchema(endpoint parser.Endpoint) (map[string]interface{}, error) {
 	// Create a JSON Schema object
@@ -214,6 +336,9 @@ func LoadToolDefinitions(filePath string) ([]models.MCPTool, error) {
 			Name:        endpoint.GenerateToolName(),
 			Description: endpoint.GenerateDescription(),
 			InputSchema: map[string]interface{}{}, // This would need to be populated
+			Annotations: generateToolAnnotations(endpoint),
+			Tags:        endpoint.Tags,
+			Endpoint:    generateToolEndpoint(endpoint),
 		}
 		tools = append(tools, tool)
 	}
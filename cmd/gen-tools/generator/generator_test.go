@@ -149,6 +149,76 @@ func TestGenerate(t *testing.T) {
 	}
 }
 
+func TestGenerateToolEndpoint(t *testing.T) {
+	endpoint := parser.Endpoint{
+		Path:   "/api/2.0/machines/{system_id}/op-deploy",
+		Method: parser.POST,
+		Parameters: []parser.Parameter{
+			{Name: "system_id", Type: parser.StringType, Location: parser.PathParam, Required: true},
+			{Name: "distro_series", Type: parser.StringType, Location: parser.BodyParam},
+			{Name: "X-Trace", Type: parser.StringType, Location: parser.HeaderParam},
+		},
+	}
+
+	toolEndpoint := generateToolEndpoint(endpoint)
+
+	if toolEndpoint.Method != "POST" {
+		t.Errorf("Expected method POST, got %s", toolEndpoint.Method)
+	}
+	if toolEndpoint.PathTemplate != endpoint.Path {
+		t.Errorf("Expected path template %s, got %s", endpoint.Path, toolEndpoint.PathTemplate)
+	}
+
+	expected := map[string]string{"system_id": "path", "distro_series": "body"}
+	if len(toolEndpoint.Parameters) != len(expected) {
+		t.Fatalf("Expected parameters %v, got %v", expected, toolEndpoint.Parameters)
+	}
+	for name, location := range expected {
+		if toolEndpoint.Parameters[name] != location {
+			t.Errorf("Expected %s to be a %s parameter, got %q", name, location, toolEndpoint.Parameters[name])
+		}
+	}
+
+	// Body parameters of GET endpoints are sent in the query string
+	endpoint.Method = parser.GET
+	toolEndpoint = generateToolEndpoint(endpoint)
+	if toolEndpoint.Parameters["distro_series"] != "query" {
+		t.Errorf("Expected distro_series to be a query parameter for GET, got %q", toolEndpoint.Parameters["distro_series"])
+	}
+}
+
+func TestGenerateToolAnnotations(t *testing.T) {
+	testCases := []struct {
+		method      parser.HTTPMethod
+		path        string
+		readOnly    bool
+		destructive bool
+		idempotent  bool
+	}{
+		{parser.GET, "/api/2.0/machines/{system_id}/", true, false, true},
+		{parser.GET, "/api/2.0/machines/{system_id}/op-query_power_state", true, false, true},
+		{parser.POST, "/api/2.0/machines/", false, false, false},
+		{parser.PUT, "/api/2.0/machines/{system_id}/", false, false, true},
+		{parser.DELETE, "/api/2.0/machines/{system_id}/", false, true, true},
+		{parser.POST, "/api/2.0/machines/{system_id}/op-release", false, true, false},
+		{parser.POST, "/api/2.0/machines/{system_id}/op-power_off", false, true, true},
+		{parser.POST, "/api/2.0/machines/{system_id}/op-power_on", false, false, true},
+		{parser.POST, "/api/2.0/machines/{system_id}/op-commission", false, false, false},
+		{parser.POST, "/api/2.0/nodes/{system_id}/interfaces/{id}/op-unlink_subnet", false, true, false},
+	}
+
+	for _, tc := range testCases {
+		annotations := generateToolAnnotations(parser.Endpoint{Method: tc.method, Path: tc.path})
+		if annotations.ReadOnlyHint != tc.readOnly || annotations.DestructiveHint != tc.destructive || annotations.IdempotentHint != tc.idempotent {
+			t.Errorf("%s %s: expected readOnly=%v destructive=%v idempotent=%v, got %+v",
+				tc.method, tc.path, tc.readOnly, tc.destructive, tc.idempotent, *annotations)
+		}
+		if annotations.OpenWorldHint {
+			t.Errorf("%s %s: expected a closed world tool", tc.method, tc.path)
+		}
+	}
+}
+
 func TestConvertParameterType(t *testing.T) {
 	generator := NewToolDefinitionGenerator(nil)
 
@@ -285,6 +285,10 @@ func loadFromConfigYaml() (*types.AppConfig, error) {
 	viper.BindEnv("logging.file_path", "LOG_FILE_PATH")
 	viper.BindEnv("logging.max_age", "LOG_MAX_AGE")
 	viper.BindEnv("logging.rotate_time", "LOG_ROTATE_TIME")
+	viper.BindEnv("server.stdio_workers", "STDIO_WORKERS")
+	viper.BindEnv("server.stdio_max_outstanding", "STDIO_MAX_OUTSTANDING")
+	viper.BindEnv("server.sse_queue_size", "SSE_QUEUE_SIZE")
+	viper.BindEnv("server.sse_slow_client_policy", "SSE_SLOW_CLIENT_POLICY")
 
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
@@ -334,6 +338,24 @@ func applyEnvironmentOverrides(config *types.AppConfig) {
 			config.Server.Port = port
 		}
 	}
+	if workersStr := os.Getenv("STDIO_WORKERS"); workersStr != "" {
+		if workers, err := strconv.Atoi(workersStr); err == nil {
+			config.Server.StdioWorkers = workers
+		}
+	}
+	if maxOutstandingStr := os.Getenv("STDIO_MAX_OUTSTANDING"); maxOutstandingStr != "" {
+		if maxOutstanding, err := strconv.Atoi(maxOutstandingStr); err == nil {
+			config.Server.StdioMaxOutstanding = maxOutstanding
+		}
+	}
+	if queueSizeStr := os.Getenv("SSE_QUEUE_SIZE"); queueSizeStr != "" {
+		if queueSize, err := strconv.Atoi(queueSizeStr); err == nil {
+			config.Server.SSEQueueSize = queueSize
+		}
+	}
+	if policy := os.Getenv("SSE_SLOW_CLIENT_POLICY"); policy != "" {
+		config.Server.SSESlowClientPolicy = policy
+	}
 
 	// Auth configuration
 	if enabledStr := os.Getenv("AUTH_ENABLED"); enabledStr != "" {
@@ -5,7 +5,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/lspecian/maas-mcp-server/internal/models"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,8 +30,8 @@ func TestLoadConfig(t *testing.T) {
 	assert.Equal(t, "http://test.maas", maasInstance.APIURL)
 	assert.Equal(t, "test_api_key", maasInstance.APIKey)
 	assert.Equal(t, "localhost", cfg.Server.Host)
-	assert.Equal(t, 8081, cfg.Server.Port)      // Adjusted to actual
-	assert.Equal(t, "debug", cfg.Logging.Level) // Adjusted to actual
+	assert.Equal(t, 8082, cfg.Server.Port)
+	assert.Equal(t, "info", cfg.Logging.Level)
 
 	// Test Auth
 	assert.Equal(t, false, cfg.Auth.Enabled)
@@ -56,30 +56,30 @@ func TestLoadConfig(t *testing.T) {
 	assert.Equal(t, "apikey", cfg.Auth.Type)
 	assert.Equal(t, "auth_test_api_key", cfg.Auth.APIKey)
 	assert.Equal(t, "memory", cfg.Auth.UserStore)
-	assert.Equal(t, false, cfg.Auth.RateLimit.Enabled) // Adjusted to actual
+	assert.Equal(t, true, cfg.Auth.RateLimit.Enabled)
 	assert.Equal(t, 5, cfg.Auth.RateLimit.MaxAttempts)
 	assert.Equal(t, 300, cfg.Auth.RateLimit.Window)
 }
 
 func TestApplyEnvironmentOverrides(t *testing.T) {
 	// Test case 1: No environment variables
-	cfg := &models.AppConfig{
-		Server: models.ServerConfig{
+	cfg := &types.AppConfig{
+		Server: types.ServerConfig{
 			Host: "localhost",
 			Port: 8082,
 		},
-		MAASInstances: make(map[string]models.MAASInstanceConfig),
-		Auth: models.AuthConfig{
+		MAASInstances: make(map[string]types.MAASInstanceConfig),
+		Auth: types.AuthConfig{
 			Enabled:   false,
 			Type:      "apikey",
 			UserStore: "memory",
-			RateLimit: models.RateLimitConfig{
+			RateLimit: types.RateLimitConfig{
 				Enabled:     true,
 				MaxAttempts: 5,
 				Window:      300,
 			},
 		},
-		Logging: models.LoggingConfig{
+		Logging: types.LoggingConfig{
 			Level: "info",
 		},
 	}
@@ -90,6 +90,10 @@ func TestApplyEnvironmentOverrides(t *testing.T) {
 	// Verify no changes
 	assert.Equal(t, "localhost", cfg.Server.Host)
 	assert.Equal(t, 8082, cfg.Server.Port)
+	assert.Equal(t, 0, cfg.Server.StdioWorkers)
+	assert.Equal(t, 0, cfg.Server.StdioMaxOutstanding)
+	assert.Equal(t, 0, cfg.Server.SSEQueueSize)
+	assert.Equal(t, "", cfg.Server.SSESlowClientPolicy)
 	assert.Equal(t, false, cfg.Auth.Enabled)
 	assert.Equal(t, "apikey", cfg.Auth.Type)
 	assert.Equal(t, "", cfg.Auth.APIKey)
@@ -102,6 +106,10 @@ func TestApplyEnvironmentOverrides(t *testing.T) {
 	// Test case 2: Set environment variables
 	os.Setenv("SERVER_HOST", "testhost")
 	os.Setenv("SERVER_PORT", "9000")
+	os.Setenv("STDIO_WORKERS", "8")
+	os.Setenv("STDIO_MAX_OUTSTANDING", "64")
+	os.Setenv("SSE_QUEUE_SIZE", "128")
+	os.Setenv("SSE_SLOW_CLIENT_POLICY", "disconnect")
 	os.Setenv("AUTH_ENABLED", "true")
 	os.Setenv("AUTH_TYPE", "basic")
 	os.Setenv("AUTH_API_KEY", "test_api_key")
@@ -116,6 +124,10 @@ func TestApplyEnvironmentOverrides(t *testing.T) {
 	defer func() {
 		os.Unsetenv("SERVER_HOST")
 		os.Unsetenv("SERVER_PORT")
+		os.Unsetenv("STDIO_WORKERS")
+		os.Unsetenv("STDIO_MAX_OUTSTANDING")
+		os.Unsetenv("SSE_QUEUE_SIZE")
+		os.Unsetenv("SSE_SLOW_CLIENT_POLICY")
 		os.Unsetenv("AUTH_ENABLED")
 		os.Unsetenv("AUTH_TYPE")
 		os.Unsetenv("AUTH_API_KEY")
@@ -135,6 +147,10 @@ func TestApplyEnvironmentOverrides(t *testing.T) {
 	// Verify changes
 	assert.Equal(t, "testhost", cfg.Server.Host)
 	assert.Equal(t, 9000, cfg.Server.Port)
+	assert.Equal(t, 8, cfg.Server.StdioWorkers)
+	assert.Equal(t, 64, cfg.Server.StdioMaxOutstanding)
+	assert.Equal(t, 128, cfg.Server.SSEQueueSize)
+	assert.Equal(t, "disconnect", cfg.Server.SSESlowClientPolicy)
 	assert.Equal(t, true, cfg.Auth.Enabled)
 	assert.Equal(t, "basic", cfg.Auth.Type)
 	assert.Equal(t, "test_api_key", cfg.Auth.APIKey)
@@ -151,10 +167,45 @@ func TestApplyEnvironmentOverrides(t *testing.T) {
 	assert.Equal(t, "test_api_key", cfg.MAASInstances["default"].APIKey)
 }
 
+func TestLoadConfigSSE(t *testing.T) {
+	os.Setenv("MAAS_API_URL", "http://test.maas")
+	os.Setenv("MAAS_API_KEY", "test_api_key")
+	os.Setenv("SSE_QUEUE_SIZE", "256")
+	os.Setenv("SSE_SLOW_CLIENT_POLICY", "drop")
+	defer func() {
+		os.Unsetenv("MAAS_API_URL")
+		os.Unsetenv("MAAS_API_KEY")
+		os.Unsetenv("SSE_QUEUE_SIZE")
+		os.Unsetenv("SSE_SLOW_CLIENT_POLICY")
+		instance = nil
+	}()
+
+	instance = nil
+	cfg, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 256, cfg.Server.SSEQueueSize)
+	assert.Equal(t, "drop", cfg.Server.SSESlowClientPolicy)
+
+	// An unknown slow client policy is rejected
+	instance = nil
+	os.Setenv("SSE_SLOW_CLIENT_POLICY", "block")
+	cfg, err = LoadConfig()
+	assert.Error(t, err)
+	assert.Nil(t, cfg)
+	assert.Contains(t, err.Error(), "SSE slow client policy")
+
+	// So is a negative queue size
+	instance = nil
+	os.Setenv("SSE_SLOW_CLIENT_POLICY", "disconnect")
+	os.Setenv("SSE_QUEUE_SIZE", "-1")
+	_, err = LoadConfig()
+	assert.Error(t, err)
+}
+
 func TestGetDefaultMAASInstance(t *testing.T) {
 	// Test with default instance
-	cfg := &models.AppConfig{
-		MAASInstances: map[string]models.MAASInstanceConfig{
+	cfg := &types.AppConfig{
+		MAASInstances: map[string]types.MAASInstanceConfig{
 			"default": {
 				APIURL: "http://default.maas",
 				APIKey: "default_api_key",
@@ -172,8 +223,8 @@ func TestGetDefaultMAASInstance(t *testing.T) {
 	assert.Equal(t, "default_api_key", instance.APIKey)
 
 	// Test with no default but other instances
-	cfg = &models.AppConfig{
-		MAASInstances: map[string]models.MAASInstanceConfig{
+	cfg = &types.AppConfig{
+		MAASInstances: map[string]types.MAASInstanceConfig{
 			"secondary": {
 				APIURL: "http://secondary.maas",
 				APIKey: "secondary_api_key",
@@ -187,8 +238,8 @@ func TestGetDefaultMAASInstance(t *testing.T) {
 	assert.Equal(t, "secondary_api_key", instance.APIKey)
 
 	// Test with no instances
-	cfg = &models.AppConfig{
-		MAASInstances: map[string]models.MAASInstanceConfig{},
+	cfg = &types.AppConfig{
+		MAASInstances: map[string]types.MAASInstanceConfig{},
 	}
 
 	instance = cfg.GetDefaultMAASInstance()
@@ -198,8 +249,8 @@ func TestGetDefaultMAASInstance(t *testing.T) {
 }
 
 func TestGetMAASInstance(t *testing.T) {
-	cfg := &models.AppConfig{
-		MAASInstances: map[string]models.MAASInstanceConfig{
+	cfg := &types.AppConfig{
+		MAASInstances: map[string]types.MAASInstanceConfig{
 			"default": {
 				APIURL: "http://default.maas",
 				APIKey: "default_api_key",
@@ -228,18 +279,18 @@ func TestGetMAASInstance(t *testing.T) {
 
 func TestGetServerAddress(t *testing.T) {
 	// Set up a test configuration
-	instance = &models.AppConfig{
-		Server: models.ServerConfig{
+	instance = &types.AppConfig{
+		Server: types.ServerConfig{
 			Host: "testhost",
 			Port: 9000,
 		},
-		MAASInstances: map[string]models.MAASInstanceConfig{
+		MAASInstances: map[string]types.MAASInstanceConfig{
 			"default": {
 				APIURL: "http://test.maas",
 				APIKey: "test_api_key",
 			},
 		},
-		Logging: models.LoggingConfig{
+		Logging: types.LoggingConfig{
 			Level: "info",
 		},
 	}
@@ -251,18 +302,18 @@ func TestGetServerAddress(t *testing.T) {
 
 func TestGetLogLevel(t *testing.T) {
 	// Set up a test configuration
-	instance = &models.AppConfig{
-		Server: models.ServerConfig{
+	instance = &types.AppConfig{
+		Server: types.ServerConfig{
 			Host: "localhost",
 			Port: 8082,
 		},
-		MAASInstances: map[string]models.MAASInstanceConfig{
+		MAASInstances: map[string]types.MAASInstanceConfig{
 			"default": {
 				APIURL: "http://test.maas",
 				APIKey: "test_api_key",
 			},
 		},
-		Logging: models.LoggingConfig{
+		Logging: types.LoggingConfig{
 			Level: "debug",
 		},
 	}
@@ -273,29 +324,29 @@ func TestGetLogLevel(t *testing.T) {
 }
 
 func TestConfigChangeEvent(t *testing.T) {
-	oldConfig := &models.AppConfig{
-		Server: models.ServerConfig{
+	oldConfig := &types.AppConfig{
+		Server: types.ServerConfig{
 			Host: "localhost",
 			Port: 8082,
 		},
-		Logging: models.LoggingConfig{
+		Logging: types.LoggingConfig{
 			Level: "info",
 		},
 	}
 
-	newConfig := &models.AppConfig{
-		Server: models.ServerConfig{
+	newConfig := &types.AppConfig{
+		Server: types.ServerConfig{
 			Host: "localhost",
 			Port: 9000,
 		},
-		Logging: models.LoggingConfig{
+		Logging: types.LoggingConfig{
 			Level: "debug",
 		},
 	}
 
 	timestamp := time.Now()
 
-	event := models.ConfigChangeEvent{
+	event := types.ConfigChangeEvent{
 		OldConfig: oldConfig,
 		NewConfig: newConfig,
 		Timestamp: timestamp,
@@ -308,23 +359,23 @@ func TestConfigChangeEvent(t *testing.T) {
 
 func TestGetCredential(t *testing.T) {
 	// Set up a test configuration
-	instance = &models.AppConfig{
-		Server: models.ServerConfig{
+	instance = &types.AppConfig{
+		Server: types.ServerConfig{
 			Host: "localhost",
 			Port: 8082,
 		},
-		MAASInstances: map[string]models.MAASInstanceConfig{
+		MAASInstances: map[string]types.MAASInstanceConfig{
 			"default": {
 				APIURL: "http://config.maas",
 				APIKey: "config_api_key",
 			},
 		},
-		Auth: models.AuthConfig{
+		Auth: types.AuthConfig{
 			Enabled: true,
 			Type:    "apikey",
 			APIKey:  "config_auth_api_key",
 		},
-		Logging: models.LoggingConfig{
+		Logging: types.LoggingConfig{
 			Level: "info",
 		},
 	}
@@ -0,0 +1,381 @@
+// Package jsonschema compiles the JSON Schemas of MCP tool inputs and validates inputs against them.
+//
+// It supports the keywords tool schemas use: type, properties, required, additionalProperties,
+// items, enum, const, the numeric, string and array bounds, pattern, allOf, anyOf, oneOf, not and
+// local $ref pointers ("#/definitions/...", "#/$defs/..."). Other keywords, such as format,
+// default and description, are annotations and are ignored.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Schema is a compiled JSON Schema
+type Schema struct {
+	root *node
+}
+
+// node is a compiled schema or subschema
+type node struct {
+	// always is set for the boolean schemas true and false
+	always *bool
+
+	types                []string
+	properties           map[string]*node
+	required             []string
+	additionalProperties *node
+	items                *node
+	minItems, maxItems   *int
+	uniqueItems          bool
+	enum                 []interface{}
+	hasConst             bool
+	constValue           interface{}
+	minimum, maximum     *big.Rat
+	exclusiveMinimum     *big.Rat
+	exclusiveMaximum     *big.Rat
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+	allOf, anyOf, oneOf  []*node
+	not                  *node
+	ref                  *node
+}
+
+// validTypes are the JSON Schema type names
+var validTypes = map[string]bool{
+	"null": true, "boolean": true, "object": true, "array": true, "number": true, "integer": true, "string": true,
+}
+
+// compiler compiles a schema document; refs holds the nodes of $ref targets by pointer, so
+// recursive schemas compile once
+type compiler struct {
+	document interface{}
+	refs     map[string]*node
+}
+
+// Compile compiles a JSON Schema document. An empty document accepts any input.
+func Compile(document []byte) (*Schema, error) {
+	if len(bytes.TrimSpace(document)) == 0 {
+		return &Schema{root: &node{}}, nil
+	}
+
+	value, err := decode(document)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	c := &compiler{document: value, refs: make(map[string]*node)}
+	root, err := c.compile(value, "")
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{root: root}, nil
+}
+
+// CompileValue compiles a JSON Schema held in a Go value, such as a map decoded from JSON
+func CompileValue(schema interface{}) (*Schema, error) {
+	document, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return Compile(document)
+}
+
+// decode decodes JSON keeping numbers exact
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return value, nil
+}
+
+// compile compiles the schema at pointer
+func (c *compiler) compile(value interface{}, pointer string) (*node, error) {
+	n := &node{}
+	if err := c.compileInto(n, value, pointer); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// compileInto compiles the schema at pointer into n
+func (c *compiler) compileInto(n *node, value interface{}, pointer string) error {
+	if always, ok := value.(bool); ok {
+		n.always = &always
+		return nil
+	}
+	schema, ok := value.(map[string]interface{})
+	if !ok {
+		return schemaError(pointer, "a schema must be an object or a boolean")
+	}
+
+	var err error
+	if ref, ok := schema["$ref"]; ok {
+		target, ok := ref.(string)
+		if !ok {
+			return schemaError(pointer+"/$ref", "must be a string")
+		}
+		if n.ref, err = c.resolve(target); err != nil {
+			return schemaError(pointer+"/$ref", err.Error())
+		}
+	}
+
+	if typ, ok := schema["type"]; ok {
+		if n.types, err = stringList(typ); err != nil {
+			return schemaError(pointer+"/type", err.Error())
+		}
+		for _, t := range n.types {
+			if !validTypes[t] {
+				return schemaError(pointer+"/type", fmt.Sprintf("unknown type %q", t))
+			}
+		}
+	}
+
+	if properties, ok := schema["properties"]; ok {
+		object, ok := properties.(map[string]interface{})
+		if !ok {
+			return schemaError(pointer+"/properties", "must be an object")
+		}
+		n.properties = make(map[string]*node, len(object))
+		for name, property := range object {
+			if n.properties[name], err = c.compile(property, pointer+"/properties/"+escape(name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if required, ok := schema["required"]; ok {
+		if _, ok := required.([]interface{}); !ok {
+			return schemaError(pointer+"/required", "must be an array of strings")
+		}
+		if n.required, err = stringList(required); err != nil {
+			return schemaError(pointer+"/required", err.Error())
+		}
+	}
+
+	if additional, ok := schema["additionalProperties"]; ok {
+		if n.additionalProperties, err = c.compile(additional, pointer+"/additionalProperties"); err != nil {
+			return err
+		}
+	}
+
+	if items, ok := schema["items"]; ok {
+		if n.items, err = c.compile(items, pointer+"/items"); err != nil {
+			return err
+		}
+	}
+
+	if enum, ok := schema["enum"]; ok {
+		values, ok := enum.([]interface{})
+		if !ok {
+			return schemaError(pointer+"/enum", "must be an array")
+		}
+		n.enum = values
+	}
+
+	if constValue, ok := schema["const"]; ok {
+		n.hasConst = true
+		n.constValue = constValue
+	}
+
+	for keyword, bound := range map[string]**big.Rat{
+		"minimum":          &n.minimum,
+		"maximum":          &n.maximum,
+		"exclusiveMinimum": &n.exclusiveMinimum,
+		"exclusiveMaximum": &n.exclusiveMaximum,
+	} {
+		value, ok := schema[keyword]
+		if !ok {
+			continue
+		}
+		switch v := value.(type) {
+		case json.Number:
+			*bound = rat(v)
+		case bool:
+			// Draft 4 marks the minimum and maximum exclusive with a boolean, handled below
+		default:
+			return schemaError(pointer+"/"+keyword, "must be a number")
+		}
+	}
+	if exclusive, _ := schema["exclusiveMinimum"].(bool); exclusive && n.minimum != nil {
+		n.exclusiveMinimum, n.minimum = n.minimum, nil
+	}
+	if exclusive, _ := schema["exclusiveMaximum"].(bool); exclusive && n.maximum != nil {
+		n.exclusiveMaximum, n.maximum = n.maximum, nil
+	}
+
+	for keyword, bound := range map[string]**int{
+		"minItems":  &n.minItems,
+		"maxItems":  &n.maxItems,
+		"minLength": &n.minLength,
+		"maxLength": &n.maxLength,
+	} {
+		value, ok := schema[keyword]
+		if !ok {
+			continue
+		}
+		number, ok := value.(json.Number)
+		if !ok {
+			return schemaError(pointer+"/"+keyword, "must be a non-negative integer")
+		}
+		count, err := number.Int64()
+		if err != nil || count < 0 {
+			return schemaError(pointer+"/"+keyword, "must be a non-negative integer")
+		}
+		limit := int(count)
+		*bound = &limit
+	}
+
+	if unique, ok := schema["uniqueItems"]; ok {
+		if n.uniqueItems, ok = unique.(bool); !ok {
+			return schemaError(pointer+"/uniqueItems", "must be a boolean")
+		}
+	}
+
+	if pattern, ok := schema["pattern"]; ok {
+		expr, ok := pattern.(string)
+		if !ok {
+			return schemaError(pointer+"/pattern", "must be a string")
+		}
+		if n.pattern, err = regexp.Compile(expr); err != nil {
+			return schemaError(pointer+"/pattern", err.Error())
+		}
+	}
+
+	for keyword, list := range map[string]*[]*node{"allOf": &n.allOf, "anyOf": &n.anyOf, "oneOf": &n.oneOf} {
+		value, ok := schema[keyword]
+		if !ok {
+			continue
+		}
+		subschemas, ok := value.([]interface{})
+		if !ok || len(subschemas) == 0 {
+			return schemaError(pointer+"/"+keyword, "must be a non-empty array")
+		}
+		for i, subschema := range subschemas {
+			compiled, err := c.compile(subschema, fmt.Sprintf("%s/%s/%d", pointer, keyword, i))
+			if err != nil {
+				return err
+			}
+			*list = append(*list, compiled)
+		}
+	}
+
+	if not, ok := schema["not"]; ok {
+		if n.not, err = c.compile(not, pointer+"/not"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolve returns the node of a local $ref, compiling it the first time it is referenced
+func (c *compiler) resolve(ref string) (*node, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("only local references are supported, got %q", ref)
+	}
+	pointer := strings.TrimPrefix(ref, "#")
+	if n, ok := c.refs[pointer]; ok {
+		return n, nil
+	}
+
+	target, err := lookup(c.document, pointer)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %q: %w", ref, err)
+	}
+
+	// Register the node before compiling it, so references back to it resolve to the same node
+	n := &node{}
+	c.refs[pointer] = n
+	if err := c.compileInto(n, target, pointer); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// lookup returns the value at a JSON pointer in a document
+func lookup(document interface{}, pointer string) (interface{}, error) {
+	if pointer == "" {
+		return document, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer")
+	}
+
+	value := document
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("no value at %s", pointer)
+		}
+		if value, ok = object[token]; !ok {
+			return nil, fmt.Errorf("no value at %s", pointer)
+		}
+	}
+	return value, nil
+}
+
+// stringList reads a keyword that holds a string or a list of strings
+func stringList(value interface{}) ([]string, error) {
+	if s, ok := value.(string); ok {
+		return []string{s}, nil
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("must be a string or an array of strings")
+	}
+	strs := make([]string, len(list))
+	for i, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a string or an array of strings")
+		}
+		strs[i] = s
+	}
+	return strs, nil
+}
+
+// schemaError describes an invalid schema keyword
+func schemaError(pointer string, message string) error {
+	if pointer == "" {
+		return fmt.Errorf("invalid schema: %s", message)
+	}
+	return fmt.Errorf("invalid schema at %s: %s", pointer, message)
+}
+
+// escape escapes a property name for use in a JSON pointer
+func escape(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+// rat returns the exact value of a JSON number
+func rat(number json.Number) *big.Rat {
+	r, ok := new(big.Rat).SetString(string(number))
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
+
+// sortedKeys returns the keys of an object in order, so violations are reported deterministically
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,138 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// violations validates input and returns the violations found
+func violations(t *testing.T, schema *Schema, input string) []Violation {
+	err := schema.Validate(json.RawMessage(input))
+	if err == nil {
+		return nil
+	}
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr), "unexpected error %v", err)
+	return validationErr.Violations
+}
+
+func TestValidate(t *testing.T) {
+	schema, err := Compile([]byte(`{
+		"type": "object",
+		"required": ["id", "count"],
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": "string", "minLength": 3, "pattern": "^[a-z0-9]+$"},
+			"count": {"type": "integer", "minimum": 1, "maximum": 50},
+			"ratio": {"type": "number", "exclusiveMaximum": 1},
+			"mode": {"enum": ["on", "off"]},
+			"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 3, "uniqueItems": true},
+			"filters": {"type": "object", "additionalProperties": {"type": "string"}}
+		}
+	}`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []Violation
+	}{
+		{"valid", `{"id": "abc123", "count": 5, "ratio": 0.5, "mode": "on", "tags": ["a", "b"], "filters": {"zone": "z1"}}`, nil},
+		{"large integer", `{"id": "abc", "count": 50.0}`, nil},
+		{"missing required", `{}`, []Violation{{"/id", "is required"}, {"/count", "is required"}}},
+		{"null input is an empty object", `null`, []Violation{{"/id", "is required"}, {"/count", "is required"}}},
+		{"wrong type", `{"id": 7, "count": "5"}`, []Violation{{"/count", "must be an integer, not string"}, {"/id", "must be a string, not integer"}}},
+		{"not an integer", `{"id": "abc", "count": 1.5}`, []Violation{{"/count", "must be an integer, not number"}}},
+		{"bounds", `{"id": "ab", "count": 51, "ratio": 1}`, []Violation{
+			{"/count", "must be at most 50"},
+			{"/id", "must be at least 3 characters long"},
+			{"/ratio", "must be less than 1"},
+		}},
+		{"pattern", `{"id": "ABC", "count": 1}`, []Violation{{"/id", "must match the pattern ^[a-z0-9]+$"}}},
+		{"enum", `{"id": "abc", "count": 1, "mode": "auto"}`, []Violation{{"/mode", `must be one of ["on","off"]`}}},
+		{"unknown property", `{"id": "abc", "count": 1, "colour": "red"}`, []Violation{{"/colour", "is not a known property"}}},
+		{"nested", `{"id": "abc", "count": 1, "tags": ["a", 2, "a", "b"], "filters": {"zone": 1}}`, []Violation{
+			{"/filters/zone", "must be a string, not integer"},
+			{"/tags", "must have at most 3 items"},
+			{"/tags/2", "duplicates item 0"},
+			{"/tags/1", "must be a string, not integer"},
+		}},
+		{"root type", `[1]`, []Violation{{"", "must be an object, not array"}}},
+		{"invalid JSON", `{"id":`, []Violation{{"", "invalid JSON: unexpected EOF"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, violations(t, schema, tt.input))
+		})
+	}
+}
+
+func TestValidate_Combinators(t *testing.T) {
+	schema, err := Compile([]byte(`{
+		"definitions": {
+			"id": {"type": "string", "minLength": 1},
+			"node": {"type": "object", "properties": {"name": {"$ref": "#/definitions/id"}, "children": {"type": "array", "items": {"$ref": "#/definitions/node"}}}}
+		},
+		"type": "object",
+		"properties": {
+			"target": {"oneOf": [{"type": "integer"}, {"$ref": "#/definitions/id"}]},
+			"either": {"anyOf": [{"type": "boolean"}, {"const": "auto"}]},
+			"tree": {"$ref": "#/definitions/node"},
+			"not_null": {"not": {"type": "null"}},
+			"both": {"allOf": [{"minimum": 2}, {"maximum": 4}]}
+		}
+	}`))
+	require.NoError(t, err)
+
+	assert.Empty(t, violations(t, schema, `{"target": 3, "either": "auto", "tree": {"name": "a", "children": [{"name": "b"}]}, "not_null": 1, "both": 3}`))
+	assert.Equal(t, []Violation{
+		{"/both", "must be at most 4"},
+		{"/either", "must match at least one of the allowed schemas"},
+		{"/not_null", "must not match the excluded schema"},
+		{"/target", "must match exactly one of the allowed schemas, but matches 0"},
+		{"/tree/children/0/name", "must be at least 1 character long"},
+	}, violations(t, schema, `{"target": 1.5, "either": "manual", "tree": {"children": [{"name": ""}]}, "not_null": null, "both": 5}`))
+}
+
+func TestCompile(t *testing.T) {
+	for _, document := range []string{``, `{}`, `true`} {
+		schema, err := Compile([]byte(document))
+		require.NoError(t, err)
+		assert.NoError(t, schema.Validate(json.RawMessage(`{"anything": [1, 2]}`)))
+	}
+
+	schema, err := CompileValue(map[string]interface{}{"type": "object", "required": []string{"id"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Violation{{"/id", "is required"}}, violations(t, schema, `{}`))
+
+	// Draft 4 exclusive bounds
+	schema, err = Compile([]byte(`{"minimum": 0, "exclusiveMinimum": true}`))
+	require.NoError(t, err)
+	assert.Equal(t, []Violation{{"", "must be greater than 0"}}, violations(t, schema, `0`))
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"not JSON":          `{"type":`,
+		"not a schema":      `[]`,
+		"unknown type":      `{"type": "map"}`,
+		"bad required":      `{"required": "id"}`,
+		"bad pattern":       `{"properties": {"id": {"pattern": "("}}}`,
+		"bad bound":         `{"minimum": "1"}`,
+		"negative length":   `{"maxLength": -1}`,
+		"empty oneOf":       `{"oneOf": []}`,
+		"unresolvable ref":  `{"$ref": "#/definitions/missing"}`,
+		"remote ref":        `{"$ref": "https://example.com/schema.json"}`,
+		"bad property type": `{"properties": {"id": 1}}`,
+	}
+	for name, document := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Compile([]byte(document))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Violation is a part of an input that does not match the schema. Pointer is the JSON pointer
+// (RFC 6901) of the offending value, such as "/filters/zone"; the empty pointer is the whole input.
+type Violation struct {
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+// String formats the violation as "pointer: message"
+func (v Violation) String() string {
+	pointer := v.Pointer
+	if pointer == "" {
+		pointer = "(root)"
+	}
+	return pointer + ": " + v.Message
+}
+
+// ValidationError lists the violations of an input that does not match its schema
+type ValidationError struct {
+	Violations []Violation `json:"violations"`
+}
+
+// Error returns the violations, separated by semicolons
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.String()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validate checks a JSON input against the schema. A missing or null input is validated as an
+// empty object, which is what MCP clients send for a tool call without arguments. It returns a
+// *ValidationError when the input does not match.
+func (s *Schema) Validate(input json.RawMessage) error {
+	trimmed := strings.TrimSpace(string(input))
+	if trimmed == "" || trimmed == "null" {
+		input = json.RawMessage(`{}`)
+	}
+
+	value, err := decode(input)
+	if err != nil {
+		return &ValidationError{Violations: []Violation{{Message: fmt.Sprintf("invalid JSON: %v", err)}}}
+	}
+	return s.ValidateValue(value)
+}
+
+// ValidateValue checks a decoded JSON value against the schema
+func (s *Schema) ValidateValue(value interface{}) error {
+	var violations []Violation
+	s.root.validate(value, "", &violations)
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// validate appends the violations of value, found at pointer, to violations
+func (n *node) validate(value interface{}, pointer string, violations *[]Violation) {
+	report := func(format string, args ...interface{}) {
+		*violations = append(*violations, Violation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n.always != nil {
+		if !*n.always {
+			report("is not allowed")
+		}
+		return
+	}
+
+	if n.ref != nil {
+		n.ref.validate(value, pointer, violations)
+	}
+
+	if len(n.types) > 0 && !n.matchesType(value) {
+		report("must be %s, not %s", article(strings.Join(n.types, " or ")), typeName(value))
+		// The other keywords would only repeat the type mismatch
+		return
+	}
+
+	if n.enum != nil {
+		found := false
+		for _, allowed := range n.enum {
+			if equal(value, allowed) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			report("must be one of %s", formatValues(n.enum))
+		}
+	}
+	if n.hasConst && !equal(value, n.constValue) {
+		report("must be %s", formatValues([]interface{}{n.constValue}))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		n.validateObject(v, pointer, violations)
+	case []interface{}:
+		n.validateArray(v, pointer, violations)
+	case string:
+		length := utf8.RuneCountInString(v)
+		if n.minLength != nil && length < *n.minLength {
+			report("must be at least %s long", count(*n.minLength, "character"))
+		}
+		if n.maxLength != nil && length > *n.maxLength {
+			report("must be at most %s long", count(*n.maxLength, "character"))
+		}
+		if n.pattern != nil && !n.pattern.MatchString(v) {
+			report("must match the pattern %s", n.pattern.String())
+		}
+	case json.Number:
+		number := rat(v)
+		if n.minimum != nil && number.Cmp(n.minimum) < 0 {
+			report("must be at least %s", n.minimum.RatString())
+		}
+		if n.maximum != nil && number.Cmp(n.maximum) > 0 {
+			report("must be at most %s", n.maximum.RatString())
+		}
+		if n.exclusiveMinimum != nil && number.Cmp(n.exclusiveMinimum) <= 0 {
+			report("must be greater than %s", n.exclusiveMinimum.RatString())
+		}
+		if n.exclusiveMaximum != nil && number.Cmp(n.exclusiveMaximum) >= 0 {
+			report("must be less than %s", n.exclusiveMaximum.RatString())
+		}
+	}
+
+	for _, subschema := range n.allOf {
+		subschema.validate(value, pointer, violations)
+	}
+	if len(n.anyOf) > 0 {
+		matched := 0
+		for _, subschema := range n.anyOf {
+			if subschema.matches(value) {
+				matched++
+				break
+			}
+		}
+		if matched == 0 {
+			report("must match at least one of the allowed schemas")
+		}
+	}
+	if len(n.oneOf) > 0 {
+		matched := 0
+		for _, subschema := range n.oneOf {
+			if subschema.matches(value) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			report("must match exactly one of the allowed schemas, but matches %d", matched)
+		}
+	}
+	if n.not != nil && n.not.matches(value) {
+		report("must not match the excluded schema")
+	}
+}
+
+// validateObject checks the properties of an object
+func (n *node) validateObject(object map[string]interface{}, pointer string, violations *[]Violation) {
+	for _, name := range n.required {
+		if _, ok := object[name]; !ok {
+			*violations = append(*violations, Violation{Pointer: pointer + "/" + escape(name), Message: "is required"})
+		}
+	}
+
+	for _, name := range sortedKeys(object) {
+		propertyPointer := pointer + "/" + escape(name)
+		if property, ok := n.properties[name]; ok {
+			property.validate(object[name], propertyPointer, violations)
+			continue
+		}
+		if n.additionalProperties != nil {
+			if n.additionalProperties.always != nil && !*n.additionalProperties.always {
+				*violations = append(*violations, Violation{Pointer: propertyPointer, Message: "is not a known property"})
+				continue
+			}
+			n.additionalProperties.validate(object[name], propertyPointer, violations)
+		}
+	}
+}
+
+// validateArray checks the items of an array
+func (n *node) validateArray(array []interface{}, pointer string, violations *[]Violation) {
+	if n.minItems != nil && len(array) < *n.minItems {
+		*violations = append(*violations, Violation{Pointer: pointer, Message: "must have at least " + count(*n.minItems, "item")})
+	}
+	if n.maxItems != nil && len(array) > *n.maxItems {
+		*violations = append(*violations, Violation{Pointer: pointer, Message: "must have at most " + count(*n.maxItems, "item")})
+	}
+	if n.uniqueItems {
+	unique:
+		for i := range array {
+			for j := 0; j < i; j++ {
+				if equal(array[i], array[j]) {
+					*violations = append(*violations, Violation{
+						Pointer: fmt.Sprintf("%s/%d", pointer, i),
+						Message: fmt.Sprintf("duplicates item %d", j),
+					})
+					break unique
+				}
+			}
+		}
+	}
+	if n.items != nil {
+		for i, item := range array {
+			n.items.validate(item, fmt.Sprintf("%s/%d", pointer, i), violations)
+		}
+	}
+}
+
+// matches reports whether value matches the schema
+func (n *node) matches(value interface{}) bool {
+	var violations []Violation
+	n.validate(value, "", &violations)
+	return len(violations) == 0
+}
+
+// matchesType reports whether value has one of the schema's types
+func (n *node) matchesType(value interface{}) bool {
+	actual := typeName(value)
+	for _, t := range n.types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// typeName returns the JSON Schema type of a decoded value; numbers without a fraction are integers
+func typeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		if rat(v).IsInt() {
+			return "integer"
+		}
+		return "number"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// count formats a number of things, such as "1 item" or "3 items"
+func count(n int, thing string) string {
+	if n == 1 {
+		return "1 " + thing
+	}
+	return fmt.Sprintf("%d %ss", n, thing)
+}
+
+// article prefixes a type name with "a" or "an"
+func article(types string) string {
+	if strings.IndexAny(types[:1], "aeiou") == 0 {
+		return "an " + types
+	}
+	return "a " + types
+}
+
+// equal reports whether two decoded JSON values are equal; numbers compare by value
+func equal(a, b interface{}) bool {
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		return ok && rat(x).Cmp(rat(y)) == 0
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for key, value := range x {
+			other, ok := y[key]
+			if !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// formatValues formats allowed values as a JSON list
+func formatValues(values []interface{}) string {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Sprint(values)
+	}
+	if len(values) == 1 {
+		return strings.TrimSuffix(strings.TrimPrefix(string(data), "["), "]")
+	}
+	return string(data)
+}
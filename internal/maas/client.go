@@ -17,6 +17,9 @@ type ClientWrapper struct {
 	common.MachineClient
 	common.NetworkClient
 	common.TagClient
+	common.ZoneClient
+	common.ResourcePoolClient
+	common.IPAddressClient
 	common.BlockDeviceClient
 	common.StorageConstraintsClient
 	common.VolumeGroupClient
@@ -66,6 +69,9 @@ func NewClientWrapper(apiURL, apiKey, apiVersion string, logger *logrus.Logger)
 		MachineClient:            newMachineClient(client, logger, retryFunc),
 		NetworkClient:            newNetworkClient(client, logger, retryFunc),
 		TagClient:                newTagClient(client, logger, retryFunc),
+		ZoneClient:               newZoneClient(client, logger, retryFunc),
+		ResourcePoolClient:       newResourcePoolClient(client, logger, retryFunc),
+		IPAddressClient:          newIPAddressClient(client, logger, retryFunc),
 		BlockDeviceClient:        storage.NewBlockDeviceClient(client, logger, retryFunc),
 		StorageConstraintsClient: storage.NewConstraintsClient(client, logger, retryFunc),
 		VolumeGroupClient:        storage.NewVolumeGroupClient(client, logger, retryFunc),
@@ -0,0 +1,17 @@
+package common
+
+import (
+	"errors"
+	"net/http"
+
+	gomaasapi "github.com/juju/gomaasapi/v2"
+)
+
+// ErrNotFound is wrapped by the errors of client calls for MAAS objects that do not exist
+var ErrNotFound = errors.New("MAAS object not found")
+
+// isNotFoundResponse reports whether err is a MAAS API response with status 404 Not Found
+func isNotFoundResponse(err error) bool {
+	var serverErr gomaasapi.ServerError
+	return errors.As(err, &serverErr) && serverErr.StatusCode == http.StatusNotFound
+}
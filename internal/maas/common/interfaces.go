@@ -7,6 +7,7 @@ import (
 	"github.com/canonical/gomaasclient/entity"
 	"github.com/sirupsen/logrus"
 
+	"github.com/lspecian/maas-mcp-server/internal/models"
 	"github.com/lspecian/maas-mcp-server/internal/models/types"
 )
 
@@ -22,6 +23,9 @@ type MachineClient interface {
 	ListMachinesSimple(ctx context.Context, filters map[string]string) ([]types.Machine, error)
 	GetMachineWithDetails(ctx context.Context, systemID string, includeDetails bool) (*types.Machine, error)
 	CheckStorageConstraints(machine *types.Machine, constraints *types.SimpleStorageConstraint) bool
+	EvaluateStorageConstraints(machine *types.Machine, constraints []types.SimpleStorageConstraint) *models.StorageMatchReport
+	AbortMachineOperation(systemID string, comment string) (*types.Machine, error)
+	ListMachineEvents(systemID string, limit int) ([]types.MachineEvent, error)
 }
 
 // NetworkClient defines operations for network management
@@ -30,6 +34,24 @@ type NetworkClient interface {
 	ListSubnets() ([]types.Subnet, error)
 	ListVLANs(fabricID int) ([]types.VLAN, error)
 	GetMachineInterfaces(systemID string) ([]types.NetworkInterface, error)
+	GetSubnetStatistics(id int) (*types.SubnetStatistics, error)
+	GetSubnetIPAddresses(id int) ([]types.SubnetIPAddress, error)
+
+	ListSpaces() ([]types.Space, error)
+	GetSpace(id int) (*types.Space, error)
+	CreateSpace(name string) (*types.Space, error)
+	UpdateSpace(id int, name string) (*types.Space, error)
+	DeleteSpace(id int) error
+
+	ListIPRanges() ([]types.IPRange, error)
+	GetIPRange(id int) (*types.IPRange, error)
+	CreateIPRange(params *entity.IPRangeParams) (*types.IPRange, error)
+	UpdateIPRange(id int, params *entity.IPRangeParams) (*types.IPRange, error)
+	DeleteIPRange(id int) error
+
+	ListStaticRoutes() ([]types.StaticRoute, error)
+	CreateStaticRoute(params *entity.StaticRouteParams) (*types.StaticRoute, error)
+	DeleteStaticRoute(id int) error
 }
 
 // TagClient defines operations for tag management
@@ -40,6 +62,31 @@ type TagClient interface {
 	RemoveTagFromMachine(tagName, systemID string) error
 }
 
+// ZoneClient defines operations for availability zone management
+type ZoneClient interface {
+	ListZones() ([]types.Zone, error)
+	GetZone(name string) (*types.Zone, error)
+	CreateZone(params *entity.ZoneParams) (*types.Zone, error)
+	UpdateZone(name string, params *entity.ZoneParams) (*types.Zone, error)
+	DeleteZone(name string) error
+}
+
+// ResourcePoolClient defines operations for resource pool management
+type ResourcePoolClient interface {
+	ListResourcePools() ([]types.ResourcePool, error)
+	GetResourcePool(name string) (*types.ResourcePool, error)
+	CreateResourcePool(params *entity.ResourcePoolParams) (*types.ResourcePool, error)
+	UpdateResourcePool(name string, params *entity.ResourcePoolParams) (*types.ResourcePool, error)
+	DeleteResourcePool(name string) error
+}
+
+// IPAddressClient defines operations for static IP address reservations
+type IPAddressClient interface {
+	ListIPAddresses(all bool) ([]types.IPAddress, error)
+	ReserveIPAddress(params *types.IPAddressReserveParams) (*types.IPAddress, error)
+	ReleaseIPAddress(ip string, force bool) error
+}
+
 // BlockDeviceClient defines operations for block device management
 type BlockDeviceClient interface {
 	GetMachineBlockDevices(systemID string) ([]types.BlockDevice, error)
@@ -98,6 +145,9 @@ type MAASClient interface {
 	MachineClient
 	NetworkClient
 	TagClient
+	ZoneClient
+	ResourcePoolClient
+	IPAddressClient
 	StorageClient
 	VolumeGroupClient
 	RAIDClient
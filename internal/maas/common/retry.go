@@ -10,17 +10,22 @@ import (
 // RetryFunc defines a function that retries an operation
 type RetryFunc func(operation func() error, attempts int, delay time.Duration) error
 
-// CreateRetryFunc creates a retry function with the given logger
+// CreateRetryFunc creates a retry function with the given logger. An operation that MAAS answers
+// with 404 Not Found is not retried; its error wraps ErrNotFound.
 func CreateRetryFunc(logger *logrus.Logger) RetryFunc {
 	return func(operation func() error, attempts int, delay time.Duration) error {
+		var err error
 		for i := 0; i < attempts; i++ {
-			err := operation()
+			err = operation()
 			if err == nil {
 				return nil
 			}
+			if isNotFoundResponse(err) {
+				return fmt.Errorf("%w: %w", ErrNotFound, err)
+			}
 			logger.Warnf("Attempt %d failed: %v", i+1, err)
 			time.Sleep(delay)
 		}
-		return fmt.Errorf("failed after %d attempts", attempts)
+		return fmt.Errorf("failed after %d attempts: %w", attempts, err)
 	}
 }
@@ -0,0 +1,104 @@
+package maas
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/canonical/gomaasclient/client"
+	"github.com/canonical/gomaasclient/entity"
+	"github.com/sirupsen/logrus"
+
+	"github.com/lspecian/maas-mcp-server/internal/maas/common"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// ipAddressClient implements the common.IPAddressClient interface
+type ipAddressClient struct {
+	client *client.Client
+	logger *logrus.Logger
+	retry  common.RetryFunc
+}
+
+// newIPAddressClient creates a new IP address client
+func newIPAddressClient(client *client.Client, logger *logrus.Logger, retry common.RetryFunc) common.IPAddressClient {
+	return &ipAddressClient{
+		client: client,
+		logger: logger,
+		retry:  retry,
+	}
+}
+
+// ListIPAddresses retrieves the addresses reserved by the API user, or by every user when all is set.
+func (a *ipAddressClient) ListIPAddresses(all bool) ([]types.IPAddress, error) {
+	var entityAddresses []entity.IPAddress
+	operation := func() error {
+		var err error
+		entityAddresses, err = a.client.IPAddresses.Get(&entity.IPAddressesParams{All: all})
+		if err != nil {
+			a.logger.Errorf("MAAS API error listing IP addresses: %v", err)
+			return fmt.Errorf("MAAS API error listing IP addresses: %w", err)
+		}
+		return nil
+	}
+
+	err := a.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	modelAddresses := make([]types.IPAddress, len(entityAddresses))
+	for i, e := range entityAddresses {
+		var m types.IPAddress
+		m.FromEntity(&e)
+		modelAddresses[i] = m
+	}
+	return modelAddresses, nil
+}
+
+// ReserveIPAddress reserves an address, optionally bound to a MAC address and with a hostname.
+// entity.IPAddressesParams has no mac or hostname, so the reserve op is posted through the
+// API client that backs IPAddresses.
+func (a *ipAddressClient) ReserveIPAddress(params *types.IPAddressReserveParams) (*types.IPAddress, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	ipAddresses, ok := a.client.IPAddresses.(*client.IPAddresses)
+	if !ok {
+		return nil, fmt.Errorf("IP address reservation is not supported by the MAAS client")
+	}
+
+	entityAddress := new(entity.IPAddress)
+	operation := func() error {
+		err := ipAddresses.APIClient.GetSubObject("ipaddresses").Post("reserve", params.Values(), func(data []byte) error {
+			return json.Unmarshal(data, entityAddress)
+		})
+		if err != nil {
+			a.logger.Errorf("MAAS API error reserving IP address (subnet '%s', ip '%s'): %v", params.Subnet, params.IP, err)
+			return fmt.Errorf("MAAS API error reserving IP address (subnet '%s', ip '%s'): %w", params.Subnet, params.IP, err)
+		}
+		return nil
+	}
+
+	err := a.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelAddress types.IPAddress
+	modelAddress.FromEntity(entityAddress)
+	return &modelAddress, nil
+}
+
+// ReleaseIPAddress releases a reserved address; force releases addresses reserved by other users.
+func (a *ipAddressClient) ReleaseIPAddress(ip string, force bool) error {
+	operation := func() error {
+		if err := a.client.IPAddresses.Release(&entity.IPAddressesParams{IP: ip, Force: force}); err != nil {
+			a.logger.Errorf("MAAS API error releasing IP address %s: %v", ip, err)
+			return fmt.Errorf("MAAS API error releasing IP address %s: %w", ip, err)
+		}
+		return nil
+	}
+	return a.retry(operation, 3, 2*time.Second)
+}
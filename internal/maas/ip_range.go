@@ -0,0 +1,118 @@
+package maas
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/canonical/gomaasclient/entity"
+
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// ListIPRanges retrieves all reserved and dynamic IP ranges.
+func (n *networkClient) ListIPRanges() ([]types.IPRange, error) {
+	var entityRanges []entity.IPRange
+	operation := func() error {
+		var err error
+		entityRanges, err = n.client.IPRanges.Get()
+		if err != nil {
+			n.logger.Errorf("MAAS API error listing IP ranges: %v", err)
+			return fmt.Errorf("MAAS API error listing IP ranges: %w", err)
+		}
+		return nil
+	}
+
+	err := n.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	modelRanges := make([]types.IPRange, len(entityRanges))
+	for i, e := range entityRanges {
+		var m types.IPRange
+		m.FromEntity(&e)
+		modelRanges[i] = m
+	}
+	return modelRanges, nil
+}
+
+// GetIPRange retrieves an IP range.
+func (n *networkClient) GetIPRange(id int) (*types.IPRange, error) {
+	var entityRange *entity.IPRange
+	operation := func() error {
+		var err error
+		entityRange, err = n.client.IPRange.Get(id)
+		if err != nil {
+			n.logger.Errorf("MAAS API error getting IP range %d: %v", id, err)
+			return fmt.Errorf("MAAS API error getting IP range %d: %w", id, err)
+		}
+		return nil
+	}
+
+	err := n.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelRange types.IPRange
+	modelRange.FromEntity(entityRange)
+	return &modelRange, nil
+}
+
+// CreateIPRange creates a new reserved or dynamic IP range.
+func (n *networkClient) CreateIPRange(params *entity.IPRangeParams) (*types.IPRange, error) {
+	var entityRange *entity.IPRange
+	operation := func() error {
+		var err error
+		entityRange, err = n.client.IPRanges.Create(params)
+		if err != nil {
+			n.logger.Errorf("MAAS API error creating IP range %s-%s: %v", params.StartIP, params.EndIP, err)
+			return fmt.Errorf("MAAS API error creating IP range %s-%s: %w", params.StartIP, params.EndIP, err)
+		}
+		return nil
+	}
+
+	err := n.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelRange types.IPRange
+	modelRange.FromEntity(entityRange)
+	return &modelRange, nil
+}
+
+// UpdateIPRange updates an IP range. MAAS replaces every field, so params must hold the complete range.
+func (n *networkClient) UpdateIPRange(id int, params *entity.IPRangeParams) (*types.IPRange, error) {
+	var entityRange *entity.IPRange
+	operation := func() error {
+		var err error
+		entityRange, err = n.client.IPRange.Update(id, params)
+		if err != nil {
+			n.logger.Errorf("MAAS API error updating IP range %d: %v", id, err)
+			return fmt.Errorf("MAAS API error updating IP range %d: %w", id, err)
+		}
+		return nil
+	}
+
+	err := n.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelRange types.IPRange
+	modelRange.FromEntity(entityRange)
+	return &modelRange, nil
+}
+
+// DeleteIPRange deletes an IP range.
+func (n *networkClient) DeleteIPRange(id int) error {
+	operation := func() error {
+		if err := n.client.IPRange.Delete(id); err != nil {
+			n.logger.Errorf("MAAS API error deleting IP range %d: %v", id, err)
+			return fmt.Errorf("MAAS API error deleting IP range %d: %w", id, err)
+		}
+		return nil
+	}
+	return n.retry(operation, 3, 2*time.Second)
+}
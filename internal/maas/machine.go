@@ -3,6 +3,8 @@ package maas
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/canonical/gomaasclient/client"
@@ -10,6 +12,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/lspecian/maas-mcp-server/internal/maas/common"
+	"github.com/lspecian/maas-mcp-server/internal/models"
 	"github.com/lspecian/maas-mcp-server/internal/models/types"
 )
 
@@ -187,6 +190,58 @@ func (m *machineClient) ReleaseMachine(systemIDs []string, comment string) error
 	return m.retry(operation, 3, 2*time.Second)
 }
 
+// AbortMachineOperation aborts the machine's current operation, such as deploying or commissioning.
+func (m *machineClient) AbortMachineOperation(systemID string, comment string) (*types.Machine, error) {
+	var entityMachine *entity.Machine
+	operation := func() error {
+		var err error
+		entityMachine, err = m.client.Machine.Abort(systemID, comment)
+		if err != nil {
+			m.logger.Errorf("MAAS API error aborting operation on machine %s: %v", systemID, err)
+			return fmt.Errorf("maas API error aborting operation on machine %s: %w", systemID, err)
+		}
+		return nil
+	}
+
+	err := m.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	var modelMachine types.Machine
+	modelMachine.FromEntity(entityMachine)
+	return &modelMachine, nil
+}
+
+// ListMachineEvents retrieves the most recent events of a machine, newest first.
+func (m *machineClient) ListMachineEvents(systemID string, limit int) ([]types.MachineEvent, error) {
+	params := &entity.EventParams{ID: systemID}
+	if limit > 0 {
+		params.Limit = strconv.Itoa(limit)
+	}
+
+	var eventsResp *entity.EventsResp
+	operation := func() error {
+		var err error
+		eventsResp, err = m.client.Events.Get(params)
+		if err != nil {
+			m.logger.Errorf("MAAS API error listing events of machine %s: %v", systemID, err)
+			return fmt.Errorf("maas API error listing events of machine %s: %w", systemID, err)
+		}
+		return nil
+	}
+
+	err := m.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]types.MachineEvent, len(eventsResp.Events))
+	for i := range eventsResp.Events {
+		events[i].FromEntity(&eventsResp.Events[i])
+	}
+	return events, nil
+}
+
 // PowerOnMachine powers on a machine.
 func (m *machineClient) PowerOnMachine(systemID string) (*types.Machine, error) {
 	var entityMachine *entity.Machine
@@ -249,16 +304,88 @@ func (m *machineClient) GetMachineWithDetails(ctx context.Context, systemID stri
 	return m.GetMachine(systemID)
 }
 
-// CheckStorageConstraints checks if a machine meets the specified storage constraints.
-// This method's placement on MachineClient is for convenience.
-// The actual MAAS API for constraint validation is likely via StorageClient.ValidateStorageConstraints.
-// For ClientWrapper, this can be a simple pass-through or a local check if feasible.
-// For now, returning true to satisfy interface. Actual logic might be in the service layer or delegate to StorageClient.
+// CheckStorageConstraints checks if a machine's physical disks satisfy the storage constraints.
+// Block devices are fetched from MAAS when the machine was loaded without them.
 func (m *machineClient) CheckStorageConstraints(machine *types.Machine, constraints *types.SimpleStorageConstraint) bool {
-	// Placeholder implementation.
-	// A real implementation might involve calling a MAAS endpoint if one exists for this specific check,
-	// or performing a local evaluation based on machine.BlockDevices and constraints.
-	// Given StorageClient.ValidateStorageConstraints, this might be redundant or for a different purpose.
-	m.logger.Warnf("CheckStorageConstraints on machineClient is a placeholder and currently returns true.")
-	return true
+	if machine == nil || constraints == nil {
+		return true
+	}
+	return m.EvaluateStorageConstraints(machine, []types.SimpleStorageConstraint{*constraints}).Matched
+}
+
+// EvaluateStorageConstraints evaluates each constraint as a separate disk requirement against the
+// machine's physical disks and reports which disks matched. Block devices are fetched from MAAS
+// when the machine was loaded without them.
+func (m *machineClient) EvaluateStorageConstraints(machine *types.Machine, constraints []types.SimpleStorageConstraint) *models.StorageMatchReport {
+	devices := machine.BlockDevices
+	if len(devices) == 0 && machine.SystemID != "" {
+		var entityDevices []entity.BlockDevice
+		operation := func() error {
+			var err error
+			entityDevices, err = m.client.BlockDevices.Get(machine.SystemID)
+			return err
+		}
+		if err := m.retry(operation, 3, 2*time.Second); err != nil {
+			m.logger.WithError(err).WithField("system_id", machine.SystemID).Warn("Failed to load block devices for storage constraint check")
+		}
+		for i := range entityDevices {
+			var device types.BlockDevice
+			device.FromEntity(&entityDevices[i])
+			devices = append(devices, device)
+		}
+	}
+
+	requirements := make([]models.SimpleStorageConstraint, len(constraints))
+	for i := range constraints {
+		requirements[i] = simpleStorageConstraint(&constraints[i])
+	}
+	report := models.EvaluateSimpleStorageConstraints(storageConstraintMachine(machine, devices), requirements...)
+
+	m.logger.WithFields(logrus.Fields{
+		"system_id": machine.SystemID,
+		"matched":   report.Matched,
+		"summary":   report.Summary,
+	}).Debug("Evaluated storage constraints")
+
+	return report
+}
+
+// storageConstraintMachine copies the fields the storage constraint evaluator needs into a models.Machine.
+func storageConstraintMachine(machine *types.Machine, devices []types.BlockDevice) *models.Machine {
+	result := &models.Machine{
+		SystemID:     machine.SystemID,
+		Hostname:     machine.Hostname,
+		BlockDevices: make([]models.BlockDevice, len(devices)),
+	}
+	for i, device := range devices {
+		result.BlockDevices[i] = models.BlockDevice{
+			ID:            device.ID,
+			Name:          device.Name,
+			Type:          device.Type,
+			Path:          device.Path,
+			Size:          device.Size,
+			UsedSize:      device.UsedSize,
+			AvailableSize: device.AvailableSize,
+			Model:         device.Model,
+			Serial:        device.Serial,
+			IDPath:        device.IDPath,
+			Tags:          device.Tags,
+		}
+	}
+	return result
+}
+
+// simpleStorageConstraint converts the types constraint, whose tags are comma separated.
+func simpleStorageConstraint(constraints *types.SimpleStorageConstraint) models.SimpleStorageConstraint {
+	result := models.SimpleStorageConstraint{
+		MinSize: constraints.MinSize,
+		MaxSize: constraints.MaxSize,
+		Count:   constraints.Count,
+	}
+	for _, tag := range strings.Split(constraints.Tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			result.Tags = append(result.Tags, tag)
+		}
+	}
+	return result
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/canonical/gomaasclient/client"
 	"github.com/canonical/gomaasclient/entity"
+	entitysubnet "github.com/canonical/gomaasclient/entity/subnet"
 	"github.com/sirupsen/logrus"
 
 	"github.com/lspecian/maas-mcp-server/internal/maas/common"
@@ -76,6 +77,54 @@ func (n *networkClient) ListSubnets() ([]types.Subnet, error) {
 	return modelSubnets, nil
 }
 
+// GetSubnetStatistics retrieves the address usage MAAS reports for a subnet.
+func (n *networkClient) GetSubnetStatistics(id int) (*types.SubnetStatistics, error) {
+	var entityStats *entitysubnet.Statistics
+	operation := func() error {
+		var err error
+		entityStats, err = n.client.Subnet.GetStatistics(id)
+		if err != nil {
+			n.logger.Errorf("MAAS API error getting statistics of subnet %d: %v", id, err)
+			return fmt.Errorf("maas API error getting statistics of subnet %d: %w", id, err)
+		}
+		return nil
+	}
+
+	err := n.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	var modelStats types.SubnetStatistics
+	modelStats.FromEntity(entityStats)
+	return &modelStats, nil
+}
+
+// GetSubnetIPAddresses retrieves the addresses in use in a subnet.
+func (n *networkClient) GetSubnetIPAddresses(id int) ([]types.SubnetIPAddress, error) {
+	var entityAddresses []entitysubnet.IPAddress
+	operation := func() error {
+		var err error
+		entityAddresses, err = n.client.Subnet.GetIPAddresses(id)
+		if err != nil {
+			n.logger.Errorf("MAAS API error getting IP addresses of subnet %d: %v", id, err)
+			return fmt.Errorf("maas API error getting IP addresses of subnet %d: %w", id, err)
+		}
+		return nil
+	}
+
+	err := n.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	modelAddresses := make([]types.SubnetIPAddress, len(entityAddresses))
+	for i, ea := range entityAddresses {
+		var ma types.SubnetIPAddress
+		ma.FromEntity(&ea)
+		modelAddresses[i] = ma
+	}
+	return modelAddresses, nil
+}
+
 // ListVLANs retrieves VLANs for a specific fabric.
 func (n *networkClient) ListVLANs(fabricID int) ([]types.VLAN, error) {
 	var entityVLANs []entity.VLAN
@@ -0,0 +1,137 @@
+package maas
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/canonical/gomaasclient/client"
+	"github.com/canonical/gomaasclient/entity"
+	"github.com/sirupsen/logrus"
+
+	"github.com/lspecian/maas-mcp-server/internal/maas/common"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// resourcePoolClient implements the common.ResourcePoolClient interface
+type resourcePoolClient struct {
+	client *client.Client
+	logger *logrus.Logger
+	retry  common.RetryFunc
+}
+
+// newResourcePoolClient creates a new resource pool client
+func newResourcePoolClient(client *client.Client, logger *logrus.Logger, retry common.RetryFunc) common.ResourcePoolClient {
+	return &resourcePoolClient{
+		client: client,
+		logger: logger,
+		retry:  retry,
+	}
+}
+
+// ListResourcePools retrieves all resource pools.
+func (p *resourcePoolClient) ListResourcePools() ([]types.ResourcePool, error) {
+	var entityResourcePools []entity.ResourcePool
+	operation := func() error {
+		var err error
+		entityResourcePools, err = p.client.ResourcePools.Get()
+		if err != nil {
+			p.logger.Errorf("MAAS API error listing resource pools: %v", err)
+			return fmt.Errorf("MAAS API error listing resource pools: %w", err)
+		}
+		return nil
+	}
+
+	err := p.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	modelResourcePools := make([]types.ResourcePool, len(entityResourcePools))
+	for i, e := range entityResourcePools {
+		var m types.ResourcePool
+		m.FromEntity(&e)
+		modelResourcePools[i] = m
+	}
+	return modelResourcePools, nil
+}
+
+// GetResourcePool retrieves a resource pool by name.
+func (p *resourcePoolClient) GetResourcePool(name string) (*types.ResourcePool, error) {
+	var entityResourcePool *entity.ResourcePool
+	operation := func() error {
+		var err error
+		entityResourcePool, err = p.client.ResourcePool.GetByName(name)
+		if err != nil {
+			p.logger.Errorf("MAAS API error getting resource pool '%s': %v", name, err)
+			return fmt.Errorf("MAAS API error getting resource pool '%s': %w", name, err)
+		}
+		return nil
+	}
+
+	err := p.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelResourcePool types.ResourcePool
+	modelResourcePool.FromEntity(entityResourcePool)
+	return &modelResourcePool, nil
+}
+
+// CreateResourcePool creates a new resource pool.
+func (p *resourcePoolClient) CreateResourcePool(params *entity.ResourcePoolParams) (*types.ResourcePool, error) {
+	var entityResourcePool *entity.ResourcePool
+	operation := func() error {
+		var err error
+		entityResourcePool, err = p.client.ResourcePools.Create(params)
+		if err != nil {
+			p.logger.Errorf("MAAS API error creating resource pool '%s': %v", params.Name, err)
+			return fmt.Errorf("MAAS API error creating resource pool '%s': %w", params.Name, err)
+		}
+		return nil
+	}
+
+	err := p.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelResourcePool types.ResourcePool
+	modelResourcePool.FromEntity(entityResourcePool)
+	return &modelResourcePool, nil
+}
+
+// UpdateResourcePool updates the name or description of a resource pool; empty fields in params are left unchanged.
+func (p *resourcePoolClient) UpdateResourcePool(name string, params *entity.ResourcePoolParams) (*types.ResourcePool, error) {
+	var entityResourcePool *entity.ResourcePool
+	operation := func() error {
+		var err error
+		entityResourcePool, err = p.client.ResourcePool.UpdateByName(name, params)
+		if err != nil {
+			p.logger.Errorf("MAAS API error updating resource pool '%s': %v", name, err)
+			return fmt.Errorf("MAAS API error updating resource pool '%s': %w", name, err)
+		}
+		return nil
+	}
+
+	err := p.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelResourcePool types.ResourcePool
+	modelResourcePool.FromEntity(entityResourcePool)
+	return &modelResourcePool, nil
+}
+
+// DeleteResourcePool deletes a resource pool.
+func (p *resourcePoolClient) DeleteResourcePool(name string) error {
+	operation := func() error {
+		if err := p.client.ResourcePool.DeleteByName(name); err != nil {
+			p.logger.Errorf("MAAS API error deleting resource pool '%s': %v", name, err)
+			return fmt.Errorf("MAAS API error deleting resource pool '%s': %w", name, err)
+		}
+		return nil
+	}
+	return p.retry(operation, 3, 2*time.Second)
+}
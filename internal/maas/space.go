@@ -0,0 +1,118 @@
+package maas
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/canonical/gomaasclient/entity"
+
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// ListSpaces retrieves all spaces.
+func (n *networkClient) ListSpaces() ([]types.Space, error) {
+	var entitySpaces []entity.Space
+	operation := func() error {
+		var err error
+		entitySpaces, err = n.client.Spaces.Get()
+		if err != nil {
+			n.logger.Errorf("MAAS API error listing spaces: %v", err)
+			return fmt.Errorf("MAAS API error listing spaces: %w", err)
+		}
+		return nil
+	}
+
+	err := n.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	modelSpaces := make([]types.Space, len(entitySpaces))
+	for i, e := range entitySpaces {
+		var m types.Space
+		m.FromEntity(&e)
+		modelSpaces[i] = m
+	}
+	return modelSpaces, nil
+}
+
+// GetSpace retrieves a space, with its subnets and VLANs.
+func (n *networkClient) GetSpace(id int) (*types.Space, error) {
+	var entitySpace *entity.Space
+	operation := func() error {
+		var err error
+		entitySpace, err = n.client.Space.Get(id)
+		if err != nil {
+			n.logger.Errorf("MAAS API error getting space %d: %v", id, err)
+			return fmt.Errorf("MAAS API error getting space %d: %w", id, err)
+		}
+		return nil
+	}
+
+	err := n.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelSpace types.Space
+	modelSpace.FromEntity(entitySpace)
+	return &modelSpace, nil
+}
+
+// CreateSpace creates a new space.
+func (n *networkClient) CreateSpace(name string) (*types.Space, error) {
+	var entitySpace *entity.Space
+	operation := func() error {
+		var err error
+		entitySpace, err = n.client.Spaces.Create(name)
+		if err != nil {
+			n.logger.Errorf("MAAS API error creating space '%s': %v", name, err)
+			return fmt.Errorf("MAAS API error creating space '%s': %w", name, err)
+		}
+		return nil
+	}
+
+	err := n.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelSpace types.Space
+	modelSpace.FromEntity(entitySpace)
+	return &modelSpace, nil
+}
+
+// UpdateSpace renames a space.
+func (n *networkClient) UpdateSpace(id int, name string) (*types.Space, error) {
+	var entitySpace *entity.Space
+	operation := func() error {
+		var err error
+		entitySpace, err = n.client.Space.Update(id, name)
+		if err != nil {
+			n.logger.Errorf("MAAS API error updating space %d: %v", id, err)
+			return fmt.Errorf("MAAS API error updating space %d: %w", id, err)
+		}
+		return nil
+	}
+
+	err := n.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelSpace types.Space
+	modelSpace.FromEntity(entitySpace)
+	return &modelSpace, nil
+}
+
+// DeleteSpace deletes a space.
+func (n *networkClient) DeleteSpace(id int) error {
+	operation := func() error {
+		if err := n.client.Space.Delete(id); err != nil {
+			n.logger.Errorf("MAAS API error deleting space %d: %v", id, err)
+			return fmt.Errorf("MAAS API error deleting space %d: %w", id, err)
+		}
+		return nil
+	}
+	return n.retry(operation, 3, 2*time.Second)
+}
@@ -0,0 +1,72 @@
+package maas
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/canonical/gomaasclient/entity"
+
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// ListStaticRoutes retrieves all static routes.
+func (n *networkClient) ListStaticRoutes() ([]types.StaticRoute, error) {
+	var entityRoutes []entity.StaticRoute
+	operation := func() error {
+		var err error
+		entityRoutes, err = n.client.StaticRoutes.Get()
+		if err != nil {
+			n.logger.Errorf("MAAS API error listing static routes: %v", err)
+			return fmt.Errorf("MAAS API error listing static routes: %w", err)
+		}
+		return nil
+	}
+
+	err := n.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	modelRoutes := make([]types.StaticRoute, len(entityRoutes))
+	for i, e := range entityRoutes {
+		var m types.StaticRoute
+		m.FromEntity(&e)
+		modelRoutes[i] = m
+	}
+	return modelRoutes, nil
+}
+
+// CreateStaticRoute creates a new static route.
+func (n *networkClient) CreateStaticRoute(params *entity.StaticRouteParams) (*types.StaticRoute, error) {
+	var entityRoute *entity.StaticRoute
+	operation := func() error {
+		var err error
+		entityRoute, err = n.client.StaticRoutes.Create(params)
+		if err != nil {
+			n.logger.Errorf("MAAS API error creating static route from %s to %s: %v", params.Source, params.Destination, err)
+			return fmt.Errorf("MAAS API error creating static route from %s to %s: %w", params.Source, params.Destination, err)
+		}
+		return nil
+	}
+
+	err := n.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelRoute types.StaticRoute
+	modelRoute.FromEntity(entityRoute)
+	return &modelRoute, nil
+}
+
+// DeleteStaticRoute deletes a static route.
+func (n *networkClient) DeleteStaticRoute(id int) error {
+	operation := func() error {
+		if err := n.client.StaticRoute.Delete(id); err != nil {
+			n.logger.Errorf("MAAS API error deleting static route %d: %v", id, err)
+			return fmt.Errorf("MAAS API error deleting static route %d: %w", id, err)
+		}
+		return nil
+	}
+	return n.retry(operation, 3, 2*time.Second)
+}
@@ -0,0 +1,137 @@
+package maas
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/canonical/gomaasclient/client"
+	"github.com/canonical/gomaasclient/entity"
+	"github.com/sirupsen/logrus"
+
+	"github.com/lspecian/maas-mcp-server/internal/maas/common"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// zoneClient implements the common.ZoneClient interface
+type zoneClient struct {
+	client *client.Client
+	logger *logrus.Logger
+	retry  common.RetryFunc
+}
+
+// newZoneClient creates a new zone client
+func newZoneClient(client *client.Client, logger *logrus.Logger, retry common.RetryFunc) common.ZoneClient {
+	return &zoneClient{
+		client: client,
+		logger: logger,
+		retry:  retry,
+	}
+}
+
+// ListZones retrieves all zones.
+func (z *zoneClient) ListZones() ([]types.Zone, error) {
+	var entityZones []entity.Zone
+	operation := func() error {
+		var err error
+		entityZones, err = z.client.Zones.Get()
+		if err != nil {
+			z.logger.Errorf("MAAS API error listing zones: %v", err)
+			return fmt.Errorf("MAAS API error listing zones: %w", err)
+		}
+		return nil
+	}
+
+	err := z.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	modelZones := make([]types.Zone, len(entityZones))
+	for i, e := range entityZones {
+		var m types.Zone
+		m.FromEntity(&e)
+		modelZones[i] = m
+	}
+	return modelZones, nil
+}
+
+// GetZone retrieves a zone by name.
+func (z *zoneClient) GetZone(name string) (*types.Zone, error) {
+	var entityZone *entity.Zone
+	operation := func() error {
+		var err error
+		entityZone, err = z.client.Zone.Get(name)
+		if err != nil {
+			z.logger.Errorf("MAAS API error getting zone '%s': %v", name, err)
+			return fmt.Errorf("MAAS API error getting zone '%s': %w", name, err)
+		}
+		return nil
+	}
+
+	err := z.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelZone types.Zone
+	modelZone.FromEntity(entityZone)
+	return &modelZone, nil
+}
+
+// CreateZone creates a new zone.
+func (z *zoneClient) CreateZone(params *entity.ZoneParams) (*types.Zone, error) {
+	var entityZone *entity.Zone
+	operation := func() error {
+		var err error
+		entityZone, err = z.client.Zones.Create(params)
+		if err != nil {
+			z.logger.Errorf("MAAS API error creating zone '%s': %v", params.Name, err)
+			return fmt.Errorf("MAAS API error creating zone '%s': %w", params.Name, err)
+		}
+		return nil
+	}
+
+	err := z.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelZone types.Zone
+	modelZone.FromEntity(entityZone)
+	return &modelZone, nil
+}
+
+// UpdateZone updates the name or description of a zone; empty fields in params are left unchanged.
+func (z *zoneClient) UpdateZone(name string, params *entity.ZoneParams) (*types.Zone, error) {
+	var entityZone *entity.Zone
+	operation := func() error {
+		var err error
+		entityZone, err = z.client.Zone.Update(name, params)
+		if err != nil {
+			z.logger.Errorf("MAAS API error updating zone '%s': %v", name, err)
+			return fmt.Errorf("MAAS API error updating zone '%s': %w", name, err)
+		}
+		return nil
+	}
+
+	err := z.retry(operation, 3, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelZone types.Zone
+	modelZone.FromEntity(entityZone)
+	return &modelZone, nil
+}
+
+// DeleteZone deletes a zone.
+func (z *zoneClient) DeleteZone(name string) error {
+	operation := func() error {
+		if err := z.client.Zone.Delete(name); err != nil {
+			z.logger.Errorf("MAAS API error deleting zone '%s': %v", name, err)
+			return fmt.Errorf("MAAS API error deleting zone '%s': %w", name, err)
+		}
+		return nil
+	}
+	return z.retry(operation, 3, 2*time.Second)
+}
@@ -0,0 +1,328 @@
+package maasclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// apiPrefixPattern matches the versioned API root that generated tool paths carry,
+// e.g. "/api/2.0/" or "/MAAS/api/2.0/". The raw client already points at that root.
+var apiPrefixPattern = regexp.MustCompile(`^/*(?:MAAS/)?api/\d+\.\d+/?`)
+
+// APIError is returned by CallAPI when MAAS answers with a non-2xx status.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+// Error returns the error message
+func (e *APIError) Error() string {
+	if e.Body != "" {
+		return fmt.Sprintf("MAAS API %s %s failed with status %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("MAAS API %s %s failed with status %d", e.Method, e.Path, e.StatusCode)
+}
+
+// CallAPI issues an OAuth1-signed request against the MAAS API.
+//
+// apiPath is an escaped MAAS resource path such as "/api/2.0/machines/abc123/op-deploy";
+// a trailing "op-<name>" segment (or an "op" parameter) selects a MAAS operation.
+// Escaped segments such as "tags/my%20tag" are sent as given, not escaped again.
+// A query in apiPath is sent as is, whatever the method.
+// requestBody is a JSON object whose fields are sent as query parameters for
+// GET and DELETE, and as multipart form fields for POST and PUT.
+// JSON responses are decoded, text responses are returned as strings, and binary
+// responses are returned base64 encoded.
+func (c *MaasClient) CallAPI(ctx context.Context, httpMethod string, apiPath string, requestBody json.RawMessage) (interface{}, error) {
+	if c.rawClient == nil {
+		return nil, fmt.Errorf("MAAS client not initialized")
+	}
+
+	method := strings.ToUpper(httpMethod)
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete:
+	default:
+		return nil, fmt.Errorf("unsupported HTTP method %q", httpMethod)
+	}
+
+	params, err := decodeAPIParams(requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resourcePath, query, err := resolveAPIPath(apiPath)
+	if err != nil {
+		return nil, err
+	}
+	if values, ok := params["op"]; ok {
+		if query.Get("op") == "" && len(values) > 0 {
+			query.Set("op", values[0])
+		}
+		delete(params, "op")
+	}
+	op := query.Get("op")
+
+	request, err := c.newAPIRequest(ctx, method, resourcePath, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	c.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"http_method": method,
+		"api_path":    resourcePath,
+		"op":          op,
+	}).Debug("Calling MAAS API")
+
+	if err := c.rawClient.Signer.OAuthSign(request); err != nil {
+		return nil, fmt.Errorf("failed to sign MAAS API request: %w", err)
+	}
+
+	httpClient := c.rawClient.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		c.logger.WithError(err).Errorf("MAAS API %s %s failed", method, resourcePath)
+		return nil, fmt.Errorf("failed to call MAAS API %s %s: %w", method, resourcePath, err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MAAS API response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		apiErr := &APIError{
+			Method:     method,
+			Path:       resourcePath,
+			StatusCode: response.StatusCode,
+			Body:       strings.TrimSpace(string(body)),
+		}
+		c.logger.WithFields(logrus.Fields{
+			"http_method": method,
+			"api_path":    resourcePath,
+			"status_code": response.StatusCode,
+		}).Warn("MAAS API returned an error status")
+		return nil, apiErr
+	}
+
+	return decodeAPIResponse(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+// newAPIRequest builds the HTTP request for a MAAS resource path relative to the API root.
+// The parameters are added to the query for GET and DELETE, and sent as the body otherwise.
+func (c *MaasClient) newAPIRequest(ctx context.Context, method, resourcePath string, query, params url.Values) (*http.Request, error) {
+	path, err := url.PathUnescape(resourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API path %q: %w", resourcePath, err)
+	}
+	// RawPath keeps escaped slashes and the like intact when the URL is written out
+	target := c.rawClient.GetURL(&url.URL{Path: path, RawPath: resourcePath})
+
+	var body io.Reader
+	contentType := ""
+
+	switch method {
+	case http.MethodGet, http.MethodDelete:
+		for key, values := range params {
+			query[key] = append(query[key], values...)
+		}
+	default:
+		buf := &bytes.Buffer{}
+		writer := multipart.NewWriter(buf)
+
+		// Sort keys so the request body is deterministic
+		keys := make([]string, 0, len(params))
+		for key := range params {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			for _, value := range params[key] {
+				if err := writer.WriteField(key, value); err != nil {
+					return nil, fmt.Errorf("failed to encode form field %q: %w", key, err)
+				}
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encode form body: %w", err)
+		}
+		body = buf
+		contentType = writer.FormDataContentType()
+	}
+
+	target.RawQuery = query.Encode()
+
+	request, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MAAS API request: %w", err)
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	request.Header.Set("Accept", "application/json")
+
+	return request, nil
+}
+
+// resolveAPIPath strips the versioned API prefix from apiPath and splits off its query
+// and a trailing "op-<name>" segment, which becomes the "op" query parameter. Empty and
+// dot segments are rejected. The returned path is still escaped and always ends in a
+// slash, as MAAS expects.
+func resolveAPIPath(apiPath string) (string, url.Values, error) {
+	query := url.Values{}
+	if idx := strings.Index(apiPath, "?"); idx >= 0 {
+		var err error
+		if query, err = url.ParseQuery(apiPath[idx+1:]); err != nil {
+			return "", nil, fmt.Errorf("invalid query in API path %q: %w", apiPath, err)
+		}
+		apiPath = apiPath[:idx]
+	}
+
+	path := apiPrefixPattern.ReplaceAllString(apiPath, "")
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return "", nil, fmt.Errorf("API path %q does not name a MAAS resource", apiPath)
+	}
+
+	segments := strings.Split(path, "/")
+	last := segments[len(segments)-1]
+	if strings.HasPrefix(last, "op-") {
+		query.Set("op", strings.TrimPrefix(last, "op-"))
+		segments = segments[:len(segments)-1]
+	}
+
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			return "", nil, fmt.Errorf("unresolved path parameter %s in API path %q", segment, apiPath)
+		}
+		value, err := url.PathUnescape(segment)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid segment %q in API path %q: %w", segment, apiPath, err)
+		}
+		if value == "" || value == "." || value == ".." {
+			return "", nil, fmt.Errorf("empty or dot segment in API path %q", apiPath)
+		}
+	}
+
+	if len(segments) == 0 {
+		return "", query, nil
+	}
+	return strings.Join(segments, "/") + "/", query, nil
+}
+
+// decodeAPIParams turns the JSON object of tool parameters into MAAS request values.
+// Arrays become repeated keys, nested objects are sent as JSON strings and nulls are dropped.
+func decodeAPIParams(requestBody json.RawMessage) (url.Values, error) {
+	values := url.Values{}
+
+	trimmed := bytes.TrimSpace(requestBody)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return values, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+
+	var params map[string]interface{}
+	if err := decoder.Decode(&params); err != nil {
+		return nil, fmt.Errorf("request parameters must be a JSON object: %w", err)
+	}
+
+	for key, value := range params {
+		if err := addAPIParamValue(values, key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// addAPIParamValue appends the string form of value to values[key].
+func addAPIParamValue(values url.Values, key string, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		values.Add(key, v)
+	case bool:
+		values.Add(key, strconv.FormatBool(v))
+	case json.Number:
+		values.Add(key, v.String())
+	case []interface{}:
+		for _, item := range v {
+			if err := addAPIParamValue(values, key, item); err != nil {
+				return err
+			}
+		}
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode parameter %q: %w", key, err)
+		}
+		values.Add(key, string(encoded))
+	}
+	return nil
+}
+
+// decodeAPIResponse converts a MAAS response body into a value a tool can return.
+func decodeAPIResponse(statusCode int, contentType string, body []byte) (interface{}, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return map[string]interface{}{
+			"status_code": statusCode,
+			"status":      http.StatusText(statusCode),
+		}, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = ""
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var result interface{}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to decode MAAS API response: %w", err)
+		}
+		return result, nil
+	case strings.HasPrefix(mediaType, "text/"):
+		return string(body), nil
+	case mediaType == "" && json.Valid(body):
+		var result interface{}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to decode MAAS API response: %w", err)
+		}
+		return result, nil
+	default:
+		if mediaType == "" {
+			mediaType = "application/octet-stream"
+		}
+		return map[string]interface{}{
+			"content_type": mediaType,
+			"encoding":     "base64",
+			"size":         len(body),
+			"data":         base64.StdEncoding.EncodeToString(body),
+		}, nil
+	}
+}
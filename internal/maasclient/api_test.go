@@ -0,0 +1,220 @@
+package maasclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/lspecian/maas-mcp-server/internal/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAPIClient(t *testing.T, handler http.HandlerFunc) *MaasClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	t.Setenv("MAAS_STORAGE_CONSTRAINTS_FILE", filepath.Join(t.TempDir(), "storage_constraints.json"))
+
+	cfg := &models.AppConfig{
+		MAASInstances: map[string]models.MAASInstanceConfig{
+			"default": {
+				APIURL: server.URL + "/MAAS",
+				APIKey: "consumer:token:secret",
+			},
+		},
+	}
+
+	client, err := NewMaasClient(cfg, logrus.New())
+	require.NoError(t, err)
+	return client
+}
+
+func TestCallAPIGetSendsQueryParameters(t *testing.T) {
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/MAAS/api/2.0/machines/", r.URL.Path)
+		assert.Equal(t, []string{"node-1", "node-2"}, r.URL.Query()["hostname"])
+		assert.Equal(t, "5", r.URL.Query().Get("limit"))
+		assert.Contains(t, r.Header.Get("Authorization"), `oauth_signature_method="PLAINTEXT"`)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"system_id":"abc123"}]`))
+	})
+
+	result, err := client.CallAPI(context.Background(), "get", "/api/2.0/machines", json.RawMessage(`{"hostname":["node-1","node-2"],"limit":5}`))
+	require.NoError(t, err)
+
+	machines, ok := result.([]interface{})
+	require.True(t, ok)
+	assert.Len(t, machines, 1)
+}
+
+func TestCallAPIPostOperationUsesMultipartForm(t *testing.T) {
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/MAAS/api/2.0/machines/abc123/", r.URL.Path)
+		assert.Equal(t, "deploy", r.URL.Query().Get("op"))
+
+		assert.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, []string{"jammy"}, r.MultipartForm.Value["distro_series"])
+		assert.Equal(t, []string{"true"}, r.MultipartForm.Value["install_kvm"])
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"system_id":"abc123","status_name":"Deploying"}`))
+	})
+
+	result, err := client.CallAPI(context.Background(), "POST", "/api/2.0/machines/abc123/op-deploy", json.RawMessage(`{"distro_series":"jammy","install_kvm":true}`))
+	require.NoError(t, err)
+
+	machine, ok := result.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "Deploying", machine["status_name"])
+}
+
+func TestCallAPIPostKeepsQueryParameters(t *testing.T) {
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/MAAS/api/2.0/machines/abc123/", r.URL.Path)
+		assert.Equal(t, url.Values{"op": {"release"}, "force": {"true"}}, r.URL.Query())
+
+		assert.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, []string{"done"}, r.MultipartForm.Value["comment"])
+		assert.NotContains(t, r.MultipartForm.Value, "force")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	})
+
+	_, err := client.CallAPI(context.Background(), "POST", "/api/2.0/machines/abc123/op-release?force=true", json.RawMessage(`{"comment":"done"}`))
+	require.NoError(t, err)
+}
+
+func TestCallAPIOperationFromParameters(t *testing.T) {
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "power_parameters", r.URL.Query().Get("op"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	})
+
+	_, err := client.CallAPI(context.Background(), "GET", "/MAAS/api/2.0/machines/", json.RawMessage(`{"op":"power_parameters"}`))
+	require.NoError(t, err)
+}
+
+func TestCallAPIResponseDecoding(t *testing.T) {
+	t.Run("text", func(t *testing.T) {
+		client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte("ssh-rsa AAAA"))
+		})
+
+		result, err := client.CallAPI(context.Background(), "GET", "/api/2.0/account/prefs/sshkeys/1", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "ssh-rsa AAAA", result)
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte{0x00, 0x01, 0x02})
+		})
+
+		result, err := client.CallAPI(context.Background(), "GET", "/api/2.0/files/op-get", json.RawMessage(`{"filename":"blob"}`))
+		require.NoError(t, err)
+
+		file, ok := result.(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "base64", file["encoding"])
+		assert.Equal(t, "AAEC", file["data"])
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodDelete, r.Method)
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		result, err := client.CallAPI(context.Background(), "DELETE", "/api/2.0/tags/fast", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, result.(map[string]interface{})["status_code"])
+	})
+}
+
+func TestCallAPIErrorStatus(t *testing.T) {
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("No Machine matches the given query."))
+	})
+
+	_, err := client.CallAPI(context.Background(), "GET", "/api/2.0/machines/missing", nil)
+	require.Error(t, err)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "No Machine matches the given query.", apiErr.Body)
+}
+
+func TestCallAPIDoesNotEscapePathTwice(t *testing.T) {
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/MAAS/api/2.0/tags/my%20tag%2Fx/", r.URL.EscapedPath())
+		assert.Equal(t, "/MAAS/api/2.0/tags/my tag/x/", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"my tag/x"}`))
+	})
+
+	result, err := client.CallAPI(context.Background(), "GET", "/api/2.0/tags/my%20tag%2Fx/", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "my tag/x"}, result)
+}
+
+func TestCallAPIRejectsInvalidInput(t *testing.T) {
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL)
+	})
+
+	_, err := client.CallAPI(context.Background(), "PATCH", "/api/2.0/machines/", nil)
+	assert.Error(t, err)
+
+	_, err = client.CallAPI(context.Background(), "GET", "/api/2.0/machines/{system_id}", nil)
+	assert.ErrorContains(t, err, "unresolved path parameter")
+
+	_, err = client.CallAPI(context.Background(), "GET", "/api/2.0/machines/", json.RawMessage(`["not","an","object"]`))
+	assert.Error(t, err)
+
+	for _, apiPath := range []string{
+		"/api/2.0/machines//op-deploy",
+		"/api/2.0/machines/../users/",
+		"/api/2.0/machines/%2E%2E/users/",
+		"/api/2.0/machines/./",
+	} {
+		_, err = client.CallAPI(context.Background(), "POST", apiPath, nil)
+		assert.ErrorContains(t, err, "empty or dot segment", apiPath)
+	}
+}
+
+func TestResolveAPIPath(t *testing.T) {
+	tests := []struct {
+		apiPath       string
+		expectedPath  string
+		expectedQuery url.Values
+	}{
+		{"/api/2.0/machines", "machines/", url.Values{}},
+		{"/MAAS/api/2.0/machines/abc/", "machines/abc/", url.Values{}},
+		{"/api/2.0/account/op-create_authorisation_token", "account/", url.Values{"op": {"create_authorisation_token"}}},
+		{"/api/2.0/machines/?op=allocate", "machines/", url.Values{"op": {"allocate"}}},
+		{"/api/2.0/machines/abc/op-release?force=true", "machines/abc/", url.Values{"op": {"release"}, "force": {"true"}}},
+	}
+
+	for _, tt := range tests {
+		path, query, err := resolveAPIPath(tt.apiPath)
+		require.NoError(t, err, tt.apiPath)
+		assert.Equal(t, tt.expectedPath, path, tt.apiPath)
+		assert.Equal(t, tt.expectedQuery, query, tt.apiPath)
+	}
+}
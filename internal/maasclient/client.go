@@ -3,11 +3,13 @@ package maasclient
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	gomaasclient "github.com/canonical/gomaasclient/client"
 	"github.com/canonical/gomaasclient/entity"
+	gomaasapi "github.com/juju/gomaasapi/v2"
 	"github.com/sirupsen/logrus"
 
 	"github.com/lspecian/maas-mcp-server/internal/models"
@@ -15,16 +17,21 @@ import (
 
 // MaasClient provides an abstraction layer over gomaasclient.
 type MaasClient struct {
-	client *gomaasclient.Client
-	logger *logrus.Logger
-	config *models.AppConfig
+	client    *gomaasclient.Client
+	rawClient *gomaasapi.Client // OAuth-signed client used by CallAPI for endpoints gomaasclient doesn't wrap
+	logger    *logrus.Logger
+	config    *models.AppConfig
+
+	instanceName    string          // MAAS instance this client talks to; keys the constraint store
+	constraintStore ConstraintStore // Server-side storage constraints, which MAAS has no endpoint for
 }
 
 // NewMaasClient creates and initializes a new MAAS client.
 // It parses the MAAS API key into consumer key, token, and secret.
 func NewMaasClient(cfg *models.AppConfig, logger *logrus.Logger) (*MaasClient, error) {
 	// Get the default MAAS instance
-	maasInstance := cfg.GetDefaultMAASInstance()
+	instanceName := defaultInstanceName(cfg)
+	maasInstance, _ := cfg.GetMAASInstance(instanceName)
 
 	// Check if we have a valid MAAS instance
 	if maasInstance.APIURL == "" || maasInstance.APIKey == "" {
@@ -43,13 +50,49 @@ func NewMaasClient(cfg *models.AppConfig, logger *logrus.Logger) (*MaasClient, e
 		return nil, fmt.Errorf("failed to initialize MAAS client: %w", err)
 	}
 
+	rawClient, err := gomaasapi.NewAuthenticatedClient(gomaasapi.AddAPIVersionToURL(maasInstance.APIURL, "2.0"), maasInstance.APIKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize MAAS API client: %w", err)
+	}
+
+	constraintStore, err := newDefaultConstraintStore()
+	if err != nil {
+		return nil, err
+	}
+
 	return &MaasClient{
-		client: client,
-		logger: logger,
-		config: cfg,
+		client:          client,
+		rawClient:       rawClient,
+		logger:          logger,
+		config:          cfg,
+		instanceName:    instanceName,
+		constraintStore: constraintStore,
 	}, nil
 }
 
+// defaultInstanceName returns "default" if configured, otherwise the first instance name in sorted order.
+func defaultInstanceName(cfg *models.AppConfig) string {
+	if _, ok := cfg.MAASInstances["default"]; ok {
+		return "default"
+	}
+
+	names := make([]string, 0, len(cfg.MAASInstances))
+	for name := range cfg.MAASInstances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return "default"
+	}
+	return names[0]
+}
+
+// SetConstraintStore replaces the store used for machine storage constraints.
+func (m *MaasClient) SetConstraintStore(store ConstraintStore) {
+	m.constraintStore = store
+}
+
 // PowerOnMachine powers on a machine.
 func (c *MaasClient) PowerOnMachine(systemID string) (*models.Machine, error) {
 	c.logger.WithField("system_id", systemID).Debug("Powering on machine")
@@ -152,10 +195,23 @@ func NewMaasClientForInstance(cfg *models.AppConfig, instanceName string, logger
 		return nil, fmt.Errorf("failed to initialize MAAS client for instance '%s': %w", instanceName, err)
 	}
 
+	rawClient, err := gomaasapi.NewAuthenticatedClient(gomaasapi.AddAPIVersionToURL(maasInstance.APIURL, "2.0"), maasInstance.APIKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize MAAS API client for instance '%s': %w", instanceName, err)
+	}
+
+	constraintStore, err := newDefaultConstraintStore()
+	if err != nil {
+		return nil, err
+	}
+
 	return &MaasClient{
-		client: client,
-		logger: logger,
-		config: cfg,
+		client:          client,
+		rawClient:       rawClient,
+		logger:          logger,
+		config:          cfg,
+		instanceName:    instanceName,
+		constraintStore: constraintStore,
 	}, nil
 }
 
@@ -343,6 +399,41 @@ func (m *MaasClient) DeployMachine(systemID string, params *entity.MachineDeploy
 	return machine, nil
 }
 
+// CommissionMachine starts commissioning a machine.
+func (m *MaasClient) CommissionMachine(systemID string, params *entity.MachineCommissionParams) (*models.Machine, error) {
+	if systemID == "" {
+		return nil, fmt.Errorf("system ID is required")
+	}
+	if params == nil {
+		params = &entity.MachineCommissionParams{}
+	}
+
+	var entityMachine *entity.Machine
+	operation := func() error {
+		var err error
+		m.logger.WithFields(logrus.Fields{
+			"system_id": systemID,
+			"params":    fmt.Sprintf("%+v", params),
+		}).Debug("Commissioning MAAS machine")
+		entityMachine, err = m.client.Machine.Commission(systemID, params)
+		if err != nil {
+			m.logger.WithError(err).WithField("system_id", systemID).Error("Failed to commission MAAS machine")
+			return fmt.Errorf("MAAS API error commissioning machine %s: %w", systemID, err)
+		}
+		return nil
+	}
+
+	if err := m.retry(operation, 3, 1*time.Second); err != nil {
+		return nil, err
+	}
+
+	// Convert entity.Machine to models.Machine
+	machine := &models.Machine{}
+	machine.FromEntity(entityMachine)
+
+	return machine, nil
+}
+
 // ReleaseMachine releases a machine back to the pool.
 func (m *MaasClient) ReleaseMachine(systemIDs []string, comment string) error {
 	if len(systemIDs) == 0 {
@@ -677,22 +768,42 @@ func (m *MaasClient) RemoveTagFromMachine(tagName, systemID string) error {
 	return m.retry(operation, 3, 1*time.Second)
 }
 
-// --- Placeholder methods to satisfy service client interfaces ---
+// ==================== Storage Constraint Evaluation ====================
 
-// CheckStorageConstraints is a placeholder to satisfy service.MachineClient
-// Interface wants: CheckStorageConstraints(*models.Machine, *models.SimpleStorageConstraint) bool
-// CheckStorageConstraints is a placeholder to satisfy service.MachineClient.
-// Interface wants: CheckStorageConstraints(*models.Machine, *models.SimpleStorageConstraint) bool
+// CheckStorageConstraints reports whether a machine's disks satisfy the storage constraint.
 func (m *MaasClient) CheckStorageConstraints(machine *models.Machine, constraint *models.SimpleStorageConstraint) bool {
+	if constraint == nil {
+		return true
+	}
+	return m.EvaluateStorageConstraints(machine, []models.SimpleStorageConstraint{*constraint}).Matched
+}
+
+// EvaluateStorageConstraints evaluates each constraint as a separate disk requirement against the
+// machine's physical disks and returns a report of why the machine matched or not. Block devices
+// are fetched from MAAS when the machine was loaded without them.
+func (m *MaasClient) EvaluateStorageConstraints(machine *models.Machine, constraints []models.SimpleStorageConstraint) *models.StorageMatchReport {
+	if len(machine.BlockDevices) == 0 && machine.SystemID != "" {
+		devices, err := m.GetMachineBlockDevices(machine.SystemID)
+		if err != nil {
+			m.logger.WithError(err).WithField("system_id", machine.SystemID).Warn("Failed to load block devices for storage constraint check")
+		} else {
+			machine.BlockDevices = devices
+		}
+	}
+
+	report := models.EvaluateSimpleStorageConstraints(machine, constraints...)
+
 	m.logger.WithFields(logrus.Fields{
 		"system_id": machine.SystemID,
-		// "constraint": constraint, // constraint is *models.SimpleStorageConstraint; log if models.SimpleStorageConstraint becomes resolvable
-	}).Warn("CheckStorageConstraints called on maasclient.MaasClient - Placeholder. Returns true.")
-	// This signature now matches the interface requirement from compiler error.
-	// Actual implementation would involve checking machine properties against constraint.
-	return true // Simulate valid
+		"matched":   report.Matched,
+		"summary":   report.Summary,
+	}).Debug("Evaluated storage constraints")
+
+	return report
 }
 
+// --- Placeholder methods to satisfy service client interfaces ---
+
 // CreateMachinePartition is a placeholder to satisfy service.StorageClient
 // Interface wants: CreateMachinePartition(string, int, map[string]interface{}) (*models.Partition, error)
 func (m *MaasClient) CreateMachinePartition(systemID string, blockDeviceID int, params map[string]interface{}) (*models.Partition, error) {
@@ -845,48 +956,7 @@ func (m *MaasClient) GetMachineWithDetails(ctx context.Context, systemID string,
 	return simulatedMachine, nil
 }
 
-// Note: Ensure other methods required by service.MachineClient, service.NetworkClient,
-// service.TagClient, service.StorageClient are present in this maasclient.MaasClient.
-// The compiler errors in cmd/server/main.go will guide if more are missing.
-
-// CallAPI is a generic method to make API calls to MAAS.
-// For now, it's a placeholder that logs the request and returns a mock response/status.
-func (c *MaasClient) CallAPI(ctx context.Context, httpMethod string, apiPath string, requestBody json.RawMessage) (interface{}, error) {
-	c.logger.WithContext(ctx).WithFields(logrus.Fields{
-		"http_method": httpMethod,
-		"api_path":    apiPath,
-		"parameters":  string(requestBody), // Logging raw parameters
-	}).Info("MaasClient.CallAPI invoked (Placeholder - actual MAAS API call not yet implemented here)")
-
-	// In a future implementation (Step 8 of the plan), this method would:
-	// 1. Use gomaasclient's generic Get(), Post(), Put(), Delete() methods.
-	//    For example:
-	//    - For GET: c.client.Get(apiPath, url.Values রূপান্তরিত_from_requestBody, &responseStruct)
-	//    - For POST: c.client.Post(apiPath, url.Values রূপান্তরিত_from_requestBody, &responseStruct)
-	//    This requires:
-	//      a. Knowing the expected response structure (`responseStruct`) to unmarshal into.
-	//      b. Converting `requestBody` (json.RawMessage) into `url.Values` or another suitable format
-	//         (like an `io.Reader` for arbitrary JSON bodies if `gomaasclient` supports it, or specific structs).
-	//         The `gomaasclient` typically uses `url.Values` for parameters or specific struct types for bodies.
-
-	// For this placeholder step, return a structured map indicating the call was received.
-	responseData := map[string]interface{}{
-		"status_message":      "Request successfully routed to MaasClient.CallAPI (Placeholder)",
-		"target_maas_action":  fmt.Sprintf("%s %s", httpMethod, apiPath),
-		"parameters_received": string(requestBody), // Echoing back raw params for now
-		"note":                "This specific MAAS API endpoint's logic is not yet implemented beyond this point.",
-	}
-	
-	// Decide whether to return this as a success (nil error) or an error.
-	// Returning it as a successful response allows the MCP client to see the placeholder data.
-	// Returning an error would make it look like a server-side failure for a non-implemented feature.
-	// Let's return success for now to show the data flow.
-	return responseData, nil
-}
-
-// GetVersion retrieves the MAAS API version.
-// It uses the MAASAPIVersion field from the gomaasclient.Client, which is populated
-// during client initialization by querying the /api/version endpoint.
+// GetVersion retrieves the MAAS version from the /version endpoint.
 func (c *MaasClient) GetVersion(ctx context.Context) (interface{}, error) {
 	c.logger.WithContext(ctx).Info("MaasClient.GetVersion invoked")
 
@@ -895,26 +965,19 @@ func (c *MaasClient) GetVersion(ctx context.Context) (interface{}, error) {
 		return nil, fmt.Errorf("MAAS client not initialized")
 	}
 
-	// The gomaasclient.Client.MAASAPIVersion field holds the string version (e.g., "2.0")
-	// that was discovered when the client was created.
-	apiVersion := c.client.MAASAPIVersion
-	if apiVersion == "" {
-		// This case should ideally not happen if NewMaasClient succeeded,
-		// as gomaasclient itself fetches this.
-		c.logger.Warn("MaasClient.GetVersion: MAASAPIVersion field is empty, attempting fallback or returning error")
-		// As a fallback, one might attempt a direct GET here if MAASAPIVersion was unexpectedly empty,
-		// but for now, we'll rely on the initialized value.
-		// If it's critical to always re-fetch, then a direct HTTP GET to "version/" would be needed here.
-		// However, gomaasclient already does this. If it's empty, it means GetClient had an issue or
-		// MAAS returned an empty version string.
-		return nil, fmt.Errorf("MAAS API version could not be determined (MAASAPIVersion field is empty)")
+	version, err := c.client.Version.Get()
+	if err != nil {
+		c.logger.WithError(err).Error("MaasClient.GetVersion: failed to get MAAS version")
+		return nil, fmt.Errorf("failed to get MAAS version: %w", err)
 	}
 
 	responseData := map[string]interface{}{
-		"api_version": apiVersion,
-		"source":      "gomaasclient.MAASAPIVersion field",
+		"api_version":  "2.0",
+		"version":      version.Version,
+		"subversion":   version.Subversion,
+		"capabilities": version.Capabilities,
 	}
 
-	c.logger.WithContext(ctx).WithField("version", apiVersion).Info("Successfully retrieved MAAS API version")
+	c.logger.WithContext(ctx).WithField("version", version.Version).Info("Successfully retrieved MAAS version")
 	return responseData, nil
 }
@@ -0,0 +1,238 @@
+package maasclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/lspecian/maas-mcp-server/internal/models"
+)
+
+// DefaultConstraintStoreFile is where storage constraints are persisted when
+// MAAS_STORAGE_CONSTRAINTS_FILE is not set.
+const DefaultConstraintStoreFile = "data/storage_constraints.json"
+
+// ErrConstraintsNotFound is returned when no storage constraints are stored for a machine.
+var ErrConstraintsNotFound = errors.New("storage constraints not found")
+
+// ConstraintStore persists storage constraints per MAAS instance and machine.
+// MAAS has no storage-constraints endpoint, so the server owns this data.
+type ConstraintStore interface {
+	// Get returns the constraints stored for a machine, or ErrConstraintsNotFound
+	Get(instance, systemID string) (*models.StorageConstraintParams, error)
+
+	// Set stores the constraints for a machine, replacing any existing ones
+	Set(instance, systemID string, params models.StorageConstraintParams) error
+
+	// Delete removes the constraints for a machine, or returns ErrConstraintsNotFound
+	Delete(instance, systemID string) error
+}
+
+// MemoryConstraintStore implements ConstraintStore using an in-memory map
+type MemoryConstraintStore struct {
+	constraints map[string]map[string]models.StorageConstraintParams // instance -> system_id -> params
+	mu          sync.RWMutex
+}
+
+// NewMemoryConstraintStore creates a new in-memory constraint store
+func NewMemoryConstraintStore() *MemoryConstraintStore {
+	return &MemoryConstraintStore{
+		constraints: make(map[string]map[string]models.StorageConstraintParams),
+	}
+}
+
+// Get returns the constraints stored for a machine
+func (s *MemoryConstraintStore) Get(instance, systemID string) (*models.StorageConstraintParams, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	params, ok := s.constraints[instance][systemID]
+	if !ok {
+		return nil, fmt.Errorf("%w for machine %s on instance %s", ErrConstraintsNotFound, systemID, instance)
+	}
+
+	return copyConstraintParams(params), nil
+}
+
+// Set stores the constraints for a machine
+func (s *MemoryConstraintStore) Set(instance, systemID string, params models.StorageConstraintParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.constraints[instance] == nil {
+		s.constraints[instance] = make(map[string]models.StorageConstraintParams)
+	}
+	s.constraints[instance][systemID] = *copyConstraintParams(params)
+
+	return nil
+}
+
+// Delete removes the constraints for a machine
+func (s *MemoryConstraintStore) Delete(instance, systemID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.constraints[instance][systemID]; !ok {
+		return fmt.Errorf("%w for machine %s on instance %s", ErrConstraintsNotFound, systemID, instance)
+	}
+
+	delete(s.constraints[instance], systemID)
+	if len(s.constraints[instance]) == 0 {
+		delete(s.constraints, instance)
+	}
+
+	return nil
+}
+
+// FileConstraintStore implements ConstraintStore using a JSON file
+type FileConstraintStore struct {
+	filePath string
+	memory   *MemoryConstraintStore
+	mu       sync.Mutex
+}
+
+// NewFileConstraintStore creates a new file-based constraint store.
+// A missing file is not an error; it is created on the first write.
+func NewFileConstraintStore(filePath string) (*FileConstraintStore, error) {
+	store := &FileConstraintStore{
+		filePath: filePath,
+		memory:   NewMemoryConstraintStore(),
+	}
+
+	if err := store.load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load storage constraints from %s: %w", filePath, err)
+	}
+
+	return store, nil
+}
+
+// load reads constraints from the file
+func (s *FileConstraintStore) load() error {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		return err
+	}
+
+	constraints := make(map[string]map[string]models.StorageConstraintParams)
+	if err := json.Unmarshal(data, &constraints); err != nil {
+		return fmt.Errorf("failed to decode storage constraints: %w", err)
+	}
+
+	s.memory.mu.Lock()
+	s.memory.constraints = constraints
+	s.memory.mu.Unlock()
+
+	return nil
+}
+
+// save writes all constraints to the file. It writes a temporary file and
+// renames it so a crash never leaves a truncated store behind.
+func (s *FileConstraintStore) save() error {
+	s.memory.mu.RLock()
+	data, err := json.MarshalIndent(s.memory.constraints, "", "  ")
+	s.memory.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode storage constraints: %w", err)
+	}
+
+	if dir := filepath.Dir(s.filePath); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.filePath), filepath.Base(s.filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write storage constraints: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write storage constraints: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), s.filePath)
+}
+
+// Get returns the constraints stored for a machine
+func (s *FileConstraintStore) Get(instance, systemID string) (*models.StorageConstraintParams, error) {
+	return s.memory.Get(instance, systemID)
+}
+
+// Set stores the constraints for a machine and persists the store
+func (s *FileConstraintStore) Set(instance, systemID string, params models.StorageConstraintParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.memory.Set(instance, systemID, params); err != nil {
+		return err
+	}
+
+	return s.save()
+}
+
+// Delete removes the constraints for a machine and persists the store
+func (s *FileConstraintStore) Delete(instance, systemID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.memory.Delete(instance, systemID); err != nil {
+		return err
+	}
+
+	return s.save()
+}
+
+// fileConstraintStores holds the file-backed stores opened by the clients of this process, by
+// absolute file path. Clients of every MAAS instance share the store of a file, so one client's
+// writes never overwrite another's.
+var (
+	fileConstraintStores   = make(map[string]*FileConstraintStore)
+	fileConstraintStoresMu sync.Mutex
+)
+
+// newDefaultConstraintStore returns the file-backed store at MAAS_STORAGE_CONSTRAINTS_FILE
+// or DefaultConstraintStoreFile, opening it on first use.
+func newDefaultConstraintStore() (ConstraintStore, error) {
+	filePath := os.Getenv("MAAS_STORAGE_CONSTRAINTS_FILE")
+	if filePath == "" {
+		filePath = DefaultConstraintStoreFile
+	}
+	return sharedFileConstraintStore(filePath)
+}
+
+// sharedFileConstraintStore returns the store of filePath shared by every client of this process
+func sharedFileConstraintStore(filePath string) (*FileConstraintStore, error) {
+	key, err := filepath.Abs(filePath)
+	if err != nil {
+		key = filepath.Clean(filePath)
+	}
+
+	fileConstraintStoresMu.Lock()
+	defer fileConstraintStoresMu.Unlock()
+
+	if store, ok := fileConstraintStores[key]; ok {
+		return store, nil
+	}
+
+	store, err := NewFileConstraintStore(filePath)
+	if err != nil {
+		return nil, err
+	}
+	fileConstraintStores[key] = store
+	return store, nil
+}
+
+// copyConstraintParams returns a copy that does not share the constraints slice.
+func copyConstraintParams(params models.StorageConstraintParams) *models.StorageConstraintParams {
+	constraints := make([]models.StorageConstraint, len(params.Constraints))
+	copy(constraints, params.Constraints)
+	return &models.StorageConstraintParams{Constraints: constraints}
+}
@@ -0,0 +1,89 @@
+package maasclient
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/lspecian/maas-mcp-server/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileConstraintStorePersists(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "nested", "storage_constraints.json")
+
+	store, err := NewFileConstraintStore(filePath)
+	require.NoError(t, err)
+
+	params := models.StorageConstraintParams{
+		Constraints: []models.StorageConstraint{
+			{Type: models.SizeConstraint, Value: "1T", Operator: "gte"},
+			{Type: models.TagConstraint, Value: "fast"},
+		},
+	}
+	require.NoError(t, store.Set("default", "abc123", params))
+	require.NoError(t, store.Set("staging", "abc123", models.StorageConstraintParams{
+		Constraints: []models.StorageConstraint{{Type: models.TypeConstraint, Value: "hdd"}},
+	}))
+
+	// A fresh store reads what the first one wrote
+	reopened, err := NewFileConstraintStore(filePath)
+	require.NoError(t, err)
+
+	retrieved, err := reopened.Get("default", "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, params, *retrieved)
+
+	require.NoError(t, reopened.Delete("default", "abc123"))
+
+	reopened, err = NewFileConstraintStore(filePath)
+	require.NoError(t, err)
+
+	_, err = reopened.Get("default", "abc123")
+	assert.ErrorIs(t, err, ErrConstraintsNotFound)
+
+	retrieved, err = reopened.Get("staging", "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "hdd", retrieved.Constraints[0].Value)
+}
+
+func TestMemoryConstraintStoreReturnsCopies(t *testing.T) {
+	store := NewMemoryConstraintStore()
+
+	params := models.StorageConstraintParams{
+		Constraints: []models.StorageConstraint{{Type: models.TagConstraint, Value: "fast"}},
+	}
+	require.NoError(t, store.Set("default", "abc123", params))
+
+	params.Constraints[0].Value = "changed"
+	retrieved, err := store.Get("default", "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "fast", retrieved.Constraints[0].Value)
+}
+
+func TestDefaultConstraintStoreIsShared(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "storage_constraints.json")
+	t.Setenv("MAAS_STORAGE_CONSTRAINTS_FILE", filePath)
+
+	// Clients of two instances open the default store
+	first, err := newDefaultConstraintStore()
+	require.NoError(t, err)
+	second, err := newDefaultConstraintStore()
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	require.NoError(t, first.Set("default", "abc123", models.StorageConstraintParams{
+		Constraints: []models.StorageConstraint{{Type: models.TagConstraint, Value: "fast"}},
+	}))
+	require.NoError(t, second.Set("staging", "def456", models.StorageConstraintParams{
+		Constraints: []models.StorageConstraint{{Type: models.TypeConstraint, Value: "hdd"}},
+	}))
+
+	// The file holds the constraints of both
+	reopened, err := NewFileConstraintStore(filePath)
+	require.NoError(t, err)
+	_, err = reopened.Get("default", "abc123")
+	assert.NoError(t, err)
+	_, err = reopened.Get("staging", "def456")
+	assert.NoError(t, err)
+}
@@ -1,13 +1,20 @@
 package maasclient
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/canonical/gomaasclient/entity"
 	"github.com/lspecian/maas-mcp-server/internal/models"
 	"github.com/sirupsen/logrus"
 )
 
+// defaultConstraintRAIDName is used by ApplyStorageConstraints when no raid name constraint is given.
+const defaultConstraintRAIDName = "md0"
+
 // SetStorageConstraints sets storage constraints for a machine
 func (m *MaasClient) SetStorageConstraints(systemID string, params models.StorageConstraintParams) error {
 	if systemID == "" {
@@ -18,64 +25,23 @@ func (m *MaasClient) SetStorageConstraints(systemID string, params models.Storag
 		return fmt.Errorf("invalid storage constraint parameters: %w", err)
 	}
 
+	store, err := m.storageConstraintStore()
+	if err != nil {
+		return err
+	}
+
 	m.logger.WithFields(logrus.Fields{
 		"system_id":   systemID,
+		"instance":    m.instanceName,
 		"constraints": params.Constraints,
 	}).Debug("Setting storage constraints")
 
-	// TODO: Replace with actual gomaasclient call if available, or raw HTTP call.
-	// Assumption: Endpoint is POST /MAAS/api/2.0/machines/{system_id}/storage-constraints
-	// Assumption: Request body is models.StorageConstraintParams
-	// Assumption: gomaasclient.Client might have a generic PostJSON or similar,
-	// or we use m.client.Client (http.Client) with m.client.AuthClient().MakeOAuth1Request(...)
-
-	apiPath := fmt.Sprintf("/MAAS/api/2.0/machines/%s/storage-constraints", systemID)
-
-	operation := func() error {
-		// Placeholder for actual API call logic
-		// Option 1: If gomaasclient has a specific method (ideal)
-		// e.g., err := m.client.Machine(systemID).StorageConstraints().Set(&gomaasEntity.StorageConstraintParams{...})
-		// if err != nil {
-		//     return fmt.Errorf("gomaasclient error setting storage constraints: %w", err)
-		// }
-
-		// Option 2: If gomaasclient has a generic JSON POST method
-		// e.g., err := m.client.PostJSON(apiPath, params, nil) // Assuming params is directly marshallable
-		// if err != nil {
-		//     return fmt.Errorf("error POSTing storage constraints to %s: %w", apiPath, err)
-		// }
-
-		// Option 3: Using raw http.Client from gomaasclient (more complex due to auth)
-		// This would involve:
-		// 1. Marshalling 'params' to JSON []byte.
-		// 2. Creating an *http.Request with method POST, the apiPath, and the JSON body.
-		// 3. Adding necessary headers (Content-Type: application/json, Authorization).
-		//    The Authorization header would need to be constructed using m.client.AuthClient().MakeOAuth1Request
-		//    or similar mechanism from gomaasclient to sign the request.
-		// 4. Executing the request using m.client.Client.Do(req).
-		// 5. Checking the response status code.
-
-		// For now, as this is a placeholder, we'll log and return nil to simulate success.
-		// This section needs to be replaced with actual MAAS API interaction.
-		m.logger.WithFields(logrus.Fields{
-			"system_id": systemID,
-			"api_path":  apiPath,
-			"params":    params,
-		}).Info("Simulating actual API call to set storage constraints")
-		// Simulate an error for demonstration if needed, otherwise return nil for success
-		// return fmt.Errorf("simulated API error")
-		return nil
-	}
-
-	if err := m.retry(operation, 3, 1*time.Second); err != nil {
-		m.logger.WithError(err).WithFields(logrus.Fields{
-			"system_id": systemID,
-			"api_path":  apiPath,
-		}).Error("Failed to set storage constraints after retries")
+	if err := store.Set(m.instanceName, systemID, params); err != nil {
+		m.logger.WithError(err).WithField("system_id", systemID).Error("Failed to store storage constraints")
 		return fmt.Errorf("failed to set storage constraints for machine %s: %w", systemID, err)
 	}
 
-	m.logger.WithField("system_id", systemID).Info("Successfully set storage constraints (pending actual API implementation)")
+	m.logger.WithField("system_id", systemID).Info("Successfully set storage constraints")
 	return nil
 }
 
@@ -85,204 +51,134 @@ func (m *MaasClient) GetStorageConstraints(systemID string) (*models.StorageCons
 		return nil, fmt.Errorf("system ID is required")
 	}
 
-	m.logger.WithField("system_id", systemID).Debug("Getting storage constraints")
-
-	// TODO: Replace with actual gomaasclient call if available, or raw HTTP call.
-	// Assumption: Endpoint is GET /MAAS/api/2.0/machines/{system_id}/storage-constraints
-	// Assumption: Response body is models.StorageConstraintParams
-
-	apiPath := fmt.Sprintf("/MAAS/api/2.0/machines/%s/storage-constraints", systemID)
-	var retrievedParams models.StorageConstraintParams // Use value type, to be filled by API call
-
-	operation := func() error {
-		// Placeholder for actual API call logic
-		// Option 1: If gomaasclient has a specific method (ideal)
-		// e.g., entityParams, err := m.client.Machine(systemID).StorageConstraints().Get()
-		// if err != nil {
-		//     return fmt.Errorf("gomaasclient error getting storage constraints: %w", err)
-		// }
-		// Convert entityParams to models.StorageConstraintParams and assign to retrievedParams
-		// retrievedParams = convertGomaasEntityToModelParams(entityParams)
-
-		// Option 2: If gomaasclient has a generic JSON GET method
-		// e.g., err := m.client.GetJSON(apiPath, &retrievedParams)
-		// if err != nil {
-		//     return fmt.Errorf("error GETting storage constraints from %s: %w", apiPath, err)
-		// }
-
-		// Option 3: Using raw http.Client (similar to POST, but GET method)
-
-		// For now, as this is a placeholder, we'll log and return a simulated response.
-		// This section needs to be replaced with actual MAAS API interaction.
-		m.logger.WithFields(logrus.Fields{
-			"system_id": systemID,
-			"api_path":  apiPath,
-		}).Info("Simulating actual API call to get storage constraints")
-
-		// Simulate a response
-		retrievedParams = models.StorageConstraintParams{
-			Constraints: []models.StorageConstraint{
-				{
-					Type:       models.SizeConstraint,
-					Value:      "256G", // Different value for simulation
-					Operator:   ">=",
-					TargetType: "disk",
-				},
-				{
-					Type:       models.TagConstraint,
-					Value:      "ssd-fast",
-					Operator:   "=",
-					TargetType: "disk",
-				},
-			},
-		}
-		// Simulate an error for demonstration if needed
-		// return fmt.Errorf("simulated API error getting constraints")
-		return nil
+	store, err := m.storageConstraintStore()
+	if err != nil {
+		return nil, err
 	}
 
-	if err := m.retry(operation, 3, 1*time.Second); err != nil {
-		m.logger.WithError(err).WithFields(logrus.Fields{
-			"system_id": systemID,
-			"api_path":  apiPath,
-		}).Error("Failed to get storage constraints after retries")
+	m.logger.WithFields(logrus.Fields{
+		"system_id": systemID,
+		"instance":  m.instanceName,
+	}).Debug("Getting storage constraints")
+
+	params, err := store.Get(m.instanceName, systemID)
+	if err != nil {
 		return nil, fmt.Errorf("failed to get storage constraints for machine %s: %w", systemID, err)
 	}
 
-	m.logger.WithField("system_id", systemID).Info("Successfully got storage constraints (pending actual API implementation)")
-	return &retrievedParams, nil
+	return params, nil
 }
 
-// ValidateStorageConstraints validates storage constraints against a machine's available storage
+// ValidateStorageConstraints validates storage constraints against a machine's available storage.
+// If params holds no constraints, the constraints stored for the machine are validated.
 func (m *MaasClient) ValidateStorageConstraints(systemID string, params models.StorageConstraintParams) (bool, []string, error) {
 	if systemID == "" {
 		return false, nil, fmt.Errorf("system ID is required")
 	}
 
-	if err := params.Validate(); err != nil {
-		return false, nil, fmt.Errorf("invalid storage constraint parameters: %w", err)
+	resolved, err := m.resolveStorageConstraints(systemID, params)
+	if err != nil {
+		return false, nil, err
 	}
 
 	m.logger.WithFields(logrus.Fields{
 		"system_id":   systemID,
-		"constraints": params.Constraints,
+		"constraints": resolved.Constraints,
 	}).Debug("Validating storage constraints")
 
-	// TODO: Replace with actual gomaasclient call if available, or raw HTTP call.
-	// Assumption: Endpoint is POST /MAAS/api/2.0/machines/{system_id}/storage-constraints/validate
-	// Assumption: Request body is models.StorageConstraintParams
-	// Assumption: Response body is {"valid": bool, "violations": []string}
-
-	apiPath := fmt.Sprintf("/MAAS/api/2.0/machines/%s/storage-constraints/validate", systemID)
-	var validationResult struct {
-		Valid      bool     `json:"valid"`
-		Violations []string `json:"violations"`
-	}
-
-	operation := func() error {
-		// Placeholder for actual API call logic
-		// Option 1: If gomaasclient has a specific method
-		// e.g., gomaasValidationResult, err := m.client.Machine(systemID).StorageConstraints().Validate(&gomaasEntity.StorageConstraintParams{...})
-		// if err != nil {
-		//     return fmt.Errorf("gomaasclient error validating storage constraints: %w", err)
-		// }
-		// validationResult.Valid = gomaasValidationResult.Valid
-		// validationResult.Violations = gomaasValidationResult.Violations
-
-		// Option 2: If gomaasclient has a generic JSON POST method
-		// e.g., err := m.client.PostJSON(apiPath, params, &validationResult)
-		// if err != nil {
-		//     return fmt.Errorf("error POSTing storage constraints validation to %s: %w", apiPath, err)
-		// }
-
-		// For now, as this is a placeholder, we'll log and return a simulated response.
-		m.logger.WithFields(logrus.Fields{
-			"system_id": systemID,
-			"api_path":  apiPath,
-			"params":    params,
-		}).Info("Simulating actual API call to validate storage constraints")
-
-		// Simulate validation - e.g., always valid, or add some logic based on params for testing
-		validationResult.Valid = true
-		validationResult.Violations = []string{}
-		if len(params.Constraints) > 0 && params.Constraints[0].Type == models.SizeConstraint && params.Constraints[0].Value == "1TB" {
-			// validationResult.Valid = false // Uncomment to simulate failure
-			// validationResult.Violations = append(validationResult.Violations, "Simulated: 1TB size constraint is too large for this machine.")
-		}
-		// return fmt.Errorf("simulated API error validating constraints")
-		return nil
-	}
-
-	if err := m.retry(operation, 3, 1*time.Second); err != nil {
-		m.logger.WithError(err).WithFields(logrus.Fields{
-			"system_id": systemID,
-			"api_path":  apiPath,
-		}).Error("Failed to validate storage constraints after retries")
+	devices, err := m.physicalBlockDevices(systemID)
+	if err != nil {
 		return false, nil, fmt.Errorf("failed to validate storage constraints for machine %s: %w", systemID, err)
 	}
 
+	plan := planStorageConstraints(devices, resolved.Constraints)
+
 	m.logger.WithFields(logrus.Fields{
 		"system_id":  systemID,
-		"valid":      validationResult.Valid,
-		"violations": validationResult.Violations,
-	}).Info("Successfully validated storage constraints (pending actual API implementation)")
-	return validationResult.Valid, validationResult.Violations, nil
+		"valid":      len(plan.violations) == 0,
+		"violations": plan.violations,
+	}).Info("Validated storage constraints")
+
+	return len(plan.violations) == 0, plan.violations, nil
 }
 
-// ApplyStorageConstraints applies storage constraints during machine deployment
+// ApplyStorageConstraints turns storage constraints into MAAS storage configuration.
+// Disk constraints select the member disks; partition, raid and volume_group constraints
+// then create a partition on each disk, a RAID array over the members, and a volume group
+// on top, in that order. If params holds no constraints, the stored constraints are applied.
 func (m *MaasClient) ApplyStorageConstraints(systemID string, params models.StorageConstraintParams) error {
 	if systemID == "" {
 		return fmt.Errorf("system ID is required")
 	}
 
-	if err := params.Validate(); err != nil {
-		return fmt.Errorf("invalid storage constraint parameters: %w", err)
+	resolved, err := m.resolveStorageConstraints(systemID, params)
+	if err != nil {
+		return err
 	}
 
 	m.logger.WithFields(logrus.Fields{
 		"system_id":   systemID,
-		"constraints": params.Constraints,
+		"constraints": resolved.Constraints,
 	}).Debug("Applying storage constraints")
 
-	// TODO: Replace with actual gomaasclient call if available, or raw HTTP call.
-	// Assumption: Endpoint is POST /MAAS/api/2.0/machines/{system_id}/storage-constraints/apply
-	// Assumption: Request body is models.StorageConstraintParams
+	devices, err := m.physicalBlockDevices(systemID)
+	if err != nil {
+		return fmt.Errorf("failed to apply storage constraints for machine %s: %w", systemID, err)
+	}
 
-	apiPath := fmt.Sprintf("/MAAS/api/2.0/machines/%s/storage-constraints/apply", systemID)
+	plan := planStorageConstraints(devices, resolved.Constraints)
+	if len(plan.violations) > 0 {
+		return fmt.Errorf("storage constraints cannot be satisfied for machine %s: %s", systemID, strings.Join(plan.violations, "; "))
+	}
 
-	operation := func() error {
-		// Placeholder for actual API call logic
-		// Option 1: If gomaasclient has a specific method
-		// e.g., err := m.client.Machine(systemID).StorageConstraints().Apply(&gomaasEntity.StorageConstraintParams{...})
-		// if err != nil {
-		//     return fmt.Errorf("gomaasclient error applying storage constraints: %w", err)
-		// }
-
-		// Option 2: If gomaasclient has a generic JSON POST method
-		// e.g., err := m.client.PostJSON(apiPath, params, nil)
-		// if err != nil {
-		//     return fmt.Errorf("error POSTing storage constraints application to %s: %w", apiPath, err)
-		// }
-
-		// For now, as this is a placeholder, we'll log and return nil to simulate success.
-		m.logger.WithFields(logrus.Fields{
-			"system_id": systemID,
-			"api_path":  apiPath,
-			"params":    params,
-		}).Info("Simulating actual API call to apply storage constraints")
-		// return fmt.Errorf("simulated API error applying constraints")
-		return nil
+	// Members are the IDs the next layer is built from, as block devices or partitions
+	memberDevices := make([]string, 0, len(plan.disks))
+	for _, disk := range plan.disks {
+		memberDevices = append(memberDevices, strconv.Itoa(disk.ID))
+	}
+	var memberPartitions []string
+
+	if plan.partitionSize > 0 {
+		for _, disk := range plan.disks {
+			partition, err := m.createConstraintPartition(systemID, disk.ID, plan.partitionSize)
+			if err != nil {
+				return err
+			}
+			memberPartitions = append(memberPartitions, strconv.Itoa(partition.ID))
+		}
+		memberDevices = nil
 	}
 
-	if err := m.retry(operation, 3, 1*time.Second); err != nil {
-		m.logger.WithError(err).WithFields(logrus.Fields{
-			"system_id": systemID,
-			"api_path":  apiPath,
-		}).Error("Failed to apply storage constraints after retries")
-		return fmt.Errorf("failed to apply storage constraints for machine %s: %w", systemID, err)
+	if plan.raidLevel != "" {
+		raid, err := m.createConstraintRAID(systemID, &entity.RAIDCreateParams{
+			Name:         plan.raidName,
+			Level:        string(plan.raidLevel),
+			BlockDevices: memberDevices,
+			Partitions:   memberPartitions,
+		})
+		if err != nil {
+			return err
+		}
+		memberDevices = []string{strconv.Itoa(raid.VirtualDevice.ID)}
+		memberPartitions = nil
 	}
 
-	m.logger.WithField("system_id", systemID).Info("Successfully applied storage constraints (pending actual API implementation)")
+	if plan.volumeGroupName != "" {
+		if _, err := m.createConstraintVolumeGroup(systemID, &entity.VolumeGroupCreateParams{
+			Name:         plan.volumeGroupName,
+			BlockDevices: memberDevices,
+			Partitions:   memberPartitions,
+		}); err != nil {
+			return err
+		}
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"system_id":    systemID,
+		"disks":        len(plan.disks),
+		"raid":         plan.raidName,
+		"volume_group": plan.volumeGroupName,
+	}).Info("Successfully applied storage constraints")
 	return nil
 }
 
@@ -292,44 +188,256 @@ func (m *MaasClient) DeleteStorageConstraints(systemID string) error {
 		return fmt.Errorf("system ID is required")
 	}
 
-	m.logger.WithField("system_id", systemID).Debug("Deleting storage constraints")
+	store, err := m.storageConstraintStore()
+	if err != nil {
+		return err
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"system_id": systemID,
+		"instance":  m.instanceName,
+	}).Debug("Deleting storage constraints")
+
+	if err := store.Delete(m.instanceName, systemID); err != nil {
+		return fmt.Errorf("failed to delete storage constraints for machine %s: %w", systemID, err)
+	}
 
-	// TODO: Replace with actual gomaasclient call if available, or raw HTTP call.
-	// Assumption: Endpoint is DELETE /MAAS/api/2.0/machines/{system_id}/storage-constraints
+	m.logger.WithField("system_id", systemID).Info("Successfully deleted storage constraints")
+	return nil
+}
 
-	apiPath := fmt.Sprintf("/MAAS/api/2.0/machines/%s/storage-constraints", systemID)
+// storageConstraintStore returns the configured constraint store.
+func (m *MaasClient) storageConstraintStore() (ConstraintStore, error) {
+	if m.constraintStore == nil {
+		return nil, fmt.Errorf("storage constraint store not configured")
+	}
+	return m.constraintStore, nil
+}
 
+// resolveStorageConstraints returns params, or the stored constraints when params is empty, validated.
+func (m *MaasClient) resolveStorageConstraints(systemID string, params models.StorageConstraintParams) (*models.StorageConstraintParams, error) {
+	resolved := &params
+	if len(params.Constraints) == 0 {
+		stored, err := m.GetStorageConstraints(systemID)
+		if err != nil {
+			if errors.Is(err, ErrConstraintsNotFound) {
+				return nil, fmt.Errorf("invalid storage constraint parameters: no constraints given or stored for machine %s", systemID)
+			}
+			return nil, err
+		}
+		resolved = stored
+	}
+
+	if err := resolved.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid storage constraint parameters: %w", err)
+	}
+	return resolved, nil
+}
+
+// physicalBlockDevices returns the machine's physical disks.
+func (m *MaasClient) physicalBlockDevices(systemID string) ([]models.BlockDevice, error) {
+	devices, err := m.GetMachineBlockDevices(systemID)
+	if err != nil {
+		return nil, err
+	}
+
+	physical := make([]models.BlockDevice, 0, len(devices))
+	for _, device := range devices {
+		if device.Type == "" || device.Type == "physical" {
+			physical = append(physical, device)
+		}
+	}
+	return physical, nil
+}
+
+// createConstraintPartition creates a partition of the given size on a block device.
+func (m *MaasClient) createConstraintPartition(systemID string, blockDeviceID int, size int64) (*entity.BlockDevicePartition, error) {
+	var partition *entity.BlockDevicePartition
 	operation := func() error {
-		// Placeholder for actual API call logic
-		// Option 1: If gomaasclient has a specific method
-		// e.g., err := m.client.Machine(systemID).StorageConstraints().Delete()
-		// if err != nil {
-		//     return fmt.Errorf("gomaasclient error deleting storage constraints: %w", err)
-		// }
-
-		// Option 2: If gomaasclient has a generic DELETE method
-		// e.g., err := m.client.Delete(apiPath, nil) // Assuming no params/body for DELETE
-		// if err != nil {
-		//     return fmt.Errorf("error DELETEing storage constraints from %s: %w", apiPath, err)
-		// }
-
-		// For now, as this is a placeholder, we'll log and return nil to simulate success.
-		m.logger.WithFields(logrus.Fields{
-			"system_id": systemID,
-			"api_path":  apiPath,
-		}).Info("Simulating actual API call to delete storage constraints")
-		// return fmt.Errorf("simulated API error deleting constraints")
+		var err error
+		partition, err = m.client.BlockDevicePartitions.Create(systemID, blockDeviceID, &entity.BlockDevicePartitionParams{Size: size})
+		if err != nil {
+			return fmt.Errorf("MAAS API error creating partition on block device %d of machine %s: %w", blockDeviceID, systemID, err)
+		}
 		return nil
 	}
 
 	if err := m.retry(operation, 3, 1*time.Second); err != nil {
-		m.logger.WithError(err).WithFields(logrus.Fields{
-			"system_id": systemID,
-			"api_path":  apiPath,
-		}).Error("Failed to delete storage constraints after retries")
-		return fmt.Errorf("failed to delete storage constraints for machine %s: %w", systemID, err)
+		m.logger.WithError(err).WithField("system_id", systemID).Error("Failed to create partition for storage constraints")
+		return nil, err
 	}
+	return partition, nil
+}
 
-	m.logger.WithField("system_id", systemID).Info("Successfully deleted storage constraints (pending actual API implementation)")
-	return nil
+// createConstraintRAID creates a RAID array for storage constraints.
+func (m *MaasClient) createConstraintRAID(systemID string, params *entity.RAIDCreateParams) (*entity.RAID, error) {
+	var raid *entity.RAID
+	operation := func() error {
+		var err error
+		raid, err = m.client.RAIDs.Create(systemID, params)
+		if err != nil {
+			return fmt.Errorf("MAAS API error creating RAID %s on machine %s: %w", params.Name, systemID, err)
+		}
+		return nil
+	}
+
+	if err := m.retry(operation, 3, 1*time.Second); err != nil {
+		m.logger.WithError(err).WithField("system_id", systemID).Error("Failed to create RAID for storage constraints")
+		return nil, err
+	}
+	return raid, nil
+}
+
+// createConstraintVolumeGroup creates a volume group for storage constraints.
+func (m *MaasClient) createConstraintVolumeGroup(systemID string, params *entity.VolumeGroupCreateParams) (*entity.VolumeGroup, error) {
+	var volumeGroup *entity.VolumeGroup
+	operation := func() error {
+		var err error
+		volumeGroup, err = m.client.VolumeGroups.Create(systemID, params)
+		if err != nil {
+			return fmt.Errorf("MAAS API error creating volume group %s on machine %s: %w", params.Name, systemID, err)
+		}
+		return nil
+	}
+
+	if err := m.retry(operation, 3, 1*time.Second); err != nil {
+		m.logger.WithError(err).WithField("system_id", systemID).Error("Failed to create volume group for storage constraints")
+		return nil, err
+	}
+	return volumeGroup, nil
+}
+
+// storageConstraintPlan is what a set of constraints resolves to on a given machine.
+type storageConstraintPlan struct {
+	disks           []models.BlockDevice
+	partitionSize   int64
+	raidLevel       models.RAIDLevel
+	raidName        string
+	volumeGroupName string
+	violations      []string
+}
+
+// planStorageConstraints selects the disks matching the disk constraints and works out
+// the partition, RAID and volume group layers. Anything that cannot be satisfied on
+// these devices is reported as a violation.
+func planStorageConstraints(devices []models.BlockDevice, constraints []models.StorageConstraint) *storageConstraintPlan {
+	plan := &storageConstraintPlan{}
+
+	var diskConstraints []models.StorageConstraint
+	minDisks, maxDisks := 1, -1
+
+	for _, constraint := range constraints {
+		switch constraint.Target() {
+		case models.DiskTarget:
+			if constraint.Type == models.CountConstraint {
+				var err error
+				if minDisks, maxDisks, err = models.ParseCountConstraint(constraint); err != nil {
+					plan.violations = append(plan.violations, err.Error())
+					return plan
+				}
+				// The layers on top need at least one disk
+				if maxDisks == 0 {
+					plan.violations = append(plan.violations, fmt.Sprintf("count constraint %s %s allows no disks", constraint.Operator, constraint.Value))
+					return plan
+				}
+				if minDisks < 1 {
+					minDisks = 1
+				}
+				continue
+			}
+			diskConstraints = append(diskConstraints, constraint)
+		case models.PartitionTarget:
+			if constraint.Type != models.SizeConstraint {
+				plan.violations = append(plan.violations, fmt.Sprintf("partition constraints only support size, got %s", constraint.Type))
+				continue
+			}
+			size, err := models.ParseStorageSize(constraint.Value)
+			if err != nil {
+				plan.violations = append(plan.violations, fmt.Sprintf("partition constraint: %v", err))
+				continue
+			}
+			plan.partitionSize = size
+		case models.RAIDTarget:
+			switch constraint.Type {
+			case models.TypeConstraint:
+				plan.raidLevel = normalizeRAIDLevel(constraint.Value)
+			case models.NameConstraint:
+				plan.raidName = constraint.Value
+			default:
+				plan.violations = append(plan.violations, fmt.Sprintf("raid constraints only support type and name, got %s", constraint.Type))
+			}
+		case models.VolumeGroupTarget:
+			if constraint.Type != models.NameConstraint {
+				plan.violations = append(plan.violations, fmt.Sprintf("volume_group constraints only support name, got %s", constraint.Type))
+				continue
+			}
+			plan.volumeGroupName = constraint.Value
+		}
+	}
+
+	for _, device := range devices {
+		matched := true
+		for _, constraint := range diskConstraints {
+			ok, err := constraint.MatchesBlockDevice(&device)
+			if err != nil {
+				plan.violations = append(plan.violations, err.Error())
+				return plan
+			}
+			if !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			plan.disks = append(plan.disks, device)
+		}
+	}
+
+	if maxDisks >= 0 && len(plan.disks) > maxDisks {
+		plan.disks = plan.disks[:maxDisks]
+	}
+	if len(plan.disks) < minDisks {
+		plan.violations = append(plan.violations, fmt.Sprintf("%d disk(s) match the disk constraints, %d required", len(plan.disks), minDisks))
+	}
+
+	if plan.partitionSize > 0 {
+		for _, disk := range plan.disks {
+			if disk.AvailableSize < plan.partitionSize {
+				plan.violations = append(plan.violations, fmt.Sprintf("disk %s has %d bytes available, partition needs %d", disk.Name, disk.AvailableSize, plan.partitionSize))
+			}
+		}
+	}
+
+	if plan.raidName != "" && plan.raidLevel == "" {
+		plan.violations = append(plan.violations, "raid constraints need a type constraint with the RAID level")
+	}
+	if plan.raidLevel != "" {
+		minMembers, ok := minRAIDMembers[plan.raidLevel]
+		if !ok {
+			plan.violations = append(plan.violations, fmt.Sprintf("unsupported RAID level %s", plan.raidLevel))
+		} else if len(plan.disks) < minMembers {
+			plan.violations = append(plan.violations, fmt.Sprintf("%s needs at least %d members, %d disk(s) selected", plan.raidLevel, minMembers, len(plan.disks)))
+		}
+		if plan.raidName == "" {
+			plan.raidName = defaultConstraintRAIDName
+		}
+	}
+
+	return plan
+}
+
+// minRAIDMembers is the smallest number of members MAAS accepts for each RAID level.
+var minRAIDMembers = map[models.RAIDLevel]int{
+	models.RAID0:  2,
+	models.RAID1:  2,
+	models.RAID5:  3,
+	models.RAID6:  4,
+	models.RAID10: 4,
+}
+
+// normalizeRAIDLevel accepts "raid-1", "raid1" or "1" and returns the MAAS level name.
+func normalizeRAIDLevel(value string) models.RAIDLevel {
+	level := strings.ToLower(strings.TrimSpace(value))
+	level = strings.TrimPrefix(strings.TrimPrefix(level, "raid"), "-")
+	return models.RAIDLevel("raid-" + level)
 }
@@ -1,20 +1,77 @@
 package maasclient
 
 import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/lspecian/maas-mcp-server/internal/models"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestSetStorageConstraints(t *testing.T) {
-	// Create a test client
+// testBlockDevices is the block device listing served for machine abc123.
+const testBlockDevices = `[
+	{"id": 1, "name": "sda", "type": "physical", "path": "/dev/disk/by-dname/sda", "size": 500000000000, "available_size": 500000000000, "tags": ["ssd"], "model": "Samsung SSD 870"},
+	{"id": 2, "name": "sdb", "type": "physical", "path": "/dev/disk/by-dname/sdb", "size": 500000000000, "available_size": 500000000000, "tags": ["ssd"], "model": "Samsung SSD 870"},
+	{"id": 3, "name": "sdc", "type": "physical", "path": "/dev/disk/by-dname/sdc", "size": 4000000000000, "available_size": 4000000000000, "tags": ["rotary"]}
+]`
+
+// newConstraintTestClient returns a client backed by an in-memory constraint store.
+func newConstraintTestClient() *MaasClient {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
-	client := &MaasClient{
-		logger: logger,
+	return &MaasClient{
+		logger:          logger,
+		instanceName:    "default",
+		constraintStore: NewMemoryConstraintStore(),
+	}
+}
+
+// newStorageTestServerClient returns a client whose MAAS serves testBlockDevices and
+// records every POST it receives, keyed by path.
+func newStorageTestServerClient(t *testing.T) (*MaasClient, func() map[string][]map[string][]string) {
+	var mu sync.Mutex
+	posts := make(map[string][]map[string][]string)
+
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/MAAS/api/2.0/nodes/abc123/blockdevices/":
+			w.Write([]byte(testBlockDevices))
+		case r.Method == http.MethodPost:
+			assert.NoError(t, r.ParseForm())
+			mu.Lock()
+			posts[r.URL.Path] = append(posts[r.URL.Path], r.PostForm)
+			mu.Unlock()
+
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/partitions/"):
+				w.Write([]byte(`{"id": 10, "size": 100000000000}`))
+			case strings.HasSuffix(r.URL.Path, "/raids/"):
+				w.Write([]byte(`{"id": 5, "name": "md0", "level": "raid-1", "virtual_device": {"id": 20, "name": "md0"}}`))
+			default:
+				w.Write([]byte(`{"id": 7, "name": "vg0"}`))
+			}
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	client.SetConstraintStore(NewMemoryConstraintStore())
+
+	return client, func() map[string][]map[string][]string {
+		mu.Lock()
+		defer mu.Unlock()
+		return posts
 	}
+}
+
+func TestSetStorageConstraints(t *testing.T) {
+	client := newConstraintTestClient()
 
 	// Test cases
 	tests := []struct {
@@ -50,6 +107,16 @@ func TestSetStorageConstraints(t *testing.T) {
 			params:    models.StorageConstraintParams{},
 			expectErr: true,
 		},
+		{
+			name:     "Unparseable size",
+			systemID: "abc123",
+			params: models.StorageConstraintParams{
+				Constraints: []models.StorageConstraint{
+					{Type: models.SizeConstraint, Value: "big", Operator: ">="},
+				},
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -65,60 +132,55 @@ func TestSetStorageConstraints(t *testing.T) {
 }
 
 func TestGetStorageConstraints(t *testing.T) {
-	// Create a test client
-	logger := logrus.New()
-	logger.SetLevel(logrus.ErrorLevel)
-	client := &MaasClient{
-		logger: logger,
-	}
+	client := newConstraintTestClient()
 
-	// Test cases
-	tests := []struct {
-		name      string
-		systemID  string
-		expectErr bool
-	}{
-		{
-			name:      "Valid system ID",
-			systemID:  "abc123",
-			expectErr: false,
-		},
-		{
-			name:      "Empty system ID",
-			systemID:  "",
-			expectErr: true,
+	params := models.StorageConstraintParams{
+		Constraints: []models.StorageConstraint{
+			{Type: models.SizeConstraint, Value: "256G", Operator: ">=", TargetType: "disk"},
+			{Type: models.TagConstraint, Value: "ssd-fast", TargetType: "disk"},
 		},
 	}
+	require.NoError(t, client.SetStorageConstraints("abc123", params))
 
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			params, err := client.GetStorageConstraints(tc.systemID)
-			if tc.expectErr {
-				assert.Error(t, err)
-				assert.Nil(t, params)
-			} else {
-				assert.NoError(t, err)
-				assert.NotNil(t, params)
-				assert.NotEmpty(t, params.Constraints)
-			}
-		})
-	}
+	t.Run("Round trip", func(t *testing.T) {
+		retrieved, err := client.GetStorageConstraints("abc123")
+		require.NoError(t, err)
+		assert.Equal(t, params, *retrieved)
+	})
+
+	t.Run("Not set", func(t *testing.T) {
+		retrieved, err := client.GetStorageConstraints("def456")
+		assert.ErrorIs(t, err, ErrConstraintsNotFound)
+		assert.Nil(t, retrieved)
+	})
+
+	t.Run("Empty system ID", func(t *testing.T) {
+		retrieved, err := client.GetStorageConstraints("")
+		assert.Error(t, err)
+		assert.Nil(t, retrieved)
+	})
+
+	t.Run("Instances are isolated", func(t *testing.T) {
+		other := newConstraintTestClient()
+		other.constraintStore = client.constraintStore
+		other.instanceName = "staging"
+
+		_, err := other.GetStorageConstraints("abc123")
+		assert.ErrorIs(t, err, ErrConstraintsNotFound)
+	})
 }
 
 func TestValidateStorageConstraints(t *testing.T) {
-	// Create a test client
-	logger := logrus.New()
-	logger.SetLevel(logrus.ErrorLevel)
-	client := &MaasClient{
-		logger: logger,
-	}
+	client, _ := newStorageTestServerClient(t)
 
 	// Test cases
 	tests := []struct {
-		name      string
-		systemID  string
-		params    models.StorageConstraintParams
-		expectErr bool
+		name               string
+		systemID           string
+		params             models.StorageConstraintParams
+		expectErr          bool
+		expectValid        bool
+		expectedViolations int
 	}{
 		{
 			name:     "Valid parameters",
@@ -133,65 +195,77 @@ func TestValidateStorageConstraints(t *testing.T) {
 					},
 				},
 			},
-			expectErr: false,
+			expectValid: true,
 		},
 		{
-			name:      "Empty system ID",
-			systemID:  "",
-			params:    models.StorageConstraintParams{},
-			expectErr: true,
+			name:     "Not enough matching disks",
+			systemID: "abc123",
+			params: models.StorageConstraintParams{
+				Constraints: []models.StorageConstraint{
+					{Type: models.TypeConstraint, Value: "ssd"},
+					{Type: models.CountConstraint, Value: "3", Operator: ">="},
+				},
+			},
+			expectValid:        false,
+			expectedViolations: 1,
 		},
 		{
-			name:      "Empty constraints",
-			systemID:  "abc123",
-			params:    models.StorageConstraintParams{},
-			expectErr: true,
+			name:     "More disks than a count",
+			systemID: "abc123",
+			params: models.StorageConstraintParams{
+				Constraints: []models.StorageConstraint{
+					{Type: models.TypeConstraint, Value: "ssd"},
+					{Type: models.CountConstraint, Value: "2", Operator: ">"},
+				},
+			},
+			expectValid:        false,
+			expectedViolations: 1,
 		},
-	}
-
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			valid, violations, err := client.ValidateStorageConstraints(tc.systemID, tc.params)
-			if tc.expectErr {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
-				assert.True(t, valid)
-				assert.Empty(t, violations)
-			}
-		})
-	}
-}
-
-func TestApplyStorageConstraints(t *testing.T) {
-	// Create a test client
-	logger := logrus.New()
-	logger.SetLevel(logrus.ErrorLevel)
-	client := &MaasClient{
-		logger: logger,
-	}
-
-	// Test cases
-	tests := []struct {
-		name      string
-		systemID  string
-		params    models.StorageConstraintParams
-		expectErr bool
-	}{
 		{
-			name:     "Valid parameters",
+			name:     "Fewer disks than a count",
 			systemID: "abc123",
 			params: models.StorageConstraintParams{
 				Constraints: []models.StorageConstraint{
-					{
-						Type:       models.SizeConstraint,
-						Value:      "100G",
-						Operator:   ">=",
-						TargetType: "disk",
-					},
+					{Type: models.TypeConstraint, Value: "ssd"},
+					{Type: models.CountConstraint, Value: "2", Operator: "<"},
 				},
 			},
-			expectErr: false,
+			expectValid: true,
+		},
+		{
+			name:     "Count allowing no disks",
+			systemID: "abc123",
+			params: models.StorageConstraintParams{
+				Constraints: []models.StorageConstraint{
+					{Type: models.CountConstraint, Value: "1", Operator: "lt"},
+				},
+			},
+			expectValid:        false,
+			expectedViolations: 1,
+		},
+		{
+			name:     "Negative count",
+			systemID: "abc123",
+			params: models.StorageConstraintParams{
+				Constraints: []models.StorageConstraint{
+					{Type: models.CountConstraint, Value: "-1"},
+				},
+			},
+			expectValid:        false,
+			expectedViolations: 1,
+		},
+		{
+			name:     "RAID needs more members",
+			systemID: "abc123",
+			params: models.StorageConstraintParams{
+				Constraints: []models.StorageConstraint{
+					{Type: models.TypeConstraint, Value: "ssd"},
+					{Type: models.CountConstraint, Value: "2"},
+					{Type: models.TypeConstraint, Value: "raid-5", TargetType: "raid"},
+				},
+			},
+			expectValid:        false,
+			expectedViolations: 1,
 		},
 		{
 			name:      "Empty system ID",
@@ -200,7 +274,7 @@ func TestApplyStorageConstraints(t *testing.T) {
 			expectErr: true,
 		},
 		{
-			name:      "Empty constraints",
+			name:      "Empty constraints and none stored",
 			systemID:  "abc123",
 			params:    models.StorageConstraintParams{},
 			expectErr: true,
@@ -209,50 +283,127 @@ func TestApplyStorageConstraints(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			err := client.ApplyStorageConstraints(tc.systemID, tc.params)
+			valid, violations, err := client.ValidateStorageConstraints(tc.systemID, tc.params)
 			if tc.expectErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, tc.expectValid, valid)
+				assert.Len(t, violations, tc.expectedViolations)
 			}
 		})
 	}
 }
 
+func TestPlanStorageConstraints(t *testing.T) {
+	var devices []models.BlockDevice
+	require.NoError(t, json.Unmarshal([]byte(testBlockDevices), &devices))
+
+	t.Run("Count below", func(t *testing.T) {
+		plan := planStorageConstraints(devices, []models.StorageConstraint{
+			{Type: models.CountConstraint, Value: "3", Operator: "<"},
+		})
+		assert.Empty(t, plan.violations)
+		assert.Len(t, plan.disks, 2)
+	})
+
+	t.Run("Count above", func(t *testing.T) {
+		plan := planStorageConstraints(devices, []models.StorageConstraint{
+			{Type: models.CountConstraint, Value: "2", Operator: ">"},
+		})
+		assert.Empty(t, plan.violations)
+		assert.Len(t, plan.disks, 3)
+	})
+
+	t.Run("Unparsable values", func(t *testing.T) {
+		plan := planStorageConstraints(devices, []models.StorageConstraint{
+			{Type: models.SizeConstraint, Value: "lots", TargetType: "partition"},
+			{Type: models.CountConstraint, Value: "two"},
+		})
+		assert.Equal(t, []string{
+			"partition constraint: invalid storage size: lots",
+			"count constraint value must be a non-negative integer: two",
+		}, plan.violations)
+	})
+}
+
+func TestApplyStorageConstraints(t *testing.T) {
+	t.Run("Stored RAID and volume group", func(t *testing.T) {
+		client, posts := newStorageTestServerClient(t)
+
+		require.NoError(t, client.SetStorageConstraints("abc123", models.StorageConstraintParams{
+			Constraints: []models.StorageConstraint{
+				{Type: models.TypeConstraint, Value: "ssd"},
+				{Type: models.CountConstraint, Value: "2", Operator: "eq"},
+				{Type: models.TypeConstraint, Value: "raid-1", TargetType: "raid"},
+				{Type: models.NameConstraint, Value: "data", TargetType: "volume_group"},
+			},
+		}))
+
+		// Empty params apply the stored constraints
+		require.NoError(t, client.ApplyStorageConstraints("abc123", models.StorageConstraintParams{}))
+
+		recorded := posts()
+		require.Len(t, recorded["/MAAS/api/2.0/nodes/abc123/raids/"], 1)
+		raid := recorded["/MAAS/api/2.0/nodes/abc123/raids/"][0]
+		assert.Equal(t, []string{"raid-1"}, raid["level"])
+		assert.Equal(t, []string{"md0"}, raid["name"])
+		assert.ElementsMatch(t, []string{"1", "2"}, raid["block_devices"])
+
+		require.Len(t, recorded["/MAAS/api/2.0/nodes/abc123/volume-groups/"], 1)
+		volumeGroup := recorded["/MAAS/api/2.0/nodes/abc123/volume-groups/"][0]
+		assert.Equal(t, []string{"data"}, volumeGroup["name"])
+		assert.Equal(t, []string{"20"}, volumeGroup["block_devices"])
+	})
+
+	t.Run("Partitions", func(t *testing.T) {
+		client, posts := newStorageTestServerClient(t)
+
+		err := client.ApplyStorageConstraints("abc123", models.StorageConstraintParams{
+			Constraints: []models.StorageConstraint{
+				{Type: models.TagConstraint, Value: "rotary"},
+				{Type: models.SizeConstraint, Value: "100G", TargetType: "partition"},
+			},
+		})
+		require.NoError(t, err)
+
+		partitions := posts()["/MAAS/api/2.0/nodes/abc123/blockdevices/3/partitions/"]
+		require.Len(t, partitions, 1)
+		assert.Equal(t, []string{"100000000000"}, partitions[0]["size"])
+	})
+
+	t.Run("Unsatisfiable", func(t *testing.T) {
+		client, posts := newStorageTestServerClient(t)
+
+		err := client.ApplyStorageConstraints("abc123", models.StorageConstraintParams{
+			Constraints: []models.StorageConstraint{
+				{Type: models.SizeConstraint, Value: "10T", Operator: ">="},
+			},
+		})
+		assert.Error(t, err)
+		assert.Empty(t, posts())
+	})
+
+	t.Run("Empty system ID", func(t *testing.T) {
+		client := newConstraintTestClient()
+		assert.Error(t, client.ApplyStorageConstraints("", models.StorageConstraintParams{}))
+	})
+}
+
 func TestDeleteStorageConstraints(t *testing.T) {
-	// Create a test client
-	logger := logrus.New()
-	logger.SetLevel(logrus.ErrorLevel)
-	client := &MaasClient{
-		logger: logger,
-	}
+	client := newConstraintTestClient()
 
-	// Test cases
-	tests := []struct {
-		name      string
-		systemID  string
-		expectErr bool
-	}{
-		{
-			name:      "Valid system ID",
-			systemID:  "abc123",
-			expectErr: false,
+	require.NoError(t, client.SetStorageConstraints("abc123", models.StorageConstraintParams{
+		Constraints: []models.StorageConstraint{
+			{Type: models.SizeConstraint, Value: "100G", Operator: ">="},
 		},
-		{
-			name:      "Empty system ID",
-			systemID:  "",
-			expectErr: true,
-		},
-	}
+	}))
 
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			err := client.DeleteStorageConstraints(tc.systemID)
-			if tc.expectErr {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
-			}
-		})
-	}
+	require.NoError(t, client.DeleteStorageConstraints("abc123"))
+
+	_, err := client.GetStorageConstraints("abc123")
+	assert.ErrorIs(t, err, ErrConstraintsNotFound)
+
+	assert.ErrorIs(t, client.DeleteStorageConstraints("abc123"), ErrConstraintsNotFound)
+	assert.Error(t, client.DeleteStorageConstraints(""))
 }
@@ -87,6 +87,14 @@ func (m *Machine) FromEntity(entity *entity.Machine) {
 	// Handle Description
 	m.Description = entity.Description
 
+	// The machine listing carries the physical disks, which storage constraint checks need
+	if len(entity.PhysicalBlockDeviceSet) > 0 {
+		m.BlockDevices = make([]BlockDevice, len(entity.PhysicalBlockDeviceSet))
+		for i := range entity.PhysicalBlockDeviceSet {
+			m.BlockDevices[i].FromEntity(&entity.PhysicalBlockDeviceSet[i])
+		}
+	}
+
 	// Initialize empty metadata map
 	m.Metadata = make(map[string]string)
 	// Add some basic metadata from available fields
@@ -0,0 +1,314 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MAAS allocation takes storage constraints as a string such as "root:100(ssd),data:500(hdd,raid)":
+// a comma separated list of disks, each an optional label, a minimum size in GB and optional tags.
+// The first disk is the one MAAS uses as the root disk.
+
+var (
+	maasStorageLabelPattern = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+	maasStorageSizePattern  = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+	maasStorageTagPattern   = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+// StorageSyntaxError is a structured error for a MAAS storage constraint string or for a
+// requirement that cannot be written in that syntax.
+type StorageSyntaxError struct {
+	Entry  int    `json:"entry"`           // 0-based index of the disk entry or requirement
+	Offset int    `json:"offset"`          // Byte offset of Value in the parsed string, -1 when serializing
+	Field  string `json:"field"`           // "storage", "entry", "label", "size", "tags" or "constraint"
+	Value  string `json:"value,omitempty"` // The offending text
+	Reason string `json:"reason"`          // Why Value was rejected
+}
+
+// Error implements the error interface.
+func (e *StorageSyntaxError) Error() string {
+	if e.Offset < 0 {
+		return fmt.Sprintf("storage requirement %d: invalid %s %q: %s", e.Entry+1, e.Field, e.Value, e.Reason)
+	}
+	return fmt.Sprintf("storage entry %d at offset %d: invalid %s %q: %s", e.Entry+1, e.Offset, e.Field, e.Value, e.Reason)
+}
+
+// ParseMAASStorageConstraints parses a MAAS storage constraint string into one requirement per disk.
+// Each requirement is named after the disk's label and holds a size constraint (at least the given
+// number of GB) and a tag constraint per tag.
+func ParseMAASStorageConstraints(input string) ([]StorageRequirement, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, &StorageSyntaxError{Field: "storage", Value: input, Reason: "at least one disk is required"}
+	}
+
+	entries, offsets, err := splitMAASStorageEntries(input)
+	if err != nil {
+		return nil, err
+	}
+
+	requirements := make([]StorageRequirement, 0, len(entries))
+	labels := make(map[string]bool)
+	for i, entry := range entries {
+		requirement, err := parseMAASStorageEntry(i, entry, offsets[i])
+		if err != nil {
+			return nil, err
+		}
+		if requirement.Name != "" {
+			if labels[requirement.Name] {
+				return nil, &StorageSyntaxError{Entry: i, Offset: offsets[i], Field: "label", Value: requirement.Name, Reason: "label is used by more than one disk"}
+			}
+			labels[requirement.Name] = true
+		}
+		requirements = append(requirements, requirement)
+	}
+
+	return requirements, nil
+}
+
+// FormatMAASStorageConstraints writes requirements in the MAAS storage constraint syntax. A requirement
+// needs a size constraint with the gte operator; it may have tag constraints, ssd/hdd type constraints
+// (written as the ssd and rotary tags MAAS sets) and a minimum count, which repeats the disk.
+// Anything else cannot be expressed to MAAS and is reported as a StorageSyntaxError.
+func FormatMAASStorageConstraints(requirements []StorageRequirement) (string, error) {
+	if len(requirements) == 0 {
+		return "", &StorageSyntaxError{Offset: -1, Field: "storage", Reason: "at least one requirement is required"}
+	}
+
+	var disks []string
+	labels := make(map[string]bool)
+	for i, requirement := range requirements {
+		formatted, err := formatMAASStorageRequirement(i, requirement)
+		if err != nil {
+			return "", err
+		}
+		for _, disk := range formatted {
+			if label, _, found := strings.Cut(disk, ":"); found {
+				if labels[label] {
+					return "", &StorageSyntaxError{Entry: i, Offset: -1, Field: "label", Value: label, Reason: "label is used by more than one disk"}
+				}
+				labels[label] = true
+			}
+		}
+		disks = append(disks, formatted...)
+	}
+
+	return strings.Join(disks, ","), nil
+}
+
+// FormatMAASStorageSimpleConstraints writes simple storage constraints in the MAAS storage constraint
+// syntax, using each constraint's Name as the disk label.
+func FormatMAASStorageSimpleConstraints(constraints []SimpleStorageConstraint) (string, error) {
+	requirements := make([]StorageRequirement, len(constraints))
+	for i := range constraints {
+		requirements[i] = constraints[i].ToStorageRequirement()
+	}
+	return FormatMAASStorageConstraints(requirements)
+}
+
+// NormalizeMAASStorageConstraints returns the MAAS storage constraint string for an allocation that
+// gives storage either as a raw string or as typed constraints. The raw form is validated and
+// rewritten in canonical form. An empty result means no storage constraint was given.
+func NormalizeMAASStorageConstraints(storage string, constraints []SimpleStorageConstraint) (string, error) {
+	switch {
+	case storage != "" && len(constraints) > 0:
+		return "", &StorageSyntaxError{Offset: -1, Field: "storage", Value: storage, Reason: "give either a storage string or storage constraints, not both"}
+	case storage != "":
+		requirements, err := ParseMAASStorageConstraints(storage)
+		if err != nil {
+			return "", err
+		}
+		return FormatMAASStorageConstraints(requirements)
+	case len(constraints) > 0:
+		return FormatMAASStorageSimpleConstraints(constraints)
+	default:
+		return "", nil
+	}
+}
+
+// splitMAASStorageEntries splits the input on the commas that are not inside a tag list and
+// returns each entry with its byte offset.
+func splitMAASStorageEntries(input string) ([]string, []int, error) {
+	var entries []string
+	var offsets []int
+
+	start, depth := 0, 0
+	for i := 0; i <= len(input); i++ {
+		if i < len(input) {
+			switch input[i] {
+			case '(':
+				if depth > 0 {
+					return nil, nil, &StorageSyntaxError{Entry: len(entries), Offset: i, Field: "tags", Value: "(", Reason: "tag lists cannot be nested"}
+				}
+				depth++
+				continue
+			case ')':
+				if depth == 0 {
+					return nil, nil, &StorageSyntaxError{Entry: len(entries), Offset: i, Field: "tags", Value: ")", Reason: "closing parenthesis without an opening one"}
+				}
+				depth--
+				continue
+			case ',':
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		} else if depth > 0 {
+			return nil, nil, &StorageSyntaxError{Entry: len(entries), Offset: start, Field: "tags", Value: input[start:], Reason: "tag list is not closed"}
+		}
+
+		entries = append(entries, input[start:i])
+		offsets = append(offsets, start)
+		start = i + 1
+	}
+
+	return entries, offsets, nil
+}
+
+// parseMAASStorageEntry parses one "label:size(tags)" disk entry starting at offset.
+func parseMAASStorageEntry(index int, entry string, offset int) (StorageRequirement, error) {
+	trimmed := strings.TrimLeft(entry, " \t")
+	offset += len(entry) - len(trimmed)
+	trimmed = strings.TrimRight(trimmed, " \t")
+	if trimmed == "" {
+		return StorageRequirement{}, &StorageSyntaxError{Entry: index, Offset: offset, Field: "entry", Value: entry, Reason: "disk entry is empty"}
+	}
+
+	requirement := StorageRequirement{}
+
+	head, tags := trimmed, ""
+	if open := strings.IndexByte(trimmed, '('); open >= 0 {
+		if !strings.HasSuffix(trimmed, ")") {
+			return requirement, &StorageSyntaxError{Entry: index, Offset: offset + open, Field: "tags", Value: trimmed[open:], Reason: "nothing may follow the tag list"}
+		}
+		head, tags = trimmed[:open], trimmed[open+1:len(trimmed)-1]
+	}
+
+	sizeOffset := offset
+	size := head
+	if label, rest, found := strings.Cut(head, ":"); found {
+		if !maasStorageLabelPattern.MatchString(label) {
+			return requirement, &StorageSyntaxError{Entry: index, Offset: offset, Field: "label", Value: label, Reason: "labels may only contain letters and digits"}
+		}
+		requirement.Name = label
+		size = rest
+		sizeOffset += len(label) + 1
+	}
+
+	if !maasStorageSizePattern.MatchString(size) {
+		return requirement, &StorageSyntaxError{Entry: index, Offset: sizeOffset, Field: "size", Value: size, Reason: "size must be a number of GB without a unit, e.g. 100 or 0.5"}
+	}
+	if amount, _ := strconv.ParseFloat(size, 64); amount <= 0 {
+		return requirement, &StorageSyntaxError{Entry: index, Offset: sizeOffset, Field: "size", Value: size, Reason: "size must be greater than zero"}
+	}
+	requirement.Constraints = append(requirement.Constraints, StorageConstraint{Type: SizeConstraint, Value: size + "G", Operator: "gte"})
+
+	if tags != "" || strings.Contains(trimmed, "(") {
+		tagOffset := offset + len(head) + 1
+		for _, tag := range strings.Split(tags, ",") {
+			name := strings.TrimSpace(tag)
+			if !maasStorageTagPattern.MatchString(name) {
+				return requirement, &StorageSyntaxError{Entry: index, Offset: tagOffset, Field: "tags", Value: tag, Reason: "tags may only contain letters, digits, '-' and '_' and cannot be empty"}
+			}
+			requirement.Constraints = append(requirement.Constraints, StorageConstraint{Type: TagConstraint, Value: name})
+			tagOffset += len(tag) + 1
+		}
+	}
+
+	return requirement, nil
+}
+
+// formatMAASStorageRequirement writes one requirement as one or more disk entries.
+func formatMAASStorageRequirement(index int, requirement StorageRequirement) ([]string, error) {
+	unsupported := func(constraint StorageConstraint, reason string) error {
+		return &StorageSyntaxError{Entry: index, Offset: -1, Field: "constraint", Value: describeStorageConstraint(constraint), Reason: reason}
+	}
+
+	if requirement.Name != "" && !maasStorageLabelPattern.MatchString(requirement.Name) {
+		return nil, &StorageSyntaxError{Entry: index, Offset: -1, Field: "label", Value: requirement.Name, Reason: "labels may only contain letters and digits"}
+	}
+
+	size := ""
+	count := 1
+	var tags []string
+	for _, constraint := range requirement.Constraints {
+		if constraint.Target() != DiskTarget {
+			return nil, unsupported(constraint, "only disk constraints can be sent to MAAS")
+		}
+		op, err := NormalizeConstraintOperator(constraint.Operator)
+		if err != nil {
+			return nil, unsupported(constraint, err.Error())
+		}
+
+		switch constraint.Type {
+		case SizeConstraint:
+			if op != "gte" {
+				return nil, unsupported(constraint, "MAAS disk sizes are minimums, use the gte operator")
+			}
+			if size != "" {
+				return nil, unsupported(constraint, "a disk can only have one size")
+			}
+			bytes, err := ParseStorageSize(constraint.Value)
+			if err != nil {
+				return nil, unsupported(constraint, err.Error())
+			}
+			if bytes <= 0 {
+				return nil, unsupported(constraint, "size must be greater than zero")
+			}
+			size = strconv.FormatFloat(float64(bytes)/1e9, 'f', -1, 64)
+		case TagConstraint:
+			if op != "eq" || !maasStorageTagPattern.MatchString(constraint.Value) {
+				return nil, unsupported(constraint, "tags must be a plain name matched with eq")
+			}
+			tags = append(tags, constraint.Value)
+		case TypeConstraint:
+			if op != "eq" {
+				return nil, unsupported(constraint, "disk types can only be matched with eq")
+			}
+			switch strings.ToLower(constraint.Value) {
+			case "ssd":
+				tags = append(tags, "ssd")
+			case "hdd", "rotary":
+				tags = append(tags, "rotary")
+			default:
+				return nil, unsupported(constraint, "MAAS only tags ssd and rotary disks; use a tag constraint instead")
+			}
+		case CountConstraint:
+			minCount, maxCount, err := ParseCountConstraint(constraint)
+			if err != nil {
+				return nil, unsupported(constraint, err.Error())
+			}
+			if maxCount >= 0 || minCount < 1 {
+				return nil, unsupported(constraint, "MAAS can only require a minimum number of at least one disk")
+			}
+			count = minCount
+		default:
+			return nil, unsupported(constraint, "MAAS storage constraints only support size, tags, ssd/hdd type and count")
+		}
+	}
+
+	if size == "" {
+		return nil, &StorageSyntaxError{Entry: index, Offset: -1, Field: "size", Reason: "a minimum size constraint is required"}
+	}
+
+	disk := size
+	if len(tags) > 0 {
+		disk += "(" + strings.Join(tags, ",") + ")"
+	}
+
+	disks := make([]string, count)
+	for n := range disks {
+		switch {
+		case requirement.Name == "":
+			disks[n] = disk
+		case count == 1:
+			disks[n] = requirement.Name + ":" + disk
+		default:
+			disks[n] = fmt.Sprintf("%s%d:%s", requirement.Name, n+1, disk)
+		}
+	}
+	return disks, nil
+}
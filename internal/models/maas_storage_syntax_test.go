@@ -0,0 +1,162 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMAASStorageConstraints(t *testing.T) {
+	requirements, err := ParseMAASStorageConstraints("root:100(ssd),data:500(hdd, raid),0.5")
+	require.NoError(t, err)
+
+	assert.Equal(t, []StorageRequirement{
+		{
+			Name: "root",
+			Constraints: []StorageConstraint{
+				{Type: SizeConstraint, Value: "100G", Operator: "gte"},
+				{Type: TagConstraint, Value: "ssd"},
+			},
+		},
+		{
+			Name: "data",
+			Constraints: []StorageConstraint{
+				{Type: SizeConstraint, Value: "500G", Operator: "gte"},
+				{Type: TagConstraint, Value: "hdd"},
+				{Type: TagConstraint, Value: "raid"},
+			},
+		},
+		{
+			Constraints: []StorageConstraint{
+				{Type: SizeConstraint, Value: "0.5G", Operator: "gte"},
+			},
+		},
+	}, requirements)
+}
+
+func TestParseMAASStorageConstraintsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		entry  int
+		offset int
+		field  string
+	}{
+		{name: "Empty", input: " ", field: "storage"},
+		{name: "Empty entry", input: "root:100,,data:5", entry: 1, offset: 9, field: "entry"},
+		{name: "Size with unit", input: "root:100G", offset: 5, field: "size"},
+		{name: "Size with unit and tags", input: "root:100G(ssd)", offset: 5, field: "size"},
+		{name: "Zero size", input: "root:100,data:0", entry: 1, offset: 14, field: "size"},
+		{name: "Bad label", input: "root-disk:100", field: "label"},
+		{name: "Duplicate label", input: "root:100,root:200", entry: 1, offset: 9, field: "label"},
+		{name: "Unclosed tags", input: "root:100(ssd", field: "tags"},
+		{name: "Nested tags", input: "root:100(ssd(x))", offset: 12, field: "tags"},
+		{name: "Text after tags", input: "root:100(ssd)x", offset: 8, field: "tags"},
+		{name: "Empty tag", input: "root:100(ssd,)", offset: 13, field: "tags"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseMAASStorageConstraints(tc.input)
+			require.Error(t, err)
+
+			var syntaxErr *StorageSyntaxError
+			require.True(t, errors.As(err, &syntaxErr))
+			assert.Equal(t, tc.entry, syntaxErr.Entry)
+			assert.Equal(t, tc.offset, syntaxErr.Offset)
+			assert.Equal(t, tc.field, syntaxErr.Field)
+		})
+	}
+}
+
+func TestFormatMAASStorageConstraints(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		for _, input := range []string{"root:100(ssd),data:500(hdd,raid)", "0.5", "1000(rotary),250"} {
+			requirements, err := ParseMAASStorageConstraints(input)
+			require.NoError(t, err)
+
+			formatted, err := FormatMAASStorageConstraints(requirements)
+			require.NoError(t, err)
+			assert.Equal(t, input, formatted)
+		}
+	})
+
+	t.Run("Simple constraints", func(t *testing.T) {
+		formatted, err := FormatMAASStorageSimpleConstraints([]SimpleStorageConstraint{
+			{Name: "root", MinSize: 100 * 1000 * 1000 * 1000, DiskType: "ssd"},
+			{Name: "data", MinSize: 1000 * 1000 * 1000 * 1000, DiskType: "hdd", Tags: []string{"raid"}, Count: 2},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "root:100(ssd),data1:1000(rotary,raid),data2:1000(rotary,raid)", formatted)
+	})
+
+	t.Run("Unsupported constraints", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			requirement StorageRequirement
+			field       string
+		}{
+			{
+				name:        "Missing size",
+				requirement: StorageRequirement{Constraints: []StorageConstraint{{Type: TagConstraint, Value: "ssd"}}},
+				field:       "size",
+			},
+			{
+				name:        "Maximum size",
+				requirement: StorageRequirement{Constraints: []StorageConstraint{{Type: SizeConstraint, Value: "1T", Operator: "lte"}}},
+				field:       "constraint",
+			},
+			{
+				name: "Model",
+				requirement: StorageRequirement{Constraints: []StorageConstraint{
+					{Type: SizeConstraint, Value: "1T", Operator: "gte"},
+					{Type: ModelConstraint, Value: "Samsung"},
+				}},
+				field: "constraint",
+			},
+			{
+				name: "Exact count",
+				requirement: StorageRequirement{Constraints: []StorageConstraint{
+					{Type: SizeConstraint, Value: "1T", Operator: "gte"},
+					{Type: CountConstraint, Value: "2", Operator: "eq"},
+				}},
+				field: "constraint",
+			},
+			{
+				name:        "Bad label",
+				requirement: StorageRequirement{Name: "fast-disk", Constraints: []StorageConstraint{{Type: SizeConstraint, Value: "1T", Operator: "gte"}}},
+				field:       "label",
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := FormatMAASStorageConstraints([]StorageRequirement{tc.requirement})
+
+				var syntaxErr *StorageSyntaxError
+				require.True(t, errors.As(err, &syntaxErr))
+				assert.Equal(t, tc.field, syntaxErr.Field)
+				assert.Equal(t, -1, syntaxErr.Offset)
+			})
+		}
+	})
+}
+
+func TestNormalizeMAASStorageConstraints(t *testing.T) {
+	storage, err := NormalizeMAASStorageConstraints(" root:100( ssd ) ", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "root:100(ssd)", storage)
+
+	storage, err = NormalizeMAASStorageConstraints("", []SimpleStorageConstraint{{MinSize: 100 * 1000 * 1000 * 1000}})
+	require.NoError(t, err)
+	assert.Equal(t, "100", storage)
+
+	storage, err = NormalizeMAASStorageConstraints("", nil)
+	require.NoError(t, err)
+	assert.Empty(t, storage)
+
+	_, err = NormalizeMAASStorageConstraints("100", []SimpleStorageConstraint{{MinSize: 1}})
+	assert.Error(t, err)
+}
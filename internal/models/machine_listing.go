@@ -29,6 +29,10 @@ type MachineListingRequest struct {
 	// Storage constraints for filtering machines
 	StorageConstraints *SimpleStorageConstraint `json:"storage_constraints,omitempty"`
 
+	// Additional storage requirements, each satisfied by its own disks
+	// (e.g. 2 NVMe disks of at least 1TB and an SSD tagged "fast")
+	StorageRequirements []SimpleStorageConstraint `json:"storage_requirements,omitempty"`
+
 	// Pagination parameters
 	Limit  int `json:"limit,omitempty"`
 	Offset int `json:"offset,omitempty"`
@@ -45,6 +49,9 @@ type MachineDiscoveryRequest struct {
 
 	// ScanStorage indicates whether to scan storage during discovery
 	ScanStorage bool `json:"scan_storage,omitempty"`
+
+	// Storage requirements the discovered machines must satisfy, each with its own disks
+	StorageConstraints []SimpleStorageConstraint `json:"storage_constraints,omitempty"`
 }
 
 // MachineListingResponse represents the response for listing machines
@@ -0,0 +1,20 @@
+package models
+
+// MachineOperation describes a machine action that runs in the background. Progress is
+// published as server-sent events on StreamURL until MAAS reports a final status.
+type MachineOperation struct {
+	// OperationID identifies the operation in the progress tracker
+	OperationID string `json:"operation_id"`
+
+	// Operation is the machine action: allocate, deploy or release
+	Operation string `json:"operation"`
+
+	// SystemID of the machine, empty for an allocation that has not picked a machine yet
+	SystemID string `json:"system_id,omitempty"`
+
+	// Status of the operation when it was accepted
+	Status string `json:"status"`
+
+	// StreamURL is the MCP SSE endpoint, filtered to the operation's events
+	StreamURL string `json:"stream_url"`
+}
@@ -167,9 +167,38 @@ type MCPDiscoveryResponse struct {
 
 // MCPTool represents a tool provided by the MCP server
 type MCPTool struct {
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	InputSchema interface{} `json:"input_schema"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	InputSchema interface{}         `json:"input_schema"`
+	Annotations *MCPToolAnnotations `json:"annotations,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Endpoint    *MCPToolEndpoint    `json:"endpoint,omitempty"`
+}
+
+// MCPToolAnnotations describes how a tool affects MAAS, so clients can warn before
+// destructive calls. All hints are always sent, since MCP treats a missing destructiveHint
+// and openWorldHint as true.
+type MCPToolAnnotations struct {
+	// Title is a human-readable name for the tool
+	Title string `json:"title,omitempty"`
+	// ReadOnlyHint is true when the tool does not change MAAS
+	ReadOnlyHint bool `json:"readOnlyHint"`
+	// DestructiveHint is true when the tool may delete or overwrite state, e.g. release or erase a machine
+	DestructiveHint bool `json:"destructiveHint"`
+	// IdempotentHint is true when repeating a call with the same arguments has no further effect
+	IdempotentHint bool `json:"idempotentHint"`
+	// OpenWorldHint is true when the tool reaches beyond the MAAS server
+	OpenWorldHint bool `json:"openWorldHint"`
+}
+
+// MCPToolEndpoint describes the MAAS API endpoint behind a generated tool
+type MCPToolEndpoint struct {
+	// Method is the HTTP method, e.g. "POST"
+	Method string `json:"method"`
+	// PathTemplate is the MAAS path with placeholders, e.g. "/api/2.0/machines/{system_id}/op-deploy"
+	PathTemplate string `json:"path_template"`
+	// Parameters maps each input parameter to where it is sent: "path", "query" or "body"
+	Parameters map[string]string `json:"parameters,omitempty"`
 }
 
 // MCPResource represents a resource provided by the MCP server
@@ -201,20 +230,40 @@ type GetMachineDetailsRequest struct {
 type AllocateMachineRequest struct {
 	Constraints map[string]string `json:"constraints,omitempty"`
 	Tags        []string          `json:"tags,omitempty"`
-	MaasConfig  *MaasConfig       `json:"_maasConfig,omitempty"`
+	// Storage is a MAAS storage constraint string, e.g. "root:100(ssd),data:500(hdd,raid)"
+	Storage string `json:"storage,omitempty"`
+	// StorageConstraints is the typed alternative to Storage; one disk per constraint (Count repeats it)
+	StorageConstraints []SimpleStorageConstraint `json:"storage_constraints,omitempty"`
+	// Async returns an operation ID straight away and streams progress over SSE
+	Async      bool        `json:"async,omitempty"`
+	MaasConfig *MaasConfig `json:"_maasConfig,omitempty"`
 }
 
 // DeployMachineRequest represents the request for deploying a machine
 type DeployMachineRequest struct {
-	SystemID   string      `json:"system_id"`
-	OSName     string      `json:"os_name,omitempty"`
-	Kernel     string      `json:"kernel,omitempty"`
-	MaasConfig *MaasConfig `json:"_maasConfig,omitempty"`
+	SystemID     string      `json:"system_id"`
+	OSName       string      `json:"os_name,omitempty"`
+	DistroSeries string      `json:"distro_series,omitempty"`
+	Kernel       string      `json:"kernel,omitempty"`
+	Async        bool        `json:"async,omitempty"`
+	MaasConfig   *MaasConfig `json:"_maasConfig,omitempty"`
+}
+
+// CommissionMachineRequest represents the request for commissioning a machine
+type CommissionMachineRequest struct {
+	SystemID       string      `json:"system_id"`
+	EnableSSH      bool        `json:"enable_ssh,omitempty"`
+	SkipNetworking bool        `json:"skip_networking,omitempty"`
+	SkipStorage    bool        `json:"skip_storage,omitempty"`
+	Async          bool        `json:"async,omitempty"`
+	MaasConfig     *MaasConfig `json:"_maasConfig,omitempty"`
 }
 
 // ReleaseMachineRequest represents the request for releasing a machine
 type ReleaseMachineRequest struct {
 	SystemID   string      `json:"system_id"`
+	Comment    string      `json:"comment,omitempty"`
+	Async      bool        `json:"async,omitempty"`
 	MaasConfig *MaasConfig `json:"_maasConfig,omitempty"`
 }
 
@@ -0,0 +1,185 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Locations of the parameters of a generated tool, as recorded in MCPToolEndpoint.Parameters
+const (
+	ParameterInPath  = "path"
+	ParameterInQuery = "query"
+	ParameterInBody  = "body"
+)
+
+// MCPToolRequest is a call to the MAAS endpoint behind a generated tool
+type MCPToolRequest struct {
+	// Method is the HTTP method, e.g. "POST"
+	Method string
+	// Path is the escaped MAAS path with the placeholders filled in and the query parameters appended
+	Path string
+	// Body holds the parameters sent in the request body
+	Body map[string]interface{}
+}
+
+// Request routes the arguments of a tool call to where the endpoint takes them: path parameters
+// fill the placeholders of the path template, query parameters are appended to the path and body
+// parameters are sent in the body. Arguments without a recorded location go to the query for GET
+// and DELETE and to the body otherwise, which is where MAAS reads them.
+func (e *MCPToolEndpoint) Request(args map[string]interface{}) (*MCPToolRequest, error) {
+	method := strings.ToUpper(e.Method)
+	remaining := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		remaining[key] = value
+	}
+
+	path, err := e.resolvePath(remaining)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &MCPToolRequest{
+		Method: method,
+		Body:   make(map[string]interface{}),
+	}
+	query := url.Values{}
+	for name, value := range remaining {
+		location := e.Parameters[name]
+		if location == "" {
+			location = ParameterInBody
+			if method == http.MethodGet || method == http.MethodDelete {
+				location = ParameterInQuery
+			}
+		}
+
+		switch location {
+		case ParameterInQuery:
+			values, err := queryValues(name, value)
+			if err != nil {
+				return nil, err
+			}
+			query[name] = append(query[name], values...)
+		case ParameterInBody:
+			request.Body[name] = value
+		case ParameterInPath:
+			return nil, fmt.Errorf("path parameter '%s' does not appear in %s", name, e.PathTemplate)
+		default:
+			return nil, fmt.Errorf("parameter '%s' has unknown location '%s'", name, location)
+		}
+	}
+
+	request.Path = path
+	if len(query) > 0 {
+		separator := "?"
+		if strings.Contains(path, "?") {
+			separator = "&"
+		}
+		request.Path += separator + query.Encode()
+	}
+
+	return request, nil
+}
+
+// resolvePath substitutes the {name} placeholders of the path template with the escaped arguments
+// of the same name, removing them from args. Values that would change the shape of the path, such as
+// an empty segment, a dot segment or an operation name, are rejected.
+func (e *MCPToolEndpoint) resolvePath(args map[string]interface{}) (string, error) {
+	var path strings.Builder
+	rest := e.PathTemplate
+	for {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			path.WriteString(rest)
+			return path.String(), nil
+		}
+		end := strings.Index(rest[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("malformed path template %q", e.PathTemplate)
+		}
+		end += start
+
+		name := rest[start+1 : end]
+		value, ok := args[name]
+		if !ok || value == nil {
+			return "", fmt.Errorf("missing required path parameter '%s'", name)
+		}
+		segment, err := scalarParameter(value)
+		if err != nil {
+			return "", fmt.Errorf("path parameter '%s' %w", name, err)
+		}
+		if err := checkPathSegment(segment); err != nil {
+			return "", fmt.Errorf("path parameter '%s' %w", name, err)
+		}
+		delete(args, name)
+
+		path.WriteString(rest[:start])
+		path.WriteString(url.PathEscape(segment))
+		rest = rest[end+1:]
+	}
+}
+
+// checkPathSegment rejects the values MAAS would not read as a resource identifier
+func checkPathSegment(segment string) error {
+	switch {
+	case segment == "":
+		return fmt.Errorf("must not be empty")
+	case segment == "." || segment == "..":
+		return fmt.Errorf("must not be %q", segment)
+	case strings.HasPrefix(segment, "op-"):
+		return fmt.Errorf("must not name an operation, got %q", segment)
+	}
+	return nil
+}
+
+// queryValues formats a query argument: arrays repeat the parameter, objects are sent as JSON
+// and nulls are left out
+func queryValues(name string, value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			itemValues, err := queryValues(name, item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, itemValues...)
+		}
+		return values, nil
+	case map[string]interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode query parameter '%s': %w", name, err)
+		}
+		return []string{string(encoded)}, nil
+	}
+
+	formatted, err := scalarParameter(value)
+	if err != nil {
+		return nil, fmt.Errorf("query parameter '%s' %w", name, err)
+	}
+	return []string{formatted}, nil
+}
+
+// scalarParameter formats a string, number or boolean argument
+func scalarParameter(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("must be a string, number or boolean, got %T", value)
+	}
+}
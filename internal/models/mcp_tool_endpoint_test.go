@@ -0,0 +1,184 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCPToolEndpointRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint MCPToolEndpoint
+		args     map[string]interface{}
+		want     *MCPToolRequest
+	}{
+		{
+			name: "Path and body parameters",
+			endpoint: MCPToolEndpoint{
+				Method:       "post",
+				PathTemplate: "/api/2.0/nodes/{system_id}/blockdevices/{id}/op-format",
+				Parameters:   map[string]string{"system_id": "path", "id": "path", "fstype": "body"},
+			},
+			args: map[string]interface{}{"system_id": "abc123", "id": float64(7), "fstype": "ext4"},
+			want: &MCPToolRequest{
+				Method: "POST",
+				Path:   "/api/2.0/nodes/abc123/blockdevices/7/op-format",
+				Body:   map[string]interface{}{"fstype": "ext4"},
+			},
+		},
+		{
+			name: "Path values are escaped",
+			endpoint: MCPToolEndpoint{
+				Method:       "GET",
+				PathTemplate: "/api/2.0/tags/{name}/",
+				Parameters:   map[string]string{"name": "path"},
+			},
+			args: map[string]interface{}{"name": "my tag/x"},
+			want: &MCPToolRequest{
+				Method: "GET",
+				Path:   "/api/2.0/tags/my%20tag%2Fx/",
+				Body:   map[string]interface{}{},
+			},
+		},
+		{
+			name: "POST with query parameters",
+			endpoint: MCPToolEndpoint{
+				Method:       "POST",
+				PathTemplate: "/api/2.0/machines/{system_id}/op-release",
+				Parameters:   map[string]string{"system_id": "path", "force": "query", "erase": "query", "comment": "body"},
+			},
+			args: map[string]interface{}{"system_id": "abc123", "force": true, "erase": true, "comment": "done"},
+			want: &MCPToolRequest{
+				Method: "POST",
+				Path:   "/api/2.0/machines/abc123/op-release?erase=true&force=true",
+				Body:   map[string]interface{}{"comment": "done"},
+			},
+		},
+		{
+			name: "Query appended to an existing query",
+			endpoint: MCPToolEndpoint{
+				Method:       "POST",
+				PathTemplate: "/api/2.0/machines/?op=allocate",
+				Parameters:   map[string]string{"dry_run": "query"},
+			},
+			args: map[string]interface{}{"dry_run": true},
+			want: &MCPToolRequest{
+				Method: "POST",
+				Path:   "/api/2.0/machines/?op=allocate&dry_run=true",
+				Body:   map[string]interface{}{},
+			},
+		},
+		{
+			name: "Unrecorded GET parameters go to the query",
+			endpoint: MCPToolEndpoint{
+				Method:       "GET",
+				PathTemplate: "/api/2.0/machines/",
+			},
+			args: map[string]interface{}{"hostname": []interface{}{"node-1", "node-2"}, "zone": nil},
+			want: &MCPToolRequest{
+				Method: "GET",
+				Path:   "/api/2.0/machines/?hostname=node-1&hostname=node-2",
+				Body:   map[string]interface{}{},
+			},
+		},
+		{
+			name: "Underscores in static segments survive",
+			endpoint: MCPToolEndpoint{
+				Method:       "POST",
+				PathTemplate: "/api/2.0/account/op-create_authorisation_token",
+			},
+			want: &MCPToolRequest{
+				Method: "POST",
+				Path:   "/api/2.0/account/op-create_authorisation_token",
+				Body:   map[string]interface{}{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := tt.endpoint.Request(tt.args)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, request)
+		})
+	}
+}
+
+func TestMCPToolEndpointRequest_LeavesArgumentsAlone(t *testing.T) {
+	endpoint := MCPToolEndpoint{Method: "GET", PathTemplate: "/api/2.0/machines/{system_id}/"}
+	args := map[string]interface{}{"system_id": "abc123"}
+
+	_, err := endpoint.Request(args)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"system_id": "abc123"}, args)
+}
+
+func TestMCPToolEndpointRequest_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint MCPToolEndpoint
+		args     map[string]interface{}
+	}{
+		{
+			name:     "Missing path parameter",
+			endpoint: MCPToolEndpoint{Method: "GET", PathTemplate: "/api/2.0/machines/{system_id}/"},
+		},
+		{
+			name:     "Malformed template",
+			endpoint: MCPToolEndpoint{Method: "GET", PathTemplate: "/api/2.0/machines/{system_id/"},
+			args:     map[string]interface{}{"system_id": "abc123"},
+		},
+		{
+			name:     "Empty path value",
+			endpoint: MCPToolEndpoint{Method: "POST", PathTemplate: "/api/2.0/machines/{system_id}/op-deploy"},
+			args:     map[string]interface{}{"system_id": ""},
+		},
+		{
+			name:     "Dot path value",
+			endpoint: MCPToolEndpoint{Method: "GET", PathTemplate: "/api/2.0/machines/{system_id}/"},
+			args:     map[string]interface{}{"system_id": "."},
+		},
+		{
+			name:     "Dot-dot path value",
+			endpoint: MCPToolEndpoint{Method: "GET", PathTemplate: "/api/2.0/machines/{system_id}/"},
+			args:     map[string]interface{}{"system_id": ".."},
+		},
+		{
+			name:     "Operation as path value",
+			endpoint: MCPToolEndpoint{Method: "POST", PathTemplate: "/api/2.0/machines/{system_id}/"},
+			args:     map[string]interface{}{"system_id": "op-release"},
+		},
+		{
+			name:     "Object in the path",
+			endpoint: MCPToolEndpoint{Method: "GET", PathTemplate: "/api/2.0/machines/{system_id}/"},
+			args:     map[string]interface{}{"system_id": map[string]interface{}{}},
+		},
+		{
+			name: "Path parameter missing from the template",
+			endpoint: MCPToolEndpoint{
+				Method:       "GET",
+				PathTemplate: "/api/2.0/machines/",
+				Parameters:   map[string]string{"system_id": "path"},
+			},
+			args: map[string]interface{}{"system_id": "abc123"},
+		},
+		{
+			name: "Unknown location",
+			endpoint: MCPToolEndpoint{
+				Method:       "POST",
+				PathTemplate: "/api/2.0/machines/",
+				Parameters:   map[string]string{"hostname": "header"},
+			},
+			args: map[string]interface{}{"hostname": "node-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.endpoint.Request(tt.args)
+			assert.Error(t, err)
+		})
+	}
+}
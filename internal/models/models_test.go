@@ -84,104 +84,6 @@ func TestTagValidation(t *testing.T) {
 	}
 }
 
-func TestMaasMachineToMCPContext(t *testing.T) {
-	// Create a MAAS machine
-	machine := &Machine{
-		SystemID:     "abc123",
-		Hostname:     "test-machine",
-		FQDN:         "test-machine.example.com",
-		Status:       "Ready",
-		Architecture: "amd64/generic",
-		PowerState:   "on",
-		PowerType:    "ipmi",
-		Zone:         "default",
-		Pool:         "default",
-		Tags:         []string{"tag1", "tag2"},
-		IPAddresses:  []string{"192.168.1.100", "10.0.0.100"},
-		CPUCount:     4,
-		Memory:       8192,
-		OSSystem:     "ubuntu",
-		DistroSeries: "focal",
-		Interfaces: []NetworkInterface{
-			{
-				ID:         1,
-				Name:       "eth0",
-				Type:       "physical",
-				Enabled:    true,
-				MACAddress: "00:11:22:33:44:55",
-				VLANid:     1,
-				Links: []LinkInfo{
-					{
-						Mode:      "static",
-						IPAddress: "192.168.1.100",
-						SubnetID:  1,
-					},
-				},
-			},
-		},
-		BlockDevices: []BlockDevice{
-			{
-				ID:            1,
-				Name:          "sda",
-				Type:          "physical",
-				Path:          "/dev/sda",
-				Size:          1000000000,
-				UsedSize:      900000000,
-				AvailableSize: 100000000,
-				Model:         "Samsung SSD",
-				Serial:        "S123456",
-			},
-		},
-	}
-
-	// Convert to MCP context
-	ctx := MaasMachineToMCPContext(machine)
-
-	// Verify conversion
-	if ctx.ID != machine.SystemID {
-		t.Errorf("Expected ID %s, got %s", machine.SystemID, ctx.ID)
-	}
-	if ctx.Name != machine.Hostname {
-		t.Errorf("Expected Name %s, got %s", machine.Hostname, ctx.Name)
-	}
-	if ctx.Status != machine.Status {
-		t.Errorf("Expected Status %s, got %s", machine.Status, ctx.Status)
-	}
-	if ctx.Architecture != machine.Architecture {
-		t.Errorf("Expected Architecture %s, got %s", machine.Architecture, ctx.Architecture)
-	}
-	if ctx.PowerState != machine.PowerState {
-		t.Errorf("Expected PowerState %s, got %s", machine.PowerState, ctx.PowerState)
-	}
-	if ctx.Zone != machine.Zone {
-		t.Errorf("Expected Zone %s, got %s", machine.Zone, ctx.Zone)
-	}
-	if ctx.Pool != machine.Pool {
-		t.Errorf("Expected Pool %s, got %s", machine.Pool, ctx.Pool)
-	}
-	if len(ctx.Tags) != len(machine.Tags) {
-		t.Errorf("Expected %d tags, got %d", len(machine.Tags), len(ctx.Tags))
-	}
-	if ctx.CPUCount != machine.CPUCount {
-		t.Errorf("Expected CPUCount %d, got %d", machine.CPUCount, ctx.CPUCount)
-	}
-	if ctx.Memory != machine.Memory {
-		t.Errorf("Expected Memory %d, got %d", machine.Memory, ctx.Memory)
-	}
-	if ctx.OSInfo.System != machine.OSSystem {
-		t.Errorf("Expected OSInfo.System %s, got %s", machine.OSSystem, ctx.OSInfo.System)
-	}
-	if ctx.OSInfo.Release != machine.DistroSeries {
-		t.Errorf("Expected OSInfo.Release %s, got %s", machine.DistroSeries, ctx.OSInfo.Release)
-	}
-	if len(ctx.NetworkInterfaces) != len(machine.Interfaces) { // Changed from ctx.Networks
-		t.Errorf("Expected %d networks, got %d", len(machine.Interfaces), len(ctx.NetworkInterfaces)) // Changed from ctx.Networks
-	}
-	if len(ctx.BlockDevices) != len(machine.BlockDevices) { // Changed from ctx.Storage
-		t.Errorf("Expected %d storage devices, got %d", len(machine.BlockDevices), len(ctx.BlockDevices)) // Changed from ctx.Storage
-	}
-}
-
 func TestMachineJSONSerialization(t *testing.T) {
 	// Create a machine
 	machine := &Machine{
@@ -56,6 +56,9 @@ type PaginatedMachines struct {
 	Offset     int `json:"offset"`
 	Page       int `json:"page"`
 	PageCount  int `json:"page_count"`
+
+	// StorageReports explains, per evaluated machine, why it met the storage constraints or not
+	StorageReports []StorageMatchReport `json:"storage_reports,omitempty"`
 }
 
 // MachineDiscoveryOptions represents options for machine discovery
@@ -68,6 +71,9 @@ type MachineDiscoveryOptions struct {
 
 	// ScanStorage indicates whether to scan storage during discovery
 	ScanStorage bool `json:"scan_storage"`
+
+	// StorageConstraints keeps only machines whose disks satisfy every constraint
+	StorageConstraints []SimpleStorageConstraint `json:"storage_constraints,omitempty"`
 }
 
 // MachineDiscoveryResult represents the result of a machine discovery operation
@@ -80,4 +86,7 @@ type MachineDiscoveryResult struct {
 
 	// Status of the discovery operation
 	Status string `json:"status"`
+
+	// StorageReports explains, per evaluated machine, why it met the storage constraints or not
+	StorageReports []StorageMatchReport `json:"storage_reports,omitempty"`
 }
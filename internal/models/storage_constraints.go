@@ -24,6 +24,15 @@ const (
 	IDConstraint         StorageConstraintType = "id"   // For constraint ID, if managed by MAAS
 )
 
+// Storage constraint targets. Disk constraints select the machine's physical disks;
+// the other targets describe what ApplyStorageConstraints builds on top of them.
+const (
+	DiskTarget        = "disk"
+	PartitionTarget   = "partition"
+	RAIDTarget        = "raid"
+	VolumeGroupTarget = "volume_group"
+)
+
 // StorageConstraint represents a single rule for storage selection.
 type StorageConstraint struct {
 	Type       StorageConstraintType `json:"type"`                  // e.g., "size", "tag", "disk_type"
@@ -48,14 +57,211 @@ func (sc *StorageConstraint) Validate() error {
 		return fmt.Errorf("storage constraint value is required for type %s", sc.Type)
 	}
 
-	// TODO: Add more specific validation for Value and Operator based on Type
-	// For example, for SizeConstraint, Value should be parseable as a size.
-	// For CountConstraint, Value should be an integer.
-	// Operator validation could also be added here.
+	switch sc.Target() {
+	case DiskTarget, PartitionTarget, RAIDTarget, VolumeGroupTarget:
+	default:
+		return fmt.Errorf("unknown storage constraint target type: %s", sc.TargetType)
+	}
+
+	if _, err := NormalizeConstraintOperator(sc.Operator); err != nil {
+		return err
+	}
+
+	switch sc.Type {
+	case SizeConstraint:
+		if _, err := ParseStorageSize(sc.Value); err != nil {
+			return err
+		}
+	case CountConstraint, IOPSConstraint, ThroughputConstraint:
+		if _, err := strconv.ParseInt(sc.Value, 10, 64); err != nil {
+			return fmt.Errorf("storage constraint value for type %s must be an integer: %s", sc.Type, sc.Value)
+		}
+	}
 
 	return nil
 }
 
+// Target returns the constraint's target type, defaulting to "disk".
+func (sc *StorageConstraint) Target() string {
+	if sc.TargetType == "" {
+		return DiskTarget
+	}
+	return strings.ToLower(sc.TargetType)
+}
+
+// MatchesBlockDevice reports whether a single block device satisfies the constraint.
+// Count, IOPS and throughput constraints cannot be decided per device and return an error.
+func (sc *StorageConstraint) MatchesBlockDevice(device *BlockDevice) (bool, error) {
+	op, err := NormalizeConstraintOperator(sc.Operator)
+	if err != nil {
+		return false, err
+	}
+
+	switch sc.Type {
+	case SizeConstraint:
+		size, err := ParseStorageSize(sc.Value)
+		if err != nil {
+			return false, err
+		}
+		return compareConstraintInt(device.Size, size, op)
+	case TypeConstraint:
+		matched := blockDeviceIsKind(device, sc.Value)
+		if op == "neq" {
+			return !matched, nil
+		}
+		return matched, nil
+	case TagConstraint:
+		matched := false
+		for _, tag := range device.Tags {
+			if strings.EqualFold(tag, sc.Value) {
+				matched = true
+				break
+			}
+		}
+		if op == "neq" {
+			return !matched, nil
+		}
+		return matched, nil
+	case ModelConstraint:
+		return compareConstraintString(device.Model, sc.Value, op)
+	case SerialConstraint:
+		return compareConstraintString(device.Serial, sc.Value, op)
+	case PathConstraint:
+		if matched, err := compareConstraintString(device.Path, sc.Value, op); err != nil || matched {
+			return matched, err
+		}
+		return compareConstraintString(device.IDPath, sc.Value, op)
+	case NameConstraint:
+		return compareConstraintString(device.Name, sc.Value, op)
+	case IDConstraint:
+		return compareConstraintString(strconv.Itoa(device.ID), sc.Value, op)
+	default:
+		return false, fmt.Errorf("storage constraint type %s cannot be evaluated against a single block device", sc.Type)
+	}
+}
+
+// ParseStorageSize parses a size such as "500000000000", "100G", "1.5TB" or "512MiB" into bytes.
+// Decimal suffixes (K, M, G, T, P) are powers of 1000 as in MAAS; binary suffixes (Ki, Mi, ...) are powers of 1024.
+func ParseStorageSize(value string) (int64, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return 0, fmt.Errorf("storage size is required")
+	}
+
+	end := len(trimmed)
+	for end > 0 && strings.IndexByte("0123456789.", trimmed[end-1]) < 0 {
+		end--
+	}
+	number, suffix := trimmed[:end], strings.ToUpper(strings.TrimSpace(trimmed[end:]))
+
+	amount, err := strconv.ParseFloat(number, 64)
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("invalid storage size: %s", value)
+	}
+
+	base := 1000.0
+	if strings.HasSuffix(suffix, "IB") {
+		base = 1024
+		suffix = strings.TrimSuffix(suffix, "IB")
+	} else {
+		suffix = strings.TrimSuffix(suffix, "B")
+	}
+
+	exponents := map[string]int{"": 0, "K": 1, "M": 2, "G": 3, "T": 4, "P": 5}
+	exponent, ok := exponents[suffix]
+	if !ok {
+		return 0, fmt.Errorf("invalid storage size unit in %s", value)
+	}
+	for i := 0; i < exponent; i++ {
+		amount *= base
+	}
+
+	return int64(amount), nil
+}
+
+// NormalizeConstraintOperator maps the accepted operator spellings onto
+// eq, neq, gt, gte, lt, lte and contains. An empty operator means eq.
+func NormalizeConstraintOperator(op string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(op)) {
+	case "", "eq", "=", "==":
+		return "eq", nil
+	case "neq", "ne", "!=":
+		return "neq", nil
+	case "gt", ">":
+		return "gt", nil
+	case "gte", "ge", ">=":
+		return "gte", nil
+	case "lt", "<":
+		return "lt", nil
+	case "lte", "le", "<=":
+		return "lte", nil
+	case "contains":
+		return "contains", nil
+	default:
+		return "", fmt.Errorf("unknown storage constraint operator: %s", op)
+	}
+}
+
+// compareConstraintInt applies a normalized operator to two integers.
+func compareConstraintInt(actual, expected int64, op string) (bool, error) {
+	switch op {
+	case "eq":
+		return actual == expected, nil
+	case "neq":
+		return actual != expected, nil
+	case "gt":
+		return actual > expected, nil
+	case "gte":
+		return actual >= expected, nil
+	case "lt":
+		return actual < expected, nil
+	case "lte":
+		return actual <= expected, nil
+	default:
+		return false, fmt.Errorf("operator %s is not supported for numeric constraints", op)
+	}
+}
+
+// compareConstraintString applies a normalized operator to two strings, ignoring case.
+func compareConstraintString(actual, expected, op string) (bool, error) {
+	switch op {
+	case "eq":
+		return strings.EqualFold(actual, expected), nil
+	case "neq":
+		return !strings.EqualFold(actual, expected), nil
+	case "contains":
+		return strings.Contains(strings.ToLower(actual), strings.ToLower(expected)), nil
+	default:
+		return false, fmt.Errorf("operator %s is not supported for string constraints", op)
+	}
+}
+
+// blockDeviceIsKind reports whether a block device is of the given kind.
+// MAAS tags physical disks "ssd" or "rotary" during commissioning; NVMe devices
+// are recognised by name, and "physical"/"virtual" compare against the device type.
+func blockDeviceIsKind(device *BlockDevice, kind string) bool {
+	kind = strings.ToLower(kind)
+	hasTag := func(name string) bool {
+		for _, tag := range device.Tags {
+			if strings.EqualFold(tag, name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch kind {
+	case "hdd", "rotary":
+		return hasTag("rotary") || hasTag("hdd")
+	case "ssd":
+		return hasTag("ssd") || strings.HasPrefix(device.Name, "nvme")
+	case "nvme":
+		return hasTag("nvme") || strings.HasPrefix(device.Name, "nvme")
+	default:
+		return strings.EqualFold(device.Type, kind) || hasTag(kind)
+	}
+}
+
 // StorageConstraintParams is used for setting or updating storage constraints.
 type StorageConstraintParams struct {
 	Constraints []StorageConstraint `json:"constraints"`
@@ -124,9 +330,8 @@ func (ssc *SimpleStorageConstraint) Validate() error {
 		return fmt.Errorf("min_throughput_mbps cannot be negative")
 	}
 
-	// Example validation for DiskType - adjust validTypes as needed
 	if ssc.DiskType != "" && ssc.DiskType != "any" {
-		validTypes := map[string]bool{"ssd": true, "hdd": true} // Example valid types
+		validTypes := map[string]bool{"ssd": true, "hdd": true, "nvme": true, "rotary": true}
 		if !validTypes[strings.ToLower(ssc.DiskType)] {
 			return fmt.Errorf("invalid disk type: %s", ssc.DiskType)
 		}
@@ -0,0 +1,345 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StorageRequirement is a set of disk constraints that a number of distinct disks must satisfy,
+// e.g. "2 NVMe disks of at least 1TB". Per-device constraints are combined with AND; a count
+// constraint gives the number of disks needed (default 1).
+type StorageRequirement struct {
+	Name        string              `json:"name,omitempty"`
+	Constraints []StorageConstraint `json:"constraints"`
+}
+
+// StorageConstraintCheck explains how a single constraint fared against a machine's disks.
+type StorageConstraintCheck struct {
+	Constraint      StorageConstraint `json:"constraint"`
+	Passed          bool              `json:"passed"`
+	MatchingDevices []string          `json:"matching_devices,omitempty"`
+	Reason          string            `json:"reason"`
+}
+
+// StorageRequirementReport explains how a single requirement fared against a machine's disks.
+type StorageRequirementReport struct {
+	Name            string                   `json:"name,omitempty"`
+	Required        int                      `json:"required"`
+	Candidates      []string                 `json:"candidates"`
+	AssignedDevices []string                 `json:"assigned_devices,omitempty"`
+	Satisfied       bool                     `json:"satisfied"`
+	Checks          []StorageConstraintCheck `json:"checks"`
+	Reason          string                   `json:"reason"`
+}
+
+// StorageMatchReport is the result of evaluating storage requirements against one machine.
+// Each requirement is satisfied by its own disks; a disk is never counted twice.
+type StorageMatchReport struct {
+	SystemID     string                     `json:"system_id"`
+	Hostname     string                     `json:"hostname,omitempty"`
+	Matched      bool                       `json:"matched"`
+	Requirements []StorageRequirementReport `json:"requirements"`
+	Summary      string                     `json:"summary"`
+}
+
+// ToStorageRequirement converts a SimpleStorageConstraint into a requirement for Count disks.
+func (ssc *SimpleStorageConstraint) ToStorageRequirement() StorageRequirement {
+	return StorageRequirement{
+		Name:        ssc.Name,
+		Constraints: ssc.ToStorageConstraints(),
+	}
+}
+
+// EvaluateSimpleStorageConstraints evaluates each SimpleStorageConstraint as a separate requirement.
+func EvaluateSimpleStorageConstraints(machine *Machine, constraints ...SimpleStorageConstraint) *StorageMatchReport {
+	requirements := make([]StorageRequirement, len(constraints))
+	for i := range constraints {
+		requirements[i] = constraints[i].ToStorageRequirement()
+	}
+	return EvaluateStorageRequirements(machine, requirements)
+}
+
+// EvaluateStorageConstraints evaluates the disk-target constraints as a single requirement.
+func EvaluateStorageConstraints(machine *Machine, constraints []StorageConstraint) *StorageMatchReport {
+	requirement := StorageRequirement{}
+	for _, constraint := range constraints {
+		if constraint.Target() == DiskTarget {
+			requirement.Constraints = append(requirement.Constraints, constraint)
+		}
+	}
+	return EvaluateStorageRequirements(machine, []StorageRequirement{requirement})
+}
+
+// EvaluateStorageRequirements checks a machine's physical disks against the requirements
+// and reports, per requirement and per constraint, which disks matched and why it passed or failed.
+func EvaluateStorageRequirements(machine *Machine, requirements []StorageRequirement) *StorageMatchReport {
+	report := &StorageMatchReport{
+		SystemID:     machine.SystemID,
+		Hostname:     machine.Hostname,
+		Requirements: make([]StorageRequirementReport, len(requirements)),
+	}
+
+	var devices []BlockDevice
+	for _, device := range machine.BlockDevices {
+		if device.Type == "" || device.Type == "physical" {
+			devices = append(devices, device)
+		}
+	}
+
+	// candidates[i] holds the indexes into devices that satisfy every constraint of requirement i
+	candidates := make([][]int, len(requirements))
+	maxCounts := make([]int, len(requirements))
+	for i, requirement := range requirements {
+		report.Requirements[i], candidates[i], maxCounts[i] = evaluateStorageRequirement(requirement, devices)
+	}
+
+	assignments := assignStorageDevices(candidates, report.Requirements, len(devices))
+
+	var failures []string
+	for i := range report.Requirements {
+		requirementReport := &report.Requirements[i]
+		label := requirementLabel(requirements[i], i)
+
+		for _, deviceIndex := range assignments[i] {
+			requirementReport.AssignedDevices = append(requirementReport.AssignedDevices, blockDeviceLabel(&devices[deviceIndex]))
+		}
+
+		switch {
+		case requirementReport.Reason != "":
+			// A constraint could not be evaluated; the reason is already set
+		case len(devices) == 0:
+			requirementReport.Reason = "machine reports no physical block devices"
+		case len(requirementReport.Candidates) < requirementReport.Required:
+			requirementReport.Reason = fmt.Sprintf("needs %d disk(s), %d match: %s", requirementReport.Required, len(requirementReport.Candidates), failedChecks(requirementReport.Checks))
+		case maxCounts[i] >= 0 && len(requirementReport.Candidates) > maxCounts[i]:
+			requirementReport.Reason = fmt.Sprintf("allows at most %d matching disk(s), %d match", maxCounts[i], len(requirementReport.Candidates))
+		case len(assignments[i]) < requirementReport.Required:
+			requirementReport.Reason = fmt.Sprintf("needs %d disk(s) not used by other requirements, %d available", requirementReport.Required, len(assignments[i]))
+		default:
+			requirementReport.Satisfied = true
+			requirementReport.Reason = fmt.Sprintf("satisfied by %s", strings.Join(requirementReport.AssignedDevices, ", "))
+		}
+
+		if !requirementReport.Satisfied {
+			failures = append(failures, fmt.Sprintf("%s %s", label, requirementReport.Reason))
+		}
+	}
+
+	report.Matched = len(failures) == 0
+	if report.Matched {
+		report.Summary = fmt.Sprintf("all %d storage requirement(s) satisfied", len(requirements))
+	} else {
+		report.Summary = strings.Join(failures, "; ")
+	}
+
+	return report
+}
+
+// evaluateStorageRequirement checks every constraint of a requirement against the devices. It returns
+// the report, the indexes of the devices matching all constraints and the maximum allowed count (-1 for none).
+func evaluateStorageRequirement(requirement StorageRequirement, devices []BlockDevice) (StorageRequirementReport, []int, int) {
+	report := StorageRequirementReport{
+		Name:       requirement.Name,
+		Required:   1,
+		Candidates: []string{},
+		Checks:     []StorageConstraintCheck{},
+	}
+	maxCount := -1
+
+	matchesAll := make([]bool, len(devices))
+	for i := range matchesAll {
+		matchesAll[i] = true
+	}
+
+	for _, constraint := range requirement.Constraints {
+		check := StorageConstraintCheck{Constraint: constraint}
+
+		if constraint.Type == CountConstraint {
+			minCount, limit, err := ParseCountConstraint(constraint)
+			if err != nil {
+				check.Reason = err.Error()
+				report.Reason = err.Error()
+			} else {
+				report.Required = minCount
+				maxCount = limit
+				check.Passed = true
+				check.Reason = fmt.Sprintf("requires %s %s matching disk(s)", countOperatorPhrase(constraint.Operator), constraint.Value)
+			}
+			report.Checks = append(report.Checks, check)
+			continue
+		}
+
+		for i := range devices {
+			matched, err := constraint.MatchesBlockDevice(&devices[i])
+			if err != nil {
+				check.Reason = err.Error()
+				report.Reason = fmt.Sprintf("cannot evaluate %s constraint: %v", constraint.Type, err)
+				matchesAll = make([]bool, len(devices))
+				break
+			}
+			if matched {
+				check.MatchingDevices = append(check.MatchingDevices, blockDeviceLabel(&devices[i]))
+			} else {
+				matchesAll[i] = false
+			}
+		}
+
+		if check.Reason == "" {
+			check.Passed = len(check.MatchingDevices) > 0
+			check.Reason = fmt.Sprintf("%d of %d disk(s) have %s", len(check.MatchingDevices), len(devices), describeStorageConstraint(constraint))
+		}
+		report.Checks = append(report.Checks, check)
+	}
+
+	var candidates []int
+	if report.Reason == "" {
+		for i, matched := range matchesAll {
+			if matched {
+				candidates = append(candidates, i)
+				report.Candidates = append(report.Candidates, blockDeviceLabel(&devices[i]))
+			}
+		}
+	}
+
+	return report, candidates, maxCount
+}
+
+// assignStorageDevices gives each requirement its own disks so that a disk is never used twice.
+// It is a bipartite matching of requirement slots to candidate disks (Kuhn's algorithm), so a
+// requirement with few candidates is not starved by one that could have used other disks.
+func assignStorageDevices(candidates [][]int, reports []StorageRequirementReport, deviceCount int) [][]int {
+	// slots[n] is the requirement the n-th needed disk belongs to
+	var slots []int
+	for i, report := range reports {
+		if report.Reason != "" {
+			continue
+		}
+		for n := 0; n < report.Required; n++ {
+			slots = append(slots, i)
+		}
+	}
+
+	owner := make([]int, deviceCount) // device index -> slot index, -1 when free
+	for i := range owner {
+		owner[i] = -1
+	}
+
+	var try func(slot int, visited []bool) bool
+	try = func(slot int, visited []bool) bool {
+		for _, device := range candidates[slots[slot]] {
+			if visited[device] {
+				continue
+			}
+			visited[device] = true
+			if owner[device] < 0 || try(owner[device], visited) {
+				owner[device] = slot
+				return true
+			}
+		}
+		return false
+	}
+
+	for slot := range slots {
+		try(slot, make([]bool, deviceCount))
+	}
+
+	assignments := make([][]int, len(reports))
+	for device, slot := range owner {
+		if slot >= 0 {
+			assignments[slots[slot]] = append(assignments[slots[slot]], device)
+		}
+	}
+	return assignments
+}
+
+// ParseCountConstraint returns the minimum number of disks and the maximum (-1 for no maximum).
+// An omitted operator means "at least".
+func ParseCountConstraint(constraint StorageConstraint) (int, int, error) {
+	value, err := strconv.Atoi(constraint.Value)
+	if err != nil || value < 0 {
+		return 0, 0, fmt.Errorf("count constraint value must be a non-negative integer: %s", constraint.Value)
+	}
+
+	op := "gte"
+	if constraint.Operator != "" {
+		if op, err = NormalizeConstraintOperator(constraint.Operator); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	switch op {
+	case "gte":
+		return value, -1, nil
+	case "gt":
+		return value + 1, -1, nil
+	case "eq":
+		return value, value, nil
+	case "lte":
+		return 0, value, nil
+	case "lt":
+		if value == 0 {
+			return 0, 0, fmt.Errorf("count constraint < 0 can never be satisfied")
+		}
+		return 0, value - 1, nil
+	default:
+		return 0, 0, fmt.Errorf("operator %s is not supported for count constraints", constraint.Operator)
+	}
+}
+
+// countOperatorPhrase describes a count operator in words.
+func countOperatorPhrase(operator string) string {
+	op := "gte"
+	if operator != "" {
+		op, _ = NormalizeConstraintOperator(operator)
+	}
+	switch op {
+	case "eq":
+		return "exactly"
+	case "gt":
+		return "more than"
+	case "lte":
+		return "at most"
+	case "lt":
+		return "fewer than"
+	default:
+		return "at least"
+	}
+}
+
+// describeStorageConstraint renders a constraint as a short phrase for match reports.
+func describeStorageConstraint(constraint StorageConstraint) string {
+	op, _ := NormalizeConstraintOperator(constraint.Operator)
+	symbols := map[string]string{"eq": "=", "neq": "!=", "gt": ">", "gte": ">=", "lt": "<", "lte": "<=", "contains": "containing"}
+	return fmt.Sprintf("%s %s %s", constraint.Type, symbols[op], constraint.Value)
+}
+
+// failedChecks lists the reasons of the checks that did not pass.
+func failedChecks(checks []StorageConstraintCheck) string {
+	var reasons []string
+	for _, check := range checks {
+		if !check.Passed {
+			reasons = append(reasons, check.Reason)
+		}
+	}
+	if len(reasons) == 0 {
+		return "no single disk satisfies all constraints together"
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// requirementLabel names a requirement in report summaries.
+func requirementLabel(requirement StorageRequirement, index int) string {
+	if requirement.Name != "" {
+		return fmt.Sprintf("requirement %q", requirement.Name)
+	}
+	return fmt.Sprintf("requirement %d", index+1)
+}
+
+// blockDeviceLabel names a block device in reports.
+func blockDeviceLabel(device *BlockDevice) string {
+	if device.Name != "" {
+		return device.Name
+	}
+	return strconv.Itoa(device.ID)
+}
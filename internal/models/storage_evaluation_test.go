@@ -0,0 +1,131 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const terabyte = int64(1000 * 1000 * 1000 * 1000)
+
+func TestEvaluateSimpleStorageConstraints(t *testing.T) {
+	// Two NVMe disks of at least 1TB and an SSD tagged "fast"
+	requirements := []SimpleStorageConstraint{
+		{Name: "data", DiskType: "nvme", MinSize: terabyte, Count: 2},
+		{Name: "cache", DiskType: "ssd", Tags: []string{"fast"}},
+	}
+
+	tests := []struct {
+		name            string
+		devices         []BlockDevice
+		expectMatched   bool
+		expectSatisfied []bool
+		expectAssigned  [][]string
+	}{
+		{
+			name: "All requirements satisfied",
+			devices: []BlockDevice{
+				{ID: 1, Name: "nvme0n1", Type: "physical", Size: 2 * terabyte},
+				{ID: 2, Name: "nvme1n1", Type: "physical", Size: 2 * terabyte},
+				{ID: 3, Name: "sda", Type: "physical", Size: terabyte / 2, Tags: []string{"ssd", "fast"}},
+			},
+			expectMatched:   true,
+			expectSatisfied: []bool{true, true},
+			expectAssigned:  [][]string{{"nvme0n1", "nvme1n1"}, {"sda"}},
+		},
+		{
+			name: "NVMe disk too small",
+			devices: []BlockDevice{
+				{ID: 1, Name: "nvme0n1", Type: "physical", Size: 2 * terabyte},
+				{ID: 2, Name: "nvme1n1", Type: "physical", Size: terabyte / 2},
+				{ID: 3, Name: "sda", Type: "physical", Size: terabyte / 2, Tags: []string{"ssd", "fast"}},
+			},
+			expectMatched:   false,
+			expectSatisfied: []bool{false, true},
+			expectAssigned:  [][]string{{"nvme0n1"}, {"sda"}},
+		},
+		{
+			name: "Disks are not shared between requirements",
+			devices: []BlockDevice{
+				{ID: 1, Name: "nvme0n1", Type: "physical", Size: 2 * terabyte, Tags: []string{"fast"}},
+				{ID: 2, Name: "nvme1n1", Type: "physical", Size: 2 * terabyte},
+			},
+			expectMatched:   false,
+			expectSatisfied: []bool{true, false},
+			expectAssigned:  [][]string{{"nvme0n1", "nvme1n1"}, nil},
+		},
+		{
+			name: "Virtual devices are ignored",
+			devices: []BlockDevice{
+				{ID: 1, Name: "nvme0n1", Type: "physical", Size: 2 * terabyte},
+				{ID: 2, Name: "md0", Type: "virtual", Size: 2 * terabyte, Tags: []string{"ssd", "fast"}},
+			},
+			expectMatched:   false,
+			expectSatisfied: []bool{false, false},
+			expectAssigned:  [][]string{{"nvme0n1"}, nil},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			machine := &Machine{SystemID: "abc123", Hostname: "node1", BlockDevices: tc.devices}
+
+			report := EvaluateSimpleStorageConstraints(machine, requirements...)
+
+			assert.Equal(t, "abc123", report.SystemID)
+			assert.Equal(t, tc.expectMatched, report.Matched, report.Summary)
+			require.Len(t, report.Requirements, len(requirements))
+			for i, requirement := range report.Requirements {
+				assert.Equal(t, tc.expectSatisfied[i], requirement.Satisfied, requirement.Reason)
+				assert.Equal(t, tc.expectAssigned[i], requirement.AssignedDevices)
+				assert.NotEmpty(t, requirement.Reason)
+			}
+			assert.NotEmpty(t, report.Summary)
+		})
+	}
+}
+
+func TestEvaluateStorageRequirementsReasons(t *testing.T) {
+	machine := &Machine{
+		SystemID: "abc123",
+		BlockDevices: []BlockDevice{
+			{ID: 1, Name: "sda", Type: "physical", Size: terabyte, Tags: []string{"ssd"}},
+			{ID: 2, Name: "sdb", Type: "physical", Size: terabyte, Tags: []string{"rotary"}},
+		},
+	}
+
+	t.Run("Failed check names the constraint", func(t *testing.T) {
+		report := EvaluateSimpleStorageConstraints(machine, SimpleStorageConstraint{DiskType: "ssd", Count: 2})
+
+		assert.False(t, report.Matched)
+		requirement := report.Requirements[0]
+		assert.Equal(t, 2, requirement.Required)
+		assert.Equal(t, []string{"sda"}, requirement.Candidates)
+		assert.Contains(t, requirement.Reason, "needs 2 disk(s), 1 match")
+	})
+
+	t.Run("Exact count rejects extra disks", func(t *testing.T) {
+		report := EvaluateStorageConstraints(machine, []StorageConstraint{
+			{Type: SizeConstraint, Value: "500G", Operator: ">="},
+			{Type: CountConstraint, Value: "1", Operator: "eq"},
+		})
+
+		assert.False(t, report.Matched)
+		assert.Contains(t, report.Requirements[0].Reason, "at most 1")
+	})
+
+	t.Run("No disks", func(t *testing.T) {
+		report := EvaluateSimpleStorageConstraints(&Machine{SystemID: "def456"}, SimpleStorageConstraint{MinSize: terabyte})
+
+		assert.False(t, report.Matched)
+		assert.Contains(t, report.Summary, "no physical block devices")
+	})
+
+	t.Run("No requirements", func(t *testing.T) {
+		report := EvaluateSimpleStorageConstraints(machine)
+
+		assert.True(t, report.Matched)
+		assert.Empty(t, report.Requirements)
+	})
+}
@@ -53,10 +53,10 @@ func TestSimpleStorageConstraint_Validate(t *testing.T) {
 		{
 			name: "Invalid constraint - Invalid DiskType",
 			constraint: SimpleStorageConstraint{
-				DiskType: "nvme",
+				DiskType: "tape",
 			},
 			expectErr:   true,
-			expectedMsg: "invalid disk type: nvme",
+			expectedMsg: "invalid disk type: tape",
 		},
 		{
 			name: "Invalid constraint - Negative Count",
@@ -78,6 +78,15 @@ func (c *MaasServerConfig) Validate() error {
 type ServerConfig struct {
 	Host string `json:"host" mapstructure:"host" validate:"required"`
 	Port int    `json:"port" mapstructure:"port" validate:"required,min=1,max=65535"`
+	// StdioWorkers is the number of stdio requests processed at once; zero uses the default
+	StdioWorkers int `json:"stdioWorkers,omitempty" mapstructure:"stdio_workers" validate:"min=0"`
+	// StdioMaxOutstanding caps the requests a stdio session may have queued or running; zero uses the default
+	StdioMaxOutstanding int `json:"stdioMaxOutstanding,omitempty" mapstructure:"stdio_max_outstanding" validate:"min=0"`
+	// SSEQueueSize is the number of events queued for each SSE client in HTTP mode; zero uses the default
+	SSEQueueSize int `json:"sseQueueSize,omitempty" mapstructure:"sse_queue_size" validate:"min=0"`
+	// SSESlowClientPolicy is what happens to an SSE client whose queue is full: "drop" its events or
+	// "disconnect" it; empty uses "drop"
+	SSESlowClientPolicy string `json:"sseSlowClientPolicy,omitempty" mapstructure:"sse_slow_client_policy" validate:"omitempty,oneof=drop disconnect"`
 }
 
 // AuthConfig represents the authentication configuration
@@ -92,6 +101,8 @@ type AuthConfig struct {
 	JWT         JWTConfig        `json:"jwt" mapstructure:"jwt"`
 	TokenConfig TokenStoreConfig `json:"tokenStore" mapstructure:"token_store"`
 	IPWhitelist []string         `json:"ipWhitelist" mapstructure:"ip_whitelist"`
+	// UserToolProfiles maps usernames to a tool profile that narrows the tools of the instance for them
+	UserToolProfiles map[string]string `json:"userToolProfiles" mapstructure:"user_tool_profiles"`
 }
 
 // OAuthConfig represents the OAuth authentication configuration
@@ -175,6 +186,26 @@ type CORSConfig struct {
 	MaxAge           int      `json:"maxAge" mapstructure:"max_age" validate:"min=0"` // Max age in seconds
 }
 
+// FullToolProfile is the name of the tool profile that offers every tool. It is used when no
+// profile is chosen, and need not be configured.
+const FullToolProfile = "full"
+
+// ToolProfileConfig is a named subset of the MCP tools. A tool is in the profile when it matches
+// one of the include selectors, or there are none, and none of the exclude selectors.
+type ToolProfileConfig struct {
+	Include []ToolSelector `json:"include" mapstructure:"include"`
+	Exclude []ToolSelector `json:"exclude" mapstructure:"exclude"`
+}
+
+// ToolSelector matches tools by name, HTTP method and tag. A tool matches when, for every list
+// that is set, one of its patterns matches. Patterns are globs such as "maas_get_*", or regular
+// expressions when prefixed with "re:", such as "re:_op-(deploy|release)$".
+type ToolSelector struct {
+	Names   []string `json:"names" mapstructure:"names"`
+	Methods []string `json:"methods" mapstructure:"methods"`
+	Tags    []string `json:"tags" mapstructure:"tags"`
+}
+
 // AppConfig represents the complete application configuration
 type AppConfig struct {
 	Server        ServerConfig                  `json:"server" mapstructure:"server"`
@@ -182,13 +213,26 @@ type AppConfig struct {
 	Auth          AuthConfig                    `json:"auth" mapstructure:"auth"`
 	Logging       LoggingConfig                 `json:"logging" mapstructure:"logging"`
 	CORS          CORSConfig                    `json:"cors" mapstructure:"cors"`
-	LastUpdated   time.Time                     `json:"lastUpdated"`
+	// ToolProfiles are the named tool profiles that instances and users can be limited to
+	ToolProfiles map[string]ToolProfileConfig `json:"toolProfiles" mapstructure:"tool_profiles"`
+	LastUpdated  time.Time                    `json:"lastUpdated"`
 }
 
 // MAASInstanceConfig stores the configuration for a single MAAS instance.
 type MAASInstanceConfig struct {
 	APIURL string `json:"apiUrl" mapstructure:"api_url" validate:"required,url"`
 	APIKey string `json:"apiKey" mapstructure:"api_key" validate:"required"`
+	// ToolProfile is the tool profile offered for the instance; empty offers every tool
+	ToolProfile string `json:"toolProfile,omitempty" mapstructure:"tool_profile"`
+}
+
+// HasToolProfile reports whether name is the full profile or a configured tool profile
+func (c *AppConfig) HasToolProfile(name string) bool {
+	if name == FullToolProfile {
+		return true
+	}
+	_, ok := c.ToolProfiles[name]
+	return ok
 }
 
 // Validate checks if the AppConfig has all required fields
@@ -200,6 +244,17 @@ func (c *AppConfig) Validate() error {
 	if c.Server.Port <= 0 || c.Server.Port > 65535 {
 		return fmt.Errorf("server port must be between 1 and 65535")
 	}
+	if c.Server.StdioWorkers < 0 || c.Server.StdioMaxOutstanding < 0 {
+		return fmt.Errorf("stdio worker and outstanding request limits must not be negative")
+	}
+	if c.Server.SSEQueueSize < 0 {
+		return fmt.Errorf("SSE queue size must not be negative")
+	}
+	switch c.Server.SSESlowClientPolicy {
+	case "", "drop", "disconnect":
+	default:
+		return fmt.Errorf("SSE slow client policy must be 'drop' or 'disconnect', not '%s'", c.Server.SSESlowClientPolicy)
+	}
 
 	// Validate MAAS instances
 	if len(c.MAASInstances) == 0 {
@@ -212,6 +267,16 @@ func (c *AppConfig) Validate() error {
 		if instance.APIKey == "" {
 			return fmt.Errorf("MAAS instance '%s' is missing API Key", name)
 		}
+		if instance.ToolProfile != "" && !c.HasToolProfile(instance.ToolProfile) {
+			return fmt.Errorf("MAAS instance '%s' uses unknown tool profile '%s'", name, instance.ToolProfile)
+		}
+	}
+
+	// Validate tool profiles
+	for user, profile := range c.Auth.UserToolProfiles {
+		if !c.HasToolProfile(profile) {
+			return fmt.Errorf("user '%s' uses unknown tool profile '%s'", user, profile)
+		}
 	}
 
 	// Validate auth config
@@ -2,9 +2,12 @@ package types
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"strconv"
 
 	"github.com/canonical/gomaasclient/entity"
+	entitysubnet "github.com/canonical/gomaasclient/entity/subnet"
 )
 
 // Machine represents a MAAS machine entity
@@ -87,6 +90,14 @@ func (m *Machine) FromEntity(entity *entity.Machine) {
 	// Handle Description
 	m.Description = entity.Description
 
+	// The machine listing carries the physical disks, which storage constraint checks need
+	if len(entity.PhysicalBlockDeviceSet) > 0 {
+		m.BlockDevices = make([]BlockDevice, len(entity.PhysicalBlockDeviceSet))
+		for i := range entity.PhysicalBlockDeviceSet {
+			m.BlockDevices[i].FromEntity(&entity.PhysicalBlockDeviceSet[i])
+		}
+	}
+
 	// Initialize empty metadata map
 	m.Metadata = make(map[string]string)
 	// Add some basic metadata from available fields
@@ -94,6 +105,30 @@ func (m *Machine) FromEntity(entity *entity.Machine) {
 	m.Metadata["hostname"] = entity.Hostname
 }
 
+// MachineEvent represents an entry of a machine's MAAS event log
+type MachineEvent struct {
+	ID          int    `json:"id"`
+	Node        string `json:"node"`
+	Hostname    string `json:"hostname,omitempty"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Level       string `json:"level"`
+	Created     string `json:"created"`
+	Username    string `json:"username,omitempty"`
+}
+
+// FromEntity converts a gomaasclient entity.Event to our MachineEvent model
+func (e *MachineEvent) FromEntity(entity *entity.Event) {
+	e.ID = entity.ID
+	e.Node = entity.Node
+	e.Hostname = entity.Hostname
+	e.Type = entity.Type
+	e.Description = entity.Description
+	e.Level = string(entity.Level)
+	e.Created = entity.Created
+	e.Username = entity.UserName
+}
+
 // Subnet represents a MAAS subnet entity
 type Subnet struct {
 	ID          int      `json:"id"`
@@ -404,6 +439,279 @@ func (t *Tag) FromEntity(entity *entity.Tag) {
 	t.ResourceURL = entity.ResourceURI
 }
 
+// Space represents a MAAS network space
+type Space struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Subnets     []Subnet `json:"subnets"`
+	VLANs       []VLAN   `json:"vlans"`
+	ResourceURL string   `json:"resource_url"`
+}
+
+// FromEntity converts a gomaasclient entity.Space to our Space model
+func (s *Space) FromEntity(entity *entity.Space) {
+	s.ID = entity.ID
+	s.Name = entity.Name
+
+	s.Subnets = make([]Subnet, len(entity.Subnets))
+	for i := range entity.Subnets {
+		s.Subnets[i].FromEntity(&entity.Subnets[i])
+	}
+
+	s.VLANs = make([]VLAN, len(entity.VLANs))
+	for i := range entity.VLANs {
+		s.VLANs[i].FromEntity(&entity.VLANs[i])
+	}
+
+	s.ResourceURL = entity.ResourceURI
+}
+
+// IPRange types in MAAS: MAAS serves DHCP from dynamic ranges and never assigns addresses
+// from reserved ones
+const (
+	IPRangeTypeDynamic  = "dynamic"
+	IPRangeTypeReserved = "reserved"
+)
+
+// IPRange represents a reserved or dynamic IP range of a MAAS subnet
+type IPRange struct {
+	ID          int    `json:"id"`
+	Type        string `json:"type"`
+	StartIP     string `json:"start_ip"`
+	EndIP       string `json:"end_ip"`
+	SubnetID    int    `json:"subnet_id"`
+	SubnetCIDR  string `json:"subnet_cidr,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+	User        string `json:"user,omitempty"`
+	ResourceURL string `json:"resource_url"`
+}
+
+// Validate checks if the IPRange has all required fields
+func (r *IPRange) Validate() error {
+	if r.Type != IPRangeTypeDynamic && r.Type != IPRangeTypeReserved {
+		return fmt.Errorf("IP range type must be %q or %q", IPRangeTypeDynamic, IPRangeTypeReserved)
+	}
+	if r.StartIP == "" || r.EndIP == "" {
+		return fmt.Errorf("IP range start and end IPs are required")
+	}
+	return nil
+}
+
+// FromEntity converts a gomaasclient entity.IPRange to our IPRange model
+func (r *IPRange) FromEntity(entity *entity.IPRange) {
+	r.ID = entity.ID
+	r.Type = entity.Type
+	if entity.StartIP != nil {
+		r.StartIP = entity.StartIP.String()
+	}
+	if entity.EndIP != nil {
+		r.EndIP = entity.EndIP.String()
+	}
+	r.SubnetID = entity.Subnet.ID
+	r.SubnetCIDR = entity.Subnet.CIDR
+	r.Comment = entity.Comment
+	r.User = entity.User.UserName
+	r.ResourceURL = entity.ResourceURI
+}
+
+// StaticRoute represents a MAAS static route from a source subnet to a destination subnet
+type StaticRoute struct {
+	ID              int    `json:"id"`
+	SourceID        int    `json:"source_id"`
+	SourceCIDR      string `json:"source_cidr"`
+	DestinationID   int    `json:"destination_id"`
+	DestinationCIDR string `json:"destination_cidr"`
+	GatewayIP       string `json:"gateway_ip"`
+	Metric          int    `json:"metric"`
+	ResourceURL     string `json:"resource_url"`
+}
+
+// FromEntity converts a gomaasclient entity.StaticRoute to our StaticRoute model
+func (r *StaticRoute) FromEntity(entity *entity.StaticRoute) {
+	r.ID = entity.ID
+	r.SourceID = entity.Source.ID
+	r.SourceCIDR = entity.Source.CIDR
+	r.DestinationID = entity.Destination.ID
+	r.DestinationCIDR = entity.Destination.CIDR
+	r.GatewayIP = entity.GatewayIP
+	r.Metric = entity.Metric
+	r.ResourceURL = entity.ResourceURI
+}
+
+// SubnetStatistics represents the address usage MAAS reports for a subnet
+type SubnetStatistics struct {
+	TotalAddresses   int     `json:"total_addresses"`
+	NumAvailable     int     `json:"num_available"`
+	NumUnavailable   int     `json:"num_unavailable"`
+	LargestAvailable int     `json:"largest_available"`
+	Usage            float64 `json:"usage"`
+	UsageString      string  `json:"usage_string,omitempty"`
+	FirstAddress     string  `json:"first_address,omitempty"`
+	LastAddress      string  `json:"last_address,omitempty"`
+	IPVersion        int     `json:"ip_version"`
+}
+
+// FromEntity converts a gomaasclient subnet.Statistics to our SubnetStatistics model
+func (s *SubnetStatistics) FromEntity(entity *entitysubnet.Statistics) {
+	s.TotalAddresses = entity.TotalAddresses
+	s.NumAvailable = entity.NumAvailable
+	s.NumUnavailable = entity.NumUnavailable
+	s.LargestAvailable = entity.LargestAvailable
+	s.Usage = entity.Usage
+	s.UsageString = entity.UsageString
+	if entity.FirstAddress != nil {
+		s.FirstAddress = entity.FirstAddress.String()
+	}
+	if entity.LastAddress != nil {
+		s.LastAddress = entity.LastAddress.String()
+	}
+	s.IPVersion = entity.IPVersion
+}
+
+// SubnetIPAddress represents an address in use in a subnet, with the node it is assigned to
+type SubnetIPAddress struct {
+	IP        string `json:"ip"`
+	AllocType int    `json:"alloc_type"`
+	SystemID  string `json:"system_id,omitempty"`
+	Hostname  string `json:"hostname,omitempty"`
+	User      string `json:"user,omitempty"`
+	Created   string `json:"created,omitempty"`
+}
+
+// FromEntity converts a gomaasclient subnet.IPAddress to our SubnetIPAddress model
+func (a *SubnetIPAddress) FromEntity(entity *entitysubnet.IPAddress) {
+	if entity.IP != nil {
+		a.IP = entity.IP.String()
+	}
+	a.AllocType = entity.AllocType
+	a.SystemID = entity.NodeSummary.SystemID
+	a.Hostname = entity.NodeSummary.Hostname
+	a.User = entity.User
+	a.Created = entity.Created
+}
+
+// IPAddress represents an address allocated through the MAAS ipaddresses endpoint, such as a
+// static reservation
+type IPAddress struct {
+	IP            string   `json:"ip"`
+	AllocType     int      `json:"alloc_type"`
+	AllocTypeName string   `json:"alloc_type_name,omitempty"`
+	SubnetID      int      `json:"subnet_id"`
+	SubnetCIDR    string   `json:"subnet_cidr"`
+	Owner         string   `json:"owner,omitempty"`
+	Created       string   `json:"created,omitempty"`
+	MACAddresses  []string `json:"mac_addresses,omitempty"`
+	ResourceURL   string   `json:"resource_url"`
+}
+
+// FromEntity converts a gomaasclient entity.IPAddress to our IPAddress model
+func (a *IPAddress) FromEntity(entity *entity.IPAddress) {
+	if entity.IP != nil {
+		a.IP = entity.IP.String()
+	}
+	a.AllocType = entity.AllocType
+	a.AllocTypeName = entity.AllocTypeName
+	a.SubnetID = entity.Subnet.ID
+	a.SubnetCIDR = entity.Subnet.CIDR
+	a.Owner = entity.Owner.UserName
+	a.Created = entity.Created
+	a.MACAddresses = nil
+	for _, iface := range entity.InterfaceSet {
+		if iface.MACAddress != "" {
+			a.MACAddresses = append(a.MACAddresses, iface.MACAddress)
+		}
+	}
+	a.ResourceURL = entity.ResourceURI
+}
+
+// IPAddressReserveParams are the parameters of a MAAS ipaddresses reserve call. entity.IPAddressesParams
+// has no MAC or hostname, so reservations are posted with these instead.
+type IPAddressReserveParams struct {
+	// Subnet is the ID or CIDR of the subnet to reserve in; required unless IP is set
+	Subnet string `json:"subnet,omitempty"`
+	// IP is the address to reserve; MAAS picks a free one from Subnet when empty
+	IP string `json:"ip,omitempty"`
+	// MAC binds the reservation to a MAC address
+	MAC string `json:"mac,omitempty"`
+	// Hostname registers a DNS name for the address, in the default domain unless qualified
+	Hostname string `json:"hostname,omitempty"`
+}
+
+// Validate checks if the IPAddressReserveParams identify an address or subnet
+func (p *IPAddressReserveParams) Validate() error {
+	if p.Subnet == "" && p.IP == "" {
+		return fmt.Errorf("subnet or IP address is required")
+	}
+	if p.IP != "" && net.ParseIP(p.IP) == nil {
+		return fmt.Errorf("invalid IP address: %s", p.IP)
+	}
+	if p.MAC != "" {
+		if _, err := net.ParseMAC(p.MAC); err != nil {
+			return fmt.Errorf("invalid MAC address: %s", p.MAC)
+		}
+	}
+	return nil
+}
+
+// Values encodes the IPAddressReserveParams as MAAS API form values
+func (p *IPAddressReserveParams) Values() url.Values {
+	values := url.Values{}
+	if p.Subnet != "" {
+		values.Set("subnet", p.Subnet)
+	}
+	if p.IP != "" {
+		values.Set("ip", p.IP)
+	}
+	if p.MAC != "" {
+		values.Set("mac", p.MAC)
+	}
+	if p.Hostname != "" {
+		values.Set("hostname", p.Hostname)
+	}
+	return values
+}
+
+// Zone represents a MAAS availability zone
+type Zone struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	ResourceURL string `json:"resource_url"`
+}
+
+// FromEntity converts a gomaasclient entity.Zone to our Zone model
+func (z *Zone) FromEntity(entity *entity.Zone) {
+	z.ID = entity.ID
+	z.Name = entity.Name
+	z.Description = entity.Description
+	z.ResourceURL = entity.ResourceURI
+}
+
+// ResourcePool represents a MAAS resource pool
+type ResourcePool struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	ResourceURL string `json:"resource_url"`
+}
+
+// FromEntity converts a gomaasclient entity.ResourcePool to our ResourcePool model
+func (p *ResourcePool) FromEntity(entity *entity.ResourcePool) {
+	p.ID = entity.ID
+	p.Name = entity.Name
+	p.Description = entity.Description
+	p.ResourceURL = entity.ResourceURI
+}
+
+// CountMachinesByStatus counts machines by their MAAS status, such as "Ready" or "Deployed"
+func CountMachinesByStatus(machines []Machine) map[string]int {
+	counts := make(map[string]int)
+	for _, machine := range machines {
+		counts[machine.Status]++
+	}
+	return counts
+}
+
 // PartitionCreateParams represents parameters for creating a partition in MAAS
 type PartitionCreateParams struct {
 	Size   int64  `json:"size"`             // Size in bytes
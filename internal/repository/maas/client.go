@@ -6,14 +6,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	gomaasclient "github.com/canonical/gomaasclient/client"
 	"github.com/canonical/gomaasclient/entity"
+	entitysubnet "github.com/canonical/gomaasclient/entity/subnet"
 	"github.com/sirupsen/logrus"
 
+	ierrors "github.com/lspecian/maas-mcp-server/internal/errors"
 	"github.com/lspecian/maas-mcp-server/internal/models/maas"
 )
 
@@ -919,8 +922,146 @@ func (c *MAASClient) GetFabric(ctx context.Context, fabricID int) (*maas.Fabric,
 	return nil, TranslateError(fmt.Errorf("fabric with ID %d not found", fabricID), http.StatusNotFound)
 }
 
-// CreateSubnet creates a new subnet
+// subnetParamKeys are the parameters CreateSubnet and UpdateSubnet accept, as in the MAAS API
+var subnetParamKeys = []string{
+	"cidr", "name", "description", "vlan", "fabric", "vid", "gateway_ip", "dns_servers",
+	"rdns_mode", "allow_dns", "allow_proxy", "managed", "active_discovery",
+}
+
+// applySubnetParams sets the fields of subnetParams given in params. Values may be JSON decoded,
+// so numbers are accepted as float64 and IDs as numbers or strings. Parameters that are unknown or
+// of the wrong type are returned as an invalid input validation error with a detail for each.
+func applySubnetParams(subnetParams *entity.SubnetParams, params map[string]interface{}) error {
+	appErr := ierrors.NewValidationErrorWithCode(ierrors.ErrorCodeInvalidInput, "Invalid subnet parameters", nil)
+
+	for key, value := range params {
+		var err error
+		switch key {
+		case "cidr":
+			subnetParams.CIDR, err = subnetStringParam(value)
+		case "name":
+			subnetParams.Name, err = subnetStringParam(value)
+		case "description":
+			subnetParams.Description, err = subnetStringParam(value)
+		case "vlan":
+			subnetParams.VLAN, err = subnetStringParam(value)
+		case "fabric":
+			subnetParams.Fabric, err = subnetStringParam(value)
+		case "vid":
+			subnetParams.VID, err = subnetIntParam(value)
+		case "gateway_ip":
+			subnetParams.GatewayIP, err = subnetStringParam(value)
+		case "dns_servers":
+			subnetParams.DNSServers, err = subnetListParam(value)
+		case "rdns_mode":
+			subnetParams.RDNSMode, err = subnetIntParam(value)
+		case "allow_dns":
+			subnetParams.AllowDNS, err = subnetBoolParam(value)
+		case "allow_proxy":
+			subnetParams.AllowProxy, err = subnetBoolParam(value)
+		case "managed":
+			subnetParams.Managed, err = subnetBoolParam(value)
+		case "active_discovery":
+			subnetParams.ActiveDiscovery, err = subnetBoolParam(value)
+		default:
+			err = fmt.Errorf("unknown parameter; expected one of %s", strings.Join(subnetParamKeys, ", "))
+		}
+		if err != nil {
+			appErr.WithDetail(key, err.Error())
+		}
+	}
+
+	if len(appErr.Details) > 0 {
+		return appErr
+	}
+	return nil
+}
+
+// subnetStringParam returns a string parameter; numbers are accepted for IDs
+func subnetStringParam(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		if v == float64(int(v)) {
+			return strconv.Itoa(int(v)), nil
+		}
+	}
+	return "", fmt.Errorf("expected a string, got %v", value)
+}
+
+// subnetIntParam returns an integer parameter
+func subnetIntParam(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	case string:
+		if i, err := strconv.Atoi(v); err == nil {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("expected an integer, got %v", value)
+}
+
+// subnetBoolParam returns a boolean parameter
+func subnetBoolParam(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b, nil
+		}
+	}
+	return false, fmt.Errorf("expected a boolean, got %v", value)
+}
+
+// subnetListParam returns a list parameter, given as a list or as a comma or space separated string
+func subnetListParam(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case []string:
+		return v, nil
+	case string:
+		return strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' }), nil
+	case []interface{}:
+		list := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a list of strings, got %v", value)
+			}
+			list[i] = s
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("expected a list of strings, got %v", value)
+}
+
+// CreateSubnet creates a new subnet. The subnet is checked against the existing subnets, VLANs
+// and fabrics by a SubnetValidator before MAAS is called.
 func (c *MAASClient) CreateSubnet(ctx context.Context, params map[string]interface{}) (*maas.Subnet, error) {
+	// entity.SubnetParams always sends these, so start from the MAAS defaults
+	subnetParams := &entity.SubnetParams{
+		RDNSMode:   2,
+		AllowDNS:   true,
+		AllowProxy: true,
+		Managed:    true,
+	}
+	if err := applySubnetParams(subnetParams, params); err != nil {
+		return nil, err
+	}
+
+	// Validate before taking the lock; the validator lists through the client
+	if err := NewSubnetValidator(c).Validate(ctx, 0, subnetParams); err != nil {
+		return nil, err
+	}
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -928,15 +1069,96 @@ func (c *MAASClient) CreateSubnet(ctx context.Context, params map[string]interfa
 		return nil, fmt.Errorf("client is closed")
 	}
 
-	// Note: The gomaasclient library doesn't provide a direct method to create a subnet
-	// This is a placeholder implementation
-	c.logger.WithField("params", params).Warn("CreateSubnet operation is not directly supported by gomaasclient")
+	var entitySubnet *entity.Subnet
+	operation := func() error {
+		var err error
+		c.logger.WithFields(logrus.Fields{
+			"cidr": subnetParams.CIDR,
+			"vlan": subnetParams.VLAN,
+		}).Debug("Creating MAAS subnet")
+		entitySubnet, err = c.client.Subnets.Create(subnetParams)
+		if err != nil {
+			c.logger.WithError(err).WithField("cidr", subnetParams.CIDR).Error("Failed to create MAAS subnet")
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
+	}
 
-	return nil, fmt.Errorf("operation not supported by the underlying client library")
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	// Convert entity.Subnet to maas.Subnet
+	subnet := &maas.Subnet{}
+	subnet.FromEntity(entitySubnet)
+
+	return subnet, nil
 }
 
-// UpdateSubnet updates an existing subnet
+// UpdateSubnet updates an existing subnet; parameters not given keep their current values. The
+// updated subnet is checked against the existing subnets, VLANs, fabrics and its IP ranges by a
+// SubnetValidator before MAAS is called.
 func (c *MAASClient) UpdateSubnet(ctx context.Context, id int, params map[string]interface{}) (*maas.Subnet, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("valid subnet ID is required")
+	}
+
+	// MAAS replaces every field of a subnet on update, so start from the current ones
+	c.mu.RLock()
+	if c.closed {
+		c.mu.RUnlock()
+		return nil, fmt.Errorf("client is closed")
+	}
+	var current *entity.Subnet
+	err := c.retry(ctx, func() error {
+		var err error
+		current, err = c.client.Subnet.Get(id)
+		if err != nil {
+			c.logger.WithError(err).WithField("subnet_id", id).Error("Failed to get MAAS subnet")
+			if strings.Contains(err.Error(), "404") {
+				return TranslateError(err, http.StatusNotFound)
+			}
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
+	})
+	c.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	subnetParams := &entity.SubnetParams{
+		CIDR:            current.CIDR,
+		Name:            current.Name,
+		Description:     current.Description,
+		VLAN:            strconv.Itoa(current.VLAN.ID),
+		RDNSMode:        current.RDNSMode,
+		AllowDNS:        current.AllowDNS,
+		AllowProxy:      current.AllowProxy,
+		Managed:         current.Managed,
+		ActiveDiscovery: current.ActiveDiscovery,
+	}
+	if current.GatewayIP != nil {
+		subnetParams.GatewayIP = current.GatewayIP.String()
+	}
+	for _, server := range current.DNSServers {
+		subnetParams.DNSServers = append(subnetParams.DNSServers, server.String())
+	}
+	// A new fabric or VID picks the VLAN, rather than the current one
+	_, vlanGiven := params["vlan"]
+	_, fabricGiven := params["fabric"]
+	_, vidGiven := params["vid"]
+	if !vlanGiven && (fabricGiven || vidGiven) {
+		subnetParams.VLAN = ""
+	}
+	if err := applySubnetParams(subnetParams, params); err != nil {
+		return nil, err
+	}
+
+	if err := NewSubnetValidator(c).Validate(ctx, id, subnetParams); err != nil {
+		return nil, err
+	}
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -944,18 +1166,33 @@ func (c *MAASClient) UpdateSubnet(ctx context.Context, id int, params map[string
 		return nil, fmt.Errorf("client is closed")
 	}
 
-	if id <= 0 {
-		return nil, fmt.Errorf("valid subnet ID is required")
+	var entitySubnet *entity.Subnet
+	operation := func() error {
+		var err error
+		c.logger.WithFields(logrus.Fields{
+			"subnet_id": id,
+			"cidr":      subnetParams.CIDR,
+		}).Debug("Updating MAAS subnet")
+		entitySubnet, err = c.client.Subnet.Update(id, subnetParams)
+		if err != nil {
+			c.logger.WithError(err).WithField("subnet_id", id).Error("Failed to update MAAS subnet")
+			if strings.Contains(err.Error(), "404") {
+				return TranslateError(err, http.StatusNotFound)
+			}
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
 	}
 
-	// Note: The gomaasclient library doesn't provide a direct method to update a subnet
-	// This is a placeholder implementation
-	c.logger.WithFields(logrus.Fields{
-		"subnet_id": id,
-		"params":    params,
-	}).Warn("UpdateSubnet operation is not directly supported by gomaasclient")
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
 
-	return nil, fmt.Errorf("operation not supported by the underlying client library")
+	// Convert entity.Subnet to maas.Subnet
+	subnet := &maas.Subnet{}
+	subnet.FromEntity(entitySubnet)
+
+	return subnet, nil
 }
 
 // DeleteSubnet deletes a subnet
@@ -1021,10 +1258,8 @@ func (c *MAASClient) GetMachineInterfaces(ctx context.Context, systemID string)
 	return interfaces, nil
 }
 
-// ==================== Storage Operations ====================
-
-// GetMachineBlockDevices retrieves block devices for a specific machine
-func (c *MAASClient) GetMachineBlockDevices(ctx context.Context, systemID string) ([]maas.BlockDevice, error) {
+// GetSubnetStatistics retrieves the address usage MAAS reports for a subnet
+func (c *MAASClient) GetSubnetStatistics(ctx context.Context, id int) (*maas.SubnetStatistics, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -1032,17 +1267,17 @@ func (c *MAASClient) GetMachineBlockDevices(ctx context.Context, systemID string
 		return nil, fmt.Errorf("client is closed")
 	}
 
-	if systemID == "" {
-		return nil, fmt.Errorf("system ID is required")
+	if id <= 0 {
+		return nil, fmt.Errorf("valid subnet ID is required")
 	}
 
-	var entityDevices []entity.BlockDevice
+	var entityStats *entitysubnet.Statistics
 	operation := func() error {
 		var err error
-		c.logger.WithField("system_id", systemID).Debug("Getting MAAS machine block devices")
-		entityDevices, err = c.client.BlockDevices.Get(systemID)
+		c.logger.WithField("subnet_id", id).Debug("Getting MAAS subnet statistics")
+		entityStats, err = c.client.Subnet.GetStatistics(id)
 		if err != nil {
-			c.logger.WithError(err).WithField("system_id", systemID).Error("Failed to get MAAS machine block devices")
+			c.logger.WithError(err).WithField("subnet_id", id).Error("Failed to get MAAS subnet statistics")
 			if strings.Contains(err.Error(), "404") {
 				return TranslateError(err, http.StatusNotFound)
 			}
@@ -1055,19 +1290,15 @@ func (c *MAASClient) GetMachineBlockDevices(ctx context.Context, systemID string
 		return nil, err
 	}
 
-	// Convert entity.BlockDevice to maas.BlockDevice
-	devices := make([]maas.BlockDevice, len(entityDevices))
-	for i, entityDevice := range entityDevices {
-		var device maas.BlockDevice
-		device.FromEntity(&entityDevice)
-		devices[i] = device
-	}
+	// Convert entitysubnet.Statistics to maas.SubnetStatistics
+	stats := &maas.SubnetStatistics{}
+	stats.FromEntity(entityStats)
 
-	return devices, nil
+	return stats, nil
 }
 
-// CreateMachineBlockDevice creates a block device for a specific machine
-func (c *MAASClient) CreateMachineBlockDevice(ctx context.Context, systemID string, params map[string]interface{}) (*maas.BlockDevice, error) {
+// GetSubnetIPAddresses retrieves the addresses in use in a subnet
+func (c *MAASClient) GetSubnetIPAddresses(ctx context.Context, id int) ([]maas.SubnetIPAddress, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -1075,77 +1306,78 @@ func (c *MAASClient) CreateMachineBlockDevice(ctx context.Context, systemID stri
 		return nil, fmt.Errorf("client is closed")
 	}
 
-	if systemID == "" {
-		return nil, fmt.Errorf("system ID is required")
+	if id <= 0 {
+		return nil, fmt.Errorf("valid subnet ID is required")
 	}
 
-	// Note: The gomaasclient library doesn't provide a direct method to create a block device
-	// This is a placeholder implementation
-	c.logger.WithFields(logrus.Fields{
-		"system_id": systemID,
-		"params":    params,
-	}).Warn("CreateMachineBlockDevice operation is not directly supported by gomaasclient")
-
-	return nil, fmt.Errorf("operation not supported by the underlying client library")
-}
-
-// UpdateMachineBlockDevice updates a block device for a specific machine
-func (c *MAASClient) UpdateMachineBlockDevice(ctx context.Context, systemID string, blockDeviceID int, params map[string]interface{}) (*maas.BlockDevice, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	if c.closed {
-		return nil, fmt.Errorf("client is closed")
+	var entityAddresses []entitysubnet.IPAddress
+	operation := func() error {
+		var err error
+		c.logger.WithField("subnet_id", id).Debug("Getting MAAS subnet IP addresses")
+		entityAddresses, err = c.client.Subnet.GetIPAddresses(id)
+		if err != nil {
+			c.logger.WithError(err).WithField("subnet_id", id).Error("Failed to get MAAS subnet IP addresses")
+			if strings.Contains(err.Error(), "404") {
+				return TranslateError(err, http.StatusNotFound)
+			}
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
 	}
 
-	if systemID == "" {
-		return nil, fmt.Errorf("system ID is required")
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
 	}
 
-	if blockDeviceID <= 0 {
-		return nil, fmt.Errorf("valid block device ID is required")
+	// Convert entitysubnet.IPAddress to maas.SubnetIPAddress
+	addresses := make([]maas.SubnetIPAddress, len(entityAddresses))
+	for i, entityAddress := range entityAddresses {
+		var address maas.SubnetIPAddress
+		address.FromEntity(&entityAddress)
+		addresses[i] = address
 	}
 
-	// Note: The gomaasclient library doesn't provide a direct method to update a block device
-	// This is a placeholder implementation
-	c.logger.WithFields(logrus.Fields{
-		"system_id":       systemID,
-		"block_device_id": blockDeviceID,
-		"params":          params,
-	}).Warn("UpdateMachineBlockDevice operation is not directly supported by gomaasclient")
-
-	return nil, fmt.Errorf("operation not supported by the underlying client library")
+	return addresses, nil
 }
 
-// DeleteMachineBlockDevice deletes a block device for a specific machine
-func (c *MAASClient) DeleteMachineBlockDevice(ctx context.Context, systemID string, blockDeviceID int) error {
+// ListSpaces retrieves all spaces
+func (c *MAASClient) ListSpaces(ctx context.Context) ([]maas.Space, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if c.closed {
-		return fmt.Errorf("client is closed")
+		return nil, fmt.Errorf("client is closed")
 	}
 
-	if systemID == "" {
-		return fmt.Errorf("system ID is required")
+	var entitySpaces []entity.Space
+	operation := func() error {
+		var err error
+		c.logger.Debug("Listing MAAS spaces")
+		entitySpaces, err = c.client.Spaces.Get()
+		if err != nil {
+			c.logger.WithError(err).Error("Failed to list MAAS spaces")
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
 	}
 
-	if blockDeviceID <= 0 {
-		return fmt.Errorf("valid block device ID is required")
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
 	}
 
-	// Note: The gomaasclient library doesn't provide a direct method to delete a block device
-	// This is a placeholder implementation
-	c.logger.WithFields(logrus.Fields{
-		"system_id":       systemID,
-		"block_device_id": blockDeviceID,
-	}).Warn("DeleteMachineBlockDevice operation is not directly supported by gomaasclient")
+	// Convert entity.Space to maas.Space
+	spaces := make([]maas.Space, len(entitySpaces))
+	for i, entitySpace := range entitySpaces {
+		var space maas.Space
+		space.FromEntity(&entitySpace)
+		spaces[i] = space
+	}
 
-	return fmt.Errorf("operation not supported by the underlying client library")
+	return spaces, nil
 }
 
-// CreateMachinePartition creates a partition on a block device for a specific machine
-func (c *MAASClient) CreateMachinePartition(ctx context.Context, systemID string, blockDeviceID int, params map[string]interface{}) (*maas.Partition, error) {
+// GetSpace retrieves space details, including its subnets and VLANs
+func (c *MAASClient) GetSpace(ctx context.Context, id int) (*maas.Space, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -1153,53 +1385,22 @@ func (c *MAASClient) CreateMachinePartition(ctx context.Context, systemID string
 		return nil, fmt.Errorf("client is closed")
 	}
 
-	if systemID == "" {
-		return nil, fmt.Errorf("system ID is required")
-	}
-
-	if blockDeviceID <= 0 {
-		return nil, fmt.Errorf("valid block device ID is required")
-	}
-
-	// Validate required parameters
-	if _, ok := params["size"]; !ok {
-		return nil, fmt.Errorf("partition size is required")
+	if id < 0 {
+		return nil, fmt.Errorf("valid space ID is required")
 	}
 
-	// Construct the API endpoint
-	endpoint := fmt.Sprintf("/api/2.0/machines/%s/block-devices/%d/partitions/", systemID, blockDeviceID)
-
-	var partition maas.Partition
+	var entitySpace *entity.Space
 	operation := func() error {
-		c.logger.WithFields(logrus.Fields{
-			"system_id":       systemID,
-			"block_device_id": blockDeviceID,
-			"params":          params,
-		}).Debug("Creating partition on MAAS machine block device")
-
-		req, err := c.newRequest(ctx, "POST", endpoint, params)
-		if err != nil {
-			return err
-		}
-
-		resp, err := c.httpClient.Do(req)
+		var err error
+		c.logger.WithField("space_id", id).Debug("Getting MAAS space")
+		entitySpace, err = c.client.Space.Get(id)
 		if err != nil {
-			c.logger.WithError(err).Error("Failed to create partition")
-			return TranslateError(err, http.StatusInternalServerError)
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-			err = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-			c.logger.WithError(err).Error("Failed to create partition")
-			return TranslateError(err, resp.StatusCode)
-		}
-
-		if err := json.NewDecoder(resp.Body).Decode(&partition); err != nil {
-			c.logger.WithError(err).Error("Failed to decode partition response")
+			c.logger.WithError(err).WithField("space_id", id).Error("Failed to get MAAS space")
+			if strings.Contains(err.Error(), "404") {
+				return TranslateError(err, http.StatusNotFound)
+			}
 			return TranslateError(err, http.StatusInternalServerError)
 		}
-
 		return nil
 	}
 
@@ -1207,66 +1408,162 @@ func (c *MAASClient) CreateMachinePartition(ctx context.Context, systemID string
 		return nil, err
 	}
 
-	return &partition, nil
+	// Convert entity.Space to maas.Space
+	space := &maas.Space{}
+	space.FromEntity(entitySpace)
+
+	return space, nil
 }
 
-// DeleteMachinePartition deletes a partition from a block device for a specific machine
-func (c *MAASClient) DeleteMachinePartition(ctx context.Context, systemID string, blockDeviceID, partitionID int) error {
+// CreateSpace creates a new space
+func (c *MAASClient) CreateSpace(ctx context.Context, name string) (*maas.Space, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if c.closed {
-		return fmt.Errorf("client is closed")
+		return nil, fmt.Errorf("client is closed")
 	}
 
-	if systemID == "" {
-		return fmt.Errorf("system ID is required")
+	if name == "" {
+		return nil, fmt.Errorf("space name is required")
 	}
 
-	if blockDeviceID <= 0 {
-		return fmt.Errorf("valid block device ID is required")
+	var entitySpace *entity.Space
+	operation := func() error {
+		var err error
+		c.logger.WithField("space_name", name).Debug("Creating MAAS space")
+		entitySpace, err = c.client.Spaces.Create(name)
+		if err != nil {
+			c.logger.WithError(err).WithField("space_name", name).Error("Failed to create MAAS space")
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
 	}
 
-	if partitionID <= 0 {
-		return fmt.Errorf("valid partition ID is required")
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
 	}
 
-	// Construct the API endpoint
-	endpoint := fmt.Sprintf("/api/2.0/machines/%s/block-devices/%d/partitions/%d/", systemID, blockDeviceID, partitionID)
+	// Convert entity.Space to maas.Space
+	space := &maas.Space{}
+	space.FromEntity(entitySpace)
+
+	return space, nil
+}
+
+// UpdateSpace renames a space
+func (c *MAASClient) UpdateSpace(ctx context.Context, id int, name string) (*maas.Space, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	if id < 0 {
+		return nil, fmt.Errorf("valid space ID is required")
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("space name is required")
+	}
 
+	var entitySpace *entity.Space
 	operation := func() error {
+		var err error
 		c.logger.WithFields(logrus.Fields{
-			"system_id":       systemID,
-			"block_device_id": blockDeviceID,
-			"partition_id":    partitionID,
-		}).Debug("Deleting partition from MAAS machine block device")
-
-		req, err := c.newRequest(ctx, "DELETE", endpoint, nil)
+			"space_id":   id,
+			"space_name": name,
+		}).Debug("Updating MAAS space")
+		entitySpace, err = c.client.Space.Update(id, name)
 		if err != nil {
-			return err
+			c.logger.WithError(err).WithField("space_id", id).Error("Failed to update MAAS space")
+			if strings.Contains(err.Error(), "404") {
+				return TranslateError(err, http.StatusNotFound)
+			}
+			return TranslateError(err, http.StatusInternalServerError)
 		}
+		return nil
+	}
 
-		resp, err := c.httpClient.Do(req)
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	// Convert entity.Space to maas.Space
+	space := &maas.Space{}
+	space.FromEntity(entitySpace)
+
+	return space, nil
+}
+
+// DeleteSpace deletes a space
+func (c *MAASClient) DeleteSpace(ctx context.Context, id int) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return fmt.Errorf("client is closed")
+	}
+
+	if id < 0 {
+		return fmt.Errorf("valid space ID is required")
+	}
+
+	operation := func() error {
+		c.logger.WithField("space_id", id).Debug("Deleting MAAS space")
+		err := c.client.Space.Delete(id)
 		if err != nil {
-			c.logger.WithError(err).Error("Failed to delete partition")
+			c.logger.WithError(err).WithField("space_id", id).Error("Failed to delete MAAS space")
+			if strings.Contains(err.Error(), "404") {
+				return TranslateError(err, http.StatusNotFound)
+			}
 			return TranslateError(err, http.StatusInternalServerError)
 		}
-		defer resp.Body.Close()
+		return nil
+	}
 
-		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-			err = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-			c.logger.WithError(err).Error("Failed to delete partition")
-			return TranslateError(err, resp.StatusCode)
-		}
+	return c.retry(ctx, operation)
+}
+
+// ListIPRanges retrieves all reserved and dynamic IP ranges
+func (c *MAASClient) ListIPRanges(ctx context.Context) ([]maas.IPRange, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
 
+	var entityRanges []entity.IPRange
+	operation := func() error {
+		var err error
+		c.logger.Debug("Listing MAAS IP ranges")
+		entityRanges, err = c.client.IPRanges.Get()
+		if err != nil {
+			c.logger.WithError(err).Error("Failed to list MAAS IP ranges")
+			return TranslateError(err, http.StatusInternalServerError)
+		}
 		return nil
 	}
 
-	return c.retry(ctx, operation)
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	// Convert entity.IPRange to maas.IPRange
+	ranges := make([]maas.IPRange, len(entityRanges))
+	for i, entityRange := range entityRanges {
+		var ipRange maas.IPRange
+		ipRange.FromEntity(&entityRange)
+		ranges[i] = ipRange
+	}
+
+	return ranges, nil
 }
 
-// UpdateMachinePartition updates a partition on a block device for a specific machine
-func (c *MAASClient) UpdateMachinePartition(ctx context.Context, systemID string, blockDeviceID, partitionID int, params map[string]interface{}) (*maas.Partition, error) {
+// GetIPRange retrieves IP range details
+func (c *MAASClient) GetIPRange(ctx context.Context, id int) (*maas.IPRange, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -1274,53 +1571,1056 @@ func (c *MAASClient) UpdateMachinePartition(ctx context.Context, systemID string
 		return nil, fmt.Errorf("client is closed")
 	}
 
-	if systemID == "" {
-		return nil, fmt.Errorf("system ID is required")
+	if id <= 0 {
+		return nil, fmt.Errorf("valid IP range ID is required")
 	}
 
-	if blockDeviceID <= 0 {
-		return nil, fmt.Errorf("valid block device ID is required")
+	var entityRange *entity.IPRange
+	operation := func() error {
+		var err error
+		c.logger.WithField("ip_range_id", id).Debug("Getting MAAS IP range")
+		entityRange, err = c.client.IPRange.Get(id)
+		if err != nil {
+			c.logger.WithError(err).WithField("ip_range_id", id).Error("Failed to get MAAS IP range")
+			if strings.Contains(err.Error(), "404") {
+				return TranslateError(err, http.StatusNotFound)
+			}
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
 	}
 
-	if partitionID <= 0 {
-		return nil, fmt.Errorf("valid partition ID is required")
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
 	}
 
-	// Construct the API endpoint
-	endpoint := fmt.Sprintf("/api/2.0/machines/%s/block-devices/%d/partitions/%d/", systemID, blockDeviceID, partitionID)
+	// Convert entity.IPRange to maas.IPRange
+	ipRange := &maas.IPRange{}
+	ipRange.FromEntity(entityRange)
 
-	var partition maas.Partition
+	return ipRange, nil
+}
+
+// CreateIPRange creates a new reserved or dynamic IP range
+func (c *MAASClient) CreateIPRange(ctx context.Context, params *entity.IPRangeParams) (*maas.IPRange, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	if params == nil {
+		return nil, fmt.Errorf("IP range parameters are required")
+	}
+
+	if err := validateIPRangeParams(params); err != nil {
+		return nil, err
+	}
+
+	var entityRange *entity.IPRange
 	operation := func() error {
+		var err error
 		c.logger.WithFields(logrus.Fields{
-			"system_id":       systemID,
-			"block_device_id": blockDeviceID,
-			"partition_id":    partitionID,
-			"params":          params,
-		}).Debug("Updating partition on MAAS machine block device")
+			"type":     params.Type,
+			"subnet":   params.Subnet,
+			"start_ip": params.StartIP,
+			"end_ip":   params.EndIP,
+		}).Debug("Creating MAAS IP range")
+		entityRange, err = c.client.IPRanges.Create(params)
+		if err != nil {
+			c.logger.WithError(err).WithField("subnet", params.Subnet).Error("Failed to create MAAS IP range")
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
+	}
 
-		req, err := c.newRequest(ctx, "PUT", endpoint, params)
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	// Convert entity.IPRange to maas.IPRange
+	ipRange := &maas.IPRange{}
+	ipRange.FromEntity(entityRange)
+
+	return ipRange, nil
+}
+
+// UpdateIPRange updates an existing IP range. MAAS replaces every field of the range, so params
+// must hold the complete range.
+func (c *MAASClient) UpdateIPRange(ctx context.Context, id int, params *entity.IPRangeParams) (*maas.IPRange, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	if id <= 0 {
+		return nil, fmt.Errorf("valid IP range ID is required")
+	}
+
+	if params == nil {
+		return nil, fmt.Errorf("IP range parameters are required")
+	}
+
+	if err := validateIPRangeParams(params); err != nil {
+		return nil, err
+	}
+
+	var entityRange *entity.IPRange
+	operation := func() error {
+		var err error
+		c.logger.WithFields(logrus.Fields{
+			"ip_range_id": id,
+			"type":        params.Type,
+			"start_ip":    params.StartIP,
+			"end_ip":      params.EndIP,
+		}).Debug("Updating MAAS IP range")
+		entityRange, err = c.client.IPRange.Update(id, params)
 		if err != nil {
-			return err
+			c.logger.WithError(err).WithField("ip_range_id", id).Error("Failed to update MAAS IP range")
+			if strings.Contains(err.Error(), "404") {
+				return TranslateError(err, http.StatusNotFound)
+			}
+			return TranslateError(err, http.StatusInternalServerError)
 		}
+		return nil
+	}
 
-		resp, err := c.httpClient.Do(req)
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	// Convert entity.IPRange to maas.IPRange
+	ipRange := &maas.IPRange{}
+	ipRange.FromEntity(entityRange)
+
+	return ipRange, nil
+}
+
+// DeleteIPRange deletes an IP range
+func (c *MAASClient) DeleteIPRange(ctx context.Context, id int) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return fmt.Errorf("client is closed")
+	}
+
+	if id <= 0 {
+		return fmt.Errorf("valid IP range ID is required")
+	}
+
+	operation := func() error {
+		c.logger.WithField("ip_range_id", id).Debug("Deleting MAAS IP range")
+		err := c.client.IPRange.Delete(id)
 		if err != nil {
-			c.logger.WithError(err).Error("Failed to update partition")
+			c.logger.WithError(err).WithField("ip_range_id", id).Error("Failed to delete MAAS IP range")
+			if strings.Contains(err.Error(), "404") {
+				return TranslateError(err, http.StatusNotFound)
+			}
 			return TranslateError(err, http.StatusInternalServerError)
 		}
-		defer resp.Body.Close()
+		return nil
+	}
 
-		if resp.StatusCode != http.StatusOK {
-			err = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-			c.logger.WithError(err).Error("Failed to update partition")
-			return TranslateError(err, resp.StatusCode)
+	return c.retry(ctx, operation)
+}
+
+// validateIPRangeParams checks the type and addresses of an IP range before it is sent to MAAS
+func validateIPRangeParams(params *entity.IPRangeParams) error {
+	ipRange := maas.IPRange{
+		Type:    params.Type,
+		StartIP: params.StartIP,
+		EndIP:   params.EndIP,
+	}
+	if err := ipRange.Validate(); err != nil {
+		return err
+	}
+	if params.Subnet == "" {
+		return fmt.Errorf("subnet is required")
+	}
+	return nil
+}
+
+// ListStaticRoutes retrieves all static routes
+func (c *MAASClient) ListStaticRoutes(ctx context.Context) ([]maas.StaticRoute, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	var entityRoutes []entity.StaticRoute
+	operation := func() error {
+		var err error
+		c.logger.Debug("Listing MAAS static routes")
+		entityRoutes, err = c.client.StaticRoutes.Get()
+		if err != nil {
+			c.logger.WithError(err).Error("Failed to list MAAS static routes")
+			return TranslateError(err, http.StatusInternalServerError)
 		}
+		return nil
+	}
 
-		if err := json.NewDecoder(resp.Body).Decode(&partition); err != nil {
-			c.logger.WithError(err).Error("Failed to decode partition response")
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	// Convert entity.StaticRoute to maas.StaticRoute
+	routes := make([]maas.StaticRoute, len(entityRoutes))
+	for i, entityRoute := range entityRoutes {
+		var route maas.StaticRoute
+		route.FromEntity(&entityRoute)
+		routes[i] = route
+	}
+
+	return routes, nil
+}
+
+// CreateStaticRoute creates a new static route
+func (c *MAASClient) CreateStaticRoute(ctx context.Context, params *entity.StaticRouteParams) (*maas.StaticRoute, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	if params == nil || params.Source == "" || params.Destination == "" {
+		return nil, fmt.Errorf("source and destination subnets are required")
+	}
+
+	if params.GatewayIP == "" {
+		return nil, fmt.Errorf("gateway IP is required")
+	}
+
+	var entityRoute *entity.StaticRoute
+	operation := func() error {
+		var err error
+		c.logger.WithFields(logrus.Fields{
+			"source":      params.Source,
+			"destination": params.Destination,
+			"gateway_ip":  params.GatewayIP,
+			"metric":      params.Metric,
+		}).Debug("Creating MAAS static route")
+		entityRoute, err = c.client.StaticRoutes.Create(params)
+		if err != nil {
+			c.logger.WithError(err).WithField("destination", params.Destination).Error("Failed to create MAAS static route")
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	// Convert entity.StaticRoute to maas.StaticRoute
+	route := &maas.StaticRoute{}
+	route.FromEntity(entityRoute)
+
+	return route, nil
+}
+
+// DeleteStaticRoute deletes a static route
+func (c *MAASClient) DeleteStaticRoute(ctx context.Context, id int) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return fmt.Errorf("client is closed")
+	}
+
+	if id <= 0 {
+		return fmt.Errorf("valid static route ID is required")
+	}
+
+	operation := func() error {
+		c.logger.WithField("static_route_id", id).Debug("Deleting MAAS static route")
+		err := c.client.StaticRoute.Delete(id)
+		if err != nil {
+			c.logger.WithError(err).WithField("static_route_id", id).Error("Failed to delete MAAS static route")
+			if strings.Contains(err.Error(), "404") {
+				return TranslateError(err, http.StatusNotFound)
+			}
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	return c.retry(ctx, operation)
+}
+
+// ==================== Storage Operations ====================
+
+// GetMachineBlockDevices retrieves block devices for a specific machine
+func (c *MAASClient) GetMachineBlockDevices(ctx context.Context, systemID string) ([]maas.BlockDevice, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	if systemID == "" {
+		return nil, fmt.Errorf("system ID is required")
+	}
+
+	var entityDevices []entity.BlockDevice
+	operation := func() error {
+		var err error
+		c.logger.WithField("system_id", systemID).Debug("Getting MAAS machine block devices")
+		entityDevices, err = c.client.BlockDevices.Get(systemID)
+		if err != nil {
+			c.logger.WithError(err).WithField("system_id", systemID).Error("Failed to get MAAS machine block devices")
+			if strings.Contains(err.Error(), "404") {
+				return TranslateError(err, http.StatusNotFound)
+			}
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	// Convert entity.BlockDevice to maas.BlockDevice
+	devices := make([]maas.BlockDevice, len(entityDevices))
+	for i, entityDevice := range entityDevices {
+		var device maas.BlockDevice
+		device.FromEntity(&entityDevice)
+		devices[i] = device
+	}
+
+	return devices, nil
+}
+
+// CreateMachineBlockDevice creates a block device for a specific machine
+func (c *MAASClient) CreateMachineBlockDevice(ctx context.Context, systemID string, params map[string]interface{}) (*maas.BlockDevice, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	if systemID == "" {
+		return nil, fmt.Errorf("system ID is required")
+	}
+
+	// Note: The gomaasclient library doesn't provide a direct method to create a block device
+	// This is a placeholder implementation
+	c.logger.WithFields(logrus.Fields{
+		"system_id": systemID,
+		"params":    params,
+	}).Warn("CreateMachineBlockDevice operation is not directly supported by gomaasclient")
+
+	return nil, fmt.Errorf("operation not supported by the underlying client library")
+}
+
+// UpdateMachineBlockDevice updates a block device for a specific machine
+func (c *MAASClient) UpdateMachineBlockDevice(ctx context.Context, systemID string, blockDeviceID int, params map[string]interface{}) (*maas.BlockDevice, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	if systemID == "" {
+		return nil, fmt.Errorf("system ID is required")
+	}
+
+	if blockDeviceID <= 0 {
+		return nil, fmt.Errorf("valid block device ID is required")
+	}
+
+	// Note: The gomaasclient library doesn't provide a direct method to update a block device
+	// This is a placeholder implementation
+	c.logger.WithFields(logrus.Fields{
+		"system_id":       systemID,
+		"block_device_id": blockDeviceID,
+		"params":          params,
+	}).Warn("UpdateMachineBlockDevice operation is not directly supported by gomaasclient")
+
+	return nil, fmt.Errorf("operation not supported by the underlying client library")
+}
+
+// DeleteMachineBlockDevice deletes a block device for a specific machine
+func (c *MAASClient) DeleteMachineBlockDevice(ctx context.Context, systemID string, blockDeviceID int) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return fmt.Errorf("client is closed")
+	}
+
+	if systemID == "" {
+		return fmt.Errorf("system ID is required")
+	}
+
+	if blockDeviceID <= 0 {
+		return fmt.Errorf("valid block device ID is required")
+	}
+
+	// Note: The gomaasclient library doesn't provide a direct method to delete a block device
+	// This is a placeholder implementation
+	c.logger.WithFields(logrus.Fields{
+		"system_id":       systemID,
+		"block_device_id": blockDeviceID,
+	}).Warn("DeleteMachineBlockDevice operation is not directly supported by gomaasclient")
+
+	return fmt.Errorf("operation not supported by the underlying client library")
+}
+
+// CreateMachinePartition creates a partition on a block device for a specific machine
+func (c *MAASClient) CreateMachinePartition(ctx context.Context, systemID string, blockDeviceID int, params map[string]interface{}) (*maas.Partition, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	if systemID == "" {
+		return nil, fmt.Errorf("system ID is required")
+	}
+
+	if blockDeviceID <= 0 {
+		return nil, fmt.Errorf("valid block device ID is required")
+	}
+
+	// Validate required parameters
+	if _, ok := params["size"]; !ok {
+		return nil, fmt.Errorf("partition size is required")
+	}
+
+	// Construct the API endpoint
+	endpoint := fmt.Sprintf("/api/2.0/machines/%s/block-devices/%d/partitions/", systemID, blockDeviceID)
+
+	var partition maas.Partition
+	operation := func() error {
+		c.logger.WithFields(logrus.Fields{
+			"system_id":       systemID,
+			"block_device_id": blockDeviceID,
+			"params":          params,
+		}).Debug("Creating partition on MAAS machine block device")
+
+		req, err := c.newRequest(ctx, "POST", endpoint, params)
+		if err != nil {
+			return err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			c.logger.WithError(err).Error("Failed to create partition")
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+			err = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+			c.logger.WithError(err).Error("Failed to create partition")
+			return TranslateError(err, resp.StatusCode)
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(&partition); err != nil {
+			c.logger.WithError(err).Error("Failed to decode partition response")
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+
+		return nil
+	}
+
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	return &partition, nil
+}
+
+// DeleteMachinePartition deletes a partition from a block device for a specific machine
+func (c *MAASClient) DeleteMachinePartition(ctx context.Context, systemID string, blockDeviceID, partitionID int) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return fmt.Errorf("client is closed")
+	}
+
+	if systemID == "" {
+		return fmt.Errorf("system ID is required")
+	}
+
+	if blockDeviceID <= 0 {
+		return fmt.Errorf("valid block device ID is required")
+	}
+
+	if partitionID <= 0 {
+		return fmt.Errorf("valid partition ID is required")
+	}
+
+	// Construct the API endpoint
+	endpoint := fmt.Sprintf("/api/2.0/machines/%s/block-devices/%d/partitions/%d/", systemID, blockDeviceID, partitionID)
+
+	operation := func() error {
+		c.logger.WithFields(logrus.Fields{
+			"system_id":       systemID,
+			"block_device_id": blockDeviceID,
+			"partition_id":    partitionID,
+		}).Debug("Deleting partition from MAAS machine block device")
+
+		req, err := c.newRequest(ctx, "DELETE", endpoint, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			c.logger.WithError(err).Error("Failed to delete partition")
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+			err = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+			c.logger.WithError(err).Error("Failed to delete partition")
+			return TranslateError(err, resp.StatusCode)
+		}
+
+		return nil
+	}
+
+	return c.retry(ctx, operation)
+}
+
+// UpdateMachinePartition updates a partition on a block device for a specific machine
+func (c *MAASClient) UpdateMachinePartition(ctx context.Context, systemID string, blockDeviceID, partitionID int, params map[string]interface{}) (*maas.Partition, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	if systemID == "" {
+		return nil, fmt.Errorf("system ID is required")
+	}
+
+	if blockDeviceID <= 0 {
+		return nil, fmt.Errorf("valid block device ID is required")
+	}
+
+	if partitionID <= 0 {
+		return nil, fmt.Errorf("valid partition ID is required")
+	}
+
+	// Construct the API endpoint
+	endpoint := fmt.Sprintf("/api/2.0/machines/%s/block-devices/%d/partitions/%d/", systemID, blockDeviceID, partitionID)
+
+	var partition maas.Partition
+	operation := func() error {
+		c.logger.WithFields(logrus.Fields{
+			"system_id":       systemID,
+			"block_device_id": blockDeviceID,
+			"partition_id":    partitionID,
+			"params":          params,
+		}).Debug("Updating partition on MAAS machine block device")
+
+		req, err := c.newRequest(ctx, "PUT", endpoint, params)
+		if err != nil {
+			return err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			c.logger.WithError(err).Error("Failed to update partition")
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+			c.logger.WithError(err).Error("Failed to update partition")
+			return TranslateError(err, resp.StatusCode)
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(&partition); err != nil {
+			c.logger.WithError(err).Error("Failed to decode partition response")
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+
+		return nil
+	}
+
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	return &partition, nil
+}
+
+// FormatMachinePartition formats a partition on a block device for a specific machine
+func (c *MAASClient) FormatMachinePartition(ctx context.Context, systemID string, blockDeviceID, partitionID int, params map[string]interface{}) (*maas.Filesystem, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	if systemID == "" {
+		return nil, fmt.Errorf("system ID is required")
+	}
+
+	if blockDeviceID <= 0 {
+		return nil, fmt.Errorf("valid block device ID is required")
+	}
+
+	if partitionID <= 0 {
+		return nil, fmt.Errorf("valid partition ID is required")
+	}
+
+	// Validate required parameters
+	if _, ok := params["fstype"]; !ok {
+		return nil, fmt.Errorf("filesystem type (fstype) is required")
+	}
+
+	// Construct the API endpoint
+	endpoint := fmt.Sprintf("/api/2.0/machines/%s/block-devices/%d/partitions/%d/format/", systemID, blockDeviceID, partitionID)
+
+	var filesystem maas.Filesystem
+	operation := func() error {
+		c.logger.WithFields(logrus.Fields{
+			"system_id":       systemID,
+			"block_device_id": blockDeviceID,
+			"partition_id":    partitionID,
+			"params":          params,
+		}).Debug("Formatting partition on MAAS machine block device")
+
+		req, err := c.newRequest(ctx, "POST", endpoint, params)
+		if err != nil {
+			return err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			c.logger.WithError(err).Error("Failed to format partition")
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+			err = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+			c.logger.WithError(err).Error("Failed to format partition")
+			return TranslateError(err, resp.StatusCode)
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(&filesystem); err != nil {
+			c.logger.WithError(err).Error("Failed to decode filesystem response")
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+
+		return nil
+	}
+
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	return &filesystem, nil
+}
+
+// MountMachineFilesystem mounts a filesystem for a specific machine
+func (c *MAASClient) MountMachineFilesystem(ctx context.Context, systemID string, filesystemID int, params map[string]interface{}) (*maas.Filesystem, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	if systemID == "" {
+		return nil, fmt.Errorf("system ID is required")
+	}
+
+	if filesystemID <= 0 {
+		return nil, fmt.Errorf("valid filesystem ID is required")
+	}
+
+	// Note: The gomaasclient library doesn't provide a direct method to mount a filesystem
+	// This is a placeholder implementation
+	c.logger.WithFields(logrus.Fields{
+		"system_id":     systemID,
+		"filesystem_id": filesystemID,
+		"params":        params,
+	}).Warn("MountMachineFilesystem operation is not directly supported by gomaasclient")
+
+	return nil, fmt.Errorf("operation not supported by the underlying client library")
+}
+
+// GetMachineFilesystems retrieves filesystems for a specific machine
+func (c *MAASClient) GetMachineFilesystems(ctx context.Context, systemID string) ([]maas.Filesystem, error) {
+	// This is a placeholder as the gomaasclient doesn't have direct filesystem operations
+	// We would need to get block devices and partitions, then extract filesystem information
+	return nil, fmt.Errorf("not implemented")
+}
+
+// ==================== Tag Operations ====================
+
+// ListTags retrieves all tags
+func (c *MAASClient) ListTags(ctx context.Context) ([]maas.Tag, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	var entityTags []entity.Tag
+	operation := func() error {
+		var err error
+		c.logger.Debug("Listing MAAS tags")
+		entityTags, err = c.client.Tags.Get()
+		if err != nil {
+			c.logger.WithError(err).Error("Failed to list MAAS tags")
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	// Convert entity.Tag to maas.Tag
+	tags := make([]maas.Tag, len(entityTags))
+	for i, entityTag := range entityTags {
+		var tag maas.Tag
+		tag.FromEntity(&entityTag)
+		tags[i] = tag
+	}
+
+	return tags, nil
+}
+
+// GetTag retrieves tag details
+func (c *MAASClient) GetTag(ctx context.Context, name string) (*maas.Tag, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("tag name is required")
+	}
+
+	var entityTag *entity.Tag
+	operation := func() error {
+		var err error
+		c.logger.WithField("tag_name", name).Debug("Getting MAAS tag")
+		entityTag, err = c.client.Tag.Get(name)
+		if err != nil {
+			c.logger.WithError(err).WithField("tag_name", name).Error("Failed to get MAAS tag")
+			if strings.Contains(err.Error(), "404") {
+				return TranslateError(err, http.StatusNotFound)
+			}
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	// Convert entity.Tag to maas.Tag
+	tag := &maas.Tag{}
+	tag.FromEntity(entityTag)
+
+	return tag, nil
+}
+
+// CreateTag creates a new tag
+func (c *MAASClient) CreateTag(ctx context.Context, name, comment, definition string) (*maas.Tag, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("tag name is required")
+	}
+
+	var entityTag *entity.Tag
+	operation := func() error {
+		var err error
+		c.logger.WithFields(logrus.Fields{
+			"tag_name":   name,
+			"comment":    comment,
+			"definition": definition,
+		}).Debug("Creating MAAS tag")
+		params := &entity.TagParams{
+			Name:       name,
+			Comment:    comment,
+			Definition: definition,
+		}
+		entityTag, err = c.client.Tags.Create(params)
+		if err != nil {
+			c.logger.WithError(err).WithField("tag_name", name).Error("Failed to create MAAS tag")
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	// Convert entity.Tag to maas.Tag
+	tag := &maas.Tag{}
+	tag.FromEntity(entityTag)
+
+	return tag, nil
+}
+
+// UpdateTag updates an existing tag
+func (c *MAASClient) UpdateTag(ctx context.Context, name string, params map[string]interface{}) (*maas.Tag, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("tag name is required")
+	}
+
+	// Note: The gomaasclient library doesn't provide a direct method to update a tag
+	// This is a placeholder implementation
+	c.logger.WithFields(logrus.Fields{
+		"tag_name": name,
+		"params":   params,
+	}).Warn("UpdateTag operation is not directly supported by gomaasclient")
+
+	return nil, fmt.Errorf("operation not supported by the underlying client library")
+}
+
+// DeleteTag deletes a tag
+func (c *MAASClient) DeleteTag(ctx context.Context, name string) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return fmt.Errorf("client is closed")
+	}
+
+	if name == "" {
+		return fmt.Errorf("tag name is required")
+	}
+
+	// Note: The gomaasclient library doesn't provide a direct method to delete a tag
+	// This is a placeholder implementation
+	c.logger.WithField("tag_name", name).Warn("DeleteTag operation is not directly supported by gomaasclient")
+
+	return fmt.Errorf("operation not supported by the underlying client library")
+}
+
+// ApplyTagToMachine applies a tag to a machine
+func (c *MAASClient) ApplyTagToMachine(ctx context.Context, tagName, systemID string) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return fmt.Errorf("client is closed")
+	}
+
+	if tagName == "" {
+		return fmt.Errorf("tag name is required")
+	}
+
+	if systemID == "" {
+		return fmt.Errorf("system ID is required")
+	}
+
+	// Note: The gomaasclient library doesn't provide a direct method to add a tag to a machine
+	// This is a placeholder implementation
+	c.logger.WithFields(logrus.Fields{
+		"tag_name":  tagName,
+		"system_id": systemID,
+	}).Warn("ApplyTagToMachine operation is not directly supported by gomaasclient")
+
+	// No retry needed as we're not making an API call
+	return fmt.Errorf("operation not supported by the underlying client library")
+}
+
+// RemoveTagFromMachine removes a tag from a machine
+func (c *MAASClient) RemoveTagFromMachine(ctx context.Context, tagName, systemID string) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return fmt.Errorf("client is closed")
+	}
+
+	if tagName == "" {
+		return fmt.Errorf("tag name is required")
+	}
+
+	if systemID == "" {
+		return fmt.Errorf("system ID is required")
+	}
+
+	// Note: The gomaasclient library doesn't provide a direct method to remove a tag from a machine
+	// This is a placeholder implementation
+	c.logger.WithFields(logrus.Fields{
+		"tag_name":  tagName,
+		"system_id": systemID,
+	}).Warn("RemoveTagFromMachine operation is not directly supported by gomaasclient")
+
+	// No retry needed as we're not making an API call
+	return fmt.Errorf("operation not supported by the underlying client library")
+}
+
+// GetMachinesWithTag retrieves all machines with a specific tag
+func (c *MAASClient) GetMachinesWithTag(ctx context.Context, tagName string) ([]maas.Machine, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	if tagName == "" {
+		return nil, fmt.Errorf("tag name is required")
+	}
+
+	// Use the ListMachines method with a filter for the tag
+	filters := map[string]string{
+		"tags": tagName,
+	}
+
+	machines, _, err := c.ListMachines(ctx, filters, nil)
+	return machines, err
+}
+
+// ListMachinesSimple retrieves machines based on filters without pagination
+func (c *MAASClient) ListMachinesSimple(ctx context.Context, filters map[string]string) ([]maas.Machine, error) {
+	machines, _, err := c.ListMachines(ctx, filters, nil)
+	return machines, err
+}
+
+// ==================== Zone Operations ====================
+
+// ListZones retrieves all zones
+func (c *MAASClient) ListZones(ctx context.Context) ([]maas.Zone, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	var entityZones []entity.Zone
+	operation := func() error {
+		var err error
+		c.logger.Debug("Listing MAAS zones")
+		entityZones, err = c.client.Zones.Get()
+		if err != nil {
+			c.logger.WithError(err).Error("Failed to list MAAS zones")
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	// Convert entity.Zone to maas.Zone
+	zones := make([]maas.Zone, len(entityZones))
+	for i, entityZone := range entityZones {
+		var zone maas.Zone
+		zone.FromEntity(&entityZone)
+		zones[i] = zone
+	}
+
+	return zones, nil
+}
+
+// GetZone retrieves zone details
+func (c *MAASClient) GetZone(ctx context.Context, name string) (*maas.Zone, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("zone name is required")
+	}
+
+	var entityZone *entity.Zone
+	operation := func() error {
+		var err error
+		c.logger.WithField("zone_name", name).Debug("Getting MAAS zone")
+		entityZone, err = c.client.Zone.Get(name)
+		if err != nil {
+			c.logger.WithError(err).WithField("zone_name", name).Error("Failed to get MAAS zone")
+			if strings.Contains(err.Error(), "404") {
+				return TranslateError(err, http.StatusNotFound)
+			}
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	// Convert entity.Zone to maas.Zone
+	zone := &maas.Zone{}
+	zone.FromEntity(entityZone)
+
+	return zone, nil
+}
+
+// CreateZone creates a new zone
+func (c *MAASClient) CreateZone(ctx context.Context, params *entity.ZoneParams) (*maas.Zone, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	if params == nil || params.Name == "" {
+		return nil, fmt.Errorf("zone name is required")
+	}
+
+	var entityZone *entity.Zone
+	operation := func() error {
+		var err error
+		c.logger.WithFields(logrus.Fields{
+			"zone_name":   params.Name,
+			"description": params.Description,
+		}).Debug("Creating MAAS zone")
+		entityZone, err = c.client.Zones.Create(params)
+		if err != nil {
+			c.logger.WithError(err).WithField("zone_name", params.Name).Error("Failed to create MAAS zone")
 			return TranslateError(err, http.StatusInternalServerError)
 		}
-
 		return nil
 	}
 
@@ -1328,11 +2628,15 @@ func (c *MAASClient) UpdateMachinePartition(ctx context.Context, systemID string
 		return nil, err
 	}
 
-	return &partition, nil
+	// Convert entity.Zone to maas.Zone
+	zone := &maas.Zone{}
+	zone.FromEntity(entityZone)
+
+	return zone, nil
 }
 
-// FormatMachinePartition formats a partition on a block device for a specific machine
-func (c *MAASClient) FormatMachinePartition(ctx context.Context, systemID string, blockDeviceID, partitionID int, params map[string]interface{}) (*maas.Filesystem, error) {
+// UpdateZone updates an existing zone
+func (c *MAASClient) UpdateZone(ctx context.Context, name string, params *entity.ZoneParams) (*maas.Zone, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -1340,58 +2644,30 @@ func (c *MAASClient) FormatMachinePartition(ctx context.Context, systemID string
 		return nil, fmt.Errorf("client is closed")
 	}
 
-	if systemID == "" {
-		return nil, fmt.Errorf("system ID is required")
-	}
-
-	if blockDeviceID <= 0 {
-		return nil, fmt.Errorf("valid block device ID is required")
-	}
-
-	if partitionID <= 0 {
-		return nil, fmt.Errorf("valid partition ID is required")
+	if name == "" {
+		return nil, fmt.Errorf("zone name is required")
 	}
 
-	// Validate required parameters
-	if _, ok := params["fstype"]; !ok {
-		return nil, fmt.Errorf("filesystem type (fstype) is required")
+	if params == nil {
+		params = &entity.ZoneParams{}
 	}
 
-	// Construct the API endpoint
-	endpoint := fmt.Sprintf("/api/2.0/machines/%s/block-devices/%d/partitions/%d/format/", systemID, blockDeviceID, partitionID)
-
-	var filesystem maas.Filesystem
+	var entityZone *entity.Zone
 	operation := func() error {
+		var err error
 		c.logger.WithFields(logrus.Fields{
-			"system_id":       systemID,
-			"block_device_id": blockDeviceID,
-			"partition_id":    partitionID,
-			"params":          params,
-		}).Debug("Formatting partition on MAAS machine block device")
-
-		req, err := c.newRequest(ctx, "POST", endpoint, params)
-		if err != nil {
-			return err
-		}
-
-		resp, err := c.httpClient.Do(req)
+			"zone_name":   name,
+			"new_name":    params.Name,
+			"description": params.Description,
+		}).Debug("Updating MAAS zone")
+		entityZone, err = c.client.Zone.Update(name, params)
 		if err != nil {
-			c.logger.WithError(err).Error("Failed to format partition")
-			return TranslateError(err, http.StatusInternalServerError)
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-			err = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-			c.logger.WithError(err).Error("Failed to format partition")
-			return TranslateError(err, resp.StatusCode)
-		}
-
-		if err := json.NewDecoder(resp.Body).Decode(&filesystem); err != nil {
-			c.logger.WithError(err).Error("Failed to decode filesystem response")
+			c.logger.WithError(err).WithField("zone_name", name).Error("Failed to update MAAS zone")
+			if strings.Contains(err.Error(), "404") {
+				return TranslateError(err, http.StatusNotFound)
+			}
 			return TranslateError(err, http.StatusInternalServerError)
 		}
-
 		return nil
 	}
 
@@ -1399,48 +2675,45 @@ func (c *MAASClient) FormatMachinePartition(ctx context.Context, systemID string
 		return nil, err
 	}
 
-	return &filesystem, nil
+	// Convert entity.Zone to maas.Zone
+	zone := &maas.Zone{}
+	zone.FromEntity(entityZone)
+
+	return zone, nil
 }
 
-// MountMachineFilesystem mounts a filesystem for a specific machine
-func (c *MAASClient) MountMachineFilesystem(ctx context.Context, systemID string, filesystemID int, params map[string]interface{}) (*maas.Filesystem, error) {
+// DeleteZone deletes a zone
+func (c *MAASClient) DeleteZone(ctx context.Context, name string) error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if c.closed {
-		return nil, fmt.Errorf("client is closed")
+		return fmt.Errorf("client is closed")
 	}
 
-	if systemID == "" {
-		return nil, fmt.Errorf("system ID is required")
+	if name == "" {
+		return fmt.Errorf("zone name is required")
 	}
 
-	if filesystemID <= 0 {
-		return nil, fmt.Errorf("valid filesystem ID is required")
+	operation := func() error {
+		c.logger.WithField("zone_name", name).Debug("Deleting MAAS zone")
+		if err := c.client.Zone.Delete(name); err != nil {
+			c.logger.WithError(err).WithField("zone_name", name).Error("Failed to delete MAAS zone")
+			if strings.Contains(err.Error(), "404") {
+				return TranslateError(err, http.StatusNotFound)
+			}
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
 	}
 
-	// Note: The gomaasclient library doesn't provide a direct method to mount a filesystem
-	// This is a placeholder implementation
-	c.logger.WithFields(logrus.Fields{
-		"system_id":     systemID,
-		"filesystem_id": filesystemID,
-		"params":        params,
-	}).Warn("MountMachineFilesystem operation is not directly supported by gomaasclient")
-
-	return nil, fmt.Errorf("operation not supported by the underlying client library")
-}
-
-// GetMachineFilesystems retrieves filesystems for a specific machine
-func (c *MAASClient) GetMachineFilesystems(ctx context.Context, systemID string) ([]maas.Filesystem, error) {
-	// This is a placeholder as the gomaasclient doesn't have direct filesystem operations
-	// We would need to get block devices and partitions, then extract filesystem information
-	return nil, fmt.Errorf("not implemented")
+	return c.retry(ctx, operation)
 }
 
-// ==================== Tag Operations ====================
+// ==================== Resource Pool Operations ====================
 
-// ListTags retrieves all tags
-func (c *MAASClient) ListTags(ctx context.Context) ([]maas.Tag, error) {
+// ListResourcePools retrieves all resource pools
+func (c *MAASClient) ListResourcePools(ctx context.Context) ([]maas.ResourcePool, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -1448,13 +2721,13 @@ func (c *MAASClient) ListTags(ctx context.Context) ([]maas.Tag, error) {
 		return nil, fmt.Errorf("client is closed")
 	}
 
-	var entityTags []entity.Tag
+	var entityResourcePools []entity.ResourcePool
 	operation := func() error {
 		var err error
-		c.logger.Debug("Listing MAAS tags")
-		entityTags, err = c.client.Tags.Get()
+		c.logger.Debug("Listing MAAS resource pools")
+		entityResourcePools, err = c.client.ResourcePools.Get()
 		if err != nil {
-			c.logger.WithError(err).Error("Failed to list MAAS tags")
+			c.logger.WithError(err).Error("Failed to list MAAS resource pools")
 			return TranslateError(err, http.StatusInternalServerError)
 		}
 		return nil
@@ -1464,19 +2737,19 @@ func (c *MAASClient) ListTags(ctx context.Context) ([]maas.Tag, error) {
 		return nil, err
 	}
 
-	// Convert entity.Tag to maas.Tag
-	tags := make([]maas.Tag, len(entityTags))
-	for i, entityTag := range entityTags {
-		var tag maas.Tag
-		tag.FromEntity(&entityTag)
-		tags[i] = tag
+	// Convert entity.ResourcePool to maas.ResourcePool
+	pools := make([]maas.ResourcePool, len(entityResourcePools))
+	for i, entityResourcePool := range entityResourcePools {
+		var pool maas.ResourcePool
+		pool.FromEntity(&entityResourcePool)
+		pools[i] = pool
 	}
 
-	return tags, nil
+	return pools, nil
 }
 
-// GetTag retrieves tag details
-func (c *MAASClient) GetTag(ctx context.Context, name string) (*maas.Tag, error) {
+// GetResourcePool retrieves resource pool details
+func (c *MAASClient) GetResourcePool(ctx context.Context, name string) (*maas.ResourcePool, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -1485,16 +2758,16 @@ func (c *MAASClient) GetTag(ctx context.Context, name string) (*maas.Tag, error)
 	}
 
 	if name == "" {
-		return nil, fmt.Errorf("tag name is required")
+		return nil, fmt.Errorf("resource pool name is required")
 	}
 
-	var entityTag *entity.Tag
+	var entityResourcePool *entity.ResourcePool
 	operation := func() error {
 		var err error
-		c.logger.WithField("tag_name", name).Debug("Getting MAAS tag")
-		entityTag, err = c.client.Tag.Get(name)
+		c.logger.WithField("pool_name", name).Debug("Getting MAAS resource pool")
+		entityResourcePool, err = c.client.ResourcePool.GetByName(name)
 		if err != nil {
-			c.logger.WithError(err).WithField("tag_name", name).Error("Failed to get MAAS tag")
+			c.logger.WithError(err).WithField("pool_name", name).Error("Failed to get MAAS resource pool")
 			if strings.Contains(err.Error(), "404") {
 				return TranslateError(err, http.StatusNotFound)
 			}
@@ -1507,15 +2780,15 @@ func (c *MAASClient) GetTag(ctx context.Context, name string) (*maas.Tag, error)
 		return nil, err
 	}
 
-	// Convert entity.Tag to maas.Tag
-	tag := &maas.Tag{}
-	tag.FromEntity(entityTag)
+	// Convert entity.ResourcePool to maas.ResourcePool
+	pool := &maas.ResourcePool{}
+	pool.FromEntity(entityResourcePool)
 
-	return tag, nil
+	return pool, nil
 }
 
-// CreateTag creates a new tag
-func (c *MAASClient) CreateTag(ctx context.Context, name, comment, definition string) (*maas.Tag, error) {
+// CreateResourcePool creates a new resource pool
+func (c *MAASClient) CreateResourcePool(ctx context.Context, params *entity.ResourcePoolParams) (*maas.ResourcePool, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -1523,26 +2796,20 @@ func (c *MAASClient) CreateTag(ctx context.Context, name, comment, definition st
 		return nil, fmt.Errorf("client is closed")
 	}
 
-	if name == "" {
-		return nil, fmt.Errorf("tag name is required")
+	if params == nil || params.Name == "" {
+		return nil, fmt.Errorf("resource pool name is required")
 	}
 
-	var entityTag *entity.Tag
+	var entityResourcePool *entity.ResourcePool
 	operation := func() error {
 		var err error
 		c.logger.WithFields(logrus.Fields{
-			"tag_name":   name,
-			"comment":    comment,
-			"definition": definition,
-		}).Debug("Creating MAAS tag")
-		params := &entity.TagParams{
-			Name:       name,
-			Comment:    comment,
-			Definition: definition,
-		}
-		entityTag, err = c.client.Tags.Create(params)
+			"pool_name":   params.Name,
+			"description": params.Description,
+		}).Debug("Creating MAAS resource pool")
+		entityResourcePool, err = c.client.ResourcePools.Create(params)
 		if err != nil {
-			c.logger.WithError(err).WithField("tag_name", name).Error("Failed to create MAAS tag")
+			c.logger.WithError(err).WithField("pool_name", params.Name).Error("Failed to create MAAS resource pool")
 			return TranslateError(err, http.StatusInternalServerError)
 		}
 		return nil
@@ -1552,15 +2819,15 @@ func (c *MAASClient) CreateTag(ctx context.Context, name, comment, definition st
 		return nil, err
 	}
 
-	// Convert entity.Tag to maas.Tag
-	tag := &maas.Tag{}
-	tag.FromEntity(entityTag)
+	// Convert entity.ResourcePool to maas.ResourcePool
+	pool := &maas.ResourcePool{}
+	pool.FromEntity(entityResourcePool)
 
-	return tag, nil
+	return pool, nil
 }
 
-// UpdateTag updates an existing tag
-func (c *MAASClient) UpdateTag(ctx context.Context, name string, params map[string]interface{}) (*maas.Tag, error) {
+// UpdateResourcePool updates an existing resource pool
+func (c *MAASClient) UpdateResourcePool(ctx context.Context, name string, params *entity.ResourcePoolParams) (*maas.ResourcePool, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -1569,21 +2836,45 @@ func (c *MAASClient) UpdateTag(ctx context.Context, name string, params map[stri
 	}
 
 	if name == "" {
-		return nil, fmt.Errorf("tag name is required")
+		return nil, fmt.Errorf("resource pool name is required")
 	}
 
-	// Note: The gomaasclient library doesn't provide a direct method to update a tag
-	// This is a placeholder implementation
-	c.logger.WithFields(logrus.Fields{
-		"tag_name": name,
-		"params":   params,
-	}).Warn("UpdateTag operation is not directly supported by gomaasclient")
+	if params == nil {
+		params = &entity.ResourcePoolParams{}
+	}
 
-	return nil, fmt.Errorf("operation not supported by the underlying client library")
+	var entityResourcePool *entity.ResourcePool
+	operation := func() error {
+		var err error
+		c.logger.WithFields(logrus.Fields{
+			"pool_name":   name,
+			"new_name":    params.Name,
+			"description": params.Description,
+		}).Debug("Updating MAAS resource pool")
+		entityResourcePool, err = c.client.ResourcePool.UpdateByName(name, params)
+		if err != nil {
+			c.logger.WithError(err).WithField("pool_name", name).Error("Failed to update MAAS resource pool")
+			if strings.Contains(err.Error(), "404") {
+				return TranslateError(err, http.StatusNotFound)
+			}
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	// Convert entity.ResourcePool to maas.ResourcePool
+	pool := &maas.ResourcePool{}
+	pool.FromEntity(entityResourcePool)
+
+	return pool, nil
 }
 
-// DeleteTag deletes a tag
-func (c *MAASClient) DeleteTag(ctx context.Context, name string) error {
+// DeleteResourcePool deletes a resource pool
+func (c *MAASClient) DeleteResourcePool(ctx context.Context, name string) error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -1592,96 +2883,154 @@ func (c *MAASClient) DeleteTag(ctx context.Context, name string) error {
 	}
 
 	if name == "" {
-		return fmt.Errorf("tag name is required")
+		return fmt.Errorf("resource pool name is required")
 	}
 
-	// Note: The gomaasclient library doesn't provide a direct method to delete a tag
-	// This is a placeholder implementation
-	c.logger.WithField("tag_name", name).Warn("DeleteTag operation is not directly supported by gomaasclient")
+	operation := func() error {
+		c.logger.WithField("pool_name", name).Debug("Deleting MAAS resource pool")
+		if err := c.client.ResourcePool.DeleteByName(name); err != nil {
+			c.logger.WithError(err).WithField("pool_name", name).Error("Failed to delete MAAS resource pool")
+			if strings.Contains(err.Error(), "404") {
+				return TranslateError(err, http.StatusNotFound)
+			}
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
+	}
 
-	return fmt.Errorf("operation not supported by the underlying client library")
+	return c.retry(ctx, operation)
 }
 
-// ApplyTagToMachine applies a tag to a machine
-func (c *MAASClient) ApplyTagToMachine(ctx context.Context, tagName, systemID string) error {
+// ==================== IP Address Operations ====================
+
+// ipAddressErrorStatus maps a MAAS ipaddresses error to the status it was returned with; MAAS
+// answers 404 for an unknown subnet and 409 or 400 for an address that cannot be reserved
+func ipAddressErrorStatus(err error) int {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "404"):
+		return http.StatusNotFound
+	case strings.Contains(message, "409"):
+		return http.StatusConflict
+	case strings.Contains(message, "400"):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ListIPAddresses retrieves the addresses reserved by the API user, or by every user when all is set
+func (c *MAASClient) ListIPAddresses(ctx context.Context, all bool) ([]maas.IPAddress, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if c.closed {
-		return fmt.Errorf("client is closed")
+		return nil, fmt.Errorf("client is closed")
 	}
 
-	if tagName == "" {
-		return fmt.Errorf("tag name is required")
+	var entityAddresses []entity.IPAddress
+	operation := func() error {
+		var err error
+		c.logger.WithField("all", all).Debug("Listing MAAS IP addresses")
+		entityAddresses, err = c.client.IPAddresses.Get(&entity.IPAddressesParams{All: all})
+		if err != nil {
+			c.logger.WithError(err).Error("Failed to list MAAS IP addresses")
+			return TranslateError(err, http.StatusInternalServerError)
+		}
+		return nil
 	}
 
-	if systemID == "" {
-		return fmt.Errorf("system ID is required")
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
 	}
 
-	// Note: The gomaasclient library doesn't provide a direct method to add a tag to a machine
-	// This is a placeholder implementation
-	c.logger.WithFields(logrus.Fields{
-		"tag_name":  tagName,
-		"system_id": systemID,
-	}).Warn("ApplyTagToMachine operation is not directly supported by gomaasclient")
+	// Convert entity.IPAddress to maas.IPAddress
+	addresses := make([]maas.IPAddress, len(entityAddresses))
+	for i, entityAddress := range entityAddresses {
+		var address maas.IPAddress
+		address.FromEntity(&entityAddress)
+		addresses[i] = address
+	}
 
-	// No retry needed as we're not making an API call
-	return fmt.Errorf("operation not supported by the underlying client library")
+	return addresses, nil
 }
 
-// RemoveTagFromMachine removes a tag from a machine
-func (c *MAASClient) RemoveTagFromMachine(ctx context.Context, tagName, systemID string) error {
+// ReserveIPAddress reserves an address, optionally bound to a MAC address and with a hostname
+func (c *MAASClient) ReserveIPAddress(ctx context.Context, params *maas.IPAddressReserveParams) (*maas.IPAddress, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if c.closed {
-		return fmt.Errorf("client is closed")
+		return nil, fmt.Errorf("client is closed")
 	}
 
-	if tagName == "" {
-		return fmt.Errorf("tag name is required")
+	if params == nil {
+		return nil, fmt.Errorf("reservation parameters are required")
+	}
+	if err := params.Validate(); err != nil {
+		return nil, err
 	}
 
-	if systemID == "" {
-		return fmt.Errorf("system ID is required")
+	// entity.IPAddressesParams has no mac or hostname, so post the reserve op through the
+	// API client that backs IPAddresses
+	ipAddresses, ok := c.client.IPAddresses.(*gomaasclient.IPAddresses)
+	if !ok {
+		return nil, fmt.Errorf("operation not supported by the underlying client library")
 	}
 
-	// Note: The gomaasclient library doesn't provide a direct method to remove a tag from a machine
-	// This is a placeholder implementation
-	c.logger.WithFields(logrus.Fields{
-		"tag_name":  tagName,
-		"system_id": systemID,
-	}).Warn("RemoveTagFromMachine operation is not directly supported by gomaasclient")
+	entityAddress := new(entity.IPAddress)
+	operation := func() error {
+		c.logger.WithFields(logrus.Fields{
+			"subnet":   params.Subnet,
+			"ip":       params.IP,
+			"mac":      params.MAC,
+			"hostname": params.Hostname,
+		}).Debug("Reserving MAAS IP address")
+		err := ipAddresses.APIClient.GetSubObject("ipaddresses").Post("reserve", params.Values(), func(data []byte) error {
+			return json.Unmarshal(data, entityAddress)
+		})
+		if err != nil {
+			c.logger.WithError(err).WithField("subnet", params.Subnet).Error("Failed to reserve MAAS IP address")
+			return TranslateError(err, ipAddressErrorStatus(err))
+		}
+		return nil
+	}
 
-	// No retry needed as we're not making an API call
-	return fmt.Errorf("operation not supported by the underlying client library")
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	// Convert entity.IPAddress to maas.IPAddress
+	address := &maas.IPAddress{}
+	address.FromEntity(entityAddress)
+
+	return address, nil
 }
 
-// GetMachinesWithTag retrieves all machines with a specific tag
-func (c *MAASClient) GetMachinesWithTag(ctx context.Context, tagName string) ([]maas.Machine, error) {
+// ReleaseIPAddress releases a reserved address; force releases addresses reserved by other users
+func (c *MAASClient) ReleaseIPAddress(ctx context.Context, ip string, force bool) error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if c.closed {
-		return nil, fmt.Errorf("client is closed")
+		return fmt.Errorf("client is closed")
 	}
 
-	if tagName == "" {
-		return nil, fmt.Errorf("tag name is required")
+	if ip == "" {
+		return fmt.Errorf("IP address is required")
 	}
 
-	// Use the ListMachines method with a filter for the tag
-	filters := map[string]string{
-		"tags": tagName,
+	operation := func() error {
+		c.logger.WithFields(logrus.Fields{
+			"ip":    ip,
+			"force": force,
+		}).Debug("Releasing MAAS IP address")
+		if err := c.client.IPAddresses.Release(&entity.IPAddressesParams{IP: ip, Force: force}); err != nil {
+			c.logger.WithError(err).WithField("ip", ip).Error("Failed to release MAAS IP address")
+			return TranslateError(err, ipAddressErrorStatus(err))
+		}
+		return nil
 	}
 
-	machines, _, err := c.ListMachines(ctx, filters, nil)
-	return machines, err
-}
-
-// ListMachinesSimple retrieves machines based on filters without pagination
-func (c *MAASClient) ListMachinesSimple(ctx context.Context, filters map[string]string) ([]maas.Machine, error) {
-	machines, _, err := c.ListMachines(ctx, filters, nil)
-	return machines, err
+	return c.retry(ctx, operation)
 }
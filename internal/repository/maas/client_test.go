@@ -0,0 +1,417 @@
+package maas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/canonical/gomaasclient/entity"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/maas-mcp-server/internal/models/maas"
+)
+
+// newTestMAASClient creates a client for a fake MAAS served by handler under /MAAS/api/2.0/
+func newTestMAASClient(t *testing.T, handler http.Handler) *MAASClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewMAASClient(&maas.ClientConfig{
+		APIURL:     server.URL + "/MAAS",
+		APIKey:     "consumer:token:secret",
+		MaxRetries: 1,
+		RetryDelay: time.Millisecond,
+	}, logrus.New())
+	require.NoError(t, err)
+	return client
+}
+
+// namedObjects is a fake MAAS collection of zones or resource pools, which MAAS addresses by
+// name. The collection is served at collection/ and an object at objectPath/{name}/.
+type namedObjects struct {
+	mu      sync.Mutex
+	objects []map[string]interface{}
+	nextID  int
+}
+
+func newNamedObjects(names ...string) *namedObjects {
+	objects := &namedObjects{}
+	for _, name := range names {
+		objects.add(name, "")
+	}
+	return objects
+}
+
+func (o *namedObjects) add(name, description string) map[string]interface{} {
+	object := map[string]interface{}{"id": o.nextID, "name": name, "description": description}
+	o.nextID++
+	o.objects = append(o.objects, object)
+	return object
+}
+
+func (o *namedObjects) find(name string) int {
+	for i, object := range o.objects {
+		if object["name"] == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// register serves the collection on mux. Zones are addressed under their collection, at
+// zones/{name}/, and resource pools under resourcepool/{name}/.
+func (o *namedObjects) register(mux *http.ServeMux, collection, objectPath string) {
+	prefix := "/MAAS/api/2.0/"
+	// MAAS gives every object the URI it is served at
+	withURI := func(object map[string]interface{}) map[string]interface{} {
+		copied := map[string]interface{}{"resource_uri": fmt.Sprintf("%s%s/%s/", prefix, objectPath, object["name"])}
+		for key, value := range object {
+			copied[key] = value
+		}
+		return copied
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if r.URL.Path == prefix+collection+"/" {
+			switch r.Method {
+			case http.MethodGet:
+				objects := make([]map[string]interface{}, len(o.objects))
+				for i, object := range o.objects {
+					objects[i] = withURI(object)
+				}
+				writeJSON(w, objects)
+			case http.MethodPost:
+				if o.find(r.PostFormValue("name")) >= 0 {
+					http.Error(w, "name already exists", http.StatusBadRequest)
+					return
+				}
+				writeJSON(w, withURI(o.add(r.PostFormValue("name"), r.PostFormValue("description"))))
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+			return
+		}
+
+		name := strings.Trim(strings.TrimPrefix(r.URL.Path, prefix+objectPath+"/"), "/")
+		i := o.find(name)
+		if i < 0 {
+			http.Error(w, fmt.Sprintf("No %s matches the given query.", objectPath), http.StatusNotFound)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, withURI(o.objects[i]))
+		case http.MethodPut:
+			for _, key := range []string{"name", "description"} {
+				if value := r.PostFormValue(key); value != "" {
+					o.objects[i][key] = value
+				}
+			}
+			writeJSON(w, withURI(o.objects[i]))
+		case http.MethodDelete:
+			o.objects = append(o.objects[:i], o.objects[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+
+	mux.HandleFunc(prefix+collection+"/", handler)
+	if objectPath != collection {
+		mux.HandleFunc(prefix+objectPath+"/", handler)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(value)
+}
+
+func TestMAASClient_Zones(t *testing.T) {
+	zones := newNamedObjects("default")
+	mux := http.NewServeMux()
+	zones.register(mux, "zones", "zones")
+	client := newTestMAASClient(t, mux)
+	ctx := context.Background()
+
+	created, err := client.CreateZone(ctx, &entity.ZoneParams{Name: "rack-2", Description: "Second rack"})
+	require.NoError(t, err)
+	assert.Equal(t, &maas.Zone{ID: 1, Name: "rack-2", Description: "Second rack", ResourceURL: "/MAAS/api/2.0/zones/rack-2/"}, created)
+
+	list, err := client.ListZones(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "default", list[0].Name)
+	assert.Equal(t, "rack-2", list[1].Name)
+
+	updated, err := client.UpdateZone(ctx, "rack-2", &entity.ZoneParams{Name: "rack-3"})
+	require.NoError(t, err)
+	assert.Equal(t, &maas.Zone{ID: 1, Name: "rack-3", Description: "Second rack", ResourceURL: "/MAAS/api/2.0/zones/rack-3/"}, updated)
+
+	zone, err := client.GetZone(ctx, "rack-3")
+	require.NoError(t, err)
+	assert.Equal(t, updated, zone)
+
+	require.NoError(t, client.DeleteZone(ctx, "rack-3"))
+	_, err = client.GetZone(ctx, "rack-3")
+	assert.True(t, IsNotFound(err), "got %v", err)
+	assert.True(t, IsNotFound(client.DeleteZone(ctx, "rack-3")))
+
+	_, err = client.GetZone(ctx, "")
+	assert.EqualError(t, err, "zone name is required")
+	_, err = client.CreateZone(ctx, &entity.ZoneParams{})
+	assert.EqualError(t, err, "zone name is required")
+}
+
+func TestMAASClient_ResourcePools(t *testing.T) {
+	pools := newNamedObjects("default")
+	mux := http.NewServeMux()
+	pools.register(mux, "resourcepools", "resourcepool")
+	client := newTestMAASClient(t, mux)
+	ctx := context.Background()
+
+	created, err := client.CreateResourcePool(ctx, &entity.ResourcePoolParams{Name: "team-a", Description: "Team A machines"})
+	require.NoError(t, err)
+	assert.Equal(t, &maas.ResourcePool{ID: 1, Name: "team-a", Description: "Team A machines", ResourceURL: "/MAAS/api/2.0/resourcepool/team-a/"}, created)
+
+	list, err := client.ListResourcePools(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "team-a", list[1].Name)
+
+	updated, err := client.UpdateResourcePool(ctx, "team-a", &entity.ResourcePoolParams{Description: "Team A"})
+	require.NoError(t, err)
+	assert.Equal(t, &maas.ResourcePool{ID: 1, Name: "team-a", Description: "Team A", ResourceURL: "/MAAS/api/2.0/resourcepool/team-a/"}, updated)
+
+	pool, err := client.GetResourcePool(ctx, "team-a")
+	require.NoError(t, err)
+	assert.Equal(t, updated, pool)
+
+	require.NoError(t, client.DeleteResourcePool(ctx, "team-a"))
+	_, err = client.GetResourcePool(ctx, "team-a")
+	assert.True(t, IsNotFound(err), "got %v", err)
+
+	_, err = client.UpdateResourcePool(ctx, "", nil)
+	assert.EqualError(t, err, "resource pool name is required")
+}
+
+func TestMAASClient_Closed(t *testing.T) {
+	client := newTestMAASClient(t, http.NotFoundHandler())
+	require.NoError(t, client.Close())
+
+	_, err := client.ListZones(context.Background())
+	assert.EqualError(t, err, "client is closed")
+	assert.EqualError(t, client.DeleteResourcePool(context.Background(), "default"), "client is closed")
+}
+
+// maasRequest is a request the fake MAAS of cannedMAAS received
+type maasRequest struct {
+	method string
+	path   string
+	form   map[string]string
+}
+
+// cannedMAAS answers "METHOD path" with a canned JSON body, and 404 for anything else, recording
+// the requests it gets
+type cannedMAAS struct {
+	mu        sync.Mutex
+	responses map[string]string
+	requests  []maasRequest
+}
+
+func (m *cannedMAAS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/MAAS/api/2.0/")
+	request := maasRequest{method: r.Method, path: path, form: make(map[string]string)}
+	for key := range r.PostForm {
+		request.form[key] = r.PostForm.Get(key)
+	}
+	m.requests = append(m.requests, request)
+
+	body, ok := m.responses[r.Method+" "+path]
+	if !ok {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	if body == "" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(body))
+}
+
+func TestMAASClient_Spaces(t *testing.T) {
+	space := `{"id":2,"name":"storage","resource_uri":"/MAAS/api/2.0/spaces/2/",
+		"subnets":[{"id":1,"cidr":"10.0.0.0/24","vlan":{"id":5001}}],"vlans":[{"id":5001,"vid":0,"fabric_id":0}]}`
+	fake := &cannedMAAS{responses: map[string]string{
+		"GET spaces/":      "[" + space + "]",
+		"GET spaces/2/":    space,
+		"POST spaces/":     `{"id":3,"name":"public","resource_uri":"/MAAS/api/2.0/spaces/3/"}`,
+		"PUT spaces/2/":    `{"id":2,"name":"san","resource_uri":"/MAAS/api/2.0/spaces/2/"}`,
+		"DELETE spaces/2/": "",
+	}}
+	client := newTestMAASClient(t, fake)
+	ctx := context.Background()
+
+	spaces, err := client.ListSpaces(ctx)
+	require.NoError(t, err)
+	require.Len(t, spaces, 1)
+	assert.Equal(t, "storage", spaces[0].Name)
+	require.Len(t, spaces[0].Subnets, 1)
+	assert.Equal(t, "10.0.0.0/24", spaces[0].Subnets[0].CIDR)
+	require.Len(t, spaces[0].VLANs, 1)
+	assert.Equal(t, 5001, spaces[0].VLANs[0].ID)
+
+	got, err := client.GetSpace(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, &spaces[0], got)
+
+	created, err := client.CreateSpace(ctx, "public")
+	require.NoError(t, err)
+	assert.Equal(t, 3, created.ID)
+	assert.Equal(t, map[string]string{"name": "public"}, fake.requests[len(fake.requests)-1].form)
+
+	updated, err := client.UpdateSpace(ctx, 2, "san")
+	require.NoError(t, err)
+	assert.Equal(t, "san", updated.Name)
+	assert.Equal(t, maasRequest{method: http.MethodPut, path: "spaces/2/", form: map[string]string{"name": "san"}},
+		fake.requests[len(fake.requests)-1])
+
+	require.NoError(t, client.DeleteSpace(ctx, 2))
+
+	_, err = client.GetSpace(ctx, 9)
+	assert.True(t, IsNotFound(err), "got %v", err)
+	assert.True(t, IsNotFound(client.DeleteSpace(ctx, 9)))
+
+	requests := len(fake.requests)
+	_, err = client.GetSpace(ctx, -1)
+	assert.EqualError(t, err, "valid space ID is required")
+	_, err = client.UpdateSpace(ctx, 2, "")
+	assert.EqualError(t, err, "space name is required")
+	assert.Len(t, fake.requests, requests, "invalid calls do not reach MAAS")
+}
+
+func TestMAASClient_IPRanges(t *testing.T) {
+	ipRange := `{"id":7,"type":"reserved","start_ip":"10.0.0.1","end_ip":"10.0.0.9","comment":"gateways",
+		"subnet":{"id":1,"cidr":"10.0.0.0/24"},"user":{"username":"admin"},"resource_uri":"/MAAS/api/2.0/ipranges/7/"}`
+	fake := &cannedMAAS{responses: map[string]string{
+		"GET ipranges/":      "[" + ipRange + "]",
+		"GET ipranges/7/":    ipRange,
+		"POST ipranges/":     ipRange,
+		"PUT ipranges/7/":    ipRange,
+		"DELETE ipranges/7/": "",
+	}}
+	client := newTestMAASClient(t, fake)
+	ctx := context.Background()
+
+	want := maas.IPRange{
+		ID:          7,
+		Type:        maas.IPRangeTypeReserved,
+		StartIP:     "10.0.0.1",
+		EndIP:       "10.0.0.9",
+		SubnetID:    1,
+		SubnetCIDR:  "10.0.0.0/24",
+		Comment:     "gateways",
+		User:        "admin",
+		ResourceURL: "/MAAS/api/2.0/ipranges/7/",
+	}
+
+	ranges, err := client.ListIPRanges(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []maas.IPRange{want}, ranges)
+
+	got, err := client.GetIPRange(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, &want, got)
+
+	params := &entity.IPRangeParams{Type: "reserved", Subnet: "1", StartIP: "10.0.0.1", EndIP: "10.0.0.9", Comment: "gateways"}
+	created, err := client.CreateIPRange(ctx, params)
+	require.NoError(t, err)
+	assert.Equal(t, &want, created)
+	assert.Equal(t, maasRequest{
+		method: http.MethodPost,
+		path:   "ipranges/",
+		form:   map[string]string{"type": "reserved", "subnet": "1", "start_ip": "10.0.0.1", "end_ip": "10.0.0.9", "comment": "gateways"},
+	}, fake.requests[len(fake.requests)-1])
+
+	_, err = client.UpdateIPRange(ctx, 7, params)
+	require.NoError(t, err)
+	assert.Equal(t, "ipranges/7/", fake.requests[len(fake.requests)-1].path)
+
+	require.NoError(t, client.DeleteIPRange(ctx, 7))
+	assert.True(t, IsNotFound(client.DeleteIPRange(ctx, 8)))
+
+	requests := len(fake.requests)
+	_, err = client.CreateIPRange(ctx, &entity.IPRangeParams{Type: "static", Subnet: "1", StartIP: "10.0.0.1", EndIP: "10.0.0.9"})
+	assert.ErrorContains(t, err, "IP range type must be")
+	_, err = client.CreateIPRange(ctx, &entity.IPRangeParams{Type: "dynamic", StartIP: "10.0.0.1", EndIP: "10.0.0.9"})
+	assert.EqualError(t, err, "subnet is required")
+	_, err = client.UpdateIPRange(ctx, 7, nil)
+	assert.EqualError(t, err, "IP range parameters are required")
+	assert.Len(t, fake.requests, requests, "invalid calls do not reach MAAS")
+}
+
+func TestMAASClient_StaticRoutes(t *testing.T) {
+	route := `{"id":4,"gateway_ip":"10.0.0.1","metric":10,"resource_uri":"/MAAS/api/2.0/static-routes/4/",
+		"source":{"id":1,"cidr":"10.0.0.0/24"},"destination":{"id":2,"cidr":"10.1.0.0/24"}}`
+	fake := &cannedMAAS{responses: map[string]string{
+		"GET static-routes/":      "[" + route + "]",
+		"POST static-routes/":     route,
+		"DELETE static-routes/4/": "",
+	}}
+	client := newTestMAASClient(t, fake)
+	ctx := context.Background()
+
+	want := maas.StaticRoute{
+		ID:              4,
+		SourceID:        1,
+		SourceCIDR:      "10.0.0.0/24",
+		DestinationID:   2,
+		DestinationCIDR: "10.1.0.0/24",
+		GatewayIP:       "10.0.0.1",
+		Metric:          10,
+		ResourceURL:     "/MAAS/api/2.0/static-routes/4/",
+	}
+
+	routes, err := client.ListStaticRoutes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []maas.StaticRoute{want}, routes)
+
+	created, err := client.CreateStaticRoute(ctx, &entity.StaticRouteParams{Source: "1", Destination: "2", GatewayIP: "10.0.0.1", Metric: 10})
+	require.NoError(t, err)
+	assert.Equal(t, &want, created)
+	assert.Equal(t, map[string]string{"source": "1", "destination": "2", "gateway_ip": "10.0.0.1", "metric": "10"},
+		fake.requests[len(fake.requests)-1].form)
+
+	require.NoError(t, client.DeleteStaticRoute(ctx, 4))
+	assert.True(t, IsNotFound(client.DeleteStaticRoute(ctx, 5)))
+
+	_, err = client.CreateStaticRoute(ctx, &entity.StaticRouteParams{Source: "1", GatewayIP: "10.0.0.1"})
+	assert.EqualError(t, err, "source and destination subnets are required")
+	_, err = client.CreateStaticRoute(ctx, &entity.StaticRouteParams{Source: "1", Destination: "2"})
+	assert.EqualError(t, err, "gateway IP is required")
+}
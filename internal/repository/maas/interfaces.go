@@ -24,6 +24,15 @@ type Client interface {
 	// Tag Operations
 	TagOperations
 
+	// Zone Operations
+	ZoneOperations
+
+	// Resource Pool Operations
+	ResourcePoolOperations
+
+	// IP Address Operations
+	IPAddressOperations
+
 	// Close closes the client and releases any resources
 	Close() error
 }
@@ -78,6 +87,12 @@ type NetworkOperations interface {
 	// GetSubnet retrieves subnet details
 	GetSubnet(ctx context.Context, id int) (*maas.Subnet, error)
 
+	// GetSubnetStatistics retrieves the address usage MAAS reports for a subnet
+	GetSubnetStatistics(ctx context.Context, id int) (*maas.SubnetStatistics, error)
+
+	// GetSubnetIPAddresses retrieves the addresses in use in a subnet
+	GetSubnetIPAddresses(ctx context.Context, id int) ([]maas.SubnetIPAddress, error)
+
 	// ListVLANs retrieves all VLANs for a fabric
 	ListVLANs(ctx context.Context, fabricID int) ([]maas.VLAN, error)
 
@@ -93,14 +108,53 @@ type NetworkOperations interface {
 	// GetMachineInterfaces retrieves network interfaces for a specific machine
 	GetMachineInterfaces(ctx context.Context, systemID string) ([]maas.NetworkInterface, error)
 
-	// CreateSubnet creates a new subnet
+	// CreateSubnet creates a new subnet, after checking it against the existing network with a SubnetValidator
 	CreateSubnet(ctx context.Context, params map[string]interface{}) (*maas.Subnet, error)
 
-	// UpdateSubnet updates an existing subnet
+	// UpdateSubnet updates an existing subnet, after checking it against the existing network with a SubnetValidator
 	UpdateSubnet(ctx context.Context, id int, params map[string]interface{}) (*maas.Subnet, error)
 
 	// DeleteSubnet deletes a subnet
 	DeleteSubnet(ctx context.Context, id int) error
+
+	// ListSpaces retrieves all spaces
+	ListSpaces(ctx context.Context) ([]maas.Space, error)
+
+	// GetSpace retrieves space details, including its subnets and VLANs
+	GetSpace(ctx context.Context, id int) (*maas.Space, error)
+
+	// CreateSpace creates a new space
+	CreateSpace(ctx context.Context, name string) (*maas.Space, error)
+
+	// UpdateSpace renames a space
+	UpdateSpace(ctx context.Context, id int, name string) (*maas.Space, error)
+
+	// DeleteSpace deletes a space
+	DeleteSpace(ctx context.Context, id int) error
+
+	// ListIPRanges retrieves all reserved and dynamic IP ranges
+	ListIPRanges(ctx context.Context) ([]maas.IPRange, error)
+
+	// GetIPRange retrieves IP range details
+	GetIPRange(ctx context.Context, id int) (*maas.IPRange, error)
+
+	// CreateIPRange creates a new reserved or dynamic IP range
+	CreateIPRange(ctx context.Context, params *entity.IPRangeParams) (*maas.IPRange, error)
+
+	// UpdateIPRange updates an existing IP range
+	UpdateIPRange(ctx context.Context, id int, params *entity.IPRangeParams) (*maas.IPRange, error)
+
+	// DeleteIPRange deletes an IP range
+	DeleteIPRange(ctx context.Context, id int) error
+
+	// ListStaticRoutes retrieves all static routes
+	ListStaticRoutes(ctx context.Context) ([]maas.StaticRoute, error)
+
+	// CreateStaticRoute creates a new static route
+	CreateStaticRoute(ctx context.Context, params *entity.StaticRouteParams) (*maas.StaticRoute, error)
+
+	// DeleteStaticRoute deletes a static route
+	DeleteStaticRoute(ctx context.Context, id int) error
 }
 
 // StorageOperations defines the interface for storage-related operations
@@ -163,6 +217,54 @@ type TagOperations interface {
 	GetMachinesWithTag(ctx context.Context, tagName string) ([]maas.Machine, error)
 }
 
+// ZoneOperations defines the interface for availability zone operations
+type ZoneOperations interface {
+	// ListZones retrieves all zones
+	ListZones(ctx context.Context) ([]maas.Zone, error)
+
+	// GetZone retrieves zone details
+	GetZone(ctx context.Context, name string) (*maas.Zone, error)
+
+	// CreateZone creates a new zone
+	CreateZone(ctx context.Context, params *entity.ZoneParams) (*maas.Zone, error)
+
+	// UpdateZone updates an existing zone; fields left empty in params are not changed
+	UpdateZone(ctx context.Context, name string, params *entity.ZoneParams) (*maas.Zone, error)
+
+	// DeleteZone deletes a zone
+	DeleteZone(ctx context.Context, name string) error
+}
+
+// ResourcePoolOperations defines the interface for resource pool operations
+type ResourcePoolOperations interface {
+	// ListResourcePools retrieves all resource pools
+	ListResourcePools(ctx context.Context) ([]maas.ResourcePool, error)
+
+	// GetResourcePool retrieves resource pool details
+	GetResourcePool(ctx context.Context, name string) (*maas.ResourcePool, error)
+
+	// CreateResourcePool creates a new resource pool
+	CreateResourcePool(ctx context.Context, params *entity.ResourcePoolParams) (*maas.ResourcePool, error)
+
+	// UpdateResourcePool updates an existing resource pool; fields left empty in params are not changed
+	UpdateResourcePool(ctx context.Context, name string, params *entity.ResourcePoolParams) (*maas.ResourcePool, error)
+
+	// DeleteResourcePool deletes a resource pool
+	DeleteResourcePool(ctx context.Context, name string) error
+}
+
+// IPAddressOperations defines the interface for static IP address reservations
+type IPAddressOperations interface {
+	// ListIPAddresses retrieves the addresses reserved by the API user, or by every user when all is set
+	ListIPAddresses(ctx context.Context, all bool) ([]maas.IPAddress, error)
+
+	// ReserveIPAddress reserves an address, optionally bound to a MAC address and with a hostname
+	ReserveIPAddress(ctx context.Context, params *maas.IPAddressReserveParams) (*maas.IPAddress, error)
+
+	// ReleaseIPAddress releases a reserved address; force releases addresses reserved by other users
+	ReleaseIPAddress(ctx context.Context, ip string, force bool) error
+}
+
 // ClientRegistry defines the interface for managing multiple MAAS clients
 type ClientRegistry interface {
 	// GetClient returns the client for a specific MAAS instance
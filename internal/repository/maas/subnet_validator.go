@@ -0,0 +1,328 @@
+package maas
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/gomaasclient/entity"
+
+	ierrors "github.com/lspecian/maas-mcp-server/internal/errors"
+	"github.com/lspecian/maas-mcp-server/internal/models/maas"
+)
+
+const (
+	// undefinedSpace is the space MAAS puts subnets in when their VLAN has none
+	undefinedSpace = "undefined"
+
+	// maxVID is the largest VLAN ID
+	maxVID = 4094
+)
+
+// Detail keys of the violations SubnetValidator reports, named after the parameter at fault
+const (
+	SubnetDetailCIDR     = "cidr"
+	SubnetDetailGateway  = "gateway_ip"
+	SubnetDetailDNS      = "dns_servers"
+	SubnetDetailVLAN     = "vlan"
+	SubnetDetailFabric   = "fabric"
+	SubnetDetailVID      = "vid"
+	SubnetDetailIPRanges = "ip_ranges"
+)
+
+// SubnetSource is the part of NetworkOperations a SubnetValidator loads the network from
+type SubnetSource interface {
+	ListSubnets(ctx context.Context) ([]maas.Subnet, error)
+	ListFabrics(ctx context.Context) ([]maas.Fabric, error)
+	ListVLANs(ctx context.Context, fabricID int) ([]maas.VLAN, error)
+	ListIPRanges(ctx context.Context) ([]maas.IPRange, error)
+}
+
+// SubnetValidator checks a subnet against every subnet, VLAN and fabric MAAS has before it is
+// created or updated. It rejects:
+//   - a CIDR that overlaps another subnet in the same space
+//   - a gateway or DNS server that is not a valid address, and a gateway outside the CIDR
+//   - a VLAN, fabric or VID that does not exist, or that disagree with each other
+//   - IP ranges of an updated subnet that fall outside its CIDR, or dynamic ranges that collide
+//     with reserved ranges
+type SubnetValidator struct {
+	source SubnetSource
+}
+
+// NewSubnetValidator creates a new subnet validator that loads the network from source
+func NewSubnetValidator(source SubnetSource) *SubnetValidator {
+	return &SubnetValidator{
+		source: source,
+	}
+}
+
+// subnetNetwork is the network a subnet is validated against
+type subnetNetwork struct {
+	subnets  []maas.Subnet
+	fabrics  []maas.Fabric
+	vlans    []maas.VLAN
+	ipRanges []maas.IPRange
+}
+
+// load reads every subnet, fabric and VLAN, and the IP ranges when they are checked
+func (v *SubnetValidator) load(ctx context.Context, withRanges bool) (*subnetNetwork, error) {
+	network := &subnetNetwork{}
+
+	var err error
+	if network.subnets, err = v.source.ListSubnets(ctx); err != nil {
+		return nil, fmt.Errorf("failed to list subnets: %w", err)
+	}
+	if network.fabrics, err = v.source.ListFabrics(ctx); err != nil {
+		return nil, fmt.Errorf("failed to list fabrics: %w", err)
+	}
+	for _, fabric := range network.fabrics {
+		vlans, err := v.source.ListVLANs(ctx, fabric.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list VLANs of fabric %d: %w", fabric.ID, err)
+		}
+		// MAAS names the fabric of a VLAN rather than giving its ID
+		for i := range vlans {
+			vlans[i].FabricID = fabric.ID
+		}
+		network.vlans = append(network.vlans, vlans...)
+	}
+	if withRanges {
+		if network.ipRanges, err = v.source.ListIPRanges(ctx); err != nil {
+			return nil, fmt.Errorf("failed to list IP ranges: %w", err)
+		}
+	}
+
+	return network, nil
+}
+
+// Validate checks the parameters a subnet is created with, when id is 0, or the merged parameters
+// subnet id is updated to. Violations are returned as an invalid input validation error whose
+// details map each offending parameter to what is wrong with it.
+func (v *SubnetValidator) Validate(ctx context.Context, id int, params *entity.SubnetParams) error {
+	network, err := v.load(ctx, id > 0)
+	if err != nil {
+		return err
+	}
+
+	violations := network.check(id, params)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	appErr := ierrors.NewValidationErrorWithCode(ierrors.ErrorCodeInvalidInput,
+		fmt.Sprintf("Subnet %s conflicts with the MAAS network", params.CIDR), nil)
+	keys := make([]string, 0, len(violations))
+	for key := range violations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		appErr.WithDetail(key, strings.Join(violations[key], "; "))
+	}
+	return appErr
+}
+
+// check returns the violations of params, by detail key
+func (n *subnetNetwork) check(id int, params *entity.SubnetParams) map[string][]string {
+	violations := make(map[string][]string)
+	add := func(key, format string, args ...interface{}) {
+		violations[key] = append(violations[key], fmt.Sprintf(format, args...))
+	}
+
+	prefix, err := netip.ParsePrefix(params.CIDR)
+	switch {
+	case params.CIDR == "":
+		add(SubnetDetailCIDR, "CIDR is required")
+	case err != nil:
+		add(SubnetDetailCIDR, "%q is not a valid CIDR", params.CIDR)
+	case prefix.Masked() != prefix:
+		add(SubnetDetailCIDR, "%s has host bits set; did you mean %s?", params.CIDR, prefix.Masked())
+	}
+	validPrefix := err == nil && prefix.Masked() == prefix
+
+	if params.GatewayIP != "" {
+		gateway, err := netip.ParseAddr(params.GatewayIP)
+		switch {
+		case err != nil:
+			add(SubnetDetailGateway, "%q is not a valid IP address", params.GatewayIP)
+		case validPrefix && !prefix.Contains(gateway):
+			add(SubnetDetailGateway, "gateway %s is outside %s", gateway, prefix)
+		}
+	}
+	for _, server := range params.DNSServers {
+		if _, err := netip.ParseAddr(server); err != nil {
+			add(SubnetDetailDNS, "%q is not a valid IP address", server)
+		}
+	}
+
+	vlan := n.checkVLAN(params, add)
+
+	if validPrefix {
+		space := n.space(id, vlan)
+		for _, subnet := range n.subnets {
+			if subnet.ID == id || spaceName(subnet.Space) != space {
+				continue
+			}
+			other, err := netip.ParsePrefix(subnet.CIDR)
+			if err != nil || !other.Overlaps(prefix) {
+				continue
+			}
+			add(SubnetDetailCIDR, "%s overlaps subnet %d (%s) in space %q", prefix, subnet.ID, subnet.CIDR, space)
+		}
+	}
+
+	if id > 0 {
+		n.checkIPRanges(id, prefix, validPrefix, add)
+	}
+
+	return violations
+}
+
+// checkVLAN resolves the VLAN the subnet goes on from its vlan, fabric and vid parameters, reporting
+// the ones that do not exist or disagree. MAAS picks the VLAN with the VID on the fabric, or the
+// default fabric, when no VLAN is given. It returns nil when the VLAN cannot be resolved.
+func (n *subnetNetwork) checkVLAN(params *entity.SubnetParams, add func(key, format string, args ...interface{})) *maas.VLAN {
+	var fabric *maas.Fabric
+	if params.Fabric != "" {
+		if fabric = n.fabric(params.Fabric); fabric == nil {
+			add(SubnetDetailFabric, "fabric %q does not exist", params.Fabric)
+			return nil
+		}
+	}
+
+	if params.VID < 0 || params.VID > maxVID {
+		add(SubnetDetailVID, "VID %d is outside 0-%d", params.VID, maxVID)
+		return nil
+	}
+
+	if params.VLAN != "" {
+		vlanID, err := strconv.Atoi(params.VLAN)
+		vlan := n.vlan(vlanID)
+		if err != nil || vlan == nil {
+			add(SubnetDetailVLAN, "VLAN %q does not exist", params.VLAN)
+			return nil
+		}
+		if fabric != nil && vlan.FabricID != fabric.ID {
+			add(SubnetDetailVLAN, "VLAN %d is on fabric %d, not fabric %d (%s)", vlan.ID, vlan.FabricID, fabric.ID, fabric.Name)
+		}
+		if params.VID != 0 && vlan.VID != params.VID {
+			add(SubnetDetailVID, "VID %d conflicts with VLAN %d, which has VID %d", params.VID, vlan.ID, vlan.VID)
+		}
+		return vlan
+	}
+
+	if fabric == nil {
+		if fabric = n.defaultFabric(); fabric == nil {
+			return nil
+		}
+	}
+	for i := range n.vlans {
+		if n.vlans[i].FabricID == fabric.ID && n.vlans[i].VID == params.VID {
+			return &n.vlans[i]
+		}
+	}
+	if params.VID != 0 {
+		add(SubnetDetailVID, "fabric %d (%s) has no VLAN with VID %d", fabric.ID, fabric.Name, params.VID)
+	}
+	return nil
+}
+
+// checkIPRanges reports IP ranges of subnet id that fall outside prefix, and dynamic ranges that
+// collide with reserved ranges
+func (n *subnetNetwork) checkIPRanges(id int, prefix netip.Prefix, validPrefix bool, add func(key, format string, args ...interface{})) {
+	type ipRange struct {
+		maas.IPRange
+		start, end netip.Addr
+	}
+
+	var dynamic, reserved []ipRange
+	for _, r := range n.ipRanges {
+		if r.SubnetID != id {
+			continue
+		}
+		start, startErr := netip.ParseAddr(r.StartIP)
+		end, endErr := netip.ParseAddr(r.EndIP)
+		if startErr != nil || endErr != nil {
+			continue
+		}
+		if validPrefix && (!prefix.Contains(start) || !prefix.Contains(end)) {
+			add(SubnetDetailIPRanges, "%s range %d (%s-%s) is outside %s", r.Type, r.ID, r.StartIP, r.EndIP, prefix)
+		}
+		switch r.Type {
+		case maas.IPRangeTypeDynamic:
+			dynamic = append(dynamic, ipRange{r, start, end})
+		case maas.IPRangeTypeReserved:
+			reserved = append(reserved, ipRange{r, start, end})
+		}
+	}
+
+	for _, d := range dynamic {
+		for _, r := range reserved {
+			if d.start.Compare(r.end) <= 0 && r.start.Compare(d.end) <= 0 {
+				add(SubnetDetailIPRanges, "dynamic range %d (%s-%s) collides with reserved range %d (%s-%s)",
+					d.ID, d.StartIP, d.EndIP, r.ID, r.StartIP, r.EndIP)
+			}
+		}
+	}
+}
+
+// space returns the space a subnet on vlan is in: the space of the other subnets on the VLAN, as
+// MAAS assigns spaces to VLANs, or else the current space of subnet id
+func (n *subnetNetwork) space(id int, vlan *maas.VLAN) string {
+	if vlan != nil {
+		for _, subnet := range n.subnets {
+			if subnet.ID != id && subnet.VLANid == vlan.ID {
+				return spaceName(subnet.Space)
+			}
+		}
+	}
+	for _, subnet := range n.subnets {
+		if subnet.ID == id {
+			return spaceName(subnet.Space)
+		}
+	}
+	return undefinedSpace
+}
+
+// fabric returns the fabric with the given ID or name
+func (n *subnetNetwork) fabric(idOrName string) *maas.Fabric {
+	id, err := strconv.Atoi(idOrName)
+	for i := range n.fabrics {
+		if (err == nil && n.fabrics[i].ID == id) || n.fabrics[i].Name == idOrName {
+			return &n.fabrics[i]
+		}
+	}
+	return nil
+}
+
+// defaultFabric returns the fabric MAAS puts subnets on when none is given, the one with the lowest ID
+func (n *subnetNetwork) defaultFabric() *maas.Fabric {
+	var fabric *maas.Fabric
+	for i := range n.fabrics {
+		if fabric == nil || n.fabrics[i].ID < fabric.ID {
+			fabric = &n.fabrics[i]
+		}
+	}
+	return fabric
+}
+
+// vlan returns the VLAN with the given ID
+func (n *subnetNetwork) vlan(id int) *maas.VLAN {
+	for i := range n.vlans {
+		if n.vlans[i].ID == id {
+			return &n.vlans[i]
+		}
+	}
+	return nil
+}
+
+// spaceName returns the name of a subnet's space, which is undefinedSpace when it has none
+func spaceName(space string) string {
+	if space == "" {
+		return undefinedSpace
+	}
+	return space
+}
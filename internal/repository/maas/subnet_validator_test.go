@@ -0,0 +1,182 @@
+package maas
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/canonical/gomaasclient/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ierrors "github.com/lspecian/maas-mcp-server/internal/errors"
+	"github.com/lspecian/maas-mcp-server/internal/models/maas"
+)
+
+// fakeSubnetSource serves two fabrics: fabric-0 with the untagged VLAN 5001 and VLAN 5002 (VID 100)
+// and fabric-1 with the untagged VLAN 5003. Subnet 1 is in space "prod" on VLAN 5002, subnet 2 in
+// space "lab" on VLAN 5003, with a reserved range .2-.9 and a dynamic range .100-.199 in subnet 1.
+type fakeSubnetSource struct {
+	err error
+}
+
+func (f *fakeSubnetSource) ListSubnets(ctx context.Context) ([]maas.Subnet, error) {
+	return []maas.Subnet{
+		{ID: 1, CIDR: "10.0.0.0/24", VLANid: 5002, Space: "prod"},
+		{ID: 2, CIDR: "10.1.0.0/16", VLANid: 5003, Space: "lab"},
+	}, f.err
+}
+
+func (f *fakeSubnetSource) ListFabrics(ctx context.Context) ([]maas.Fabric, error) {
+	return []maas.Fabric{{ID: 0, Name: "fabric-0"}, {ID: 1, Name: "fabric-1"}}, nil
+}
+
+func (f *fakeSubnetSource) ListVLANs(ctx context.Context, fabricID int) ([]maas.VLAN, error) {
+	// MAAS does not return the fabric ID of a VLAN
+	if fabricID == 0 {
+		return []maas.VLAN{{ID: 5001, VID: 0}, {ID: 5002, VID: 100}}, nil
+	}
+	return []maas.VLAN{{ID: 5003, VID: 0}}, nil
+}
+
+func (f *fakeSubnetSource) ListIPRanges(ctx context.Context) ([]maas.IPRange, error) {
+	return []maas.IPRange{
+		{ID: 1, Type: maas.IPRangeTypeReserved, StartIP: "10.0.0.2", EndIP: "10.0.0.9", SubnetID: 1},
+		{ID: 2, Type: maas.IPRangeTypeDynamic, StartIP: "10.0.0.100", EndIP: "10.0.0.199", SubnetID: 1},
+	}, nil
+}
+
+func TestSubnetValidator(t *testing.T) {
+	validator := NewSubnetValidator(&fakeSubnetSource{})
+
+	tests := []struct {
+		name   string
+		id     int
+		params entity.SubnetParams
+		want   map[string]string
+	}{
+		{
+			name:   "New subnet on its own",
+			params: entity.SubnetParams{CIDR: "10.2.0.0/24", GatewayIP: "10.2.0.1", Fabric: "fabric-1"},
+		},
+		{
+			name:   "Overlap in another space",
+			params: entity.SubnetParams{CIDR: "10.1.2.0/24", VLAN: "5001"},
+		},
+		{
+			name:   "Overlap in the same space",
+			params: entity.SubnetParams{CIDR: "10.0.0.128/25", VID: 100},
+			want:   map[string]string{"cidr": `10.0.0.128/25 overlaps subnet 1 (10.0.0.0/24) in space "prod"`},
+		},
+		{
+			name:   "Invalid CIDR and addresses",
+			params: entity.SubnetParams{CIDR: "10.3.0.1/24", GatewayIP: "gateway", DNSServers: []string{"8.8.8.8", "dns"}},
+			want: map[string]string{
+				"cidr":        "10.3.0.1/24 has host bits set; did you mean 10.3.0.0/24?",
+				"gateway_ip":  `"gateway" is not a valid IP address`,
+				"dns_servers": `"dns" is not a valid IP address`,
+			},
+		},
+		{
+			name:   "Gateway outside the CIDR",
+			params: entity.SubnetParams{CIDR: "10.3.0.0/24", GatewayIP: "10.3.1.1"},
+			want:   map[string]string{"gateway_ip": "gateway 10.3.1.1 is outside 10.3.0.0/24"},
+		},
+		{
+			name:   "VID conflicts with the VLAN",
+			params: entity.SubnetParams{CIDR: "10.3.0.0/24", VLAN: "5002", VID: 200},
+			want:   map[string]string{"vid": "VID 200 conflicts with VLAN 5002, which has VID 100"},
+		},
+		{
+			name:   "VLAN on another fabric",
+			params: entity.SubnetParams{CIDR: "10.3.0.0/24", VLAN: "5003", Fabric: "0"},
+			want:   map[string]string{"vlan": "VLAN 5003 is on fabric 1, not fabric 0 (fabric-0)"},
+		},
+		{
+			name:   "VID not on the fabric",
+			params: entity.SubnetParams{CIDR: "10.3.0.0/24", Fabric: "fabric-1", VID: 100},
+			want:   map[string]string{"vid": "fabric 1 (fabric-1) has no VLAN with VID 100"},
+		},
+		{
+			name:   "Unknown VLAN",
+			params: entity.SubnetParams{CIDR: "10.3.0.0/24", VLAN: "42"},
+			want:   map[string]string{"vlan": `VLAN "42" does not exist`},
+		},
+		{
+			name:   "Update keeps its own CIDR",
+			id:     1,
+			params: entity.SubnetParams{CIDR: "10.0.0.0/24", VLAN: "5002", GatewayIP: "10.0.0.1"},
+		},
+		{
+			name:   "Update shrinks the CIDR past its ranges",
+			id:     1,
+			params: entity.SubnetParams{CIDR: "10.0.0.0/25", VLAN: "5002"},
+			want:   map[string]string{"ip_ranges": "dynamic range 2 (10.0.0.100-10.0.0.199) is outside 10.0.0.0/25"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.Validate(context.Background(), tt.id, &tt.params)
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var appErr *ierrors.AppError
+			require.True(t, errors.As(err, &appErr))
+			assert.Equal(t, ierrors.ErrorTypeValidation, appErr.Type)
+			assert.Equal(t, tt.want, appErr.Details)
+		})
+	}
+}
+
+func TestSubnetValidator_RangeCollision(t *testing.T) {
+	network := &subnetNetwork{
+		subnets: []maas.Subnet{{ID: 1, CIDR: "10.0.0.0/24"}},
+		ipRanges: []maas.IPRange{
+			{ID: 1, Type: maas.IPRangeTypeReserved, StartIP: "10.0.0.2", EndIP: "10.0.0.120", SubnetID: 1},
+			{ID: 2, Type: maas.IPRangeTypeDynamic, StartIP: "10.0.0.100", EndIP: "10.0.0.199", SubnetID: 1},
+			{ID: 3, Type: maas.IPRangeTypeDynamic, StartIP: "10.0.0.200", EndIP: "10.0.0.220", SubnetID: 1},
+		},
+	}
+
+	violations := network.check(1, &entity.SubnetParams{CIDR: "10.0.0.0/24"})
+	assert.Equal(t, map[string][]string{
+		"ip_ranges": {"dynamic range 2 (10.0.0.100-10.0.0.199) collides with reserved range 1 (10.0.0.2-10.0.0.120)"},
+	}, violations)
+}
+
+func TestSubnetValidator_LoadError(t *testing.T) {
+	validator := NewSubnetValidator(&fakeSubnetSource{err: errors.New("connection refused")})
+
+	err := validator.Validate(context.Background(), 0, &entity.SubnetParams{CIDR: "10.3.0.0/24"})
+	require.Error(t, err)
+	var appErr *ierrors.AppError
+	assert.False(t, errors.As(err, &appErr))
+}
+
+func TestApplySubnetParams(t *testing.T) {
+	params := &entity.SubnetParams{Managed: true}
+	err := applySubnetParams(params, map[string]interface{}{
+		"cidr":        "10.3.0.0/24",
+		"vlan":        float64(5002),
+		"vid":         "100",
+		"dns_servers": []interface{}{"8.8.8.8", "8.8.4.4"},
+		"allow_dns":   false,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &entity.SubnetParams{
+		CIDR:       "10.3.0.0/24",
+		VLAN:       "5002",
+		VID:        100,
+		DNSServers: []string{"8.8.8.8", "8.8.4.4"},
+		Managed:    true,
+	}, params)
+
+	err = applySubnetParams(params, map[string]interface{}{"vid": 1.5, "colour": "blue"})
+	var appErr *ierrors.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.Contains(t, appErr.Details, "vid")
+	assert.Contains(t, appErr.Details, "colour")
+}
@@ -79,6 +79,26 @@ func (r *MaasRepository) DeployMachine(ctx context.Context, systemID string, par
 	return machine, nil
 }
 
+// AbortMachineOperation aborts the current operation on a machine
+func (r *MaasRepository) AbortMachineOperation(ctx context.Context, systemID string, comment string) (*types.Machine, error) {
+	machine, err := r.client.MachineClient.AbortMachineOperation(systemID, comment)
+	if err != nil {
+		r.logger.WithError(err).WithField("id", systemID).Error("Failed to abort machine operation in MAAS via ClientWrapper")
+		return nil, err
+	}
+	return machine, nil
+}
+
+// ListMachineEvents retrieves the most recent events of a machine
+func (r *MaasRepository) ListMachineEvents(ctx context.Context, systemID string, limit int) ([]types.MachineEvent, error) {
+	events, err := r.client.MachineClient.ListMachineEvents(systemID, limit)
+	if err != nil {
+		r.logger.WithError(err).WithField("id", systemID).Error("Failed to list machine events in MAAS via ClientWrapper")
+		return nil, err
+	}
+	return events, nil
+}
+
 // ReleaseMachine releases a machine back to the pool
 func (r *MaasRepository) ReleaseMachine(ctx context.Context, systemIDs []string, comment string) error {
 	// Call MAAS client
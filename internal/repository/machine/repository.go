@@ -33,3 +33,16 @@ type Repository interface {
 	// PowerOffMachine powers off a machine
 	PowerOffMachine(ctx context.Context, systemID string) (*types.Machine, error)
 }
+
+// OperationAborter is implemented by repositories that can abort a machine's current operation.
+// It is separate from Repository so that existing implementations need not support it.
+type OperationAborter interface {
+	// AbortMachineOperation aborts the current operation (deploying, commissioning, ...) on a machine
+	AbortMachineOperation(ctx context.Context, systemID string, comment string) (*types.Machine, error)
+}
+
+// EventLister is implemented by repositories that can read a machine's MAAS event log
+type EventLister interface {
+	// ListMachineEvents returns at most limit of the machine's most recent events, newest first
+	ListMachineEvents(ctx context.Context, systemID string, limit int) ([]types.MachineEvent, error)
+}
@@ -380,11 +380,15 @@ func (h *Handlers) ReleaseMachine(c *gin.Context) {
 		return
 	}
 
-	_, err := h.service.ReleaseMachine(c.Request.Context(), req)
+	result, err := h.service.ReleaseMachine(c.Request.Context(), req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to release machine: " + err.Error()})
 		return
 	}
+	if req.Async {
+		c.JSON(http.StatusAccepted, result) // Operation to follow on the progress stream
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Machine %s released successfully", req.SystemID)})
 }
 
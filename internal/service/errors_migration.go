@@ -0,0 +1,64 @@
+package service
+
+import (
+	ierrors "github.com/lspecian/maas-mcp-server/internal/errors"
+)
+
+// ToAppError converts a ServiceError to the appropriate errors.AppError type
+// This is a utility function to help with migration from the old error system to the new one
+func ToAppError(serviceErr *ServiceError) *ierrors.AppError {
+	switch serviceErr.Err {
+	case ErrNotFound:
+		return ierrors.NewNotFoundError(serviceErr.Message, serviceErr.Err)
+	case ErrBadRequest:
+		return ierrors.NewValidationError(serviceErr.Message, serviceErr.Err)
+	case ErrForbidden:
+		return ierrors.NewAuthenticationError(serviceErr.Message, serviceErr.Err)
+	case ErrServiceUnavailable, ErrConflict:
+		return ierrors.NewMaasClientError(serviceErr.Message, serviceErr.Err)
+	default:
+		return ierrors.NewInternalError(serviceErr.Message, serviceErr.Err)
+	}
+}
+
+// FromAppError converts an errors.AppError to a ServiceError
+// This is a utility function to help with migration from the new error system to the old one
+// if needed for backward compatibility
+func FromAppError(appErr *ierrors.AppError) *ServiceError {
+	var serviceErr *ServiceError
+
+	switch appErr.Type {
+	case ierrors.ErrorTypeNotFound:
+		serviceErr = &ServiceError{
+			Err:        ErrNotFound,
+			StatusCode: appErr.HTTPStatusCode(),
+			Message:    appErr.Message,
+		}
+	case ierrors.ErrorTypeValidation:
+		serviceErr = &ServiceError{
+			Err:        ErrBadRequest,
+			StatusCode: appErr.HTTPStatusCode(),
+			Message:    appErr.Message,
+		}
+	case ierrors.ErrorTypeAuthentication:
+		serviceErr = &ServiceError{
+			Err:        ErrForbidden,
+			StatusCode: appErr.HTTPStatusCode(),
+			Message:    appErr.Message,
+		}
+	case ierrors.ErrorTypeMaasClient:
+		serviceErr = &ServiceError{
+			Err:        ErrServiceUnavailable,
+			StatusCode: appErr.HTTPStatusCode(),
+			Message:    appErr.Message,
+		}
+	default:
+		serviceErr = &ServiceError{
+			Err:        ErrInternalServer,
+			StatusCode: appErr.HTTPStatusCode(),
+			Message:    appErr.Message,
+		}
+	}
+
+	return serviceErr
+}
@@ -1,92 +1,92 @@
-package errors
+package service
 
 import (
 	"net/http"
 	"testing"
 
-	"github.com/lspecian/maas-mcp-server/internal/service"
+	ierrors "github.com/lspecian/maas-mcp-server/internal/errors"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestFromServiceError(t *testing.T) {
+func TestToAppError(t *testing.T) {
 	testCases := []struct {
 		name          string
-		serviceErr    *service.ServiceError
-		expectedType  ErrorType
+		serviceErr    *ServiceError
+		expectedType  ierrors.ErrorType
 		expectedMsg   string
 		expectedCause error
 	}{
 		{
 			name: "NotFoundError",
-			serviceErr: &service.ServiceError{
-				Err:        service.ErrNotFound,
+			serviceErr: &ServiceError{
+				Err:        ErrNotFound,
 				StatusCode: http.StatusNotFound,
 				Message:    "resource not found",
 			},
-			expectedType:  ErrorTypeNotFound,
+			expectedType:  ierrors.ErrorTypeNotFound,
 			expectedMsg:   "resource not found",
-			expectedCause: service.ErrNotFound,
+			expectedCause: ErrNotFound,
 		},
 		{
 			name: "BadRequestError",
-			serviceErr: &service.ServiceError{
-				Err:        service.ErrBadRequest,
+			serviceErr: &ServiceError{
+				Err:        ErrBadRequest,
 				StatusCode: http.StatusBadRequest,
 				Message:    "invalid request",
 			},
-			expectedType:  ErrorTypeValidation,
+			expectedType:  ierrors.ErrorTypeValidation,
 			expectedMsg:   "invalid request",
-			expectedCause: service.ErrBadRequest,
+			expectedCause: ErrBadRequest,
 		},
 		{
 			name: "ForbiddenError",
-			serviceErr: &service.ServiceError{
-				Err:        service.ErrForbidden,
+			serviceErr: &ServiceError{
+				Err:        ErrForbidden,
 				StatusCode: http.StatusForbidden,
 				Message:    "forbidden",
 			},
-			expectedType:  ErrorTypeAuthentication,
+			expectedType:  ierrors.ErrorTypeAuthentication,
 			expectedMsg:   "forbidden",
-			expectedCause: service.ErrForbidden,
+			expectedCause: ErrForbidden,
 		},
 		{
 			name: "ServiceUnavailableError",
-			serviceErr: &service.ServiceError{
-				Err:        service.ErrServiceUnavailable,
+			serviceErr: &ServiceError{
+				Err:        ErrServiceUnavailable,
 				StatusCode: http.StatusServiceUnavailable,
 				Message:    "service unavailable",
 			},
-			expectedType:  ErrorTypeMaasClient,
+			expectedType:  ierrors.ErrorTypeMaasClient,
 			expectedMsg:   "service unavailable",
-			expectedCause: service.ErrServiceUnavailable,
+			expectedCause: ErrServiceUnavailable,
 		},
 		{
 			name: "ConflictError",
-			serviceErr: &service.ServiceError{
-				Err:        service.ErrConflict,
+			serviceErr: &ServiceError{
+				Err:        ErrConflict,
 				StatusCode: http.StatusConflict,
 				Message:    "conflict",
 			},
-			expectedType:  ErrorTypeMaasClient,
+			expectedType:  ierrors.ErrorTypeMaasClient,
 			expectedMsg:   "conflict",
-			expectedCause: service.ErrConflict,
+			expectedCause: ErrConflict,
 		},
 		{
 			name: "InternalServerError",
-			serviceErr: &service.ServiceError{
-				Err:        service.ErrInternalServer,
+			serviceErr: &ServiceError{
+				Err:        ErrInternalServer,
 				StatusCode: http.StatusInternalServerError,
 				Message:    "internal server error",
 			},
-			expectedType:  ErrorTypeInternal,
+			expectedType:  ierrors.ErrorTypeInternal,
 			expectedMsg:   "internal server error",
-			expectedCause: service.ErrInternalServer,
+			expectedCause: ErrInternalServer,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			appErr := FromServiceError(tc.serviceErr)
+			appErr := ToAppError(tc.serviceErr)
 
 			assert.Equal(t, tc.expectedType, appErr.Type)
 			assert.Equal(t, tc.expectedMsg, appErr.Message)
@@ -95,66 +95,66 @@ func TestFromServiceError(t *testing.T) {
 	}
 }
 
-func TestToServiceError(t *testing.T) {
+func TestFromAppError(t *testing.T) {
 	testCases := []struct {
 		name               string
-		appErr             *AppError
+		appErr             *ierrors.AppError
 		expectedErr        error
 		expectedStatusCode int
 		expectedMsg        string
 	}{
 		{
 			name: "NotFoundError",
-			appErr: &AppError{
-				Type:    ErrorTypeNotFound,
+			appErr: &ierrors.AppError{
+				Type:    ierrors.ErrorTypeNotFound,
 				Message: "resource not found",
 				Cause:   nil,
 			},
-			expectedErr:        service.ErrNotFound,
+			expectedErr:        ErrNotFound,
 			expectedStatusCode: http.StatusNotFound,
 			expectedMsg:        "resource not found",
 		},
 		{
 			name: "ValidationError",
-			appErr: &AppError{
-				Type:    ErrorTypeValidation,
+			appErr: &ierrors.AppError{
+				Type:    ierrors.ErrorTypeValidation,
 				Message: "invalid request",
 				Cause:   nil,
 			},
-			expectedErr:        service.ErrBadRequest,
+			expectedErr:        ErrBadRequest,
 			expectedStatusCode: http.StatusBadRequest,
 			expectedMsg:        "invalid request",
 		},
 		{
 			name: "AuthenticationError",
-			appErr: &AppError{
-				Type:    ErrorTypeAuthentication,
+			appErr: &ierrors.AppError{
+				Type:    ierrors.ErrorTypeAuthentication,
 				Message: "unauthorized",
 				Cause:   nil,
 			},
-			expectedErr:        service.ErrForbidden,
+			expectedErr:        ErrForbidden,
 			expectedStatusCode: http.StatusUnauthorized,
 			expectedMsg:        "unauthorized",
 		},
 		{
 			name: "MaasClientError",
-			appErr: &AppError{
-				Type:    ErrorTypeMaasClient,
+			appErr: &ierrors.AppError{
+				Type:    ierrors.ErrorTypeMaasClient,
 				Message: "maas client error",
 				Cause:   nil,
 			},
-			expectedErr:        service.ErrServiceUnavailable,
+			expectedErr:        ErrServiceUnavailable,
 			expectedStatusCode: http.StatusBadGateway,
 			expectedMsg:        "maas client error",
 		},
 		{
 			name: "InternalError",
-			appErr: &AppError{
-				Type:    ErrorTypeInternal,
+			appErr: &ierrors.AppError{
+				Type:    ierrors.ErrorTypeInternal,
 				Message: "internal error",
 				Cause:   nil,
 			},
-			expectedErr:        service.ErrInternalServer,
+			expectedErr:        ErrInternalServer,
 			expectedStatusCode: http.StatusInternalServerError,
 			expectedMsg:        "internal error",
 		},
@@ -162,7 +162,7 @@ func TestToServiceError(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			serviceErr := ToServiceError(tc.appErr)
+			serviceErr := FromAppError(tc.appErr)
 
 			assert.Equal(t, tc.expectedErr, serviceErr.Err)
 			assert.Equal(t, tc.expectedStatusCode, serviceErr.StatusCode)
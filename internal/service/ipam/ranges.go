@@ -0,0 +1,160 @@
+package ipam
+
+import (
+	"math"
+	"math/big"
+	"net/netip"
+	"sort"
+)
+
+// addrRange is an inclusive range of addresses of one IP family, held as integers so that
+// IPv6 subnets are handled like IPv4 ones
+type addrRange struct {
+	start *big.Int
+	end   *big.Int
+}
+
+// addrToInt returns the integer value of an address
+func addrToInt(addr netip.Addr) *big.Int {
+	if addr.Is4() {
+		b := addr.As4()
+		return new(big.Int).SetBytes(b[:])
+	}
+	b := addr.As16()
+	return new(big.Int).SetBytes(b[:])
+}
+
+// intToAddr returns the address of an integer value, IPv4 when is4 is set
+func intToAddr(n *big.Int, is4 bool) netip.Addr {
+	if is4 {
+		var b [4]byte
+		n.FillBytes(b[:])
+		return netip.AddrFrom4(b)
+	}
+	var b [16]byte
+	n.FillBytes(b[:])
+	return netip.AddrFrom16(b)
+}
+
+// parseAddr parses an address of the family of prefix; it reports false for anything else
+func parseAddr(s string, prefix netip.Prefix) (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	addr = addr.Unmap()
+	if addr.Is4() != prefix.Addr().Is4() {
+		return netip.Addr{}, false
+	}
+	return addr, true
+}
+
+// prefixRange returns every address of a prefix
+func prefixRange(prefix netip.Prefix) addrRange {
+	start := addrToInt(prefix.Masked().Addr())
+	hostBits := uint(prefix.Addr().BitLen() - prefix.Bits())
+	size := new(big.Int).Lsh(big.NewInt(1), hostBits)
+	end := new(big.Int).Sub(new(big.Int).Add(start, size), big.NewInt(1))
+	return addrRange{start: start, end: end}
+}
+
+// usableRange returns the addresses of a subnet that can be assigned: all but the network
+// address and, in IPv4, the broadcast address. Point-to-point prefixes (/31, /127) and single
+// addresses keep all their addresses.
+func usableRange(prefix netip.Prefix) addrRange {
+	r := prefixRange(prefix)
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostBits <= 1 {
+		return r
+	}
+	r.start = new(big.Int).Add(r.start, big.NewInt(1))
+	if prefix.Addr().Is4() {
+		r.end = new(big.Int).Sub(r.end, big.NewInt(1))
+	}
+	return r
+}
+
+// size returns the number of addresses of a range
+func (r addrRange) size() *big.Int {
+	n := new(big.Int).Sub(r.end, r.start)
+	return n.Add(n, big.NewInt(1))
+}
+
+// intersect returns the part of r inside bounds, and false when there is none
+func (r addrRange) intersect(bounds addrRange) (addrRange, bool) {
+	start, end := r.start, r.end
+	if start.Cmp(bounds.start) < 0 {
+		start = bounds.start
+	}
+	if end.Cmp(bounds.end) > 0 {
+		end = bounds.end
+	}
+	if start.Cmp(end) > 0 {
+		return addrRange{}, false
+	}
+	return addrRange{start: start, end: end}, true
+}
+
+// mergeRanges clips ranges to bounds and returns them sorted, with overlapping and adjacent
+// ranges merged
+func mergeRanges(ranges []addrRange, bounds addrRange) []addrRange {
+	clipped := make([]addrRange, 0, len(ranges))
+	for _, r := range ranges {
+		if r, ok := r.intersect(bounds); ok {
+			clipped = append(clipped, r)
+		}
+	}
+	sort.Slice(clipped, func(i, j int) bool {
+		return clipped[i].start.Cmp(clipped[j].start) < 0
+	})
+
+	merged := make([]addrRange, 0, len(clipped))
+	for _, r := range clipped {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			next := new(big.Int).Add(last.end, big.NewInt(1))
+			if r.start.Cmp(next) <= 0 {
+				if r.end.Cmp(last.end) > 0 {
+					last.end = r.end
+				}
+				continue
+			}
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// totalSize returns the number of addresses of merged ranges
+func totalSize(merged []addrRange) *big.Int {
+	total := new(big.Int)
+	for _, r := range merged {
+		total.Add(total, r.size())
+	}
+	return total
+}
+
+// gaps returns the parts of bounds that merged ranges do not cover, in order
+func gaps(merged []addrRange, bounds addrRange) []addrRange {
+	var free []addrRange
+	next := bounds.start
+	for _, r := range merged {
+		if r.start.Cmp(next) > 0 {
+			free = append(free, addrRange{start: next, end: new(big.Int).Sub(r.start, big.NewInt(1))})
+		}
+		next = new(big.Int).Add(r.end, big.NewInt(1))
+	}
+	if next.Cmp(bounds.end) <= 0 {
+		free = append(free, addrRange{start: next, end: bounds.end})
+	}
+	return free
+}
+
+// toUint64 converts a count of addresses, saturating at the largest uint64 for IPv6 subnets
+// wider than /64
+func toUint64(n *big.Int) uint64 {
+	if !n.IsUint64() {
+		return math.MaxUint64
+	}
+	return n.Uint64()
+}
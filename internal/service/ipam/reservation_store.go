@@ -0,0 +1,224 @@
+package ipam
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultReservationStoreFile is where the reservation audit is persisted when
+// MAAS_IP_RESERVATIONS_FILE is not set.
+const DefaultReservationStoreFile = "data/ip_reservations.json"
+
+// Reservation is an audit entry for an address this server reserved in MAAS
+type Reservation struct {
+	IP         string     `json:"ip"`
+	SubnetID   int        `json:"subnet_id"`
+	SubnetCIDR string     `json:"subnet_cidr"`
+	MAC        string     `json:"mac,omitempty"`
+	Hostname   string     `json:"hostname,omitempty"`
+	Comment    string     `json:"comment,omitempty"`
+	ReservedAt time.Time  `json:"reserved_at"`
+	ReleasedAt *time.Time `json:"released_at,omitempty"`
+}
+
+// Released reports whether the address has been released through this server
+func (r *Reservation) Released() bool {
+	return r.ReleasedAt != nil
+}
+
+// ReservationStore records the reservations this server made. MAAS does not record which
+// client reserved an address, so the server owns this data.
+type ReservationStore interface {
+	// Add records a new reservation
+	Add(reservation Reservation) error
+
+	// MarkReleased sets the release time of the unreleased reservations of an address and
+	// returns how many were marked
+	MarkReleased(ip string, at time.Time) (int, error)
+
+	// List returns every reservation in the order they were made
+	List() ([]Reservation, error)
+}
+
+// MemoryReservationStore implements ReservationStore using an in-memory slice
+type MemoryReservationStore struct {
+	reservations []Reservation
+	mu           sync.RWMutex
+}
+
+// NewMemoryReservationStore creates a new in-memory reservation store
+func NewMemoryReservationStore() *MemoryReservationStore {
+	return &MemoryReservationStore{}
+}
+
+// Add records a new reservation
+func (s *MemoryReservationStore) Add(reservation Reservation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reservations = append(s.reservations, reservation)
+	return nil
+}
+
+// MarkReleased sets the release time of the unreleased reservations of an address
+func (s *MemoryReservationStore) MarkReleased(ip string, at time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return markReleased(s.reservations, ip, at), nil
+}
+
+// List returns every reservation in the order they were made
+func (s *MemoryReservationStore) List() ([]Reservation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reservations := make([]Reservation, len(s.reservations))
+	copy(reservations, s.reservations)
+	return reservations, nil
+}
+
+// set replaces every reservation
+func (s *MemoryReservationStore) set(reservations []Reservation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reservations = reservations
+}
+
+// markReleased sets the release time of the unreleased reservations of an address in
+// reservations and returns how many were marked
+func markReleased(reservations []Reservation, ip string, at time.Time) int {
+	marked := 0
+	for i := range reservations {
+		if reservations[i].IP == ip && !reservations[i].Released() {
+			releasedAt := at
+			reservations[i].ReleasedAt = &releasedAt
+			marked++
+		}
+	}
+	return marked
+}
+
+// FileReservationStore implements ReservationStore using a JSON file
+type FileReservationStore struct {
+	filePath string
+	memory   *MemoryReservationStore
+	mu       sync.Mutex
+}
+
+// NewFileReservationStore creates a new file-based reservation store.
+// A missing file is not an error; it is created on the first write.
+func NewFileReservationStore(filePath string) (*FileReservationStore, error) {
+	store := &FileReservationStore{
+		filePath: filePath,
+		memory:   NewMemoryReservationStore(),
+	}
+
+	if err := store.load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load IP reservations from %s: %w", filePath, err)
+	}
+
+	return store, nil
+}
+
+// NewDefaultReservationStore opens the file-backed store at MAAS_IP_RESERVATIONS_FILE
+// or DefaultReservationStoreFile.
+func NewDefaultReservationStore() (*FileReservationStore, error) {
+	filePath := os.Getenv("MAAS_IP_RESERVATIONS_FILE")
+	if filePath == "" {
+		filePath = DefaultReservationStoreFile
+	}
+	return NewFileReservationStore(filePath)
+}
+
+// load reads reservations from the file
+func (s *FileReservationStore) load() error {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		return err
+	}
+
+	var reservations []Reservation
+	if err := json.Unmarshal(data, &reservations); err != nil {
+		return fmt.Errorf("failed to decode IP reservations: %w", err)
+	}
+
+	s.memory.set(reservations)
+	return nil
+}
+
+// save writes reservations to the file. It writes a temporary file and
+// renames it so a crash never leaves a truncated store behind.
+func (s *FileReservationStore) save(reservations []Reservation) error {
+	data, err := json.MarshalIndent(reservations, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode IP reservations: %w", err)
+	}
+
+	if dir := filepath.Dir(s.filePath); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.filePath), filepath.Base(s.filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write IP reservations: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write IP reservations: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), s.filePath)
+}
+
+// Add records a new reservation and persists the store. The reservation is only
+// kept once it has been written.
+func (s *FileReservationStore) Add(reservation Reservation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reservations, _ := s.memory.List()
+	reservations = append(reservations, reservation)
+	if err := s.save(reservations); err != nil {
+		return err
+	}
+
+	s.memory.set(reservations)
+	return nil
+}
+
+// MarkReleased sets the release time of the unreleased reservations of an address and
+// persists the store when any were marked. Nothing is marked unless it has been written.
+func (s *FileReservationStore) MarkReleased(ip string, at time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reservations, _ := s.memory.List()
+	marked := markReleased(reservations, ip, at)
+	if marked == 0 {
+		return 0, nil
+	}
+	if err := s.save(reservations); err != nil {
+		return 0, err
+	}
+
+	s.memory.set(reservations)
+	return marked, nil
+}
+
+// List returns every reservation in the order they were made
+func (s *FileReservationStore) List() ([]Reservation, error) {
+	return s.memory.List()
+}
@@ -0,0 +1,125 @@
+package ipam
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// ReservationClient is the part of the MAAS client that reserves and releases addresses
+type ReservationClient interface {
+	ReserveIPAddress(params *types.IPAddressReserveParams) (*types.IPAddress, error)
+	ReleaseIPAddress(ip string, force bool) error
+}
+
+// Release is the result of releasing an address
+type Release struct {
+	IP string `json:"ip"`
+	// Audited is true when the address was reserved through this server
+	Audited bool `json:"audited"`
+}
+
+// Reservations reserves and releases static addresses in MAAS and keeps an audit of the
+// reservations made through it
+type Reservations struct {
+	client ReservationClient
+	store  ReservationStore
+	logger *logrus.Logger
+	now    func() time.Time
+}
+
+// NewReservations creates a new reservation service that audits to store
+func NewReservations(client ReservationClient, store ReservationStore, logger *logrus.Logger) *Reservations {
+	return &Reservations{
+		client: client,
+		store:  store,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Reserve reserves an address in MAAS, optionally bound to a MAC address and with a hostname,
+// and records it in the audit with comment
+func (r *Reservations) Reserve(ctx context.Context, params types.IPAddressReserveParams, comment string) (*Reservation, error) {
+	if err := params.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	address, err := r.client.ReserveIPAddress(&params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve IP address: %w", err)
+	}
+
+	reservation := Reservation{
+		IP:         address.IP,
+		SubnetID:   address.SubnetID,
+		SubnetCIDR: address.SubnetCIDR,
+		MAC:        params.MAC,
+		Hostname:   params.Hostname,
+		Comment:    comment,
+		ReservedAt: r.now().UTC(),
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"ip":       reservation.IP,
+		"subnet":   reservation.SubnetCIDR,
+		"mac":      reservation.MAC,
+		"hostname": reservation.Hostname,
+	}).Info("Reserved IP address")
+
+	// The address is held in MAAS either way, so name it in the error
+	if err := r.store.Add(reservation); err != nil {
+		return nil, fmt.Errorf("reserved %s but failed to record it in the reservation audit: %w", reservation.IP, err)
+	}
+
+	return &reservation, nil
+}
+
+// Release releases an address in MAAS and marks its reservation released in the audit. Addresses
+// that were not reserved through this server can be released too.
+func (r *Reservations) Release(ctx context.Context, ip string, force bool) (*Release, error) {
+	if ip == "" {
+		return nil, fmt.Errorf("%w: IP address is required", ErrInvalidInput)
+	}
+
+	if err := r.client.ReleaseIPAddress(ip, force); err != nil {
+		return nil, fmt.Errorf("failed to release IP address %s: %w", ip, err)
+	}
+
+	marked, err := r.store.MarkReleased(ip, r.now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("released %s but failed to record it in the reservation audit: %w", ip, err)
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"ip":      ip,
+		"audited": marked > 0,
+	}).Info("Released IP address")
+
+	return &Release{IP: ip, Audited: marked > 0}, nil
+}
+
+// List returns the audited reservations, oldest first. Released reservations are left out
+// unless includeReleased is set.
+func (r *Reservations) List(ctx context.Context, includeReleased bool) ([]Reservation, error) {
+	reservations, err := r.store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the reservation audit: %w", err)
+	}
+
+	if includeReleased {
+		return reservations, nil
+	}
+
+	active := make([]Reservation, 0, len(reservations))
+	for _, reservation := range reservations {
+		if !reservation.Released() {
+			active = append(active, reservation)
+		}
+	}
+	return active, nil
+}
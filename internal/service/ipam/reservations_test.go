@@ -0,0 +1,165 @@
+package ipam
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// fakeReservationClient hands out .10, .11, ... of 10.0.0.0/24 and records releases
+type fakeReservationClient struct {
+	next     int
+	params   []types.IPAddressReserveParams
+	released []string
+	err      error
+}
+
+func (f *fakeReservationClient) ReserveIPAddress(params *types.IPAddressReserveParams) (*types.IPAddress, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.params = append(f.params, *params)
+	ip := params.IP
+	if ip == "" {
+		ip = fmt.Sprintf("10.0.0.%d", 10+f.next)
+		f.next++
+	}
+	return &types.IPAddress{IP: ip, SubnetID: 1, SubnetCIDR: "10.0.0.0/24"}, nil
+}
+
+func (f *fakeReservationClient) ReleaseIPAddress(ip string, force bool) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.released = append(f.released, ip)
+	return nil
+}
+
+func TestReservations(t *testing.T) {
+	client := &fakeReservationClient{}
+	reservations := NewReservations(client, NewMemoryReservationStore(), createTestLogger())
+	reservations.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	vip, err := reservations.Reserve(ctx, types.IPAddressReserveParams{
+		Subnet:   "10.0.0.0/24",
+		MAC:      "52:54:00:12:34:56",
+		Hostname: "lb-vip",
+	}, "VIP for lb-1")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.10", vip.IP)
+	assert.Equal(t, 1, vip.SubnetID)
+	assert.Equal(t, "52:54:00:12:34:56", vip.MAC)
+	assert.Equal(t, "lb-vip", vip.Hostname)
+	assert.Equal(t, "VIP for lb-1", vip.Comment)
+	assert.False(t, vip.Released())
+	assert.Equal(t, "52:54:00:12:34:56", client.params[0].MAC)
+
+	_, err = reservations.Reserve(ctx, types.IPAddressReserveParams{IP: "10.0.0.50"}, "")
+	require.NoError(t, err)
+
+	release, err := reservations.Release(ctx, "10.0.0.10", false)
+	require.NoError(t, err)
+	assert.True(t, release.Audited)
+	assert.Equal(t, []string{"10.0.0.10"}, client.released)
+
+	// Addresses reserved elsewhere are released but not audited
+	release, err = reservations.Release(ctx, "10.0.0.99", true)
+	require.NoError(t, err)
+	assert.False(t, release.Audited)
+
+	active, err := reservations.List(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, "10.0.0.50", active[0].IP)
+
+	all, err := reservations.List(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.True(t, all[0].Released())
+}
+
+func TestReservations_Errors(t *testing.T) {
+	client := &fakeReservationClient{}
+	reservations := NewReservations(client, NewMemoryReservationStore(), createTestLogger())
+	ctx := context.Background()
+
+	_, err := reservations.Reserve(ctx, types.IPAddressReserveParams{}, "")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, err = reservations.Reserve(ctx, types.IPAddressReserveParams{Subnet: "1", MAC: "not-a-mac"}, "")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.Empty(t, client.params)
+
+	_, err = reservations.Release(ctx, "", false)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	// Failed MAAS calls leave the audit alone
+	client.err = fmt.Errorf("409 CONFLICT")
+	_, err = reservations.Reserve(ctx, types.IPAddressReserveParams{IP: "10.0.0.10"}, "")
+	assert.Error(t, err)
+	all, err := reservations.List(ctx, true)
+	require.NoError(t, err)
+	assert.Empty(t, all)
+}
+
+func TestFileReservationStore(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "data", "ip_reservations.json")
+	reservedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	store, err := NewFileReservationStore(filePath)
+	require.NoError(t, err)
+	require.NoError(t, store.Add(Reservation{IP: "10.0.0.10", SubnetID: 1, ReservedAt: reservedAt}))
+	require.NoError(t, store.Add(Reservation{IP: "10.0.0.11", SubnetID: 1, ReservedAt: reservedAt}))
+
+	marked, err := store.MarkReleased("10.0.0.10", reservedAt.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, marked)
+
+	// A second release finds nothing left to mark
+	marked, err = store.MarkReleased("10.0.0.10", reservedAt.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, marked)
+
+	reopened, err := NewFileReservationStore(filePath)
+	require.NoError(t, err)
+	reservations, err := reopened.List()
+	require.NoError(t, err)
+	require.Len(t, reservations, 2)
+	require.NotNil(t, reservations[0].ReleasedAt)
+	assert.Equal(t, reservedAt.Add(time.Hour), *reservations[0].ReleasedAt)
+	assert.False(t, reservations[1].Released())
+}
+
+func TestFileReservationStore_SaveError(t *testing.T) {
+	dir := t.TempDir()
+	reservedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	store, err := NewFileReservationStore(filepath.Join(dir, "data", "ip_reservations.json"))
+	require.NoError(t, err)
+	require.NoError(t, store.Add(Reservation{IP: "10.0.0.10", SubnetID: 1, ReservedAt: reservedAt}))
+
+	// A file in place of the directory makes every save fail
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "data")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data"), nil, 0644))
+
+	assert.Error(t, store.Add(Reservation{IP: "10.0.0.11", SubnetID: 1, ReservedAt: reservedAt}))
+	marked, err := store.MarkReleased("10.0.0.10", reservedAt.Add(time.Hour))
+	assert.Error(t, err)
+	assert.Equal(t, 0, marked)
+
+	// The store holds only what was written
+	reservations, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, reservations, 1)
+	assert.Equal(t, "10.0.0.10", reservations[0].IP)
+	assert.False(t, reservations[0].Released())
+}
@@ -0,0 +1,345 @@
+package ipam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+const (
+	// DefaultFreeBlocks and MaxFreeBlocks bound the number of free blocks in a subnet's utilization
+	DefaultFreeBlocks = 5
+	MaxFreeBlocks     = 50
+
+	// MaxFreeIPs is the largest number of addresses FindFreeIPs returns
+	MaxFreeIPs = 256
+)
+
+var (
+	// ErrInvalidInput is wrapped by the errors of requests with invalid arguments
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrNotFound is wrapped by the errors of requests for addresses MAAS has no subnet for
+	ErrNotFound = errors.New("not found")
+)
+
+// Client is the part of the MAAS client that subnet utilization is computed from
+type Client interface {
+	ListSubnets() ([]types.Subnet, error)
+	GetSubnet(id int) (*types.Subnet, error)
+	GetSubnetStatistics(id int) (*types.SubnetStatistics, error)
+	GetSubnetIPAddresses(id int) ([]types.SubnetIPAddress, error)
+	ListIPRanges() ([]types.IPRange, error)
+}
+
+// IPBlock is a run of consecutive addresses
+type IPBlock struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Size  uint64 `json:"size"`
+}
+
+// SubnetUtilization is the address usage of a subnet. Every assignable address is counted once:
+// as used when MAAS assigned it or it is the gateway, otherwise as reserved when it is in a
+// reserved range, otherwise as dynamic when it is in a dynamic range, and as free when it is in
+// none of these. Counts of IPv6 subnets wider than /64 saturate at the largest uint64.
+type SubnetUtilization struct {
+	SubnetID          int                     `json:"subnet_id"`
+	CIDR              string                  `json:"cidr"`
+	Total             uint64                  `json:"total"`
+	Used              uint64                  `json:"used"`
+	Reserved          uint64                  `json:"reserved"`
+	Dynamic           uint64                  `json:"dynamic"`
+	Free              uint64                  `json:"free"`
+	UsagePercent      float64                 `json:"usage_percent"`
+	LargestFreeBlocks []IPBlock               `json:"largest_free_blocks"`
+	ReservedRanges    []types.IPRange         `json:"reserved_ranges"`
+	DynamicRanges     []types.IPRange         `json:"dynamic_ranges"`
+	Statistics        *types.SubnetStatistics `json:"maas_statistics,omitempty"`
+}
+
+// FreeIPs is the result of FindFreeIPs
+type FreeIPs struct {
+	CIDR           string   `json:"cidr"`
+	SubnetID       int      `json:"subnet_id"`
+	SubnetCIDR     string   `json:"subnet_cidr"`
+	Requested      int      `json:"requested"`
+	Addresses      []string `json:"addresses"`
+	ExcludeDynamic bool     `json:"exclude_dynamic"`
+}
+
+// Service computes the address utilization of subnets and finds unused addresses in them
+type Service struct {
+	client Client
+	logger *logrus.Logger
+}
+
+// NewService creates a new subnet utilization service
+func NewService(client Client, logger *logrus.Logger) *Service {
+	return &Service{
+		client: client,
+		logger: logger,
+	}
+}
+
+// subnetLayout holds the assignable addresses of a subnet and the addresses taken in it
+type subnetLayout struct {
+	subnet   *types.Subnet
+	prefix   netip.Prefix
+	usable   addrRange
+	used     []addrRange
+	reserved []addrRange
+	dynamic  []addrRange
+
+	reservedRanges []types.IPRange
+	dynamicRanges  []types.IPRange
+}
+
+// GetSubnetUtilization combines the statistics, IP ranges and assigned addresses of a subnet
+// into its used, reserved, dynamic and free address counts, with up to maxBlocks of its largest
+// free blocks
+func (s *Service) GetSubnetUtilization(ctx context.Context, subnetID int, maxBlocks int) (*SubnetUtilization, error) {
+	s.logger.WithFields(logrus.Fields{
+		"subnet_id":  subnetID,
+		"max_blocks": maxBlocks,
+	}).Debug("Getting subnet utilization")
+
+	if subnetID <= 0 {
+		return nil, fmt.Errorf("%w: valid subnet ID is required", ErrInvalidInput)
+	}
+	if maxBlocks <= 0 {
+		maxBlocks = DefaultFreeBlocks
+	} else if maxBlocks > MaxFreeBlocks {
+		maxBlocks = MaxFreeBlocks
+	}
+
+	subnet, err := s.client.GetSubnet(subnetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subnet %d: %w", subnetID, err)
+	}
+
+	layout, err := s.loadLayout(subnet)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.client.GetSubnetStatistics(subnetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statistics of subnet %d: %w", subnetID, err)
+	}
+
+	usedMerged := mergeRanges(layout.used, layout.usable)
+	takenMerged := mergeRanges(append(append([]addrRange{}, layout.used...), layout.reserved...), layout.usable)
+	allMerged := mergeRanges(append(append(append([]addrRange{}, layout.used...), layout.reserved...), layout.dynamic...), layout.usable)
+
+	total := layout.usable.size()
+	used := totalSize(usedMerged)
+	taken := totalSize(takenMerged)
+	all := totalSize(allMerged)
+
+	utilization := &SubnetUtilization{
+		SubnetID:       subnet.ID,
+		CIDR:           layout.prefix.String(),
+		Total:          toUint64(total),
+		Used:           toUint64(used),
+		Reserved:       toUint64(new(big.Int).Sub(taken, used)),
+		Dynamic:        toUint64(new(big.Int).Sub(all, taken)),
+		Free:           toUint64(new(big.Int).Sub(total, all)),
+		UsagePercent:   usagePercent(all, total),
+		ReservedRanges: layout.reservedRanges,
+		DynamicRanges:  layout.dynamicRanges,
+		Statistics:     stats,
+	}
+
+	free := gaps(allMerged, layout.usable)
+	sort.SliceStable(free, func(i, j int) bool {
+		return free[i].size().Cmp(free[j].size()) > 0
+	})
+	if len(free) > maxBlocks {
+		free = free[:maxBlocks]
+	}
+	utilization.LargestFreeBlocks = make([]IPBlock, len(free))
+	for i, block := range free {
+		utilization.LargestFreeBlocks[i] = layout.block(block)
+	}
+
+	return utilization, nil
+}
+
+// FindFreeIPs returns up to count addresses of cidr that are not assigned, not the gateway and
+// not in a reserved range, and with excludeDynamic not in a dynamic range either. cidr must lie
+// in a MAAS subnet; it can be the subnet itself or a part of it.
+func (s *Service) FindFreeIPs(ctx context.Context, cidr string, count int, excludeDynamic bool) (*FreeIPs, error) {
+	s.logger.WithFields(logrus.Fields{
+		"cidr":            cidr,
+		"count":           count,
+		"exclude_dynamic": excludeDynamic,
+	}).Debug("Finding free IP addresses")
+
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid CIDR %q: %w", ErrInvalidInput, cidr, err)
+	}
+	prefix = prefix.Masked()
+	if count <= 0 || count > MaxFreeIPs {
+		return nil, fmt.Errorf("%w: count must be between 1 and %d", ErrInvalidInput, MaxFreeIPs)
+	}
+
+	subnets, err := s.client.ListSubnets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subnets: %w", err)
+	}
+	subnet := containingSubnet(subnets, prefix)
+	if subnet == nil {
+		return nil, fmt.Errorf("%w: no MAAS subnet contains %s", ErrNotFound, prefix)
+	}
+
+	layout, err := s.loadLayout(subnet)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds, ok := layout.usable.intersect(prefixRange(prefix))
+	if !ok {
+		return nil, fmt.Errorf("%w: %s has no assignable addresses", ErrInvalidInput, prefix)
+	}
+
+	taken := append(append([]addrRange{}, layout.used...), layout.reserved...)
+	if excludeDynamic {
+		taken = append(taken, layout.dynamic...)
+	}
+
+	result := &FreeIPs{
+		CIDR:           prefix.String(),
+		SubnetID:       subnet.ID,
+		SubnetCIDR:     layout.prefix.String(),
+		Requested:      count,
+		Addresses:      make([]string, 0, count),
+		ExcludeDynamic: excludeDynamic,
+	}
+	is4 := prefix.Addr().Is4()
+	for _, block := range gaps(mergeRanges(taken, bounds), bounds) {
+		for n := new(big.Int).Set(block.start); n.Cmp(block.end) <= 0 && len(result.Addresses) < count; n.Add(n, big.NewInt(1)) {
+			result.Addresses = append(result.Addresses, intToAddr(n, is4).String())
+		}
+		if len(result.Addresses) == count {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// loadLayout reads the IP ranges and assigned addresses of a subnet
+func (s *Service) loadLayout(subnet *types.Subnet) (*subnetLayout, error) {
+	prefix, err := netip.ParsePrefix(subnet.CIDR)
+	if err != nil {
+		return nil, fmt.Errorf("subnet %d has an invalid CIDR %q: %w", subnet.ID, subnet.CIDR, err)
+	}
+	prefix = prefix.Masked()
+
+	ranges, err := s.client.ListIPRanges()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list IP ranges: %w", err)
+	}
+	addresses, err := s.client.GetSubnetIPAddresses(subnet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get IP addresses of subnet %d: %w", subnet.ID, err)
+	}
+
+	layout := &subnetLayout{
+		subnet:         subnet,
+		prefix:         prefix,
+		usable:         usableRange(prefix),
+		reservedRanges: []types.IPRange{},
+		dynamicRanges:  []types.IPRange{},
+	}
+
+	for _, address := range addresses {
+		if addr, ok := parseAddr(address.IP, prefix); ok {
+			n := addrToInt(addr)
+			layout.used = append(layout.used, addrRange{start: n, end: n})
+		}
+	}
+	if addr, ok := parseAddr(subnet.GatewayIP, prefix); ok {
+		n := addrToInt(addr)
+		layout.used = append(layout.used, addrRange{start: n, end: n})
+	}
+
+	for _, ipRange := range ranges {
+		if ipRange.SubnetID != subnet.ID {
+			continue
+		}
+		start, ok := parseAddr(ipRange.StartIP, prefix)
+		if !ok {
+			continue
+		}
+		end, ok := parseAddr(ipRange.EndIP, prefix)
+		if !ok {
+			continue
+		}
+		r := addrRange{start: addrToInt(start), end: addrToInt(end)}
+
+		switch ipRange.Type {
+		case types.IPRangeTypeReserved:
+			layout.reserved = append(layout.reserved, r)
+			layout.reservedRanges = append(layout.reservedRanges, ipRange)
+		case types.IPRangeTypeDynamic:
+			layout.dynamic = append(layout.dynamic, r)
+			layout.dynamicRanges = append(layout.dynamicRanges, ipRange)
+		}
+	}
+
+	return layout, nil
+}
+
+// block returns a range of addresses of the subnet as an IPBlock
+func (l *subnetLayout) block(r addrRange) IPBlock {
+	is4 := l.prefix.Addr().Is4()
+	return IPBlock{
+		Start: intToAddr(r.start, is4).String(),
+		End:   intToAddr(r.end, is4).String(),
+		Size:  toUint64(r.size()),
+	}
+}
+
+// containingSubnet returns the most specific subnet that contains prefix, or nil
+func containingSubnet(subnets []types.Subnet, prefix netip.Prefix) *types.Subnet {
+	var best *types.Subnet
+	bestBits := -1
+	for i := range subnets {
+		subnetPrefix, err := netip.ParsePrefix(subnets[i].CIDR)
+		if err != nil {
+			continue
+		}
+		subnetPrefix = subnetPrefix.Masked()
+		if subnetPrefix.Addr().Is4() != prefix.Addr().Is4() {
+			continue
+		}
+		if subnetPrefix.Bits() > prefix.Bits() || !subnetPrefix.Contains(prefix.Addr()) {
+			continue
+		}
+		if subnetPrefix.Bits() > bestBits {
+			best = &subnets[i]
+			bestBits = subnetPrefix.Bits()
+		}
+	}
+	return best
+}
+
+// usagePercent returns the share of total that is taken, rounded to two decimals
+func usagePercent(taken, total *big.Int) float64 {
+	if total.Sign() == 0 {
+		return 0
+	}
+	ratio, _ := new(big.Float).Quo(new(big.Float).SetInt(taken), new(big.Float).SetInt(total)).Float64()
+	return math.Round(ratio*10000) / 100
+}
@@ -0,0 +1,193 @@
+package ipam
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// fakeClient serves subnets with their IP ranges and assigned addresses
+type fakeClient struct {
+	subnets   []types.Subnet
+	ranges    []types.IPRange
+	addresses map[int][]types.SubnetIPAddress
+}
+
+func (f *fakeClient) ListSubnets() ([]types.Subnet, error) {
+	return f.subnets, nil
+}
+
+func (f *fakeClient) GetSubnet(id int) (*types.Subnet, error) {
+	for _, subnet := range f.subnets {
+		if subnet.ID == id {
+			return &subnet, nil
+		}
+	}
+	return nil, fmt.Errorf("subnet %d not found", id)
+}
+
+func (f *fakeClient) GetSubnetStatistics(id int) (*types.SubnetStatistics, error) {
+	return &types.SubnetStatistics{TotalAddresses: 254, IPVersion: 4}, nil
+}
+
+func (f *fakeClient) GetSubnetIPAddresses(id int) ([]types.SubnetIPAddress, error) {
+	return f.addresses[id], nil
+}
+
+func (f *fakeClient) ListIPRanges() ([]types.IPRange, error) {
+	return f.ranges, nil
+}
+
+// newFakeClient returns a /24 with the gateway at .1, a reserved range .2-.9, a dynamic range
+// .100-.199 and addresses assigned at .10, .11 and .150, plus an IPv6 subnet
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		subnets: []types.Subnet{
+			{ID: 1, CIDR: "10.0.0.0/24", GatewayIP: "10.0.0.1"},
+			{ID: 2, CIDR: "10.0.0.0/16"},
+			{ID: 3, CIDR: "2001:db8::/64", GatewayIP: "2001:db8::1"},
+		},
+		ranges: []types.IPRange{
+			{ID: 1, Type: types.IPRangeTypeReserved, StartIP: "10.0.0.2", EndIP: "10.0.0.9", SubnetID: 1},
+			{ID: 2, Type: types.IPRangeTypeDynamic, StartIP: "10.0.0.100", EndIP: "10.0.0.199", SubnetID: 1},
+			{ID: 3, Type: types.IPRangeTypeDynamic, StartIP: "10.0.1.1", EndIP: "10.0.1.200", SubnetID: 2},
+		},
+		addresses: map[int][]types.SubnetIPAddress{
+			1: {{IP: "10.0.0.10"}, {IP: "10.0.0.11"}, {IP: "10.0.0.150"}},
+			3: {{IP: "2001:db8::2"}},
+		},
+	}
+}
+
+func createTestLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.DebugLevel)
+	return logger
+}
+
+func TestGetSubnetUtilization(t *testing.T) {
+	service := NewService(newFakeClient(), createTestLogger())
+
+	utilization, err := service.GetSubnetUtilization(context.Background(), 1, 2)
+	require.NoError(t, err)
+
+	// .1 gateway, .10, .11 and .150 assigned
+	assert.Equal(t, uint64(254), utilization.Total)
+	assert.Equal(t, uint64(4), utilization.Used)
+	assert.Equal(t, uint64(8), utilization.Reserved)
+	// .150 is assigned, so it is not counted as dynamic
+	assert.Equal(t, uint64(99), utilization.Dynamic)
+	assert.Equal(t, uint64(143), utilization.Free)
+	assert.Equal(t, utilization.Total, utilization.Used+utilization.Reserved+utilization.Dynamic+utilization.Free)
+	assert.InDelta(t, 43.7, utilization.UsagePercent, 0.01)
+
+	assert.Equal(t, []IPBlock{
+		{Start: "10.0.0.12", End: "10.0.0.99", Size: 88},
+		{Start: "10.0.0.200", End: "10.0.0.254", Size: 55},
+	}, utilization.LargestFreeBlocks)
+	assert.Len(t, utilization.ReservedRanges, 1)
+	assert.Len(t, utilization.DynamicRanges, 1)
+	assert.NotNil(t, utilization.Statistics)
+}
+
+func TestGetSubnetUtilization_IPv6(t *testing.T) {
+	service := NewService(newFakeClient(), createTestLogger())
+
+	utilization, err := service.GetSubnetUtilization(context.Background(), 3, 0)
+	require.NoError(t, err)
+
+	// Every address but the network address: 2^64 - 1
+	assert.Equal(t, uint64(1<<64-1), utilization.Total)
+	assert.Equal(t, uint64(2), utilization.Used)
+	assert.Equal(t, "2001:db8::3", utilization.LargestFreeBlocks[0].Start)
+}
+
+func TestGetSubnetUtilization_Errors(t *testing.T) {
+	service := NewService(newFakeClient(), createTestLogger())
+
+	_, err := service.GetSubnetUtilization(context.Background(), 0, 0)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, err = service.GetSubnetUtilization(context.Background(), 42, 0)
+	assert.Error(t, err)
+}
+
+func TestFindFreeIPs(t *testing.T) {
+	service := NewService(newFakeClient(), createTestLogger())
+
+	tests := []struct {
+		name           string
+		cidr           string
+		count          int
+		excludeDynamic bool
+		wantSubnet     int
+		want           []string
+	}{
+		{
+			name:       "Most specific subnet, skipping gateway, reserved and assigned addresses",
+			cidr:       "10.0.0.0/24",
+			count:      3,
+			wantSubnet: 1,
+			want:       []string{"10.0.0.12", "10.0.0.13", "10.0.0.14"},
+		},
+		{
+			name:       "Dynamic range included",
+			cidr:       "10.0.0.144/29",
+			count:      8,
+			wantSubnet: 1,
+			want:       []string{"10.0.0.144", "10.0.0.145", "10.0.0.146", "10.0.0.147", "10.0.0.148", "10.0.0.149", "10.0.0.151"},
+		},
+		{
+			name:           "Dynamic range excluded",
+			cidr:           "10.0.0.96/27",
+			count:          5,
+			excludeDynamic: true,
+			wantSubnet:     1,
+			want:           []string{"10.0.0.96", "10.0.0.97", "10.0.0.98", "10.0.0.99"},
+		},
+		{
+			name:           "Part of a wider subnet, where .0 is a host address",
+			cidr:           "10.0.1.0/24",
+			count:          2,
+			excludeDynamic: true,
+			wantSubnet:     2,
+			want:           []string{"10.0.1.0", "10.0.1.201"},
+		},
+		{
+			name:       "IPv6",
+			cidr:       "2001:db8::/120",
+			count:      2,
+			wantSubnet: 3,
+			want:       []string{"2001:db8::3", "2001:db8::4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.FindFreeIPs(context.Background(), tt.cidr, tt.count, tt.excludeDynamic)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSubnet, result.SubnetID)
+			assert.Equal(t, tt.want, result.Addresses)
+			assert.Equal(t, tt.count, result.Requested)
+		})
+	}
+}
+
+func TestFindFreeIPs_Errors(t *testing.T) {
+	service := NewService(newFakeClient(), createTestLogger())
+
+	_, err := service.FindFreeIPs(context.Background(), "not-a-cidr", 1, false)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, err = service.FindFreeIPs(context.Background(), "10.0.0.0/24", 0, false)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, err = service.FindFreeIPs(context.Background(), "192.168.0.0/24", 1, false)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
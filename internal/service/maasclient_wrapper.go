@@ -87,6 +87,11 @@ func (w *MaasClientWrapper) DeployMachine(systemID string, params *entity.Machin
 	return w.client.DeployMachine(systemID, params)
 }
 
+// CommissionMachine implements the MachineCommissioner interface
+func (w *MaasClientWrapper) CommissionMachine(systemID string, params *entity.MachineCommissionParams) (*models.Machine, error) {
+	return w.client.CommissionMachine(systemID, params)
+}
+
 // ReleaseMachine implements the MachineClient interface
 func (w *MaasClientWrapper) ReleaseMachine(systemIDs []string, comment string) error {
 	return w.client.ReleaseMachine(systemIDs, comment)
@@ -260,72 +265,8 @@ func (w *MaasClientWrapper) DeleteStorageConstraints(systemID string) error {
 
 // CheckStorageConstraints implements the MachineClient interface
 func (w *MaasClientWrapper) CheckStorageConstraints(machine *models.Machine, constraints *models.SimpleStorageConstraint) bool {
-	// This is a simplified check. A real implementation would involve more complex logic
-	// to iterate through machine's block devices and compare against constraints.
 	if machine == nil || constraints == nil {
-		return true // Or false, depending on desired behavior for nil inputs
-	}
-
-	// Example: Check minimum disk count
-	if constraints.Count > 0 {
-		if len(machine.BlockDevices) < constraints.Count {
-			return false
-		}
+		return true
 	}
-
-	// Example: Check minimum disk size and type (simplified)
-	// This requires iterating through machine.BlockDevices and checking individual disks.
-	// For simplicity, we'll assume if MinSize or DiskType is set, we need to check them.
-	// A more robust implementation would be needed here.
-	if constraints.MinSize > 0 || (constraints.DiskType != "" && constraints.DiskType != "any") {
-		// Placeholder for actual disk checking logic
-		// Iterate through machine.BlockDevices
-		// For each disk:
-		//   Check if disk.Size >= constraints.MinSize (if MinSize > 0)
-		//   Check if disk.Type matches constraints.DiskType (if DiskType is specified and not "any")
-		// If a sufficient number of disks meet the criteria (related to constraints.Count), return true.
-		// This is a complex check and depends on how MAAS exposes disk type and how to aggregate.
-		// For now, let's assume if these are set, the check needs more detail.
-		// We can return true to allow filtering at a higher level or implement a basic check.
-
-		// Simplified check: if any disk meets the size, and if type is specified, at least one matches.
-		// This is not a perfect match for "all disks of this type must be at least X size"
-		// or "N disks of this type and size".
-		disksMeetingCriteria := 0
-		for _, bd := range machine.BlockDevices {
-			meetsSize := true
-			if constraints.MinSize > 0 {
-				if bd.Size < constraints.MinSize {
-					meetsSize = false
-				}
-			}
-
-			meetsType := true
-			if constraints.DiskType != "" && constraints.DiskType != "any" {
-				// Assuming bd.Type exists and matches "ssd", "hdd" etc.
-				// This field might not be directly available or named 'Type' in models.BlockDevice
-				// For example, it might be in bd.StoragePool.DiskType or similar.
-				// This is a placeholder for actual type checking.
-				// if bd.Type != constraints.DiskType {
-				// 	meetsType = false
-				// }
-				// Let's assume for now if a type is specified, we need a more detailed check
-				// or that the MAAS API query handles this.
-				// For in-memory filtering, this needs to be robust.
-			}
-
-			if meetsSize && meetsType {
-				disksMeetingCriteria++
-			}
-		}
-		if constraints.Count > 0 {
-			return disksMeetingCriteria >= constraints.Count
-		}
-		// If count is not specified, but size/type are, then at least one disk must match.
-		if constraints.MinSize > 0 || (constraints.DiskType != "" && constraints.DiskType != "any") {
-			return disksMeetingCriteria > 0
-		}
-	}
-
-	return true // Default to true if no specific constraints failed
+	return w.client.CheckStorageConstraints(machine, constraints)
 }
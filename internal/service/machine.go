@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/canonical/gomaasclient/entity"
 	"github.com/sirupsen/logrus"
 
 	"github.com/lspecian/maas-mcp-server/internal/models"
 	"github.com/lspecian/maas-mcp-server/internal/models/maas"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
 )
 
 // MachineService handles machine management operations
@@ -28,10 +30,21 @@ func NewMachineService(client MachineClient, logger *logrus.Logger) *MachineServ
 }
 
 // ListMachinesPaginated retrieves a list of machines with optional filtering and pagination.
+// Storage constraints are read from the min_disk_size, disk_type and min_disk_count filters.
 func (s *MachineService) ListMachinesPaginated(ctx context.Context, filters map[string]string, pagination *models.PaginationOptions) (*models.PaginatedMachines, error) {
+	return s.ListMachinesWithStorage(ctx, filters, pagination, nil)
+}
+
+// ListMachinesWithStorage retrieves a paginated list of machines whose disks satisfy every storage
+// requirement, each requirement using its own disks. Without requirements, they are read from the
+// storage filters. Requirements are checked as the constraints MachineClient takes, see
+// machineStorageConstraints. When the client can explain its decisions, a match report for every
+// evaluated machine is returned in StorageReports.
+func (s *MachineService) ListMachinesWithStorage(ctx context.Context, filters map[string]string, pagination *models.PaginationOptions, requirements []models.SimpleStorageConstraint) (*models.PaginatedMachines, error) {
 	s.logger.WithFields(logrus.Fields{
-		"filters":    filters,
-		"pagination": pagination,
+		"filters":      filters,
+		"pagination":   pagination,
+		"requirements": requirements,
 	}).Debug("Listing machines with pagination")
 
 	if err := validateFilters(filters); err != nil {
@@ -58,36 +71,21 @@ func (s *MachineService) ListMachinesPaginated(ctx context.Context, filters map[
 		return nil, mapClientError(err)
 	}
 
+	constraints := machineStorageConstraints(requirements)
+	if len(constraints) == 0 {
+		constraints = storageRequirementsFromFilters(filters)
+	}
+
+	// Storage constraints are checked in-service after fetching the paginated list from MAAS
 	machineContexts := make([]models.MachineContext, 0, len(machines))
+	var reports []models.StorageMatchReport
 	for _, m := range machines {
-		// Apply storage constraint filtering if constraints are present in filters
-		// This filtering is done in-service after fetching the paginated list from MAAS.
-		var storageConstraints *models.SimpleStorageConstraint
-		if minSizeStr, ok := filters["min_disk_size"]; ok {
-			if storageConstraints == nil {
-				storageConstraints = &models.SimpleStorageConstraint{}
-			}
-			minSize, _ := parseInt64(minSizeStr)
-			storageConstraints.MinSize = minSize
-		}
-		if diskType, ok := filters["disk_type"]; ok {
-			if storageConstraints == nil {
-				storageConstraints = &models.SimpleStorageConstraint{}
-			}
-			storageConstraints.DiskType = diskType
+		matched, report := s.matchStorageRequirements(&m, constraints)
+		if report != nil {
+			reports = append(reports, *report)
 		}
-		if minDiskCountStr, ok := filters["min_disk_count"]; ok {
-			if storageConstraints == nil {
-				storageConstraints = &models.SimpleStorageConstraint{}
-			}
-			minDiskCount, _ := parseInt(minDiskCountStr)
-			storageConstraints.Count = minDiskCount
-		}
-
-		if storageConstraints != nil {
-			if !s.maasClient.CheckStorageConstraints(&m, storageConstraints) {
-				continue
-			}
+		if !matched {
+			continue
 		}
 		machineContext := models.MaasMachineToMCPContext(&m)
 		machineContexts = append(machineContexts, *machineContext)
@@ -97,8 +95,9 @@ func (s *MachineService) ListMachinesPaginated(ctx context.Context, filters map[
 	// The returned PaginatedMachines.Machines will be the filtered list for the current page.
 	// PaginatedMachines.TotalCount will reflect the MAAS total for pagination calculation.
 	result := &models.PaginatedMachines{
-		Machines:   machineContexts,
-		TotalCount: totalCount,
+		Machines:       machineContexts,
+		TotalCount:     totalCount,
+		StorageReports: reports,
 	}
 
 	if pagination != nil {
@@ -149,38 +148,10 @@ func (s *MachineService) ListMachines(ctx context.Context, filters map[string]st
 
 	// Convert MAAS machines to MCP context and apply in-memory storage constraint filtering
 	machineContexts := make([]models.MachineContext, 0, len(machines))
+	requirements := storageRequirementsFromFilters(filters)
 	for _, m := range machines {
-		// Placeholder: Parse storage constraints from filters
-		// This logic was previously in the paginated ListMachines and is kept here for consistency
-		// if MAAS API doesn't directly support these filters for ListMachinesSimple.
-		var storageConstraints *models.SimpleStorageConstraint
-		if minSizeStr, ok := filters["min_disk_size"]; ok {
-			if storageConstraints == nil {
-				storageConstraints = &models.SimpleStorageConstraint{}
-			}
-			minSize, _ := parseInt64(minSizeStr)
-			storageConstraints.MinSize = minSize
-		}
-		if diskType, ok := filters["disk_type"]; ok {
-			if storageConstraints == nil {
-				storageConstraints = &models.SimpleStorageConstraint{}
-			}
-			storageConstraints.DiskType = diskType
-		}
-		if minDiskCountStr, ok := filters["min_disk_count"]; ok {
-			if storageConstraints == nil {
-				storageConstraints = &models.SimpleStorageConstraint{}
-			}
-			minDiskCount, _ := parseInt(minDiskCountStr)
-			storageConstraints.Count = minDiskCount
-		}
-
-		// If storage constraints are defined, check if the machine meets them
-		if storageConstraints != nil {
-			// Assuming CheckStorageConstraints can work with models.Machine from ListMachinesSimple.
-			if !s.maasClient.CheckStorageConstraints(&m, storageConstraints) {
-				continue // Skip this machine if it doesn't meet constraints
-			}
+		if matched, _ := s.matchStorageRequirements(&m, requirements); !matched {
+			continue
 		}
 
 		machineContext := models.MaasMachineToMCPContext(&m)
@@ -241,22 +212,123 @@ func (s *MachineService) DiscoverMachines(ctx context.Context, options *models.M
 
 	// Create discovery result
 	result := &models.MachineDiscoveryResult{
-		DiscoveredCount: len(beforeMachines),
-		Status:          "completed",
+		Status: "completed",
+	}
+
+	var requirements []types.SimpleStorageConstraint
+	if options != nil {
+		requirements = machineStorageConstraints(options.StorageConstraints)
 	}
 
-	// Convert MAAS machines to MCP context for the result
-	discoveredMachines := make([]models.MachineContext, len(beforeMachines))
-	for i, m := range beforeMachines {
+	// Convert MAAS machines to MCP context for the result, keeping those that meet the storage constraints
+	discoveredMachines := make([]models.MachineContext, 0, len(beforeMachines))
+	for _, m := range beforeMachines {
+		matched, report := s.matchStorageRequirements(&m, requirements)
+		if report != nil {
+			result.StorageReports = append(result.StorageReports, *report)
+		}
+		if !matched {
+			continue
+		}
 		machineContext := models.MaasMachineToMCPContext(&m)
-		discoveredMachines[i] = *machineContext
+		discoveredMachines = append(discoveredMachines, *machineContext)
 	}
 	result.DiscoveredMachines = discoveredMachines
+	result.DiscoveredCount = len(discoveredMachines)
 
 	s.logger.WithField("discoveredCount", result.DiscoveredCount).Info("Machine discovery completed")
 	return result, nil
 }
 
+// matchStorageRequirements reports whether the machine's disks satisfy all requirements. The report
+// is only returned by clients implementing StorageConstraintEvaluator; for other clients each
+// requirement is checked on its own, so two requirements may be satisfied by the same disk.
+func (s *MachineService) matchStorageRequirements(machine *types.Machine, requirements []types.SimpleStorageConstraint) (bool, *models.StorageMatchReport) {
+	if len(requirements) == 0 {
+		return true, nil
+	}
+
+	if evaluator, ok := s.maasClient.(StorageConstraintEvaluator); ok {
+		report := evaluator.EvaluateStorageConstraints(machine, requirements)
+		return report.Matched, report
+	}
+
+	for i := range requirements {
+		if !s.maasClient.CheckStorageConstraints(machine, &requirements[i]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// storageRequirementsFromFilters builds a storage requirement from the min_disk_size, disk_type
+// and min_disk_count filters, or returns nil when none is set.
+func storageRequirementsFromFilters(filters map[string]string) []types.SimpleStorageConstraint {
+	var storageConstraints *types.SimpleStorageConstraint
+	if minSizeStr, ok := filters["min_disk_size"]; ok {
+		if storageConstraints == nil {
+			storageConstraints = &types.SimpleStorageConstraint{}
+		}
+		minSize, _ := parseInt64(minSizeStr)
+		storageConstraints.MinSize = minSize
+	}
+	if diskType, ok := filters["disk_type"]; ok {
+		if storageConstraints == nil {
+			storageConstraints = &types.SimpleStorageConstraint{}
+		}
+		storageConstraints.Tags = diskTypeTag(diskType)
+	}
+	if minDiskCountStr, ok := filters["min_disk_count"]; ok {
+		if storageConstraints == nil {
+			storageConstraints = &types.SimpleStorageConstraint{}
+		}
+		minDiskCount, _ := parseInt(minDiskCountStr)
+		storageConstraints.Count = minDiskCount
+	}
+
+	if storageConstraints == nil {
+		return nil
+	}
+	return []types.SimpleStorageConstraint{*storageConstraints}
+}
+
+// machineStorageConstraints converts storage requirements into the constraints MachineClient
+// checks. The disk type becomes the tag MAAS sets on such disks; the other fields that only
+// models.SimpleStorageConstraint has, such as the path or model, are not checked.
+func machineStorageConstraints(requirements []models.SimpleStorageConstraint) []types.SimpleStorageConstraint {
+	if len(requirements) == 0 {
+		return nil
+	}
+
+	constraints := make([]types.SimpleStorageConstraint, len(requirements))
+	for i, requirement := range requirements {
+		tags := append([]string{}, requirement.Tags...)
+		if tag := diskTypeTag(requirement.DiskType); tag != "" {
+			tags = append(tags, tag)
+		}
+		constraints[i] = types.SimpleStorageConstraint{
+			MinSize: requirement.MinSize,
+			MaxSize: requirement.MaxSize,
+			Count:   requirement.Count,
+			Tags:    strings.Join(tags, ","),
+		}
+	}
+	return constraints
+}
+
+// diskTypeTag returns the tag MAAS sets on disks of the given type during commissioning: ssd or
+// rotary for hdd. Other types are matched as a tag of the same name, and "any" as no tag.
+func diskTypeTag(diskType string) string {
+	switch diskType = strings.ToLower(diskType); diskType {
+	case "", "any":
+		return ""
+	case "hdd":
+		return "rotary"
+	default:
+		return diskType
+	}
+}
+
 // GetMachinePowerState retrieves the power state of a specific machine
 func (s *MachineService) GetMachinePowerState(ctx context.Context, id string) (string, error) {
 	s.logger.WithField("id", id).Debug("Getting machine power state")
@@ -292,7 +364,7 @@ func (s *MachineService) AllocateMachine(ctx context.Context, constraints map[st
 	// Validate constraints
 	if err := validateConstraints(constraints); err != nil {
 		return nil, &ServiceError{
-			Err:        ErrBadRequest,
+			Err:        fmt.Errorf("%w: %w", ErrBadRequest, err),
 			StatusCode: http.StatusBadRequest,
 			Message:    fmt.Sprintf("Invalid constraints: %v", err),
 		}
@@ -365,6 +437,58 @@ func (s *MachineService) DeployMachine(ctx context.Context, id string, osConfig
 	return result, nil
 }
 
+// CommissionMachine starts commissioning a machine
+func (s *MachineService) CommissionMachine(ctx context.Context, id string, options map[string]string) (*models.MachineContext, error) {
+	s.logger.WithFields(logrus.Fields{
+		"id":      id,
+		"options": options,
+	}).Debug("Commissioning machine")
+
+	// Validate ID
+	if id == "" {
+		return nil, &ServiceError{
+			Err:        ErrBadRequest,
+			StatusCode: http.StatusBadRequest,
+			Message:    "Machine ID is required",
+		}
+	}
+
+	commissioner, ok := s.maasClient.(MachineCommissioner)
+	if !ok {
+		return nil, &ServiceError{
+			Err:        ErrNotImplemented,
+			StatusCode: http.StatusNotImplemented,
+			Message:    "Commissioning is not supported by the MAAS client",
+		}
+	}
+
+	params, err := convertCommissionOptionsToParams(options)
+	if err != nil {
+		return nil, &ServiceError{
+			Err:        ErrBadRequest,
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("Invalid commissioning options: %v", err),
+		}
+	}
+
+	// Call MAAS client to commission machine
+	machine, err := commissioner.CommissionMachine(id, params)
+	if err != nil {
+		s.logger.WithError(err).WithField("id", id).Error("Failed to commission machine in MAAS")
+		return nil, mapClientError(err)
+	}
+
+	// Convert MAAS machine to MCP context
+	result := models.MaasMachineToMCPContext(machine)
+
+	s.logger.WithFields(logrus.Fields{
+		"id":   machine.SystemID,
+		"name": machine.Hostname,
+	}).Info("Successfully started machine commissioning")
+
+	return result, nil
+}
+
 // ReleaseMachine releases a machine back to the available pool
 func (s *MachineService) ReleaseMachine(ctx context.Context, id string, comment string) error {
 	s.logger.WithFields(logrus.Fields{
@@ -465,8 +589,19 @@ func validateFilters(filters map[string]string) error {
 
 // validateConstraints validates machine allocation constraints
 func validateConstraints(constraints map[string]string) error {
-	// Implement validation logic for constraints
-	// For now, accept all constraints as valid
+	// The storage constraint uses the MAAS label:size(tags) syntax; errors are *models.StorageSyntaxError
+	if storage, ok := constraints["storage"]; ok {
+		if _, err := models.ParseMAASStorageConstraints(storage); err != nil {
+			return err
+		}
+	}
+	for _, key := range []string{"cpu_count", "mem"} {
+		if value, ok := constraints[key]; ok {
+			if _, err := parseInt64(value); err != nil {
+				return fmt.Errorf("%s must be an integer: %s", key, value)
+			}
+		}
+	}
 	return nil
 }
 
@@ -503,6 +638,19 @@ func convertConstraintsToParams(constraints map[string]string) *entity.MachineAl
 		params.Tags = []string{tags}
 	}
 
+	if cpuCount, ok := constraints["cpu_count"]; ok {
+		params.CPUCount, _ = parseInt(cpuCount)
+	}
+
+	if mem, ok := constraints["mem"]; ok {
+		params.Mem, _ = parseInt64(mem)
+	}
+
+	if storage, ok := constraints["storage"]; ok {
+		// MAAS takes the whole label:size(tags) list as a single storage value
+		params.Storage = []string{storage}
+	}
+
 	// Add more mappings as needed
 
 	return params
@@ -530,6 +678,37 @@ func convertOSConfigToParams(osConfig map[string]string) *entity.MachineDeployPa
 	return params
 }
 
+// convertCommissionOptionsToParams converts commissioning options to MAAS commissioning parameters
+func convertCommissionOptionsToParams(options map[string]string) (*entity.MachineCommissionParams, error) {
+	params := &entity.MachineCommissionParams{
+		CommissioningScripts: options["commissioning_scripts"],
+		TestingScripts:       options["testing_scripts"],
+	}
+
+	// MAAS takes the boolean options as 0 or 1
+	flags := map[string]*int{
+		"enable_ssh":      &params.EnableSSH,
+		"skip_bmc_config": &params.SkipBMCConfig,
+		"skip_networking": &params.SkipNetworking,
+		"skip_storage":    &params.SkipStorage,
+	}
+	for name, target := range flags {
+		value, ok := options[name]
+		if !ok || value == "" {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a boolean, got %q", name, value)
+		}
+		if enabled {
+			*target = 1
+		}
+	}
+
+	return params, nil
+}
+
 // parseInt64 is a helper function to parse a string to int64
 func parseInt64(s string) (int64, error) {
 	return strconv.ParseInt(s, 10, 64)
@@ -10,6 +10,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/lspecian/maas-mcp-server/internal/conversion"
+	"github.com/lspecian/maas-mcp-server/internal/models"
 	"github.com/lspecian/maas-mcp-server/internal/models/types"
 	"github.com/lspecian/maas-mcp-server/internal/repository/machine"
 )
@@ -22,6 +23,7 @@ var (
 	ErrServiceUnavailable = errors.New("service unavailable")
 	ErrConflict           = errors.New("resource conflict")
 	ErrForbidden          = errors.New("operation not permitted")
+	ErrNotImplemented     = errors.New("operation not supported")
 )
 
 // ServiceError represents an error with HTTP status code mapping
@@ -271,6 +273,81 @@ func (s *Service) ReleaseMachine(ctx context.Context, id string, comment string)
 	return nil
 }
 
+// AbortMachineOperation aborts the current operation on a machine, such as a deployment
+func (s *Service) AbortMachineOperation(ctx context.Context, id string, comment string) (*types.MachineContext, error) {
+	s.logger.WithFields(logrus.Fields{
+		"id":      id,
+		"comment": comment,
+	}).Debug("Aborting machine operation")
+
+	// Validate ID
+	if id == "" {
+		return nil, &ServiceError{
+			Err:        ErrBadRequest,
+			StatusCode: http.StatusBadRequest,
+			Message:    "Machine ID is required",
+		}
+	}
+
+	aborter, ok := s.repository.(machine.OperationAborter)
+	if !ok {
+		return nil, &ServiceError{
+			Err:        ErrNotImplemented,
+			StatusCode: http.StatusNotImplemented,
+			Message:    "Aborting machine operations is not supported by the repository",
+		}
+	}
+
+	// Call repository to abort the operation
+	m, err := aborter.AbortMachineOperation(ctx, id, comment)
+	if err != nil {
+		s.logger.WithError(err).WithField("id", id).Error("Failed to abort machine operation in repository")
+		return nil, mapRepositoryError(err)
+	}
+
+	// Use the conversion function to create a MachineContext from the Machine
+	result := conversion.MaasMachineToMCPContext(m, m.SystemID, s.logger, nil)
+
+	s.logger.WithField("id", id).Info("Successfully aborted machine operation")
+	return result, nil
+}
+
+// ListMachineEvents retrieves at most limit of a machine's most recent MAAS events, newest first
+func (s *Service) ListMachineEvents(ctx context.Context, id string, limit int) ([]types.MachineEvent, error) {
+	s.logger.WithFields(logrus.Fields{
+		"id":    id,
+		"limit": limit,
+	}).Debug("Listing machine events")
+
+	// Validate ID
+	if id == "" {
+		return nil, &ServiceError{
+			Err:        ErrBadRequest,
+			StatusCode: http.StatusBadRequest,
+			Message:    "Machine ID is required",
+		}
+	}
+
+	lister, ok := s.repository.(machine.EventLister)
+	if !ok {
+		return nil, &ServiceError{
+			Err:        ErrNotImplemented,
+			StatusCode: http.StatusNotImplemented,
+			Message:    "Listing machine events is not supported by the repository",
+		}
+	}
+
+	// Call repository to list events
+	events, err := lister.ListMachineEvents(ctx, id, limit)
+	if err != nil {
+		s.logger.WithError(err).WithField("id", id).Error("Failed to list machine events from repository")
+		return nil, mapRepositoryError(err)
+	}
+
+	s.logger.WithField("count", len(events)).Debug("Successfully retrieved machine events")
+	return events, nil
+}
+
 // PowerOnMachine powers on a machine
 func (s *Service) PowerOnMachine(ctx context.Context, id string) (*types.MachineContext, error) {
 	s.logger.WithField("id", id).Debug("Powering on machine")
@@ -344,8 +421,12 @@ func validateFilters(filters map[string]string) error {
 
 // validateConstraints validates machine allocation constraints
 func validateConstraints(constraints map[string]string) error {
-	// Implement validation logic for constraints
-	// For now, accept all constraints as valid
+	// The storage constraint uses the MAAS label:size(tags) syntax; errors are *models.StorageSyntaxError
+	if storage, ok := constraints["storage"]; ok {
+		if _, err := models.ParseMAASStorageConstraints(storage); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -382,6 +463,11 @@ func convertConstraintsToParams(constraints map[string]string) *entity.MachineAl
 		params.Tags = []string{tags}
 	}
 
+	if storage, ok := constraints["storage"]; ok {
+		// MAAS takes the whole label:size(tags) list as a single storage value
+		params.Storage = []string{storage}
+	}
+
 	// Add more mappings as needed
 
 	return params
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/canonical/gomaasclient/entity"
+	"github.com/lspecian/maas-mcp-server/internal/models"
 	"github.com/lspecian/maas-mcp-server/internal/models/types"
 )
 
@@ -39,3 +40,16 @@ type MachineClient interface {
 	// CheckStorageConstraints checks if a machine meets the specified storage constraints
 	CheckStorageConstraints(machine *types.Machine, constraints *types.SimpleStorageConstraint) bool
 }
+
+// MachineCommissioner is implemented by machine clients that can start commissioning a machine.
+type MachineCommissioner interface {
+	// CommissionMachine starts commissioning a machine
+	CommissionMachine(systemID string, params *entity.MachineCommissionParams) (*types.Machine, error)
+}
+
+// StorageConstraintEvaluator is implemented by machine clients that can explain a storage
+// constraint match. MachineService uses it, when available, to attach per-machine match reports.
+type StorageConstraintEvaluator interface {
+	// EvaluateStorageConstraints evaluates each constraint as a separate disk requirement
+	EvaluateStorageConstraints(machine *types.Machine, constraints []types.SimpleStorageConstraint) *models.StorageMatchReport
+}
@@ -1,7 +1,6 @@
 package progress
 
 import (
-	"container/ring"
 	"sync"
 
 	"github.com/lspecian/maas-mcp-server/internal/transport/mcp/events"
@@ -11,18 +10,18 @@ import (
 // It provides thread-safe access to the events and supports retrieving events
 // after a specific event ID.
 type EventBuffer struct {
-	// buffer is a circular buffer that stores events
-	buffer *ring.Ring
+	// events holds the stored events; once full, the oldest is overwritten
+	events []events.Event
+
+	// start is the index of the oldest event and count the number of events stored
+	start int
+	count int
 
 	// capacity is the maximum number of events the buffer can store
 	capacity int
 
 	// mutex protects access to the buffer
 	mutex sync.RWMutex
-
-	// eventIDMap maps event IDs to their position in the buffer
-	// This allows for efficient retrieval of events after a specific ID
-	eventIDMap map[string]int
 }
 
 // NewEventBuffer creates a new EventBuffer with the specified capacity
@@ -32,115 +31,56 @@ func NewEventBuffer(capacity int) *EventBuffer {
 	}
 
 	return &EventBuffer{
-		buffer:     ring.New(capacity),
-		capacity:   capacity,
-		eventIDMap: make(map[string]int),
+		events:   make([]events.Event, capacity),
+		capacity: capacity,
 	}
 }
 
-// Add adds an event to the buffer
+// Add adds an event to the buffer, replacing the oldest event when the buffer is full
 func (b *EventBuffer) Add(event events.Event) {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
-	// Store the event in the buffer
-	b.buffer.Value = event
-
-	// If the event has an ID, store its position in the map
-	if id := event.ID(); id != "" {
-		// Calculate the current position in the buffer
-		position := 0
-		current := b.buffer
-		for i := 0; i < b.capacity; i++ {
-			if current == b.buffer {
-				position = i
-				break
-			}
-			current = current.Next()
-		}
-		b.eventIDMap[id] = position
-	}
-
-	// Move to the next position in the buffer
-	b.buffer = b.buffer.Next()
-
-	// Clean up old event IDs that are no longer in the buffer
-	// This is a simple approach - we could optimize this further if needed
-	if len(b.eventIDMap) > b.capacity*2 {
-		newMap := make(map[string]int)
-		for id, pos := range b.eventIDMap {
-			// Check if the position is still valid
-			current := b.buffer
-			for i := 0; i < b.capacity; i++ {
-				if i == pos && current.Value != nil {
-					if event, ok := current.Value.(events.Event); ok && event.ID() == id {
-						newMap[id] = pos
-						break
-					}
-				}
-				current = current.Next()
-			}
-		}
-		b.eventIDMap = newMap
+	if b.count < b.capacity {
+		b.events[(b.start+b.count)%b.capacity] = event
+		b.count++
+		return
 	}
+	b.events[b.start] = event
+	b.start = (b.start + 1) % b.capacity
 }
 
-// GetAll returns all events in the buffer
+// GetAll returns all events in the buffer, oldest first
 func (b *EventBuffer) GetAll() []events.Event {
 	b.mutex.RLock()
 	defer b.mutex.RUnlock()
 
-	var result []events.Event
-	b.buffer.Do(func(value interface{}) {
-		if value != nil {
-			if event, ok := value.(events.Event); ok {
-				result = append(result, event)
-			}
-		}
-	})
-
-	return result
+	return b.after(-1)
 }
 
-// GetAfterID returns all events in the buffer that occurred after the event with the specified ID
+// GetAfterID returns all events in the buffer that occurred after the event with the specified ID.
+// If the ID is empty or no longer in the buffer, all events are returned.
 func (b *EventBuffer) GetAfterID(id string) []events.Event {
 	b.mutex.RLock()
 	defer b.mutex.RUnlock()
 
-	// If the ID is empty or not found, return all events
 	if id == "" {
-		return b.GetAll()
+		return b.after(-1)
 	}
-
-	position, exists := b.eventIDMap[id]
-	if !exists {
-		return b.GetAll()
-	}
-
-	var result []events.Event
-	current := b.buffer
-
-	// Find the position in the buffer
-	for i := 0; i < b.capacity; i++ {
-		if i == position {
-			break
+	for i := b.count - 1; i >= 0; i-- {
+		if b.events[(b.start+i)%b.capacity].ID() == id {
+			return b.after(i)
 		}
-		current = current.Next()
 	}
+	return b.after(-1)
+}
 
-	// Skip the event with the specified ID
-	current = current.Next()
-
-	// Collect all events after the specified ID
-	for i := 0; i < b.capacity; i++ {
-		if current.Value != nil {
-			if event, ok := current.Value.(events.Event); ok {
-				result = append(result, event)
-			}
-		}
-		current = current.Next()
+// after returns the events stored after the i-th oldest one; the caller holds the lock
+func (b *EventBuffer) after(i int) []events.Event {
+	var result []events.Event
+	for j := i + 1; j < b.count; j++ {
+		result = append(result, b.events[(b.start+j)%b.capacity])
 	}
-
 	return result
 }
 
@@ -149,6 +89,7 @@ func (b *EventBuffer) Clear() {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
-	b.buffer = ring.New(b.capacity)
-	b.eventIDMap = make(map[string]int)
+	b.events = make([]events.Event, b.capacity)
+	b.start = 0
+	b.count = 0
 }
@@ -2,12 +2,17 @@ package progress
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
 	"github.com/lspecian/maas-mcp-server/internal/logging"
 )
 
+// ErrOperationCancelled is the cause of a context that was cancelled because the client asked for it.
+// Operations use it to tell an explicit cancellation apart from a shutdown or a timeout.
+var ErrOperationCancelled = errors.New("operation cancelled by client")
+
 // CancellationManager handles the cancellation of long-running operations
 // when clients disconnect from the SSE stream.
 type CancellationManager struct {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/lspecian/maas-mcp-server/internal/transport/mcp/events"
@@ -40,11 +41,25 @@ func parseEventID(eventID string) (string, string, int64, int64, error) {
 	return operationID, eventType, timestamp, sequence, nil
 }
 
+// EventOperationID returns the ID of the operation an event ID generated by the
+// ReconnectionManager belongs to, so a client resuming with only its last event ID can be
+// given the rest of the right operation
+func EventOperationID(eventID string) (string, error) {
+	operationID, _, _, _, err := parseEventID(eventID)
+	if err != nil {
+		return "", err
+	}
+	return operationID, nil
+}
+
 // ReconnectionManager handles reconnection support for SSE clients
 type ReconnectionManager struct {
 	// buffers is a map of operation IDs to event buffers
 	buffers map[string]*EventBuffer
 
+	// mutex protects the buffers map
+	mutex sync.Mutex
+
 	// bufferSize is the maximum number of events to store in each buffer
 	bufferSize int
 }
@@ -64,6 +79,9 @@ func NewReconnectionManager(bufferSize int) *ReconnectionManager {
 // GetBuffer returns the event buffer for the specified operation ID
 // If the buffer doesn't exist, it creates a new one
 func (r *ReconnectionManager) GetBuffer(operationID string) *EventBuffer {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	buffer, exists := r.buffers[operationID]
 	if !exists {
 		buffer = NewEventBuffer(r.bufferSize)
@@ -112,6 +130,11 @@ func (r *ReconnectionManager) AddEvent(event events.Event) {
 		if e.EventID == "" {
 			e.EventID = generateEventID(operationID, string(e.Type()), time.Now().UnixNano())
 		}
+	case *events.MessageEvent:
+		operationID = e.OperationID
+		if e.EventID == "" {
+			e.EventID = generateEventID(operationID, string(e.Type()), time.Now().UnixNano())
+		}
 	default:
 		// Unknown event type, skip it
 		return
@@ -126,7 +149,9 @@ func (r *ReconnectionManager) AddEvent(event events.Event) {
 
 // GetEventsAfterID returns all events for the specified operation ID that occurred after the event with the specified ID
 func (r *ReconnectionManager) GetEventsAfterID(operationID string, lastEventID string) []events.Event {
+	r.mutex.Lock()
 	buffer, exists := r.buffers[operationID]
+	r.mutex.Unlock()
 	if !exists {
 		return nil
 	}
@@ -136,5 +161,8 @@ func (r *ReconnectionManager) GetEventsAfterID(operationID string, lastEventID s
 
 // CleanupOperation removes the buffer for the specified operation ID
 func (r *ReconnectionManager) CleanupOperation(operationID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	delete(r.buffers, operationID)
 }
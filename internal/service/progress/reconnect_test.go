@@ -129,3 +129,24 @@ func TestReconnectionManager_CleanupOperation(t *testing.T) {
 	_, exists := manager.buffers["op1"]
 	assert.False(t, exists)
 }
+
+func TestReconnectionManager_MessageEvents(t *testing.T) {
+	manager := NewReconnectionManager(5)
+
+	first := events.NewMessageEvent("stream-1", []byte(`{"jsonrpc":"2.0","method":"notifications/progress"}`))
+	second := events.NewMessageEvent("stream-1", []byte(`{"jsonrpc":"2.0","result":{},"id":"1"}`))
+	manager.AddEvent(first)
+	manager.AddEvent(second)
+	require.NotEmpty(t, first.ID())
+
+	// A client resuming with only the last event ID it saw gets the rest of its stream
+	operationID, err := EventOperationID(first.ID())
+	require.NoError(t, err)
+	assert.Equal(t, "stream-1", operationID)
+	missed := manager.GetEventsAfterID(operationID, first.ID())
+	require.Len(t, missed, 1)
+	assert.Equal(t, second.ID(), missed[0].ID())
+
+	_, err = EventOperationID("not-an-event-id")
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package progress
 
 import (
+	"context"
 	"time"
 
 	"github.com/lspecian/maas-mcp-server/internal/transport/mcp/events"
@@ -50,3 +51,18 @@ type ProgressReporter interface {
 
 // ProgressReporterFunc is a function type that creates a new ProgressReporter for a given operation.
 type ProgressReporterFunc func(operationID string) ProgressReporter
+
+// reporterContextKey is the context key under which a ProgressReporter is stored.
+type reporterContextKey struct{}
+
+// ContextWithReporter returns a copy of ctx that carries reporter. Transports use it to hand a
+// reporter to tool handlers, which do not otherwise know how progress reaches the client.
+func ContextWithReporter(ctx context.Context, reporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, reporterContextKey{}, reporter)
+}
+
+// ReporterFromContext returns the ProgressReporter stored in ctx, if any.
+func ReporterFromContext(ctx context.Context) (ProgressReporter, bool) {
+	reporter, ok := ctx.Value(reporterContextKey{}).(ProgressReporter)
+	return reporter, ok
+}
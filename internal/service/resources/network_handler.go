@@ -7,14 +7,22 @@ import (
 
 	"github.com/lspecian/maas-mcp-server/internal/errors"
 	"github.com/lspecian/maas-mcp-server/internal/logging"
-	"github.com/lspecian/maas-mcp-server/internal/models" // Added import for models
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
 	"github.com/lspecian/maas-mcp-server/internal/service"
 )
 
+// NetworkSource is the part of the MAAS client that space and IP range resources are read from
+type NetworkSource interface {
+	GetSpace(id int) (*types.Space, error)
+	GetSubnet(id int) (*types.Subnet, error)
+	ListIPRanges() ([]types.IPRange, error)
+}
+
 // NetworkResourceHandler handles network resource requests
 type NetworkResourceHandler struct {
 	BaseResourceHandler
 	mcpService *service.MCPService
+	source     NetworkSource
 }
 
 // NewNetworkResourceHandler creates a new network resource handler
@@ -37,6 +45,11 @@ func NewNetworkResourceHandler(mcpService *service.MCPService, logger *logging.L
 	}
 }
 
+// SetSource sets the MAAS client that space and IP range resources are read from
+func (h *NetworkResourceHandler) SetSource(source NetworkSource) {
+	h.source = source
+}
+
 // HandleRequest handles a network resource request
 func (h *NetworkResourceHandler) HandleRequest(ctx context.Context, request *ResourceRequest) (interface{}, error) {
 	// Parse the URI
@@ -101,103 +114,50 @@ func (h *NetworkResourceHandler) handleSubnetResource(ctx context.Context, reque
 
 // handleIPRangesResource handles IP ranges resources
 func (h *NetworkResourceHandler) handleIPRangesResource(ctx context.Context, subnetID int, request *ResourceRequest) (interface{}, error) {
-	// Get subnet details
-	subnet, err := h.mcpService.GetSubnetDetails(ctx, subnetID)
-	if err != nil {
-		return nil, err
-	}
-
-	// For now, return a placeholder since we don't have IP ranges in the current implementation
-	// Type assert subnet to *models.NetworkContext (or appropriate MAAS model)
-	snet, ok := subnet.(*models.NetworkContext) // Assuming GetSubnetDetails returns *models.NetworkContext or compatible
-	if !ok {
-		// If it's a different MAAS model, adjust assertion or mapping
-		// For now, assume it might be *maas.Subnet and try to access CIDR if direct model is different
-		// This part might need adjustment based on actual GetSubnetDetails return type
-		if concreteSubnet, castOK := subnet.(interface{ GetCIDR() string }); castOK { // Example if it's a MAAS type with a GetCIDR method
-			return map[string]interface{}{
-				"subnet_id": subnetID,
-				"cidr":      concreteSubnet.GetCIDR(),
-				"ranges":    []interface{}{},
-			}, nil
-		}
-		// Fallback if no direct CIDR or GetCIDR method
-		h.Logger.WithContext(ctx).Warnf("Failed to assert subnet to a known type with CIDR for ID %d", subnetID)
-		return map[string]interface{}{
-			"subnet_id": subnetID,
-			"cidr":      "unknown", // Indicate CIDR couldn't be determined
-			"ranges":    []interface{}{},
-		}, nil
-	}
-
-	return map[string]interface{}{
-		"subnet_id": subnetID,
-		"cidr":      snet.CIDR,       // Use snet.CIDR
-		"ranges":    []interface{}{}, // Empty array for now
-	}, nil
+	return h.subnetIPRanges(subnetID, "")
 }
 
 // handleReservedRangesResource handles reserved IP ranges resources
 func (h *NetworkResourceHandler) handleReservedRangesResource(ctx context.Context, subnetID int, request *ResourceRequest) (interface{}, error) {
-	// Get subnet details
-	subnet, err := h.mcpService.GetSubnetDetails(ctx, subnetID)
-	if err != nil {
-		return nil, err
-	}
-
-	// For now, return a placeholder since we don't have reserved ranges in the current implementation
-	snet, ok := subnet.(*models.NetworkContext)
-	if !ok {
-		if concreteSubnet, castOK := subnet.(interface{ GetCIDR() string }); castOK {
-			return map[string]interface{}{
-				"subnet_id": subnetID,
-				"cidr":      concreteSubnet.GetCIDR(),
-				"ranges":    []interface{}{},
-			}, nil
-		}
-		h.Logger.WithContext(ctx).Warnf("Failed to assert subnet to a known type with CIDR for ID %d (reserved ranges)", subnetID)
-		return map[string]interface{}{
-			"subnet_id": subnetID,
-			"cidr":      "unknown",
-			"ranges":    []interface{}{},
-		}, nil
-	}
-	return map[string]interface{}{
-		"subnet_id": subnetID,
-		"cidr":      snet.CIDR,       // Use snet.CIDR
-		"ranges":    []interface{}{}, // Empty array for now
-	}, nil
+	return h.subnetIPRanges(subnetID, types.IPRangeTypeReserved)
 }
 
 // handleDynamicRangesResource handles dynamic IP ranges resources
 func (h *NetworkResourceHandler) handleDynamicRangesResource(ctx context.Context, subnetID int, request *ResourceRequest) (interface{}, error) {
-	// Get subnet details
-	subnet, err := h.mcpService.GetSubnetDetails(ctx, subnetID)
+	return h.subnetIPRanges(subnetID, types.IPRangeTypeDynamic)
+}
+
+// subnetIPRanges returns the IP ranges of a subnet, only those of rangeType unless it is empty
+func (h *NetworkResourceHandler) subnetIPRanges(subnetID int, rangeType string) (interface{}, error) {
+	if h.source == nil {
+		return nil, errors.NewUnsupportedOperationError("IP range resources are not available", nil)
+	}
+
+	subnet, err := h.source.GetSubnet(subnetID)
+	if err != nil {
+		return nil, errors.NewMaasClientError(fmt.Sprintf("Failed to get subnet %d", subnetID), err)
+	}
+
+	allRanges, err := h.source.ListIPRanges()
 	if err != nil {
-		return nil, err
-	}
-
-	// For now, return a placeholder since we don't have dynamic ranges in the current implementation
-	snet, ok := subnet.(*models.NetworkContext)
-	if !ok {
-		if concreteSubnet, castOK := subnet.(interface{ GetCIDR() string }); castOK {
-			return map[string]interface{}{
-				"subnet_id": subnetID,
-				"cidr":      concreteSubnet.GetCIDR(),
-				"ranges":    []interface{}{},
-			}, nil
+		return nil, errors.NewMaasClientError("Failed to list IP ranges", err)
+	}
+
+	ranges := make([]types.IPRange, 0)
+	for _, ipRange := range allRanges {
+		if ipRange.SubnetID != subnetID {
+			continue
+		}
+		if rangeType != "" && ipRange.Type != rangeType {
+			continue
 		}
-		h.Logger.WithContext(ctx).Warnf("Failed to assert subnet to a known type with CIDR for ID %d (dynamic ranges)", subnetID)
-		return map[string]interface{}{
-			"subnet_id": subnetID,
-			"cidr":      "unknown",
-			"ranges":    []interface{}{},
-		}, nil
+		ranges = append(ranges, ipRange)
 	}
+
 	return map[string]interface{}{
 		"subnet_id": subnetID,
-		"cidr":      snet.CIDR,       // Use snet.CIDR
-		"ranges":    []interface{}{}, // Empty array for now
+		"cidr":      subnet.CIDR,
+		"ranges":    ranges,
 	}, nil
 }
 
@@ -234,14 +194,25 @@ func (h *NetworkResourceHandler) handleFabricResource(ctx context.Context, reque
 // handleSpaceResource handles space-related resources
 func (h *NetworkResourceHandler) handleSpaceResource(ctx context.Context, request *ResourceRequest) (interface{}, error) {
 	// Get the space ID
-	spaceID := request.Parameters["space_id"]
-	if spaceID == "" {
+	spaceIDStr := request.Parameters["space_id"]
+	if spaceIDStr == "" {
 		return nil, errors.NewValidationError("space_id is required", nil)
 	}
 
-	// For now, return a placeholder since we don't have space details in the current implementation
-	return map[string]interface{}{
-		"id":   spaceID,
-		"name": "Space " + spaceID,
-	}, nil
+	// Convert space ID to int
+	spaceID, err := strconv.Atoi(spaceIDStr)
+	if err != nil {
+		return nil, errors.NewValidationError(fmt.Sprintf("Invalid space ID: %s", err.Error()), err)
+	}
+
+	if h.source == nil {
+		return nil, errors.NewUnsupportedOperationError("Space resources are not available", nil)
+	}
+
+	space, err := h.source.GetSpace(spaceID)
+	if err != nil {
+		return nil, errors.NewMaasClientError(fmt.Sprintf("Failed to get space %d", spaceID), err)
+	}
+
+	return space, nil
 }
@@ -15,6 +15,7 @@ type ResourceService struct {
 	logger     *logging.Logger
 	mcpService *service.MCPService
 	cache      *ResourceCache
+	network    *NetworkResourceHandler
 }
 
 // NewResourceService creates a new resource service
@@ -45,8 +46,8 @@ func (s *ResourceService) registerHandlers() error {
 	}
 
 	// Register network handler
-	networkHandler := NewNetworkResourceHandler(s.mcpService, s.logger)
-	if err := s.registry.RegisterHandler(networkHandler); err != nil {
+	s.network = NewNetworkResourceHandler(s.mcpService, s.logger)
+	if err := s.registry.RegisterHandler(s.network); err != nil {
 		return fmt.Errorf("failed to register network handler: %w", err)
 	}
 
@@ -127,6 +128,16 @@ func (s *ResourceService) GetResourceWithContentType(ctx context.Context, uri st
 	return response, nil
 }
 
+// SetNetworkSource sets the MAAS client that space and IP range resources are read from
+func (s *ResourceService) SetNetworkSource(source NetworkSource) {
+	s.network.SetSource(source)
+}
+
+// Registry returns the registry holding the resource handlers
+func (s *ResourceService) Registry() *Registry {
+	return s.registry
+}
+
 // GetResourceHandlers returns all registered resource handlers
 func (s *ResourceService) GetResourceHandlers() []ResourceHandler {
 	return s.registry.GetHandlers()
@@ -37,6 +37,10 @@ type URIParseResult struct {
 	Pattern         string
 }
 
+// parameterRegexp matches the parameters of a URI pattern:
+// {param_name}, {param_name?}, and {param_name:value1|value2}
+var parameterRegexp = regexp.MustCompile(`\{([^{}:?]+)(\?)?(?::([^{}]+))?\}`)
+
 // ParseURI parses a URI string into its components
 func ParseURI(uri string) (*URIPattern, error) {
 	// Split the URI into scheme and path
@@ -105,10 +109,7 @@ func ParseURI(uri string) (*URIPattern, error) {
 
 // ExtractParameters extracts parameters from a URI pattern
 func ExtractParameters(pattern string) ([]URIParameter, error) {
-	// Regular expression to match parameters in the pattern
-	// Matches {param_name}, {param_name?}, and {param_name:value1|value2}
-	re := regexp.MustCompile(`\{([^{}:]+)(\?)?(?::([^{}]+))?\}`)
-	matches := re.FindAllStringSubmatch(pattern, -1)
+	matches := parameterRegexp.FindAllStringSubmatch(pattern, -1)
 
 	parameters := make([]URIParameter, 0, len(matches))
 	for _, match := range matches {
@@ -142,56 +143,61 @@ func MatchURI(uri string, pattern string) (*URIParseResult, error) {
 		return nil, err
 	}
 
-	// Create a regular expression from the pattern
-	regexPattern := pattern
-	paramMap := make(map[string]string)
-
-	// Replace parameters with regex capture groups
-	for _, param := range parameters {
-		var replacement string
+	// Create a regular expression from the pattern: the literal parts are quoted and the
+	// parameters become capture groups. An optional parameter takes the slash before it along,
+	// so that it can be left out altogether.
+	var regexPattern strings.Builder
+	regexPattern.WriteString("^")
+	last := 0
+	for i, loc := range parameterRegexp.FindAllStringIndex(pattern, -1) {
+		param := parameters[i]
+		literal := pattern[last:loc[0]]
+		last = loc[1]
+
+		value := "[^/]+"
 		if len(param.Values) > 0 {
 			// For enumerated parameters, create a group with alternatives
-			replacement = fmt.Sprintf("(?P<%s>%s)", param.Name, strings.Join(param.Values, "|"))
-		} else if param.IsOptional {
-			// For optional parameters
-			replacement = fmt.Sprintf("(?P<%s>[^/]*)?", param.Name)
-		} else {
-			// For required parameters
-			replacement = fmt.Sprintf("(?P<%s>[^/]+)", param.Name)
+			values := make([]string, len(param.Values))
+			for j, v := range param.Values {
+				values[j] = regexp.QuoteMeta(v)
+			}
+			value = strings.Join(values, "|")
 		}
 
-		// Escape curly braces for regex
-		paramPattern := fmt.Sprintf("\\{%s", param.Name)
 		if param.IsOptional {
-			paramPattern += "\\?"
-		}
-		if len(param.Values) > 0 {
-			paramPattern += fmt.Sprintf(":%s", strings.Join(param.Values, "\\|"))
+			separator := ""
+			if strings.HasSuffix(literal, "/") {
+				literal = strings.TrimSuffix(literal, "/")
+				separator = "/"
+			}
+			regexPattern.WriteString(regexp.QuoteMeta(literal))
+			fmt.Fprintf(&regexPattern, "(?:%s(?P<%s>%s))?", separator, param.Name, value)
+			continue
 		}
-		paramPattern += "\\}"
 
-		regexPattern = regexp.MustCompile(paramPattern).ReplaceAllString(regexPattern, replacement)
+		regexPattern.WriteString(regexp.QuoteMeta(literal))
+		fmt.Fprintf(&regexPattern, "(?P<%s>%s)", param.Name, value)
 	}
-
-	// Convert the pattern to a regex
-	regexPattern = "^" + regexp.QuoteMeta(regexPattern) + "$"
-	regexPattern = strings.Replace(regexPattern, "\\(\\?P<", "(?P<", -1)
-	regexPattern = strings.Replace(regexPattern, "\\)", ")", -1)
-	regexPattern = strings.Replace(regexPattern, "\\|", "|", -1)
+	regexPattern.WriteString(regexp.QuoteMeta(pattern[last:]))
+	regexPattern.WriteString("$")
 
 	// Create the regex
-	re, err := regexp.Compile(regexPattern)
+	re, err := regexp.Compile(regexPattern.String())
 	if err != nil {
 		return nil, fmt.Errorf("invalid pattern: %w", err)
 	}
 
+	// The query string is not part of the pattern
+	path := strings.SplitN(uri, "?", 2)[0]
+
 	// Match the URI against the pattern
-	match := re.FindStringSubmatch(uri)
+	match := re.FindStringSubmatch(path)
 	if match == nil {
 		return nil, fmt.Errorf("URI does not match pattern: %s", pattern)
 	}
 
 	// Extract parameter values
+	paramMap := make(map[string]string)
 	for i, name := range re.SubexpNames() {
 		if i != 0 && name != "" {
 			paramMap[name] = match[i]
@@ -2,14 +2,17 @@ package service
 
 import (
 	"context"
+	"encoding/json" // Added for MCPService.CallAPI
+	"errors"
 	"fmt"
 	"net/http"
-	"encoding/json" // Added for MCPService.CallAPI
-	"strings"       // Added for strings.EqualFold
+	"strconv"
+	"strings"
 
 	"github.com/lspecian/maas-mcp-server/internal/logging"
 	"github.com/lspecian/maas-mcp-server/internal/models"
 	"github.com/lspecian/maas-mcp-server/internal/maasclient" // Added for MaasClient field
+	"github.com/lspecian/maas-mcp-server/pkg/mcp"
 )
 
 // ServiceError represents an error from a service
@@ -44,6 +47,7 @@ var (
 	ErrInternalServer     = fmt.Errorf("internal server error")
 	ErrServiceUnavailable = fmt.Errorf("service unavailable")
 	ErrConflict           = fmt.Errorf("conflict") // Added for HTTP 409
+	ErrNotImplemented     = fmt.Errorf("not implemented")
 )
 
 // MCPService is the main service for MCP operations
@@ -54,6 +58,15 @@ type MCPService struct {
 	storageService *StorageService // Added StorageService
 	logger         *logging.Logger
 	maasClient     *maasclient.MaasClient // Added MaasClient field
+	operations     MachineOperationRunner // Set by SetMachineOperations
+}
+
+// MachineOperationRunner runs machine actions in the background and returns the operation that
+// follows each one, such as the MachineOperations of pkg/mcp/tools
+type MachineOperationRunner interface {
+	Allocate(ctx context.Context, constraints map[string]string) (*models.MachineOperation, error)
+	Deploy(ctx context.Context, id string, osConfig map[string]string) (*models.MachineOperation, error)
+	Release(ctx context.Context, id string, comment string) (*models.MachineOperation, error)
 }
 
 // NewMCPService creates a new MCP service
@@ -124,6 +137,22 @@ func (s *MCPService) ListMachines(ctx context.Context, params interface{}) (inte
 		filters["tags"] = tags
 	}
 
+	// Collect storage requirements; each one is satisfied by its own disks
+	var storageRequirements []models.SimpleStorageConstraint
+	if req.StorageConstraints != nil {
+		storageRequirements = append(storageRequirements, *req.StorageConstraints)
+	}
+	storageRequirements = append(storageRequirements, req.StorageRequirements...)
+	for i := range storageRequirements {
+		if err := storageRequirements[i].Validate(); err != nil {
+			return nil, &ServiceError{
+				Err:        ErrBadRequest,
+				StatusCode: http.StatusBadRequest,
+				Message:    fmt.Sprintf("Invalid storage constraints: %v", err),
+			}
+		}
+	}
+
 	// Create pagination options if provided
 	var paginationOptions *models.PaginationOptions
 	if req.Limit > 0 || req.Offset > 0 || req.Page > 0 {
@@ -150,7 +179,7 @@ func (s *MCPService) ListMachines(ctx context.Context, params interface{}) (inte
 	}
 
 	// Call the machine service to list machines
-	return s.machineService.ListMachinesPaginated(ctx, filters, paginationOptions)
+	return s.machineService.ListMachinesWithStorage(ctx, filters, paginationOptions, storageRequirements)
 }
 
 // DiscoverMachines discovers machines in the network
@@ -172,6 +201,7 @@ func (s *MCPService) DiscoverMachines(ctx context.Context, params interface{}) (
 		CommissioningEnabled: req.CommissioningEnabled,
 		ScanNetworks:         req.ScanNetworks,
 		ScanStorage:          req.ScanStorage,
+		StorageConstraints:   req.StorageConstraints,
 	}
 
 	// Call the machine service to discover machines
@@ -192,30 +222,227 @@ func (s *MCPService) AllocateMachine(ctx context.Context, params interface{}) (i
 
 	// Convert params to constraints map
 	constraints := make(map[string]string)
-	// TODO: Convert params to constraints
+	var storage string
+	var storageConstraints []models.SimpleStorageConstraint
+	var async bool
+	switch req := params.(type) {
+	case mcp.AllocateMachineRequest:
+		allocateRequestConstraints(&req, constraints)
+		storage, storageConstraints, async = req.Storage, req.StorageConstraints, req.Async
+	case *mcp.AllocateMachineRequest:
+		allocateRequestConstraints(req, constraints)
+		storage, storageConstraints, async = req.Storage, req.StorageConstraints, req.Async
+	case *models.AllocateMachineRequest:
+		for key, value := range req.Constraints {
+			constraints[key] = value
+		}
+		if len(req.Tags) > 0 {
+			constraints["tags"] = strings.Join(req.Tags, ",")
+		}
+		storage, storageConstraints, async = req.Storage, req.StorageConstraints, req.Async
+	case map[string]string:
+		for key, value := range req {
+			constraints[key] = value
+		}
+	case nil:
+	default:
+		return nil, &ServiceError{
+			Err:        ErrBadRequest,
+			StatusCode: http.StatusBadRequest,
+			Message:    "Invalid parameters",
+		}
+	}
+
+	// Storage may be given as a MAAS storage string or as typed constraints; both end up as the string
+	if storage == "" {
+		storage = constraints["storage"]
+	}
+	normalized, err := models.NormalizeMAASStorageConstraints(storage, storageConstraints)
+	if err != nil {
+		return nil, &ServiceError{
+			Err:        fmt.Errorf("%w: %w", ErrBadRequest, err),
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("Invalid storage constraints: %v", err),
+		}
+	}
+	if normalized != "" {
+		constraints["storage"] = normalized
+	}
+
+	if async {
+		operations, err := s.machineOperations()
+		if err != nil {
+			return nil, err
+		}
+		return operations.Allocate(ctx, constraints)
+	}
 
 	// Call the machine service to allocate a machine
 	return s.machineService.AllocateMachine(ctx, constraints)
 }
 
+// allocateRequestConstraints copies the fields of an allocation request into a constraints map.
+func allocateRequestConstraints(req *mcp.AllocateMachineRequest, constraints map[string]string) {
+	if req.Hostname != "" {
+		constraints["hostname"] = req.Hostname
+	}
+	if req.Zone != "" {
+		constraints["zone"] = req.Zone
+	}
+	if req.Pool != "" {
+		constraints["pool"] = req.Pool
+	}
+	if req.Architecture != "" {
+		constraints["architecture"] = req.Architecture
+	}
+	if len(req.Tags) > 0 {
+		constraints["tags"] = strings.Join(req.Tags, ",")
+	}
+	if req.MinCPUCount > 0 {
+		constraints["cpu_count"] = strconv.Itoa(req.MinCPUCount)
+	}
+	if req.MinMemory > 0 {
+		constraints["mem"] = strconv.Itoa(req.MinMemory)
+	}
+}
+
 // DeployMachine deploys an operating system to a machine
 func (s *MCPService) DeployMachine(ctx context.Context, params interface{}) (interface{}, error) {
 	s.logger.Debug("MCPService.DeployMachine called")
 
-	// TODO: Extract systemID and osConfig from params
+	var req *mcp.DeployMachineRequest
+	switch p := params.(type) {
+	case mcp.DeployMachineRequest:
+		req = &p
+	case *mcp.DeployMachineRequest:
+		req = p
+	}
+	if req == nil {
+		return nil, &ServiceError{
+			Err:        ErrBadRequest,
+			StatusCode: http.StatusBadRequest,
+			Message:    "Invalid parameters",
+		}
+	}
+
+	osConfig := make(map[string]string)
+	if req.DistroSeries != "" {
+		osConfig["distro_series"] = req.DistroSeries
+	}
+	if req.UserData != "" {
+		osConfig["user_data"] = req.UserData
+	}
+	if req.HWEKernel != "" {
+		osConfig["hwe_kernel"] = req.HWEKernel
+	}
+
+	if req.Async {
+		operations, err := s.machineOperations()
+		if err != nil {
+			return nil, err
+		}
+		return operations.Deploy(ctx, req.SystemID, osConfig)
+	}
 
 	// Call the machine service to deploy a machine
-	return nil, fmt.Errorf("not implemented")
+	return s.machineService.DeployMachine(ctx, req.SystemID, osConfig)
+}
+
+// CommissionMachine commissions a machine
+func (s *MCPService) CommissionMachine(ctx context.Context, params interface{}) (interface{}, error) {
+	s.logger.Debug("MCPService.CommissionMachine called")
+
+	var req *mcp.CommissionMachineRequest
+	switch p := params.(type) {
+	case mcp.CommissionMachineRequest:
+		req = &p
+	case *mcp.CommissionMachineRequest:
+		req = p
+	}
+	if req == nil {
+		return nil, &ServiceError{
+			Err:        ErrBadRequest,
+			StatusCode: http.StatusBadRequest,
+			Message:    "Invalid parameters",
+		}
+	}
+
+	options := map[string]string{
+		"enable_ssh":      strconv.FormatBool(req.EnableSSH),
+		"skip_networking": strconv.FormatBool(req.SkipNetworking),
+		"skip_storage":    strconv.FormatBool(req.SkipStorage),
+	}
+	if req.CommissioningScripts != "" {
+		options["commissioning_scripts"] = req.CommissioningScripts
+	}
+	if req.TestingScripts != "" {
+		options["testing_scripts"] = req.TestingScripts
+	}
+
+	if req.Async {
+		return nil, &ServiceError{
+			Err:        ErrNotImplemented,
+			StatusCode: http.StatusNotImplemented,
+			Message:    "Asynchronous commissioning is not supported",
+		}
+	}
+
+	// Call the machine service to commission a machine
+	return s.machineService.CommissionMachine(ctx, req.SystemID, options)
 }
 
 // ReleaseMachine releases a machine back to the pool
 func (s *MCPService) ReleaseMachine(ctx context.Context, params interface{}) (interface{}, error) {
 	s.logger.Debug("MCPService.ReleaseMachine called")
 
-	// TODO: Extract systemID and comment from params
+	var req *mcp.ReleaseMachineRequest
+	switch p := params.(type) {
+	case mcp.ReleaseMachineRequest:
+		req = &p
+	case *mcp.ReleaseMachineRequest:
+		req = p
+	}
+	if req == nil {
+		return nil, &ServiceError{
+			Err:        ErrBadRequest,
+			StatusCode: http.StatusBadRequest,
+			Message:    "Invalid parameters",
+		}
+	}
+
+	if req.Async {
+		operations, err := s.machineOperations()
+		if err != nil {
+			return nil, err
+		}
+		return operations.Release(ctx, req.SystemID, req.Comment)
+	}
 
 	// Call the machine service to release a machine
-	return nil, fmt.Errorf("not implemented")
+	if err := s.machineService.ReleaseMachine(ctx, req.SystemID, req.Comment); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"success":   true,
+		"system_id": req.SystemID,
+	}, nil
+}
+
+// SetMachineOperations enables asynchronous machine operations, run by operations
+func (s *MCPService) SetMachineOperations(operations MachineOperationRunner) {
+	s.operations = operations
+}
+
+// machineOperations returns the asynchronous operation runner, or an error when none is set
+func (s *MCPService) machineOperations() (MachineOperationRunner, error) {
+	if s.operations == nil {
+		return nil, &ServiceError{
+			Err:        ErrServiceUnavailable,
+			StatusCode: http.StatusServiceUnavailable,
+			Message:    "Asynchronous machine operations are not enabled",
+		}
+	}
+	return s.operations, nil
 }
 
 // PowerOnMachine powers on a machine
@@ -269,6 +496,15 @@ func mapClientError(err error) error {
 		return serviceErr
 	}
 
+	// Storage constraints are owned by the server, so a missing entry is a plain not found
+	if errors.Is(err, maasclient.ErrConstraintsNotFound) {
+		return &ServiceError{
+			Err:        ErrNotFound,
+			StatusCode: http.StatusNotFound,
+			Message:    err.Error(),
+		}
+	}
+
 	// Default to internal server error
 	statusCode := http.StatusInternalServerError
 	message := err.Error()
@@ -336,27 +572,41 @@ func (s *MCPService) CallAPI(ctx context.Context, httpMethod string, apiPath str
 		}
 	}
 
-	// Specific handling for GET /api/2.0/version
-	// Note: The derivedPath from the tool name "maas_get_api_2.0_version" is "/api/2.0/version"
-	if strings.EqualFold(httpMethod, "GET") && apiPath == "/api/2.0/version" {
-		s.logger.Info("MCPService: Dispatching to GetVersion for /api/2.0/version")
-		return s.maasClient.GetVersion(ctx)
-	}
-	
-	// Specific handling for other known tools can be added here as else-if blocks.
-	// For example, if we had a specific method for listing machines:
-	// if strings.EqualFold(httpMethod, "GET") && apiPath == "/api/2.0/machines" {
-	//    s.logger.Info("MCPService: Dispatching to a specific ListMachines method if it existed")
-	//    // return s.maasClient.ListMachines(ctx, requestBody) // Hypothetical
-	// } else if strings.EqualFold(httpMethod, "POST") && apiPath == "/api/2.0/account/op-create_authorisation_token" {
-	// 	s.logger.Info("MCPService: Dispatching to generic MaasClient.CallAPI for POST /api/2.0/account/op-create_authorisation_token")
-	// 	// This specific tool will use the generic CallAPI in MaasClient for now.
-	// 	// Future work could involve s.maasClient.CreateAuthorisationToken(ctx, params_from_requestBody)
-	// 	return s.maasClient.CallAPI(ctx, httpMethod, apiPath, requestBody)
-	// }
-
-
-	// Fallback to the generic CallAPI placeholder for other tools not specifically handled above
-	s.logger.Info("MCPService: Dispatching to generic MaasClient.CallAPI (placeholder) for unrecognized or not-yet-specifically-handled tool.")
-	return s.maasClient.CallAPI(ctx, httpMethod, apiPath, requestBody)
+	result, err := s.maasClient.CallAPI(ctx, httpMethod, apiPath, requestBody)
+	if err != nil {
+		var apiErr *maasclient.APIError
+		if errors.As(err, &apiErr) {
+			return nil, &ServiceError{
+				Err:        mapMAASStatusToError(apiErr.StatusCode),
+				StatusCode: apiErr.StatusCode,
+				Message:    apiErr.Error(),
+			}
+		}
+		return nil, &ServiceError{
+			Err:        ErrServiceUnavailable,
+			StatusCode: http.StatusBadGateway,
+			Message:    fmt.Sprintf("MAAS API call failed: %v", err),
+		}
+	}
+	return result, nil
+}
+
+// mapMAASStatusToError maps a MAAS HTTP status code to one of the common service errors.
+func mapMAASStatusToError(statusCode int) error {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusServiceUnavailable:
+		return ErrServiceUnavailable
+	default:
+		return ErrInternalServer
+	}
 }
@@ -7,11 +7,13 @@ import (
 	"os"
 	"path/filepath"
 	"fmt"
-	"strings" // Added for createGenericToolHandler
+	"net/url"
+	"strings"
 
 	"github.com/lspecian/maas-mcp-server/internal/logging"
 	"github.com/lspecian/maas-mcp-server/internal/models"
 	"github.com/lspecian/maas-mcp-server/internal/service"
+	"github.com/lspecian/maas-mcp-server/internal/toolprofile"
 )
 
 // OsReadFile is a package-level variable that defaults to os.ReadFile.
@@ -23,16 +25,26 @@ var OsReadFile = os.ReadFile
 type Factory struct {
 	mcpService *service.MCPService
 	logger     *logging.Logger
+	profile    *toolprofile.Profile
 }
 
-// NewFactory creates a new factory
+// NewFactory creates a new factory. It registers every tool until a tool profile is set.
 func NewFactory(mcpService *service.MCPService, logger *logging.Logger) *Factory {
 	return &Factory{
 		mcpService: mcpService,
 		logger:     logger,
+		profile:    toolprofile.Full(),
 	}
 }
 
+// SetToolProfile limits the tools registered by the factory to those the profile allows
+func (f *Factory) SetToolProfile(profile *toolprofile.Profile) {
+	if profile == nil {
+		profile = toolprofile.Full()
+	}
+	f.profile = profile
+}
+
 // CreateToolService creates a new tool service with all dependencies
 func (f *Factory) CreateToolService() ToolService {
 	// Create dependencies
@@ -60,6 +72,68 @@ func (f *Factory) CreateToolService() ToolService {
 
 // registerTools registers all tools with the service
 func (f *Factory) registerTools(toolService ToolService) {
+	toolDefinitions, toolsFilePath, err := f.loadToolDefinitions()
+	if err != nil {
+		f.logger.Fatalf("Failed to load tool definitions: %v", err)
+		return
+	}
+
+	if len(toolDefinitions) == 0 {
+		f.logger.Warnf("No tools found in %s. MCP server will have no dynamically registered MAAS tools.", toolsFilePath)
+	}
+
+	registeredCount := 0
+	skippedCount := 0
+	for _, tool := range toolDefinitions {
+		if !f.profile.Allows(profileTool(tool)) {
+			skippedCount++
+			continue
+		}
+
+		// Ensure tool.InputSchema is correctly passed. It's already part of models.MCPTool.
+		// The RegisterTool function expects an interface{} for the schema, which models.MCPInputSchema is.
+		
+		handler := f.createGenericToolHandler(tool) // Pass the whole tool model
+
+		// The inputSchema for RegisterTool is interface{}. tool.InputSchema is models.MCPInputSchema.
+		// This should be compatible.
+		err := toolService.RegisterToolWithAnnotations(tool.Name, tool.Description, tool.InputSchema, tool.Annotations, handler)
+		if err != nil {
+			f.logger.Errorf("Failed to register tool '%s': %v", tool.Name, err)
+		} else {
+			registeredCount++
+			// f.logger.Debugf("Successfully registered tool: %s", tool.Name) // Optional
+		}
+	}
+	f.logger.Infof("Successfully registered %d tools from %s", registeredCount, toolsFilePath)
+	if skippedCount > 0 {
+		f.logger.Infof("Skipped %d tools not in tool profile '%s'", skippedCount, f.profile.Name())
+	}
+}
+
+// ToolDefinitions returns the generated MAAS API tool definitions that the tool profile allows
+func (f *Factory) ToolDefinitions() ([]models.MCPTool, error) {
+	toolDefinitions, _, err := f.loadToolDefinitions()
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make([]models.MCPTool, 0, len(toolDefinitions))
+	for _, tool := range toolDefinitions {
+		if f.profile.Allows(profileTool(tool)) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed, nil
+}
+
+// GenericToolHandler returns the handler that calls the MAAS API endpoint of a generated tool
+func (f *Factory) GenericToolHandler(tool models.MCPTool) ToolHandler {
+	return f.createGenericToolHandler(tool)
+}
+
+// loadToolDefinitions reads the generated tool definitions, returning them with the path they were read from
+func (f *Factory) loadToolDefinitions() ([]models.MCPTool, string, error) {
 	toolsFilePath := "cmd/gen-tools/generated_maas_tools.json" // Assumes execution from project root
 
 	// Attempt to construct a more robust path if running from within internal/service/tools or similar
@@ -85,40 +159,28 @@ func (f *Factory) registerTools(toolService ToolService) {
 
 	jsonData, err := OsReadFile(toolsFilePath) // Use the package-level variable
 	if err != nil {
-		f.logger.Fatalf("Failed to read tool definitions file '%s': %v", toolsFilePath, err)
-		return 
+		return nil, toolsFilePath, fmt.Errorf("failed to read tool definitions file '%s': %w", toolsFilePath, err)
 	}
 
 	var toolDefinitions struct {
 		Tools []models.MCPTool `json:"tools"`
 	}
 	if err := json.Unmarshal(jsonData, &toolDefinitions); err != nil {
-		f.logger.Fatalf("Failed to unmarshal tool definitions from '%s': %v", toolsFilePath, err)
-		return
+		return nil, toolsFilePath, fmt.Errorf("failed to unmarshal tool definitions from '%s': %w", toolsFilePath, err)
 	}
+	return toolDefinitions.Tools, toolsFilePath, nil
+}
 
-	if len(toolDefinitions.Tools) == 0 {
-		f.logger.Warnf("No tools found in %s. MCP server will have no dynamically registered MAAS tools.", toolsFilePath)
+// profileTool returns the properties of a tool that tool profiles select on
+func profileTool(tool models.MCPTool) toolprofile.Tool {
+	profiled := toolprofile.Tool{
+		Name: tool.Name,
+		Tags: tool.Tags,
 	}
-
-	registeredCount := 0
-	for _, tool := range toolDefinitions.Tools {
-		// Ensure tool.InputSchema is correctly passed. It's already part of models.MCPTool.
-		// The RegisterTool function expects an interface{} for the schema, which models.MCPInputSchema is.
-		
-		handler := f.createGenericToolHandler(tool) // Pass the whole tool model
-
-		// The inputSchema for RegisterTool is interface{}. tool.InputSchema is models.MCPInputSchema.
-		// This should be compatible.
-		err := toolService.RegisterTool(tool.Name, tool.Description, tool.InputSchema, handler)
-		if err != nil {
-			f.logger.Errorf("Failed to register tool '%s': %v", tool.Name, err)
-		} else {
-			registeredCount++
-			// f.logger.Debugf("Successfully registered tool: %s", tool.Name) // Optional
-		}
+	if tool.Endpoint != nil {
+		profiled.Method = tool.Endpoint.Method
 	}
-	f.logger.Infof("Successfully registered %d tools from %s", registeredCount, toolsFilePath)
+	return profiled
 }
 
 // registerMachineTools registers machine management tools
@@ -335,54 +397,63 @@ func (f *Factory) registerScriptTools(toolService ToolService) {
 	// These will be implemented when the script service is available
 }
 
-// createGenericToolHandler creates a "smart" placeholder handler that logs details.
+// createGenericToolHandler creates a handler that forwards a generated tool call to the MAAS API.
 func (f *Factory) createGenericToolHandler(toolDefinition models.MCPTool) ToolHandler {
 	return func(ctx context.Context, params json.RawMessage) (interface{}, error) {
-		// Tool name format is expected to be like "maas_<method>_<path_segments_joined_by_underscores>"
-		// e.g., "maas_post_api_2.0_account_op-create_authorisation_token"
-		// or "maas_delete_api_2.0_account_prefs_sshkeys_id" (where id was a path param)
-
 		f.logger.Infof("GenericToolHandler: Tool '%s' invoked.", toolDefinition.Name)
 
-		nameParts := strings.SplitN(toolDefinition.Name, "_", 3)
-		if len(nameParts) < 3 || nameParts[0] != "maas" {
-			errMsg := fmt.Sprintf("generic handler: could not parse tool name '%s' into expected format [maas_method_path]", toolDefinition.Name)
-			f.logger.Error(errMsg)
-			return nil, fmt.Errorf(errMsg) // Or a more structured internal error
-		}
-
-		httpMethod := strings.ToUpper(nameParts[1])
-		// Reconstruct path: replace underscores with slashes in the path part.
-		// This is a simplification. If path parameters were originally named e.g. "system_id",
-		// the name might be "..._system_id_..." and this would become "/system/id/".
-		// This basic reconstruction is a starting point.
-		derivedPath := "/" + strings.ReplaceAll(nameParts[2], "_", "/")
-		
-		// A more robust path reconstruction would ideally rely on an original path template
-		// stored in the tool definition if available. For now, this derivation is an approximation.
-
-		f.logger.Infof("  Derived HTTP Method: %s", httpMethod)
-		f.logger.Infof("  Derived API Path (approximate): %s", derivedPath)
-		f.logger.Infof("  Input Parameters (raw): %s", string(params))
-		f.logger.Debugf("  Tool Description: %s", toolDefinition.Description)
-		// For debugging, you might want to see the schema, but it can be very verbose:
-		// schemaBytes, _ := json.Marshal(toolDefinition.InputSchema)
-		// f.logger.Debugf("  Input Schema: %s", string(schemaBytes))
-
-		// Future logic for actual dispatch and MAAS client call will go here.
-		// For now, it remains a "smart" placeholder.
-		
 		// Ensure mcpService is available
 		if f.mcpService == nil {
 			f.logger.Error("GenericToolHandler: MCPService is not available in factory.")
 			return nil, fmt.Errorf("internal server error: MCPService not configured for generic handler")
 		}
 
-		// Call f.mcpService.CallAPI with the derived information
-		return f.mcpService.CallAPI(ctx, httpMethod, derivedPath, params)
+		if toolDefinition.Endpoint == nil {
+			return f.callDerivedEndpoint(ctx, toolDefinition, params)
+		}
+
+		var input map[string]interface{}
+		if len(params) > 0 && string(params) != "null" {
+			if err := json.Unmarshal(params, &input); err != nil {
+				return nil, fmt.Errorf("invalid parameters for tool '%s': %w", toolDefinition.Name, err)
+			}
+		}
+
+		// Each argument goes where the endpoint takes it: the path, the query or the body
+		request, err := toolDefinition.Endpoint.Request(input)
+		if err != nil {
+			return nil, fmt.Errorf("tool '%s': %w", toolDefinition.Name, err)
+		}
+
+		body, err := json.Marshal(request.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode parameters for tool '%s': %w", toolDefinition.Name, err)
+		}
+
+		f.logger.Debugf("GenericToolHandler: %s %s", request.Method, request.Path)
+
+		return f.mcpService.CallAPI(ctx, request.Method, request.Path, body)
 	}
 }
 
+// callDerivedEndpoint handles tool definitions generated before endpoint metadata was
+// recorded, deriving the method and path from the tool name.
+// Tool names look like "maas_<method>_<path_segments_joined_by_underscores>", so
+// this cannot recover paths whose segments contain underscores.
+func (f *Factory) callDerivedEndpoint(ctx context.Context, toolDefinition models.MCPTool, params json.RawMessage) (interface{}, error) {
+	nameParts := strings.SplitN(toolDefinition.Name, "_", 3)
+	if len(nameParts) < 3 || nameParts[0] != "maas" {
+		errMsg := fmt.Sprintf("generic handler: could not parse tool name '%s' into expected format [maas_method_path]", toolDefinition.Name)
+		f.logger.Error(errMsg)
+		return nil, fmt.Errorf("%s", errMsg)
+	}
+
+	httpMethod := strings.ToUpper(nameParts[1])
+	derivedPath := "/" + strings.ReplaceAll(nameParts[2], "_", "/")
+	f.logger.Warnf("GenericToolHandler: Tool '%s' has no endpoint metadata, using approximate path %s", toolDefinition.Name, derivedPath)
+
+	return f.mcpService.CallAPI(ctx, httpMethod, derivedPath, params)
+}
 
 // createMCPServiceHandler creates a handler function for an MCP service method
 func (f *Factory) createMCPServiceHandler(
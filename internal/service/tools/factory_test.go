@@ -40,6 +40,10 @@ func (m *mockToolService) RegisterTool(name string, description string, inputSch
 	return nil
 }
 
+func (m *mockToolService) RegisterToolWithAnnotations(name string, description string, inputSchema interface{}, annotations *models.MCPToolAnnotations, handler ToolHandler) error {
+	return m.RegisterTool(name, description, inputSchema, handler)
+}
+
 func (m *mockToolService) GetTool(name string) (RegisteredTool, bool) {
 	// Not needed for these tests
 	return RegisteredTool{}, false
@@ -328,4 +332,3 @@ func TestFactory_RegisterTools_RegisterToolError(t *testing.T) {
 // The subtask implies testing the behavior, so I will assume I can make readFileFunc effective.
 // I will modify factory.go to use a package-level variable for os.ReadFile.
 
-```
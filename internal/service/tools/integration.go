@@ -7,6 +7,7 @@ import (
 	"github.com/lspecian/maas-mcp-server/internal/logging"
 	"github.com/lspecian/maas-mcp-server/internal/models"
 	"github.com/lspecian/maas-mcp-server/internal/service"
+	"github.com/lspecian/maas-mcp-server/internal/toolprofile"
 	"github.com/lspecian/maas-mcp-server/internal/transport/mcp"
 	"github.com/lspecian/maas-mcp-server/internal/version"
 )
@@ -65,6 +66,7 @@ func (a *MCPToolServiceAdapter) GetServerInfo(ctx context.Context) (*models.MCPD
 			Name:        tool.Name,
 			Description: tool.Description,
 			InputSchema: tool.InputSchema,
+			Annotations: tool.Annotations,
 		})
 	}
 
@@ -118,8 +120,9 @@ func (a *MCPToolServiceAdapter) NegotiateVersion(ctx context.Context, clientVers
 	return "1.0", nil
 }
 
-// CreateToolServiceFromMCPService creates a tool service from an existing MCP service
-func CreateToolServiceFromMCPService(mcpService *service.MCPService, logger *logging.Logger) ToolService {
+// CreateToolServiceFromMCPService creates a tool service from an existing MCP service, with the
+// tools the profile allows. A nil profile allows every tool.
+func CreateToolServiceFromMCPService(mcpService *service.MCPService, logger *logging.Logger, profile *toolprofile.Profile) ToolService {
 	// Create dependencies
 	validator := NewToolValidator()
 	requestMapper := NewRequestMapper()
@@ -139,6 +142,7 @@ func CreateToolServiceFromMCPService(mcpService *service.MCPService, logger *log
 
 	// Register tools
 	factory := NewFactory(mcpService, logger)
+	factory.SetToolProfile(profile)
 	factory.registerTools(toolService)
 
 	return toolService
@@ -69,6 +69,7 @@ func (t *ListMachinesTool) Execute(ctx context.Context, params interface{}) (int
 	}
 
 	// Add storage constraints if provided
+	var storageRequirements []models.SimpleStorageConstraint
 	if listParams.StorageConstraints != nil {
 		// Validate storage constraints
 		if err := listParams.StorageConstraints.Validate(); err != nil {
@@ -89,6 +90,14 @@ func (t *ListMachinesTool) Execute(ctx context.Context, params interface{}) (int
 		if listParams.StorageConstraints.Count > 0 {
 			filters["min_disk_count"] = fmt.Sprintf("%d", listParams.StorageConstraints.Count)
 		}
+
+		storageRequirements = append(storageRequirements, *listParams.StorageConstraints)
+	}
+	for i := range listParams.StorageRequirements {
+		if err := listParams.StorageRequirements[i].Validate(); err != nil {
+			return nil, errors.NewValidationError(fmt.Sprintf("Invalid storage requirement %d: %v", i+1, err), nil)
+		}
+		storageRequirements = append(storageRequirements, listParams.StorageRequirements[i])
 	}
 
 	// Create pagination options if provided
@@ -117,7 +126,7 @@ func (t *ListMachinesTool) Execute(ctx context.Context, params interface{}) (int
 	}
 
 	// Call the service to list machines
-	result, err := t.machineService.ListMachinesPaginated(ctx, filters, paginationOptions)
+	result, err := t.machineService.ListMachinesWithStorage(ctx, filters, paginationOptions, storageRequirements)
 	if err != nil {
 		t.logger.WithError(err).Error("Failed to list machines")
 		return nil, mapServiceError(err)
@@ -149,11 +158,19 @@ func (t *DiscoverMachinesTool) Execute(ctx context.Context, params interface{})
 		return nil, errors.NewValidationError("Invalid parameters type", nil)
 	}
 
+	// Validate storage constraints
+	for i := range discoverParams.StorageConstraints {
+		if err := discoverParams.StorageConstraints[i].Validate(); err != nil {
+			return nil, errors.NewValidationError(fmt.Sprintf("Invalid storage constraint %d: %v", i+1, err), nil)
+		}
+	}
+
 	// Create discovery options
 	options := &models.MachineDiscoveryOptions{
 		CommissioningEnabled: discoverParams.CommissioningEnabled,
 		ScanNetworks:         discoverParams.ScanNetworks,
 		ScanStorage:          discoverParams.ScanStorage,
+		StorageConstraints:   discoverParams.StorageConstraints,
 	}
 
 	// Call the service to discover machines
@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"github.com/lspecian/maas-mcp-server/internal/errors"
+	"github.com/lspecian/maas-mcp-server/internal/models"
 )
 
 // DefaultToolRegistry is the default implementation of ToolRegistry
@@ -26,6 +27,11 @@ func NewToolRegistry(validator ToolValidator) ToolRegistry {
 
 // RegisterTool registers a tool with the registry
 func (r *DefaultToolRegistry) RegisterTool(name string, description string, inputSchema interface{}, handler ToolHandler) error {
+	return r.RegisterToolWithAnnotations(name, description, inputSchema, nil, handler)
+}
+
+// RegisterToolWithAnnotations registers a tool and its MCP tool annotations with the registry
+func (r *DefaultToolRegistry) RegisterToolWithAnnotations(name string, description string, inputSchema interface{}, annotations *models.MCPToolAnnotations, handler ToolHandler) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
@@ -34,14 +40,22 @@ func (r *DefaultToolRegistry) RegisterTool(name string, description string, inpu
 		return fmt.Errorf("tool '%s' already registered", name)
 	}
 
+	// Compile JSON Schema input schemas, so inputs are checked before the handler runs
+	compiled, err := compileInputSchema(inputSchema)
+	if err != nil {
+		return fmt.Errorf("invalid input schema for tool '%s': %w", name, err)
+	}
+
 	// Create tool definition
 	toolDef := ToolDefinition{
 		Schema: ToolSchema{
 			Name:        name,
 			Description: description,
 			InputSchema: inputSchema,
+			Annotations: annotations,
 		},
-		Handler: handler,
+		Handler:     handler,
+		inputSchema: compiled,
 	}
 
 	// Register tool
@@ -82,7 +96,11 @@ func (r *DefaultToolRegistry) ExecuteTool(ctx context.Context, name string, para
 	}
 
 	// Validate parameters
-	if r.validator != nil && tool.Schema.InputSchema != nil {
+	if tool.HasJSONSchema() {
+		if err := tool.ValidateInput(params); err != nil {
+			return nil, err
+		}
+	} else if r.validator != nil && tool.Schema.InputSchema != nil {
 		if err := r.validator.Validate(tool.Schema.InputSchema, params); err != nil {
 			return nil, errors.NewValidationError(fmt.Sprintf("Invalid parameters for tool '%s': %v", name, err), err)
 		}
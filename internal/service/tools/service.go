@@ -8,6 +8,7 @@ import (
 
 	"github.com/lspecian/maas-mcp-server/internal/errors"
 	"github.com/lspecian/maas-mcp-server/internal/logging"
+	"github.com/lspecian/maas-mcp-server/internal/models"
 )
 
 // DefaultToolService is the default implementation of ToolService
@@ -56,7 +57,12 @@ func (s *DefaultToolService) ExecuteTool(ctx context.Context, name string, param
 	}
 
 	// Validate parameters
-	if s.validator != nil && tool.Schema.InputSchema != nil {
+	if tool.HasJSONSchema() {
+		if err := tool.ValidateInput(params); err != nil {
+			s.logger.WithContext(ctx).WithError(err).Error("Tool parameter validation failed")
+			return nil, err
+		}
+	} else if s.validator != nil && tool.Schema.InputSchema != nil {
 		if err := s.validator.Validate(tool.Schema.InputSchema, params); err != nil {
 			s.logger.WithContext(ctx).WithError(err).Error("Tool parameter validation failed")
 			return nil, err
@@ -92,6 +98,11 @@ func (s *DefaultToolService) RegisterTool(name string, description string, input
 	return s.registry.RegisterTool(name, description, inputSchema, handler)
 }
 
+// RegisterToolWithAnnotations registers a tool and its MCP tool annotations with the service
+func (s *DefaultToolService) RegisterToolWithAnnotations(name string, description string, inputSchema interface{}, annotations *models.MCPToolAnnotations, handler ToolHandler) error {
+	return s.registry.RegisterToolWithAnnotations(name, description, inputSchema, annotations, handler)
+}
+
 // CreateToolHandler creates a handler function for a tool
 func CreateToolHandler(
 	requestType reflect.Type,
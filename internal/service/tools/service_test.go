@@ -7,7 +7,9 @@ import (
 	"reflect"
 	"testing"
 
+	ierrors "github.com/lspecian/maas-mcp-server/internal/errors"
 	"github.com/lspecian/maas-mcp-server/internal/logging"
+	"github.com/lspecian/maas-mcp-server/internal/models"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -23,6 +25,11 @@ func (m *MockToolRegistry) RegisterTool(name string, description string, inputSc
 	return args.Error(0)
 }
 
+func (m *MockToolRegistry) RegisterToolWithAnnotations(name string, description string, inputSchema interface{}, annotations *models.MCPToolAnnotations, handler ToolHandler) error {
+	args := m.Called(name, description, inputSchema, annotations, handler)
+	return args.Error(0)
+}
+
 func (m *MockToolRegistry) GetTool(name string) (*ToolDefinition, bool) {
 	args := m.Called(name)
 	if args.Get(0) == nil {
@@ -324,4 +331,63 @@ func TestDefaultToolService_RegisterTool(t *testing.T) {
 		assert.Error(t, err)
 		registry.AssertExpectations(t)
 	})
+
+	t.Run("With annotations", func(t *testing.T) {
+		// Setup
+		annotations := &models.MCPToolAnnotations{DestructiveHint: true}
+		registry.On("RegisterToolWithAnnotations", toolName, toolDescription, toolSchema, annotations, mock.AnythingOfType("ToolHandler")).Return(nil).Once()
+
+		// Execute
+		err := service.RegisterToolWithAnnotations(toolName, toolDescription, toolSchema, annotations, toolHandler)
+
+		// Assert
+		assert.NoError(t, err)
+		registry.AssertExpectations(t)
+	})
+}
+
+func TestDefaultToolRegistry_JSONSchema(t *testing.T) {
+	// The validator only handles request structs; JSON Schemas are compiled by the registry
+	validator := new(MockToolValidator)
+	registry := NewToolRegistry(validator)
+
+	called := false
+	handler := func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		called = true
+		return map[string]string{"result": "success"}, nil
+	}
+	inputSchema := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"system_id"},
+		"properties": map[string]interface{}{
+			"system_id": map[string]interface{}{"type": "string"},
+			"limit":     map[string]interface{}{"type": "integer", "minimum": 1},
+		},
+	}
+	assert.NoError(t, registry.RegisterTool("test_tool", "Test tool", inputSchema, handler))
+
+	t.Run("Valid", func(t *testing.T) {
+		result, err := registry.ExecuteTool(context.Background(), "test_tool", json.RawMessage(`{"system_id": "abc123", "limit": 5}`))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"result": "success"}, result)
+		assert.True(t, called)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		called = false
+		_, err := registry.ExecuteTool(context.Background(), "test_tool", json.RawMessage(`{"limit": 0}`))
+
+		var appErr *ierrors.AppError
+		assert.True(t, errors.As(err, &appErr))
+		assert.Equal(t, ierrors.ErrorCodeInvalidInput, *appErr.Code)
+		assert.Equal(t, map[string]string{"/system_id": "is required", "/limit": "must be at least 1"}, appErr.Details)
+		assert.False(t, called)
+	})
+
+	t.Run("Invalid schema", func(t *testing.T) {
+		err := registry.RegisterTool("bad_tool", "Bad tool", json.RawMessage(`{"type": "map"}`), handler)
+		assert.ErrorContains(t, err, "invalid input schema")
+	})
+
+	validator.AssertNotCalled(t, "Validate", mock.Anything, mock.Anything)
 }
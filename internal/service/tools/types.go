@@ -3,14 +3,22 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	stderrors "errors"
+	"fmt"
 	"reflect"
+
+	"github.com/lspecian/maas-mcp-server/internal/errors"
+	"github.com/lspecian/maas-mcp-server/internal/jsonschema"
+	"github.com/lspecian/maas-mcp-server/internal/models"
 )
 
-// ToolSchema defines the structure of a tool, including its name, description, and input schema
+// ToolSchema defines the structure of a tool, including its name, description, input schema
+// and the annotations that tell clients how it affects MAAS
 type ToolSchema struct {
 	Name        string
 	Description string
 	InputSchema interface{}
+	Annotations *models.MCPToolAnnotations
 }
 
 // ToolHandler is a function that handles a tool call
@@ -20,6 +28,58 @@ type ToolHandler func(ctx context.Context, params json.RawMessage) (interface{},
 type ToolDefinition struct {
 	Schema  ToolSchema
 	Handler ToolHandler
+
+	// inputSchema is Schema.InputSchema compiled at registration, when it is a JSON Schema document
+	// rather than a request struct checked by the ToolValidator
+	inputSchema *jsonschema.Schema
+}
+
+// HasJSONSchema reports whether the tool's input schema is a compiled JSON Schema, which
+// ValidateInput checks, rather than a request struct for the ToolValidator
+func (d *ToolDefinition) HasJSONSchema() bool {
+	return d.inputSchema != nil
+}
+
+// ValidateInput checks params against the tool's compiled JSON Schema. Violations are returned as an
+// invalid input validation error whose details map the JSON pointer of each offending value to
+// what is wrong with it.
+func (d *ToolDefinition) ValidateInput(params json.RawMessage) error {
+	if d.inputSchema == nil {
+		return nil
+	}
+	err := d.inputSchema.Validate(params)
+	if err == nil {
+		return nil
+	}
+
+	appErr := errors.NewValidationErrorWithCode(errors.ErrorCodeInvalidInput, fmt.Sprintf("Invalid parameters for tool '%s'", d.Schema.Name), err)
+	var validationErr *jsonschema.ValidationError
+	if stderrors.As(err, &validationErr) {
+		for _, violation := range validationErr.Violations {
+			message := violation.Message
+			if previous, ok := appErr.Details[violation.Pointer]; ok {
+				message = previous + "; " + message
+			}
+			appErr.WithDetail(violation.Pointer, message)
+		}
+	}
+	return appErr
+}
+
+// compileInputSchema compiles an input schema given as a JSON Schema document: raw JSON, or a map
+// decoded from JSON as in the generated tool definitions. Other schemas, such as request structs,
+// are left to the ToolValidator and compile to nil.
+func compileInputSchema(inputSchema interface{}) (*jsonschema.Schema, error) {
+	switch schema := inputSchema.(type) {
+	case json.RawMessage:
+		return jsonschema.Compile(schema)
+	case []byte:
+		return jsonschema.Compile(schema)
+	case map[string]interface{}:
+		return jsonschema.CompileValue(schema)
+	default:
+		return nil, nil
+	}
 }
 
 // ToolRegistry is a registry of tools
@@ -27,6 +87,9 @@ type ToolRegistry interface {
 	// RegisterTool registers a tool with the registry
 	RegisterTool(name string, description string, inputSchema interface{}, handler ToolHandler) error
 
+	// RegisterToolWithAnnotations registers a tool and its MCP tool annotations with the registry
+	RegisterToolWithAnnotations(name string, description string, inputSchema interface{}, annotations *models.MCPToolAnnotations, handler ToolHandler) error
+
 	// GetTool returns a tool by name
 	GetTool(name string) (*ToolDefinition, bool)
 
@@ -71,4 +134,7 @@ type ToolService interface {
 
 	// RegisterTool registers a tool with the service
 	RegisterTool(name string, description string, inputSchema interface{}, handler ToolHandler) error
+
+	// RegisterToolWithAnnotations registers a tool and its MCP tool annotations with the service
+	RegisterToolWithAnnotations(name string, description string, inputSchema interface{}, annotations *models.MCPToolAnnotations, handler ToolHandler) error
 }
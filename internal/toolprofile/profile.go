@@ -0,0 +1,165 @@
+// Package toolprofile selects the MCP tools offered for a MAAS instance or an authenticated user
+// from the tool profiles in the configuration.
+package toolprofile
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// regexpPrefix marks a pattern as a regular expression rather than a glob
+const regexpPrefix = "re:"
+
+// Tool is what a profile matches a tool on
+type Tool struct {
+	Name string
+	// Method is the HTTP method of the MAAS API call behind the tool, if known
+	Method string
+	Tags   []string
+}
+
+// Profile is a compiled tool profile
+type Profile struct {
+	name    string
+	include []selector
+	exclude []selector
+}
+
+// selector is a compiled types.ToolSelector; an empty list matches every tool
+type selector struct {
+	names   []matcher
+	methods []matcher
+	tags    []matcher
+}
+
+// matcher reports whether a value matches a pattern
+type matcher func(value string) bool
+
+// Full returns the profile that allows every tool
+func Full() *Profile {
+	return &Profile{name: types.FullToolProfile}
+}
+
+// New compiles a tool profile
+func New(name string, cfg types.ToolProfileConfig) (*Profile, error) {
+	profile := &Profile{name: name}
+	var err error
+	if profile.include, err = compileSelectors(cfg.Include); err != nil {
+		return nil, fmt.Errorf("tool profile '%s' include: %w", name, err)
+	}
+	if profile.exclude, err = compileSelectors(cfg.Exclude); err != nil {
+		return nil, fmt.Errorf("tool profile '%s' exclude: %w", name, err)
+	}
+	return profile, nil
+}
+
+// Name returns the name of the profile
+func (p *Profile) Name() string {
+	return p.name
+}
+
+// Allows reports whether the tool is in the profile
+func (p *Profile) Allows(tool Tool) bool {
+	included := len(p.include) == 0
+	for _, s := range p.include {
+		if s.matches(tool) {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+
+	for _, s := range p.exclude {
+		if s.matches(tool) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether every list of the selector that is set matches the tool
+func (s selector) matches(tool Tool) bool {
+	if len(s.names) > 0 && !matchAny(s.names, tool.Name) {
+		return false
+	}
+	if len(s.methods) > 0 && !matchAny(s.methods, strings.ToUpper(tool.Method)) {
+		return false
+	}
+	if len(s.tags) > 0 {
+		for _, tag := range tool.Tags {
+			if matchAny(s.tags, tag) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// matchAny reports whether one of the matchers matches the value
+func matchAny(matchers []matcher, value string) bool {
+	for _, m := range matchers {
+		if m(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileSelectors compiles the patterns of each selector
+func compileSelectors(selectors []types.ToolSelector) ([]selector, error) {
+	compiled := make([]selector, 0, len(selectors))
+	for i, s := range selectors {
+		var c selector
+		var err error
+		if c.names, err = compilePatterns(s.Names, false); err != nil {
+			return nil, fmt.Errorf("selector %d names: %w", i+1, err)
+		}
+		if c.methods, err = compilePatterns(s.Methods, true); err != nil {
+			return nil, fmt.Errorf("selector %d methods: %w", i+1, err)
+		}
+		if c.tags, err = compilePatterns(s.Tags, false); err != nil {
+			return nil, fmt.Errorf("selector %d tags: %w", i+1, err)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// compilePatterns compiles glob and "re:" patterns. Upper-cased patterns match upper-cased values,
+// which makes HTTP methods case-insensitive.
+func compilePatterns(patterns []string, upper bool) ([]matcher, error) {
+	matchers := make([]matcher, 0, len(patterns))
+	for _, pattern := range patterns {
+		if expr, ok := strings.CutPrefix(pattern, regexpPrefix); ok {
+			if upper {
+				expr = "(?i)" + expr
+			}
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression %q: %w", expr, err)
+			}
+			matchers = append(matchers, re.MatchString)
+			continue
+		}
+
+		if upper {
+			pattern = strings.ToUpper(pattern)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+		glob := pattern
+		matchers = append(matchers, func(value string) bool {
+			matched, _ := path.Match(glob, value)
+			return matched
+		})
+	}
+	return matchers, nil
+}
@@ -0,0 +1,116 @@
+package toolprofile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+var (
+	listMachines  = Tool{Name: "maas_get_api_2.0_machines", Method: "GET", Tags: []string{"machines"}}
+	deployMachine = Tool{Name: "maas_post_api_2.0_machines_system_id_op-deploy", Method: "POST", Tags: []string{"machines"}}
+	formatDevice  = Tool{Name: "maas_post_api_2.0_nodes_system_id_blockdevices_id_op-format", Method: "POST", Tags: []string{"nodes"}}
+	listUsers     = Tool{Name: "maas_get_api_2.0_users", Method: "GET", Tags: []string{"users"}}
+	deleteUser    = Tool{Name: "maas_delete_api_2.0_users_username", Method: "DELETE", Tags: []string{"users"}}
+)
+
+func TestProfile_Allows(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  types.ToolProfileConfig
+		allowed []Tool
+		denied  []Tool
+	}{
+		{
+			name:    "empty profile allows everything",
+			allowed: []Tool{listMachines, deployMachine, formatDevice, deleteUser},
+		},
+		{
+			name: "readonly",
+			config: types.ToolProfileConfig{
+				Include: []types.ToolSelector{{Methods: []string{"get"}}},
+				Exclude: []types.ToolSelector{{Tags: []string{"users"}}},
+			},
+			allowed: []Tool{listMachines},
+			denied:  []Tool{deployMachine, listUsers, deleteUser},
+		},
+		{
+			name: "operator",
+			config: types.ToolProfileConfig{
+				Include: []types.ToolSelector{
+					{Methods: []string{"GET"}, Tags: []string{"machines", "nodes"}},
+					{Names: []string{"re:_op-(deploy|release|power_on|power_off)$"}},
+				},
+			},
+			allowed: []Tool{listMachines, deployMachine},
+			denied:  []Tool{formatDevice, listUsers},
+		},
+		{
+			name: "storage-admin",
+			config: types.ToolProfileConfig{
+				Include: []types.ToolSelector{{Names: []string{"*_blockdevices*", "*_partitions*", "*_volume-group*"}}},
+			},
+			allowed: []Tool{formatDevice},
+			denied:  []Tool{listMachines, deployMachine},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := New(tt.name, tt.config)
+			require.NoError(t, err)
+			for _, tool := range tt.allowed {
+				assert.True(t, profile.Allows(tool), "%s should be allowed", tool.Name)
+			}
+			for _, tool := range tt.denied {
+				assert.False(t, profile.Allows(tool), "%s should be denied", tool.Name)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidPattern(t *testing.T) {
+	_, err := New("bad", types.ToolProfileConfig{Include: []types.ToolSelector{{Names: []string{"re:("}}}})
+	assert.ErrorContains(t, err, "tool profile 'bad' include")
+
+	_, err = New("bad", types.ToolProfileConfig{Exclude: []types.ToolSelector{{Tags: []string{"[a-"}}}})
+	assert.ErrorContains(t, err, "tool profile 'bad' exclude")
+}
+
+func TestSet(t *testing.T) {
+	cfg := &types.AppConfig{
+		ToolProfiles: map[string]types.ToolProfileConfig{
+			"operator": {Exclude: []types.ToolSelector{{Tags: []string{"users"}}}},
+			"readonly": {Include: []types.ToolSelector{{Methods: []string{"GET"}}}},
+		},
+		Auth: types.AuthConfig{
+			UserToolProfiles: map[string]string{"alice": "readonly", "bob": "full"},
+		},
+	}
+
+	set, err := NewSet(cfg, &types.MAASInstanceConfig{ToolProfile: "operator"})
+	require.NoError(t, err)
+	assert.Equal(t, "operator", set.Instance().Name())
+
+	// Users without a profile get the instance's tools
+	assert.True(t, set.Allows("", deployMachine))
+	assert.True(t, set.Allows("carol", deployMachine))
+	assert.False(t, set.Allows("carol", listUsers))
+
+	// A user's profile narrows the instance's tools, but cannot widen them
+	assert.True(t, set.Allows("alice", listMachines))
+	assert.False(t, set.Allows("alice", deployMachine))
+	assert.False(t, set.Allows("bob", deleteUser))
+
+	// Without a profile the instance offers every tool
+	set, err = NewSet(cfg, &types.MAASInstanceConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, types.FullToolProfile, set.Instance().Name())
+	assert.True(t, set.Allows("", deleteUser))
+
+	_, err = NewSet(cfg, &types.MAASInstanceConfig{ToolProfile: "missing"})
+	assert.ErrorContains(t, err, "unknown tool profile 'missing'")
+}
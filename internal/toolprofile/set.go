@@ -0,0 +1,66 @@
+package toolprofile
+
+import (
+	"fmt"
+
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// Set resolves the tool profile of a MAAS instance and of the users authenticated against it
+type Set struct {
+	instance *Profile
+	users    map[string]*Profile
+}
+
+// NewSet compiles the tool profiles of the configuration for a MAAS instance. The instance's
+// profile limits the tools offered to everyone, and a user's profile narrows them further for that
+// user. Without profiles every tool is offered.
+func NewSet(cfg *types.AppConfig, instance *types.MAASInstanceConfig) (*Set, error) {
+	profiles := map[string]*Profile{types.FullToolProfile: Full()}
+	for name, profileConfig := range cfg.ToolProfiles {
+		profile, err := New(name, profileConfig)
+		if err != nil {
+			return nil, err
+		}
+		profiles[name] = profile
+	}
+
+	lookup := func(name string) (*Profile, error) {
+		if name == "" {
+			name = types.FullToolProfile
+		}
+		profile, ok := profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown tool profile '%s'", name)
+		}
+		return profile, nil
+	}
+
+	set := &Set{users: make(map[string]*Profile, len(cfg.Auth.UserToolProfiles))}
+	var err error
+	if set.instance, err = lookup(instance.ToolProfile); err != nil {
+		return nil, err
+	}
+	for user, name := range cfg.Auth.UserToolProfiles {
+		if set.users[user], err = lookup(name); err != nil {
+			return nil, fmt.Errorf("user '%s': %w", user, err)
+		}
+	}
+	return set, nil
+}
+
+// Instance returns the tool profile of the MAAS instance
+func (s *Set) Instance() *Profile {
+	return s.instance
+}
+
+// Allows reports whether the tool is offered to a user; username is empty when no user is authenticated
+func (s *Set) Allows(username string, tool Tool) bool {
+	if !s.instance.Allows(tool) {
+		return false
+	}
+	if profile, ok := s.users[username]; ok && username != "" {
+		return profile.Allows(tool)
+	}
+	return true
+}
@@ -18,6 +18,7 @@ const (
 	EventTypeHeartbeat  EventType = "heartbeat"
 	EventTypeLog        EventType = "log"
 	EventTypeStatus     EventType = "status"
+	EventTypeMessage    EventType = "message"
 )
 
 // StatusType represents the status of an operation
@@ -55,6 +56,17 @@ type Event interface {
 
 	// ID returns the event ID (optional)
 	ID() string
+
+	// Subject returns the IDs of the operation and the machine the event is about, either of
+	// which may be empty
+	Subject() (operationID string, machineID string)
+}
+
+// Publisher delivers server-side events, such as machine status changes or the completion of an
+// operation, to the clients that follow them. Services publish through it without knowing which
+// transport, if any, carries the events.
+type Publisher interface {
+	Publish(event Event)
 }
 
 // BaseEvent contains common fields for all events
@@ -67,6 +79,9 @@ type BaseEvent struct {
 
 	// EventID is an optional ID for the event
 	EventID string `json:"event_id,omitempty"`
+
+	// MachineID is the system ID of the machine the event is about, if any
+	MachineID string `json:"machine_id,omitempty"`
 }
 
 // ID returns the event ID
@@ -74,6 +89,11 @@ func (e *BaseEvent) ID() string {
 	return e.EventID
 }
 
+// Subject returns the IDs of the operation and the machine the event is about
+func (e *BaseEvent) Subject() (string, string) {
+	return e.OperationID, e.MachineID
+}
+
 // ProgressEvent represents a progress update for a long-running operation
 type ProgressEvent struct {
 	BaseEvent
@@ -282,6 +302,25 @@ func (e *StatusEvent) ToSSE() (string, []byte, error) {
 	return string(EventTypeStatus), data, nil
 }
 
+// MessageEvent carries a JSON-RPC message sent to an MCP client over an SSE stream. The
+// operation ID is the ID of the stream, so a client that reconnects can resume it.
+type MessageEvent struct {
+	BaseEvent
+
+	// Message is the JSON-RPC message, sent as the event data
+	Message json.RawMessage `json:"message"`
+}
+
+// Type returns the event type
+func (e *MessageEvent) Type() EventType {
+	return EventTypeMessage
+}
+
+// ToSSE converts the event to SSE format; the data is the JSON-RPC message itself
+func (e *MessageEvent) ToSSE() (string, []byte, error) {
+	return string(EventTypeMessage), e.Message, nil
+}
+
 // NewProgressEvent creates a new progress event
 func NewProgressEvent(operationID string, status StatusType, progress float64, message string, details interface{}) *ProgressEvent {
 	return &ProgressEvent{
@@ -364,6 +403,17 @@ func NewStatusEvent(operationID string, previousStatus, currentStatus StatusType
 	}
 }
 
+// NewMessageEvent creates a new message event
+func NewMessageEvent(operationID string, message json.RawMessage) *MessageEvent {
+	return &MessageEvent{
+		BaseEvent: BaseEvent{
+			OperationID: operationID,
+			Timestamp:   time.Now(),
+		},
+		Message: message,
+	}
+}
+
 // WriteSSE writes an event to an http.ResponseWriter in SSE format
 func WriteSSE(w io.Writer, event Event) error {
 	// Get the event type and data
@@ -176,6 +176,31 @@ func TestStatusEvent(t *testing.T) {
 	assert.Equal(t, "pending", jsonData["previous_status"])
 	assert.Equal(t, "in_progress", jsonData["current_status"])
 	assert.Equal(t, "Operation started", jsonData["message"])
+	assert.NotContains(t, jsonData, "machine_id")
+
+	// An event about a machine names it in its data and its subject
+	event.MachineID = "abc123"
+	_, data, err = event.ToSSE()
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"machine_id":"abc123"`)
+	operationID, machineID := event.Subject()
+	assert.Equal(t, "op-123", operationID)
+	assert.Equal(t, "abc123", machineID)
+}
+
+func TestMessageEvent(t *testing.T) {
+	message := json.RawMessage(`{"jsonrpc":"2.0","method":"notifications/progress","params":{"progress":50}}`)
+	event := NewMessageEvent("stream-1", message)
+	event.EventID = "evt-1"
+
+	assert.Equal(t, EventTypeMessage, event.Type())
+	assert.Equal(t, "evt-1", event.ID())
+
+	// The data is the JSON-RPC message, not the event
+	eventType, data, err := event.ToSSE()
+	assert.NoError(t, err)
+	assert.Equal(t, "message", eventType)
+	assert.JSONEq(t, string(message), string(data))
 }
 
 func TestWriteSSE(t *testing.T) {
@@ -246,6 +271,7 @@ func TestEventTypeConstants(t *testing.T) {
 	assert.Equal(t, EventType("heartbeat"), EventTypeHeartbeat)
 	assert.Equal(t, EventType("log"), EventTypeLog)
 	assert.Equal(t, EventType("status"), EventTypeStatus)
+	assert.Equal(t, EventType("message"), EventTypeMessage)
 }
 
 func TestStatusTypeConstants(t *testing.T) {
@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/lspecian/maas-mcp-server/internal/errors"
 	"github.com/lspecian/maas-mcp-server/internal/logging"
+	"github.com/lspecian/maas-mcp-server/internal/service/progress"
 	"github.com/lspecian/maas-mcp-server/internal/transport/mcp/events"
 )
 
@@ -16,6 +17,7 @@ import (
 type Handler struct {
 	service Service
 	logger  *logging.Logger
+	tracker *progress.ProgressTracker
 }
 
 // NewHandler creates a new MCP handler
@@ -27,6 +29,11 @@ func NewHandler(service Service, logger *logging.Logger) *Handler {
 	}
 }
 
+// SetProgressTracker sets the tracker whose operations are streamed by HandleStream
+func (h *Handler) SetProgressTracker(tracker *progress.ProgressTracker) {
+	h.tracker = tracker
+}
+
 // RegisterRoutes registers the MCP routes with the given router
 func (h *Handler) RegisterRoutes(router *gin.Engine, middleware *Middleware) {
 	fmt.Println("MCP: Registering MCP routes")
@@ -129,17 +136,9 @@ func (h *Handler) HandleToolCall(c *gin.Context) {
 	c.JSON(http.StatusOK, NewMCPResponse(result, request.ID))
 }
 
-// HandleStream handles SSE streaming for MCP
+// HandleStream streams the progress events of a tracked operation over SSE. Clients that
+// reconnect with a Last-Event-ID header receive the events they missed.
 func (h *Handler) HandleStream(c *gin.Context) {
-	// Set headers for SSE following best practices
-	c.Writer.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
-	c.Writer.Header().Set("Cache-Control", "no-cache, no-transform")
-	c.Writer.Header().Set("Connection", "keep-alive")
-	c.Writer.Header().Set("Transfer-Encoding", "chunked")
-	c.Writer.Header().Set("X-Accel-Buffering", "no")          // Prevent proxy buffering
-	c.Writer.Header().Set("Access-Control-Allow-Origin", "*") // Allow CORS
-	c.Writer.Header().Set("Pragma", "no-cache")               // For older browsers
-
 	// Get operation ID from query
 	operationID := c.Query("operation_id")
 	if operationID == "" {
@@ -154,74 +153,42 @@ func (h *Handler) HandleStream(c *gin.Context) {
 		return
 	}
 
-	// Create a channel for events
-	eventChan := make(chan events.Event)
-
-	// Create a channel for errors
-	errChan := make(chan error)
-
-	// Create a channel for done signal
-	doneChan := make(chan bool)
+	if h.tracker == nil {
+		c.JSON(http.StatusServiceUnavailable, NewMCPErrorResponse(
+			NewMCPError(
+				ErrorCodeInternalError,
+				"Operation streaming is not enabled",
+				nil,
+			),
+			nil,
+		))
+		return
+	}
 
-	// Create a context with cancel
+	// Subscribing also registers the connection for heartbeats and disconnect handling
 	ctx := c.Request.Context()
+	eventChan, err := h.tracker.SubscribeToEventsWithLastEventID(ctx, operationID, c.GetHeader("Last-Event-ID"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewMCPErrorResponse(
+			NewMCPError(
+				ErrorCodeResourceNotFound,
+				fmt.Sprintf("Operation %s not found", operationID),
+				nil,
+			),
+			nil,
+		))
+		return
+	}
 
-	// Start a goroutine to send events
-	go func() {
-		// Simulate progress events
-		// In a real implementation, this would be connected to the actual operation
-		progress := 0.0
-		for progress < 100.0 {
-			select {
-			case <-ctx.Done():
-				// Context was canceled
-				h.logger.Debug("Context canceled, stopping event generation")
-				return
-			case <-doneChan:
-				// Done signal received
-				h.logger.Debug("Done signal received, stopping event generation")
-				return
-			default:
-				// Send progress event
-				progress += 10.0
-				if progress > 100.0 {
-					progress = 100.0
-				}
-
-				// Create a progress event
-				progressEvent := events.NewProgressEvent(
-					operationID,
-					events.StatusInProgress,
-					progress,
-					fmt.Sprintf("Operation %s is %d%% complete", operationID, int(progress)),
-					nil,
-				)
-
-				eventChan <- progressEvent
-
-				// This line is now handled above
-
-				// Sleep for a bit
-				time.Sleep(1 * time.Second)
-
-				// If progress is 100%, send a completion event
-				if progress >= 100.0 {
-					// Create a completion event
-					completionEvent := events.NewCompletionEvent(
-						operationID,
-						"Operation completed successfully",
-						"Operation completed successfully",
-						float64(time.Since(time.Now().Add(-10*time.Second)).Seconds()), // Simulated duration
-					)
-
-					eventChan <- completionEvent
-
-					// Signal done
-					doneChan <- true
-				}
-			}
-		}
-	}()
+	// Set headers for SSE following best practices
+	c.Writer.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	c.Writer.Header().Set("Cache-Control", "no-cache, no-transform")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("Transfer-Encoding", "chunked")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")          // Prevent proxy buffering
+	c.Writer.Header().Set("Access-Control-Allow-Origin", "*") // Allow CORS
+	c.Writer.Header().Set("Pragma", "no-cache")               // For older browsers
+	c.Writer.WriteHeader(http.StatusOK)
 
 	// Flush headers immediately to establish the SSE connection
 	if err := writeSSEFlush(c.Writer); err != nil {
@@ -236,39 +203,42 @@ func (h *Handler) HandleStream(c *gin.Context) {
 			// Client disconnected
 			h.logger.Debug("Client disconnected, closing SSE stream")
 			return
-		case <-doneChan:
-			// Operation completed
-			h.logger.Debug("Operation completed, closing SSE stream")
-			return
-		case err := <-errChan:
-			// Error occurred
-			h.logger.WithError(err).Error("Error in SSE stream")
-
-			// Create and send error event
-			errorEvent := events.NewErrorEvent(
-				operationID,
-				err.Error(),
-				500, // Generic error code
-				nil,
-				false,
-			)
-
-			if err := events.WriteSSE(c.Writer, errorEvent); err != nil {
-				h.logger.WithError(err).Error("Failed to write error event")
+		case event, ok := <-eventChan:
+			if !ok {
+				// Operation was cleaned up or cancelled
+				h.logger.Debug("Event channel closed, closing SSE stream")
+				return
 			}
 
-			// Close connection
-			return
-		case event := <-eventChan:
 			// Write the event with error handling
 			if err := events.WriteSSE(c.Writer, event); err != nil {
 				h.logger.WithError(err).Error("Failed to write SSE event")
 				return
 			}
+			if err := writeSSEFlush(c.Writer); err != nil {
+				h.logger.WithError(err).Error("Failed to flush SSE event")
+				return
+			}
+
+			if isFinalEvent(event) {
+				h.logger.Debug("Operation finished, closing SSE stream")
+				return
+			}
 		}
 	}
 }
 
+// isFinalEvent reports whether no more events will follow for the operation
+func isFinalEvent(event events.Event) bool {
+	switch e := event.(type) {
+	case *events.CompletionEvent, *events.ErrorEvent:
+		return true
+	case *events.StatusEvent:
+		return e.CurrentStatus == events.StatusCancelled
+	}
+	return false
+}
+
 // writeSSEEvent is now deprecated in favor of events.WriteSSE
 // It's kept for backward compatibility
 func writeSSEEvent(w http.ResponseWriter, eventType string, data interface{}, id string) error {
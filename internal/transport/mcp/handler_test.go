@@ -12,6 +12,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/lspecian/maas-mcp-server/internal/logging"
 	"github.com/lspecian/maas-mcp-server/internal/models"
+	"github.com/lspecian/maas-mcp-server/internal/service/progress"
 	"github.com/lspecian/maas-mcp-server/internal/transport/mcp/events"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -274,40 +275,34 @@ func TestHandleStream(t *testing.T) {
 		Format: logging.LogFormatJSON,
 	})
 
+	// Create a tracker with an operation that makes progress and completes
+	tracker := progress.NewProgressTracker(logger)
+	reporter, _, err := tracker.StartOperation("test-op-123")
+	assert.NoError(t, err)
+	assert.NoError(t, reporter.ReportProgress(50, "Machine 1 is Deploying", nil))
+	assert.NoError(t, reporter.ReportCompletion(nil, "Machine 1 is Deployed"))
+
 	// Create a handler
 	handler := NewHandler(mockService, logger)
+	handler.SetProgressTracker(tracker)
 
 	// Create a router
 	router := gin.New()
 	router.GET("/mcp/stream", handler.HandleStream)
 
-	// Create a context with cancel for the request
-	ctx, cancel := context.WithCancel(context.Background())
+	// Create a context with a timeout so a stream that never finishes fails the test
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// Create a request with operation_id and the cancellable context
+	// Create a request with operation_id
 	req, _ := http.NewRequestWithContext(ctx, "GET", "/mcp/stream?operation_id=test-op-123", nil)
 
 	// Create a response recorder that captures the response
 	w := httptest.NewRecorder()
 
-	// Create a channel to signal when the test should stop
-	done := make(chan bool)
-
-	// Start a goroutine to serve the request
-	go func() {
-		router.ServeHTTP(w, req)
-		done <- true
-	}()
-
-	// Wait for a short time to allow some events to be sent
-	time.Sleep(3 * time.Second)
-
-	// Cancel the request context to stop the stream
-	cancel()
-
-	// Wait for the handler to finish
-	<-done
+	// The stream closes on its own after the completion event
+	router.ServeHTTP(w, req)
+	assert.NoError(t, ctx.Err())
 
 	// Check the response status code
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -323,10 +318,43 @@ func TestHandleStream(t *testing.T) {
 	// Check the response body for SSE format
 	responseBody := w.Body.String()
 	assert.Contains(t, responseBody, "event: progress")
+	assert.Contains(t, responseBody, "event: completion")
 	assert.Contains(t, responseBody, "data: {")
 	assert.Contains(t, responseBody, "\"operation_id\":\"test-op-123\"")
 }
 
+func TestHandleStreamUnknownOperation(t *testing.T) {
+	// Set up Gin in test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create a logger
+	logger, _ := logging.NewEnhancedLogger(logging.LoggerConfig{
+		Level:  "info",
+		Format: logging.LogFormatJSON,
+	})
+
+	// Create a handler
+	handler := NewHandler(new(MockService), logger)
+	handler.SetProgressTracker(progress.NewProgressTracker(logger))
+
+	// Create a router
+	router := gin.New()
+	router.GET("/mcp/stream", handler.HandleStream)
+
+	// Create a request for an operation that was never started
+	req, _ := http.NewRequest("GET", "/mcp/stream?operation_id=unknown", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Check the response
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var response MCPResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotNil(t, response.Error)
+	assert.Equal(t, ErrorCodeResourceNotFound, response.Error.Code)
+}
+
 func TestHandleStreamMissingOperationID(t *testing.T) {
 	// Set up Gin in test mode
 	gin.SetMode(gin.TestMode)
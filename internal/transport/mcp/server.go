@@ -6,6 +6,7 @@ import (
 	"github.com/lspecian/maas-mcp-server/internal/logging"
 	"github.com/lspecian/maas-mcp-server/internal/models"
 	"github.com/lspecian/maas-mcp-server/internal/service"
+	"github.com/lspecian/maas-mcp-server/internal/service/progress"
 	"github.com/lspecian/maas-mcp-server/internal/transport"
 )
 
@@ -33,8 +34,13 @@ func NewServer(
 	// Create middleware
 	middleware := NewMiddleware(logger, config, authHandler)
 
+	// The handler streams the operations of the tracker to SSE clients. Asynchronous machine
+	// operations run on the pkg/mcp server, which publishes them on its own SSE endpoint.
+	tracker := progress.NewProgressTracker(logger)
+
 	// Create handler
 	handler := NewHandler(service, logger)
+	handler.SetProgressTracker(tracker)
 
 	return &Server{
 		handler:    handler,
@@ -58,6 +58,8 @@ func (s *ServiceImpl) ExecuteTool(ctx context.Context, toolName string, rawParam
 		return s.executeMaasDeployMachine(ctx, rawParams)
 	case "maas_release_machine":
 		return s.executeMaasReleaseMachine(ctx, rawParams)
+	case "maas_commission_machine":
+		return s.executeMaasCommissionMachine(ctx, rawParams)
 	case "maas_get_machine_power_state":
 		return s.executeMaasGetMachinePowerState(ctx, rawParams)
 	case "maas_power_on_machine":
@@ -315,16 +317,31 @@ func (s *ServiceImpl) executeMaasAllocateMachine(ctx context.Context, rawParams
 		return nil, errors.NewValidationError("Invalid parameters for maas_allocate_machine: "+err.Error(), err)
 	}
 
-	// Convert models.AllocateMachineRequest to pkg/mcp.AllocateMachineRequest
-	// This is a temporary solution until we unify the models
-	pkgRequest := mcp.AllocateMachineRequest{
-		// Map fields as needed
-		Tags: request.Tags,
-		// Other fields not available in our model
+	// Check the storage constraints here so that syntax errors report where the problem is
+	if _, err := models.NormalizeMAASStorageConstraints(request.Storage, request.StorageConstraints); err != nil {
+		return nil, storageSyntaxValidationError(err)
 	}
 
 	// Execute the service method
-	return s.mcpService.AllocateMachine(ctx, pkgRequest)
+	return s.mcpService.AllocateMachine(ctx, &request)
+}
+
+// storageSyntaxValidationError converts a storage constraint syntax error into a validation error
+// whose details locate the offending disk entry.
+func storageSyntaxValidationError(err error) error {
+	appErr := errors.NewValidationError("Invalid storage constraints", err)
+	if syntaxErr, ok := err.(*models.StorageSyntaxError); ok {
+		appErr.WithDetail("field", syntaxErr.Field).
+			WithDetail("entry", fmt.Sprint(syntaxErr.Entry+1)).
+			WithDetail("reason", syntaxErr.Reason)
+		if syntaxErr.Value != "" {
+			appErr.WithDetail("value", syntaxErr.Value)
+		}
+		if syntaxErr.Offset >= 0 {
+			appErr.WithDetail("offset", fmt.Sprint(syntaxErr.Offset))
+		}
+	}
+	return appErr
 }
 
 func (s *ServiceImpl) executeMaasDeployMachine(ctx context.Context, rawParams json.RawMessage) (interface{}, error) {
@@ -342,9 +359,10 @@ func (s *ServiceImpl) executeMaasDeployMachine(ctx context.Context, rawParams js
 	// This is a temporary solution until we unify the models
 	pkgRequest := mcp.DeployMachineRequest{
 		SystemID:     request.SystemID,
-		DistroSeries: "", // Not available in our model
+		DistroSeries: request.DistroSeries,
 		UserData:     "", // Not available in our model
-		HWEKernel:    "", // Not available in our model
+		HWEKernel:    request.Kernel,
+		Async:        request.Async,
 	}
 
 	// Execute the service method
@@ -366,14 +384,18 @@ func (s *ServiceImpl) executeMaasReleaseMachine(ctx context.Context, rawParams j
 	// This is a temporary solution until we unify the models
 	pkgRequest := mcp.ReleaseMachineRequest{
 		SystemID: request.SystemID,
-		Comment:  "", // Not available in our model
+		Comment:  request.Comment,
+		Async:    request.Async,
 	}
 
 	// Execute the service method
-	// Assuming the first return value is not explicitly needed here beyond error checking.
-	if _, err := s.mcpService.ReleaseMachine(ctx, pkgRequest); err != nil {
+	result, err := s.mcpService.ReleaseMachine(ctx, pkgRequest)
+	if err != nil {
 		return nil, err
 	}
+	if request.Async {
+		return result, nil
+	}
 
 	return map[string]interface{}{
 		"success":   true,
@@ -382,6 +404,29 @@ func (s *ServiceImpl) executeMaasReleaseMachine(ctx context.Context, rawParams j
 	}, nil
 }
 
+func (s *ServiceImpl) executeMaasCommissionMachine(ctx context.Context, rawParams json.RawMessage) (interface{}, error) {
+	var request models.CommissionMachineRequest
+	if err := json.Unmarshal(rawParams, &request); err != nil {
+		return nil, errors.NewValidationError("Invalid parameters for maas_commission_machine: "+err.Error(), err)
+	}
+
+	// Validate required fields
+	if request.SystemID == "" {
+		return nil, errors.NewValidationError("system_id is required", nil)
+	}
+
+	pkgRequest := mcp.CommissionMachineRequest{
+		SystemID:       request.SystemID,
+		EnableSSH:      request.EnableSSH,
+		SkipNetworking: request.SkipNetworking,
+		SkipStorage:    request.SkipStorage,
+		Async:          request.Async,
+	}
+
+	// Execute the service method
+	return s.mcpService.CommissionMachine(ctx, pkgRequest)
+}
+
 func (s *ServiceImpl) executeMaasGetMachinePowerState(ctx context.Context, rawParams json.RawMessage) (interface{}, error) {
 	var request models.GetMachinePowerStateRequest
 	if err := json.Unmarshal(rawParams, &request); err != nil {
@@ -0,0 +1,202 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// defaultBatchConcurrency is the number of requests in a batch executed at once over HTTP
+const defaultBatchConcurrency = 8
+
+// isBatch reports whether a JSON-RPC payload is a batch, that is a JSON array
+func isBatch(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// isNotification reports whether a request in a batch is a notification. A notification has no
+// "id" member and gets no response, not even an error.
+func isNotification(element json.RawMessage) bool {
+	var message struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(element, &message); err != nil {
+		return false
+	}
+	return message.ID == nil
+}
+
+// runBatch calls handle for every element of a batch, running at most concurrency at once, and
+// returns once all of them have finished
+func runBatch(ctx context.Context, elements []json.RawMessage, concurrency int, handle func(element json.RawMessage)) {
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	slots := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for _, element := range elements {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			// Requests that have not started are dropped; the batch is going nowhere
+			wg.Wait()
+			return
+		}
+
+		wg.Add(1)
+		go func(element json.RawMessage) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			handle(element)
+		}(element)
+	}
+	wg.Wait()
+}
+
+// batchResponses collects the responses to the requests of a stdio batch
+type batchResponses struct {
+	mu        sync.Mutex
+	responses []map[string]interface{}
+}
+
+// writeResponse adds a response to the batch
+func (b *batchResponses) writeResponse(response map[string]interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.responses = append(b.responses, response)
+}
+
+// discardResponses drops the responses to notifications
+type discardResponses struct{}
+
+// writeResponse discards the response
+func (discardResponses) writeResponse(response map[string]interface{}) {}
+
+// processBatch handles a JSON-RPC batch read from stdin. Each request takes an outstanding and a
+// worker slot, as a request on its own line does, so a batch cannot get past the session limits;
+// requests over the outstanding ceiling are answered with an error. The responses are written as
+// one array once all requests have finished; notifications add nothing to it, and a batch of only
+// notifications gets no response at all.
+func (s *StdioServer) processBatch(ctx context.Context, line string) error {
+	var elements []json.RawMessage
+	if err := json.Unmarshal([]byte(line), &elements); err != nil {
+		s.logger.WithError(err).Error("Failed to parse JSON-RPC batch")
+		s.writeError(s, JSONRPCID(""), -32700, "Parse error", err.Error())
+		return err
+	}
+	if len(elements) == 0 {
+		s.writeError(s, JSONRPCID(""), -32600, "Invalid Request", "batch must not be empty")
+		return fmt.Errorf("empty batch")
+	}
+
+	s.logger.WithField("requests", len(elements)).Info("Processing JSON-RPC batch")
+
+	batch := &batchResponses{}
+	var wg sync.WaitGroup
+	for _, element := range elements {
+		var request JSONRPCRequest
+		if err := json.Unmarshal(element, &request); err != nil || request.Method == "" {
+			detail := "request must be an object with a method"
+			if err != nil {
+				detail = err.Error()
+			}
+			s.writeError(batch, JSONRPCID(""), -32600, "Invalid Request", detail)
+			continue
+		}
+
+		var out responseWriter = batch
+		if isNotification(element) {
+			out = discardResponses{}
+		}
+
+		// Cancellations take no slots, as they may be for a request waiting on one
+		if request.Method == "notifications/cancelled" {
+			if err := s.processRequest(ctx, request, out); err != nil {
+				s.logger.WithError(err).Error("Error processing cancellation")
+			}
+			continue
+		}
+
+		if !s.acquireOutstanding(out, request.Method, request.ID) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(request JSONRPCRequest, out responseWriter) {
+			defer wg.Done()
+			defer s.releaseOutstanding()
+
+			// Requests that have not started when ctx is done are dropped
+			if !s.acquireWorker(ctx) {
+				return
+			}
+			defer s.releaseWorker()
+
+			if err := s.processRequest(ctx, request, out); err != nil {
+				s.logger.WithError(err).WithField("method", request.Method).Error("Error processing batch request")
+			}
+		}(request, out)
+	}
+	wg.Wait()
+
+	if len(batch.responses) == 0 {
+		return nil
+	}
+	s.writeMessage(batch.responses)
+	return nil
+}
+
+// processBatch handles a JSON-RPC batch posted over HTTP. It returns the responses to send back, or
+// nil when the batch held only notifications.
+func (s *Server) processBatch(ctx context.Context, sessionID string, body []byte) (int, interface{}) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(body, &elements); err != nil {
+		return http.StatusBadRequest, JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error:   &JSONRPCError{Code: -32700, Message: "Parse error", Data: err.Error()},
+			ID:      JSONRPCID(""),
+		}
+	}
+	if len(elements) == 0 {
+		return http.StatusBadRequest, JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error:   &JSONRPCError{Code: -32600, Message: "Invalid Request", Data: "batch must not be empty"},
+			ID:      JSONRPCID(""),
+		}
+	}
+
+	var mu sync.Mutex
+	responses := make([]JSONRPCResponse, 0, len(elements))
+	runBatch(ctx, elements, defaultBatchConcurrency, func(element json.RawMessage) {
+		var response *JSONRPCResponse
+		var request JSONRPCRequest
+		if err := json.Unmarshal(element, &request); err != nil {
+			response = &JSONRPCResponse{
+				JSONRPC: "2.0",
+				Error:   &JSONRPCError{Code: -32600, Message: "Invalid Request", Data: err.Error()},
+				ID:      JSONRPCID(""),
+			}
+		} else {
+			_, response = s.processRequest(ctx, sessionID, request)
+			if isNotification(element) {
+				response = nil
+			}
+		}
+
+		if response != nil {
+			mu.Lock()
+			responses = append(responses, *response)
+			mu.Unlock()
+		}
+	})
+
+	if len(responses) == 0 {
+		return http.StatusAccepted, nil
+	}
+	return http.StatusOK, responses
+}
@@ -0,0 +1,276 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// FindToolsName is the name of the tool that searches the tool catalogue
+	FindToolsName = "maas_find_tools"
+
+	// InvokeToolName is the name of the tool that runs any tool of the catalogue
+	InvokeToolName = "maas_invoke_tool"
+)
+
+const (
+	// defaultFindLimit and maxFindLimit bound the number of tools maas_find_tools returns
+	defaultFindLimit = 10
+	maxFindLimit     = 50
+
+	// Weights of a keyword found in a tool's name, description and parameter docs
+	nameWeight        = 5
+	descriptionWeight = 2
+	parameterWeight   = 1
+)
+
+// FindToolsInput represents the input for the maas_find_tools tool
+type FindToolsInput struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+// FoundTool is a tool returned by maas_find_tools, with the schema needed to call it
+type FoundTool struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	InputSchema json.RawMessage  `json:"inputSchema"`
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+	// Listed tells whether the tool is in tools/list and can be called directly; the others
+	// are called through maas_invoke_tool
+	Listed bool `json:"listed"`
+	Score  int  `json:"score"`
+}
+
+// FindToolsOutput represents the output for the maas_find_tools tool
+type FindToolsOutput struct {
+	Tools []FoundTool `json:"tools"`
+	// Total is the number of matching tools, of which at most the limit are returned
+	Total int `json:"total"`
+}
+
+// InvokeToolInput represents the input for the maas_invoke_tool tool
+type InvokeToolInput struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+var findToolsSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["query"],
+	"properties": {
+		"query": {
+			"type": "string",
+			"description": "Keywords to look for in tool names, descriptions and parameters, e.g. \"vlan dhcp\""
+		},
+		"limit": {
+			"type": "integer",
+			"minimum": 1,
+			"maximum": 50,
+			"description": "Maximum number of tools to return (default 10)"
+		}
+	}
+}`)
+
+var invokeToolSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["name"],
+	"properties": {
+		"name": {
+			"type": "string",
+			"description": "Name of the tool to run, as returned by maas_find_tools"
+		},
+		"arguments": {
+			"type": "object",
+			"description": "Arguments of the tool, matching its input schema"
+		}
+	}
+}`)
+
+// RegisterCatalogTools registers maas_find_tools and maas_invoke_tool. Together they make every tool
+// offered to the caller reachable, including deferred tools that tools/list leaves out.
+func (r *Registry) RegisterCatalogTools() error {
+	err := r.RegisterTool(ToolInfo{
+		Name:        FindToolsName,
+		Description: "Search all MAAS tools, including those not listed, by keyword and return their input schemas",
+		InputSchema: findToolsSchema,
+		Annotations: &ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+		Handler:     r.findTools,
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.RegisterTool(ToolInfo{
+		Name:        InvokeToolName,
+		Description: "Run any MAAS tool by name, such as one found with maas_find_tools, after checking its arguments",
+		InputSchema: invokeToolSchema,
+		Handler:     r.invokeTool,
+	})
+}
+
+// findTools implements maas_find_tools
+func (r *Registry) findTools(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	var params FindToolsInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	keywords := searchTerms(params.Query)
+	if len(keywords) == 0 {
+		return nil, fmt.Errorf("query must contain at least one keyword")
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultFindLimit
+	} else if limit > maxFindLimit {
+		limit = maxFindLimit
+	}
+
+	found := make([]FoundTool, 0)
+	for _, tool := range r.ListAllToolsFor(userFromContext(ctx)) {
+		if tool.Name == FindToolsName || tool.Name == InvokeToolName {
+			continue
+		}
+		score := scoreTool(tool, keywords)
+		if score == 0 {
+			continue
+		}
+		found = append(found, FoundTool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: tool.InputSchema,
+			Annotations: tool.Annotations,
+			Listed:      !tool.Deferred,
+			Score:       score,
+		})
+	}
+
+	// Best matches first; among equals, listed tools and then shorter names, which tend to be
+	// the general form of an endpoint
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Score != found[j].Score {
+			return found[i].Score > found[j].Score
+		}
+		if found[i].Listed != found[j].Listed {
+			return found[i].Listed
+		}
+		if len(found[i].Name) != len(found[j].Name) {
+			return len(found[i].Name) < len(found[j].Name)
+		}
+		return found[i].Name < found[j].Name
+	})
+
+	output := FindToolsOutput{Tools: found, Total: len(found)}
+	if len(found) > limit {
+		output.Tools = found[:limit]
+	}
+
+	result, err := json.Marshal(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return result, nil
+}
+
+// invokeTool implements maas_invoke_tool
+func (r *Registry) invokeTool(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	var params InvokeToolInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+	if params.Name == "" {
+		return nil, fmt.Errorf("tool name is required")
+	}
+	if params.Name == InvokeToolName {
+		return nil, fmt.Errorf("%s cannot invoke itself", InvokeToolName)
+	}
+
+	tool, ok := r.GetToolFor(userFromContext(ctx), params.Name)
+	if !ok {
+		return nil, fmt.Errorf("tool %s not found", params.Name)
+	}
+
+	arguments := params.Arguments
+	if len(arguments) == 0 || string(arguments) == "null" {
+		arguments = json.RawMessage(`{}`)
+	}
+	if err := tool.ValidateInput(arguments); err != nil {
+		return nil, fmt.Errorf("invalid arguments for tool %s: %w", tool.Name, err)
+	}
+
+	return tool.Handler(ctx, arguments)
+}
+
+// scoreTool adds up the weights of the places each keyword appears in a tool. A tool that lacks
+// any of the keywords scores zero.
+func scoreTool(tool ToolInfo, keywords []string) int {
+	name := strings.Join(searchTerms(tool.Name), " ")
+	description := strings.ToLower(tool.Description)
+	parameters := strings.ToLower(strings.Join(schemaDocs(tool.InputSchema), " "))
+
+	total := 0
+	for _, keyword := range keywords {
+		score := 0
+		if strings.Contains(name, keyword) {
+			score += nameWeight
+		}
+		if strings.Contains(description, keyword) {
+			score += descriptionWeight
+		}
+		if strings.Contains(parameters, keyword) {
+			score += parameterWeight
+		}
+		if score == 0 {
+			return 0
+		}
+		total += score
+	}
+	return total
+}
+
+// searchTerms splits text into lower-case words, so "list_machines" and "list machines" match alike
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+}
+
+// schemaDocs returns the property names and descriptions of a JSON schema, including nested ones
+func schemaDocs(schema json.RawMessage) []string {
+	var root interface{}
+	if len(schema) == 0 || json.Unmarshal(schema, &root) != nil {
+		return nil
+	}
+
+	var docs []string
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch value := node.(type) {
+		case map[string]interface{}:
+			if description, ok := value["description"].(string); ok {
+				docs = append(docs, description)
+			}
+			if properties, ok := value["properties"].(map[string]interface{}); ok {
+				for name, property := range properties {
+					docs = append(docs, name)
+					walk(property)
+				}
+			}
+			if items, ok := value["items"]; ok {
+				walk(items)
+			}
+		case []interface{}:
+			for _, item := range value {
+				walk(item)
+			}
+		}
+	}
+	walk(root)
+	return docs
+}
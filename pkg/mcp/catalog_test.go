@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/maas-mcp-server/internal/jsonschema"
+)
+
+// setupCatalogRegistry registers a listed machine tool, two deferred VLAN tools and the catalog tools
+func setupCatalogRegistry(t *testing.T) *Registry {
+	registry := NewRegistry()
+	echo := func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+		return input, nil
+	}
+
+	require.NoError(t, registry.RegisterTool(ToolInfo{
+		Name:        "maas_list_machines",
+		Description: "List machines with optional filtering",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"filters":{"type":"object"}}}`),
+		Handler:     echo,
+	}))
+	require.NoError(t, registry.RegisterTool(ToolInfo{
+		Name:        "maas_get_api_2.0_vlans",
+		Description: "List VLANs of a fabric",
+		InputSchema: json.RawMessage(`{"type":"object","required":["fabric_id"],"properties":{"fabric_id":{"type":"integer","description":"ID of the fabric"}}}`),
+		Handler:     echo,
+		Deferred:    true,
+	}))
+	require.NoError(t, registry.RegisterTool(ToolInfo{
+		Name:        "maas_put_api_2.0_vlans_vid",
+		Description: "Update a VLAN",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"dhcp_on":{"type":"boolean","description":"Whether MAAS serves DHCP on the VLAN"}}}`),
+		Handler:     echo,
+		Deferred:    true,
+	}))
+	require.NoError(t, registry.RegisterCatalogTools())
+	return registry
+}
+
+// findTools runs maas_find_tools as user
+func findTools(t *testing.T, registry *Registry, user string, input string) FindToolsOutput {
+	tool, ok := registry.GetTool(FindToolsName)
+	require.True(t, ok)
+	result, err := tool.Handler(withUser(context.Background(), user), json.RawMessage(input))
+	require.NoError(t, err)
+
+	var output FindToolsOutput
+	require.NoError(t, json.Unmarshal(result, &output))
+	return output
+}
+
+func TestListToolsFor_LeavesOutDeferredTools(t *testing.T) {
+	registry := setupCatalogRegistry(t)
+
+	var listed []string
+	for _, tool := range registry.ListToolsFor("") {
+		listed = append(listed, tool.Name)
+	}
+	assert.ElementsMatch(t, []string{"maas_list_machines", FindToolsName, InvokeToolName}, listed)
+	assert.Len(t, registry.ListAllToolsFor(""), 5)
+}
+
+func TestFindTools(t *testing.T) {
+	registry := setupCatalogRegistry(t)
+
+	output := findTools(t, registry, "", `{"query":"vlan"}`)
+	require.Equal(t, 2, output.Total)
+	// The update tool also mentions VLANs in its parameters, so it ranks first
+	assert.Equal(t, "maas_put_api_2.0_vlans_vid", output.Tools[0].Name)
+	assert.Greater(t, output.Tools[0].Score, output.Tools[1].Score)
+	assert.Equal(t, "maas_get_api_2.0_vlans", output.Tools[1].Name)
+	assert.False(t, output.Tools[1].Listed)
+	assert.JSONEq(t, `{"type":"object","required":["fabric_id"],"properties":{"fabric_id":{"type":"integer","description":"ID of the fabric"}}}`, string(output.Tools[1].InputSchema))
+
+	// Every keyword must match, in the name, the description or the parameters
+	output = findTools(t, registry, "", `{"query":"VLAN dhcp"}`)
+	require.Equal(t, 1, output.Total)
+	assert.Equal(t, "maas_put_api_2.0_vlans_vid", output.Tools[0].Name)
+
+	output = findTools(t, registry, "", `{"query":"list_machines"}`)
+	require.Equal(t, 1, output.Total)
+	assert.True(t, output.Tools[0].Listed)
+
+	output = findTools(t, registry, "", `{"query":"vlan","limit":1}`)
+	assert.Equal(t, 2, output.Total)
+	assert.Len(t, output.Tools, 1)
+
+	output = findTools(t, registry, "", `{"query":"find tools"}`)
+	assert.Zero(t, output.Total, "the catalog tools do not find themselves")
+
+	tool, _ := registry.GetTool(FindToolsName)
+	_, err := tool.Handler(context.Background(), json.RawMessage(`{"query":" - "}`))
+	assert.Error(t, err)
+}
+
+func TestFindTools_Filtered(t *testing.T) {
+	registry := setupCatalogRegistry(t)
+	registry.SetToolFilter(func(user string, tool ToolInfo) bool {
+		return user != "alice" || tool.Name != "maas_put_api_2.0_vlans_vid"
+	})
+
+	assert.Equal(t, 2, findTools(t, registry, "bob", `{"query":"vlan"}`).Total)
+	assert.Equal(t, 1, findTools(t, registry, "alice", `{"query":"vlan"}`).Total)
+
+	tool, _ := registry.GetTool(InvokeToolName)
+	_, err := tool.Handler(withUser(context.Background(), "alice"), json.RawMessage(`{"name":"maas_put_api_2.0_vlans_vid"}`))
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestInvokeTool(t *testing.T) {
+	registry := setupCatalogRegistry(t)
+	tool, ok := registry.GetTool(InvokeToolName)
+	require.True(t, ok)
+	invoke := func(input string) (json.RawMessage, error) {
+		return tool.Handler(context.Background(), json.RawMessage(input))
+	}
+
+	result, err := invoke(`{"name":"maas_get_api_2.0_vlans","arguments":{"fabric_id":2}}`)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"fabric_id":2}`, string(result))
+
+	result, err = invoke(`{"name":"maas_put_api_2.0_vlans_vid"}`)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(result), "missing arguments are an empty object")
+
+	_, err = invoke(`{"name":"maas_get_api_2.0_vlans","arguments":{}}`)
+	assert.ErrorContains(t, err, "/fabric_id: is required")
+	var validationErr *jsonschema.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	_, err = invoke(`{"name":"maas_get_api_2.0_vlans","arguments":[2]}`)
+	assert.ErrorContains(t, err, "(root): must be an object, not array")
+
+	_, err = invoke(`{"name":"maas_get_api_2.0_nothing"}`)
+	assert.ErrorContains(t, err, "not found")
+
+	_, err = invoke(`{"name":"maas_invoke_tool","arguments":{"name":"maas_list_machines"}}`)
+	assert.Error(t, err)
+
+	_, err = invoke(`{}`)
+	assert.ErrorContains(t, err, "tool name is required")
+}
@@ -5,14 +5,22 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 
 	"github.com/lspecian/maas-mcp-server/internal/config"
 	"github.com/lspecian/maas-mcp-server/internal/logging"
 	"github.com/lspecian/maas-mcp-server/internal/maas"
+	"github.com/lspecian/maas-mcp-server/internal/maasclient"
+	"github.com/lspecian/maas-mcp-server/internal/models"
 	"github.com/lspecian/maas-mcp-server/internal/repository/machine"
+	"github.com/lspecian/maas-mcp-server/internal/service/ipam"
 	machineservice "github.com/lspecian/maas-mcp-server/internal/service/machine"
+	"github.com/lspecian/maas-mcp-server/internal/service/progress"
+	"github.com/lspecian/maas-mcp-server/internal/toolprofile"
 	"github.com/lspecian/maas-mcp-server/pkg/mcp"
+	"github.com/lspecian/maas-mcp-server/pkg/mcp/prompts"
+	"github.com/lspecian/maas-mcp-server/pkg/mcp/resources"
 	"github.com/lspecian/maas-mcp-server/pkg/mcp/tools"
 )
 
@@ -48,10 +56,30 @@ func main() {
 
 	// Initialize MCP tools
 	machineTools := tools.NewMachineTools(machineService)
+	zoneTools := tools.NewZoneTools(maasClientWrapper)
+	resourcePoolTools := tools.NewResourcePoolTools(maasClientWrapper)
+	networkTools := tools.NewNetworkTools(maasClientWrapper)
+	ipamTools := tools.NewIPAMTools(ipam.NewService(maasClientWrapper, logger))
+
+	// Audit the IP reservations this server makes in MAAS_IP_RESERVATIONS_FILE
+	reservationStore, err := ipam.NewDefaultReservationStore()
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to open the IP reservation audit")
+	}
+	ipAddressTools := tools.NewIPAddressTools(ipam.NewReservations(maasClientWrapper, reservationStore, logger))
 
 	// Create MCP registry
 	registry := mcp.NewRegistry()
 
+	// Offer each user only the tools of the instance's and their own tool profile
+	profiles, err := toolprofile.NewSet(cfg, maasInstance)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load tool profiles")
+	}
+	registry.SetToolFilter(func(user string, tool mcp.ToolInfo) bool {
+		return profiles.Allows(user, toolprofile.Tool{Name: tool.Name, Method: tool.Method, Tags: tool.Tags})
+	})
+
 	// Register MCP tools
 	listMachinesSchema := json.RawMessage(`{
 		"type": "object",
@@ -95,46 +123,610 @@ func main() {
 		}
 	}`)
 
+	deployMachineSchema := json.RawMessage(`{
+		"type": "object",
+		"required": ["id"],
+		"properties": {
+			"id": {
+				"type": "string"
+			},
+			"distro_series": {
+				"type": "string"
+			},
+			"hwe_kernel": {
+				"type": "string"
+			},
+			"user_data": {
+				"type": "string"
+			},
+			"async": {
+				"type": "boolean",
+				"description": "Return at once with an operation whose progress is streamed on its stream_url (HTTP transport only)"
+			}
+		}
+	}`)
+
 	err = registry.RegisterTool(mcp.ToolInfo{
-		Name:        "maas_list_machines",
-		Description: "List machines with optional filtering",
-		InputSchema: listMachinesSchema,
-		Handler:     machineTools.ListMachines,
+		Name:         "maas_list_machines",
+		Description:  "List machines with optional filtering",
+		InputSchema:  listMachinesSchema,
+		OutputSchema: tools.ListMachinesOutputSchema,
+		Annotations:  &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+		Method:       "GET",
+		Tags:         []string{"machines"},
+		Summarize:    tools.SummarizeMachines,
+		Handler:      machineTools.ListMachines,
 	})
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to register maas_list_machines tool")
 	}
 
 	err = registry.RegisterTool(mcp.ToolInfo{
-		Name:        "maas_get_machine_details",
-		Description: "Get details for a specific machine",
-		InputSchema: getMachineDetailsSchema,
-		Handler:     machineTools.GetMachineDetails,
+		Name:         "maas_get_machine_details",
+		Description:  "Get details for a specific machine",
+		InputSchema:  getMachineDetailsSchema,
+		OutputSchema: tools.MachineOutputSchema,
+		Annotations:  &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+		Method:       "GET",
+		Tags:         []string{"machines"},
+		Summarize:    tools.SummarizeMachine,
+		Handler:      machineTools.GetMachineDetails,
 	})
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to register maas_get_machine_details tool")
 	}
 
 	err = registry.RegisterTool(mcp.ToolInfo{
-		Name:        "maas_power_on_machine",
-		Description: "Power on a machine",
-		InputSchema: powerOnMachineSchema,
-		Handler:     machineTools.PowerOnMachine,
+		Name:         "maas_power_on_machine",
+		Description:  "Power on a machine",
+		InputSchema:  powerOnMachineSchema,
+		OutputSchema: tools.MachineOutputSchema,
+		Annotations:  &mcp.ToolAnnotations{IdempotentHint: true},
+		Method:       "POST",
+		Tags:         []string{"machines"},
+		Summarize:    tools.SummarizeMachine,
+		Handler:      machineTools.PowerOnMachine,
 	})
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to register maas_power_on_machine tool")
 	}
 
 	err = registry.RegisterTool(mcp.ToolInfo{
-		Name:        "maas_power_off_machine",
-		Description: "Power off a machine",
-		InputSchema: powerOffMachineSchema,
-		Handler:     machineTools.PowerOffMachine,
+		Name:         "maas_power_off_machine",
+		Description:  "Power off a machine",
+		InputSchema:  powerOffMachineSchema,
+		OutputSchema: tools.MachineOutputSchema,
+		Annotations:  &mcp.ToolAnnotations{DestructiveHint: true, IdempotentHint: true},
+		Method:       "POST",
+		Tags:         []string{"machines"},
+		Summarize:    tools.SummarizeMachine,
+		Handler:      machineTools.PowerOffMachine,
 	})
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to register maas_power_off_machine tool")
 	}
 
+	err = registry.RegisterTool(mcp.ToolInfo{
+		Name:         "maas_deploy_machine",
+		Description:  "Deploy a machine; with a progress token the call reports progress and returns once deployment finishes, with async it returns an operation to follow",
+		InputSchema:  deployMachineSchema,
+		OutputSchema: tools.MachineActionOutputSchema,
+		Annotations:  &mcp.ToolAnnotations{DestructiveHint: true},
+		Method:       "POST",
+		Tags:         []string{"machines"},
+		Summarize:    tools.SummarizeMachineAction,
+		Handler:      machineTools.DeployMachine,
+	})
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to register maas_deploy_machine tool")
+	}
+
+	err = registry.RegisterTool(mcp.ToolInfo{
+		Name:        "maas_allocate_machine",
+		Description: "Allocate a machine matching the given constraints; storage is a MAAS storage string or typed constraints, and with async the call returns an operation to follow",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"hostname": {"type": "string"},
+				"zone": {"type": "string"},
+				"pool": {"type": "string"},
+				"architecture": {"type": "string"},
+				"tags": {"type": "array", "items": {"type": "string"}},
+				"storage": {"type": "string", "description": "MAAS storage constraint string, e.g. root:100(ssd),data:500(hdd,raid) with sizes in GB"},
+				"storage_constraints": {
+					"type": "array",
+					"description": "Typed alternative to storage, one disk per constraint",
+					"items": {
+						"type": "object",
+						"required": ["min_size_bytes"],
+						"properties": {
+							"name": {"type": "string", "description": "Disk label"},
+							"min_size_bytes": {"type": "integer", "minimum": 1},
+							"disk_type": {"type": "string", "description": "ssd, hdd or nvme"},
+							"tags": {"type": "array", "items": {"type": "string"}},
+							"count": {"type": "integer", "minimum": 1, "description": "Number of such disks"}
+						}
+					}
+				},
+				"async": {
+					"type": "boolean",
+					"description": "Return at once with an operation whose progress is streamed on its stream_url (HTTP transport only)"
+				}
+			}
+		}`),
+		OutputSchema: tools.MachineActionOutputSchema,
+		Method:       "POST",
+		Tags:         []string{"machines"},
+		Summarize:    tools.SummarizeMachineAction,
+		Handler:      machineTools.AllocateMachine,
+	})
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to register maas_allocate_machine tool")
+	}
+
+	err = registry.RegisterTool(mcp.ToolInfo{
+		Name:        "maas_cancel_machine_operation",
+		Description: "Cancel a background machine operation and abort the machine's action in MAAS",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"required": ["operation_id"],
+			"properties": {
+				"operation_id": {"type": "string", "description": "ID of the operation returned by an async call"}
+			}
+		}`),
+		OutputSchema: tools.CancelMachineOperationOutputSchema,
+		Annotations:  &mcp.ToolAnnotations{DestructiveHint: true, IdempotentHint: true},
+		Method:       "POST",
+		Tags:         []string{"machines"},
+		Handler:      machineTools.CancelMachineOperation,
+	})
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to register maas_cancel_machine_operation tool")
+	}
+
+	// Register the zone and resource pool tools
+	listSchema := json.RawMessage(`{"type": "object", "properties": {}}`)
+	nameSchema := func(kind string) json.RawMessage {
+		return json.RawMessage(`{
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string", "description": "Name of the ` + kind + `"}
+			}
+		}`)
+	}
+	createSchema := func(kind string) json.RawMessage {
+		return json.RawMessage(`{
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string", "description": "Name of the new ` + kind + `"},
+				"description": {"type": "string"}
+			}
+		}`)
+	}
+	updateSchema := func(kind string) json.RawMessage {
+		return json.RawMessage(`{
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string", "description": "Current name of the ` + kind + `"},
+				"new_name": {"type": "string", "description": "New name of the ` + kind + `"},
+				"description": {"type": "string", "description": "New description of the ` + kind + `"}
+			}
+		}`)
+	}
+
+	// Tagged like the generated zone and resource pool tools, so that tool profiles treat both alike
+	placementTools := []mcp.ToolInfo{
+		{
+			Name:         "maas_list_zones",
+			Description:  "List availability zones",
+			InputSchema:  listSchema,
+			OutputSchema: tools.ListZonesOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+			Method:       "GET",
+			Tags:         []string{"zone", "zones"},
+			Summarize:    tools.SummarizeZones,
+			Handler:      zoneTools.ListZones,
+		},
+		{
+			Name:         "maas_get_zone",
+			Description:  "Get an availability zone by name",
+			InputSchema:  nameSchema("zone"),
+			OutputSchema: tools.ZoneOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+			Method:       "GET",
+			Tags:         []string{"zone", "zones"},
+			Handler:      zoneTools.GetZone,
+		},
+		{
+			Name:         "maas_create_zone",
+			Description:  "Create an availability zone",
+			InputSchema:  createSchema("zone"),
+			OutputSchema: tools.ZoneOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{},
+			Method:       "POST",
+			Tags:         []string{"zone", "zones"},
+			Handler:      zoneTools.CreateZone,
+		},
+		{
+			Name:         "maas_update_zone",
+			Description:  "Rename an availability zone or change its description",
+			InputSchema:  updateSchema("zone"),
+			OutputSchema: tools.ZoneOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{IdempotentHint: true},
+			Method:       "PUT",
+			Tags:         []string{"zone", "zones"},
+			Handler:      zoneTools.UpdateZone,
+		},
+		{
+			Name:         "maas_delete_zone",
+			Description:  "Delete an availability zone",
+			InputSchema:  nameSchema("zone"),
+			OutputSchema: tools.DeleteZoneOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{DestructiveHint: true, IdempotentHint: true},
+			Method:       "DELETE",
+			Tags:         []string{"zone", "zones"},
+			Handler:      zoneTools.DeleteZone,
+		},
+		{
+			Name:         "maas_list_resource_pools",
+			Description:  "List resource pools",
+			InputSchema:  listSchema,
+			OutputSchema: tools.ListResourcePoolsOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+			Method:       "GET",
+			Tags:         []string{"resourcepool", "resourcepools"},
+			Summarize:    tools.SummarizeResourcePools,
+			Handler:      resourcePoolTools.ListResourcePools,
+		},
+		{
+			Name:         "maas_get_resource_pool",
+			Description:  "Get a resource pool by name",
+			InputSchema:  nameSchema("resource pool"),
+			OutputSchema: tools.ResourcePoolOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+			Method:       "GET",
+			Tags:         []string{"resourcepool", "resourcepools"},
+			Handler:      resourcePoolTools.GetResourcePool,
+		},
+		{
+			Name:         "maas_create_resource_pool",
+			Description:  "Create a resource pool",
+			InputSchema:  createSchema("resource pool"),
+			OutputSchema: tools.ResourcePoolOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{},
+			Method:       "POST",
+			Tags:         []string{"resourcepool", "resourcepools"},
+			Handler:      resourcePoolTools.CreateResourcePool,
+		},
+		{
+			Name:         "maas_update_resource_pool",
+			Description:  "Rename a resource pool or change its description",
+			InputSchema:  updateSchema("resource pool"),
+			OutputSchema: tools.ResourcePoolOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{IdempotentHint: true},
+			Method:       "PUT",
+			Tags:         []string{"resourcepool", "resourcepools"},
+			Handler:      resourcePoolTools.UpdateResourcePool,
+		},
+		{
+			Name:         "maas_delete_resource_pool",
+			Description:  "Delete a resource pool",
+			InputSchema:  nameSchema("resource pool"),
+			OutputSchema: tools.DeleteResourcePoolOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{DestructiveHint: true, IdempotentHint: true},
+			Method:       "DELETE",
+			Tags:         []string{"resourcepool", "resourcepools"},
+			Handler:      resourcePoolTools.DeleteResourcePool,
+		},
+	}
+	for _, info := range placementTools {
+		if err := registry.RegisterTool(info); err != nil {
+			logger.WithError(err).Fatalf("Failed to register %s tool", info.Name)
+		}
+	}
+
+	// Register the space, IP range and static route tools
+	idSchema := func(kind string) json.RawMessage {
+		return json.RawMessage(`{
+			"type": "object",
+			"required": ["id"],
+			"properties": {
+				"id": {"type": "integer", "minimum": 0, "description": "ID of the ` + kind + `"}
+			}
+		}`)
+	}
+
+	// Tagged like the generated space, IP range and static route tools
+	networkToolInfos := []mcp.ToolInfo{
+		{
+			Name:         "maas_list_spaces",
+			Description:  "List network spaces",
+			InputSchema:  listSchema,
+			OutputSchema: tools.ListSpacesOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+			Method:       "GET",
+			Tags:         []string{"spaces"},
+			Summarize:    tools.SummarizeSpaces,
+			Handler:      networkTools.ListSpaces,
+		},
+		{
+			Name:         "maas_get_space",
+			Description:  "Get a network space by ID, with its subnets and VLANs",
+			InputSchema:  idSchema("space"),
+			OutputSchema: tools.SpaceOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+			Method:       "GET",
+			Tags:         []string{"spaces"},
+			Handler:      networkTools.GetSpace,
+		},
+		{
+			Name:        "maas_create_space",
+			Description: "Create a network space",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"required": ["name"],
+				"properties": {
+					"name": {"type": "string", "description": "Name of the new space"}
+				}
+			}`),
+			OutputSchema: tools.SpaceOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{},
+			Method:       "POST",
+			Tags:         []string{"spaces"},
+			Handler:      networkTools.CreateSpace,
+		},
+		{
+			Name:        "maas_update_space",
+			Description: "Rename a network space",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"required": ["id", "name"],
+				"properties": {
+					"id": {"type": "integer", "minimum": 0, "description": "ID of the space"},
+					"name": {"type": "string", "description": "New name of the space"}
+				}
+			}`),
+			OutputSchema: tools.SpaceOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{IdempotentHint: true},
+			Method:       "PUT",
+			Tags:         []string{"spaces"},
+			Handler:      networkTools.UpdateSpace,
+		},
+		{
+			Name:         "maas_delete_space",
+			Description:  "Delete a network space",
+			InputSchema:  idSchema("space"),
+			OutputSchema: tools.DeletedOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{DestructiveHint: true, IdempotentHint: true},
+			Method:       "DELETE",
+			Tags:         []string{"spaces"},
+			Handler:      networkTools.DeleteSpace,
+		},
+		{
+			Name:        "maas_list_ip_ranges",
+			Description: "List reserved and dynamic IP ranges, optionally of one subnet or one type",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"subnet_id": {"type": "integer", "description": "Only list the ranges of this subnet"},
+					"type": {"type": "string", "enum": ["dynamic", "reserved"], "description": "Only list ranges of this type"}
+				}
+			}`),
+			OutputSchema: tools.ListIPRangesOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+			Method:       "GET",
+			Tags:         []string{"ipranges"},
+			Summarize:    tools.SummarizeIPRanges,
+			Handler:      networkTools.ListIPRanges,
+		},
+		{
+			Name:         "maas_get_ip_range",
+			Description:  "Get an IP range by ID",
+			InputSchema:  idSchema("IP range"),
+			OutputSchema: tools.IPRangeOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+			Method:       "GET",
+			Tags:         []string{"ipranges"},
+			Handler:      networkTools.GetIPRange,
+		},
+		{
+			Name:        "maas_create_ip_range",
+			Description: "Create a reserved or dynamic IP range in a subnet; MAAS serves DHCP from dynamic ranges",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"required": ["type", "subnet", "start_ip", "end_ip"],
+				"properties": {
+					"type": {"type": "string", "enum": ["dynamic", "reserved"]},
+					"subnet": {"type": "string", "description": "ID or CIDR of the subnet"},
+					"start_ip": {"type": "string", "description": "First address of the range"},
+					"end_ip": {"type": "string", "description": "Last address of the range"},
+					"comment": {"type": "string"}
+				}
+			}`),
+			OutputSchema: tools.IPRangeOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{},
+			Method:       "POST",
+			Tags:         []string{"ipranges"},
+			Handler:      networkTools.CreateIPRange,
+		},
+		{
+			Name:        "maas_update_ip_range",
+			Description: "Change the type, bounds or comment of an IP range",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"required": ["id"],
+				"properties": {
+					"id": {"type": "integer", "minimum": 1, "description": "ID of the IP range"},
+					"type": {"type": "string", "enum": ["dynamic", "reserved"]},
+					"start_ip": {"type": "string", "description": "New first address of the range"},
+					"end_ip": {"type": "string", "description": "New last address of the range"},
+					"comment": {"type": "string"}
+				}
+			}`),
+			OutputSchema: tools.IPRangeOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{IdempotentHint: true},
+			Method:       "PUT",
+			Tags:         []string{"ipranges"},
+			Handler:      networkTools.UpdateIPRange,
+		},
+		{
+			Name:         "maas_delete_ip_range",
+			Description:  "Delete an IP range",
+			InputSchema:  idSchema("IP range"),
+			OutputSchema: tools.DeletedOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{DestructiveHint: true, IdempotentHint: true},
+			Method:       "DELETE",
+			Tags:         []string{"ipranges"},
+			Handler:      networkTools.DeleteIPRange,
+		},
+		{
+			Name:         "maas_list_static_routes",
+			Description:  "List static routes",
+			InputSchema:  listSchema,
+			OutputSchema: tools.ListStaticRoutesOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+			Method:       "GET",
+			Tags:         []string{"static-routes"},
+			Summarize:    tools.SummarizeStaticRoutes,
+			Handler:      networkTools.ListStaticRoutes,
+		},
+		{
+			Name:        "maas_create_static_route",
+			Description: "Create a static route from a source subnet to a destination subnet through a gateway",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"required": ["source", "destination", "gateway_ip"],
+				"properties": {
+					"source": {"type": "string", "description": "ID or CIDR of the source subnet"},
+					"destination": {"type": "string", "description": "ID or CIDR of the destination subnet"},
+					"gateway_ip": {"type": "string", "description": "Gateway in the source subnet"},
+					"metric": {"type": "integer", "minimum": 0}
+				}
+			}`),
+			OutputSchema: tools.StaticRouteOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{},
+			Method:       "POST",
+			Tags:         []string{"static-routes"},
+			Handler:      networkTools.CreateStaticRoute,
+		},
+		{
+			Name:         "maas_delete_static_route",
+			Description:  "Delete a static route",
+			InputSchema:  idSchema("static route"),
+			OutputSchema: tools.DeletedOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{DestructiveHint: true, IdempotentHint: true},
+			Method:       "DELETE",
+			Tags:         []string{"static-routes"},
+			Handler:      networkTools.DeleteStaticRoute,
+		},
+	}
+	// Tagged like the generated subnet tools
+	networkToolInfos = append(networkToolInfos,
+		mcp.ToolInfo{
+			Name:        "maas_get_subnet_utilization",
+			Description: "Get the used, reserved, dynamic and free address counts of a subnet, and its largest free blocks",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"required": ["subnet_id"],
+				"properties": {
+					"subnet_id": {"type": "integer", "minimum": 1, "description": "ID of the subnet"},
+					"max_blocks": {"type": "integer", "minimum": 1, "maximum": 50, "description": "Number of free blocks to return (default 5)"}
+				}
+			}`),
+			OutputSchema: tools.SubnetUtilizationOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+			Method:       "GET",
+			Tags:         []string{"subnets"},
+			Summarize:    tools.SummarizeSubnetUtilization,
+			Handler:      ipamTools.GetSubnetUtilization,
+		},
+		mcp.ToolInfo{
+			Name:        "maas_find_free_ips",
+			Description: "Find unused IP addresses in a CIDR that lies in a MAAS subnet, optionally outside its dynamic (DHCP) ranges",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"required": ["cidr"],
+				"properties": {
+					"cidr": {"type": "string", "description": "A MAAS subnet, or a part of one, e.g. 10.0.0.0/24 or 10.0.0.64/26"},
+					"count": {"type": "integer", "minimum": 1, "maximum": 256, "description": "Number of addresses to return (default 1)"},
+					"exclude_dynamic": {"type": "boolean", "description": "Skip addresses in dynamic ranges"}
+				}
+			}`),
+			OutputSchema: tools.FreeIPsOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+			Method:       "GET",
+			Tags:         []string{"subnets"},
+			Summarize:    tools.SummarizeFreeIPs,
+			Handler:      ipamTools.FindFreeIPs,
+		},
+	)
+
+	// Tagged like the generated ipaddresses tools
+	networkToolInfos = append(networkToolInfos,
+		mcp.ToolInfo{
+			Name:        "maas_reserve_ip",
+			Description: "Reserve a static IP address in a subnet, optionally bound to a MAC address and with a DNS hostname, e.g. a load balancer VIP. MAAS picks a free address when no IP is given",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"subnet": {"type": "string", "description": "ID or CIDR of the subnet, required unless ip is given"},
+					"ip": {"type": "string", "description": "Address to reserve"},
+					"mac": {"type": "string", "description": "MAC address to bind the reservation to"},
+					"hostname": {"type": "string", "description": "Hostname to register for the address, in the default domain unless qualified"},
+					"comment": {"type": "string", "description": "Why the address was reserved, kept in the reservation audit"}
+				}
+			}`),
+			OutputSchema: tools.ReserveIPOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{},
+			Method:       "POST",
+			Tags:         []string{"ipaddresses"},
+			Summarize:    tools.SummarizeReservation,
+			Handler:      ipAddressTools.ReserveIP,
+		},
+		mcp.ToolInfo{
+			Name:        "maas_release_ip",
+			Description: "Release a reserved static IP address",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"required": ["ip"],
+				"properties": {
+					"ip": {"type": "string", "description": "Address to release"},
+					"force": {"type": "boolean", "description": "Release the address even if another user reserved it (admin only)"}
+				}
+			}`),
+			OutputSchema: tools.ReleaseIPOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{DestructiveHint: true, IdempotentHint: true},
+			Method:       "POST",
+			Tags:         []string{"ipaddresses"},
+			Summarize:    tools.SummarizeRelease,
+			Handler:      ipAddressTools.ReleaseIP,
+		},
+		mcp.ToolInfo{
+			Name:        "maas_list_ip_reservations",
+			Description: "List the static IP addresses reserved through this server, oldest first",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"include_released": {"type": "boolean", "description": "Include reservations that have since been released"}
+				}
+			}`),
+			OutputSchema: tools.ListIPReservationsOutputSchema,
+			Annotations:  &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+			Method:       "GET",
+			Tags:         []string{"ipaddresses"},
+			Summarize:    tools.SummarizeIPReservations,
+			Handler:      ipAddressTools.ListIPReservations,
+		},
+	)
+	for _, info := range networkToolInfos {
+		if err := registry.RegisterTool(info); err != nil {
+			logger.WithError(err).Fatalf("Failed to register %s tool", info.Name)
+		}
+	}
+
 	// Register MCP resources
 	err = registry.RegisterResource(mcp.ResourceInfo{
 		Name:        "maas_machine",
@@ -145,21 +737,83 @@ func main() {
 		logger.WithError(err).Fatal("Failed to register maas_machine resource")
 	}
 
+	for _, info := range []mcp.ResourceInfo{
+		{Name: "maas_zone", Description: "MAAS availability zone, with its machine counts by status", URIPattern: "maas://zone/{name}"},
+		{Name: "maas_pool", Description: "MAAS resource pool, with its machine counts by status", URIPattern: "maas://pool/{name}"},
+		{Name: "maas_space", Description: "MAAS network space, with its subnets and VLANs", URIPattern: "maas://space/{space_id}"},
+		{Name: "maas_subnet_ip_ranges", Description: "Reserved and dynamic IP ranges of a MAAS subnet", URIPattern: "maas://subnet/{subnet_id}/ip-ranges"},
+		{Name: "maas_subnet_reserved_ranges", Description: "Reserved IP ranges of a MAAS subnet", URIPattern: "maas://subnet/{subnet_id}/reserved-ranges"},
+		{Name: "maas_subnet_dynamic_ranges", Description: "Dynamic (DHCP) IP ranges of a MAAS subnet", URIPattern: "maas://subnet/{subnet_id}/dynamic-ranges"},
+	} {
+		if err := registry.RegisterResource(info); err != nil {
+			logger.WithError(err).Fatalf("Failed to register %s resource", info.Name)
+		}
+	}
+
 	// Register list_machines tool (alias for maas_list_machines)
 	err = registry.RegisterTool(mcp.ToolInfo{
-		Name:        "list_machines",
-		Description: "List MAAS machines with optional filtering",
-		InputSchema: listMachinesSchema,
-		Handler:     machineTools.ListMachines,
+		Name:         "list_machines",
+		Description:  "List MAAS machines with optional filtering",
+		InputSchema:  listMachinesSchema,
+		OutputSchema: tools.ListMachinesOutputSchema,
+		Annotations:  &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+		Method:       "GET",
+		Tags:         []string{"machines"},
+		Summarize:    tools.SummarizeMachines,
+		Handler:      machineTools.ListMachines,
 	})
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to register list_machines tool")
 	}
 
+	// Serve MAAS resources (maas://machine/{system_id} etc.) through resources/read
+	resourceReader := resources.NewReader(maasClientWrapper, logger)
+	registry.SetResourceReader(resourceReader)
+
+	// Poll subscribed resources for resources/subscribe
+	subscriptions := resources.NewSubscriptionManager(resourceReader, logger)
+	defer subscriptions.Close()
+	registry.SetResourceSubscriber(subscriptions)
+
+	// Make the generated MAAS API tools reachable through maas_find_tools and maas_invoke_tool,
+	// without listing all of them in tools/list
+	apiClient, err := maasclient.NewMaasClient(&models.AppConfig{
+		MAASInstances: map[string]models.MAASInstanceConfig{
+			"default": {APIURL: maasInstance.APIURL, APIKey: maasInstance.APIKey},
+		},
+	}, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to create MAAS API client")
+	}
+	generatedTools := tools.NewGeneratedTools(apiClient)
+	definitions, err := tools.LoadGeneratedTools(tools.DefaultGeneratedToolsPath)
+	if err != nil {
+		logger.WithError(err).Warn("Generated MAAS API tools are not available")
+	}
+	for _, definition := range definitions {
+		info, err := generatedToolInfo(definition, generatedTools.Handler(definition))
+		if err == nil {
+			err = registry.RegisterTool(info)
+		}
+		if err != nil {
+			logger.WithError(err).WithField("tool", definition.Name).Warn("Failed to register generated tool")
+		}
+	}
+	if err := registry.RegisterCatalogTools(); err != nil {
+		logger.WithError(err).Fatal("Failed to register catalog tools")
+	}
+
+	// Register the MAAS runbook prompts, and any prompt templates in the config directory
+	promptDir := filepath.Join(filepath.Dir(config.FallbackConfigPath), "prompts")
+	if err := prompts.NewRunbooks(machineService, prompts.NewClientSubnets(maasClientWrapper)).Register(registry, promptDir); err != nil {
+		logger.WithError(err).Fatal("Failed to register prompts")
+	}
+
 	// Create and run the appropriate server based on the mode
 	if useStdio {
 		// Create stdio server
 		stdioServer := mcp.NewStdioServer(registry, logger)
+		stdioServer.SetConcurrency(cfg.Server.StdioWorkers, cfg.Server.StdioMaxOutstanding)
 
 		// Run stdio server
 		if err := stdioServer.Run(); err != nil {
@@ -168,6 +822,12 @@ func main() {
 	} else {
 		// Create HTTP server
 		httpServer := mcp.NewServer(registry, logger)
+		httpServer.SetEventQueue(cfg.Server.SSEQueueSize, mcp.SlowClientPolicy(cfg.Server.SSESlowClientPolicy))
+
+		// Background machine operations publish their events on the server's SSE endpoint
+		operations := tools.NewMachineOperations(machineService, progress.NewProgressTracker(&logging.Logger{Logger: logger}), logger)
+		operations.SetPublisher(httpServer)
+		machineTools.SetOperations(operations)
 
 		// Start HTTP server in a goroutine
 		go func() {
@@ -193,3 +853,33 @@ func main() {
 
 	logger.Info("Server exiting")
 }
+
+// generatedToolInfo returns a deferred MCP tool that runs a generated MAAS API tool
+func generatedToolInfo(definition models.MCPTool, handler mcp.ToolFunc) (mcp.ToolInfo, error) {
+	inputSchema, err := json.Marshal(definition.InputSchema)
+	if err != nil {
+		return mcp.ToolInfo{}, fmt.Errorf("failed to marshal input schema: %w", err)
+	}
+
+	info := mcp.ToolInfo{
+		Name:        definition.Name,
+		Description: definition.Description,
+		InputSchema: inputSchema,
+		Tags:        definition.Tags,
+		Deferred:    true,
+		Handler:     handler,
+	}
+	if definition.Endpoint != nil {
+		info.Method = definition.Endpoint.Method
+	}
+	if a := definition.Annotations; a != nil {
+		info.Annotations = &mcp.ToolAnnotations{
+			Title:           a.Title,
+			ReadOnlyHint:    a.ReadOnlyHint,
+			DestructiveHint: a.DestructiveHint,
+			IdempotentHint:  a.IdempotentHint,
+			OpenWorldHint:   a.OpenWorldHint,
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,208 @@
+package mcp
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SlowClientPolicy decides what happens to an SSE client whose event queue is full
+type SlowClientPolicy string
+
+const (
+	// DropEvents drops the events that do not fit in the client's queue; the client stays connected
+	DropEvents SlowClientPolicy = "drop"
+
+	// DisconnectClient ends the stream of the client, which has to reconnect and catch up
+	DisconnectClient SlowClientPolicy = "disconnect"
+)
+
+// defaultEventQueueSize is the number of events queued for an SSE client before the slow client
+// policy applies
+const defaultEventQueueSize = 64
+
+// EventFilter selects the events an SSE client receives. An event must match one of the values of
+// every list that is not empty, so the empty filter receives every event.
+type EventFilter struct {
+	OperationIDs []string
+	MachineIDs   []string
+	EventTypes   []string
+}
+
+// parseEventFilter reads the filter of an SSE client from its request query. operation_id,
+// machine_id and event_type may each be repeated or hold comma-separated values.
+func parseEventFilter(query url.Values) EventFilter {
+	values := func(key string) []string {
+		var list []string
+		for _, value := range query[key] {
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+		}
+		return list
+	}
+
+	return EventFilter{
+		OperationIDs: values("operation_id"),
+		MachineIDs:   values("machine_id"),
+		EventTypes:   values("event_type"),
+	}
+}
+
+// matches reports whether an event passes the filter
+func (f EventFilter) matches(event SSEEvent) bool {
+	return matchesAny(f.OperationIDs, event.OperationID) &&
+		matchesAny(f.MachineIDs, event.MachineID) &&
+		matchesAny(f.EventTypes, event.Event)
+}
+
+// matchesAny reports whether value is in values, or values is empty
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, allowed := range values {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sseClient is an SSE stream connected to the hub
+type sseClient struct {
+	id     string
+	filter EventFilter
+
+	// events is the client's bounded queue
+	events chan SSEEvent
+
+	// gone is closed when the hub disconnects the client for being too slow
+	gone chan struct{}
+
+	// dropped counts the events dropped for the client
+	dropped int
+}
+
+// eventHub tracks the connected SSE clients and fans events out to them. Every client has a
+// bounded queue, so a slow client never holds up the others; what happens when its queue is full
+// is decided by the policy.
+type eventHub struct {
+	logger *logrus.Logger
+
+	mu        sync.Mutex
+	clients   map[string]*sseClient
+	queueSize int
+	policy    SlowClientPolicy
+}
+
+// newEventHub creates a hub without clients
+func newEventHub(logger *logrus.Logger) *eventHub {
+	return &eventHub{
+		logger:    logger,
+		clients:   make(map[string]*sseClient),
+		queueSize: defaultEventQueueSize,
+		policy:    DropEvents,
+	}
+}
+
+// configure sets the queue size and slow client policy of the clients that connect from now on.
+// A size of zero and an empty policy keep the current settings.
+func (h *eventHub) configure(queueSize int, policy SlowClientPolicy) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if queueSize > 0 {
+		h.queueSize = queueSize
+	}
+	if policy != "" {
+		h.policy = policy
+	}
+}
+
+// connect registers a client and returns it
+func (h *eventHub) connect(id string, filter EventFilter) *sseClient {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	client := &sseClient{
+		id:     id,
+		filter: filter,
+		events: make(chan SSEEvent, h.queueSize),
+		gone:   make(chan struct{}),
+	}
+	h.clients[id] = client
+	return client
+}
+
+// disconnect removes a client; it is a no-op for a client that is already gone
+func (h *eventHub) disconnect(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.clients, id)
+}
+
+// has reports whether a client is connected
+func (h *eventHub) has(id string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, ok := h.clients[id]
+	return ok
+}
+
+// send queues an event for one client, whatever its filter. It reports whether the event was queued.
+func (h *eventHub) send(id string, event SSEEvent) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	client, ok := h.clients[id]
+	if !ok {
+		return false
+	}
+	return h.deliver(client, event)
+}
+
+// broadcast queues an event for every client whose filter it matches and returns how many got it
+func (h *eventHub) broadcast(event SSEEvent) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delivered := 0
+	for _, client := range h.clients {
+		if client.filter.matches(event) && h.deliver(client, event) {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+// deliver queues an event for a client, applying the slow client policy when its queue is full.
+// The caller holds h.mu.
+func (h *eventHub) deliver(client *sseClient, event SSEEvent) bool {
+	select {
+	case client.events <- event:
+		return true
+	default:
+	}
+
+	logger := h.logger.WithFields(logrus.Fields{
+		"session_id": client.id,
+		"event":      event.Event,
+	})
+	if h.policy == DisconnectClient {
+		logger.Warn("Disconnecting SSE client, it is not keeping up")
+		delete(h.clients, client.id)
+		close(client.gone)
+		return false
+	}
+
+	client.dropped++
+	logger.WithField("dropped", client.dropped).Warn("Dropping event, SSE client is not keeping up")
+	return false
+}
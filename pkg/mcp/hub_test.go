@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/maas-mcp-server/internal/transport/mcp/events"
+)
+
+// newTestHub creates a hub that logs nowhere
+func newTestHub() *eventHub {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return newEventHub(logger)
+}
+
+func TestEventFilter(t *testing.T) {
+	filter := parseEventFilter(url.Values{
+		"machine_id": {"abc123, def456", "ghi789"},
+		"event_type": {"status"},
+	})
+	assert.Equal(t, EventFilter{
+		MachineIDs: []string{"abc123", "def456", "ghi789"},
+		EventTypes: []string{"status"},
+	}, filter)
+
+	assert.True(t, filter.matches(SSEEvent{Event: "status", MachineID: "def456", OperationID: "op-1"}))
+	assert.False(t, filter.matches(SSEEvent{Event: "progress", MachineID: "def456"}))
+	assert.False(t, filter.matches(SSEEvent{Event: "status", MachineID: "xyz000"}))
+	assert.False(t, filter.matches(SSEEvent{Event: "status"}), "events about no machine do not match a machine filter")
+	assert.True(t, EventFilter{}.matches(SSEEvent{Event: "anything"}))
+}
+
+func TestEventHub_Broadcast(t *testing.T) {
+	hub := newTestHub()
+	all := hub.connect("all", EventFilter{})
+	operation := hub.connect("operation", EventFilter{OperationIDs: []string{"op-1"}})
+
+	assert.Equal(t, 2, hub.broadcast(SSEEvent{Event: "completion", OperationID: "op-1"}))
+	assert.Equal(t, 1, hub.broadcast(SSEEvent{Event: "completion", OperationID: "op-2"}))
+	assert.Len(t, all.events, 2)
+	assert.Len(t, operation.events, 1)
+
+	// Sending to one client ignores its filter
+	assert.True(t, hub.send("operation", SSEEvent{Event: "message"}))
+	assert.Len(t, operation.events, 2)
+
+	hub.disconnect("all")
+	assert.False(t, hub.has("all"))
+	assert.False(t, hub.send("all", SSEEvent{Event: "message"}))
+}
+
+func TestEventHub_SlowClients(t *testing.T) {
+	t.Run("drop", func(t *testing.T) {
+		hub := newTestHub()
+		hub.configure(2, DropEvents)
+		client := hub.connect("slow", EventFilter{})
+
+		for i := 0; i < 3; i++ {
+			hub.broadcast(SSEEvent{Event: "progress"})
+		}
+		assert.Len(t, client.events, 2)
+		assert.Equal(t, 1, client.dropped)
+		assert.True(t, hub.has("slow"), "the client stays connected")
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+		hub := newTestHub()
+		hub.configure(1, DisconnectClient)
+		slow := hub.connect("slow", EventFilter{})
+		fast := hub.connect("fast", EventFilter{})
+
+		assert.Equal(t, 2, hub.broadcast(SSEEvent{Event: "progress"}))
+		<-fast.events
+		assert.Equal(t, 1, hub.broadcast(SSEEvent{Event: "progress"}))
+
+		assert.False(t, hub.has("slow"))
+		assert.True(t, hub.has("fast"))
+		select {
+		case <-slow.gone:
+		default:
+			t.Fatal("the slow client was not told to go")
+		}
+		hub.disconnect("slow")
+	})
+}
+
+func TestPublish_SSE(t *testing.T) {
+	server := setupTestHTTPServer(t)
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	ctx, disconnect := context.WithCancel(context.Background())
+	defer disconnect()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+"/mcp/sse?machine_id=abc123&event_type=status", nil)
+	require.NoError(t, err)
+	stream, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer stream.Body.Close()
+	reader := bufio.NewReader(stream.Body)
+	readSSEEvent(t, reader, "endpoint")
+
+	// Only the status change of the followed machine reaches the client
+	other := events.NewStatusEvent("op-1", events.StatusPending, events.StatusInProgress, "Machine def456 is Deploying", nil)
+	other.MachineID = "def456"
+	server.Publish(other)
+	server.Publish(events.NewCompletionEvent("op-2", nil, "Machine abc123 is Deployed", 1))
+	followed := events.NewStatusEvent("op-2", events.StatusInProgress, events.StatusComplete, "Machine abc123 is Deployed", nil)
+	followed.MachineID = "abc123"
+	server.Publish(followed)
+
+	data := readSSEEvent(t, reader, "status")
+	assert.Contains(t, data, `"machine_id":"abc123"`)
+	assert.Contains(t, data, `"operation_id":"op-2"`)
+}
+
+func TestPublish_StreamableHTTP(t *testing.T) {
+	server := setupTestHTTPServer(t)
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	sessionID := initializeSession(t, httpServer.URL)
+
+	// Sessions without a GET stream get nothing
+	assert.Equal(t, 0, server.publishEvent(SSEEvent{Event: "status", MachineID: "abc123", Data: `{}`}))
+
+	ctx, disconnect := context.WithCancel(context.Background())
+	defer disconnect()
+	stream, err := http.DefaultClient.Do(sessionRequest(t, ctx, http.MethodGet, httpServer.URL+"/mcp?machine_id=abc123&event_type=status", sessionID, ""))
+	require.NoError(t, err)
+	defer stream.Body.Close()
+	require.Equal(t, http.StatusOK, stream.StatusCode)
+
+	// Only the status change of the followed machine reaches the session, as a notification
+	other := events.NewStatusEvent("op-1", events.StatusPending, events.StatusInProgress, "Machine def456 is Deploying", nil)
+	other.MachineID = "def456"
+	server.Publish(other)
+	server.Publish(events.NewCompletionEvent("op-2", nil, "Machine abc123 is Deployed", 1))
+	followed := events.NewStatusEvent("op-2", events.StatusInProgress, events.StatusComplete, "Machine abc123 is Deployed", nil)
+	followed.MachineID = "abc123"
+	server.Publish(followed)
+
+	_, data := readSSEMessage(t, bufio.NewReader(stream.Body))
+	var notification struct {
+		Method string            `json:"method"`
+		Params serverEventParams `json:"params"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(data), &notification))
+	assert.Equal(t, serverEventMethod, notification.Method)
+	assert.Equal(t, "status", notification.Params.Type)
+	assert.Contains(t, string(notification.Params.Data), `"machine_id":"abc123"`)
+	assert.Contains(t, string(notification.Params.Data), `"operation_id":"op-2"`)
+}
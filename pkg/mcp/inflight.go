@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/lspecian/maas-mcp-server/internal/logging"
+	"github.com/lspecian/maas-mcp-server/internal/service/progress"
+	"github.com/sirupsen/logrus"
+)
+
+// requestKeyPrefix namespaces JSON-RPC IDs in the cancellation manager
+const requestKeyPrefix = "jsonrpc-request-"
+
+// CancelledParams represents the parameters of a notifications/cancelled message
+type CancelledParams struct {
+	RequestID JSONRPCID `json:"requestId"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// inflightRequests tracks the context of every request being executed, keyed by JSON-RPC ID,
+// so that a notifications/cancelled message from the client can cancel it
+type inflightRequests struct {
+	cancellation *progress.CancellationManager
+	logger       *logrus.Logger
+
+	// mu makes checking for and registering an ID a single step
+	mu sync.Mutex
+}
+
+// newInflightRequests creates an empty request table
+func newInflightRequests(logger *logrus.Logger) *inflightRequests {
+	return &inflightRequests{
+		cancellation: progress.NewCancellationManager(&logging.Logger{Logger: logger}, 0),
+		logger:       logger,
+	}
+}
+
+// start registers a request and returns the context it must run with, and a function to call when
+// it has finished. The function reports whether the client cancelled the request in the meantime.
+// Requests without an ID, and duplicates of an ID that is still running, cannot be cancelled.
+func (r *inflightRequests) start(parent context.Context, id JSONRPCID) (context.Context, func() bool) {
+	if id == "" {
+		return parent, func() bool { return false }
+	}
+	key := requestKeyPrefix + id.String()
+
+	r.mu.Lock()
+	if _, exists := r.cancellation.GetOperationContext(key); exists {
+		r.mu.Unlock()
+		r.logger.WithField("id", id.String()).Warn("Request ID is already in flight; the duplicate cannot be cancelled")
+		return parent, func() bool { return false }
+	}
+	operationCtx, _ := r.cancellation.RegisterOperation(key)
+	r.mu.Unlock()
+
+	// The request stops when either the transport gives up on it or the client cancels it
+	ctx, cancel := context.WithCancelCause(parent)
+	stop := context.AfterFunc(operationCtx, func() {
+		cancel(progress.ErrOperationCancelled)
+	})
+
+	return ctx, func() bool {
+		stop()
+		cancel(nil)
+		cancelled := r.cancellation.IsOperationCancelled(key)
+		r.cancellation.CleanupOperation(key)
+		return cancelled
+	}
+}
+
+// cancel cancels the request with the given ID and reports whether it was in flight
+func (r *inflightRequests) cancel(id JSONRPCID) bool {
+	key := requestKeyPrefix + id.String()
+	if _, exists := r.cancellation.GetOperationContext(key); !exists {
+		return false
+	}
+	r.cancellation.CancelOperation(key)
+	return true
+}
+
+// handleCancelled handles a notifications/cancelled message. Unknown or finished requests are
+// ignored, as the notification may arrive after the response has been sent.
+func (r *inflightRequests) handleCancelled(params json.RawMessage) {
+	var cancelledParams CancelledParams
+	if err := json.Unmarshal(params, &cancelledParams); err != nil || cancelledParams.RequestID == "" {
+		r.logger.WithField("params", string(params)).Warn("Ignoring notifications/cancelled without a request ID")
+		return
+	}
+
+	logger := r.logger.WithFields(logrus.Fields{
+		"id":     cancelledParams.RequestID.String(),
+		"reason": cancelledParams.Reason,
+	})
+	if !r.cancel(cancelledParams.RequestID) {
+		logger.Debug("Ignoring cancellation of a request that is not in flight")
+		return
+	}
+	logger.Info("Cancelled request at the client's request")
+}
@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/lspecian/maas-mcp-server/internal/service/progress"
+	"github.com/lspecian/maas-mcp-server/internal/transport/mcp/events"
+)
+
+// progressTotal is the total sent with progress notifications; reporters work in percent
+const progressTotal = 100.0
+
+// notifyFunc sends a JSON-RPC notification to the client of a request
+type notifyFunc func(method string, params interface{})
+
+// progressNotifier implements progress.ProgressReporter for a tools/call request that carried a
+// progress token. Progress is sent as notifications/progress; log, status and error reports are
+// sent as notifications/message. The final result is still the JSON-RPC response.
+type progressNotifier struct {
+	notify    notifyFunc
+	token     json.RawMessage
+	startTime time.Time
+
+	mu           sync.Mutex
+	lastProgress float64
+	sent         bool
+}
+
+// newProgressNotifier creates a reporter that sends notifications for the given progress token
+func newProgressNotifier(notify notifyFunc, token json.RawMessage) *progressNotifier {
+	return &progressNotifier{
+		notify:    notify,
+		token:     token,
+		startTime: time.Now(),
+	}
+}
+
+// ReportProgress sends a notifications/progress message. MCP requires progress to increase with
+// every notification, so a report that does not move forward is sent as a log message instead.
+func (r *progressNotifier) ReportProgress(value float64, message string, details interface{}) error {
+	r.mu.Lock()
+	if r.sent && value <= r.lastProgress {
+		r.mu.Unlock()
+		return r.ReportLog(events.LogLevelInfo, message, "", details)
+	}
+	r.lastProgress = value
+	r.sent = true
+	r.mu.Unlock()
+
+	params := map[string]interface{}{
+		"progressToken": r.token,
+		"progress":      value,
+		"total":         progressTotal,
+	}
+	if message != "" {
+		params["message"] = message
+	}
+	r.notify("notifications/progress", params)
+	return nil
+}
+
+// ReportCompletion reports the operation as fully done; the result itself goes in the response
+func (r *progressNotifier) ReportCompletion(result interface{}, message string) error {
+	return r.ReportProgress(progressTotal, message, nil)
+}
+
+// ReportError sends the error as an error-level log message; the response carries the failure
+func (r *progressNotifier) ReportError(err string, code int, details interface{}, recoverable bool) error {
+	return r.ReportLog(events.LogLevelError, err, "", map[string]interface{}{
+		"code":        code,
+		"details":     details,
+		"recoverable": recoverable,
+	})
+}
+
+// ReportLog sends a notifications/message message
+func (r *progressNotifier) ReportLog(level events.LogLevel, message string, source string, details interface{}) error {
+	data := map[string]interface{}{
+		"message": message,
+	}
+	if details != nil {
+		data["details"] = details
+	}
+
+	params := map[string]interface{}{
+		"level": string(level),
+		"data":  data,
+	}
+	if source != "" {
+		params["logger"] = source
+	}
+	r.notify("notifications/message", params)
+	return nil
+}
+
+// ReportStatus sends a status change as an info-level log message
+func (r *progressNotifier) ReportStatus(previousStatus, currentStatus events.StatusType, message string, details interface{}) error {
+	return r.ReportLog(events.LogLevelInfo, message, "", map[string]interface{}{
+		"previous_status": previousStatus,
+		"status":          currentStatus,
+		"details":         details,
+	})
+}
+
+// OperationID returns the progress token the client supplied
+func (r *progressNotifier) OperationID() string {
+	var token string
+	if err := json.Unmarshal(r.token, &token); err == nil {
+		return token
+	}
+	return string(r.token)
+}
+
+// StartTime returns the time the tool call started
+func (r *progressNotifier) StartTime() time.Time {
+	return r.startTime
+}
+
+// Ensure progressNotifier implements the reporter used by the SSE path
+var _ progress.ProgressReporter = (*progressNotifier)(nil)
@@ -0,0 +1,274 @@
+package prompts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/lspecian/maas-mcp-server/internal/models"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+	"github.com/lspecian/maas-mcp-server/pkg/mcp"
+)
+
+// MachineSummary is the part of a machine that matters when planning capacity
+type MachineSummary struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Status       string   `json:"status"`
+	PowerState   string   `json:"power_state"`
+	Architecture string   `json:"architecture"`
+	Zone         string   `json:"zone"`
+	Pool         string   `json:"pool"`
+	CPUCount     int      `json:"cpu_count"`
+	Memory       int64    `json:"memory"`
+	Tags         []string `json:"tags,omitempty"`
+}
+
+// AddressUse is an address of a subnet assigned to a machine interface
+type AddressUse struct {
+	IP        string `json:"ip"`
+	MachineID string `json:"machine_id"`
+	Machine   string `json:"machine"`
+	Interface string `json:"interface"`
+}
+
+// SubnetUsage describes how much of a subnet is assigned to machine interfaces. UsableAddresses
+// and UtilizationPercent are left out for subnets too large to count, such as IPv6 /64s.
+type SubnetUsage struct {
+	CIDR               string       `json:"cidr"`
+	UsableAddresses    uint64       `json:"usable_addresses,omitempty"`
+	UsedAddresses      int          `json:"used_addresses"`
+	UtilizationPercent float64      `json:"utilization_percent,omitempty"`
+	Addresses          []AddressUse `json:"addresses"`
+}
+
+// renderer holds the state of a single prompt rendering. MAAS data is fetched once per rendering,
+// however many messages use it.
+type renderer struct {
+	ctx      context.Context
+	runbooks *Runbooks
+	fetched  map[string]interface{}
+}
+
+// funcs returns the functions available to prompt templates:
+//
+//	machine ID                   the machine, with its network interfaces and storage
+//	machineEvents ID LIMIT       the machine's most recent MAAS events, newest first
+//	machines [KEY VALUE]...      the machines matching the filters, such as "pool" "default"
+//	summarize MACHINES           the capacity-relevant fields of machines
+//	statusCounts MACHINES        the number of machines in each status
+//	subnet CIDR                  the subnet with the given CIDR
+//	subnetUsage CIDR             the addresses of the subnet assigned to machines
+//	atoi STRING                  a positive integer argument
+//	toJSON VALUE                 the value as indented JSON
+func (r *renderer) funcs() template.FuncMap {
+	return template.FuncMap{
+		"machine":       r.machine,
+		"machineEvents": r.machineEvents,
+		"machines":      r.machines,
+		"summarize":     summarize,
+		"statusCounts":  statusCounts,
+		"subnet":        r.subnet,
+		"subnetUsage":   r.subnetUsage,
+		"atoi":          atoi,
+		"toJSON":        toJSON,
+	}
+}
+
+// fetch returns the value stored under key, calling get to fetch it the first time
+func (r *renderer) fetch(key string, get func() (interface{}, error)) (interface{}, error) {
+	if value, ok := r.fetched[key]; ok {
+		return value, nil
+	}
+	value, err := get()
+	if err != nil {
+		return nil, err
+	}
+	r.fetched[key] = value
+	return value, nil
+}
+
+// machine returns a machine by system ID
+func (r *renderer) machine(id string) (*types.MachineContext, error) {
+	value, err := r.fetch("machine:"+id, func() (interface{}, error) {
+		machine, err := r.runbooks.machines.GetMachine(r.ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get machine %s: %w", id, err)
+		}
+		return machine, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*types.MachineContext), nil
+}
+
+// machineEvents returns the most recent events of a machine
+func (r *renderer) machineEvents(id string, limit int) ([]types.MachineEvent, error) {
+	value, err := r.fetch(fmt.Sprintf("events:%s:%d", id, limit), func() (interface{}, error) {
+		events, err := r.runbooks.machines.ListMachineEvents(r.ctx, id, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list events of machine %s: %w", id, err)
+		}
+		return events, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]types.MachineEvent), nil
+}
+
+// machines returns the machines matching filters given as key, value pairs
+func (r *renderer) machines(pairs ...string) ([]types.MachineContext, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("machines takes filter keys and values in pairs")
+	}
+	filters := make(map[string]string, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		filters[pairs[i]] = pairs[i+1]
+	}
+
+	value, err := r.fetch("machines:"+strings.Join(pairs, "\x00"), func() (interface{}, error) {
+		machines, err := r.runbooks.machines.ListMachines(r.ctx, filters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list machines: %w", err)
+		}
+		return machines, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]types.MachineContext), nil
+}
+
+// subnet returns the subnet with the given CIDR
+func (r *renderer) subnet(cidr string) (*models.SubnetContext, error) {
+	if _, err := netip.ParsePrefix(cidr); err != nil {
+		return nil, fmt.Errorf("%w: %s is not a CIDR", mcp.ErrInvalidPromptArguments, cidr)
+	}
+
+	value, err := r.fetch("subnet:"+cidr, func() (interface{}, error) {
+		subnets, err := r.runbooks.subnets.ListSubnets(r.ctx, map[string]string{"cidr": cidr})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list subnets: %w", err)
+		}
+		if len(subnets) == 0 {
+			return nil, fmt.Errorf("%w: no subnet has CIDR %s", mcp.ErrInvalidPromptArguments, cidr)
+		}
+		return &subnets[0], nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*models.SubnetContext), nil
+}
+
+// subnetUsage returns the addresses of a subnet that are assigned to machine interfaces
+func (r *renderer) subnetUsage(cidr string) (*SubnetUsage, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s is not a CIDR", mcp.ErrInvalidPromptArguments, cidr)
+	}
+	prefix = prefix.Masked()
+
+	machines, err := r.machines()
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &SubnetUsage{
+		CIDR:      prefix.String(),
+		Addresses: make([]AddressUse, 0),
+	}
+	for _, machine := range machines {
+		for _, iface := range machine.NetworkInterfaces {
+			addr, err := netip.ParseAddr(iface.IPAddress)
+			if err != nil || !prefix.Contains(addr) {
+				continue
+			}
+			usage.Addresses = append(usage.Addresses, AddressUse{
+				IP:        addr.String(),
+				MachineID: machine.ID,
+				Machine:   machine.Name,
+				Interface: iface.Name,
+			})
+		}
+	}
+	sort.Slice(usage.Addresses, func(i, j int) bool {
+		return netip.MustParseAddr(usage.Addresses[i].IP).Less(netip.MustParseAddr(usage.Addresses[j].IP))
+	})
+	usage.UsedAddresses = len(usage.Addresses)
+
+	if usable := usableAddresses(prefix); usable > 0 {
+		usage.UsableAddresses = usable
+		usage.UtilizationPercent = math.Round(float64(usage.UsedAddresses)/float64(usable)*1000) / 10
+	}
+	return usage, nil
+}
+
+// usableAddresses returns the number of host addresses in a prefix, or zero when there are too many
+// to count. IPv4 subnets larger than a /31 lose their network and broadcast addresses.
+func usableAddresses(prefix netip.Prefix) uint64 {
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostBits >= 64 {
+		return 0
+	}
+	count := uint64(1) << hostBits
+	if prefix.Addr().Is4() && hostBits > 1 {
+		count -= 2
+	}
+	return count
+}
+
+// summarize returns the capacity-relevant fields of machines
+func summarize(machines []types.MachineContext) []MachineSummary {
+	summaries := make([]MachineSummary, len(machines))
+	for i, machine := range machines {
+		summaries[i] = MachineSummary{
+			ID:           machine.ID,
+			Name:         machine.Name,
+			Status:       machine.Status,
+			PowerState:   machine.PowerState,
+			Architecture: machine.Architecture,
+			Zone:         machine.Zone,
+			Pool:         machine.Pool,
+			CPUCount:     machine.CPUCount,
+			Memory:       machine.Memory,
+			Tags:         machine.Tags,
+		}
+	}
+	return summaries
+}
+
+// statusCounts returns the number of machines in each status
+func statusCounts(machines []types.MachineContext) map[string]int {
+	counts := make(map[string]int)
+	for _, machine := range machines {
+		counts[machine.Status]++
+	}
+	return counts
+}
+
+// atoi parses a positive integer argument
+func atoi(value string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%w: %q is not a positive integer", mcp.ErrInvalidPromptArguments, value)
+	}
+	return n, nil
+}
+
+// toJSON formats a value as indented JSON
+func toJSON(value interface{}) (string, error) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to format value as JSON: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,116 @@
+package prompts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/lspecian/maas-mcp-server/internal/models"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+	"github.com/lspecian/maas-mcp-server/pkg/mcp"
+)
+
+// MachineSource provides the machine state embedded in prompts. It is implemented by the machine service.
+type MachineSource interface {
+	GetMachine(ctx context.Context, id string) (*types.MachineContext, error)
+	ListMachines(ctx context.Context, filters map[string]string) ([]types.MachineContext, error)
+	ListMachineEvents(ctx context.Context, id string, limit int) ([]types.MachineEvent, error)
+}
+
+// SubnetSource provides the subnets embedded in prompts. It is implemented by ClientSubnets.
+type SubnetSource interface {
+	ListSubnets(ctx context.Context, filters map[string]string) ([]models.SubnetContext, error)
+}
+
+// Runbooks provides MCP prompts for MAAS runbooks. Every prompt fetches the MAAS state it needs
+// when it is requested, so the messages the client gets describe MAAS as it is now.
+type Runbooks struct {
+	machines MachineSource
+	subnets  SubnetSource
+}
+
+// NewRunbooks creates a new Runbooks instance
+func NewRunbooks(machines MachineSource, subnets SubnetSource) *Runbooks {
+	return &Runbooks{
+		machines: machines,
+		subnets:  subnets,
+	}
+}
+
+// Register registers the built-in runbook prompts, then the prompt templates in templateDir. A
+// template with the name of a built-in prompt replaces it.
+func (r *Runbooks) Register(registry *mcp.Registry, templateDir string) error {
+	builtin, err := BuiltinTemplates()
+	if err != nil {
+		return err
+	}
+	custom, err := LoadTemplates(templateDir)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range append(builtin, custom...) {
+		if err := registry.RegisterPrompt(r.Prompt(t)); err != nil {
+			return fmt.Errorf("failed to register prompt %s: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+// Prompt returns the MCP prompt that renders a template
+func (r *Runbooks) Prompt(t *Template) mcp.PromptInfo {
+	return mcp.PromptInfo{
+		Name:        t.Name,
+		Description: t.Description,
+		Arguments:   t.PromptArguments(),
+		Handler: func(ctx context.Context, arguments map[string]string) (*mcp.PromptResult, error) {
+			return r.render(ctx, t, arguments)
+		},
+	}
+}
+
+// render renders every message of a template with the given arguments
+func (r *Runbooks) render(ctx context.Context, t *Template, arguments map[string]string) (*mcp.PromptResult, error) {
+	// Every declared argument is defined, so optional ones that were left out render as empty
+	data := make(map[string]string, len(t.Arguments))
+	for _, argument := range t.Arguments {
+		data[argument.Name] = ""
+	}
+	for name, value := range arguments {
+		data[name] = value
+	}
+
+	rend := &renderer{
+		ctx:      ctx,
+		runbooks: r,
+		fetched:  make(map[string]interface{}),
+	}
+	funcs := rend.funcs()
+
+	result := &mcp.PromptResult{
+		Description: t.Description,
+		Messages:    make([]mcp.PromptMessage, 0, len(t.Messages)),
+	}
+	for i, message := range t.Messages {
+		tmpl, err := template.New(t.Name).Funcs(funcs).Option("missingkey=error").Parse(message.Text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse message %d of prompt %s: %w", i+1, t.Name, err)
+		}
+
+		var text bytes.Buffer
+		if err := tmpl.Execute(&text, data); err != nil {
+			return nil, fmt.Errorf("failed to render message %d of prompt %s: %w", i+1, t.Name, err)
+		}
+
+		result.Messages = append(result.Messages, mcp.PromptMessage{
+			Role: message.Role,
+			Content: mcp.PromptContent{
+				Type: "text",
+				Text: strings.TrimSpace(text.String()),
+			},
+		})
+	}
+	return result, nil
+}
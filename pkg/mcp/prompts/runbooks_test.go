@@ -0,0 +1,201 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/maas-mcp-server/internal/models"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+	"github.com/lspecian/maas-mcp-server/pkg/mcp"
+)
+
+// fakeMachines serves two machines in the default pool; calls counts the MAAS requests made
+type fakeMachines struct {
+	calls int
+}
+
+func (f *fakeMachines) all() []types.MachineContext {
+	return []types.MachineContext{
+		{
+			ID: "abc123", Name: "node1", Status: "Failed Deployment", PowerState: "off", Pool: "default", CPUCount: 8,
+			NetworkInterfaces: []types.NetworkContext{{Name: "eth0", IPAddress: "10.0.0.10"}},
+		},
+		{
+			ID: "def456", Name: "node2", Status: "Ready", PowerState: "off", Pool: "default", CPUCount: 16,
+			NetworkInterfaces: []types.NetworkContext{{Name: "eth0", IPAddress: "10.0.0.9"}, {Name: "eth1", IPAddress: "192.168.1.5"}},
+		},
+	}
+}
+
+func (f *fakeMachines) GetMachine(ctx context.Context, id string) (*types.MachineContext, error) {
+	f.calls++
+	for _, machine := range f.all() {
+		if machine.ID == id {
+			return &machine, nil
+		}
+	}
+	return nil, fmt.Errorf("machine %s not found", id)
+}
+
+func (f *fakeMachines) ListMachines(ctx context.Context, filters map[string]string) ([]types.MachineContext, error) {
+	f.calls++
+	var machines []types.MachineContext
+	for _, machine := range f.all() {
+		if pool, ok := filters["pool"]; ok && machine.Pool != pool {
+			continue
+		}
+		machines = append(machines, machine)
+	}
+	return machines, nil
+}
+
+func (f *fakeMachines) ListMachineEvents(ctx context.Context, id string, limit int) ([]types.MachineEvent, error) {
+	f.calls++
+	return []types.MachineEvent{
+		{ID: 2, Node: id, Type: "Failed deployment", Level: "ERROR", Description: "curtin failed"},
+		{ID: 1, Node: id, Type: "Deploying", Level: "INFO"},
+	}, nil
+}
+
+// fakeSubnets serves a single subnet
+type fakeSubnets struct{}
+
+func (fakeSubnets) ListSubnets(ctx context.Context, filters map[string]string) ([]models.SubnetContext, error) {
+	if filters["cidr"] != "10.0.0.0/24" {
+		return nil, nil
+	}
+	return []models.SubnetContext{{ID: "1", Name: "pxe", CIDR: "10.0.0.0/24", GatewayIP: "10.0.0.1"}}, nil
+}
+
+// getPrompt renders a registered prompt
+func getPrompt(t *testing.T, registry *mcp.Registry, name string, arguments map[string]string) (*mcp.PromptResult, error) {
+	prompt, ok := registry.GetPrompt(name)
+	require.True(t, ok, "prompt %s is not registered", name)
+	return prompt.Handler(context.Background(), arguments)
+}
+
+func TestRunbooks_Builtin(t *testing.T) {
+	machines := &fakeMachines{}
+	registry := mcp.NewRegistry()
+	require.NoError(t, NewRunbooks(machines, fakeSubnets{}).Register(registry, filepath.Join(t.TempDir(), "missing")))
+
+	var names []string
+	for _, prompt := range registry.ListPrompts() {
+		names = append(names, prompt.Name)
+	}
+	assert.Equal(t, []string{"maas_audit_subnet_utilization", "maas_diagnose_failed_deployment", "maas_plan_capacity"}, names)
+
+	t.Run("diagnose failed deployment", func(t *testing.T) {
+		machines.calls = 0
+		result, err := getPrompt(t, registry, "maas_diagnose_failed_deployment", map[string]string{"system_id": "abc123"})
+		require.NoError(t, err)
+		require.Len(t, result.Messages, 1)
+		assert.Equal(t, "user", result.Messages[0].Role)
+		assert.Equal(t, "text", result.Messages[0].Content.Type)
+		assert.Contains(t, result.Messages[0].Content.Text, `status is now "Failed Deployment"`)
+		assert.Contains(t, result.Messages[0].Content.Text, "curtin failed")
+		assert.Equal(t, 2, machines.calls, "the machine is fetched once however often the template uses it")
+	})
+
+	t.Run("plan capacity", func(t *testing.T) {
+		result, err := getPrompt(t, registry, "maas_plan_capacity", map[string]string{"n": "3", "pool": "default"})
+		require.NoError(t, err)
+		text := result.Messages[0].Content.Text
+		assert.Contains(t, text, "We need 3 machines")
+		assert.Contains(t, text, "The pool has 2 machines")
+		assert.Contains(t, text, `"Ready": 1`)
+
+		_, err = getPrompt(t, registry, "maas_plan_capacity", map[string]string{"n": "many", "pool": "default"})
+		assert.ErrorIs(t, err, mcp.ErrInvalidPromptArguments)
+	})
+
+	t.Run("audit subnet utilization", func(t *testing.T) {
+		result, err := getPrompt(t, registry, "maas_audit_subnet_utilization", map[string]string{"cidr": "10.0.0.0/24"})
+		require.NoError(t, err)
+		text := result.Messages[0].Content.Text
+		assert.Contains(t, text, "10.0.0.0/24 (pxe)")
+		assert.Contains(t, text, "(2 in use)")
+		assert.Contains(t, text, `"usable_addresses": 254`)
+		assert.NotContains(t, text, "192.168.1.5")
+
+		_, err = getPrompt(t, registry, "maas_audit_subnet_utilization", map[string]string{"cidr": "10.9.0.0/24"})
+		assert.ErrorIs(t, err, mcp.ErrInvalidPromptArguments)
+
+		_, err = getPrompt(t, registry, "maas_audit_subnet_utilization", map[string]string{"cidr": "not-a-cidr"})
+		assert.ErrorIs(t, err, mcp.ErrInvalidPromptArguments)
+	})
+}
+
+func TestRunbooks_TemplatesFromDirectory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "power.yaml"), []byte(`
+name: check_power
+description: Check why a machine will not power on
+arguments:
+  - name: system_id
+    required: true
+  - name: note
+messages:
+  - role: user
+    text: "Machine {{ (machine .system_id).Name }} is powered {{ (machine .system_id).PowerState }}.{{ if .note }} {{ .note }}{{ end }}"
+  - role: assistant
+    text: I will check the BMC first.
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plan.yml"), []byte(`
+name: maas_plan_capacity
+messages:
+  - role: user
+    text: Our own capacity runbook
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a template"), 0644))
+
+	registry := mcp.NewRegistry()
+	require.NoError(t, NewRunbooks(&fakeMachines{}, fakeSubnets{}).Register(registry, dir))
+
+	prompt, ok := registry.GetPrompt("check_power")
+	require.True(t, ok)
+	assert.Equal(t, []mcp.PromptArgument{{Name: "system_id", Required: true}, {Name: "note"}}, prompt.Arguments)
+
+	result, err := getPrompt(t, registry, "check_power", map[string]string{"system_id": "abc123"})
+	require.NoError(t, err)
+	require.Len(t, result.Messages, 2)
+	assert.Equal(t, "Machine node1 is powered off.", result.Messages[0].Content.Text)
+	assert.Equal(t, "assistant", result.Messages[1].Role)
+
+	// A template replaces the built-in prompt of the same name
+	result, err = getPrompt(t, registry, "maas_plan_capacity", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Our own capacity runbook", result.Messages[0].Content.Text)
+}
+
+func TestParseTemplate_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"invalid YAML":  "name: [",
+		"no name":       "messages: [{role: user, text: hi}]",
+		"no messages":   "name: empty",
+		"bad role":      "name: bad\nmessages: [{role: system, text: hi}]",
+		"bad template":  "name: bad\nmessages: [{role: user, text: '{{ .x '}]",
+		"unknown func":  "name: bad\nmessages: [{role: user, text: '{{ nope .x }}'}]",
+		"unnamed input": "name: bad\narguments: [{description: x}]\nmessages: [{role: user, text: hi}]",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseTemplate([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadTemplates_InvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("name: broken"), 0644))
+
+	_, err := LoadTemplates(dir)
+	assert.ErrorContains(t, err, "broken.yaml")
+}
@@ -0,0 +1,77 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lspecian/maas-mcp-server/internal/models"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// SubnetClient is the part of the MAAS client that ClientSubnets lists subnets from
+type SubnetClient interface {
+	ListSubnets() ([]types.Subnet, error)
+}
+
+// ClientSubnets is a SubnetSource that lists the subnets of the MAAS client directly
+type ClientSubnets struct {
+	client SubnetClient
+}
+
+// NewClientSubnets creates a SubnetSource over the MAAS client
+func NewClientSubnets(client SubnetClient) *ClientSubnets {
+	return &ClientSubnets{
+		client: client,
+	}
+}
+
+// ListSubnets lists the subnets matching the cidr, name and space filters
+func (s *ClientSubnets) ListSubnets(ctx context.Context, filters map[string]string) ([]models.SubnetContext, error) {
+	subnets, err := s.client.ListSubnets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subnets: %w", err)
+	}
+
+	result := make([]models.SubnetContext, 0, len(subnets))
+	for _, subnet := range subnets {
+		if !matchSubnetFilter(filters, "cidr", subnet.CIDR) ||
+			!matchSubnetFilter(filters, "name", subnet.Name) ||
+			!matchSubnetFilter(filters, "space", subnet.Space) {
+			continue
+		}
+		result = append(result, *models.MaasSubnetToMCPContext(modelSubnet(&subnet)))
+	}
+	return result, nil
+}
+
+// matchSubnetFilter reports whether value matches the filter of the given key, if there is one
+func matchSubnetFilter(filters map[string]string, key, value string) bool {
+	filter, ok := filters[key]
+	return !ok || filter == "" || filter == value
+}
+
+// modelSubnet converts a subnet of the MAAS client to the model the MCP context is built from
+func modelSubnet(subnet *types.Subnet) *models.Subnet {
+	converted := &models.Subnet{
+		ID:          subnet.ID,
+		Name:        subnet.Name,
+		CIDR:        subnet.CIDR,
+		VLANid:      subnet.VLANid,
+		Space:       subnet.Space,
+		GatewayIP:   subnet.GatewayIP,
+		DNSServers:  subnet.DNSServers,
+		Managed:     subnet.Managed,
+		Active:      subnet.Active,
+		AllowDNS:    subnet.AllowDNS,
+		AllowProxy:  subnet.AllowProxy,
+		ResourceURL: subnet.ResourceURL,
+		FabricID:    subnet.FabricID,
+		FabricName:  subnet.FabricName,
+		Description: subnet.Description,
+	}
+	if subnet.VLAN != nil {
+		vlan := models.VLAN(*subnet.VLAN)
+		converted.VLAN = &vlan
+	}
+	return converted
+}
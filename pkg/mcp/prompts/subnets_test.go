@@ -0,0 +1,43 @@
+package prompts
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// fakeSubnetClient serves a PXE subnet on VLAN 5001 and a storage subnet in space "storage"
+type fakeSubnetClient struct{}
+
+func (fakeSubnetClient) ListSubnets() ([]types.Subnet, error) {
+	return []types.Subnet{
+		{ID: 1, Name: "pxe", CIDR: "10.0.0.0/24", GatewayIP: "10.0.0.1", FabricName: "fabric-0", VLAN: &types.VLAN{ID: 5001, Name: "untagged", VID: 0}},
+		{ID: 2, Name: "storage", CIDR: "10.1.0.0/24", Space: "storage"},
+	}, nil
+}
+
+func TestClientSubnets(t *testing.T) {
+	subnets := NewClientSubnets(fakeSubnetClient{})
+
+	all, err := subnets.ListSubnets(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	byCIDR, err := subnets.ListSubnets(context.Background(), map[string]string{"cidr": "10.0.0.0/24"})
+	require.NoError(t, err)
+	require.Len(t, byCIDR, 1)
+	assert.Equal(t, "1", byCIDR[0].ID)
+	assert.Equal(t, "pxe", byCIDR[0].Name)
+	assert.Equal(t, "10.0.0.1", byCIDR[0].GatewayIP)
+	assert.Equal(t, "untagged", byCIDR[0].VLAN)
+	assert.Equal(t, "fabric-0", byCIDR[0].Fabric)
+
+	bySpace, err := subnets.ListSubnets(context.Background(), map[string]string{"space": "storage", "cidr": ""})
+	require.NoError(t, err)
+	require.Len(t, bySpace, 1)
+	assert.Equal(t, "storage", bySpace[0].Name)
+}
@@ -0,0 +1,154 @@
+package prompts
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/lspecian/maas-mcp-server/pkg/mcp"
+)
+
+//go:embed builtin/*.yaml
+var builtinFS embed.FS
+
+// Template is a prompt defined in YAML. The text of each message is a Go text/template that gets
+// the prompt arguments as its data, so {{ .system_id }} is the system_id argument, and can call the
+// data functions of the renderer to embed MAAS state in the message.
+type Template struct {
+	Name        string             `yaml:"name"`
+	Description string             `yaml:"description"`
+	Arguments   []TemplateArgument `yaml:"arguments"`
+	Messages    []TemplateMessage  `yaml:"messages"`
+}
+
+// TemplateArgument describes an argument of a prompt template
+type TemplateArgument struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+}
+
+// TemplateMessage is a message of a prompt template
+type TemplateMessage struct {
+	Role string `yaml:"role"`
+	Text string `yaml:"text"`
+}
+
+// ParseTemplate parses and validates a prompt template
+func ParseTemplate(data []byte) (*Template, error) {
+	var t Template
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Validate checks that the template has a name and messages, and that every message parses
+func (t *Template) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("prompt template name is required")
+	}
+	for _, argument := range t.Arguments {
+		if argument.Name == "" {
+			return fmt.Errorf("prompt template %s has an argument without a name", t.Name)
+		}
+	}
+	if len(t.Messages) == 0 {
+		return fmt.Errorf("prompt template %s has no messages", t.Name)
+	}
+
+	// Functions are bound to a request when the template is rendered; parsing only needs the names
+	funcs := (&renderer{}).funcs()
+	for i, message := range t.Messages {
+		if message.Role != "user" && message.Role != "assistant" {
+			return fmt.Errorf("prompt template %s message %d: role must be user or assistant", t.Name, i+1)
+		}
+		if _, err := template.New(t.Name).Funcs(funcs).Parse(message.Text); err != nil {
+			return fmt.Errorf("prompt template %s message %d: %w", t.Name, i+1, err)
+		}
+	}
+	return nil
+}
+
+// PromptArguments returns the arguments of the template as MCP prompt arguments
+func (t *Template) PromptArguments() []mcp.PromptArgument {
+	arguments := make([]mcp.PromptArgument, len(t.Arguments))
+	for i, argument := range t.Arguments {
+		arguments[i] = mcp.PromptArgument{
+			Name:        argument.Name,
+			Description: argument.Description,
+			Required:    argument.Required,
+		}
+	}
+	return arguments
+}
+
+// BuiltinTemplates returns the MAAS runbook prompts shipped with the server
+func BuiltinTemplates() ([]*Template, error) {
+	entries, err := builtinFS.ReadDir("builtin")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read built-in prompt templates: %w", err)
+	}
+
+	templates := make([]*Template, 0, len(entries))
+	for _, entry := range entries {
+		name := path.Join("builtin", entry.Name())
+		data, err := builtinFS.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read built-in prompt template %s: %w", name, err)
+		}
+		t, err := ParseTemplate(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load built-in prompt template %s: %w", name, err)
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// LoadTemplates loads the prompt templates in the .yaml and .yml files of dir, one template per file,
+// in file name order. A directory that does not exist holds no templates.
+func LoadTemplates(dir string) ([]*Template, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read prompt template directory %s: %w", dir, err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		extension := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (extension != ".yaml" && extension != ".yml") {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	templates := make([]*Template, 0, len(names))
+	for _, name := range names {
+		filePath := filepath.Join(dir, name)
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt template %s: %w", filePath, err)
+		}
+		t, err := ParseTemplate(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load prompt template %s: %w", filePath, err)
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
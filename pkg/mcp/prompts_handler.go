@@ -0,0 +1,135 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// PromptsGetParams represents the parameters for a prompts/get request
+type PromptsGetParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// handlePromptsList handles the prompts/list request
+func (s *StdioServer) handlePromptsList(out responseWriter, id JSONRPCID) {
+	// Get all prompts
+	prompts := s.registry.ListPrompts()
+
+	// Build response
+	response := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"result": map[string]interface{}{
+			"prompts": prompts,
+		},
+		"id": id.String(),
+	}
+
+	// Write response
+	out.writeResponse(response)
+}
+
+// handlePromptsGet handles the prompts/get request
+func (s *StdioServer) handlePromptsGet(ctx context.Context, out responseWriter, id JSONRPCID, params json.RawMessage) error {
+	// Parse params
+	var getParams PromptsGetParams
+	if err := json.Unmarshal(params, &getParams); err != nil {
+		s.logger.WithError(err).Error("Failed to parse prompts/get params")
+		s.writeError(out, id, errorCodeInvalidParams, "Invalid params", err.Error())
+		return err
+	}
+
+	prompt, ok := s.registry.GetPrompt(getParams.Name)
+	if !ok {
+		s.writeError(out, id, errorCodeInvalidParams, "Invalid params", fmt.Sprintf("prompt %s not found", getParams.Name))
+		return fmt.Errorf("prompt not found: %s", getParams.Name)
+	}
+
+	if err := checkPromptArguments(prompt, getParams.Arguments); err != nil {
+		s.writeError(out, id, errorCodeInvalidParams, "Invalid params", err.Error())
+		return err
+	}
+
+	// Render the prompt; the client may cancel it with notifications/cancelled while it fetches
+	ctx, finish := s.requests().start(ctx, id)
+	result, err := prompt.Handler(ctx, getParams.Arguments)
+	if finish() {
+		s.logger.WithField("prompt", getParams.Name).WithField("id", id.String()).Info("Prompt cancelled by client")
+		return nil
+	}
+	if err != nil {
+		s.logger.WithError(err).WithField("prompt", getParams.Name).Error("Failed to render prompt")
+		if errors.Is(err, ErrInvalidPromptArguments) {
+			s.writeError(out, id, errorCodeInvalidParams, "Invalid params", err.Error())
+			return err
+		}
+		s.writeError(out, id, -32000, "Server error", err.Error())
+		return err
+	}
+
+	// Marshal the result
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		s.writeError(out, id, -32000, "Server error", "Failed to format result")
+		return err
+	}
+
+	s.writeResult(out, id, resultJSON)
+	return nil
+}
+
+// handlePromptsList handles prompts/list posted over HTTP
+func (s *Server) handlePromptsList(request JSONRPCRequest) (int, *JSONRPCResponse) {
+	return resultResponse(request.ID, map[string]interface{}{
+		"prompts": s.registry.ListPrompts(),
+	})
+}
+
+// handlePromptsGet handles prompts/get posted over HTTP
+func (s *Server) handlePromptsGet(ctx context.Context, request JSONRPCRequest) (int, *JSONRPCResponse) {
+	var getParams PromptsGetParams
+	if err := json.Unmarshal(request.Params, &getParams); err != nil {
+		return http.StatusBadRequest, errorResponse(request.ID, errorCodeInvalidParams, "Invalid params", err.Error())
+	}
+
+	prompt, ok := s.registry.GetPrompt(getParams.Name)
+	if !ok {
+		return http.StatusBadRequest, errorResponse(request.ID, errorCodeInvalidParams, "Invalid params", fmt.Sprintf("prompt %s not found", getParams.Name))
+	}
+	if err := checkPromptArguments(prompt, getParams.Arguments); err != nil {
+		return http.StatusBadRequest, errorResponse(request.ID, errorCodeInvalidParams, "Invalid params", err.Error())
+	}
+
+	ctx, finish := s.inflight.start(ctx, request.ID)
+	result, err := prompt.Handler(ctx, getParams.Arguments)
+	if finish() {
+		return http.StatusOK, errorResponse(request.ID, -32800, "Request cancelled", nil)
+	}
+	if err != nil {
+		s.logger.WithError(err).WithField("prompt", getParams.Name).Error("Failed to render prompt")
+		if errors.Is(err, ErrInvalidPromptArguments) {
+			return http.StatusBadRequest, errorResponse(request.ID, errorCodeInvalidParams, "Invalid params", err.Error())
+		}
+		return http.StatusInternalServerError, errorResponse(request.ID, -32000, "Server error", err.Error())
+	}
+
+	return resultResponse(request.ID, result)
+}
+
+// checkPromptArguments checks that every required argument of a prompt is given
+func checkPromptArguments(prompt PromptInfo, arguments map[string]string) error {
+	var missing []string
+	for _, argument := range prompt.Arguments {
+		if argument.Required && arguments[argument.Name] == "" {
+			missing = append(missing, argument.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("prompt %s requires arguments: %s", prompt.Name, strings.Join(missing, ", "))
+	}
+	return nil
+}
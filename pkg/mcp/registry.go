@@ -3,19 +3,116 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
+
+	"github.com/lspecian/maas-mcp-server/internal/jsonschema"
 )
 
 // ToolFunc is a function that implements an MCP tool
 type ToolFunc func(ctx context.Context, input json.RawMessage) (json.RawMessage, error)
 
+// ToolSummaryFunc summarizes the output of a tool in a line of text, such as
+// "3 machines, 2 Ready, 1 Deployed". It returns an empty string when it has nothing to say.
+type ToolSummaryFunc func(output json.RawMessage) string
+
 // ToolInfo contains metadata about an MCP tool
 type ToolInfo struct {
-	Name        string          `json:"name"`
-	Description string          `json:"description"`
-	InputSchema json.RawMessage `json:"inputSchema"`
-	Handler     ToolFunc        `json:"-"`
+	Name         string           `json:"name"`
+	Description  string           `json:"description"`
+	InputSchema  json.RawMessage  `json:"inputSchema"`
+	OutputSchema json.RawMessage  `json:"outputSchema,omitempty"`
+	Annotations  *ToolAnnotations `json:"annotations,omitempty"`
+	Handler      ToolFunc         `json:"-"`
+
+	// Summarize describes the output of a successful call in the text content of its
+	// tools/call result, next to the structured content
+	Summarize ToolSummaryFunc `json:"-"`
+
+	// Method is the HTTP method of the MAAS API call behind the tool and Tags are the MAAS API
+	// sections it belongs to, such as "machines"; tool filters match on both
+	Method string   `json:"-"`
+	Tags   []string `json:"-"`
+
+	// Deferred tools are left out of tools/list to keep it short. They can still be called, and
+	// are found with maas_find_tools and run with maas_invoke_tool.
+	Deferred bool `json:"-"`
+
+	// schema and outputSchema are InputSchema and OutputSchema compiled when the tool is registered
+	schema       *jsonschema.Schema
+	outputSchema *jsonschema.Schema
+}
+
+// ValidateInput checks input against the tool's input schema. It returns a *jsonschema.ValidationError
+// listing the violations when the input does not match.
+func (t ToolInfo) ValidateInput(input json.RawMessage) error {
+	if t.schema == nil {
+		return nil
+	}
+	return t.schema.Validate(input)
+}
+
+// Call validates input against the tool's input schema and runs the tool. The handler is not run
+// for input that does not match.
+func (t ToolInfo) Call(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	if err := t.ValidateInput(input); err != nil {
+		return nil, err
+	}
+	return t.Handler(ctx, input)
+}
+
+// ToolFilter reports whether a tool is offered to a user. user is the authenticated user, or
+// empty when the client is not authenticated, as over stdio.
+type ToolFilter func(user string, tool ToolInfo) bool
+
+// ToolAnnotations tell MCP clients how a tool affects MAAS, so they can warn before
+// destructive calls. All hints are always sent, since MCP treats a missing destructiveHint
+// and openWorldHint as true.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    bool   `json:"readOnlyHint"`
+	DestructiveHint bool   `json:"destructiveHint"`
+	IdempotentHint  bool   `json:"idempotentHint"`
+	OpenWorldHint   bool   `json:"openWorldHint"`
+}
+
+// PromptFunc renders an MCP prompt with the arguments supplied by the client
+type PromptFunc func(ctx context.Context, arguments map[string]string) (*PromptResult, error)
+
+// PromptArgument describes an argument of an MCP prompt
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// PromptInfo contains metadata about an MCP prompt
+type PromptInfo struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+	Handler     PromptFunc       `json:"-"`
+}
+
+// PromptContent is the text content of a prompt message
+type PromptContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// PromptMessage is a message of a rendered prompt
+type PromptMessage struct {
+	Role    string        `json:"role"`
+	Content PromptContent `json:"content"`
+}
+
+// PromptResult is a rendered prompt, as returned by prompts/get
+type PromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
 }
 
 // ResourceInfo contains metadata about an MCP resource
@@ -25,11 +122,65 @@ type ResourceInfo struct {
 	URIPattern  string `json:"uri_pattern"`
 }
 
+// ResourceTemplate describes a parameterized resource URI, as listed by resources/templates/list
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceContents is the content of a resource returned by resources/read
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text"`
+}
+
+// ResourceReader reads resources by URI. Errors wrapping ErrResourceNotFound or ErrInvalidResourceURI
+// are reported to the client as such; anything else is a server error.
+type ResourceReader interface {
+	// ReadResource returns the contents of the resource at uri
+	ReadResource(ctx context.Context, uri string) ([]ResourceContents, error)
+
+	// ListResourceTemplates returns the URI templates of the resources that can be read
+	ListResourceTemplates() []ResourceTemplate
+}
+
+// ResourceSubscriber watches resources for changes on behalf of client sessions. notify is called with
+// the URI of a subscribed resource every time it changes, until the subscription is removed.
+type ResourceSubscriber interface {
+	// Subscribe starts watching the resource at uri for a session, checking it every interval.
+	// A zero interval uses the subscriber's default. Subscribing again replaces the subscription.
+	Subscribe(sessionID, uri string, interval time.Duration, notify func(uri string)) error
+
+	// Unsubscribe stops watching the resource at uri for a session
+	Unsubscribe(sessionID, uri string)
+
+	// UnsubscribeAll removes every subscription of a session, for when its client disconnects
+	UnsubscribeAll(sessionID string)
+}
+
+var (
+	// ErrResourceNotFound is returned by a ResourceReader when no resource exists at the URI
+	ErrResourceNotFound = errors.New("resource not found")
+
+	// ErrInvalidResourceURI is returned by a ResourceReader when the URI is malformed
+	ErrInvalidResourceURI = errors.New("invalid resource URI")
+
+	// ErrInvalidPromptArguments is returned by a PromptFunc when the arguments cannot be used
+	ErrInvalidPromptArguments = errors.New("invalid prompt arguments")
+)
+
 // Registry manages MCP tools and resources
 type Registry struct {
-	tools     map[string]ToolInfo
-	resources map[string]ResourceInfo
-	mu        sync.RWMutex
+	tools              map[string]ToolInfo
+	resources          map[string]ResourceInfo
+	prompts            map[string]PromptInfo
+	resourceReader     ResourceReader
+	resourceSubscriber ResourceSubscriber
+	toolFilter         ToolFilter
+	mu                 sync.RWMutex
 }
 
 // NewRegistry creates a new MCP registry
@@ -37,10 +188,12 @@ func NewRegistry() *Registry {
 	return &Registry{
 		tools:     make(map[string]ToolInfo),
 		resources: make(map[string]ResourceInfo),
+		prompts:   make(map[string]PromptInfo),
 	}
 }
 
-// RegisterTool registers an MCP tool
+// RegisterTool registers an MCP tool. Its input and output schemas are compiled, so that inputs can
+// be validated before the handler runs and outputs before they are returned.
 func (r *Registry) RegisterTool(info ToolInfo) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -53,6 +206,18 @@ func (r *Registry) RegisterTool(info ToolInfo) error {
 		return fmt.Errorf("tool handler is required")
 	}
 
+	schema, err := jsonschema.Compile(info.InputSchema)
+	if err != nil {
+		return fmt.Errorf("invalid input schema for tool %s: %w", info.Name, err)
+	}
+	info.schema = schema
+
+	if len(info.OutputSchema) > 0 {
+		if info.outputSchema, err = jsonschema.Compile(info.OutputSchema); err != nil {
+			return fmt.Errorf("invalid output schema for tool %s: %w", info.Name, err)
+		}
+	}
+
 	r.tools[info.Name] = info
 	return nil
 }
@@ -74,6 +239,69 @@ func (r *Registry) RegisterResource(info ResourceInfo) error {
 	return nil
 }
 
+// RegisterPrompt registers an MCP prompt. Registering a prompt under an existing name replaces it.
+func (r *Registry) RegisterPrompt(info PromptInfo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if info.Name == "" {
+		return fmt.Errorf("prompt name is required")
+	}
+
+	if info.Handler == nil {
+		return fmt.Errorf("prompt handler is required")
+	}
+
+	for _, argument := range info.Arguments {
+		if argument.Name == "" {
+			return fmt.Errorf("prompt %s has an argument without a name", info.Name)
+		}
+	}
+
+	r.prompts[info.Name] = info
+	return nil
+}
+
+// SetResourceReader sets the reader that serves resources/read and resources/templates/list
+func (r *Registry) SetResourceReader(reader ResourceReader) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.resourceReader = reader
+}
+
+// GetResourceReader returns the resource reader, if one has been set
+func (r *Registry) GetResourceReader() (ResourceReader, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.resourceReader, r.resourceReader != nil
+}
+
+// SetResourceSubscriber sets the subscriber that serves resources/subscribe and resources/unsubscribe
+func (r *Registry) SetResourceSubscriber(subscriber ResourceSubscriber) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.resourceSubscriber = subscriber
+}
+
+// GetResourceSubscriber returns the resource subscriber, if one has been set
+func (r *Registry) GetResourceSubscriber() (ResourceSubscriber, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.resourceSubscriber, r.resourceSubscriber != nil
+}
+
+// SetToolFilter sets the filter that decides which tools each user is offered
+func (r *Registry) SetToolFilter(filter ToolFilter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.toolFilter = filter
+}
+
 // GetTool returns an MCP tool by name
 func (r *Registry) GetTool(name string) (ToolInfo, bool) {
 	r.mu.RLock()
@@ -83,6 +311,18 @@ func (r *Registry) GetTool(name string) (ToolInfo, bool) {
 	return tool, ok
 }
 
+// GetToolFor returns an MCP tool by name if it is offered to user
+func (r *Registry) GetToolFor(user string, name string) (ToolInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tool, ok := r.tools[name]
+	if !ok || (r.toolFilter != nil && !r.toolFilter(user, tool)) {
+		return ToolInfo{}, false
+	}
+	return tool, true
+}
+
 // GetResource returns an MCP resource by name
 func (r *Registry) GetResource(name string) (ResourceInfo, bool) {
 	r.mu.RLock()
@@ -92,6 +332,15 @@ func (r *Registry) GetResource(name string) (ResourceInfo, bool) {
 	return resource, ok
 }
 
+// GetPrompt returns an MCP prompt by name
+func (r *Registry) GetPrompt(name string) (PromptInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prompt, ok := r.prompts[name]
+	return prompt, ok
+}
+
 // ListTools returns all registered MCP tools
 func (r *Registry) ListTools() []ToolInfo {
 	r.mu.RLock()
@@ -105,6 +354,36 @@ func (r *Registry) ListTools() []ToolInfo {
 	return tools
 }
 
+// ListToolsFor returns the registered MCP tools offered to user, leaving out deferred tools
+func (r *Registry) ListToolsFor(user string) []ToolInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tools := make([]ToolInfo, 0, len(r.tools))
+	for _, tool := range r.tools {
+		if !tool.Deferred && (r.toolFilter == nil || r.toolFilter(user, tool)) {
+			tools = append(tools, tool)
+		}
+	}
+
+	return tools
+}
+
+// ListAllToolsFor returns the registered MCP tools offered to user, including deferred tools
+func (r *Registry) ListAllToolsFor(user string) []ToolInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tools := make([]ToolInfo, 0, len(r.tools))
+	for _, tool := range r.tools {
+		if r.toolFilter == nil || r.toolFilter(user, tool) {
+			tools = append(tools, tool)
+		}
+	}
+
+	return tools
+}
+
 // ListResources returns all registered MCP resources
 func (r *Registry) ListResources() []ResourceInfo {
 	r.mu.RLock()
@@ -118,12 +397,42 @@ func (r *Registry) ListResources() []ResourceInfo {
 	return resources
 }
 
-// ExecuteTool executes an MCP tool by name
+// ListPrompts returns all registered MCP prompts, sorted by name
+func (r *Registry) ListPrompts() []PromptInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prompts := make([]PromptInfo, 0, len(r.prompts))
+	for _, prompt := range r.prompts {
+		prompts = append(prompts, prompt)
+	}
+	sort.Slice(prompts, func(i, j int) bool {
+		return prompts[i].Name < prompts[j].Name
+	})
+
+	return prompts
+}
+
+// ExecuteTool validates the input of an MCP tool and executes it by name
 func (r *Registry) ExecuteTool(ctx context.Context, name string, input json.RawMessage) (json.RawMessage, error) {
 	tool, ok := r.GetTool(name)
 	if !ok {
 		return nil, fmt.Errorf("tool not found: %s", name)
 	}
 
-	return tool.Handler(ctx, input)
+	return tool.Call(ctx, input)
+}
+
+// toolError returns the JSON-RPC error code, message and data for an error of a tool call. Input
+// that does not match the tool's schema is an invalid params error whose data lists the violations
+// by JSON pointer, so the client can correct its call.
+func toolError(err error) (int, string, interface{}) {
+	var validationErr *jsonschema.ValidationError
+	if errors.As(err, &validationErr) {
+		return errorCodeInvalidParams, "Invalid params", map[string]interface{}{
+			"message":    err.Error(),
+			"violations": validationErr.Violations,
+		}
+	}
+	return -32000, "Server error", err.Error()
 }
@@ -0,0 +1,95 @@
+package resources
+
+import (
+	"sync"
+	"time"
+)
+
+// Default cache settings
+const (
+	DefaultCacheTTL        = 5 * time.Minute
+	DefaultCacheMaxEntries = 1000
+)
+
+// cacheEntry is the JSON text of a resource and the time it expires
+type cacheEntry struct {
+	text      string
+	expiresAt time.Time
+}
+
+// Cache holds the JSON text of recently read resources by URI, so that repeated reads do not call
+// MAAS and subscriptions can compare a poll with what clients last read
+type Cache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewCache creates a cache whose entries expire after ttl
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:        ttl,
+		maxEntries: DefaultCacheMaxEntries,
+		entries:    make(map[string]cacheEntry),
+	}
+}
+
+// Get returns the cached text of a resource, if it has not expired
+func (c *Cache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return entry.text, true
+}
+
+// Set caches the text of a resource. When the cache is full, expired entries are dropped first,
+// then the entry closest to expiring.
+func (c *Cache) Set(key, text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = cacheEntry{
+		text:      text,
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+// Delete drops the cached text of a resource
+func (c *Cache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
+
+// evict makes room for an entry; the caller must hold c.mu
+func (c *Cache) evict(now time.Time) {
+	oldestKey := ""
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, entry.expiresAt
+		}
+	}
+	if len(c.entries) >= c.maxEntries {
+		delete(c.entries, oldestKey)
+	}
+}
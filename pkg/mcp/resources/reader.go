@@ -0,0 +1,499 @@
+// Package resources serves MAAS resources, such as maas://machine/{system_id}, over MCP resources/read,
+// resources/templates/list and resources/subscribe.
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lspecian/maas-mcp-server/internal/conversion"
+	"github.com/lspecian/maas-mcp-server/internal/maas/common"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+	"github.com/lspecian/maas-mcp-server/pkg/mcp"
+)
+
+// uriScheme is the scheme of MAAS resource URIs
+const uriScheme = "maas://"
+
+// mimeTypeJSON is the MIME type of every resource
+const mimeTypeJSON = "application/json"
+
+// noCacheParam is the query parameter that makes a read bypass the cache, as in maas://zones?no-cache
+const noCacheParam = "no-cache"
+
+// Source is the part of the MAAS client that resources are read from
+type Source interface {
+	GetMachine(systemID string) (*types.Machine, error)
+	GetMachineInterfaces(systemID string) ([]types.NetworkInterface, error)
+	ListMachinesSimple(ctx context.Context, filters map[string]string) ([]types.Machine, error)
+	ListZones() ([]types.Zone, error)
+	GetZone(name string) (*types.Zone, error)
+	ListResourcePools() ([]types.ResourcePool, error)
+	GetResourcePool(name string) (*types.ResourcePool, error)
+	ListTags() ([]types.Tag, error)
+	GetSpace(id int) (*types.Space, error)
+	GetSubnet(id int) (*types.Subnet, error)
+	ListIPRanges() ([]types.IPRange, error)
+}
+
+// ZoneDetails is a zone together with a count of its machines by status
+type ZoneDetails struct {
+	types.Zone
+	MachineCount     int            `json:"machine_count"`
+	MachinesByStatus map[string]int `json:"machines_by_status"`
+}
+
+// ResourcePoolDetails is a resource pool together with a count of its machines by status
+type ResourcePoolDetails struct {
+	types.ResourcePool
+	MachineCount     int            `json:"machine_count"`
+	MachinesByStatus map[string]int `json:"machines_by_status"`
+}
+
+// TagDetails is a tag together with a count of its machines by status
+type TagDetails struct {
+	types.Tag
+	MachineCount     int            `json:"machine_count"`
+	MachinesByStatus map[string]int `json:"machines_by_status"`
+}
+
+// TagMachines is the machines a tag is applied to
+type TagMachines struct {
+	TagName  string               `json:"tag_name"`
+	Count    int                  `json:"count"`
+	Machines []mcp.MachineSummary `json:"machines"`
+}
+
+// SubnetIPRanges is the IP ranges of a subnet
+type SubnetIPRanges struct {
+	SubnetID int             `json:"subnet_id"`
+	CIDR     string          `json:"cidr"`
+	Ranges   []types.IPRange `json:"ranges"`
+}
+
+// readFunc reads a resource given the values of the parameters of its URI pattern
+type readFunc func(ctx context.Context, params map[string]string) (interface{}, error)
+
+// route is a URI pattern, such as maas://machine/{system_id}, and the function reading its resources
+type route struct {
+	pattern string
+	read    readFunc
+}
+
+// Reader reads MAAS resources by URI and caches what it reads. It implements mcp.ResourceReader.
+type Reader struct {
+	source Source
+	logger *logrus.Logger
+	routes []route
+	cache  *Cache
+}
+
+// NewReader creates a new reader that reads resources from source
+func NewReader(source Source, logger *logrus.Logger) *Reader {
+	r := &Reader{
+		source: source,
+		logger: logger,
+		cache:  NewCache(DefaultCacheTTL),
+	}
+	r.routes = []route{
+		{"maas://machine/{system_id}", r.machine},
+		{"maas://zones", r.zones},
+		{"maas://zone/{zone_name}", r.zone},
+		{"maas://pools", r.pools},
+		{"maas://pool/{pool_name}", r.pool},
+		{"maas://tags", r.tags},
+		{"maas://tag/{tag_name}", r.tag},
+		{"maas://tag/{tag_name}/machines", r.tagMachines},
+		{"maas://space/{space_id}", r.space},
+		{"maas://subnet/{subnet_id}", r.subnet},
+		{"maas://subnet/{subnet_id}/ip-ranges", r.ipRanges("")},
+		{"maas://subnet/{subnet_id}/reserved-ranges", r.ipRanges(types.IPRangeTypeReserved)},
+		{"maas://subnet/{subnet_id}/dynamic-ranges", r.ipRanges(types.IPRangeTypeDynamic)},
+	}
+	return r
+}
+
+// ReadResource reads the resource at uri for MCP resources/read, returning it as JSON text. A resource
+// read in the last DefaultCacheTTL is served from the cache, unless uri has a no-cache query parameter.
+func (r *Reader) ReadResource(ctx context.Context, uri string) ([]mcp.ResourceContents, error) {
+	text, found := "", false
+	if key, cacheable := cacheKey(uri); cacheable {
+		text, found = r.cache.Get(key)
+	}
+	if !found {
+		var err error
+		if text, err = r.read(ctx, uri); err != nil {
+			return nil, err
+		}
+	}
+
+	return []mcp.ResourceContents{
+		{
+			URI:      uri,
+			MimeType: mimeTypeJSON,
+			Text:     text,
+		},
+	}, nil
+}
+
+// read reads the resource at uri from MAAS and refreshes its cache entry. The entry of a resource
+// that no longer exists is dropped, and the error wraps mcp.ErrResourceNotFound.
+func (r *Reader) read(ctx context.Context, uri string) (string, error) {
+	read, params, err := r.match(uri)
+	if err != nil {
+		return "", err
+	}
+	key, _ := cacheKey(uri)
+
+	data, err := read(ctx, params)
+	if err != nil {
+		if errors.Is(err, common.ErrNotFound) {
+			err = fmt.Errorf("%w: %v", mcp.ErrResourceNotFound, err)
+		}
+		if errors.Is(err, mcp.ErrResourceNotFound) {
+			r.cache.Delete(key)
+		}
+		return "", err
+	}
+
+	text, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal resource %s: %w", uri, err)
+	}
+
+	r.cache.Set(key, string(text))
+	return string(text), nil
+}
+
+// ListResourceTemplates returns an MCP resource template for every parameterized URI pattern, sorted
+// by template. Patterns without parameters are fixed resources, not templates.
+func (r *Reader) ListResourceTemplates() []mcp.ResourceTemplate {
+	templates := make([]mcp.ResourceTemplate, 0, len(r.routes))
+	for _, route := range r.routes {
+		if !strings.Contains(route.pattern, "{") {
+			continue
+		}
+		templates = append(templates, newResourceTemplate(route.pattern))
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].URITemplate < templates[j].URITemplate
+	})
+	return templates
+}
+
+// match returns the read function of the pattern uri matches, with the values of its parameters.
+// The query of uri, if any, is ignored.
+func (r *Reader) match(uri string) (readFunc, map[string]string, error) {
+	if !strings.HasPrefix(uri, uriScheme) {
+		return nil, nil, fmt.Errorf("%w: %s is not a %s URI", mcp.ErrInvalidResourceURI, uri, uriScheme)
+	}
+	path, _, _ := strings.Cut(strings.TrimPrefix(uri, uriScheme), "?")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for _, route := range r.routes {
+		if params, ok := matchPattern(route.pattern, segments); ok {
+			return route.read, params, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: %s", mcp.ErrResourceNotFound, uri)
+}
+
+// cacheKey returns the key uri is cached under, which leaves out the query, and whether a read of
+// uri may be served from the cache
+func cacheKey(uri string) (string, bool) {
+	path, query, _ := strings.Cut(uri, "?")
+	values, err := url.ParseQuery(query)
+	_, noCache := values[noCacheParam]
+	return strings.TrimRight(path, "/"), err == nil && !noCache
+}
+
+// matchPattern matches the path segments of a URI against a URI pattern, returning the values of
+// the pattern's {name} parameters
+func matchPattern(pattern string, segments []string) (map[string]string, bool) {
+	patternSegments := strings.Split(strings.TrimPrefix(pattern, uriScheme), "/")
+	if len(patternSegments) != len(segments) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, segment := range patternSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if segments[i] == "" {
+				return nil, false
+			}
+			params[segment[1:len(segment)-1]] = segments[i]
+			continue
+		}
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// newResourceTemplate describes a URI pattern such as maas://subnet/{subnet_id}/ip-ranges. The name
+// is built from the literal path segments ("subnet ip ranges") and the description names the parameters.
+func newResourceTemplate(pattern string) mcp.ResourceTemplate {
+	var nameParts, parameters []string
+	for _, segment := range strings.Split(strings.TrimPrefix(pattern, uriScheme), "/") {
+		if strings.HasPrefix(segment, "{") {
+			parameters = append(parameters, strings.Trim(segment, "{}"))
+			continue
+		}
+		nameParts = append(nameParts, strings.ReplaceAll(segment, "-", " "))
+	}
+	name := strings.Join(nameParts, " ")
+
+	return mcp.ResourceTemplate{
+		URITemplate: pattern,
+		Name:        name,
+		Description: fmt.Sprintf("MAAS %s by %s", name, strings.Join(parameters, ", ")),
+		MimeType:    mimeTypeJSON,
+	}
+}
+
+// machine reads maas://machine/{system_id}
+func (r *Reader) machine(ctx context.Context, params map[string]string) (interface{}, error) {
+	systemID := params["system_id"]
+	machine, err := r.source.GetMachine(systemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get machine %s: %w", systemID, err)
+	}
+	return conversion.MaasMachineToMCPContext(machine, systemID, r.logger, r.source), nil
+}
+
+// zones reads maas://zones
+func (r *Reader) zones(ctx context.Context, params map[string]string) (interface{}, error) {
+	zones, err := r.source.ListZones()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list zones: %w", err)
+	}
+	if zones == nil {
+		zones = []types.Zone{}
+	}
+	return map[string]interface{}{
+		"zones": zones,
+	}, nil
+}
+
+// zone reads maas://zone/{zone_name}, counting the machines of the zone by status
+func (r *Reader) zone(ctx context.Context, params map[string]string) (interface{}, error) {
+	name := params["zone_name"]
+	zone, err := r.source.GetZone(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get zone %s: %w", name, err)
+	}
+
+	machines, err := r.source.ListMachinesSimple(ctx, map[string]string{"zone": name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list machines in zone %s: %w", name, err)
+	}
+
+	return &ZoneDetails{
+		Zone:             *zone,
+		MachineCount:     len(machines),
+		MachinesByStatus: types.CountMachinesByStatus(machines),
+	}, nil
+}
+
+// pools reads maas://pools
+func (r *Reader) pools(ctx context.Context, params map[string]string) (interface{}, error) {
+	pools, err := r.source.ListResourcePools()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource pools: %w", err)
+	}
+	if pools == nil {
+		pools = []types.ResourcePool{}
+	}
+	return map[string]interface{}{
+		"pools": pools,
+	}, nil
+}
+
+// pool reads maas://pool/{pool_name}, counting the machines of the pool by status
+func (r *Reader) pool(ctx context.Context, params map[string]string) (interface{}, error) {
+	name := params["pool_name"]
+	pool, err := r.source.GetResourcePool(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource pool %s: %w", name, err)
+	}
+
+	machines, err := r.source.ListMachinesSimple(ctx, map[string]string{"pool": name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list machines in resource pool %s: %w", name, err)
+	}
+
+	return &ResourcePoolDetails{
+		ResourcePool:     *pool,
+		MachineCount:     len(machines),
+		MachinesByStatus: types.CountMachinesByStatus(machines),
+	}, nil
+}
+
+// tags reads maas://tags
+func (r *Reader) tags(ctx context.Context, params map[string]string) (interface{}, error) {
+	tags, err := r.source.ListTags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	if tags == nil {
+		tags = []types.Tag{}
+	}
+	return map[string]interface{}{
+		"tags": tags,
+	}, nil
+}
+
+// tag reads maas://tag/{tag_name}, counting the machines with the tag by status
+func (r *Reader) tag(ctx context.Context, params map[string]string) (interface{}, error) {
+	tag, err := r.findTag(params["tag_name"])
+	if err != nil {
+		return nil, err
+	}
+
+	machines, err := r.source.ListMachinesSimple(ctx, map[string]string{"tags": tag.Name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list machines with tag %s: %w", tag.Name, err)
+	}
+
+	return &TagDetails{
+		Tag:              *tag,
+		MachineCount:     len(machines),
+		MachinesByStatus: types.CountMachinesByStatus(machines),
+	}, nil
+}
+
+// tagMachines reads maas://tag/{tag_name}/machines
+func (r *Reader) tagMachines(ctx context.Context, params map[string]string) (interface{}, error) {
+	tag, err := r.findTag(params["tag_name"])
+	if err != nil {
+		return nil, err
+	}
+
+	machines, err := r.source.ListMachinesSimple(ctx, map[string]string{"tags": tag.Name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list machines with tag %s: %w", tag.Name, err)
+	}
+
+	summaries := make([]mcp.MachineSummary, 0, len(machines))
+	for _, machine := range machines {
+		summaries = append(summaries, mcp.MachineSummary{
+			SystemID:   machine.SystemID,
+			Hostname:   machine.Hostname,
+			FQDN:       machine.FQDN,
+			Status:     machine.Status,
+			Zone:       machine.Zone,
+			Pool:       machine.Pool,
+			Tags:       machine.Tags,
+			PowerState: machine.PowerState,
+		})
+	}
+
+	return &TagMachines{
+		TagName:  tag.Name,
+		Count:    len(summaries),
+		Machines: summaries,
+	}, nil
+}
+
+// findTag returns the tag with the given name. MAAS has no call for a single tag, so the tags are
+// listed and searched.
+func (r *Reader) findTag(name string) (*types.Tag, error) {
+	tags, err := r.source.ListTags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	for i := range tags {
+		if tags[i].Name == name {
+			return &tags[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no tag named %s", mcp.ErrResourceNotFound, name)
+}
+
+// space reads maas://space/{space_id}
+func (r *Reader) space(ctx context.Context, params map[string]string) (interface{}, error) {
+	id, err := intParam(params, "space_id")
+	if err != nil {
+		return nil, err
+	}
+
+	space, err := r.source.GetSpace(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get space %d: %w", id, err)
+	}
+	return space, nil
+}
+
+// subnet reads maas://subnet/{subnet_id}
+func (r *Reader) subnet(ctx context.Context, params map[string]string) (interface{}, error) {
+	id, err := intParam(params, "subnet_id")
+	if err != nil {
+		return nil, err
+	}
+
+	subnet, err := r.source.GetSubnet(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subnet %d: %w", id, err)
+	}
+	return subnet, nil
+}
+
+// ipRanges returns the read function of the IP ranges of a subnet, only those of rangeType unless
+// it is empty
+func (r *Reader) ipRanges(rangeType string) readFunc {
+	return func(ctx context.Context, params map[string]string) (interface{}, error) {
+		id, err := intParam(params, "subnet_id")
+		if err != nil {
+			return nil, err
+		}
+
+		subnet, err := r.source.GetSubnet(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get subnet %d: %w", id, err)
+		}
+
+		allRanges, err := r.source.ListIPRanges()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list IP ranges: %w", err)
+		}
+
+		ranges := make([]types.IPRange, 0)
+		for _, ipRange := range allRanges {
+			if ipRange.SubnetID != id {
+				continue
+			}
+			if rangeType != "" && ipRange.Type != rangeType {
+				continue
+			}
+			ranges = append(ranges, ipRange)
+		}
+
+		return &SubnetIPRanges{
+			SubnetID: id,
+			CIDR:     subnet.CIDR,
+			Ranges:   ranges,
+		}, nil
+	}
+}
+
+// intParam returns the value of an integer URI parameter
+func intParam(params map[string]string, name string) (int, error) {
+	value, err := strconv.Atoi(params[name])
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s must be an integer, got %q", mcp.ErrInvalidResourceURI, name, params[name])
+	}
+	return value, nil
+}
+
+// Ensure Reader can serve MCP resources
+var _ mcp.ResourceReader = (*Reader)(nil)
@@ -0,0 +1,226 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/maas-mcp-server/internal/maas/common"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+	"github.com/lspecian/maas-mcp-server/pkg/mcp"
+)
+
+// fakeSource serves machine abc123 in zone "default" and pool "default", tag "gpu", space 1 and
+// subnet 2 with a reserved and a dynamic range. The test can change the hostname of the machine, or
+// remove it.
+type fakeSource struct {
+	mu       sync.Mutex
+	hostname string
+	removed  bool
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{hostname: "node1"}
+}
+
+func (f *fakeSource) GetMachine(systemID string) (*types.Machine, error) {
+	if systemID != "abc123" {
+		return nil, errors.New("no Machine matches the given query")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.removed {
+		return nil, fmt.Errorf("maas API error getting machine %s: %w", systemID, common.ErrNotFound)
+	}
+	return &types.Machine{SystemID: "abc123", Hostname: f.hostname, Status: "Ready", Zone: "default", Pool: "default"}, nil
+}
+
+func (f *fakeSource) removeMachine() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.removed = true
+}
+
+func (f *fakeSource) setHostname(hostname string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hostname = hostname
+}
+
+func (f *fakeSource) GetMachineInterfaces(systemID string) ([]types.NetworkInterface, error) {
+	return []types.NetworkInterface{{ID: 1, Name: "eth0"}}, nil
+}
+
+func (f *fakeSource) ListMachinesSimple(ctx context.Context, filters map[string]string) ([]types.Machine, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return []types.Machine{{SystemID: "abc123", Hostname: f.hostname, Status: "Ready"}, {SystemID: "def456", Status: "Deployed"}}, nil
+}
+
+func (f *fakeSource) ListZones() ([]types.Zone, error) {
+	return []types.Zone{{ID: 1, Name: "default"}}, nil
+}
+
+func (f *fakeSource) GetZone(name string) (*types.Zone, error) {
+	return &types.Zone{ID: 1, Name: name, Description: "Rack A"}, nil
+}
+
+func (f *fakeSource) ListResourcePools() ([]types.ResourcePool, error) {
+	return nil, nil
+}
+
+func (f *fakeSource) GetResourcePool(name string) (*types.ResourcePool, error) {
+	return &types.ResourcePool{ID: 0, Name: name}, nil
+}
+
+func (f *fakeSource) ListTags() ([]types.Tag, error) {
+	return []types.Tag{{Name: "gpu", Comment: "GPU nodes"}}, nil
+}
+
+func (f *fakeSource) GetSpace(id int) (*types.Space, error) {
+	return &types.Space{ID: id, Name: "prod"}, nil
+}
+
+func (f *fakeSource) GetSubnet(id int) (*types.Subnet, error) {
+	return &types.Subnet{ID: id, CIDR: "10.0.0.0/24"}, nil
+}
+
+func (f *fakeSource) ListIPRanges() ([]types.IPRange, error) {
+	return []types.IPRange{
+		{ID: 1, Type: types.IPRangeTypeReserved, StartIP: "10.0.0.2", EndIP: "10.0.0.9", SubnetID: 2},
+		{ID: 2, Type: types.IPRangeTypeDynamic, StartIP: "10.0.0.100", EndIP: "10.0.0.199", SubnetID: 2},
+		{ID: 3, Type: types.IPRangeTypeDynamic, StartIP: "10.1.0.100", EndIP: "10.1.0.199", SubnetID: 3},
+	}, nil
+}
+
+// readJSON reads the resource at uri and decodes its text
+func readJSON(t *testing.T, reader *Reader, uri string) map[string]interface{} {
+	contents, err := reader.ReadResource(context.Background(), uri)
+	require.NoError(t, err)
+	require.Len(t, contents, 1)
+	assert.Equal(t, uri, contents[0].URI)
+	assert.Equal(t, "application/json", contents[0].MimeType)
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(contents[0].Text), &data))
+	return data
+}
+
+func TestReader_ReadResource(t *testing.T) {
+	reader := NewReader(newFakeSource(), logrus.New())
+
+	machine := readJSON(t, reader, "maas://machine/abc123")
+	assert.Equal(t, "abc123", machine["id"])
+	assert.Equal(t, "node1", machine["name"])
+
+	zone := readJSON(t, reader, "maas://zone/default")
+	assert.Equal(t, "Rack A", zone["description"])
+	assert.EqualValues(t, 2, zone["machine_count"])
+	assert.Equal(t, map[string]interface{}{"Ready": float64(1), "Deployed": float64(1)}, zone["machines_by_status"])
+
+	pools := readJSON(t, reader, "maas://pools")
+	assert.Equal(t, []interface{}{}, pools["pools"])
+
+	tag := readJSON(t, reader, "maas://tag/gpu")
+	assert.Equal(t, "GPU nodes", tag["comment"])
+	assert.EqualValues(t, 2, tag["machine_count"])
+
+	tagMachines := readJSON(t, reader, "maas://tag/gpu/machines")
+	assert.EqualValues(t, 2, tagMachines["count"])
+	require.Len(t, tagMachines["machines"], 2)
+	assert.Equal(t, "abc123", tagMachines["machines"].([]interface{})[0].(map[string]interface{})["id"])
+
+	subnet := readJSON(t, reader, "maas://subnet/2")
+	assert.Equal(t, "10.0.0.0/24", subnet["cidr"])
+
+	space := readJSON(t, reader, "maas://space/1?nocache=true")
+	assert.Equal(t, "prod", space["name"])
+
+	ranges := readJSON(t, reader, "maas://subnet/2/dynamic-ranges")
+	assert.Equal(t, "10.0.0.0/24", ranges["cidr"])
+	require.Len(t, ranges["ranges"], 1)
+	assert.Equal(t, "10.0.0.100", ranges["ranges"].([]interface{})[0].(map[string]interface{})["start_ip"])
+
+	allRanges := readJSON(t, reader, "maas://subnet/2/ip-ranges")
+	assert.Len(t, allRanges["ranges"], 2)
+
+	reserved := readJSON(t, reader, "maas://subnet/2/reserved-ranges")
+	require.Len(t, reserved["ranges"], 1)
+	assert.Equal(t, "reserved", reserved["ranges"].([]interface{})[0].(map[string]interface{})["type"])
+}
+
+func TestReader_ReadResourceErrors(t *testing.T) {
+	reader := NewReader(newFakeSource(), logrus.New())
+
+	_, err := reader.ReadResource(context.Background(), "http://machine/abc123")
+	assert.ErrorIs(t, err, mcp.ErrInvalidResourceURI)
+
+	_, err = reader.ReadResource(context.Background(), "maas://space/prod")
+	assert.ErrorIs(t, err, mcp.ErrInvalidResourceURI)
+
+	_, err = reader.ReadResource(context.Background(), "maas://fabrics")
+	assert.ErrorIs(t, err, mcp.ErrResourceNotFound)
+
+	_, err = reader.ReadResource(context.Background(), "maas://tag/missing/machines")
+	assert.ErrorIs(t, err, mcp.ErrResourceNotFound)
+
+	_, err = reader.ReadResource(context.Background(), "maas://machine/")
+	assert.ErrorIs(t, err, mcp.ErrResourceNotFound)
+
+	_, err = reader.ReadResource(context.Background(), "maas://machine/missing")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, mcp.ErrResourceNotFound)
+}
+
+func TestReader_Cache(t *testing.T) {
+	source := newFakeSource()
+	reader := NewReader(source, logrus.New())
+
+	assert.Equal(t, "node1", readJSON(t, reader, "maas://machine/abc123")["name"])
+
+	source.setHostname("node2")
+	assert.Equal(t, "node1", readJSON(t, reader, "maas://machine/abc123")["name"], "a cached read does not call MAAS")
+	assert.Equal(t, "node2", readJSON(t, reader, "maas://machine/abc123?no-cache")["name"])
+	assert.Equal(t, "node2", readJSON(t, reader, "maas://machine/abc123")["name"], "an uncached read refreshes the cache")
+
+	source.removeMachine()
+	_, err := reader.ReadResource(context.Background(), "maas://machine/abc123?no-cache=true")
+	assert.ErrorIs(t, err, mcp.ErrResourceNotFound)
+	_, err = reader.ReadResource(context.Background(), "maas://machine/abc123")
+	assert.ErrorIs(t, err, mcp.ErrResourceNotFound, "a removed resource is dropped from the cache")
+}
+
+func TestReader_ListResourceTemplates(t *testing.T) {
+	templates := NewReader(newFakeSource(), logrus.New()).ListResourceTemplates()
+
+	uris := make([]string, 0, len(templates))
+	for _, template := range templates {
+		uris = append(uris, template.URITemplate)
+	}
+	assert.Equal(t, []string{
+		"maas://machine/{system_id}",
+		"maas://pool/{pool_name}",
+		"maas://space/{space_id}",
+		"maas://subnet/{subnet_id}",
+		"maas://subnet/{subnet_id}/dynamic-ranges",
+		"maas://subnet/{subnet_id}/ip-ranges",
+		"maas://subnet/{subnet_id}/reserved-ranges",
+		"maas://tag/{tag_name}",
+		"maas://tag/{tag_name}/machines",
+		"maas://zone/{zone_name}",
+	}, uris)
+
+	assert.Equal(t, mcp.ResourceTemplate{
+		URITemplate: "maas://subnet/{subnet_id}/ip-ranges",
+		Name:        "subnet ip ranges",
+		Description: "MAAS subnet ip ranges by subnet_id",
+		MimeType:    "application/json",
+	}, templates[5])
+}
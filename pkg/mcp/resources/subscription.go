@@ -0,0 +1,282 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lspecian/maas-mcp-server/pkg/mcp"
+)
+
+// Default subscription settings
+const (
+	DefaultSubscriptionInterval = 30 * time.Second
+	MinSubscriptionInterval     = 5 * time.Second
+
+	// subscriptionPollTimeout bounds a single read of a subscribed resource
+	subscriptionPollTimeout = 30 * time.Second
+)
+
+// subscriptionKey identifies a subscription by client session and resource URI
+type subscriptionKey struct {
+	sessionID string
+	uri       string
+}
+
+// subscription watches a single resource for a single session
+type subscription struct {
+	uri      string
+	interval time.Duration
+	notify   func(uri string)
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// SubscriptionManager polls subscribed MAAS resources and notifies the subscribing session when one
+// changes. Every subscription polls at its own interval. A change is the resource reading differently
+// from what was last seen, starting from the reader's cache entry when there is one; each poll
+// refreshes that entry, so later reads get the new data. It implements mcp.ResourceSubscriber.
+type SubscriptionManager struct {
+	reader *Reader
+	logger *logrus.Logger
+
+	defaultInterval time.Duration
+	minInterval     time.Duration
+
+	mu            sync.Mutex
+	subscriptions map[subscriptionKey]*subscription
+}
+
+// NewSubscriptionManager creates a subscription manager that reads resources through reader
+func NewSubscriptionManager(reader *Reader, logger *logrus.Logger) *SubscriptionManager {
+	return &SubscriptionManager{
+		reader:          reader,
+		logger:          logger,
+		defaultInterval: DefaultSubscriptionInterval,
+		minInterval:     MinSubscriptionInterval,
+		subscriptions:   make(map[subscriptionKey]*subscription),
+	}
+}
+
+// SetIntervals sets the interval used when a subscription does not ask for one, and the shortest
+// interval a subscription may ask for
+func (m *SubscriptionManager) SetIntervals(defaultInterval, minInterval time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.defaultInterval = defaultInterval
+	m.minInterval = minInterval
+}
+
+// Subscribe starts polling the resource at uri for a session. Intervals below the minimum are raised
+// to it. Subscribing to a resource the session already watches replaces the subscription.
+func (m *SubscriptionManager) Subscribe(sessionID, uri string, interval time.Duration, notify func(uri string)) error {
+	if _, _, err := m.reader.match(uri); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := &subscription{
+		uri:    uri,
+		notify: notify,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	key := subscriptionKey{sessionID: sessionID, uri: uri}
+	m.mu.Lock()
+	sub.interval = m.clampInterval(interval)
+	previous := m.subscriptions[key]
+	m.subscriptions[key] = sub
+	m.mu.Unlock()
+
+	if previous != nil {
+		previous.stop()
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"uri":        uri,
+		"interval":   sub.interval.String(),
+	}).Info("Resource subscription started")
+
+	go m.watch(ctx, sub)
+	return nil
+}
+
+// Unsubscribe stops polling the resource at uri for a session. No notification is sent for it once
+// Unsubscribe has returned.
+func (m *SubscriptionManager) Unsubscribe(sessionID, uri string) {
+	key := subscriptionKey{sessionID: sessionID, uri: uri}
+	m.mu.Lock()
+	sub := m.subscriptions[key]
+	delete(m.subscriptions, key)
+	m.mu.Unlock()
+
+	if sub != nil {
+		sub.stop()
+		m.logger.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"uri":        uri,
+		}).Info("Resource subscription stopped")
+	}
+}
+
+// UnsubscribeAll stops every subscription of a session
+func (m *SubscriptionManager) UnsubscribeAll(sessionID string) {
+	m.mu.Lock()
+	var stopped []*subscription
+	for key, sub := range m.subscriptions {
+		if key.sessionID == sessionID {
+			stopped = append(stopped, sub)
+			delete(m.subscriptions, key)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, sub := range stopped {
+		sub.stop()
+	}
+	if len(stopped) > 0 {
+		m.logger.WithFields(logrus.Fields{
+			"session_id":    sessionID,
+			"subscriptions": len(stopped),
+		}).Info("Resource subscriptions of session stopped")
+	}
+}
+
+// Close stops every subscription
+func (m *SubscriptionManager) Close() {
+	m.mu.Lock()
+	stopped := make([]*subscription, 0, len(m.subscriptions))
+	for key, sub := range m.subscriptions {
+		stopped = append(stopped, sub)
+		delete(m.subscriptions, key)
+	}
+	m.mu.Unlock()
+
+	for _, sub := range stopped {
+		sub.stop()
+	}
+}
+
+// clampInterval applies the default and minimum intervals; the caller must hold m.mu
+func (m *SubscriptionManager) clampInterval(interval time.Duration) time.Duration {
+	if interval <= 0 {
+		interval = m.defaultInterval
+	}
+	if interval < m.minInterval {
+		interval = m.minInterval
+	}
+	return interval
+}
+
+// watch polls a subscribed resource until the subscription is stopped
+func (m *SubscriptionManager) watch(ctx context.Context, sub *subscription) {
+	defer close(sub.done)
+
+	logger := m.logger.WithField("uri", sub.uri)
+
+	// The cached resource is what clients last read, so it is the baseline; without one, the first
+	// poll sets it
+	last, seen := m.cachedFingerprint(sub)
+	if !seen {
+		fingerprint, err := m.poll(ctx, sub)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to read subscribed resource")
+		} else {
+			last, seen = fingerprint, true
+		}
+	}
+
+	ticker := time.NewTicker(sub.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		fingerprint, err := m.poll(ctx, sub)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.WithError(err).Warn("Failed to read subscribed resource")
+			}
+			continue
+		}
+		if seen && fingerprint == last {
+			continue
+		}
+
+		changed := seen
+		last, seen = fingerprint, true
+		if changed && ctx.Err() == nil {
+			logger.Debug("Subscribed resource changed")
+			sub.notify(sub.uri)
+		}
+	}
+}
+
+// poll reads a subscribed resource from MAAS, which refreshes its cache entry, and returns its text
+// for comparison. A resource that no longer exists reads as empty, so its removal is a change.
+func (m *SubscriptionManager) poll(ctx context.Context, sub *subscription) (string, error) {
+	pollCtx, cancel := context.WithTimeout(ctx, subscriptionPollTimeout)
+	defer cancel()
+
+	text, err := m.reader.read(pollCtx, sub.uri)
+	if err != nil {
+		if errors.Is(err, mcp.ErrResourceNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return fingerprintText(text), nil
+}
+
+// cachedFingerprint returns the text of the cached resource of a subscription for comparison
+func (m *SubscriptionManager) cachedFingerprint(sub *subscription) (string, bool) {
+	key, cacheable := cacheKey(sub.uri)
+	if !cacheable {
+		return "", false
+	}
+	text, found := m.reader.cache.Get(key)
+	if !found {
+		return "", false
+	}
+	return fingerprintText(text), true
+}
+
+// fingerprintText returns the text of a resource for comparison. The MCP contexts are stamped with
+// the time they were built in last_updated, which changes with every read, so it is left out.
+func fingerprintText(text string) string {
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &data); err != nil {
+		return text
+	}
+	if _, ok := data["last_updated"]; !ok {
+		return text
+	}
+	delete(data, "last_updated")
+
+	fingerprint, err := json.Marshal(data)
+	if err != nil {
+		return text
+	}
+	return string(fingerprint)
+}
+
+// stop cancels the subscription and waits for its polling to end
+func (s *subscription) stop() {
+	s.cancel()
+	<-s.done
+}
+
+// Ensure SubscriptionManager can serve MCP resource subscriptions
+var _ mcp.ResourceSubscriber = (*SubscriptionManager)(nil)
@@ -0,0 +1,171 @@
+package resources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/maas-mcp-server/pkg/mcp"
+)
+
+func newTestSubscriptionManager(t *testing.T) (*SubscriptionManager, *fakeSource) {
+	source := newFakeSource()
+	manager := NewSubscriptionManager(NewReader(source, logrus.New()), logrus.New())
+	manager.SetIntervals(10*time.Millisecond, 10*time.Millisecond)
+	t.Cleanup(manager.Close)
+	return manager, source
+}
+
+// notifications returns a notify function and the channel it sends URIs on
+func notifications() (func(uri string), chan string) {
+	updates := make(chan string, 16)
+	return func(uri string) { updates <- uri }, updates
+}
+
+func TestSubscriptionManager_NotifiesOnChange(t *testing.T) {
+	manager, source := newTestSubscriptionManager(t)
+	notify, updates := notifications()
+
+	require.NoError(t, manager.Subscribe("session1", "maas://machine/abc123", 0, notify))
+
+	// Nothing has changed yet
+	select {
+	case uri := <-updates:
+		t.Fatalf("unexpected update for %s", uri)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	source.setHostname("node2")
+	select {
+	case uri := <-updates:
+		assert.Equal(t, "maas://machine/abc123", uri)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no update after the resource changed")
+	}
+}
+
+func TestSubscriptionManager_NotifiesOnRemoval(t *testing.T) {
+	manager, source := newTestSubscriptionManager(t)
+	notify, updates := notifications()
+
+	require.NoError(t, manager.Subscribe("session1", "maas://machine/abc123", 0, notify))
+	time.Sleep(50 * time.Millisecond)
+
+	source.removeMachine()
+	select {
+	case uri := <-updates:
+		assert.Equal(t, "maas://machine/abc123", uri)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no update after the resource was removed")
+	}
+
+	// A resource that stays removed is not a change
+	select {
+	case uri := <-updates:
+		t.Fatalf("unexpected update for %s", uri)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSubscriptionManager_TagMachines(t *testing.T) {
+	manager, source := newTestSubscriptionManager(t)
+	notify, updates := notifications()
+
+	require.NoError(t, manager.Subscribe("session1", "maas://tag/gpu/machines", 0, notify))
+	time.Sleep(50 * time.Millisecond)
+
+	source.setHostname("node2")
+	select {
+	case uri := <-updates:
+		assert.Equal(t, "maas://tag/gpu/machines", uri)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no update after a machine with the tag changed")
+	}
+}
+
+func TestSubscriptionManager_ComparesWithCache(t *testing.T) {
+	manager, source := newTestSubscriptionManager(t)
+	notify, updates := notifications()
+
+	// The client read the machine before it changed, so the first poll is already a change
+	_, err := manager.reader.ReadResource(context.Background(), "maas://machine/abc123")
+	require.NoError(t, err)
+	source.setHostname("node2")
+
+	require.NoError(t, manager.Subscribe("session1", "maas://machine/abc123", 0, notify))
+	select {
+	case uri := <-updates:
+		assert.Equal(t, "maas://machine/abc123", uri)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no update for a change since the cached read")
+	}
+
+	// The poll refreshed the cache
+	contents, err := manager.reader.ReadResource(context.Background(), "maas://machine/abc123")
+	require.NoError(t, err)
+	assert.Contains(t, contents[0].Text, `"node2"`)
+}
+
+func TestSubscriptionManager_IgnoresReadTime(t *testing.T) {
+	manager, _ := newTestSubscriptionManager(t)
+	notify, updates := notifications()
+
+	// Machine contexts are stamped with the time of every read
+	require.NoError(t, manager.Subscribe("session1", "maas://machine/abc123", 0, notify))
+
+	select {
+	case uri := <-updates:
+		t.Fatalf("unexpected update for %s", uri)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSubscriptionManager_Unsubscribe(t *testing.T) {
+	manager, source := newTestSubscriptionManager(t)
+	notify1, updates1 := notifications()
+	notify2, updates2 := notifications()
+
+	require.NoError(t, manager.Subscribe("session1", "maas://machine/abc123", 0, notify1))
+	require.NoError(t, manager.Subscribe("session2", "maas://machine/abc123", 0, notify2))
+
+	// Let both subscriptions read their baseline before the resource changes
+	time.Sleep(50 * time.Millisecond)
+
+	manager.UnsubscribeAll("session1")
+	source.setHostname("node2")
+
+	select {
+	case <-updates2:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no update for the remaining session")
+	}
+	assert.Empty(t, updates1)
+
+	manager.Unsubscribe("session2", "maas://machine/abc123")
+	source.setHostname("node3")
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, updates2)
+}
+
+func TestSubscriptionManager_SubscribeErrors(t *testing.T) {
+	manager, _ := newTestSubscriptionManager(t)
+	notify, _ := notifications()
+
+	err := manager.Subscribe("session1", "not a uri", 0, notify)
+	assert.ErrorIs(t, err, mcp.ErrInvalidResourceURI)
+
+	err = manager.Subscribe("session1", "maas://fabrics", 0, notify)
+	assert.ErrorIs(t, err, mcp.ErrResourceNotFound)
+}
+
+func TestSubscriptionManager_ClampsInterval(t *testing.T) {
+	manager := NewSubscriptionManager(nil, logrus.New())
+
+	assert.Equal(t, DefaultSubscriptionInterval, manager.clampInterval(0))
+	assert.Equal(t, MinSubscriptionInterval, manager.clampInterval(time.Second))
+	assert.Equal(t, time.Minute, manager.clampInterval(time.Minute))
+}
@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Error codes for resources/read, as defined by the MCP specification
+const (
+	errorCodeInvalidParams    = -32602
+	errorCodeResourceNotFound = -32002
+)
+
+// ResourcesReadParams represents the parameters for a resources/read request
+type ResourcesReadParams struct {
+	URI string `json:"uri"`
+}
+
+// handleResourceTemplatesList handles the resources/templates/list request
+func (s *StdioServer) handleResourceTemplatesList(out responseWriter, id JSONRPCID) {
+	templates := []ResourceTemplate{}
+	if reader, ok := s.registry.GetResourceReader(); ok {
+		templates = append(templates, reader.ListResourceTemplates()...)
+	}
+
+	// Build response
+	response := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"result": map[string]interface{}{
+			"resourceTemplates": templates,
+		},
+		"id": id.String(),
+	}
+
+	// Write response
+	out.writeResponse(response)
+}
+
+// handleResourcesRead handles the resources/read request
+func (s *StdioServer) handleResourcesRead(ctx context.Context, out responseWriter, id JSONRPCID, params json.RawMessage) error {
+	// Parse params
+	var readParams ResourcesReadParams
+	if err := json.Unmarshal(params, &readParams); err != nil {
+		s.logger.WithError(err).Error("Failed to parse resources/read params")
+		s.writeError(out, id, errorCodeInvalidParams, "Invalid params", err.Error())
+		return err
+	}
+	if readParams.URI == "" {
+		s.writeError(out, id, errorCodeInvalidParams, "Invalid params", "uri is required")
+		return fmt.Errorf("uri is required")
+	}
+
+	reader, ok := s.registry.GetResourceReader()
+	if !ok {
+		s.writeError(out, id, errorCodeResourceNotFound, "Resource not found", map[string]string{"uri": readParams.URI})
+		return fmt.Errorf("no resource reader registered")
+	}
+
+	// Read the resource; the client may cancel it with notifications/cancelled while it runs
+	ctx, finish := s.requests().start(ctx, id)
+	contents, err := reader.ReadResource(ctx, readParams.URI)
+	if finish() {
+		s.logger.WithField("uri", readParams.URI).WithField("id", id.String()).Info("Resource read cancelled by client")
+		return nil
+	}
+	if err != nil {
+		s.logger.WithError(err).WithField("uri", readParams.URI).Error("Failed to read resource")
+		code, message := resourceErrorCode(err)
+		if code == errorCodeResourceNotFound {
+			s.writeError(out, id, code, message, map[string]string{"uri": readParams.URI})
+			return err
+		}
+		s.writeError(out, id, code, message, err.Error())
+		return err
+	}
+
+	// Marshal the result
+	result, err := json.Marshal(map[string]interface{}{
+		"contents": contents,
+	})
+	if err != nil {
+		s.writeError(out, id, -32000, "Server error", "Failed to format result")
+		return err
+	}
+
+	s.writeResult(out, id, result)
+	return nil
+}
+
+// handleResourceTemplatesList handles resources/templates/list posted over HTTP
+func (s *Server) handleResourceTemplatesList(request JSONRPCRequest) (int, *JSONRPCResponse) {
+	templates := []ResourceTemplate{}
+	if reader, ok := s.registry.GetResourceReader(); ok {
+		templates = append(templates, reader.ListResourceTemplates()...)
+	}
+	return resultResponse(request.ID, map[string]interface{}{
+		"resourceTemplates": templates,
+	})
+}
+
+// handleResourcesRead handles resources/read posted over HTTP
+func (s *Server) handleResourcesRead(ctx context.Context, request JSONRPCRequest) (int, *JSONRPCResponse) {
+	var readParams ResourcesReadParams
+	if err := json.Unmarshal(request.Params, &readParams); err != nil {
+		return http.StatusBadRequest, errorResponse(request.ID, errorCodeInvalidParams, "Invalid params", err.Error())
+	}
+	if readParams.URI == "" {
+		return http.StatusBadRequest, errorResponse(request.ID, errorCodeInvalidParams, "Invalid params", "uri is required")
+	}
+
+	reader, ok := s.registry.GetResourceReader()
+	if !ok {
+		return http.StatusNotFound, errorResponse(request.ID, errorCodeResourceNotFound, "Resource not found", map[string]string{"uri": readParams.URI})
+	}
+
+	ctx, finish := s.inflight.start(ctx, request.ID)
+	contents, err := reader.ReadResource(ctx, readParams.URI)
+	if finish() {
+		return http.StatusOK, errorResponse(request.ID, -32800, "Request cancelled", nil)
+	}
+	if err != nil {
+		s.logger.WithError(err).WithField("uri", readParams.URI).Error("Failed to read resource")
+		switch code, message := resourceErrorCode(err); code {
+		case errorCodeResourceNotFound:
+			return http.StatusNotFound, errorResponse(request.ID, code, message, map[string]string{"uri": readParams.URI})
+		case errorCodeInvalidParams:
+			return http.StatusBadRequest, errorResponse(request.ID, code, message, err.Error())
+		default:
+			return http.StatusInternalServerError, errorResponse(request.ID, code, message, err.Error())
+		}
+	}
+
+	return resultResponse(request.ID, map[string]interface{}{
+		"contents": contents,
+	})
+}
@@ -5,9 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lspecian/maas-mcp-server/internal/service/progress"
+	"github.com/lspecian/maas-mcp-server/internal/transport/mcp/events"
 	"github.com/lspecian/maas-mcp-server/internal/version"
 	"github.com/sirupsen/logrus"
 )
@@ -17,6 +22,41 @@ type Server struct {
 	registry *Registry
 	logger   *logrus.Logger
 	router   *gin.Engine
+	inflight *inflightRequests
+
+	// hub tracks the open SSE streams, keyed by session ID, and fans events out to them
+	hub *eventHub
+
+	// httpSessions holds the sessions of the Streamable HTTP transport, keyed by session ID, and
+	// replay the recent events of their streams, keyed by stream ID
+	httpSessionsMu sync.Mutex
+	httpSessions   map[string]*httpSession
+	replay         *progress.ReconnectionManager
+
+	// sweeping is set while idle sessions are being swept, which is done every sessionSweepInterval.
+	// Sessions idle for sessionIdleTimeout are ended, and finished streams that were not resumed
+	// within finishedStreamRetention are forgotten.
+	sweeping                bool
+	sessionSweepInterval    time.Duration
+	sessionIdleTimeout      time.Duration
+	finishedStreamRetention time.Duration
+}
+
+// authenticatedUserKey is the gin context key under which the auth middleware stores the username
+const authenticatedUserKey = "authenticated_user"
+
+// userContextKey is the context key of the authenticated user of an HTTP request
+type userContextKey struct{}
+
+// withUser returns a context carrying the authenticated user, whose tool filter applies to the request
+func withUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+// userFromContext returns the authenticated user of a request, or "" when there is none
+func userFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(userContextKey{}).(string)
+	return user
 }
 
 // NewServer creates a new MCP server
@@ -27,6 +67,15 @@ func NewServer(registry *Registry, logger *logrus.Logger) *Server {
 		registry: registry,
 		logger:   logger,
 		router:   router,
+		inflight: newInflightRequests(logger),
+		hub:      newEventHub(logger),
+
+		httpSessions: make(map[string]*httpSession),
+		replay:       progress.NewReconnectionManager(streamReplayBuffer),
+
+		sessionSweepInterval:    sessionSweepInterval,
+		sessionIdleTimeout:      sessionIdleTimeout,
+		finishedStreamRetention: finishedStreamRetention,
 	}
 
 	// Register routes
@@ -37,16 +86,25 @@ func NewServer(registry *Registry, logger *logrus.Logger) *Server {
 
 // registerRoutes registers the MCP server routes
 func (s *Server) registerRoutes() {
-	// MCP discovery endpoint
-	s.router.GET("/mcp", s.handleDiscovery)
+	// MCP discovery endpoint, and the SSE stream of a Streamable HTTP session
+	s.router.GET("/mcp", s.handleGet)
 
 	// MCP JSON-RPC endpoint
 	s.router.POST("/mcp", s.handleJSONRPC)
 
-	// MCP SSE endpoint
+	// End of a Streamable HTTP session
+	s.router.DELETE("/mcp", s.handleDeleteSession)
+
+	// MCP SSE endpoint of the older HTTP+SSE transport
 	s.router.GET("/mcp/sse", s.handleSSE)
 }
 
+// SetEventQueue sets the number of events queued for each SSE client and what happens to a client
+// whose queue is full. Zero values keep the defaults, a queue of 64 events and dropping events.
+func (s *Server) SetEventQueue(size int, policy SlowClientPolicy) {
+	s.hub.configure(size, policy)
+}
+
 // Run starts the MCP server
 func (s *Server) Run(addr string) error {
 	return s.router.Run(addr)
@@ -59,8 +117,8 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // handleDiscovery handles the MCP discovery endpoint
 func (s *Server) handleDiscovery(c *gin.Context) {
-	// Get all tools and resources
-	tools := s.registry.ListTools()
+	// Get the tools offered to the user, and all resources
+	tools := s.registry.ListToolsFor(c.GetString(authenticatedUserKey))
 	resources := s.registry.ListResources()
 
 	// Build response
@@ -138,11 +196,50 @@ type JSONRPCError struct {
 	Data    any    `json:"data,omitempty"`
 }
 
-// handleJSONRPC handles the MCP JSON-RPC endpoint
+// handleJSONRPC handles the MCP JSON-RPC endpoint. Requests carrying the Mcp-Session-Id header belong
+// to a Streamable HTTP session; requests without it are answered as before sessions existed.
 func (s *Server) handleJSONRPC(c *gin.Context) {
+	session, ok := s.requestSession(c)
+	if !ok {
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error: &JSONRPCError{
+				Code:    -32700,
+				Message: "Parse error",
+				Data:    err.Error(),
+			},
+			ID: JSONRPCID(""),
+		})
+		return
+	}
+
+	ctx := withUser(c.Request.Context(), c.GetString(authenticatedUserKey))
+
+	// Resource updates go to the session, or to the SSE stream of the older transport
+	sessionID := c.Query("session_id")
+	if session != nil {
+		sessionID = session.id
+	}
+
+	// A JSON array is a batch of requests
+	if isBatch(body) {
+		status, responses := s.processBatch(ctx, sessionID, body)
+		if responses == nil {
+			c.Status(status)
+			return
+		}
+		c.JSON(status, responses)
+		return
+	}
+
 	// Parse request
 	var request JSONRPCRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
+	if err := json.Unmarshal(body, &request); err != nil {
 		c.JSON(http.StatusBadRequest, JSONRPCResponse{
 			JSONRPC: "2.0",
 			Error: &JSONRPCError{
@@ -155,9 +252,29 @@ func (s *Server) handleJSONRPC(c *gin.Context) {
 		return
 	}
 
+	// A tool call of a session may be answered with a stream carrying its progress
+	if session != nil && request.Method == "tools/call" && request.ID != "" && acceptsEventStream(c) {
+		s.streamToolsCall(c, session, request)
+		return
+	}
+
+	status, response := s.processRequest(ctx, sessionID, request)
+	if request.Method == "initialize" && session == nil && response != nil && response.Error == nil {
+		c.Header(sessionHeader, s.openHTTPSession(c.GetString(authenticatedUserKey)).id)
+	}
+	if response == nil {
+		c.Status(status)
+		return
+	}
+	c.JSON(status, response)
+}
+
+// processRequest handles a parsed request. It returns the HTTP status and the response, which is
+// nil for notifications. sessionID is the SSE stream the request was posted for, if any.
+func (s *Server) processRequest(ctx context.Context, sessionID string, request JSONRPCRequest) (int, *JSONRPCResponse) {
 	// Validate request
 	if request.JSONRPC != "2.0" {
-		c.JSON(http.StatusBadRequest, JSONRPCResponse{
+		return http.StatusBadRequest, &JSONRPCResponse{
 			JSONRPC: "2.0",
 			Error: &JSONRPCError{
 				Code:    -32600,
@@ -165,14 +282,48 @@ func (s *Server) handleJSONRPC(c *gin.Context) {
 				Data:    "jsonrpc must be 2.0",
 			},
 			ID: request.ID,
-		})
-		return
+		}
 	}
 
-	// Get tool
-	tool, ok := s.registry.GetTool(request.Method)
+	// Handle cancellation of a request running on another connection; notifications get no response body
+	if request.Method == "notifications/cancelled" {
+		s.inflight.handleCancelled(request.Params)
+		return http.StatusAccepted, nil
+	}
+	if strings.HasPrefix(request.Method, "notifications/") {
+		return http.StatusAccepted, nil
+	}
+
+	// Resource subscriptions are tied to the SSE stream that receives the updates
+	switch request.Method {
+	case "initialize":
+		return s.handleInitialize(request)
+	case "ping":
+		return http.StatusOK, &JSONRPCResponse{JSONRPC: "2.0", Result: json.RawMessage(`{}`), ID: request.ID}
+	case "tools/list":
+		return s.handleToolsList(ctx, request)
+	case "resources/list":
+		return s.handleResourcesList(request)
+	case "resources/templates/list":
+		return s.handleResourceTemplatesList(request)
+	case "resources/read":
+		return s.handleResourcesRead(ctx, request)
+	case "prompts/list":
+		return s.handlePromptsList(request)
+	case "prompts/get":
+		return s.handlePromptsGet(ctx, request)
+	case "resources/subscribe":
+		return s.handleResourcesSubscribe(sessionID, request)
+	case "resources/unsubscribe":
+		return s.handleResourcesUnsubscribe(sessionID, request)
+	case "tools/call":
+		return s.handleToolsCall(ctx, request)
+	}
+
+	// Get tool; tools the user is not offered are not found
+	tool, ok := s.registry.GetToolFor(userFromContext(ctx), request.Method)
 	if !ok {
-		c.JSON(http.StatusBadRequest, JSONRPCResponse{
+		return http.StatusBadRequest, &JSONRPCResponse{
 			JSONRPC: "2.0",
 			Error: &JSONRPCError{
 				Code:    -32601,
@@ -180,41 +331,159 @@ func (s *Server) handleJSONRPC(c *gin.Context) {
 				Data:    fmt.Sprintf("method %s not found", request.Method),
 			},
 			ID: request.ID,
-		})
-		return
+		}
 	}
 
-	// Execute tool
-	result, err := tool.Handler(c.Request.Context(), request.Params)
+	// Execute tool; the client may cancel it with notifications/cancelled while it runs
+	ctx, finish := s.inflight.start(ctx, request.ID)
+	result, err := tool.Call(ctx, request.Params)
+	if finish() {
+		// The HTTP request is still open, so it is answered with the cancellation error
+		return http.StatusOK, &JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error: &JSONRPCError{
+				Code:    -32800,
+				Message: "Request cancelled",
+			},
+			ID: request.ID,
+		}
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, JSONRPCResponse{
+		status := http.StatusInternalServerError
+		code, message, data := toolError(err)
+		if code == errorCodeInvalidParams {
+			status = http.StatusBadRequest
+		}
+		return status, &JSONRPCResponse{
 			JSONRPC: "2.0",
 			Error: &JSONRPCError{
-				Code:    -32000,
-				Message: "Server error",
-				Data:    err.Error(),
+				Code:    code,
+				Message: message,
+				Data:    data,
 			},
 			ID: request.ID,
-		})
-		return
+		}
 	}
 
 	// Return result
-	c.JSON(http.StatusOK, JSONRPCResponse{
+	return http.StatusOK, &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result:  result,
+		ID:      request.ID,
+	}
+}
+
+// handleToolsCall handles a tools/call request. A tool that fails is answered with an isError
+// result; only invalid arguments are JSON-RPC errors.
+func (s *Server) handleToolsCall(ctx context.Context, request JSONRPCRequest) (int, *JSONRPCResponse) {
+	errorResponse := func(status int, code int, message string, data interface{}) (int, *JSONRPCResponse) {
+		return status, &JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error:   &JSONRPCError{Code: code, Message: message, Data: data},
+			ID:      request.ID,
+		}
+	}
+
+	var params ToolsCallParams
+	if err := json.Unmarshal(request.Params, &params); err != nil {
+		return errorResponse(http.StatusBadRequest, errorCodeInvalidParams, "Invalid params", err.Error())
+	}
+	if params.Name == "" {
+		return errorResponse(http.StatusBadRequest, errorCodeInvalidParams, "Invalid params", "tool name is required")
+	}
+
+	tool, ok := s.registry.GetToolFor(userFromContext(ctx), params.Name)
+	if !ok {
+		return errorResponse(http.StatusBadRequest, -32601, "Method not found", fmt.Sprintf("tool %s not found", params.Name))
+	}
+
+	// Progress is only reported when the response is a stream that can carry it
+	if notify := notifierFromContext(ctx); notify != nil {
+		if token := params.progressToken(); token != nil {
+			ctx = progress.ContextWithReporter(ctx, newProgressNotifier(notify, token))
+		}
+	}
+
+	ctx, finish := s.inflight.start(ctx, request.ID)
+	output, err := tool.Call(ctx, params.Arguments)
+	if finish() {
+		return errorResponse(http.StatusOK, -32800, "Request cancelled", nil)
+	}
+	if err != nil {
+		if code, message, data := toolError(err); code == errorCodeInvalidParams {
+			return errorResponse(http.StatusBadRequest, code, message, data)
+		}
+	}
+
+	result, err := json.Marshal(newToolCallResult(tool, output, err))
+	if err != nil {
+		return errorResponse(http.StatusInternalServerError, -32000, "Server error", "Failed to format result")
+	}
+	return http.StatusOK, &JSONRPCResponse{
 		JSONRPC: "2.0",
 		Result:  result,
 		ID:      request.ID,
+	}
+}
+
+// handleInitialize handles the initialize request. The session it starts, if any, is set up by the caller.
+func (s *Server) handleInitialize(request JSONRPCRequest) (int, *JSONRPCResponse) {
+	var params struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if len(request.Params) > 0 {
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return http.StatusBadRequest, errorResponse(request.ID, errorCodeInvalidParams, "Invalid params", err.Error())
+		}
+	}
+
+	_, hasSubscriber := s.registry.GetResourceSubscriber()
+	return resultResponse(request.ID, initializeResult(params.ProtocolVersion, hasSubscriber))
+}
+
+// handleToolsList handles the tools/list request, listing the tools offered to the user
+func (s *Server) handleToolsList(ctx context.Context, request JSONRPCRequest) (int, *JSONRPCResponse) {
+	return resultResponse(request.ID, map[string]interface{}{
+		"tools": s.registry.ListToolsFor(userFromContext(ctx)),
+	})
+}
+
+// handleResourcesList handles the resources/list request
+func (s *Server) handleResourcesList(request JSONRPCRequest) (int, *JSONRPCResponse) {
+	return resultResponse(request.ID, map[string]interface{}{
+		"resources": s.registry.ListResources(),
 	})
 }
 
+// resultResponse builds a successful JSON-RPC response
+func resultResponse(id JSONRPCID, result interface{}) (int, *JSONRPCResponse) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return http.StatusInternalServerError, errorResponse(id, -32000, "Server error", "Failed to format result")
+	}
+	return http.StatusOK, &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result:  data,
+		ID:      id,
+	}
+}
+
 // SSEEvent represents a server-sent event
 type SSEEvent struct {
 	Event string
 	Data  string
 	ID    string
+
+	// OperationID and MachineID tell what the event is about. They select the clients that
+	// receive a broadcast event and are not sent.
+	OperationID string
+	MachineID   string
 }
 
-// handleSSE handles the MCP SSE endpoint
+// handleSSE handles the MCP SSE endpoint. Each stream is a session: the first event is "endpoint",
+// giving the URL to post requests to, and notifications for the session follow as "message" events.
+// Events published on the server follow too, filtered by the operation_id, machine_id and event_type
+// query parameters.
 func (s *Server) handleSSE(c *gin.Context) {
 	// Set headers for SSE
 	c.Writer.Header().Set("Content-Type", "text/event-stream")
@@ -222,48 +491,106 @@ func (s *Server) handleSSE(c *gin.Context) {
 	c.Writer.Header().Set("Connection", "keep-alive")
 	c.Writer.Header().Set("Transfer-Encoding", "chunked")
 
-	// Create a channel for events
-	events := make(chan SSEEvent)
-
-	// Create a context that is canceled when the client disconnects
-	ctx, cancel := context.WithCancel(c.Request.Context())
-	defer cancel()
-
-	// Start a goroutine to send events
-	go func() {
-		// Send a ping event every 30 seconds to keep the connection alive
-		ticker := time.NewTicker(30 * time.Second)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				// Client disconnected
-				return
-			case event := <-events:
-				// Send event
-				fmt.Fprintf(c.Writer, "event: %s\n", event.Event)
-				fmt.Fprintf(c.Writer, "data: %s\n", event.Data)
-				if event.ID != "" {
-					fmt.Fprintf(c.Writer, "id: %s\n", event.ID)
-				}
-				fmt.Fprintf(c.Writer, "\n")
-				c.Writer.Flush()
-			case <-ticker.C:
-				// Send ping
-				fmt.Fprintf(c.Writer, "event: ping\n")
-				fmt.Fprintf(c.Writer, "data: %d\n", time.Now().Unix())
-				fmt.Fprintf(c.Writer, "\n")
-				c.Writer.Flush()
-			}
+	// Register the session; it ends, along with its subscriptions, when the client disconnects
+	sessionID := uuid.New().String()
+	client := s.openSession(sessionID, parseEventFilter(c.Request.URL.Query()))
+	defer s.closeSession(sessionID)
+
+	ctx := c.Request.Context()
+	writeEvent := func(event SSEEvent) {
+		writeSSEEvent(c.Writer, event)
+	}
+
+	writeEvent(SSEEvent{Event: "endpoint", Data: "/mcp?session_id=" + sessionID})
+
+	// Send a ping event every 30 seconds to keep the connection alive
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Client disconnected
+			return
+		case <-client.gone:
+			// The client fell too far behind and has to reconnect
+			return
+		case event := <-client.events:
+			writeEvent(event)
+		case <-ticker.C:
+			writeEvent(SSEEvent{Event: "ping", Data: fmt.Sprintf("%d", time.Now().Unix())})
 		}
-	}()
+	}
+}
 
-	// Wait for client to disconnect
-	<-ctx.Done()
+// openSession connects an SSE session to the hub and returns the client its events are queued for
+func (s *Server) openSession(sessionID string, filter EventFilter) *sseClient {
+	client := s.hub.connect(sessionID, filter)
+	s.logger.WithField("session_id", sessionID).Info("SSE session opened")
+	return client
 }
 
-// SendEvent sends an event to all connected SSE clients
-func (s *Server) SendEvent(event SSEEvent) {
-	// TODO: Implement event broadcasting to all connected clients
+// closeSession removes an SSE session and the resource subscriptions made for it
+func (s *Server) closeSession(sessionID string) {
+	s.hub.disconnect(sessionID)
+
+	if subscriber, ok := s.registry.GetResourceSubscriber(); ok {
+		subscriber.UnsubscribeAll(sessionID)
+	}
+	s.logger.WithField("session_id", sessionID).Info("SSE session closed")
 }
+
+// hasSession reports whether an SSE session or a Streamable HTTP session is open
+func (s *Server) hasSession(sessionID string) bool {
+	if s.hub.has(sessionID) {
+		return true
+	}
+	_, ok := s.getHTTPSession(sessionID)
+	return ok
+}
+
+// sendNotification sends a JSON-RPC notification to an SSE session as a "message" event. The event is
+// dropped if the session has closed, and the slow client policy applies if it is too far behind. A
+// Streamable HTTP session gets it on the stream it opened with a GET.
+func (s *Server) sendNotification(sessionID string, method string, params interface{}) {
+	data, err := notificationMessage(method, params)
+	if err != nil {
+		s.logger.WithError(err).WithField("method", method).Error("Failed to marshal notification")
+		return
+	}
+	if s.publishNotification(sessionID, data) {
+		return
+	}
+
+	s.hub.send(sessionID, SSEEvent{Event: "message", Data: string(data)})
+}
+
+// SendEvent sends an event to every connected SSE client whose filter it matches, and returns the
+// number of clients it was queued for
+func (s *Server) SendEvent(event SSEEvent) int {
+	return s.hub.broadcast(event)
+}
+
+// Publish sends a server-side event, such as a machine status change, to the SSE clients and the
+// Streamable HTTP sessions that follow its operation, machine and type. It implements events.Publisher.
+func (s *Server) Publish(event events.Event) {
+	name, data, err := event.ToSSE()
+	if err != nil {
+		s.logger.WithError(err).WithField("event", string(event.Type())).Error("Failed to encode event")
+		return
+	}
+
+	operationID, machineID := event.Subject()
+	sseEvent := SSEEvent{
+		Event:       name,
+		Data:        string(data),
+		ID:          event.ID(),
+		OperationID: operationID,
+		MachineID:   machineID,
+	}
+	s.SendEvent(sseEvent)
+	s.publishEvent(sseEvent)
+}
+
+// Ensure Server can publish the events of services
+var _ events.Publisher = (*Server)(nil)
@@ -0,0 +1,506 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/maas-mcp-server/internal/service/progress"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestHTTPServer creates a Server with a registry holding a working and a failing tool
+func setupTestHTTPServer(t *testing.T) *Server {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	registry := NewRegistry()
+	registerTestTool(t, registry, "power_state", func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(`{"state":"on"}`), nil
+	})
+	registerTestTool(t, registry, "failing_tool", func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+		return nil, fmt.Errorf("tool failed")
+	})
+
+	return NewServer(registry, logger)
+}
+
+// postJSONRPC posts a JSON-RPC payload to the server
+func postJSONRPC(server *Server, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	server.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestHandleJSONRPC_SingleRequest(t *testing.T) {
+	server := setupTestHTTPServer(t)
+
+	recorder := postJSONRPC(server, `{"jsonrpc":"2.0","method":"power_state","params":{},"id":1}`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response JSONRPCResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, JSONRPCID("1"), response.ID)
+	assert.JSONEq(t, `{"state":"on"}`, string(response.Result))
+
+	recorder = postJSONRPC(server, `{"jsonrpc":"2.0","method":"failing_tool","params":{},"id":2}`)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	// Input that does not match the tool's schema is rejected before the tool runs
+	recorder = postJSONRPC(server, `{"jsonrpc":"2.0","method":"power_state","params":{"name":5},"id":3}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.NotNil(t, response.Error)
+	assert.Equal(t, -32602, response.Error.Code)
+	assert.Contains(t, recorder.Body.String(), `{"pointer":"/name","message":"must be a string, not integer"}`)
+}
+
+// TestHandleJSONRPC_ToolsCall tests that tools/call answers with content blocks and reports a
+// failing tool in its result
+func TestHandleJSONRPC_ToolsCall(t *testing.T) {
+	server := setupTestHTTPServer(t)
+
+	recorder := postJSONRPC(server, `{"jsonrpc":"2.0","method":"tools/call","params":{"name":"power_state","arguments":{}},"id":1}`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var response JSONRPCResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.JSONEq(t, `{"content":[{"type":"text","text":"{\"state\":\"on\"}"}],"structuredContent":{"state":"on"}}`, string(response.Result))
+
+	recorder = postJSONRPC(server, `{"jsonrpc":"2.0","method":"tools/call","params":{"name":"failing_tool","arguments":{}},"id":2}`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Nil(t, response.Error)
+	assert.JSONEq(t, `{"content":[{"type":"text","text":"tool failed"}],"isError":true}`, string(response.Result))
+
+	recorder = postJSONRPC(server, `{"jsonrpc":"2.0","method":"tools/call","params":{"name":"power_state","arguments":{"name":5}},"id":3}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"code":-32602`)
+
+	recorder = postJSONRPC(server, `{"jsonrpc":"2.0","method":"tools/call","params":{"name":"missing_tool"},"id":4}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "tool missing_tool not found")
+}
+
+// TestToolFilter_HTTP tests that an authenticated user only sees and calls the tools offered to them
+func TestToolFilter_HTTP(t *testing.T) {
+	server := setupTestHTTPServer(t)
+	server.registry.SetToolFilter(func(user string, tool ToolInfo) bool {
+		return user != "alice" || tool.Name == "power_state"
+	})
+
+	serve := func(user string, method string, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest(method, "/mcp", strings.NewReader(body))
+		if user != "" {
+			c.Set(authenticatedUserKey, user)
+		}
+		if method == http.MethodGet {
+			server.handleDiscovery(c)
+		} else {
+			server.handleJSONRPC(c)
+		}
+		return recorder
+	}
+
+	var discovery struct {
+		Result struct {
+			Capabilities struct {
+				Tools []ToolInfo `json:"tools"`
+			} `json:"capabilities"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(serve("alice", http.MethodGet, "").Body.Bytes(), &discovery))
+	require.Len(t, discovery.Result.Capabilities.Tools, 1)
+	assert.Equal(t, "power_state", discovery.Result.Capabilities.Tools[0].Name)
+
+	recorder := serve("alice", http.MethodPost, `{"jsonrpc":"2.0","method":"failing_tool","params":{},"id":1}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Method not found")
+
+	// Batched requests are filtered too
+	recorder = serve("alice", http.MethodPost, `[{"jsonrpc":"2.0","method":"failing_tool","params":{},"id":1}]`)
+	assert.Contains(t, recorder.Body.String(), "Method not found")
+
+	// Other users get every tool
+	recorder = serve("bob", http.MethodPost, `{"jsonrpc":"2.0","method":"failing_tool","params":{},"id":1}`)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
+func TestHandleJSONRPC_Batch(t *testing.T) {
+	server := setupTestHTTPServer(t)
+
+	recorder := postJSONRPC(server, `[
+		{"jsonrpc":"2.0","method":"power_state","params":{},"id":1},
+		{"jsonrpc":"2.0","method":"failing_tool","params":{},"id":2},
+		{"jsonrpc":"2.0","method":"missing_tool","params":{},"id":3},
+		{"jsonrpc":"2.0","method":"power_state","params":{}},
+		{"method":"power_state","id":4},
+		"not a request"
+	]`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var responses []JSONRPCResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responses))
+	require.Len(t, responses, 5)
+
+	byID := make(map[JSONRPCID]JSONRPCResponse)
+	for _, response := range responses {
+		byID[response.ID] = response
+	}
+	assert.Nil(t, byID["1"].Error)
+	assert.Equal(t, -32000, byID["2"].Error.Code)
+	assert.Equal(t, -32601, byID["3"].Error.Code)
+	assert.Equal(t, -32600, byID["4"].Error.Code)
+	assert.Equal(t, -32600, byID[""].Error.Code)
+}
+
+func TestHandleJSONRPC_BatchEdgeCases(t *testing.T) {
+	server := setupTestHTTPServer(t)
+
+	t.Run("empty batch", func(t *testing.T) {
+		recorder := postJSONRPC(server, `[]`)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+		var response JSONRPCResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, -32600, response.Error.Code)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		recorder := postJSONRPC(server, `[{"jsonrpc":"2.0","method":"power_state"`)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+		var response JSONRPCResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, -32700, response.Error.Code)
+	})
+
+	t.Run("only notifications", func(t *testing.T) {
+		recorder := postJSONRPC(server, `[{"jsonrpc":"2.0","method":"power_state","params":{}}]`)
+		assert.Equal(t, http.StatusAccepted, recorder.Code)
+		assert.Empty(t, recorder.Body.String())
+	})
+}
+
+// readSSEEvent reads the next event of the given type from an SSE stream and returns its data
+func readSSEEvent(t *testing.T, reader *bufio.Reader, eventType string) string {
+	event := ""
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: ") && event == eventType:
+			return strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestResourcesSubscribe_SSE(t *testing.T) {
+	server := setupTestHTTPServer(t)
+	subscriber := newFakeResourceSubscriber()
+	server.registry.SetResourceSubscriber(subscriber)
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	ctx, disconnect := context.WithCancel(context.Background())
+	defer disconnect()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+"/mcp/sse", nil)
+	require.NoError(t, err)
+	stream, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer stream.Body.Close()
+	events := bufio.NewReader(stream.Body)
+
+	// The endpoint event names the session the subscription belongs to
+	endpoint := readSSEEvent(t, events, "endpoint")
+	require.True(t, strings.HasPrefix(endpoint, "/mcp?session_id="))
+	sessionID := strings.TrimPrefix(endpoint, "/mcp?session_id=")
+
+	response, err := http.Post(httpServer.URL+endpoint, "application/json",
+		strings.NewReader(`{"jsonrpc":"2.0","method":"resources/subscribe","params":{"uri":"maas://machine/abc123"},"id":1}`))
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	require.True(t, subscriber.update(sessionID, "maas://machine/abc123"))
+	var notification map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(readSSEEvent(t, events, "message")), &notification))
+	assert.Equal(t, "notifications/resources/updated", notification["method"])
+	assert.Equal(t, "maas://machine/abc123", notification["params"].(map[string]interface{})["uri"])
+
+	// Disconnecting ends the session and its subscriptions
+	disconnect()
+	assert.Eventually(t, func() bool {
+		sessions := subscriber.unsubscribedAllSessions()
+		return len(sessions) == 1 && sessions[0] == sessionID
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.False(t, subscriber.update(sessionID, "maas://machine/abc123"))
+}
+
+func TestResourcesSubscribe_WithoutSession(t *testing.T) {
+	server := setupTestHTTPServer(t)
+	server.registry.SetResourceSubscriber(newFakeResourceSubscriber())
+
+	recorder := postJSONRPC(server, `{"jsonrpc":"2.0","method":"resources/subscribe","params":{"uri":"maas://machine/abc123"},"id":1}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	var response JSONRPCResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, errorCodeInvalidParams, response.Error.Code)
+}
+
+// readSSEMessage reads the next "message" event of an SSE stream and returns its ID and data
+func readSSEMessage(t *testing.T, reader *bufio.Reader) (string, string) {
+	event, id, data := "", "", ""
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		case line == "" && event == "message":
+			return id, data
+		case line == "":
+			event, id, data = "", "", ""
+		}
+	}
+}
+
+// initializeSession posts an initialize request and returns the session ID the server assigned
+func initializeSession(t *testing.T, baseURL string) string {
+	response, err := http.Post(baseURL+"/mcp", "application/json",
+		strings.NewReader(`{"jsonrpc":"2.0","method":"initialize","params":{"protocolVersion":"2025-03-26"},"id":1}`))
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	var result JSONRPCResponse
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&result))
+	assert.Contains(t, string(result.Result), `"protocolVersion":"2025-03-26"`)
+
+	sessionID := response.Header.Get(sessionHeader)
+	require.NotEmpty(t, sessionID)
+	return sessionID
+}
+
+// sessionRequest builds a request of a Streamable HTTP session
+func sessionRequest(t *testing.T, ctx context.Context, method string, url string, sessionID string, body string) *http.Request {
+	request, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json, text/event-stream")
+	request.Header.Set(sessionHeader, sessionID)
+	return request
+}
+
+func TestStreamableHTTP_Session(t *testing.T) {
+	server := setupTestHTTPServer(t)
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	sessionID := initializeSession(t, httpServer.URL)
+	do := func(method string, sessionID string, body string) *http.Response {
+		response, err := http.DefaultClient.Do(sessionRequest(t, context.Background(), method, httpServer.URL+"/mcp", sessionID, body))
+		require.NoError(t, err)
+		t.Cleanup(func() { response.Body.Close() })
+		return response
+	}
+
+	// Notifications are accepted without a body
+	response := do(http.MethodPost, sessionID, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+
+	// Requests other than tools/call are answered with JSON
+	response = do(http.MethodPost, sessionID, `{"jsonrpc":"2.0","method":"tools/list","id":2}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", response.Header.Get("Content-Type"))
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"name":"power_state"`)
+
+	// Unknown sessions are not found, and GET and DELETE need a session
+	response = do(http.MethodPost, "unknown", `{"jsonrpc":"2.0","method":"tools/list","id":3}`)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	response = do(http.MethodGet, "", "")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	response = do(http.MethodDelete, "", "")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	// After a DELETE, the session is gone
+	response = do(http.MethodDelete, sessionID, "")
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	response = do(http.MethodPost, sessionID, `{"jsonrpc":"2.0","method":"tools/list","id":4}`)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestStreamableHTTP_ToolsCallStream(t *testing.T) {
+	server := setupTestHTTPServer(t)
+	release := make(chan struct{})
+	registerTestTool(t, server.registry, "deploy", func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+		if reporter, ok := progress.ReporterFromContext(ctx); ok {
+			reporter.ReportProgress(50, "Halfway", nil)
+		}
+		<-release
+		return json.RawMessage(`{"status":"Deployed"}`), nil
+	})
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	sessionID := initializeSession(t, httpServer.URL)
+
+	ctx, disconnect := context.WithCancel(context.Background())
+	defer disconnect()
+	response, err := http.DefaultClient.Do(sessionRequest(t, ctx, http.MethodPost, httpServer.URL+"/mcp", sessionID,
+		`{"jsonrpc":"2.0","method":"tools/call","params":{"name":"deploy","arguments":{},"_meta":{"progressToken":"op-1"}},"id":7}`))
+	require.NoError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	// The progress of the call comes first on the stream
+	progressID, data := readSSEMessage(t, bufio.NewReader(response.Body))
+	require.NotEmpty(t, progressID)
+	var notification map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(data), &notification))
+	assert.Equal(t, "notifications/progress", notification["method"])
+	assert.Equal(t, "op-1", notification["params"].(map[string]interface{})["progressToken"])
+
+	// The client loses the stream while the tool runs; the call carries on
+	disconnect()
+	session, ok := server.getHTTPSession(sessionID)
+	require.True(t, ok)
+	assert.Eventually(t, func() bool { return session.idle(0) }, 5*time.Second, 10*time.Millisecond)
+	close(release)
+
+	// Resuming from the last event received delivers the response
+	request := sessionRequest(t, context.Background(), http.MethodGet, httpServer.URL+"/mcp", sessionID, "")
+	request.Header.Set(lastEventIDHeader, progressID)
+	resumed, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer resumed.Body.Close()
+	require.Equal(t, http.StatusOK, resumed.StatusCode)
+
+	responseID, data := readSSEMessage(t, bufio.NewReader(resumed.Body))
+	assert.NotEqual(t, progressID, responseID)
+	var result JSONRPCResponse
+	require.NoError(t, json.Unmarshal([]byte(data), &result))
+	assert.Equal(t, JSONRPCID("7"), result.ID)
+	assert.Contains(t, string(result.Result), `"structuredContent":{"status":"Deployed"}`)
+
+	// Once the response is delivered, the stream cannot be resumed again
+	assert.Eventually(t, func() bool {
+		request := sessionRequest(t, context.Background(), http.MethodGet, httpServer.URL+"/mcp", sessionID, "")
+		request.Header.Set(lastEventIDHeader, progressID)
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		response.Body.Close()
+		return response.StatusCode == http.StatusNotFound
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestStreamableHTTP_ResourceUpdates(t *testing.T) {
+	server := setupTestHTTPServer(t)
+	subscriber := newFakeResourceSubscriber()
+	server.registry.SetResourceSubscriber(subscriber)
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	sessionID := initializeSession(t, httpServer.URL)
+
+	// Updates arrive on the stream the session opened with a GET
+	stream, err := http.DefaultClient.Do(sessionRequest(t, context.Background(), http.MethodGet, httpServer.URL+"/mcp", sessionID, ""))
+	require.NoError(t, err)
+	defer stream.Body.Close()
+	require.Equal(t, http.StatusOK, stream.StatusCode)
+
+	response, err := http.DefaultClient.Do(sessionRequest(t, context.Background(), http.MethodPost, httpServer.URL+"/mcp", sessionID,
+		`{"jsonrpc":"2.0","method":"resources/subscribe","params":{"uri":"maas://machine/abc123"},"id":2}`))
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	require.True(t, subscriber.update(sessionID, "maas://machine/abc123"))
+	_, data := readSSEMessage(t, bufio.NewReader(stream.Body))
+	assert.Contains(t, data, `"method":"notifications/resources/updated"`)
+
+	// Ending the session removes its subscriptions
+	response, err = http.DefaultClient.Do(sessionRequest(t, context.Background(), http.MethodDelete, httpServer.URL+"/mcp", sessionID, ""))
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, []string{sessionID}, subscriber.unsubscribedAllSessions())
+}
+
+func TestStreamableHTTP_SessionSweep(t *testing.T) {
+	server := setupTestHTTPServer(t)
+	server.sessionSweepInterval = 10 * time.Millisecond
+	server.finishedStreamRetention = 0
+
+	// A finished stream that nobody resumes is forgotten, while its session is still in use
+	session := server.openHTTPSession("")
+	stream := session.newStream()
+	server.publish(session, stream, json.RawMessage(`{"jsonrpc":"2.0","result":{},"id":1}`), true)
+	assert.Eventually(t, func() bool {
+		session.mu.Lock()
+		defer session.mu.Unlock()
+		_, ok := session.streams[stream.id]
+		return !ok
+	}, 5*time.Second, 10*time.Millisecond)
+	_, ok := server.getHTTPSession(session.id)
+	assert.True(t, ok)
+
+	// An idle session is ended without a new session being opened, and the sweep then stops
+	server.httpSessionsMu.Lock()
+	server.sessionIdleTimeout = 0
+	server.httpSessionsMu.Unlock()
+	assert.Eventually(t, func() bool {
+		server.httpSessionsMu.Lock()
+		defer server.httpSessionsMu.Unlock()
+		return len(server.httpSessions) == 0 && !server.sweeping
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Error(t, session.ctx.Err())
+}
+
+func TestInitialize_ProtocolVersion(t *testing.T) {
+	server := setupTestHTTPServer(t)
+
+	tests := []struct {
+		requested string
+		want      string
+	}{
+		{requested: "2025-03-26", want: "2025-03-26"},
+		{requested: "2024-11-05", want: "2024-11-05"},
+		{requested: "2099-01-01", want: "2025-03-26"},
+		{requested: "", want: "2024-11-05"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.requested, func(t *testing.T) {
+			params, err := json.Marshal(map[string]string{"protocolVersion": tt.requested})
+			require.NoError(t, err)
+			recorder := postJSONRPC(server, `{"jsonrpc":"2.0","method":"initialize","params":`+string(params)+`,"id":1}`)
+			require.Equal(t, http.StatusOK, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), `"protocolVersion":"`+tt.want+`"`)
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -25,12 +26,37 @@ func getCwd() string {
 	return cwd
 }
 
+const (
+	// defaultStdioWorkers is the number of requests processed at once
+	defaultStdioWorkers = 4
+
+	// defaultStdioMaxOutstanding is the number of requests that may be queued or running at once
+	defaultStdioMaxOutstanding = 32
+
+	// errorCodeTooManyRequests is returned when a request would exceed the outstanding request ceiling
+	errorCodeTooManyRequests = -32001
+
+	// stdioUser is the user tool filters see for stdio clients, which are not authenticated
+	stdioUser = ""
+)
+
 // StdioServer is an MCP server implementation that communicates via stdin/stdout
 type StdioServer struct {
 	registry *Registry
 	logger   *logrus.Logger
 	reader   *bufio.Reader
 	writer   io.Writer
+	writeMu  sync.Mutex // Requests are processed concurrently, so writes are serialized
+
+	workers        int
+	maxOutstanding int
+
+	slotsOnce        sync.Once
+	workerSlots      chan struct{}
+	outstandingSlots chan struct{}
+
+	inflightOnce sync.Once
+	inflight     *inflightRequests
 }
 
 // NewStdioServer creates a new stdio MCP server
@@ -43,6 +69,88 @@ func NewStdioServer(registry *Registry, logger *logrus.Logger) *StdioServer {
 	}
 }
 
+// SetConcurrency sets how many requests are processed at once and how many may be outstanding,
+// queued or running, before new requests are rejected. Zero values keep the defaults.
+func (s *StdioServer) SetConcurrency(workers, maxOutstanding int) {
+	s.workers = workers
+	s.maxOutstanding = maxOutstanding
+}
+
+// concurrencyLimits returns the worker and outstanding request limits, applying the defaults
+func (s *StdioServer) concurrencyLimits() (int, int) {
+	workers := s.workers
+	if workers <= 0 {
+		workers = defaultStdioWorkers
+	}
+	maxOutstanding := s.maxOutstanding
+	if maxOutstanding <= 0 {
+		maxOutstanding = defaultStdioMaxOutstanding
+	}
+	if maxOutstanding < workers {
+		maxOutstanding = workers
+	}
+	return workers, maxOutstanding
+}
+
+// initSlots creates the worker and outstanding request slots on first use
+func (s *StdioServer) initSlots() {
+	s.slotsOnce.Do(func() {
+		workers, maxOutstanding := s.concurrencyLimits()
+		s.workerSlots = make(chan struct{}, workers)
+		s.outstandingSlots = make(chan struct{}, maxOutstanding)
+	})
+}
+
+// acquireOutstanding takes an outstanding request slot. When the session already has too many
+// requests outstanding the request is answered with an error on out instead, and false is returned.
+func (s *StdioServer) acquireOutstanding(out responseWriter, method string, id JSONRPCID) bool {
+	s.initSlots()
+	select {
+	case s.outstandingSlots <- struct{}{}:
+		return true
+	default:
+	}
+
+	maxOutstanding := cap(s.outstandingSlots)
+	s.logger.WithFields(logrus.Fields{
+		"method":          method,
+		"id":              id.String(),
+		"max_outstanding": maxOutstanding,
+	}).Warn("Rejecting request, too many outstanding requests")
+	s.writeError(out, id, errorCodeTooManyRequests, "Too many outstanding requests",
+		fmt.Sprintf("at most %d requests may be outstanding; retry once a response has arrived", maxOutstanding))
+	return false
+}
+
+// releaseOutstanding gives back a slot taken by acquireOutstanding
+func (s *StdioServer) releaseOutstanding() {
+	<-s.outstandingSlots
+}
+
+// acquireWorker waits for a free worker, returning false if ctx is done first
+func (s *StdioServer) acquireWorker(ctx context.Context) bool {
+	s.initSlots()
+	select {
+	case s.workerSlots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// releaseWorker gives back a slot taken by acquireWorker
+func (s *StdioServer) releaseWorker() {
+	<-s.workerSlots
+}
+
+// requests returns the table of in-flight requests, creating it on first use
+func (s *StdioServer) requests() *inflightRequests {
+	s.inflightOnce.Do(func() {
+		s.inflight = newInflightRequests(s.logger)
+	})
+	return s.inflight
+}
+
 // Run starts the stdio MCP server
 func (s *StdioServer) Run() error {
 	s.logger.Info("Starting stdio MCP server")
@@ -56,10 +164,17 @@ func (s *StdioServer) Run() error {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
+	// Requests still being processed are waited for after the context is canceled
+	var requests sync.WaitGroup
+	defer requests.Wait()
+
 	// Create a context that can be canceled
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Resource subscriptions end with the session
+	defer s.unsubscribeAll()
+
 	// Start a goroutine to handle signals
 	go func() {
 		sig := <-sigChan
@@ -103,6 +218,7 @@ func (s *StdioServer) Run() error {
 
 	// Start a goroutine to read from stdin
 	go func() {
+		stdinClosed := false
 		for {
 			// Check if context is canceled
 			select {
@@ -116,6 +232,11 @@ func (s *StdioServer) Run() error {
 			line, err := s.reader.ReadString('\n')
 			if err != nil {
 				if err == io.EOF {
+					if !stdinClosed {
+						// The client is gone, so nobody is left to notify of resource updates
+						stdinClosed = true
+						s.unsubscribeAll()
+					}
 					s.logger.Info("Stdin closed, but continuing to run")
 					// Don't exit, just wait for a signal
 					time.Sleep(100 * time.Millisecond)
@@ -137,7 +258,14 @@ func (s *StdioServer) Run() error {
 		}
 	}()
 
-	// Process JSON-RPC messages from stdin
+	// Process JSON-RPC messages from stdin. Each request runs in its own goroutine once a worker
+	// is free; responses carry the request ID, so they may be written in any order.
+	workers, maxOutstanding := s.concurrencyLimits()
+	s.logger.WithFields(logrus.Fields{
+		"workers":         workers,
+		"max_outstanding": maxOutstanding,
+	}).Info("Processing requests concurrently")
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -150,10 +278,49 @@ func (s *StdioServer) Run() error {
 			// Log the received line
 			s.logger.WithField("input", line).Info("Received input from stdin")
 
-			// Process the line
-			if err := s.processLine(ctx, line); err != nil {
-				s.logger.WithError(err).Error("Error processing line")
+			// Every request of a batch takes its own slots, so the batch itself holds none
+			if isBatch([]byte(line)) {
+				requests.Add(1)
+				go func(line string) {
+					defer requests.Done()
+					if err := s.processLine(ctx, line); err != nil {
+						s.logger.WithError(err).Error("Error processing batch")
+					}
+				}(line)
+				continue
+			}
+
+			method, id := peekRequest(line)
+
+			// Cancellations are handled straight away; waiting for a worker could mean waiting
+			// for the very request they cancel
+			if method == "notifications/cancelled" {
+				if err := s.processLine(ctx, line); err != nil {
+					s.logger.WithError(err).Error("Error processing cancellation")
+				}
+				continue
+			}
+
+			// Reject the request rather than queue it when the session has too many outstanding
+			if !s.acquireOutstanding(s, method, id) {
+				continue
 			}
+
+			requests.Add(1)
+			go func(line string) {
+				defer requests.Done()
+				defer s.releaseOutstanding()
+
+				if !s.acquireWorker(ctx) {
+					return
+				}
+				defer s.releaseWorker()
+
+				// Process the line
+				if err := s.processLine(ctx, line); err != nil {
+					s.logger.WithError(err).Error("Error processing line")
+				}
+			}(line)
 		}
 	}
 }
@@ -165,6 +332,11 @@ func (s *StdioServer) processLine(ctx context.Context, line string) error {
 	// Parse JSON-RPC request with detailed logging
 	s.logger.WithField("line", line).Debug("Attempting to parse JSON-RPC request")
 
+	// A JSON array is a batch of requests
+	if isBatch([]byte(line)) {
+		return s.processBatch(ctx, line)
+	}
+
 	var request JSONRPCRequest
 	if err := json.Unmarshal([]byte(line), &request); err != nil {
 		s.logger.WithError(err).Error("Failed to parse JSON-RPC request")
@@ -186,11 +358,16 @@ func (s *StdioServer) processLine(ctx context.Context, line string) error {
 			request.ID = simpleRequest.ID
 			request.JSONRPC = "2.0" // Assume 2.0 for compatibility
 		} else {
-			s.writeError(JSONRPCID(""), -32700, "Parse error", err.Error())
+			s.writeError(s, JSONRPCID(""), -32700, "Parse error", err.Error())
 			return err
 		}
 	}
 
+	return s.processRequest(ctx, request, s)
+}
+
+// processRequest handles a parsed request and passes its response to out
+func (s *StdioServer) processRequest(ctx context.Context, request JSONRPCRequest, out responseWriter) error {
 	// Log the parsed request
 	s.logger.WithFields(logrus.Fields{
 		"jsonrpc": request.JSONRPC,
@@ -210,68 +387,132 @@ func (s *StdioServer) processLine(ctx context.Context, line string) error {
 		s.logger.Warn("Request missing JSONRPC version field, assuming valid request")
 	}
 
+	// Handle cancellation of an in-flight request; notifications get no response
+	if request.Method == "notifications/cancelled" {
+		s.logger.Info("Handling notifications/cancelled")
+		s.requests().handleCancelled(request.Params)
+		return nil
+	}
+
 	// Handle discovery request
 	if request.Method == "discover" {
 		s.logger.Info("Handling discovery request")
-		s.handleDiscovery(request.ID)
+		s.handleDiscovery(out, request.ID)
 		return nil
 	}
 
 	// Handle initialize request (required by MCP protocol)
 	if request.Method == "initialize" {
 		s.logger.Info("Handling initialize request")
-		s.handleInitialize(request.ID, request.Params)
+		s.handleInitialize(out, request.ID, request.Params)
 		return nil
 	}
 
 	// Handle tools/list request
 	if request.Method == "tools/list" {
 		s.logger.Info("Handling tools/list request")
-		s.handleToolsList(request.ID)
+		s.handleToolsList(out, request.ID)
 		return nil
 	}
 
 	// Handle resources/list request
 	if request.Method == "resources/list" {
 		s.logger.Info("Handling resources/list request")
-		s.handleResourcesList(request.ID)
+		s.handleResourcesList(out, request.ID)
 		return nil
 	}
 
+	// Handle resources/templates/list request
+	if request.Method == "resources/templates/list" {
+		s.logger.Info("Handling resources/templates/list request")
+		s.handleResourceTemplatesList(out, request.ID)
+		return nil
+	}
+
+	// Handle resources/read request
+	if request.Method == "resources/read" {
+		s.logger.Info("Handling resources/read request")
+		return s.handleResourcesRead(ctx, out, request.ID, request.Params)
+	}
+
+	// Handle resources/subscribe request
+	if request.Method == "resources/subscribe" {
+		s.logger.Info("Handling resources/subscribe request")
+		return s.handleResourcesSubscribe(out, request.ID, request.Params)
+	}
+
+	// Handle resources/unsubscribe request
+	if request.Method == "resources/unsubscribe" {
+		s.logger.Info("Handling resources/unsubscribe request")
+		return s.handleResourcesUnsubscribe(out, request.ID, request.Params)
+	}
+
+	// Handle prompts/list request
+	if request.Method == "prompts/list" {
+		s.logger.Info("Handling prompts/list request")
+		s.handlePromptsList(out, request.ID)
+		return nil
+	}
+
+	// Handle prompts/get request
+	if request.Method == "prompts/get" {
+		s.logger.Info("Handling prompts/get request")
+		return s.handlePromptsGet(ctx, out, request.ID, request.Params)
+	}
+
 	// Handle tools/call request (MCP protocol standard)
 	if request.Method == "tools/call" {
 		s.logger.Info("Handling tools/call request")
-		return s.handleToolsCall(ctx, request.ID, request.Params)
+		return s.handleToolsCall(ctx, out, request.ID, request.Params)
 	}
 
 	// Get tool
 	s.logger.WithField("method", request.Method).Info("Looking up tool")
-	tool, ok := s.registry.GetTool(request.Method)
+	tool, ok := s.registry.GetToolFor(stdioUser, request.Method)
 	if !ok {
 		s.logger.WithField("method", request.Method).Error("Method not found")
-		s.writeError(request.ID, -32601, "Method not found", fmt.Sprintf("method %s not found", request.Method))
+		s.writeError(out, request.ID, -32601, "Method not found", fmt.Sprintf("method %s not found", request.Method))
 		return fmt.Errorf("method not found: %s", request.Method)
 	}
 
-	// Execute tool
+	// Execute tool; the client may cancel it with notifications/cancelled while it runs
 	s.logger.WithField("method", request.Method).Info("Executing tool")
-	result, err := tool.Handler(ctx, request.Params)
+	ctx, finish := s.requests().start(ctx, request.ID)
+	result, err := tool.Call(ctx, request.Params)
+	if finish() {
+		// A cancelled request gets no response
+		s.logger.WithField("method", request.Method).WithField("id", request.ID.String()).Info("Tool call cancelled by client")
+		return nil
+	}
 	if err != nil {
 		s.logger.WithError(err).WithField("method", request.Method).Error("Tool execution failed")
-		s.writeError(request.ID, -32000, "Server error", err.Error())
+		code, message, data := toolError(err)
+		s.writeError(out, request.ID, code, message, data)
 		return err
 	}
 
 	// Write result
 	s.logger.WithField("method", request.Method).Info("Tool execution succeeded")
-	s.writeResult(request.ID, result)
+	s.writeResult(out, request.ID, result)
 	return nil
 }
 
+// peekRequest returns the method and ID of a JSON-RPC message without fully parsing it
+func peekRequest(line string) (string, JSONRPCID) {
+	var message struct {
+		Method string    `json:"method"`
+		ID     JSONRPCID `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(line), &message); err != nil {
+		return "", JSONRPCID("")
+	}
+	return message.Method, message.ID
+}
+
 // handleDiscovery handles the MCP discovery request
-func (s *StdioServer) handleDiscovery(id JSONRPCID) {
+func (s *StdioServer) handleDiscovery(out responseWriter, id JSONRPCID) {
 	// Get all tools and resources
-	tools := s.registry.ListTools()
+	tools := s.registry.ListToolsFor(stdioUser)
 	resources := s.registry.ListResources()
 
 	// Build response
@@ -291,11 +532,11 @@ func (s *StdioServer) handleDiscovery(id JSONRPCID) {
 	}
 
 	// Write response
-	s.writeResponse(response)
+	out.writeResponse(response)
 }
 
 // handleInitialize handles the MCP initialize request
-func (s *StdioServer) handleInitialize(id JSONRPCID, params json.RawMessage) {
+func (s *StdioServer) handleInitialize(out responseWriter, id JSONRPCID, params json.RawMessage) {
 	s.logger.WithField("params", string(params)).Debug("Initialize params")
 
 	// Parse initialize params if provided
@@ -323,38 +564,21 @@ func (s *StdioServer) handleInitialize(id JSONRPCID, params json.RawMessage) {
 	}
 
 	// Get all tools and resources
-	tools := s.registry.ListTools()
+	tools := s.registry.ListToolsFor(stdioUser)
 	resources := s.registry.ListResources()
 
-	// Use protocol version from client or default to "2024-11-05"
-	protocolVersion := initializeParams.ProtocolVersion
-	if protocolVersion == "" {
-		protocolVersion = "2024-11-05"
-	}
+	// Resource subscriptions are only offered when something can watch the resources
+	_, hasSubscriber := s.registry.GetResourceSubscriber()
 
 	// Build response
 	response := map[string]interface{}{
 		"jsonrpc": "2.0",
-		"result": map[string]interface{}{
-			"protocolVersion": protocolVersion,
-			"serverInfo": map[string]interface{}{
-				"name":    "MAAS MCP Server",
-				"version": version.GetVersion(),
-			},
-			"capabilities": map[string]interface{}{
-				"tools": map[string]interface{}{
-					"listChanged": false,
-				},
-				"resources": map[string]interface{}{
-					"listChanged": false,
-				},
-			},
-		},
-		"id": id.String(),
+		"result":  initializeResult(initializeParams.ProtocolVersion, hasSubscriber),
+		"id":      id.String(),
 	}
 
-	// Write response
-	s.writeResponse(response)
+	// Write response; the notifications that follow go straight to stdout
+	out.writeResponse(response)
 
 	// Send initialized notification
 	s.logger.Info("Sending initialized notification")
@@ -388,10 +612,53 @@ func (s *StdioServer) handleInitialize(id JSONRPCID, params json.RawMessage) {
 	s.writeResponse(resourcesListResponse)
 }
 
+// supportedProtocolVersions are the MCP protocol versions the server speaks, latest first
+var supportedProtocolVersions = []string{"2025-03-26", "2024-11-05"}
+
+// negotiateProtocolVersion returns the protocol version requested by the client when the server
+// supports it, "2024-11-05" when it sent none and the latest supported version otherwise
+func negotiateProtocolVersion(requested string) string {
+	if requested == "" {
+		return "2024-11-05"
+	}
+	for _, supported := range supportedProtocolVersions {
+		if requested == supported {
+			return requested
+		}
+	}
+	return supportedProtocolVersions[0]
+}
+
+// initializeResult builds the result of an initialize request, with the protocol version
+// negotiated for the one requested by the client
+func initializeResult(protocolVersion string, hasSubscriber bool) map[string]interface{} {
+	return map[string]interface{}{
+		"protocolVersion": negotiateProtocolVersion(protocolVersion),
+		"serverInfo": map[string]interface{}{
+			"name":    "MAAS MCP Server",
+			"version": version.GetVersion(),
+		},
+		"capabilities": map[string]interface{}{
+			"tools": map[string]interface{}{
+				"listChanged": false,
+			},
+			"resources": map[string]interface{}{
+				"subscribe":   hasSubscriber,
+				"listChanged": false,
+			},
+			"prompts": map[string]interface{}{
+				"listChanged": false,
+			},
+			// Progress of long tool calls is also sent as log messages
+			"logging": map[string]interface{}{},
+		},
+	}
+}
+
 // handleToolsList handles the tools/list request
-func (s *StdioServer) handleToolsList(id JSONRPCID) {
+func (s *StdioServer) handleToolsList(out responseWriter, id JSONRPCID) {
 	// Get all tools
-	tools := s.registry.ListTools()
+	tools := s.registry.ListToolsFor(stdioUser)
 
 	// Build response
 	response := map[string]interface{}{
@@ -403,11 +670,11 @@ func (s *StdioServer) handleToolsList(id JSONRPCID) {
 	}
 
 	// Write response
-	s.writeResponse(response)
+	out.writeResponse(response)
 }
 
 // handleResourcesList handles the resources/list request
-func (s *StdioServer) handleResourcesList(id JSONRPCID) {
+func (s *StdioServer) handleResourcesList(out responseWriter, id JSONRPCID) {
 	// Get all resources
 	resources := s.registry.ListResources()
 
@@ -421,22 +688,28 @@ func (s *StdioServer) handleResourcesList(id JSONRPCID) {
 	}
 
 	// Write response
-	s.writeResponse(response)
+	out.writeResponse(response)
 }
 
-// writeResult writes a JSON-RPC result to stdout
-func (s *StdioServer) writeResult(id JSONRPCID, result json.RawMessage) {
+// responseWriter receives the response to a request. The server writes it to stdout; a batch
+// collects the responses to its requests and writes them together.
+type responseWriter interface {
+	writeResponse(response map[string]interface{})
+}
+
+// writeResult writes a JSON-RPC result to out
+func (s *StdioServer) writeResult(out responseWriter, id JSONRPCID, result json.RawMessage) {
 	response := map[string]interface{}{
 		"jsonrpc": "2.0",
 		"result":  result,
 		"id":      id.String(),
 	}
 	s.logger.WithField("response_type", "result").WithField("id", id.String()).Debug("Preparing result response")
-	s.writeResponse(response)
+	out.writeResponse(response)
 }
 
-// writeError writes a JSON-RPC error to stdout
-func (s *StdioServer) writeError(id JSONRPCID, code int, message string, data interface{}) {
+// writeError writes a JSON-RPC error to out
+func (s *StdioServer) writeError(out responseWriter, id JSONRPCID, code int, message string, data interface{}) {
 	response := map[string]interface{}{
 		"jsonrpc": "2.0",
 		"error": map[string]interface{}{
@@ -451,11 +724,31 @@ func (s *StdioServer) writeError(id JSONRPCID, code int, message string, data in
 	errorLogger = errorLogger.WithField("code", code)
 	errorLogger = errorLogger.WithField("message", message)
 	errorLogger.Debug("Preparing error response")
-	s.writeResponse(response)
+	out.writeResponse(response)
+}
+
+// writeNotification writes a JSON-RPC notification to stdout
+func (s *StdioServer) writeNotification(method string, params interface{}) {
+	notification := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	}
+	s.logger.WithField("method", method).Debug("Preparing notification")
+	s.writeResponse(notification)
 }
 
 // writeResponse writes a JSON-RPC response to stdout
 func (s *StdioServer) writeResponse(response map[string]interface{}) {
+	s.writeMessage(response)
+}
+
+// writeMessage writes a JSON-RPC message, or a batch of them, to stdout as a single line
+func (s *StdioServer) writeMessage(response interface{}) {
+	// Serialize writes so notifications and responses never interleave
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
 	// Marshal response to JSON
 	responseJSON, err := json.Marshal(response)
 	if err != nil {
@@ -1,16 +1,23 @@
 package mcp
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/lspecian/maas-mcp-server/internal/jsonschema"
+	"github.com/lspecian/maas-mcp-server/internal/service/progress"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // setupTestServer creates a StdioServer with a real registry for testing
@@ -356,3 +363,795 @@ func TestParameterValidation(t *testing.T) {
 	assert.Equal(t, "Server error", errorObj["message"])
 	assert.Contains(t, errorObj["data"], "invalid name")
 }
+
+// TestSchemaValidation tests that input that does not match a tool's schema is rejected with an
+// invalid params error listing the violations, without running the tool
+func TestSchemaValidation(t *testing.T) {
+	server, registry, outputBuffer := setupTestServer(t)
+
+	called := false
+	err := registry.RegisterTool(ToolInfo{
+		Name:        "deploy",
+		Description: "Deploy a machine",
+		InputSchema: json.RawMessage(`{"type":"object","required":["id"],"properties":{"id":{"type":"string"},"count":{"type":"integer","minimum":1}}}`),
+		Handler: func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+			called = true
+			return json.RawMessage(`{}`), nil
+		},
+	})
+	require.NoError(t, err)
+
+	for _, line := range []string{
+		`{"jsonrpc":"2.0","method":"tools/call","params":{"name":"deploy","arguments":{"count":0}},"id":1}`,
+		`{"jsonrpc":"2.0","method":"deploy","params":{"count":0},"id":1}`,
+	} {
+		outputBuffer.Reset()
+		err = server.processLine(context.Background(), line)
+		assert.Error(t, err)
+		assert.False(t, called, "the handler must not run for invalid input")
+
+		var response struct {
+			Error struct {
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+				Data    struct {
+					Violations []jsonschema.Violation `json:"violations"`
+				} `json:"data"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(outputBuffer.Bytes(), &response))
+		assert.Equal(t, -32602, response.Error.Code)
+		assert.Equal(t, "Invalid params", response.Error.Message)
+		assert.Equal(t, []jsonschema.Violation{
+			{Pointer: "/id", Message: "is required"},
+			{Pointer: "/count", Message: "must be at least 1"},
+		}, response.Error.Data.Violations)
+	}
+
+	outputBuffer.Reset()
+	err = server.processLine(context.Background(), `{"jsonrpc":"2.0","method":"tools/call","params":{"name":"deploy","arguments":{"id":"abc123"}},"id":2}`)
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+// TestToolsCall_StructuredResult tests that tools/call returns the output as structured content
+// with a text summary, and reports failures as tool errors
+func TestToolsCall_StructuredResult(t *testing.T) {
+	server, registry, outputBuffer := setupTestServer(t)
+
+	output := `{"machines":[{"status":"Ready"},{"status":"Ready"},{"status":"Deployed"}]}`
+	err := registry.RegisterTool(ToolInfo{
+		Name:         "list",
+		Description:  "List machines",
+		InputSchema:  json.RawMessage(`{"type":"object"}`),
+		OutputSchema: json.RawMessage(`{"type":"object","required":["machines"],"properties":{"machines":{"type":"array"}}}`),
+		Summarize: func(output json.RawMessage) string {
+			return "3 machines, 2 Ready, 1 Deployed"
+		},
+		Handler: func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+			if strings.Contains(string(input), "broken") {
+				return json.RawMessage(`{"items":[]}`), nil
+			}
+			if strings.Contains(string(input), "fail") {
+				return nil, fmt.Errorf("MAAS is unreachable")
+			}
+			return json.RawMessage(output), nil
+		},
+	})
+	require.NoError(t, err)
+
+	call := func(arguments string) ToolCallResult {
+		outputBuffer.Reset()
+		_ = server.processLine(context.Background(), `{"jsonrpc":"2.0","method":"tools/call","params":{"name":"list","arguments":`+arguments+`},"id":1}`)
+		var response struct {
+			Result ToolCallResult `json:"result"`
+			Error  interface{}    `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(outputBuffer.Bytes(), &response))
+		require.Nil(t, response.Error)
+		return response.Result
+	}
+
+	result := call(`{}`)
+	assert.False(t, result.IsError)
+	assert.JSONEq(t, output, string(result.StructuredContent))
+	assert.Equal(t, []ToolContent{
+		{Type: "text", Text: "3 machines, 2 Ready, 1 Deployed"},
+		{Type: "text", Text: output},
+	}, result.Content)
+
+	// A failing tool is a tool error, not a protocol error
+	result = call(`{"fail":true}`)
+	assert.True(t, result.IsError)
+	assert.Nil(t, result.StructuredContent)
+	assert.Equal(t, []ToolContent{{Type: "text", Text: "MAAS is unreachable"}}, result.Content)
+
+	// So is output that does not match the output schema
+	result = call(`{"broken":true}`)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "/machines: is required")
+
+	// tools/list advertises the output schema
+	outputBuffer.Reset()
+	require.NoError(t, server.processLine(context.Background(), `{"jsonrpc":"2.0","method":"tools/list","id":2}`))
+	assert.Contains(t, outputBuffer.String(), `"outputSchema":{"type":"object","required":["machines"]`)
+}
+
+func TestRegisterTool_InvalidSchema(t *testing.T) {
+	registry := NewRegistry()
+	err := registry.RegisterTool(ToolInfo{
+		Name:        "broken",
+		InputSchema: json.RawMessage(`{"type":"map"}`),
+		Handler: func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+			return nil, nil
+		},
+	})
+	assert.ErrorContains(t, err, "invalid input schema for tool broken")
+	_, ok := registry.GetTool("broken")
+	assert.False(t, ok)
+
+	err = registry.RegisterTool(ToolInfo{
+		Name:         "broken",
+		OutputSchema: json.RawMessage(`{"required":"id"}`),
+		Handler: func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+			return nil, nil
+		},
+	})
+	assert.ErrorContains(t, err, "invalid output schema for tool broken")
+}
+
+// decodeOutputLines parses each line the server wrote as a JSON object
+func decodeOutputLines(t *testing.T, output string) []map[string]interface{} {
+	var messages []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var message map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &message))
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// TestToolsCall_ProgressNotifications tests that a tools/call with a progress token streams
+// notifications/progress before the final response
+func TestToolsCall_ProgressNotifications(t *testing.T) {
+	server, registry, outputBuffer := setupTestServer(t)
+
+	// Register a test tool that reports progress through the context's reporter
+	registerTestTool(t, registry, "slow_tool", func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+		reporter, ok := progress.ReporterFromContext(ctx)
+		if !ok {
+			return json.RawMessage(`{"result":"no progress"}`), nil
+		}
+		reporter.ReportProgress(10, "Machine abc123 is Allocated", nil)
+		reporter.ReportProgress(50, "Machine abc123 is Deploying", nil)
+		reporter.ReportProgress(50, "Still deploying", nil)
+		reporter.ReportCompletion(nil, "Machine abc123 is Deployed")
+		return json.RawMessage(`{"result":"deployed"}`), nil
+	})
+
+	request := `{"jsonrpc":"2.0","method":"tools/call","params":{"name":"slow_tool","arguments":{},"_meta":{"progressToken":42}},"id":"1"}`
+	assert.NoError(t, server.processLine(context.Background(), request))
+
+	messages := decodeOutputLines(t, outputBuffer.String())
+	assert.Len(t, messages, 5)
+
+	// Progress only ever increases; the repeated value is sent as a log message
+	var progressValues []float64
+	for _, message := range messages[:4] {
+		params := message["params"].(map[string]interface{})
+		switch message["method"] {
+		case "notifications/progress":
+			assert.Equal(t, float64(42), params["progressToken"])
+			assert.Equal(t, float64(100), params["total"])
+			progressValues = append(progressValues, params["progress"].(float64))
+		case "notifications/message":
+			assert.Equal(t, "info", params["level"])
+		default:
+			t.Fatalf("unexpected message before the response: %v", message)
+		}
+	}
+	assert.Equal(t, []float64{10, 50, 100}, progressValues)
+
+	// The response arrives last
+	assert.Equal(t, "1", messages[4]["id"])
+	assert.Contains(t, messages[4]["result"].(map[string]interface{})["content"].([]interface{})[0].(map[string]interface{})["text"], "deployed")
+}
+
+// TestToolsCall_NoProgressToken tests that tools get no reporter when the client did not ask for progress
+func TestToolsCall_NoProgressToken(t *testing.T) {
+	server, registry, outputBuffer := setupTestServer(t)
+
+	registerTestTool(t, registry, "slow_tool", func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+		_, ok := progress.ReporterFromContext(ctx)
+		assert.False(t, ok)
+		return json.RawMessage(`{"result":"done"}`), nil
+	})
+
+	request := `{"jsonrpc":"2.0","method":"tools/call","params":{"name":"slow_tool","arguments":{}},"id":"1"}`
+	assert.NoError(t, server.processLine(context.Background(), request))
+
+	messages := decodeOutputLines(t, outputBuffer.String())
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "1", messages[0]["id"])
+}
+
+// TestToolsCall_Cancelled tests that notifications/cancelled cancels the context of an in-flight
+// tool call and that the cancelled call gets no response
+func TestToolsCall_Cancelled(t *testing.T) {
+	server, registry, outputBuffer := setupTestServer(t)
+
+	started := make(chan struct{})
+	causes := make(chan error, 1)
+	registerTestTool(t, registry, "slow_tool", func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+		close(started)
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+		return nil, ctx.Err()
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		request := `{"jsonrpc":"2.0","method":"tools/call","params":{"name":"slow_tool","arguments":{}},"id":7}`
+		done <- server.processLine(context.Background(), request)
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tool was not called")
+	}
+
+	cancellation := `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"user aborted"}}`
+	assert.NoError(t, server.processLine(context.Background(), cancellation))
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelled tool call did not return")
+	}
+	assert.ErrorIs(t, <-causes, progress.ErrOperationCancelled)
+
+	// Neither the call nor the notification is answered
+	assert.Empty(t, outputBuffer.String())
+
+	// The ID can be reused once the call has finished
+	registerTestTool(t, registry, "fast_tool", func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(`{"result":"done"}`), nil
+	})
+	request := `{"jsonrpc":"2.0","method":"tools/call","params":{"name":"fast_tool","arguments":{}},"id":7}`
+	require.NoError(t, server.processLine(context.Background(), request))
+	messages := decodeOutputLines(t, outputBuffer.String())
+	require.Len(t, messages, 1)
+	assert.Equal(t, "7", messages[0]["id"])
+}
+
+// TestToolsCall_CancelUnknownRequest tests that cancelling a request that is not in flight is ignored
+func TestToolsCall_CancelUnknownRequest(t *testing.T) {
+	server, _, outputBuffer := setupTestServer(t)
+
+	cancellation := `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"missing"}}`
+	assert.NoError(t, server.processLine(context.Background(), cancellation))
+	assert.Empty(t, outputBuffer.String())
+}
+
+// runTestServer runs the server's Run loop on pipes. It returns a function that sends a line to the
+// server and a channel of the JSON-RPC responses it writes; the server stops when the test ends.
+func runTestServer(t *testing.T, server *StdioServer) (func(string), <-chan map[string]interface{}) {
+	inputReader, inputWriter := io.Pipe()
+	outputReader, outputWriter := io.Pipe()
+	server.reader = bufio.NewReader(inputReader)
+	server.writer = outputWriter
+
+	responses := make(chan map[string]interface{}, 16)
+	go func() {
+		scanner := bufio.NewScanner(outputReader)
+		for scanner.Scan() {
+			var message map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+				continue // The plain text ready message
+			}
+			if message["method"] == "ready" {
+				continue
+			}
+			responses <- message
+		}
+	}()
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- server.Run()
+	}()
+	t.Cleanup(func() {
+		inputWriter.CloseWithError(errors.New("test finished"))
+		<-runErr
+		outputWriter.Close()
+	})
+
+	send := func(line string) {
+		_, err := fmt.Fprintln(inputWriter, line)
+		require.NoError(t, err)
+	}
+	return send, responses
+}
+
+// nextResponse waits for the next response written by the server
+func nextResponse(t *testing.T, responses <-chan map[string]interface{}) map[string]interface{} {
+	select {
+	case response := <-responses:
+		return response
+	case <-time.After(5 * time.Second):
+		t.Fatal("no response from the server")
+		return nil
+	}
+}
+
+// TestToolsList_Annotations tests that tools/list returns the annotations of the tools that have them
+func TestToolsList_Annotations(t *testing.T) {
+	server, registry, outputBuffer := setupTestServer(t)
+	handler := func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(`{}`), nil
+	}
+	registerTestTool(t, registry, "plain_tool", handler)
+	err := registry.RegisterTool(ToolInfo{
+		Name:        "release_tool",
+		Description: "Test tool",
+		InputSchema: json.RawMessage(`{"type":"object"}`),
+		Annotations: &ToolAnnotations{DestructiveHint: true},
+		Handler:     handler,
+	})
+	assert.NoError(t, err)
+
+	err = server.processLine(context.Background(), `{"jsonrpc":"2.0","method":"tools/list","id":1}`)
+	assert.NoError(t, err)
+
+	var response struct {
+		Result struct {
+			Tools []map[string]json.RawMessage `json:"tools"`
+		} `json:"result"`
+	}
+	assert.NoError(t, json.Unmarshal(outputBuffer.Bytes(), &response))
+
+	annotations := make(map[string]string)
+	for _, tool := range response.Result.Tools {
+		var name string
+		assert.NoError(t, json.Unmarshal(tool["name"], &name))
+		annotations[name] = string(tool["annotations"])
+	}
+	assert.Equal(t, map[string]string{
+		"plain_tool":   "",
+		"release_tool": `{"readOnlyHint":false,"destructiveHint":true,"idempotentHint":false,"openWorldHint":false}`,
+	}, annotations)
+}
+
+// TestToolsList_Filter tests that stdio clients only see and call the tools the filter offers them
+func TestToolsList_Filter(t *testing.T) {
+	server, registry, outputBuffer := setupTestServer(t)
+	handler := func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(`{}`), nil
+	}
+	registerTestTool(t, registry, "list_tool", handler)
+	registerTestTool(t, registry, "delete_tool", handler)
+	registry.SetToolFilter(func(user string, tool ToolInfo) bool {
+		return user == "" && tool.Name == "list_tool"
+	})
+
+	err := server.processLine(context.Background(), `{"jsonrpc":"2.0","method":"tools/list","id":1}`)
+	assert.NoError(t, err)
+	var response struct {
+		Result struct {
+			Tools []ToolInfo `json:"tools"`
+		} `json:"result"`
+	}
+	assert.NoError(t, json.Unmarshal(outputBuffer.Bytes(), &response))
+	require.Len(t, response.Result.Tools, 1)
+	assert.Equal(t, "list_tool", response.Result.Tools[0].Name)
+
+	outputBuffer.Reset()
+	err = server.processLine(context.Background(), `{"jsonrpc":"2.0","method":"tools/call","params":{"name":"delete_tool","arguments":{}},"id":2}`)
+	assert.Error(t, err)
+	assert.Contains(t, outputBuffer.String(), "tool delete_tool not found")
+}
+
+// TestRun_ConcurrentRequests tests that a slow tool call does not hold up other requests
+func TestRun_ConcurrentRequests(t *testing.T) {
+	server, registry, _ := setupTestServer(t)
+	server.SetConcurrency(2, 0)
+
+	release := make(chan struct{})
+	registerTestTool(t, registry, "slow_tool", func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+		<-release
+		return json.RawMessage(`{"result":"slow"}`), nil
+	})
+	send, responses := runTestServer(t, server)
+
+	send(`{"jsonrpc":"2.0","method":"tools/call","params":{"name":"slow_tool","arguments":{}},"id":1}`)
+	send(`{"jsonrpc":"2.0","method":"tools/list","id":2}`)
+
+	// The listing is answered while the tool call is still running
+	response := nextResponse(t, responses)
+	assert.Equal(t, "2", response["id"])
+
+	close(release)
+	response = nextResponse(t, responses)
+	assert.Equal(t, "1", response["id"])
+	assert.Contains(t, response, "result")
+}
+
+// TestRun_MaxOutstanding tests that requests beyond the outstanding ceiling are rejected, not queued
+func TestRun_MaxOutstanding(t *testing.T) {
+	server, registry, _ := setupTestServer(t)
+	server.SetConcurrency(1, 1)
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	registerTestTool(t, registry, "slow_tool", func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	})
+	send, responses := runTestServer(t, server)
+
+	send(`{"jsonrpc":"2.0","method":"tools/call","params":{"name":"slow_tool","arguments":{}},"id":1}`)
+	<-started
+	send(`{"jsonrpc":"2.0","method":"tools/list","id":2}`)
+
+	response := nextResponse(t, responses)
+	assert.Equal(t, "2", response["id"])
+	assert.Equal(t, float64(errorCodeTooManyRequests), response["error"].(map[string]interface{})["code"])
+
+	// Cancellations still get through while the session is at its ceiling
+	send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}`)
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow tool call was not cancelled")
+	}
+
+	// Once the cancelled call has finished, new requests are accepted again
+	for id := 3; ; id++ {
+		send(fmt.Sprintf(`{"jsonrpc":"2.0","method":"tools/list","id":%d}`, id))
+		response = nextResponse(t, responses)
+		assert.Equal(t, fmt.Sprint(id), response["id"])
+		if _, ok := response["result"]; ok {
+			break
+		}
+		require.Less(t, id, 100, "requests are still rejected after the call finished")
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestProcessLine_Batch tests that a batch is answered with one array holding a response for every
+// request but the notifications
+func TestProcessLine_Batch(t *testing.T) {
+	server, registry, outputBuffer := setupTestServer(t)
+
+	registerTestTool(t, registry, "power_state", func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(`{"state":"on"}`), nil
+	})
+	registerTestTool(t, registry, "failing_tool", func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+		return nil, fmt.Errorf("tool failed")
+	})
+
+	batch := `[
+		{"jsonrpc":"2.0","method":"tools/call","params":{"name":"power_state","arguments":{}},"id":1},
+		{"jsonrpc":"2.0","method":"power_state","params":{},"id":"2"},
+		{"jsonrpc":"2.0","method":"failing_tool","params":{},"id":3},
+		{"jsonrpc":"2.0","method":"missing_tool","params":{},"id":4},
+		{"jsonrpc":"2.0","method":"power_state","params":{}},
+		1
+	]`
+	assert.NoError(t, server.processLine(context.Background(), strings.Join(strings.Fields(batch), "")))
+
+	var responses []map[string]interface{}
+	require.NoError(t, json.Unmarshal(outputBuffer.Bytes(), &responses))
+	require.Len(t, responses, 5)
+
+	byID := make(map[string]map[string]interface{})
+	for _, response := range responses {
+		byID[response["id"].(string)] = response
+	}
+	assert.Contains(t, byID["1"], "result")
+	assert.Contains(t, byID["2"], "result")
+	assert.Equal(t, float64(-32000), byID["3"]["error"].(map[string]interface{})["code"])
+	assert.Equal(t, float64(-32601), byID["4"]["error"].(map[string]interface{})["code"])
+	assert.Equal(t, float64(-32600), byID[""]["error"].(map[string]interface{})["code"])
+}
+
+// TestProcessLine_BatchEdgeCases tests empty batches and batches of notifications
+func TestProcessLine_BatchEdgeCases(t *testing.T) {
+	t.Run("empty batch", func(t *testing.T) {
+		server, _, outputBuffer := setupTestServer(t)
+
+		assert.Error(t, server.processLine(context.Background(), `[]`))
+
+		messages := decodeOutputLines(t, outputBuffer.String())
+		require.Len(t, messages, 1)
+		assert.Equal(t, float64(-32600), messages[0]["error"].(map[string]interface{})["code"])
+	})
+
+	t.Run("only notifications", func(t *testing.T) {
+		server, registry, outputBuffer := setupTestServer(t)
+		called := make(chan struct{}, 2)
+		registerTestTool(t, registry, "test_tool", func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+			called <- struct{}{}
+			return json.RawMessage(`{}`), nil
+		})
+
+		batch := `[{"jsonrpc":"2.0","method":"test_tool","params":{}},{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":9}}]`
+		assert.NoError(t, server.processLine(context.Background(), batch))
+		assert.Len(t, called, 1)
+		assert.Empty(t, outputBuffer.String())
+	})
+}
+
+// TestProcessLine_BatchTakesSlots tests that every request of a batch takes an outstanding and a
+// worker slot, as a request on its own line does
+func TestProcessLine_BatchTakesSlots(t *testing.T) {
+	server, registry, outputBuffer := setupTestServer(t)
+	server.SetConcurrency(1, 2)
+
+	called := make(chan struct{}, 2)
+	registerTestTool(t, registry, "test_tool", func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+		called <- struct{}{}
+		return json.RawMessage(`{}`), nil
+	})
+
+	// Another request holds one of the two outstanding slots and the only worker
+	require.True(t, server.acquireOutstanding(server, "tools/call", JSONRPCID("0")))
+	require.True(t, server.acquireWorker(context.Background()))
+
+	batch := `[{"jsonrpc":"2.0","method":"test_tool","params":{},"id":1},{"jsonrpc":"2.0","method":"test_tool","params":{},"id":2}]`
+	done := make(chan error, 1)
+	go func() {
+		done <- server.processLine(context.Background(), batch)
+	}()
+
+	// The first request waits for the worker
+	select {
+	case <-called:
+		t.Fatal("batch request ran without a worker slot")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	server.releaseWorker()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("batch did not finish")
+	}
+	server.releaseOutstanding()
+	assert.Len(t, called, 1)
+
+	// The second request is over the outstanding ceiling
+	var responses []map[string]interface{}
+	require.NoError(t, json.Unmarshal(outputBuffer.Bytes(), &responses))
+	require.Len(t, responses, 2)
+	byID := make(map[string]map[string]interface{})
+	for _, response := range responses {
+		byID[response["id"].(string)] = response
+	}
+	assert.Contains(t, byID["1"], "result")
+	assert.Equal(t, float64(errorCodeTooManyRequests), byID["2"]["error"].(map[string]interface{})["code"])
+	assert.Empty(t, server.outstandingSlots)
+	assert.Empty(t, server.workerSlots)
+}
+
+// fakeResourceReader serves a single machine resource
+type fakeResourceReader struct{}
+
+func (fakeResourceReader) ReadResource(ctx context.Context, uri string) ([]ResourceContents, error) {
+	switch uri {
+	case "maas://machine/abc123":
+		return []ResourceContents{{URI: uri, MimeType: "application/json", Text: `{"data":{"system_id":"abc123"}}`}}, nil
+	case "maas://machine":
+		return nil, fmt.Errorf("%w: missing system_id", ErrInvalidResourceURI)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+	}
+}
+
+func (fakeResourceReader) ListResourceTemplates() []ResourceTemplate {
+	return []ResourceTemplate{{URITemplate: "maas://machine/{system_id}", Name: "machine", MimeType: "application/json"}}
+}
+
+// TestResourcesRead tests resources/read and resources/templates/list against the registry's reader
+func TestResourcesRead(t *testing.T) {
+	server, registry, outputBuffer := setupTestServer(t)
+	registry.SetResourceReader(fakeResourceReader{})
+
+	requests := []string{
+		`{"jsonrpc":"2.0","method":"resources/templates/list","id":1}`,
+		`{"jsonrpc":"2.0","method":"resources/read","params":{"uri":"maas://machine/abc123"},"id":2}`,
+		`{"jsonrpc":"2.0","method":"resources/read","params":{"uri":"maas://machine/missing"},"id":3}`,
+		`{"jsonrpc":"2.0","method":"resources/read","params":{"uri":"maas://machine"},"id":4}`,
+		`{"jsonrpc":"2.0","method":"resources/read","params":{},"id":5}`,
+	}
+	for _, request := range requests {
+		server.processLine(context.Background(), request)
+	}
+
+	messages := decodeOutputLines(t, outputBuffer.String())
+	require.Len(t, messages, 5)
+
+	templates := messages[0]["result"].(map[string]interface{})["resourceTemplates"].([]interface{})
+	require.Len(t, templates, 1)
+	assert.Equal(t, "maas://machine/{system_id}", templates[0].(map[string]interface{})["uriTemplate"])
+
+	contents := messages[1]["result"].(map[string]interface{})["contents"].([]interface{})
+	require.Len(t, contents, 1)
+	assert.Equal(t, "maas://machine/abc123", contents[0].(map[string]interface{})["uri"])
+	assert.Contains(t, contents[0].(map[string]interface{})["text"], "abc123")
+
+	assert.Equal(t, float64(errorCodeResourceNotFound), messages[2]["error"].(map[string]interface{})["code"])
+	assert.Equal(t, float64(errorCodeInvalidParams), messages[3]["error"].(map[string]interface{})["code"])
+	assert.Equal(t, float64(errorCodeInvalidParams), messages[4]["error"].(map[string]interface{})["code"])
+}
+
+// TestResourcesRead_NoReader tests that templates are empty and reads fail when no reader is set
+func TestResourcesRead_NoReader(t *testing.T) {
+	server, _, outputBuffer := setupTestServer(t)
+
+	server.processLine(context.Background(), `{"jsonrpc":"2.0","method":"resources/templates/list","id":1}`)
+	server.processLine(context.Background(), `{"jsonrpc":"2.0","method":"resources/read","params":{"uri":"maas://machine/abc123"},"id":2}`)
+
+	messages := decodeOutputLines(t, outputBuffer.String())
+	require.Len(t, messages, 2)
+	assert.Empty(t, messages[0]["result"].(map[string]interface{})["resourceTemplates"])
+	assert.Equal(t, float64(errorCodeResourceNotFound), messages[1]["error"].(map[string]interface{})["code"])
+}
+
+// fakeResourceSubscriber records subscriptions to machine resources and sends updates on demand
+type fakeResourceSubscriber struct {
+	mu              sync.Mutex
+	notify          map[string]func(uri string)
+	intervals       map[string]time.Duration
+	unsubscribedAll []string
+}
+
+func newFakeResourceSubscriber() *fakeResourceSubscriber {
+	return &fakeResourceSubscriber{
+		notify:    make(map[string]func(uri string)),
+		intervals: make(map[string]time.Duration),
+	}
+}
+
+func (f *fakeResourceSubscriber) Subscribe(sessionID, uri string, interval time.Duration, notify func(uri string)) error {
+	if !strings.HasPrefix(uri, "maas://machine/") {
+		return fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.notify[sessionID+" "+uri] = notify
+	f.intervals[sessionID+" "+uri] = interval
+	return nil
+}
+
+func (f *fakeResourceSubscriber) Unsubscribe(sessionID, uri string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.notify, sessionID+" "+uri)
+}
+
+func (f *fakeResourceSubscriber) UnsubscribeAll(sessionID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for key := range f.notify {
+		if strings.HasPrefix(key, sessionID+" ") {
+			delete(f.notify, key)
+		}
+	}
+	f.unsubscribedAll = append(f.unsubscribedAll, sessionID)
+}
+
+// update notifies the session of a change to uri and reports whether it is subscribed
+func (f *fakeResourceSubscriber) update(sessionID, uri string) bool {
+	f.mu.Lock()
+	notify, ok := f.notify[sessionID+" "+uri]
+	f.mu.Unlock()
+	if ok {
+		notify(uri)
+	}
+	return ok
+}
+
+// unsubscribedAllSessions returns the sessions whose subscriptions were all removed
+func (f *fakeResourceSubscriber) unsubscribedAllSessions() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.unsubscribedAll...)
+}
+
+// TestResourcesSubscribe tests resources/subscribe, the updates that follow, and resources/unsubscribe
+func TestResourcesSubscribe(t *testing.T) {
+	server, registry, outputBuffer := setupTestServer(t)
+	subscriber := newFakeResourceSubscriber()
+	registry.SetResourceSubscriber(subscriber)
+
+	server.processLine(context.Background(), `{"jsonrpc":"2.0","method":"resources/subscribe","params":{"uri":"maas://machine/abc123","intervalSeconds":10},"id":1}`)
+	assert.Equal(t, 10*time.Second, subscriber.intervals["stdio maas://machine/abc123"])
+	require.True(t, subscriber.update(stdioSessionID, "maas://machine/abc123"))
+
+	server.processLine(context.Background(), `{"jsonrpc":"2.0","method":"resources/unsubscribe","params":{"uri":"maas://machine/abc123"},"id":2}`)
+	assert.False(t, subscriber.update(stdioSessionID, "maas://machine/abc123"))
+
+	server.processLine(context.Background(), `{"jsonrpc":"2.0","method":"resources/subscribe","params":{"uri":"maas://tag/virtual/machines"},"id":3}`)
+	server.processLine(context.Background(), `{"jsonrpc":"2.0","method":"resources/subscribe","params":{"uri":"maas://machine/abc123","intervalSeconds":-1},"id":4}`)
+
+	messages := decodeOutputLines(t, outputBuffer.String())
+	require.Len(t, messages, 5)
+
+	assert.Equal(t, "1", messages[0]["id"])
+	assert.Equal(t, map[string]interface{}{}, messages[0]["result"])
+
+	assert.Equal(t, "notifications/resources/updated", messages[1]["method"])
+	assert.Equal(t, "maas://machine/abc123", messages[1]["params"].(map[string]interface{})["uri"])
+
+	assert.Equal(t, "2", messages[2]["id"])
+	assert.Equal(t, float64(errorCodeResourceNotFound), messages[3]["error"].(map[string]interface{})["code"])
+	assert.Equal(t, float64(errorCodeInvalidParams), messages[4]["error"].(map[string]interface{})["code"])
+}
+
+// TestResourcesSubscribe_Capability tests that initialize only advertises subscriptions when they are served
+func TestResourcesSubscribe_Capability(t *testing.T) {
+	server, registry, outputBuffer := setupTestServer(t)
+
+	subscribeCapability := func() interface{} {
+		outputBuffer.Reset()
+		server.processLine(context.Background(), `{"jsonrpc":"2.0","method":"initialize","params":{},"id":1}`)
+		result := decodeOutputLines(t, outputBuffer.String())[0]["result"].(map[string]interface{})
+		return result["capabilities"].(map[string]interface{})["resources"].(map[string]interface{})["subscribe"]
+	}
+
+	assert.Equal(t, false, subscribeCapability())
+
+	registry.SetResourceSubscriber(newFakeResourceSubscriber())
+	assert.Equal(t, true, subscribeCapability())
+}
+
+// TestPrompts tests prompts/list and prompts/get against the registry's prompts
+func TestPrompts(t *testing.T) {
+	server, registry, outputBuffer := setupTestServer(t)
+	require.NoError(t, registry.RegisterPrompt(PromptInfo{
+		Name:        "greet",
+		Description: "Greet a machine",
+		Arguments:   []PromptArgument{{Name: "system_id", Required: true}},
+		Handler: func(ctx context.Context, arguments map[string]string) (*PromptResult, error) {
+			if arguments["system_id"] == "bad" {
+				return nil, fmt.Errorf("%w: unknown machine", ErrInvalidPromptArguments)
+			}
+			return &PromptResult{Messages: []PromptMessage{
+				{Role: "user", Content: PromptContent{Type: "text", Text: "Hello " + arguments["system_id"]}},
+			}}, nil
+		},
+	}))
+
+	requests := []string{
+		`{"jsonrpc":"2.0","method":"prompts/list","id":1}`,
+		`{"jsonrpc":"2.0","method":"prompts/get","params":{"name":"greet","arguments":{"system_id":"abc123"}},"id":2}`,
+		`{"jsonrpc":"2.0","method":"prompts/get","params":{"name":"greet"},"id":3}`,
+		`{"jsonrpc":"2.0","method":"prompts/get","params":{"name":"missing"},"id":4}`,
+		`{"jsonrpc":"2.0","method":"prompts/get","params":{"name":"greet","arguments":{"system_id":"bad"}},"id":5}`,
+	}
+	for _, request := range requests {
+		server.processLine(context.Background(), request)
+	}
+
+	messages := decodeOutputLines(t, outputBuffer.String())
+	require.Len(t, messages, 5)
+
+	prompts := messages[0]["result"].(map[string]interface{})["prompts"].([]interface{})
+	require.Len(t, prompts, 1)
+	assert.Equal(t, "greet", prompts[0].(map[string]interface{})["name"])
+
+	promptMessages := messages[1]["result"].(map[string]interface{})["messages"].([]interface{})
+	require.Len(t, promptMessages, 1)
+	content := promptMessages[0].(map[string]interface{})["content"].(map[string]interface{})
+	assert.Equal(t, "Hello abc123", content["text"])
+
+	for _, message := range messages[2:] {
+		assert.Equal(t, float64(errorCodeInvalidParams), message["error"].(map[string]interface{})["code"])
+	}
+}
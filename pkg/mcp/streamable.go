@@ -0,0 +1,550 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lspecian/maas-mcp-server/internal/service/progress"
+	"github.com/lspecian/maas-mcp-server/internal/transport/mcp/events"
+	"github.com/sirupsen/logrus"
+)
+
+// The Streamable HTTP transport serves every message on /mcp. A client starts a session with an
+// initialize POST, whose response carries the session ID in the Mcp-Session-Id header, and sends that
+// header with every later request. A tools/call posted with "Accept: text/event-stream" is answered
+// with an SSE stream carrying the call's notifications and then its response; a GET opens a stream for
+// notifications not tied to a request, such as resource updates; a DELETE ends the session. Every event
+// has an ID, and a client that loses a stream resumes it with a GET carrying the Last-Event-ID header.
+const (
+	// sessionHeader carries the session ID of the Streamable HTTP transport
+	sessionHeader = "Mcp-Session-Id"
+
+	// lastEventIDHeader is sent by a client resuming an SSE stream
+	lastEventIDHeader = "Last-Event-ID"
+
+	// serverEventMethod is the notification carrying server-side events on the stream a session
+	// opened with a GET
+	serverEventMethod = "notifications/maas/event"
+
+	// sessionIdleTimeout is how long a session without an open stream lives after its last request
+	sessionIdleTimeout = 30 * time.Minute
+
+	// sessionSweepInterval is how often idle sessions and finished streams are looked for
+	sessionSweepInterval = time.Minute
+
+	// finishedStreamRetention is how long a stream whose final event was not delivered is kept for
+	// the client to resume it
+	finishedStreamRetention = 5 * time.Minute
+
+	// streamReplayBuffer is the number of events of a stream kept for clients that resume it
+	streamReplayBuffer = 256
+
+	// keepAliveInterval is how often an idle SSE stream gets a comment, so proxies keep it open
+	keepAliveInterval = 30 * time.Second
+)
+
+// httpSession is a session of the Streamable HTTP transport
+type httpSession struct {
+	id   string
+	user string
+
+	// ctx is the context of the requests answered on the session's streams. They outlive the HTTP
+	// request, so the client can resume the stream, and stop when the session ends.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// mu guards the fields below and the streams they hold
+	mu       sync.Mutex
+	streams  map[string]*sseStream
+	lastUsed time.Time
+	// standalone is the stream opened with a GET, if any
+	standalone *sseStream
+}
+
+// sseStream is an SSE stream of a session. Its events are kept in the replay buffer under its ID, so
+// the stream outlives the connection it was opened on.
+type sseStream struct {
+	id string
+
+	// finalID is the ID of the stream's last event, set once it is published, at finishedAt
+	finalID    string
+	finishedAt time.Time
+
+	// listener receives the events of the connection serving the stream, nil while there is none
+	listener chan SSEEvent
+
+	// filter selects the server-side events sent on the stream a session opened with a GET
+	filter EventFilter
+}
+
+// serverEventParams are the params of a serverEventMethod notification: the name of the event, as
+// sent on /mcp/sse, and its data
+type serverEventParams struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// notifierContextKey is the context key of the function sending notifications for a request
+type notifierContextKey struct{}
+
+// withNotifier returns a context carrying the function sending notifications to the client of a request
+func withNotifier(ctx context.Context, notify notifyFunc) context.Context {
+	return context.WithValue(ctx, notifierContextKey{}, notify)
+}
+
+// notifierFromContext returns the function sending notifications for a request, or nil when the
+// request is answered with plain JSON
+func notifierFromContext(ctx context.Context) notifyFunc {
+	notify, _ := ctx.Value(notifierContextKey{}).(notifyFunc)
+	return notify
+}
+
+// acceptsEventStream reports whether the client of a request accepts an SSE response
+func acceptsEventStream(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+}
+
+// writeSSEEvent writes an event to an SSE stream and flushes it
+func writeSSEEvent(w gin.ResponseWriter, event SSEEvent) {
+	if event.Event != "" {
+		fmt.Fprintf(w, "event: %s\n", event.Event)
+	}
+	if event.ID != "" {
+		fmt.Fprintf(w, "id: %s\n", event.ID)
+	}
+	fmt.Fprintf(w, "data: %s\n\n", event.Data)
+	w.Flush()
+}
+
+// notificationMessage encodes a JSON-RPC notification
+func notificationMessage(method string, params interface{}) (json.RawMessage, error) {
+	return json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	})
+}
+
+// openHTTPSession starts a session for user, and the sweep of idle sessions if it is not running
+func (s *Server) openHTTPSession(user string) *httpSession {
+	ctx, cancel := context.WithCancel(context.Background())
+	session := &httpSession{
+		id:       uuid.New().String(),
+		user:     user,
+		ctx:      ctx,
+		cancel:   cancel,
+		streams:  make(map[string]*sseStream),
+		lastUsed: time.Now(),
+	}
+
+	s.httpSessionsMu.Lock()
+	s.httpSessions[session.id] = session
+	if !s.sweeping {
+		s.sweeping = true
+		go s.sweepHTTPSessions()
+	}
+	s.httpSessionsMu.Unlock()
+
+	s.logger.WithField("session_id", session.id).Info("HTTP session opened")
+	return session
+}
+
+// sweepHTTPSessions periodically ends the sessions that have been idle for too long and forgets the
+// finished streams that no client resumed in time. It stops once there are no sessions left.
+func (s *Server) sweepHTTPSessions() {
+	ticker := time.NewTicker(s.sessionSweepInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.httpSessionsMu.Lock()
+		if len(s.httpSessions) == 0 {
+			s.sweeping = false
+			s.httpSessionsMu.Unlock()
+			return
+		}
+		sessions := make([]*httpSession, 0, len(s.httpSessions))
+		for _, session := range s.httpSessions {
+			sessions = append(sessions, session)
+		}
+		idleTimeout, retention := s.sessionIdleTimeout, s.finishedStreamRetention
+		s.httpSessionsMu.Unlock()
+
+		for _, session := range sessions {
+			if session.idle(idleTimeout) {
+				s.logger.WithField("session_id", session.id).Info("Ending idle HTTP session")
+				s.endHTTPSession(session)
+				continue
+			}
+			for _, streamID := range session.removeFinishedStreams(retention) {
+				s.replay.CleanupOperation(streamID)
+			}
+		}
+	}
+}
+
+// idle reports whether a session has had no request and no open stream for longer than timeout
+func (session *httpSession) idle(timeout time.Duration) bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	for _, stream := range session.streams {
+		if stream.listener != nil {
+			return false
+		}
+	}
+	return time.Since(session.lastUsed) > timeout
+}
+
+// removeFinishedStreams forgets the streams that ended more than retention ago while no connection
+// was serving them, and returns their IDs
+func (session *httpSession) removeFinishedStreams(retention time.Duration) []string {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	var removed []string
+	for id, stream := range session.streams {
+		if stream.finalID != "" && stream.listener == nil && time.Since(stream.finishedAt) > retention {
+			delete(session.streams, id)
+			removed = append(removed, id)
+		}
+	}
+	return removed
+}
+
+// getHTTPSession returns a session by ID
+func (s *Server) getHTTPSession(sessionID string) (*httpSession, bool) {
+	s.httpSessionsMu.Lock()
+	defer s.httpSessionsMu.Unlock()
+
+	session, ok := s.httpSessions[sessionID]
+	return session, ok
+}
+
+// endHTTPSession ends a session: its requests are cancelled, its streams closed and forgotten, and
+// its resource subscriptions removed
+func (s *Server) endHTTPSession(session *httpSession) {
+	s.httpSessionsMu.Lock()
+	delete(s.httpSessions, session.id)
+	s.httpSessionsMu.Unlock()
+
+	session.cancel()
+	if subscriber, ok := s.registry.GetResourceSubscriber(); ok {
+		subscriber.UnsubscribeAll(session.id)
+	}
+
+	session.mu.Lock()
+	for _, stream := range session.streams {
+		if stream.listener != nil {
+			close(stream.listener)
+			stream.listener = nil
+		}
+		s.replay.CleanupOperation(stream.id)
+	}
+	session.streams = make(map[string]*sseStream)
+	session.standalone = nil
+	session.mu.Unlock()
+
+	s.logger.WithField("session_id", session.id).Info("HTTP session closed")
+}
+
+// requestSession returns the session named by the Mcp-Session-Id header of a request, or nil when
+// the header is missing. A session that does not exist, has ended or belongs to another user is
+// answered with 404, which tells the client to initialize a new one, and ok is false.
+func (s *Server) requestSession(c *gin.Context) (*httpSession, bool) {
+	sessionID := c.GetHeader(sessionHeader)
+	if sessionID == "" {
+		return nil, true
+	}
+
+	session, ok := s.getHTTPSession(sessionID)
+	if !ok || session.user != c.GetString(authenticatedUserKey) {
+		c.JSON(http.StatusNotFound, errorResponse(JSONRPCID(""), -32001, "Session not found", map[string]string{"sessionId": sessionID}))
+		return nil, false
+	}
+
+	session.mu.Lock()
+	session.lastUsed = time.Now()
+	session.mu.Unlock()
+	return session, true
+}
+
+// newStream adds a stream to a session
+func (session *httpSession) newStream() *sseStream {
+	stream := &sseStream{id: uuid.New().String()}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	session.streams[stream.id] = stream
+	return stream
+}
+
+// publish sends a JSON-RPC message on a stream. The message is kept for replay, and handed to the
+// connection serving the stream if there is one. A connection that is not keeping up is closed; the
+// client resumes the stream from the last event it got. The final message ends the stream.
+func (s *Server) publish(session *httpSession, stream *sseStream, message json.RawMessage, final bool) {
+	event := events.NewMessageEvent(stream.id, message)
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	// The streams of an ended session are gone, along with their replay buffers
+	if session.ctx.Err() != nil {
+		return
+	}
+	s.replay.AddEvent(event)
+	if final {
+		stream.finalID = event.EventID
+		stream.finishedAt = time.Now()
+	}
+	if stream.listener == nil {
+		return
+	}
+
+	select {
+	case stream.listener <- SSEEvent{Event: string(events.EventTypeMessage), Data: string(message), ID: event.EventID}:
+	default:
+		s.logger.WithFields(logrus.Fields{
+			"session_id": session.id,
+			"stream_id":  stream.id,
+		}).Warn("Closing SSE stream, the client is not keeping up")
+		close(stream.listener)
+		stream.listener = nil
+		return
+	}
+	if final {
+		close(stream.listener)
+		stream.listener = nil
+	}
+}
+
+// attachStream makes the caller the connection serving a stream. It returns the events published
+// after lastEventID, for a client resuming the stream, and the channel of the events that follow,
+// which is nil when the stream has already ended. A connection still serving the stream is closed.
+func (s *Server) attachStream(session *httpSession, stream *sseStream, lastEventID string) ([]SSEEvent, chan SSEEvent) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	var replayed []SSEEvent
+	if lastEventID != "" {
+		for _, event := range s.replay.GetEventsAfterID(stream.id, lastEventID) {
+			if message, ok := event.(*events.MessageEvent); ok {
+				replayed = append(replayed, SSEEvent{Event: string(events.EventTypeMessage), Data: string(message.Message), ID: message.EventID})
+			}
+		}
+	}
+
+	if stream.listener != nil {
+		close(stream.listener)
+		stream.listener = nil
+	}
+	if stream.finalID != "" {
+		return replayed, nil
+	}
+	stream.listener = make(chan SSEEvent, defaultEventQueueSize)
+	return replayed, stream.listener
+}
+
+// detachStream is called when the connection serving a stream ends, lastEventID being the last
+// event it wrote. A stream whose final event was delivered is forgotten.
+func (s *Server) detachStream(session *httpSession, stream *sseStream, listener chan SSEEvent, lastEventID string) {
+	session.mu.Lock()
+	if listener != nil && stream.listener == listener {
+		stream.listener = nil
+	}
+	finished := stream.finalID != "" && stream.finalID == lastEventID
+	if finished {
+		delete(session.streams, stream.id)
+	}
+	session.mu.Unlock()
+
+	if finished {
+		s.replay.CleanupOperation(stream.id)
+	}
+}
+
+// serveStream answers a request with an SSE stream: the replayed events first, then those sent on
+// listener until it is closed or the client goes away
+func (s *Server) serveStream(c *gin.Context, session *httpSession, stream *sseStream, replayed []SSEEvent, listener chan SSEEvent) {
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set(sessionHeader, session.id)
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	lastEventID := ""
+	for _, event := range replayed {
+		writeSSEEvent(c.Writer, event)
+		lastEventID = event.ID
+	}
+
+	if listener != nil {
+		ticker := time.NewTicker(keepAliveInterval)
+		defer ticker.Stop()
+
+	serve:
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				break serve
+			case event, ok := <-listener:
+				if !ok {
+					break serve
+				}
+				writeSSEEvent(c.Writer, event)
+				lastEventID = event.ID
+			case <-ticker.C:
+				io.WriteString(c.Writer, ": keepalive\n\n")
+				c.Writer.Flush()
+			}
+		}
+	}
+
+	s.detachStream(session, stream, listener, lastEventID)
+}
+
+// streamToolsCall answers a tools/call request with an SSE stream. The call runs in the session's
+// context, so it goes on if the connection drops; its notifications and then its response are sent
+// on the stream, which the client can resume.
+func (s *Server) streamToolsCall(c *gin.Context, session *httpSession, request JSONRPCRequest) {
+	stream := session.newStream()
+	_, listener := s.attachStream(session, stream, "")
+
+	ctx := withUser(session.ctx, session.user)
+	ctx = withNotifier(ctx, func(method string, params interface{}) {
+		message, err := notificationMessage(method, params)
+		if err != nil {
+			s.logger.WithError(err).WithField("method", method).Error("Failed to marshal notification")
+			return
+		}
+		s.publish(session, stream, message, false)
+	})
+
+	go func() {
+		_, response := s.processRequest(ctx, session.id, request)
+		message, err := json.Marshal(response)
+		if err != nil {
+			message, _ = json.Marshal(errorResponse(request.ID, -32000, "Server error", "Failed to format result"))
+		}
+		s.publish(session, stream, message, true)
+	}()
+
+	s.serveStream(c, session, stream, nil, listener)
+}
+
+// handleGet handles GET /mcp. A client accepting an event stream opens the session's stream for
+// notifications, or resumes a stream it lost; other clients get the discovery document. The
+// operation_id, machine_id and event_type query parameters select the server-side events sent on
+// the session's stream, as they do on /mcp/sse.
+func (s *Server) handleGet(c *gin.Context) {
+	if !acceptsEventStream(c) {
+		s.handleDiscovery(c)
+		return
+	}
+
+	session, ok := s.requestSession(c)
+	if !ok {
+		return
+	}
+	if session == nil {
+		c.JSON(http.StatusBadRequest, errorResponse(JSONRPCID(""), -32600, "Invalid Request", sessionHeader+" header is required"))
+		return
+	}
+
+	lastEventID := c.GetHeader(lastEventIDHeader)
+	var stream *sseStream
+	if lastEventID != "" {
+		if streamID, err := progress.EventOperationID(lastEventID); err == nil {
+			session.mu.Lock()
+			stream = session.streams[streamID]
+			session.mu.Unlock()
+		}
+		if stream == nil {
+			c.JSON(http.StatusNotFound, errorResponse(JSONRPCID(""), -32001, "Stream not found", map[string]string{"lastEventId": lastEventID}))
+			return
+		}
+	} else {
+		session.mu.Lock()
+		if session.standalone == nil {
+			session.standalone = &sseStream{id: uuid.New().String()}
+			session.streams[session.standalone.id] = session.standalone
+		}
+		stream = session.standalone
+		stream.filter = parseEventFilter(c.Request.URL.Query())
+		session.mu.Unlock()
+	}
+
+	replayed, listener := s.attachStream(session, stream, lastEventID)
+	s.serveStream(c, session, stream, replayed, listener)
+}
+
+// handleDeleteSession handles DELETE /mcp, which ends the session named by the Mcp-Session-Id header
+func (s *Server) handleDeleteSession(c *gin.Context) {
+	session, ok := s.requestSession(c)
+	if !ok {
+		return
+	}
+	if session == nil {
+		c.JSON(http.StatusBadRequest, errorResponse(JSONRPCID(""), -32600, "Invalid Request", sessionHeader+" header is required"))
+		return
+	}
+
+	s.endHTTPSession(session)
+	c.Status(http.StatusNoContent)
+}
+
+// publishNotification sends a notification on the stream a session opened with a GET. It reports
+// false when sessionID is not a Streamable HTTP session.
+func (s *Server) publishNotification(sessionID string, message json.RawMessage) bool {
+	session, ok := s.getHTTPSession(sessionID)
+	if !ok {
+		return false
+	}
+
+	session.mu.Lock()
+	stream := session.standalone
+	session.mu.Unlock()
+	if stream != nil {
+		s.publish(session, stream, message, false)
+	}
+	return true
+}
+
+// publishEvent sends a server-side event, as a serverEventMethod notification, on the streams that
+// sessions opened with a GET and whose filter it matches. It returns the number of streams it was
+// sent on.
+func (s *Server) publishEvent(event SSEEvent) int {
+	message, err := notificationMessage(serverEventMethod, serverEventParams{Type: event.Event, Data: json.RawMessage(event.Data)})
+	if err != nil {
+		s.logger.WithError(err).WithField("event", event.Event).Error("Failed to marshal event notification")
+		return 0
+	}
+
+	s.httpSessionsMu.Lock()
+	sessions := make([]*httpSession, 0, len(s.httpSessions))
+	for _, session := range s.httpSessions {
+		sessions = append(sessions, session)
+	}
+	s.httpSessionsMu.Unlock()
+
+	sent := 0
+	for _, session := range sessions {
+		session.mu.Lock()
+		stream := session.standalone
+		matches := stream != nil && stream.filter.matches(event)
+		session.mu.Unlock()
+		if matches {
+			s.publish(session, stream, message, false)
+			sent++
+		}
+	}
+	return sent
+}
@@ -0,0 +1,177 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// stdioSessionID identifies the single client of the stdio server to the resource subscriber
+const stdioSessionID = "stdio"
+
+// ResourcesSubscribeParams represents the parameters for resources/subscribe and resources/unsubscribe.
+// IntervalSeconds is an extension that sets how often the resource is checked for changes.
+type ResourcesSubscribeParams struct {
+	URI             string `json:"uri"`
+	IntervalSeconds int    `json:"intervalSeconds,omitempty"`
+}
+
+// parseSubscribeParams parses and validates resources/subscribe or resources/unsubscribe params
+func parseSubscribeParams(params json.RawMessage) (ResourcesSubscribeParams, error) {
+	var subscribeParams ResourcesSubscribeParams
+	if err := json.Unmarshal(params, &subscribeParams); err != nil {
+		return subscribeParams, err
+	}
+	if subscribeParams.URI == "" {
+		return subscribeParams, fmt.Errorf("uri is required")
+	}
+	if subscribeParams.IntervalSeconds < 0 {
+		return subscribeParams, fmt.Errorf("intervalSeconds must not be negative")
+	}
+	return subscribeParams, nil
+}
+
+// interval returns the requested polling interval, zero for the subscriber's default
+func (p ResourcesSubscribeParams) interval() time.Duration {
+	return time.Duration(p.IntervalSeconds) * time.Second
+}
+
+// resourceUpdatedParams builds the params of a notifications/resources/updated message
+func resourceUpdatedParams(uri string) map[string]interface{} {
+	return map[string]interface{}{
+		"uri": uri,
+	}
+}
+
+// resourceErrorCode returns the JSON-RPC error code and message for an error from a ResourceReader
+// or ResourceSubscriber
+func resourceErrorCode(err error) (int, string) {
+	switch {
+	case errors.Is(err, ErrResourceNotFound):
+		return errorCodeResourceNotFound, "Resource not found"
+	case errors.Is(err, ErrInvalidResourceURI):
+		return errorCodeInvalidParams, "Invalid params"
+	default:
+		return -32000, "Server error"
+	}
+}
+
+// handleResourcesSubscribe handles the resources/subscribe request. Updates are sent to stdout as
+// notifications/resources/updated until the client unsubscribes or stdin is closed.
+func (s *StdioServer) handleResourcesSubscribe(out responseWriter, id JSONRPCID, params json.RawMessage) error {
+	subscribeParams, err := parseSubscribeParams(params)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to parse resources/subscribe params")
+		s.writeError(out, id, errorCodeInvalidParams, "Invalid params", err.Error())
+		return err
+	}
+
+	subscriber, ok := s.registry.GetResourceSubscriber()
+	if !ok {
+		s.writeError(out, id, -32601, "Method not found", "resource subscriptions are not supported")
+		return fmt.Errorf("no resource subscriber registered")
+	}
+
+	err = subscriber.Subscribe(stdioSessionID, subscribeParams.URI, subscribeParams.interval(), func(uri string) {
+		s.writeNotification("notifications/resources/updated", resourceUpdatedParams(uri))
+	})
+	if err != nil {
+		s.logger.WithError(err).WithField("uri", subscribeParams.URI).Error("Failed to subscribe to resource")
+		code, message := resourceErrorCode(err)
+		s.writeError(out, id, code, message, err.Error())
+		return err
+	}
+
+	s.writeResult(out, id, json.RawMessage(`{}`))
+	return nil
+}
+
+// handleResourcesUnsubscribe handles the resources/unsubscribe request
+func (s *StdioServer) handleResourcesUnsubscribe(out responseWriter, id JSONRPCID, params json.RawMessage) error {
+	subscribeParams, err := parseSubscribeParams(params)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to parse resources/unsubscribe params")
+		s.writeError(out, id, errorCodeInvalidParams, "Invalid params", err.Error())
+		return err
+	}
+
+	if subscriber, ok := s.registry.GetResourceSubscriber(); ok {
+		subscriber.Unsubscribe(stdioSessionID, subscribeParams.URI)
+	}
+
+	s.writeResult(out, id, json.RawMessage(`{}`))
+	return nil
+}
+
+// unsubscribeAll removes the subscriptions of the stdio client
+func (s *StdioServer) unsubscribeAll() {
+	if subscriber, ok := s.registry.GetResourceSubscriber(); ok {
+		subscriber.UnsubscribeAll(stdioSessionID)
+	}
+}
+
+// handleResourcesSubscribe handles resources/subscribe posted over HTTP. The request must carry the
+// session ID of an open SSE stream, which the updates are sent to as "message" events.
+func (s *Server) handleResourcesSubscribe(sessionID string, request JSONRPCRequest) (int, *JSONRPCResponse) {
+	subscribeParams, err := parseSubscribeParams(request.Params)
+	if err != nil {
+		return http.StatusBadRequest, errorResponse(request.ID, errorCodeInvalidParams, "Invalid params", err.Error())
+	}
+
+	subscriber, ok := s.registry.GetResourceSubscriber()
+	if !ok {
+		return http.StatusBadRequest, errorResponse(request.ID, -32601, "Method not found", "resource subscriptions are not supported")
+	}
+	if !s.hasSession(sessionID) {
+		return http.StatusBadRequest, errorResponse(request.ID, errorCodeInvalidParams, "Invalid params",
+			"resources/subscribe requires the session_id of an open SSE stream")
+	}
+
+	err = subscriber.Subscribe(sessionID, subscribeParams.URI, subscribeParams.interval(), func(uri string) {
+		s.sendNotification(sessionID, "notifications/resources/updated", resourceUpdatedParams(uri))
+	})
+	if err != nil {
+		s.logger.WithError(err).WithField("uri", subscribeParams.URI).Error("Failed to subscribe to resource")
+		code, message := resourceErrorCode(err)
+		return http.StatusBadRequest, errorResponse(request.ID, code, message, err.Error())
+	}
+
+	return http.StatusOK, &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result:  json.RawMessage(`{}`),
+		ID:      request.ID,
+	}
+}
+
+// handleResourcesUnsubscribe handles resources/unsubscribe posted over HTTP
+func (s *Server) handleResourcesUnsubscribe(sessionID string, request JSONRPCRequest) (int, *JSONRPCResponse) {
+	subscribeParams, err := parseSubscribeParams(request.Params)
+	if err != nil {
+		return http.StatusBadRequest, errorResponse(request.ID, errorCodeInvalidParams, "Invalid params", err.Error())
+	}
+
+	if subscriber, ok := s.registry.GetResourceSubscriber(); ok && sessionID != "" {
+		subscriber.Unsubscribe(sessionID, subscribeParams.URI)
+	}
+
+	return http.StatusOK, &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result:  json.RawMessage(`{}`),
+		ID:      request.ID,
+	}
+}
+
+// errorResponse builds a JSON-RPC error response
+func errorResponse(id JSONRPCID, code int, message string, data interface{}) *JSONRPCResponse {
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Error: &JSONRPCError{
+			Code:    code,
+			Message: message,
+			Data:    data,
+		},
+		ID: id,
+	}
+}
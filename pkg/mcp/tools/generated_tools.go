@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lspecian/maas-mcp-server/internal/models"
+)
+
+// DefaultGeneratedToolsPath is where cmd/gen-tools writes the generated tool definitions, relative
+// to the project root
+const DefaultGeneratedToolsPath = "cmd/gen-tools/generated_maas_tools.json"
+
+// APICaller calls a MAAS API endpoint. It is implemented by maasclient.MaasClient.
+type APICaller interface {
+	CallAPI(ctx context.Context, httpMethod string, apiPath string, requestBody json.RawMessage) (interface{}, error)
+}
+
+// GeneratedTools runs the MAAS API tools generated by cmd/gen-tools
+type GeneratedTools struct {
+	client APICaller
+}
+
+// NewGeneratedTools creates a new GeneratedTools instance
+func NewGeneratedTools(client APICaller) *GeneratedTools {
+	return &GeneratedTools{
+		client: client,
+	}
+}
+
+// LoadGeneratedTools reads the generated tool definitions from path
+func LoadGeneratedTools(path string) ([]models.MCPTool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tool definitions file '%s': %w", path, err)
+	}
+
+	var definitions struct {
+		Tools []models.MCPTool `json:"tools"`
+	}
+	if err := json.Unmarshal(data, &definitions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tool definitions from '%s': %w", path, err)
+	}
+	return definitions.Tools, nil
+}
+
+// Handler returns the handler that calls the MAAS API endpoint of a generated tool
+func (t *GeneratedTools) Handler(definition models.MCPTool) func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	return func(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+		result, err := t.call(ctx, definition, input)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(result)
+	}
+}
+
+// call forwards a generated tool call to the MAAS API
+func (t *GeneratedTools) call(ctx context.Context, definition models.MCPTool, input json.RawMessage) (interface{}, error) {
+	if definition.Endpoint == nil {
+		return t.callDerivedEndpoint(ctx, definition, input)
+	}
+
+	var args map[string]interface{}
+	if len(input) > 0 && string(input) != "null" {
+		if err := json.Unmarshal(input, &args); err != nil {
+			return nil, fmt.Errorf("invalid parameters for tool '%s': %w", definition.Name, err)
+		}
+	}
+
+	// Each argument goes where the endpoint takes it: the path, the query or the body
+	request, err := definition.Endpoint.Request(args)
+	if err != nil {
+		return nil, fmt.Errorf("tool '%s': %w", definition.Name, err)
+	}
+
+	body, err := json.Marshal(request.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode parameters for tool '%s': %w", definition.Name, err)
+	}
+
+	return t.client.CallAPI(ctx, request.Method, request.Path, body)
+}
+
+// callDerivedEndpoint handles tool definitions generated before endpoint metadata was recorded,
+// deriving the method and path from the tool name. Tool names look like
+// "maas_<method>_<path_segments_joined_by_underscores>", so this cannot recover paths whose
+// segments contain underscores.
+func (t *GeneratedTools) callDerivedEndpoint(ctx context.Context, definition models.MCPTool, input json.RawMessage) (interface{}, error) {
+	nameParts := strings.SplitN(definition.Name, "_", 3)
+	if len(nameParts) < 3 || nameParts[0] != "maas" {
+		return nil, fmt.Errorf("could not parse tool name '%s' into expected format [maas_method_path]", definition.Name)
+	}
+
+	method := strings.ToUpper(nameParts[1])
+	path := "/" + strings.ReplaceAll(nameParts[2], "_", "/")
+	return t.client.CallAPI(ctx, method, path, input)
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/maas-mcp-server/internal/models"
+)
+
+// recordingCaller records the MAAS API call it gets and returns result
+type recordingCaller struct {
+	method string
+	path   string
+	body   json.RawMessage
+	result interface{}
+}
+
+func (c *recordingCaller) CallAPI(ctx context.Context, httpMethod string, apiPath string, requestBody json.RawMessage) (interface{}, error) {
+	c.method, c.path, c.body = httpMethod, apiPath, requestBody
+	return c.result, nil
+}
+
+func TestGeneratedToolsHandler(t *testing.T) {
+	caller := &recordingCaller{result: map[string]interface{}{"status_name": "Releasing"}}
+	definition := models.MCPTool{
+		Name: "maas_post_machines_system_id_op_release",
+		Endpoint: &models.MCPToolEndpoint{
+			Method:       "POST",
+			PathTemplate: "/api/2.0/machines/{system_id}/op-release",
+			Parameters:   map[string]string{"system_id": "path", "force": "query", "comment": "body"},
+		},
+	}
+
+	output, err := NewGeneratedTools(caller).Handler(definition)(context.Background(),
+		json.RawMessage(`{"system_id":"abc123","force":true,"comment":"done"}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "POST", caller.method)
+	assert.Equal(t, "/api/2.0/machines/abc123/op-release?force=true", caller.path)
+	assert.JSONEq(t, `{"comment":"done"}`, string(caller.body))
+	assert.JSONEq(t, `{"status_name":"Releasing"}`, string(output))
+}
+
+func TestGeneratedToolsHandler_DerivedEndpoint(t *testing.T) {
+	caller := &recordingCaller{result: []interface{}{}}
+	definition := models.MCPTool{Name: "maas_get_machines"}
+
+	_, err := NewGeneratedTools(caller).Handler(definition)(context.Background(), json.RawMessage(`{"hostname":"node-1"}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "GET", caller.method)
+	assert.Equal(t, "/machines", caller.path)
+	assert.JSONEq(t, `{"hostname":"node-1"}`, string(caller.body))
+}
+
+func TestGeneratedToolsHandler_Errors(t *testing.T) {
+	caller := &recordingCaller{}
+	generated := NewGeneratedTools(caller)
+
+	_, err := generated.Handler(models.MCPTool{
+		Name:     "maas_get_machines_system_id",
+		Endpoint: &models.MCPToolEndpoint{Method: "GET", PathTemplate: "/api/2.0/machines/{system_id}/"},
+	})(context.Background(), json.RawMessage(`{}`))
+	assert.ErrorContains(t, err, "system_id")
+
+	_, err = generated.Handler(models.MCPTool{Name: "list_machines"})(context.Background(), nil)
+	assert.Error(t, err)
+
+	assert.Empty(t, caller.method, "no MAAS API call is made for an invalid tool call")
+}
+
+func TestLoadGeneratedTools(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"tools":[{"name":"maas_get_zones","endpoint":{"method":"GET","path_template":"/api/2.0/zones/"}}]}`), 0o644))
+
+	definitions, err := LoadGeneratedTools(path)
+	require.NoError(t, err)
+	require.Len(t, definitions, 1)
+	assert.Equal(t, "maas_get_zones", definitions[0].Name)
+	require.NotNil(t, definitions[0].Endpoint)
+	assert.Equal(t, "/api/2.0/zones/", definitions[0].Endpoint.PathTemplate)
+
+	_, err = LoadGeneratedTools(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
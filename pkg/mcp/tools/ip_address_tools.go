@@ -0,0 +1,229 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+	"github.com/lspecian/maas-mcp-server/internal/service/ipam"
+)
+
+// reservationSchema describes an audited reservation in the output of the IP address tools
+const reservationSchema = `{
+	"type": "object",
+	"required": ["ip", "subnet_id", "subnet_cidr", "reserved_at"],
+	"properties": {
+		"ip": {"type": "string"},
+		"subnet_id": {"type": "integer"},
+		"subnet_cidr": {"type": "string"},
+		"mac": {"type": "string"},
+		"hostname": {"type": "string"},
+		"comment": {"type": "string"},
+		"reserved_at": {"type": "string", "format": "date-time"},
+		"released_at": {"type": "string", "format": "date-time"}
+	}
+}`
+
+// ReserveIPOutputSchema is the output schema of the ReserveIP tool
+var ReserveIPOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["reservation"],
+	"properties": {
+		"reservation": ` + reservationSchema + `
+	}
+}`)
+
+// ReleaseIPOutputSchema is the output schema of the ReleaseIP tool
+var ReleaseIPOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["ip", "audited"],
+	"properties": {
+		"ip": {"type": "string", "description": "The released address"},
+		"audited": {"type": "boolean", "description": "Whether the address was reserved through this server"}
+	}
+}`)
+
+// ListIPReservationsOutputSchema is the output schema of the ListIPReservations tool
+var ListIPReservationsOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["reservations"],
+	"properties": {
+		"reservations": {"type": "array", "items": ` + reservationSchema + `}
+	}
+}`)
+
+// IPAddressTools provides MCP tools for static IP address reservations
+type IPAddressTools struct {
+	reservations *ipam.Reservations
+}
+
+// NewIPAddressTools creates a new IPAddressTools instance
+func NewIPAddressTools(reservations *ipam.Reservations) *IPAddressTools {
+	return &IPAddressTools{
+		reservations: reservations,
+	}
+}
+
+// ReserveIPInput represents the input for the ReserveIP tool
+type ReserveIPInput struct {
+	Subnet   string `json:"subnet,omitempty"`
+	IP       string `json:"ip,omitempty"`
+	MAC      string `json:"mac,omitempty"`
+	Hostname string `json:"hostname,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// ReserveIPOutput represents the output for the ReserveIP tool
+type ReserveIPOutput struct {
+	Reservation *ipam.Reservation `json:"reservation"`
+}
+
+// ReserveIP reserves a static address in a subnet, optionally bound to a MAC address and with a hostname
+func (t *IPAddressTools) ReserveIP(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params ReserveIPInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.Subnet == "" && params.IP == "" {
+		return nil, fmt.Errorf("subnet or ip is required")
+	}
+
+	reservation, err := t.reservations.Reserve(ctx, types.IPAddressReserveParams{
+		Subnet:   params.Subnet,
+		IP:       params.IP,
+		MAC:      params.MAC,
+		Hostname: params.Hostname,
+	}, params.Comment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve IP address: %w", err)
+	}
+
+	return marshalOutput(ReserveIPOutput{Reservation: reservation})
+}
+
+// SummarizeReservation summarizes the output of the ReserveIP tool, such as
+// "Reserved 10.0.0.12 in 10.0.0.0/24 for lb-vip (52:54:00:12:34:56)"
+func SummarizeReservation(result json.RawMessage) string {
+	var output ReserveIPOutput
+	if err := json.Unmarshal(result, &output); err != nil || output.Reservation == nil {
+		return ""
+	}
+	return "Reserved " + describeReservation(output.Reservation)
+}
+
+// ReleaseIPInput represents the input for the ReleaseIP tool
+type ReleaseIPInput struct {
+	IP    string `json:"ip"`
+	Force bool   `json:"force,omitempty"`
+}
+
+// ReleaseIP releases a reserved address
+func (t *IPAddressTools) ReleaseIP(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params ReleaseIPInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.IP == "" {
+		return nil, fmt.Errorf("ip is required")
+	}
+
+	release, err := t.reservations.Release(ctx, params.IP, params.Force)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release IP address: %w", err)
+	}
+
+	return marshalOutput(release)
+}
+
+// SummarizeRelease summarizes the output of the ReleaseIP tool, such as "Released 10.0.0.12"
+func SummarizeRelease(result json.RawMessage) string {
+	var output ipam.Release
+	if err := json.Unmarshal(result, &output); err != nil {
+		return ""
+	}
+	if !output.Audited {
+		return fmt.Sprintf("Released %s (not reserved through this server)", output.IP)
+	}
+	return "Released " + output.IP
+}
+
+// ListIPReservationsInput represents the input for the ListIPReservations tool
+type ListIPReservationsInput struct {
+	IncludeReleased bool `json:"include_released,omitempty"`
+}
+
+// ListIPReservationsOutput represents the output for the ListIPReservations tool
+type ListIPReservationsOutput struct {
+	Reservations []ipam.Reservation `json:"reservations"`
+}
+
+// ListIPReservations lists the reservations made through this server, oldest first
+func (t *IPAddressTools) ListIPReservations(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params ListIPReservationsInput
+	if len(input) > 0 {
+		if err := json.Unmarshal(input, &params); err != nil {
+			return nil, fmt.Errorf("failed to parse input: %w", err)
+		}
+	}
+
+	reservations, err := t.reservations.List(ctx, params.IncludeReleased)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list IP reservations: %w", err)
+	}
+
+	// An empty list stays a list
+	if reservations == nil {
+		reservations = []ipam.Reservation{}
+	}
+
+	return marshalOutput(ListIPReservationsOutput{Reservations: reservations})
+}
+
+// SummarizeIPReservations summarizes the output of the ListIPReservations tool, such as
+// "2 reservations: 10.0.0.12 in 10.0.0.0/24 for lb-vip, 10.0.0.13 in 10.0.0.0/24"
+func SummarizeIPReservations(result json.RawMessage) string {
+	var output ListIPReservationsOutput
+	if err := json.Unmarshal(result, &output); err != nil {
+		return ""
+	}
+	if len(output.Reservations) == 0 {
+		return "No IP reservations"
+	}
+
+	descriptions := make([]string, len(output.Reservations))
+	for i := range output.Reservations {
+		descriptions[i] = describeReservation(&output.Reservations[i])
+	}
+	if len(descriptions) == 1 {
+		return "1 reservation: " + descriptions[0]
+	}
+	return fmt.Sprintf("%d reservations: %s", len(descriptions), strings.Join(descriptions, ", "))
+}
+
+// describeReservation describes a reservation as "<ip> in <cidr> for <hostname> (<mac>)", leaving
+// out the parts it does not have
+func describeReservation(reservation *ipam.Reservation) string {
+	description := reservation.IP
+	if reservation.SubnetCIDR != "" {
+		description += " in " + reservation.SubnetCIDR
+	}
+	if reservation.Hostname != "" {
+		description += " for " + reservation.Hostname
+	}
+	if reservation.MAC != "" {
+		description += " (" + reservation.MAC + ")"
+	}
+	if reservation.Released() {
+		description += " [released]"
+	}
+	return description
+}
@@ -0,0 +1,158 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lspecian/maas-mcp-server/internal/service/ipam"
+)
+
+// SubnetUtilizationOutputSchema is the output schema of the GetSubnetUtilization tool
+var SubnetUtilizationOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["subnet_id", "cidr", "total", "used", "reserved", "dynamic", "free", "largest_free_blocks"],
+	"properties": {
+		"subnet_id": {"type": "integer"},
+		"cidr": {"type": "string"},
+		"total": {"type": "integer", "description": "Assignable addresses of the subnet"},
+		"used": {"type": "integer", "description": "Addresses assigned by MAAS, and the gateway"},
+		"reserved": {"type": "integer", "description": "Unused addresses in reserved ranges"},
+		"dynamic": {"type": "integer", "description": "Unused addresses in dynamic (DHCP) ranges"},
+		"free": {"type": "integer", "description": "Addresses in none of the above"},
+		"usage_percent": {"type": "number"},
+		"largest_free_blocks": {
+			"type": "array",
+			"items": {
+				"type": "object",
+				"required": ["start", "end", "size"],
+				"properties": {
+					"start": {"type": "string"},
+					"end": {"type": "string"},
+					"size": {"type": "integer"}
+				}
+			}
+		},
+		"reserved_ranges": {"type": "array", "items": ` + ipRangeSchema + `},
+		"dynamic_ranges": {"type": "array", "items": ` + ipRangeSchema + `},
+		"maas_statistics": {"type": "object", "description": "Statistics as reported by MAAS"}
+	}
+}`)
+
+// FreeIPsOutputSchema is the output schema of the FindFreeIPs tool
+var FreeIPsOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["cidr", "subnet_id", "subnet_cidr", "requested", "addresses"],
+	"properties": {
+		"cidr": {"type": "string"},
+		"subnet_id": {"type": "integer", "description": "MAAS subnet the CIDR lies in"},
+		"subnet_cidr": {"type": "string"},
+		"requested": {"type": "integer"},
+		"addresses": {"type": "array", "items": {"type": "string"}, "description": "Unused addresses, fewer than requested when the CIDR runs out"},
+		"exclude_dynamic": {"type": "boolean"}
+	}
+}`)
+
+// IPAMTools provides MCP tools for subnet address utilization
+type IPAMTools struct {
+	service *ipam.Service
+}
+
+// NewIPAMTools creates a new IPAMTools instance
+func NewIPAMTools(service *ipam.Service) *IPAMTools {
+	return &IPAMTools{
+		service: service,
+	}
+}
+
+// GetSubnetUtilizationInput represents the input for the GetSubnetUtilization tool
+type GetSubnetUtilizationInput struct {
+	SubnetID  int `json:"subnet_id"`
+	MaxBlocks int `json:"max_blocks,omitempty"`
+}
+
+// GetSubnetUtilization returns the used, reserved, dynamic and free address counts of a subnet
+// and its largest free blocks
+func (t *IPAMTools) GetSubnetUtilization(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params GetSubnetUtilizationInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.SubnetID <= 0 {
+		return nil, fmt.Errorf("subnet_id is required")
+	}
+
+	utilization, err := t.service.GetSubnetUtilization(ctx, params.SubnetID, params.MaxBlocks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subnet utilization: %w", err)
+	}
+
+	return marshalOutput(utilization)
+}
+
+// SummarizeSubnetUtilization summarizes the output of the GetSubnetUtilization tool, such as
+// "10.0.0.0/24: 43.7% in use, 143 of 254 addresses free (4 used, 8 reserved, 99 dynamic)"
+func SummarizeSubnetUtilization(result json.RawMessage) string {
+	var output ipam.SubnetUtilization
+	if err := json.Unmarshal(result, &output); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s: %.1f%% in use, %d of %d addresses free (%d used, %d reserved, %d dynamic)",
+		output.CIDR, output.UsagePercent, output.Free, output.Total, output.Used, output.Reserved, output.Dynamic)
+}
+
+// FindFreeIPsInput represents the input for the FindFreeIPs tool
+type FindFreeIPsInput struct {
+	CIDR           string `json:"cidr"`
+	Count          int    `json:"count,omitempty"`
+	ExcludeDynamic bool   `json:"exclude_dynamic,omitempty"`
+}
+
+// FindFreeIPs returns unused addresses of a CIDR
+func (t *IPAMTools) FindFreeIPs(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params FindFreeIPsInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.CIDR == "" {
+		return nil, fmt.Errorf("cidr is required")
+	}
+	if params.Count == 0 {
+		params.Count = 1
+	}
+
+	free, err := t.service.FindFreeIPs(ctx, params.CIDR, params.Count, params.ExcludeDynamic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find free IP addresses: %w", err)
+	}
+
+	return marshalOutput(free)
+}
+
+// SummarizeFreeIPs summarizes the output of the FindFreeIPs tool, such as
+// "2 free addresses in 10.0.0.0/24: 10.0.0.12, 10.0.0.13"
+func SummarizeFreeIPs(result json.RawMessage) string {
+	var output ipam.FreeIPs
+	if err := json.Unmarshal(result, &output); err != nil {
+		return ""
+	}
+	if len(output.Addresses) == 0 {
+		return "No free addresses in " + output.CIDR
+	}
+
+	summary := fmt.Sprintf("%d free addresses in %s: %s", len(output.Addresses), output.CIDR, strings.Join(output.Addresses, ", "))
+	if len(output.Addresses) == 1 {
+		summary = fmt.Sprintf("1 free address in %s: %s", output.CIDR, output.Addresses[0])
+	}
+	if len(output.Addresses) < output.Requested {
+		summary += fmt.Sprintf(" (%d requested)", output.Requested)
+	}
+	return summary
+}
@@ -0,0 +1,352 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/lspecian/maas-mcp-server/internal/models"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+	"github.com/lspecian/maas-mcp-server/internal/service/machine"
+	"github.com/lspecian/maas-mcp-server/internal/service/progress"
+	"github.com/lspecian/maas-mcp-server/internal/transport/mcp/events"
+)
+
+const (
+	// defaultOperationTimeout bounds how long an operation waits for a final status
+	defaultOperationTimeout = time.Hour
+
+	// defaultOperationRetention keeps finished operations around so clients can catch up on their events
+	defaultOperationRetention = 10 * time.Minute
+
+	// maxConsecutivePollErrors is the number of failed status reads in a row that fails the operation
+	maxConsecutivePollErrors = 3
+
+	// operationStreamPath is the SSE endpoint of the MCP server, which streams the published events
+	// of the operations given in its operation_id query parameter
+	operationStreamPath = "/mcp/sse"
+)
+
+// machineWorkflow describes the MAAS statuses a machine passes through during an action
+type machineWorkflow struct {
+	// operation is the name of the action
+	operation string
+
+	// progress maps the intermediate statuses (lower case) to a completion percentage
+	progress map[string]float64
+
+	// done is the status that ends the operation successfully
+	done string
+
+	// failed lists the statuses that end the operation with an error
+	failed []string
+}
+
+var (
+	allocateWorkflow = machineWorkflow{
+		operation: "allocate",
+		done:      "Allocated",
+	}
+
+	deployWorkflow = machineWorkflow{
+		operation: "deploy",
+		progress:  deployProgress,
+		done:      "Deployed",
+		failed:    []string{"Failed deployment"},
+	}
+
+	releaseWorkflow = machineWorkflow{
+		operation: "release",
+		progress:  map[string]float64{"releasing": 30, "disk erasing": 60},
+		done:      "Ready",
+		failed:    []string{"Failed releasing", "Failed disk erasing"},
+	}
+)
+
+// isFailed reports whether status ends the workflow with an error
+func (w machineWorkflow) isFailed(status string) bool {
+	for _, failed := range w.failed {
+		if strings.EqualFold(status, failed) {
+			return true
+		}
+	}
+	return false
+}
+
+// MachineOperations runs machine actions in the background. The MAAS action is started
+// synchronously, so invalid requests fail immediately; the machine is then polled until MAAS
+// reports a final status. Every status transition is reported to the progress tracker and
+// published, so SSE clients following the operation or the machine see it.
+type MachineOperations struct {
+	service      *machine.Service
+	tracker      *progress.ProgressTracker
+	publisher    events.Publisher
+	logger       *logrus.Logger
+	pollInterval time.Duration
+	timeout      time.Duration
+	retention    time.Duration
+}
+
+// NewMachineOperations creates a new background machine operation runner
+func NewMachineOperations(service *machine.Service, tracker *progress.ProgressTracker, logger *logrus.Logger) *MachineOperations {
+	return &MachineOperations{
+		service:      service,
+		tracker:      tracker,
+		logger:       logger,
+		pollInterval: defaultPollInterval,
+		timeout:      defaultOperationTimeout,
+		retention:    defaultOperationRetention,
+	}
+}
+
+// SetPolling changes how often machine status is polled and how long an operation may run.
+// Zero values keep the current settings.
+func (o *MachineOperations) SetPolling(interval, timeout time.Duration) {
+	if interval > 0 {
+		o.pollInterval = interval
+	}
+	if timeout > 0 {
+		o.timeout = timeout
+	}
+}
+
+// SetPublisher publishes the status changes and outcome of every operation through publisher,
+// normally the MCP server
+func (o *MachineOperations) SetPublisher(publisher events.Publisher) {
+	o.publisher = publisher
+}
+
+// Allocate allocates a machine and returns an operation that completes once MAAS reports it allocated
+func (o *MachineOperations) Allocate(ctx context.Context, constraints map[string]string) (*models.MachineOperation, error) {
+	machine, err := o.service.AllocateMachine(ctx, constraints)
+	if err != nil {
+		return nil, err
+	}
+	return o.start(allocateWorkflow, machine.ID, machine)
+}
+
+// Deploy starts deploying a machine and returns an operation that tracks it until it is deployed
+func (o *MachineOperations) Deploy(ctx context.Context, id string, osConfig map[string]string) (*models.MachineOperation, error) {
+	machine, err := o.service.DeployMachine(ctx, id, osConfig)
+	if err != nil {
+		return nil, err
+	}
+	return o.start(deployWorkflow, id, machine)
+}
+
+// Release starts releasing a machine and returns an operation that tracks it until it is ready
+func (o *MachineOperations) Release(ctx context.Context, id string, comment string) (*models.MachineOperation, error) {
+	if err := o.service.ReleaseMachine(ctx, id, comment); err != nil {
+		return nil, err
+	}
+
+	// Releasing does not return the machine; the first poll picks up its status instead
+	return o.start(releaseWorkflow, id, nil)
+}
+
+// Cancel stops following an operation and asks MAAS to abort the machine's action
+func (o *MachineOperations) Cancel(operationID string) error {
+	return o.tracker.CancelOperation(operationID)
+}
+
+// start registers the operation with the progress tracker and watches the machine in the background
+func (o *MachineOperations) start(workflow machineWorkflow, systemID string, machine *types.MachineContext) (*models.MachineOperation, error) {
+	// Event IDs are built from the operation ID with ':' separators, so the ID must not contain one
+	operationID := fmt.Sprintf("machine-%s-%s", workflow.operation, uuid.NewString())
+
+	reporter, ctx, err := o.tracker.StartOperation(operationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start %s operation: %w", workflow.operation, err)
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"operation_id": operationID,
+		"operation":    workflow.operation,
+		"system_id":    systemID,
+	}).Info("Started background machine operation")
+
+	go o.watch(ctx, reporter, workflow, systemID, machine)
+
+	return &models.MachineOperation{
+		OperationID: operationID,
+		Operation:   workflow.operation,
+		SystemID:    systemID,
+		Status:      string(events.StatusInitializing),
+		StreamURL:   operationStreamPath + "?operation_id=" + operationID,
+	}, nil
+}
+
+// watch polls the machine until the workflow reaches a final status, the operation times out,
+// or the operation is cancelled, in which case MAAS is asked to abort the action
+func (o *MachineOperations) watch(ctx context.Context, reporter progress.ProgressReporter, workflow machineWorkflow, systemID string, machine *types.MachineContext) {
+	operationID := reporter.OperationID()
+	logger := o.logger.WithFields(logrus.Fields{
+		"operation_id": operationID,
+		"system_id":    systemID,
+	})
+	defer o.scheduleCleanup(operationID)
+
+	ticker := time.NewTicker(o.pollInterval)
+	defer ticker.Stop()
+	deadline := time.NewTimer(o.timeout)
+	defer deadline.Stop()
+
+	previousStatus := ""
+	percent := 0.0
+	pollErrors := 0
+
+	for {
+		if machine != nil && !strings.EqualFold(machine.Status, previousStatus) {
+			if o.reportTransition(reporter, workflow, previousStatus, machine, &percent) {
+				return
+			}
+			previousStatus = machine.Status
+		}
+
+		select {
+		case <-ctx.Done():
+			o.abort(reporter, workflow, systemID, previousStatus, logger)
+			return
+		case <-deadline.C:
+			o.fail(reporter, systemID,
+				fmt.Sprintf("Timed out after %s waiting for machine %s to reach %s", o.timeout, systemID, workflow.done),
+				http.StatusGatewayTimeout,
+				transitionDetails(systemID, "", previousStatus, previousStatus),
+				true,
+			)
+			return
+		case <-ticker.C:
+		}
+
+		current, err := o.service.GetMachine(ctx, systemID)
+		if err != nil {
+			if ctx.Err() != nil {
+				// Cancelled while polling; the next iteration aborts
+				continue
+			}
+			pollErrors++
+			logger.WithError(err).Warn("Failed to read machine status")
+			if pollErrors >= maxConsecutivePollErrors {
+				o.fail(reporter, systemID,
+					fmt.Sprintf("Failed to read status of machine %s: %v", systemID, err),
+					errorStatusCode(err),
+					transitionDetails(systemID, "", previousStatus, previousStatus),
+					true,
+				)
+				return
+			}
+			machine = nil
+			continue
+		}
+		pollErrors = 0
+		machine = current
+	}
+}
+
+// abort asks MAAS to abort the action of a cancelled operation. The operation context is already
+// cancelled, so the abort gets its own deadline.
+func (o *MachineOperations) abort(reporter progress.ProgressReporter, workflow machineWorkflow, systemID, previousStatus string, logger *logrus.Entry) {
+	ctx, cancel := context.WithTimeout(context.Background(), abortTimeout)
+	defer cancel()
+
+	details := transitionDetails(systemID, "", previousStatus, previousStatus)
+	if _, err := o.service.AbortMachineOperation(ctx, systemID, "Cancelled by MCP client"); err != nil {
+		logger.WithError(err).Error("Failed to abort machine operation")
+		o.fail(reporter, systemID,
+			fmt.Sprintf("The %s of machine %s was cancelled but could not be aborted: %v", workflow.operation, systemID, err),
+			errorStatusCode(err),
+			details,
+			false,
+		)
+		return
+	}
+
+	logger.Info("Machine operation cancelled and aborted in MAAS")
+	statusEvent := events.NewStatusEvent(reporter.OperationID(), events.StatusInProgress, events.StatusCancelled,
+		fmt.Sprintf("The %s of machine %s was aborted", workflow.operation, systemID), details)
+	statusEvent.MachineID = systemID
+	o.publish(statusEvent)
+}
+
+// reportTransition publishes a status change and reports whether the operation has finished
+func (o *MachineOperations) reportTransition(reporter progress.ProgressReporter, workflow machineWorkflow, previousStatus string, machine *types.MachineContext, percent *float64) bool {
+	details := transitionDetails(machine.ID, machine.Name, previousStatus, machine.Status)
+	operationID := reporter.OperationID()
+	message := fmt.Sprintf("Machine %s is %s", machine.ID, machine.Status)
+
+	switch {
+	case strings.EqualFold(machine.Status, workflow.done):
+		reporter.ReportCompletion(machine, message)
+		completion := events.NewCompletionEvent(operationID, machine, message, time.Since(reporter.StartTime()).Seconds())
+		completion.MachineID = machine.ID
+		o.publish(completion)
+		return true
+	case workflow.isFailed(machine.Status):
+		o.fail(reporter, machine.ID, fmt.Sprintf("Machine %s entered status %s", machine.ID, machine.Status), http.StatusConflict, details, false)
+		return true
+	}
+
+	// Statuses outside the workflow are still reported, at the last known percentage
+	if value, ok := workflow.progress[strings.ToLower(machine.Status)]; ok && value > *percent {
+		*percent = value
+	}
+	reporter.ReportProgress(*percent, message, details)
+
+	previous := events.StatusInProgress
+	if previousStatus == "" {
+		previous = events.StatusInitializing
+	}
+	statusEvent := events.NewStatusEvent(operationID, previous, events.StatusInProgress, message, details)
+	statusEvent.MachineID = machine.ID
+	o.publish(statusEvent)
+	return false
+}
+
+// fail reports an operation as failed to the tracker and publishes the error
+func (o *MachineOperations) fail(reporter progress.ProgressReporter, systemID, message string, code int, details map[string]interface{}, recoverable bool) {
+	reporter.ReportError(message, code, details, recoverable)
+	errorEvent := events.NewErrorEvent(reporter.OperationID(), message, code, details, recoverable)
+	errorEvent.MachineID = systemID
+	o.publish(errorEvent)
+}
+
+// publish hands an event to the publisher, if one is set
+func (o *MachineOperations) publish(event events.Event) {
+	if o.publisher != nil {
+		o.publisher.Publish(event)
+	}
+}
+
+// scheduleCleanup removes a finished operation from the tracker once the retention period has passed
+func (o *MachineOperations) scheduleCleanup(operationID string) {
+	time.AfterFunc(o.retention, func() {
+		if err := o.tracker.CleanupOperation(operationID); err != nil {
+			o.logger.WithError(err).WithField("operation_id", operationID).Debug("Failed to clean up machine operation")
+		}
+	})
+}
+
+// transitionDetails builds the details attached to machine operation events
+func transitionDetails(systemID, hostname, previousStatus, status string) map[string]interface{} {
+	return map[string]interface{}{
+		"system_id":       systemID,
+		"hostname":        hostname,
+		"previous_status": previousStatus,
+		"status":          status,
+	}
+}
+
+// errorStatusCode returns the HTTP status code carried by a machine service error
+func errorStatusCode(err error) int {
+	var serviceErr *machine.ServiceError
+	if errors.As(err, &serviceErr) {
+		return serviceErr.StatusCode
+	}
+	return http.StatusInternalServerError
+}
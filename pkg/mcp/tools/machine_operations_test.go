@@ -0,0 +1,336 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/canonical/gomaasclient/entity"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/maas-mcp-server/internal/logging"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+	machinerepo "github.com/lspecian/maas-mcp-server/internal/repository/machine"
+	"github.com/lspecian/maas-mcp-server/internal/service/machine"
+	"github.com/lspecian/maas-mcp-server/internal/service/progress"
+	"github.com/lspecian/maas-mcp-server/internal/transport/mcp/events"
+)
+
+// abortingRepository is a mock machine repository that can also abort machine operations
+type abortingRepository struct {
+	machinerepo.MockRepository
+
+	mu      sync.Mutex
+	aborted []string
+	abortFn func(systemID string) error
+}
+
+// AbortMachineOperation records the machine whose operation was aborted
+func (r *abortingRepository) AbortMachineOperation(ctx context.Context, systemID string, comment string) (*types.Machine, error) {
+	r.mu.Lock()
+	r.aborted = append(r.aborted, systemID)
+	r.mu.Unlock()
+	if r.abortFn != nil {
+		if err := r.abortFn(systemID); err != nil {
+			return nil, err
+		}
+	}
+	return &types.Machine{SystemID: systemID, Status: "Allocated"}, nil
+}
+
+// abortedMachines returns the machines whose operation was aborted so far
+func (r *abortingRepository) abortedMachines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.aborted...)
+}
+
+// statusSequence returns the given MAAS statuses one poll at a time, repeating the last one
+func statusSequence(statuses ...string) func(ctx context.Context, systemID string) (*types.Machine, error) {
+	var mu sync.Mutex
+	next := 0
+	return func(ctx context.Context, systemID string) (*types.Machine, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		status := statuses[next]
+		if next < len(statuses)-1 {
+			next++
+		}
+		return &types.Machine{SystemID: systemID, Hostname: "machine1", Status: status}, nil
+	}
+}
+
+// deployingFrom returns a DeployMachineFn that leaves the machine in status
+func deployingFrom(status string) func(ctx context.Context, systemID string, params *entity.MachineDeployParams) (*types.Machine, error) {
+	return func(ctx context.Context, systemID string, params *entity.MachineDeployParams) (*types.Machine, error) {
+		return &types.Machine{SystemID: systemID, Hostname: "machine1", Status: status}, nil
+	}
+}
+
+// recordingPublisher records the events published to it
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+// Publish records the event
+func (p *recordingPublisher) Publish(event events.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+}
+
+// published returns the events published so far
+func (p *recordingPublisher) published() []events.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]events.Event(nil), p.events...)
+}
+
+// lastPublished waits for the publisher to receive an event of the given type and returns it
+func (p *recordingPublisher) lastPublished(t *testing.T, eventType events.EventType) events.Event {
+	t.Helper()
+	var found events.Event
+	require.Eventually(t, func() bool {
+		published := p.published()
+		if len(published) == 0 || published[len(published)-1].Type() != eventType {
+			return false
+		}
+		found = published[len(published)-1]
+		return true
+	}, 5*time.Second, 5*time.Millisecond)
+	return found
+}
+
+// collectEvents reads the tracker events of an operation until a completion or error event arrives
+func collectEvents(t *testing.T, tracker *progress.ProgressTracker, operationID string) []events.Event {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	eventChan, err := tracker.SubscribeToEvents(ctx, operationID)
+	require.NoError(t, err)
+
+	var received []events.Event
+	for {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("operation %s did not finish, got %d events", operationID, len(received))
+		case event := <-eventChan:
+			received = append(received, event)
+			if event.Type() == events.EventTypeCompletion || event.Type() == events.EventTypeError {
+				return received
+			}
+		}
+	}
+}
+
+func newTestMachineOperations(t *testing.T, repository machinerepo.Repository) (*MachineOperations, *progress.ProgressTracker, *recordingPublisher) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	tracker := progress.NewProgressTracker(&logging.Logger{Logger: logger})
+
+	operations := NewMachineOperations(machine.NewService(repository, logger), tracker, logger)
+	operations.SetPolling(5*time.Millisecond, 5*time.Second)
+	publisher := &recordingPublisher{}
+	operations.SetPublisher(publisher)
+	return operations, tracker, publisher
+}
+
+func TestMachineOperations_Deploy(t *testing.T) {
+	repository := &abortingRepository{MockRepository: machinerepo.MockRepository{
+		DeployMachineFn: deployingFrom("Allocated"),
+		GetMachineFn:    statusSequence("Deploying", "Deploying", "Deployed"),
+	}}
+	operations, tracker, publisher := newTestMachineOperations(t, repository)
+
+	operation, err := operations.Deploy(context.Background(), "abc123", map[string]string{"distro_series": "jammy"})
+	require.NoError(t, err)
+	assert.Equal(t, "deploy", operation.Operation)
+	assert.Equal(t, "abc123", operation.SystemID)
+	assert.NotContains(t, operation.OperationID, ":")
+	assert.Equal(t, "/mcp/sse?operation_id="+operation.OperationID, operation.StreamURL)
+
+	received := collectEvents(t, tracker, operation.OperationID)
+	var percentages []float64
+	for _, event := range received {
+		if progressEvent, ok := event.(*events.ProgressEvent); ok {
+			percentages = append(percentages, progressEvent.Progress)
+		}
+	}
+	assert.Equal(t, []float64{10, 50}, percentages)
+	assert.Equal(t, events.EventTypeCompletion, received[len(received)-1].Type())
+
+	// Every status change is published, and the outcome last, all naming the operation and machine
+	publisher.lastPublished(t, events.EventTypeCompletion)
+	published := publisher.published()
+	require.Len(t, published, 3)
+	for _, event := range published {
+		operationID, machineID := event.Subject()
+		assert.Equal(t, operation.OperationID, operationID)
+		assert.Equal(t, "abc123", machineID)
+	}
+	assert.Equal(t, events.EventTypeStatus, published[0].Type())
+	assert.Equal(t, events.EventTypeStatus, published[1].Type())
+	assert.Empty(t, repository.abortedMachines())
+}
+
+func TestMachineOperations_DeployFailed(t *testing.T) {
+	operations, tracker, publisher := newTestMachineOperations(t, &abortingRepository{MockRepository: machinerepo.MockRepository{
+		DeployMachineFn: deployingFrom("Deploying"),
+		GetMachineFn:    statusSequence("Failed deployment"),
+	}})
+
+	operation, err := operations.Deploy(context.Background(), "abc123", nil)
+	require.NoError(t, err)
+
+	received := collectEvents(t, tracker, operation.OperationID)
+	errorEvent, ok := received[len(received)-1].(*events.ErrorEvent)
+	require.True(t, ok)
+	assert.False(t, errorEvent.Recoverable)
+	publisher.lastPublished(t, events.EventTypeError)
+}
+
+func TestMachineOperations_DeployInvalid(t *testing.T) {
+	operations, _, _ := newTestMachineOperations(t, &abortingRepository{})
+
+	// The MAAS action is started synchronously, so an invalid request fails straight away
+	operation, err := operations.Deploy(context.Background(), "", nil)
+	assert.Error(t, err)
+	assert.Nil(t, operation)
+}
+
+func TestMachineOperations_PollErrors(t *testing.T) {
+	operations, tracker, _ := newTestMachineOperations(t, &abortingRepository{MockRepository: machinerepo.MockRepository{
+		DeployMachineFn: deployingFrom("Deploying"),
+		GetMachineFn: func(ctx context.Context, systemID string) (*types.Machine, error) {
+			return nil, errors.New("MAAS is down")
+		},
+	}})
+
+	operation, err := operations.Deploy(context.Background(), "abc123", nil)
+	require.NoError(t, err)
+
+	received := collectEvents(t, tracker, operation.OperationID)
+	errorEvent, ok := received[len(received)-1].(*events.ErrorEvent)
+	require.True(t, ok)
+	assert.True(t, errorEvent.Recoverable)
+	assert.Contains(t, errorEvent.Error, "Failed to read status of machine abc123")
+}
+
+func TestMachineOperations_Release(t *testing.T) {
+	var released []string
+	operations, tracker, _ := newTestMachineOperations(t, &abortingRepository{MockRepository: machinerepo.MockRepository{
+		ReleaseMachineFn: func(ctx context.Context, systemIDs []string, comment string) error {
+			released = append(released, systemIDs...)
+			return nil
+		},
+		GetMachineFn: statusSequence("Releasing", "Disk erasing", "Ready"),
+	}})
+
+	operation, err := operations.Release(context.Background(), "abc123", "done")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"abc123"}, released)
+
+	received := collectEvents(t, tracker, operation.OperationID)
+	assert.Equal(t, events.EventTypeCompletion, received[len(received)-1].Type())
+}
+
+func TestMachineOperations_Allocate(t *testing.T) {
+	operations, tracker, _ := newTestMachineOperations(t, &abortingRepository{MockRepository: machinerepo.MockRepository{
+		AllocateMachineFn: func(ctx context.Context, params *entity.MachineAllocateParams) (*types.Machine, error) {
+			return &types.Machine{SystemID: "abc123", Status: "Allocated"}, nil
+		},
+	}})
+
+	operation, err := operations.Allocate(context.Background(), map[string]string{"zone": "default"})
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", operation.SystemID)
+
+	// The machine is allocated as soon as MAAS answers
+	received := collectEvents(t, tracker, operation.OperationID)
+	assert.Equal(t, events.EventTypeCompletion, received[len(received)-1].Type())
+}
+
+func TestMachineOperations_CancelAborts(t *testing.T) {
+	repository := &abortingRepository{MockRepository: machinerepo.MockRepository{
+		DeployMachineFn: deployingFrom("Deploying"),
+		GetMachineFn:    statusSequence("Deploying"),
+	}}
+	operations, _, publisher := newTestMachineOperations(t, repository)
+
+	operation, err := operations.Deploy(context.Background(), "abc123", nil)
+	require.NoError(t, err)
+	publisher.lastPublished(t, events.EventTypeStatus)
+
+	require.NoError(t, operations.Cancel(operation.OperationID))
+	require.Eventually(t, func() bool {
+		return len(repository.abortedMachines()) == 1
+	}, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"abc123"}, repository.abortedMachines())
+
+	statusEvent, ok := publisher.lastPublished(t, events.EventTypeStatus).(*events.StatusEvent)
+	require.True(t, ok)
+	assert.Equal(t, events.StatusCancelled, statusEvent.CurrentStatus)
+
+	assert.Error(t, operations.Cancel("machine-deploy-unknown"))
+}
+
+func TestMachineOperations_AbortFails(t *testing.T) {
+	repository := &abortingRepository{
+		MockRepository: machinerepo.MockRepository{
+			DeployMachineFn: deployingFrom("Deploying"),
+			GetMachineFn:    statusSequence("Deploying"),
+		},
+		abortFn: func(systemID string) error { return errors.New("machine is not deploying") },
+	}
+	operations, _, publisher := newTestMachineOperations(t, repository)
+
+	operation, err := operations.Deploy(context.Background(), "abc123", nil)
+	require.NoError(t, err)
+	publisher.lastPublished(t, events.EventTypeStatus)
+
+	require.NoError(t, operations.Cancel(operation.OperationID))
+	errorEvent, ok := publisher.lastPublished(t, events.EventTypeError).(*events.ErrorEvent)
+	require.True(t, ok)
+	assert.Contains(t, errorEvent.Error, "was cancelled but could not be aborted")
+}
+
+func TestMachineTools_DeployAsync(t *testing.T) {
+	repository := &abortingRepository{MockRepository: machinerepo.MockRepository{
+		DeployMachineFn: deployingFrom("Deploying"),
+		GetMachineFn:    statusSequence("Deployed"),
+	}}
+	operations, _, _ := newTestMachineOperations(t, repository)
+	machineTools := NewMachineTools(machine.NewService(repository, logrus.New()))
+
+	// Without the runner, which only the HTTP transport sets up, async calls fail
+	_, err := machineTools.DeployMachine(context.Background(), json.RawMessage(`{"id":"abc123","async":true}`))
+	assert.EqualError(t, err, "background deployments are not available on this transport")
+	_, err = machineTools.CancelMachineOperation(context.Background(), json.RawMessage(`{"operation_id":"x"}`))
+	assert.EqualError(t, err, "background machine operations are not available on this transport")
+
+	machineTools.SetOperations(operations)
+	output, err := machineTools.DeployMachine(context.Background(), json.RawMessage(`{"id":"abc123","async":true}`))
+	require.NoError(t, err)
+	assertMatchesSchema(t, MachineActionOutputSchema, output)
+
+	var deployment DeployMachineOutput
+	require.NoError(t, json.Unmarshal(output, &deployment))
+	require.NotNil(t, deployment.Operation)
+	assert.Nil(t, deployment.Machine)
+	assert.Equal(t, "The deploy of machine abc123 runs in the background as operation "+deployment.Operation.OperationID, SummarizeMachineAction(output))
+
+	// A synchronous deployment still returns the machine
+	output, err = machineTools.DeployMachine(context.Background(), json.RawMessage(`{"id":"abc123"}`))
+	require.NoError(t, err)
+	assertMatchesSchema(t, MachineActionOutputSchema, output)
+	assert.Equal(t, "Machine abc123 (machine1) is Deploying", SummarizeMachineAction(output))
+
+	_, err = machineTools.CancelMachineOperation(context.Background(), json.RawMessage(`{}`))
+	assert.EqualError(t, err, "operation ID is required")
+}
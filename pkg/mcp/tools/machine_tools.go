@@ -3,24 +3,120 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/lspecian/maas-mcp-server/internal/models"
 	"github.com/lspecian/maas-mcp-server/internal/models/types"
 	"github.com/lspecian/maas-mcp-server/internal/service/machine"
+	"github.com/lspecian/maas-mcp-server/internal/service/progress"
 )
 
+// defaultPollInterval is how often a deployment is polled while progress is being reported
+const defaultPollInterval = 10 * time.Second
+
+// abortTimeout bounds the MAAS call that aborts a deployment whose tool call was cancelled
+const abortTimeout = 30 * time.Second
+
+// deployProgress maps the statuses a machine passes through while deploying to a percentage
+var deployProgress = map[string]float64{
+	"allocated": 10,
+	"deploying": 50,
+}
+
+// machineSchema describes a machine in the output of the machine tools
+const machineSchema = `{
+	"type": "object",
+	"required": ["id", "status"],
+	"properties": {
+		"id": {"type": "string", "description": "MAAS system ID"},
+		"name": {"type": "string"},
+		"fqdn": {"type": "string"},
+		"status": {"type": "string", "description": "MAAS status, such as Ready or Deployed"},
+		"power_state": {"type": "string"},
+		"zone": {"type": "string"},
+		"pool": {"type": "string"},
+		"tags": {"type": ["array", "null"], "items": {"type": "string"}},
+		"cpu_count": {"type": "integer"},
+		"memory": {"type": "integer"}
+	}
+}`
+
+// ListMachinesOutputSchema is the output schema of the ListMachines tool
+var ListMachinesOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["machines"],
+	"properties": {
+		"machines": {"type": "array", "items": ` + machineSchema + `}
+	}
+}`)
+
+// MachineOutputSchema is the output schema of the tools that return a single machine:
+// GetMachineDetails, PowerOnMachine and PowerOffMachine
+var MachineOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["machine"],
+	"properties": {
+		"machine": ` + machineSchema + `
+	}
+}`)
+
+// operationSchema describes a background machine operation
+const operationSchema = `{
+	"type": "object",
+	"required": ["operation_id", "operation", "status", "stream_url"],
+	"properties": {
+		"operation_id": {"type": "string"},
+		"operation": {"type": "string", "description": "Machine action: allocate, deploy or release"},
+		"system_id": {"type": "string"},
+		"status": {"type": "string", "description": "Status of the operation when it was accepted"},
+		"stream_url": {"type": "string", "description": "SSE endpoint that streams the operation's events"}
+	}
+}`
+
+// MachineActionOutputSchema is the output schema of the AllocateMachine and DeployMachine tools:
+// the machine, or the operation that follows the action when it runs in the background
+var MachineActionOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"machine": ` + machineSchema + `,
+		"operation": ` + operationSchema + `
+	},
+	"oneOf": [{"required": ["machine"]}, {"required": ["operation"]}]
+}`)
+
+// CancelMachineOperationOutputSchema is the output schema of the CancelMachineOperation tool
+var CancelMachineOperationOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["cancelled"],
+	"properties": {
+		"cancelled": {"type": "string", "description": "ID of the cancelled operation"}
+	}
+}`)
+
 // MachineTools provides MCP tools for machine management
 type MachineTools struct {
-	service *machine.Service
+	service      *machine.Service
+	operations   *MachineOperations
+	pollInterval time.Duration
 }
 
 // NewMachineTools creates a new MachineTools instance
 func NewMachineTools(service *machine.Service) *MachineTools {
 	return &MachineTools{
-		service: service,
+		service:      service,
+		pollInterval: defaultPollInterval,
 	}
 }
 
+// SetOperations lets AllocateMachine and DeployMachine run in the background through operations
+func (t *MachineTools) SetOperations(operations *MachineOperations) {
+	t.operations = operations
+}
+
 // ListMachinesInput represents the input for the ListMachines tool
 type ListMachinesInput struct {
 	Filters map[string]string `json:"filters,omitempty"`
@@ -45,7 +141,10 @@ func (t *MachineTools) ListMachines(ctx context.Context, input json.RawMessage)
 		return nil, fmt.Errorf("failed to list machines: %w", err)
 	}
 
-	// Prepare output
+	// Prepare output; an empty list stays a list
+	if machines == nil {
+		machines = []types.MachineContext{}
+	}
 	output := ListMachinesOutput{
 		Machines: machines,
 	}
@@ -59,6 +158,80 @@ func (t *MachineTools) ListMachines(ctx context.Context, input json.RawMessage)
 	return result, nil
 }
 
+// SummarizeMachines summarizes the output of the ListMachines tool by status, such as
+// "3 machines, 2 Ready, 1 Deployed"
+func SummarizeMachines(result json.RawMessage) string {
+	var output ListMachinesOutput
+	if err := json.Unmarshal(result, &output); err != nil {
+		return ""
+	}
+	if len(output.Machines) == 0 {
+		return "No machines"
+	}
+
+	counts := make(map[string]int)
+	for _, machine := range output.Machines {
+		counts[machine.Status]++
+	}
+	statuses := make([]string, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	// Most common status first
+	sort.Slice(statuses, func(i, j int) bool {
+		if counts[statuses[i]] != counts[statuses[j]] {
+			return counts[statuses[i]] > counts[statuses[j]]
+		}
+		return statuses[i] < statuses[j]
+	})
+
+	parts := []string{fmt.Sprintf("%d machines", len(output.Machines))}
+	if len(output.Machines) == 1 {
+		parts[0] = "1 machine"
+	}
+	for _, status := range statuses {
+		name := status
+		if name == "" {
+			name = "unknown status"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", counts[status], name))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SummarizeMachine summarizes the output of the tools that return a single machine, such as
+// "Machine abc123 (node1) is Deployed, power on"
+func SummarizeMachine(result json.RawMessage) string {
+	var output GetMachineDetailsOutput
+	if err := json.Unmarshal(result, &output); err != nil || output.Machine == nil {
+		return ""
+	}
+
+	machine := output.Machine
+	summary := "Machine " + machine.ID
+	if machine.Name != "" {
+		summary += " (" + machine.Name + ")"
+	}
+	summary += " is " + machine.Status
+	if machine.PowerState != "" {
+		summary += ", power " + machine.PowerState
+	}
+	return summary
+}
+
+// SummarizeMachineAction summarizes the output of the AllocateMachine and DeployMachine tools,
+// which is the machine or, for an action that runs in the background, its operation
+func SummarizeMachineAction(result json.RawMessage) string {
+	var output DeployMachineOutput
+	if err := json.Unmarshal(result, &output); err != nil {
+		return ""
+	}
+	if output.Operation != nil {
+		return fmt.Sprintf("The %s of machine %s runs in the background as operation %s", output.Operation.Operation, output.Operation.SystemID, output.Operation.OperationID)
+	}
+	return SummarizeMachine(result)
+}
+
 // GetMachineDetailsInput represents the input for the GetMachineDetails tool
 type GetMachineDetailsInput struct {
 	ID string `json:"id"`
@@ -187,3 +360,237 @@ func (t *MachineTools) PowerOffMachine(ctx context.Context, input json.RawMessag
 
 	return result, nil
 }
+
+// AllocateMachineInput represents the input for the AllocateMachine tool. Storage is given either
+// as a MAAS storage constraint string or as typed constraints.
+type AllocateMachineInput struct {
+	Hostname           string                           `json:"hostname,omitempty"`
+	Zone               string                           `json:"zone,omitempty"`
+	Pool               string                           `json:"pool,omitempty"`
+	Architecture       string                           `json:"architecture,omitempty"`
+	Tags               []string                         `json:"tags,omitempty"`
+	Storage            string                           `json:"storage,omitempty"`
+	StorageConstraints []models.SimpleStorageConstraint `json:"storage_constraints,omitempty"`
+	Async              bool                             `json:"async,omitempty"`
+}
+
+// AllocateMachineOutput represents the output for the AllocateMachine tool
+type AllocateMachineOutput struct {
+	Machine   *types.MachineContext    `json:"machine,omitempty"`
+	Operation *models.MachineOperation `json:"operation,omitempty"`
+}
+
+// AllocateMachine allocates a machine matching the given constraints. With async set, the tool
+// returns the operation that follows the allocation instead of the machine.
+func (t *MachineTools) AllocateMachine(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	var params AllocateMachineInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Both storage forms end up as the MAAS storage constraint string
+	storage, err := models.NormalizeMAASStorageConstraints(params.Storage, params.StorageConstraints)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage constraints: %w", err)
+	}
+
+	constraints := make(map[string]string)
+	for key, value := range map[string]string{
+		"hostname":     params.Hostname,
+		"zone":         params.Zone,
+		"pool":         params.Pool,
+		"architecture": params.Architecture,
+		"tags":         strings.Join(params.Tags, ","),
+		"storage":      storage,
+	} {
+		if value != "" {
+			constraints[key] = value
+		}
+	}
+
+	if params.Async {
+		if t.operations == nil {
+			return nil, fmt.Errorf("background allocations are not available on this transport")
+		}
+		operation, err := t.operations.Allocate(ctx, constraints)
+		if err != nil {
+			return nil, fmt.Errorf("failed to allocate machine: %w", err)
+		}
+		return marshalOutput(AllocateMachineOutput{Operation: operation})
+	}
+
+	machine, err := t.service.AllocateMachine(ctx, constraints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate machine: %w", err)
+	}
+	return marshalOutput(AllocateMachineOutput{Machine: machine})
+}
+
+// DeployMachineInput represents the input for the DeployMachine tool
+type DeployMachineInput struct {
+	ID           string `json:"id"`
+	DistroSeries string `json:"distro_series,omitempty"`
+	HWEKernel    string `json:"hwe_kernel,omitempty"`
+	UserData     string `json:"user_data,omitempty"`
+	Async        bool   `json:"async,omitempty"`
+}
+
+// DeployMachineOutput represents the output for the DeployMachine tool
+type DeployMachineOutput struct {
+	Machine   *types.MachineContext    `json:"machine,omitempty"`
+	Operation *models.MachineOperation `json:"operation,omitempty"`
+}
+
+// DeployMachine deploys a machine. With async set, the deployment is followed in the background
+// and the tool returns the operation, whose events are streamed over SSE. Otherwise, when the
+// caller asked for progress, the tool waits until MAAS reports the machine deployed or failed and
+// reports each status change on the way, and else returns as soon as the deployment has started.
+func (t *MachineTools) DeployMachine(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params DeployMachineInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.ID == "" {
+		return nil, fmt.Errorf("machine ID is required")
+	}
+
+	osConfig := make(map[string]string)
+	if params.DistroSeries != "" {
+		osConfig["distro_series"] = params.DistroSeries
+	}
+	if params.HWEKernel != "" {
+		osConfig["hwe_kernel"] = params.HWEKernel
+	}
+	if params.UserData != "" {
+		osConfig["user_data"] = params.UserData
+	}
+
+	if params.Async {
+		if t.operations == nil {
+			return nil, fmt.Errorf("background deployments are not available on this transport")
+		}
+		operation, err := t.operations.Deploy(ctx, params.ID, osConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to deploy machine: %w", err)
+		}
+		return marshalOutput(DeployMachineOutput{Operation: operation})
+	}
+
+	// Call service
+	machine, err := t.service.DeployMachine(ctx, params.ID, osConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deploy machine: %w", err)
+	}
+
+	if reporter, ok := progress.ReporterFromContext(ctx); ok {
+		machine, err = t.waitForDeployment(ctx, reporter, params.ID, machine)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Prepare output
+	output := DeployMachineOutput{
+		Machine: machine,
+	}
+
+	// Marshal output
+	result, err := json.Marshal(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+
+	return result, nil
+}
+
+// waitForDeployment polls the machine until it is deployed or has failed, reporting each status change
+func (t *MachineTools) waitForDeployment(ctx context.Context, reporter progress.ProgressReporter, id string, machine *types.MachineContext) (*types.MachineContext, error) {
+	ticker := time.NewTicker(t.pollInterval)
+	defer ticker.Stop()
+
+	previousStatus := ""
+	percent := 0.0
+	for {
+		if !strings.EqualFold(machine.Status, previousStatus) {
+			message := fmt.Sprintf("Machine %s is %s", id, machine.Status)
+			switch {
+			case strings.EqualFold(machine.Status, "Deployed"):
+				reporter.ReportCompletion(machine, message)
+				return machine, nil
+			case strings.EqualFold(machine.Status, "Failed deployment"):
+				reporter.ReportError(message, 0, nil, false)
+				return nil, fmt.Errorf("failed to deploy machine: %s", message)
+			}
+
+			if value, ok := deployProgress[strings.ToLower(machine.Status)]; ok && value > percent {
+				percent = value
+			}
+			reporter.ReportProgress(percent, message, map[string]interface{}{
+				"system_id":       id,
+				"previous_status": previousStatus,
+				"status":          machine.Status,
+			})
+			previousStatus = machine.Status
+		}
+
+		select {
+		case <-ctx.Done():
+			// Only an explicit cancellation aborts the deployment; on shutdown MAAS carries on
+			if errors.Is(context.Cause(ctx), progress.ErrOperationCancelled) {
+				if err := t.abortDeployment(id); err != nil {
+					return nil, fmt.Errorf("deployment of machine %s was cancelled but could not be aborted: %w", id, err)
+				}
+			}
+			return nil, fmt.Errorf("stopped waiting for machine %s to deploy: %w", id, ctx.Err())
+		case <-ticker.C:
+		}
+
+		current, err := t.service.GetMachine(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get machine status: %w", err)
+		}
+		machine = current
+	}
+}
+
+// abortDeployment asks MAAS to abort the deployment after the client cancelled the call. The
+// request context is already cancelled, so the abort gets its own deadline.
+func (t *MachineTools) abortDeployment(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), abortTimeout)
+	defer cancel()
+
+	_, err := t.service.AbortMachineOperation(ctx, id, "Cancelled by MCP client")
+	return err
+}
+
+// CancelMachineOperationInput represents the input for the CancelMachineOperation tool
+type CancelMachineOperationInput struct {
+	OperationID string `json:"operation_id"`
+}
+
+// CancelMachineOperationOutput represents the output for the CancelMachineOperation tool
+type CancelMachineOperationOutput struct {
+	Cancelled string `json:"cancelled"`
+}
+
+// CancelMachineOperation cancels a background machine operation; MAAS is asked to abort the action
+func (t *MachineTools) CancelMachineOperation(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	var params CancelMachineOperationInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+	if params.OperationID == "" {
+		return nil, fmt.Errorf("operation ID is required")
+	}
+	if t.operations == nil {
+		return nil, fmt.Errorf("background machine operations are not available on this transport")
+	}
+
+	if err := t.operations.Cancel(params.OperationID); err != nil {
+		return nil, fmt.Errorf("failed to cancel machine operation: %w", err)
+	}
+	return marshalOutput(CancelMachineOperationOutput{Cancelled: params.OperationID})
+}
@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/canonical/gomaasclient/entity"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/maas-mcp-server/internal/models"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+	machinerepo "github.com/lspecian/maas-mcp-server/internal/repository/machine"
+	"github.com/lspecian/maas-mcp-server/internal/service/machine"
+)
+
+// allocatingRepository returns a mock repository that allocates machine abc123 and records the
+// parameters of the last allocation
+func allocatingRepository(allocated **entity.MachineAllocateParams) *abortingRepository {
+	return &abortingRepository{MockRepository: machinerepo.MockRepository{
+		AllocateMachineFn: func(ctx context.Context, params *entity.MachineAllocateParams) (*types.Machine, error) {
+			*allocated = params
+			return &types.Machine{SystemID: "abc123", Hostname: "machine1", Status: "Allocated"}, nil
+		},
+	}}
+}
+
+func TestMachineTools_AllocateMachine(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantStorage []string
+	}{
+		{
+			name:        "storage string",
+			input:       `{"zone":"rack-1","storage":" root:100( ssd ),data:500(hdd)"}`,
+			wantStorage: []string{"root:100(ssd),data:500(hdd)"},
+		},
+		{
+			name: "typed storage",
+			input: `{"zone":"rack-1","storage_constraints":[
+				{"name":"root","min_size_bytes":100000000000,"disk_type":"ssd"},
+				{"name":"data","min_size_bytes":500000000000,"disk_type":"hdd","count":2}
+			]}`,
+			wantStorage: []string{"root:100(ssd),data1:500(rotary),data2:500(rotary)"},
+		},
+		{
+			name:  "no storage",
+			input: `{"zone":"rack-1"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var allocated *entity.MachineAllocateParams
+			machineTools := NewMachineTools(machine.NewService(allocatingRepository(&allocated), logrus.New()))
+
+			output, err := machineTools.AllocateMachine(context.Background(), json.RawMessage(tt.input))
+			require.NoError(t, err)
+			assertMatchesSchema(t, MachineActionOutputSchema, output)
+			assert.Equal(t, "Machine abc123 (machine1) is Allocated", SummarizeMachineAction(output))
+
+			require.NotNil(t, allocated)
+			assert.Equal(t, "rack-1", allocated.Zone)
+			assert.Equal(t, tt.wantStorage, allocated.Storage)
+		})
+	}
+}
+
+func TestMachineTools_AllocateMachineInvalidStorage(t *testing.T) {
+	var allocated *entity.MachineAllocateParams
+	machineTools := NewMachineTools(machine.NewService(allocatingRepository(&allocated), logrus.New()))
+
+	tests := []struct {
+		name  string
+		input string
+		field string
+	}{
+		{name: "bad syntax", input: `{"storage":"root:100G"}`, field: "size"},
+		{name: "both forms", input: `{"storage":"100","storage_constraints":[{"min_size_bytes":1000000000}]}`, field: "storage"},
+		{name: "typed without size", input: `{"storage_constraints":[{"disk_type":"ssd"}]}`, field: "size"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := machineTools.AllocateMachine(context.Background(), json.RawMessage(tt.input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid storage constraints")
+
+			var syntaxErr *models.StorageSyntaxError
+			require.True(t, errors.As(err, &syntaxErr))
+			assert.Equal(t, tt.field, syntaxErr.Field)
+		})
+	}
+	assert.Nil(t, allocated, "an invalid allocation reached MAAS")
+}
+
+func TestMachineTools_AllocateMachineAsync(t *testing.T) {
+	var allocated *entity.MachineAllocateParams
+	repository := allocatingRepository(&allocated)
+	machineTools := NewMachineTools(machine.NewService(repository, logrus.New()))
+
+	_, err := machineTools.AllocateMachine(context.Background(), json.RawMessage(`{"async":true}`))
+	assert.EqualError(t, err, "background allocations are not available on this transport")
+
+	operations, tracker, _ := newTestMachineOperations(t, repository)
+	machineTools.SetOperations(operations)
+
+	output, err := machineTools.AllocateMachine(context.Background(), json.RawMessage(`{"storage":"50","async":true}`))
+	require.NoError(t, err)
+	assertMatchesSchema(t, MachineActionOutputSchema, output)
+	assert.Equal(t, []string{"50"}, allocated.Storage)
+
+	var allocation AllocateMachineOutput
+	require.NoError(t, json.Unmarshal(output, &allocation))
+	require.NotNil(t, allocation.Operation)
+	assert.Equal(t, "allocate", allocation.Operation.Operation)
+	assert.Equal(t, "The allocate of machine abc123 runs in the background as operation "+allocation.Operation.OperationID, SummarizeMachineAction(output))
+	collectEvents(t, tracker, allocation.Operation.OperationID)
+}
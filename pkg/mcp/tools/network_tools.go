@@ -0,0 +1,628 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/gomaasclient/entity"
+
+	"github.com/lspecian/maas-mcp-server/internal/maas/common"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// spaceSchema describes a space in the output of the space tools
+const spaceSchema = `{
+	"type": "object",
+	"required": ["id", "name"],
+	"properties": {
+		"id": {"type": "integer"},
+		"name": {"type": "string"},
+		"subnets": {"type": "array", "items": {"type": "object"}},
+		"vlans": {"type": "array", "items": {"type": "object"}},
+		"resource_url": {"type": "string"}
+	}
+}`
+
+// ipRangeSchema describes an IP range in the output of the IP range tools
+const ipRangeSchema = `{
+	"type": "object",
+	"required": ["id", "type", "start_ip", "end_ip"],
+	"properties": {
+		"id": {"type": "integer"},
+		"type": {"type": "string", "enum": ["dynamic", "reserved"]},
+		"start_ip": {"type": "string"},
+		"end_ip": {"type": "string"},
+		"subnet_id": {"type": "integer"},
+		"subnet_cidr": {"type": "string"},
+		"comment": {"type": "string"},
+		"user": {"type": "string"},
+		"resource_url": {"type": "string"}
+	}
+}`
+
+// staticRouteSchema describes a static route in the output of the static route tools
+const staticRouteSchema = `{
+	"type": "object",
+	"required": ["id", "gateway_ip"],
+	"properties": {
+		"id": {"type": "integer"},
+		"source_id": {"type": "integer"},
+		"source_cidr": {"type": "string"},
+		"destination_id": {"type": "integer"},
+		"destination_cidr": {"type": "string"},
+		"gateway_ip": {"type": "string"},
+		"metric": {"type": "integer"},
+		"resource_url": {"type": "string"}
+	}
+}`
+
+// deletedIDSchema is the output schema of the tools that delete an object by ID
+const deletedIDSchema = `{
+	"type": "object",
+	"required": ["deleted"],
+	"properties": {
+		"deleted": {"type": "integer", "description": "ID of the deleted object"}
+	}
+}`
+
+// ListSpacesOutputSchema is the output schema of the ListSpaces tool
+var ListSpacesOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["spaces"],
+	"properties": {
+		"spaces": {"type": "array", "items": ` + spaceSchema + `}
+	}
+}`)
+
+// SpaceOutputSchema is the output schema of the tools that return a single space:
+// GetSpace, CreateSpace and UpdateSpace
+var SpaceOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["space"],
+	"properties": {
+		"space": ` + spaceSchema + `
+	}
+}`)
+
+// ListIPRangesOutputSchema is the output schema of the ListIPRanges tool
+var ListIPRangesOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["ip_ranges"],
+	"properties": {
+		"ip_ranges": {"type": "array", "items": ` + ipRangeSchema + `}
+	}
+}`)
+
+// IPRangeOutputSchema is the output schema of the tools that return a single IP range:
+// GetIPRange, CreateIPRange and UpdateIPRange
+var IPRangeOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["ip_range"],
+	"properties": {
+		"ip_range": ` + ipRangeSchema + `
+	}
+}`)
+
+// ListStaticRoutesOutputSchema is the output schema of the ListStaticRoutes tool
+var ListStaticRoutesOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["static_routes"],
+	"properties": {
+		"static_routes": {"type": "array", "items": ` + staticRouteSchema + `}
+	}
+}`)
+
+// StaticRouteOutputSchema is the output schema of the CreateStaticRoute tool
+var StaticRouteOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["static_route"],
+	"properties": {
+		"static_route": ` + staticRouteSchema + `
+	}
+}`)
+
+// DeletedOutputSchema is the output schema of DeleteSpace, DeleteIPRange and DeleteStaticRoute
+var DeletedOutputSchema = json.RawMessage(deletedIDSchema)
+
+// NetworkTools provides MCP tools for space, IP range and static route management
+type NetworkTools struct {
+	client common.NetworkClient
+}
+
+// NewNetworkTools creates a new NetworkTools instance
+func NewNetworkTools(client common.NetworkClient) *NetworkTools {
+	return &NetworkTools{
+		client: client,
+	}
+}
+
+// IDInput represents the input for the tools that take only the ID of an object
+type IDInput struct {
+	ID *int `json:"id"`
+}
+
+// DeletedOutput represents the output for the tools that delete an object by ID
+type DeletedOutput struct {
+	Deleted int `json:"deleted"`
+}
+
+// ListSpacesOutput represents the output for the ListSpaces tool
+type ListSpacesOutput struct {
+	Spaces []types.Space `json:"spaces"`
+}
+
+// SpaceOutput represents the output for the tools that return a single space
+type SpaceOutput struct {
+	Space *types.Space `json:"space"`
+}
+
+// ListSpaces lists all spaces
+func (t *NetworkTools) ListSpaces(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	spaces, err := t.client.ListSpaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list spaces: %w", err)
+	}
+
+	// An empty list stays a list
+	if spaces == nil {
+		spaces = []types.Space{}
+	}
+
+	return marshalOutput(ListSpacesOutput{Spaces: spaces})
+}
+
+// SummarizeSpaces summarizes the output of the ListSpaces tool, such as "2 spaces: storage, public"
+func SummarizeSpaces(result json.RawMessage) string {
+	var output ListSpacesOutput
+	if err := json.Unmarshal(result, &output); err != nil {
+		return ""
+	}
+	if len(output.Spaces) == 0 {
+		return "No spaces"
+	}
+
+	names := make([]string, len(output.Spaces))
+	for i, space := range output.Spaces {
+		names[i] = space.Name
+	}
+	if len(names) == 1 {
+		return "1 space: " + names[0]
+	}
+	return fmt.Sprintf("%d spaces: %s", len(names), strings.Join(names, ", "))
+}
+
+// GetSpace gets a space by ID, with its subnets and VLANs
+func (t *NetworkTools) GetSpace(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	id, err := parseID(input, "space")
+	if err != nil {
+		return nil, err
+	}
+
+	space, err := t.client.GetSpace(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get space: %w", err)
+	}
+
+	return marshalOutput(SpaceOutput{Space: space})
+}
+
+// CreateSpaceInput represents the input for the CreateSpace tool
+type CreateSpaceInput struct {
+	Name string `json:"name"`
+}
+
+// CreateSpace creates a space
+func (t *NetworkTools) CreateSpace(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params CreateSpaceInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.Name == "" {
+		return nil, fmt.Errorf("space name is required")
+	}
+
+	space, err := t.client.CreateSpace(params.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create space: %w", err)
+	}
+
+	return marshalOutput(SpaceOutput{Space: space})
+}
+
+// UpdateSpaceInput represents the input for the UpdateSpace tool
+type UpdateSpaceInput struct {
+	ID   *int   `json:"id"`
+	Name string `json:"name"`
+}
+
+// UpdateSpace renames a space
+func (t *NetworkTools) UpdateSpace(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params UpdateSpaceInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.ID == nil {
+		return nil, fmt.Errorf("space ID is required")
+	}
+	if params.Name == "" {
+		return nil, fmt.Errorf("space name is required")
+	}
+
+	space, err := t.client.UpdateSpace(*params.ID, params.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update space: %w", err)
+	}
+
+	return marshalOutput(SpaceOutput{Space: space})
+}
+
+// DeleteSpace deletes a space
+func (t *NetworkTools) DeleteSpace(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	id, err := parseID(input, "space")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.client.DeleteSpace(id); err != nil {
+		return nil, fmt.Errorf("failed to delete space: %w", err)
+	}
+
+	return marshalOutput(DeletedOutput{Deleted: id})
+}
+
+// ListIPRangesInput represents the input for the ListIPRanges tool
+type ListIPRangesInput struct {
+	SubnetID int    `json:"subnet_id,omitempty"`
+	Type     string `json:"type,omitempty"`
+}
+
+// ListIPRangesOutput represents the output for the ListIPRanges tool
+type ListIPRangesOutput struct {
+	IPRanges []types.IPRange `json:"ip_ranges"`
+}
+
+// IPRangeOutput represents the output for the tools that return a single IP range
+type IPRangeOutput struct {
+	IPRange *types.IPRange `json:"ip_range"`
+}
+
+// ListIPRanges lists the reserved and dynamic IP ranges, optionally of one subnet or one type
+func (t *NetworkTools) ListIPRanges(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params ListIPRangesInput
+	if len(input) > 0 {
+		if err := json.Unmarshal(input, &params); err != nil {
+			return nil, fmt.Errorf("failed to parse input: %w", err)
+		}
+	}
+
+	// Validate input
+	if params.Type != "" && params.Type != types.IPRangeTypeDynamic && params.Type != types.IPRangeTypeReserved {
+		return nil, fmt.Errorf("type must be %q or %q", types.IPRangeTypeDynamic, types.IPRangeTypeReserved)
+	}
+
+	ranges, err := t.client.ListIPRanges()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list IP ranges: %w", err)
+	}
+
+	filtered := make([]types.IPRange, 0, len(ranges))
+	for _, ipRange := range ranges {
+		if params.SubnetID != 0 && ipRange.SubnetID != params.SubnetID {
+			continue
+		}
+		if params.Type != "" && ipRange.Type != params.Type {
+			continue
+		}
+		filtered = append(filtered, ipRange)
+	}
+
+	return marshalOutput(ListIPRangesOutput{IPRanges: filtered})
+}
+
+// SummarizeIPRanges summarizes the output of the ListIPRanges tool, such as
+// "2 IP ranges: 10.0.0.100-10.0.0.200 (dynamic), 10.0.0.1-10.0.0.9 (reserved)"
+func SummarizeIPRanges(result json.RawMessage) string {
+	var output ListIPRangesOutput
+	if err := json.Unmarshal(result, &output); err != nil {
+		return ""
+	}
+	if len(output.IPRanges) == 0 {
+		return "No IP ranges"
+	}
+
+	ranges := make([]string, len(output.IPRanges))
+	for i, ipRange := range output.IPRanges {
+		ranges[i] = fmt.Sprintf("%s-%s (%s)", ipRange.StartIP, ipRange.EndIP, ipRange.Type)
+	}
+	if len(ranges) == 1 {
+		return "1 IP range: " + ranges[0]
+	}
+	return fmt.Sprintf("%d IP ranges: %s", len(ranges), strings.Join(ranges, ", "))
+}
+
+// GetIPRange gets an IP range by ID
+func (t *NetworkTools) GetIPRange(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	id, err := parseID(input, "IP range")
+	if err != nil {
+		return nil, err
+	}
+
+	ipRange, err := t.client.GetIPRange(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get IP range: %w", err)
+	}
+
+	return marshalOutput(IPRangeOutput{IPRange: ipRange})
+}
+
+// CreateIPRangeInput represents the input for the CreateIPRange tool
+type CreateIPRangeInput struct {
+	Type    string `json:"type"`
+	Subnet  string `json:"subnet"`
+	StartIP string `json:"start_ip"`
+	EndIP   string `json:"end_ip"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// CreateIPRange creates a reserved or dynamic IP range in a subnet
+func (t *NetworkTools) CreateIPRange(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params CreateIPRangeInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.Subnet == "" {
+		return nil, fmt.Errorf("subnet is required")
+	}
+	rangeParams := &entity.IPRangeParams{
+		Type:    params.Type,
+		Subnet:  params.Subnet,
+		StartIP: params.StartIP,
+		EndIP:   params.EndIP,
+		Comment: params.Comment,
+	}
+	if err := validateIPRange(rangeParams); err != nil {
+		return nil, err
+	}
+
+	ipRange, err := t.client.CreateIPRange(rangeParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create IP range: %w", err)
+	}
+
+	return marshalOutput(IPRangeOutput{IPRange: ipRange})
+}
+
+// UpdateIPRangeInput represents the input for the UpdateIPRange tool. Fields left out are not changed.
+type UpdateIPRangeInput struct {
+	ID      *int    `json:"id"`
+	Type    string  `json:"type,omitempty"`
+	StartIP string  `json:"start_ip,omitempty"`
+	EndIP   string  `json:"end_ip,omitempty"`
+	Comment *string `json:"comment,omitempty"`
+}
+
+// UpdateIPRange changes the type, bounds or comment of an IP range
+func (t *NetworkTools) UpdateIPRange(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params UpdateIPRangeInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.ID == nil {
+		return nil, fmt.Errorf("IP range ID is required")
+	}
+	if params.Type == "" && params.StartIP == "" && params.EndIP == "" && params.Comment == nil {
+		return nil, fmt.Errorf("type, start_ip, end_ip or comment is required")
+	}
+
+	// MAAS replaces every field of the range, so start from the current one
+	current, err := t.client.GetIPRange(*params.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get IP range: %w", err)
+	}
+	rangeParams := &entity.IPRangeParams{
+		Type:    current.Type,
+		Subnet:  strconv.Itoa(current.SubnetID),
+		StartIP: current.StartIP,
+		EndIP:   current.EndIP,
+		Comment: current.Comment,
+	}
+	if params.Type != "" {
+		rangeParams.Type = params.Type
+	}
+	if params.StartIP != "" {
+		rangeParams.StartIP = params.StartIP
+	}
+	if params.EndIP != "" {
+		rangeParams.EndIP = params.EndIP
+	}
+	if params.Comment != nil {
+		rangeParams.Comment = *params.Comment
+	}
+	if err := validateIPRange(rangeParams); err != nil {
+		return nil, err
+	}
+
+	ipRange, err := t.client.UpdateIPRange(*params.ID, rangeParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update IP range: %w", err)
+	}
+
+	return marshalOutput(IPRangeOutput{IPRange: ipRange})
+}
+
+// DeleteIPRange deletes an IP range
+func (t *NetworkTools) DeleteIPRange(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	id, err := parseID(input, "IP range")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.client.DeleteIPRange(id); err != nil {
+		return nil, fmt.Errorf("failed to delete IP range: %w", err)
+	}
+
+	return marshalOutput(DeletedOutput{Deleted: id})
+}
+
+// validateIPRange checks the type of an IP range and that its bounds are IP addresses of the
+// same family, in order
+func validateIPRange(params *entity.IPRangeParams) error {
+	if params.Type != types.IPRangeTypeDynamic && params.Type != types.IPRangeTypeReserved {
+		return fmt.Errorf("type must be %q or %q", types.IPRangeTypeDynamic, types.IPRangeTypeReserved)
+	}
+
+	start := net.ParseIP(params.StartIP)
+	if start == nil {
+		return fmt.Errorf("start_ip %q is not a valid IP address", params.StartIP)
+	}
+	end := net.ParseIP(params.EndIP)
+	if end == nil {
+		return fmt.Errorf("end_ip %q is not a valid IP address", params.EndIP)
+	}
+	if (start.To4() == nil) != (end.To4() == nil) {
+		return fmt.Errorf("start_ip and end_ip must be of the same IP version")
+	}
+	if bytes.Compare(start.To16(), end.To16()) > 0 {
+		return fmt.Errorf("start_ip %s is after end_ip %s", params.StartIP, params.EndIP)
+	}
+	return nil
+}
+
+// ListStaticRoutesOutput represents the output for the ListStaticRoutes tool
+type ListStaticRoutesOutput struct {
+	StaticRoutes []types.StaticRoute `json:"static_routes"`
+}
+
+// StaticRouteOutput represents the output for the CreateStaticRoute tool
+type StaticRouteOutput struct {
+	StaticRoute *types.StaticRoute `json:"static_route"`
+}
+
+// ListStaticRoutes lists all static routes
+func (t *NetworkTools) ListStaticRoutes(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	routes, err := t.client.ListStaticRoutes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list static routes: %w", err)
+	}
+
+	// An empty list stays a list
+	if routes == nil {
+		routes = []types.StaticRoute{}
+	}
+
+	return marshalOutput(ListStaticRoutesOutput{StaticRoutes: routes})
+}
+
+// SummarizeStaticRoutes summarizes the output of the ListStaticRoutes tool, such as
+// "1 static route: 10.0.0.0/24 -> 10.1.0.0/24 via 10.0.0.1"
+func SummarizeStaticRoutes(result json.RawMessage) string {
+	var output ListStaticRoutesOutput
+	if err := json.Unmarshal(result, &output); err != nil {
+		return ""
+	}
+	if len(output.StaticRoutes) == 0 {
+		return "No static routes"
+	}
+
+	routes := make([]string, len(output.StaticRoutes))
+	for i, route := range output.StaticRoutes {
+		routes[i] = fmt.Sprintf("%s -> %s via %s", route.SourceCIDR, route.DestinationCIDR, route.GatewayIP)
+	}
+	if len(routes) == 1 {
+		return "1 static route: " + routes[0]
+	}
+	return fmt.Sprintf("%d static routes: %s", len(routes), strings.Join(routes, ", "))
+}
+
+// CreateStaticRouteInput represents the input for the CreateStaticRoute tool
+type CreateStaticRouteInput struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	GatewayIP   string `json:"gateway_ip"`
+	Metric      int    `json:"metric,omitempty"`
+}
+
+// CreateStaticRoute creates a static route from a source subnet to a destination subnet
+func (t *NetworkTools) CreateStaticRoute(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params CreateStaticRouteInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.Source == "" || params.Destination == "" {
+		return nil, fmt.Errorf("source and destination subnets are required")
+	}
+	if net.ParseIP(params.GatewayIP) == nil {
+		return nil, fmt.Errorf("gateway_ip %q is not a valid IP address", params.GatewayIP)
+	}
+	if params.Metric < 0 {
+		return nil, fmt.Errorf("metric must not be negative")
+	}
+
+	route, err := t.client.CreateStaticRoute(&entity.StaticRouteParams{
+		Source:      params.Source,
+		Destination: params.Destination,
+		GatewayIP:   params.GatewayIP,
+		Metric:      params.Metric,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create static route: %w", err)
+	}
+
+	return marshalOutput(StaticRouteOutput{StaticRoute: route})
+}
+
+// DeleteStaticRoute deletes a static route
+func (t *NetworkTools) DeleteStaticRoute(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	id, err := parseID(input, "static route")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.client.DeleteStaticRoute(id); err != nil {
+		return nil, fmt.Errorf("failed to delete static route: %w", err)
+	}
+
+	return marshalOutput(DeletedOutput{Deleted: id})
+}
+
+// parseID reads the ID of the tools that take only the ID of an object
+func parseID(input json.RawMessage, kind string) (int, error) {
+	var params IDInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return 0, fmt.Errorf("failed to parse input: %w", err)
+	}
+	if params.ID == nil {
+		return 0, fmt.Errorf("%s ID is required", kind)
+	}
+	return *params.ID, nil
+}
+
+// marshalOutput marshals the output of a tool
+func marshalOutput(output interface{}) (json.RawMessage, error) {
+	result, err := json.Marshal(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return result, nil
+}
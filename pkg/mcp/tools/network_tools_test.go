@@ -0,0 +1,313 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/canonical/gomaasclient/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/maas-mcp-server/internal/maas/common"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// fakeNetworkClient serves spaces, IP ranges and static routes, and records the parameters of the
+// IP ranges and static routes it creates or updates. Like types.Space.FromEntity, spaces always
+// have non-nil subnet and VLAN lists. The subnet calls are not used by the network tools and panic.
+type fakeNetworkClient struct {
+	common.NetworkClient
+
+	spaces []types.Space
+	ranges []types.IPRange
+	routes []types.StaticRoute
+
+	rangeParams *entity.IPRangeParams
+	routeParams *entity.StaticRouteParams
+	deleted     []int
+}
+
+func newFakeNetworkClient() *fakeNetworkClient {
+	return &fakeNetworkClient{
+		spaces: []types.Space{
+			{ID: 1, Name: "storage", Subnets: []types.Subnet{{ID: 1, CIDR: "10.0.0.0/24"}}, VLANs: []types.VLAN{}},
+			{ID: 2, Name: "public", Subnets: []types.Subnet{}, VLANs: []types.VLAN{}},
+		},
+		ranges: []types.IPRange{
+			{ID: 1, Type: types.IPRangeTypeDynamic, StartIP: "10.0.0.100", EndIP: "10.0.0.200", SubnetID: 1},
+			{ID: 2, Type: types.IPRangeTypeReserved, StartIP: "10.0.0.1", EndIP: "10.0.0.9", SubnetID: 1, Comment: "gateways"},
+			{ID: 3, Type: types.IPRangeTypeReserved, StartIP: "10.5.0.1", EndIP: "10.5.0.9", SubnetID: 5},
+		},
+		routes: []types.StaticRoute{
+			{ID: 1, SourceID: 1, SourceCIDR: "10.0.0.0/24", DestinationID: 5, DestinationCIDR: "10.5.0.0/24", GatewayIP: "10.0.0.1"},
+		},
+	}
+}
+
+func (c *fakeNetworkClient) ListSpaces() ([]types.Space, error) {
+	return c.spaces, nil
+}
+
+func (c *fakeNetworkClient) GetSpace(id int) (*types.Space, error) {
+	for i := range c.spaces {
+		if c.spaces[i].ID == id {
+			return &c.spaces[i], nil
+		}
+	}
+	return nil, fmt.Errorf("space %d not found", id)
+}
+
+func (c *fakeNetworkClient) CreateSpace(name string) (*types.Space, error) {
+	return &types.Space{ID: 3, Name: name, Subnets: []types.Subnet{}, VLANs: []types.VLAN{}}, nil
+}
+
+func (c *fakeNetworkClient) UpdateSpace(id int, name string) (*types.Space, error) {
+	space, err := c.GetSpace(id)
+	if err != nil {
+		return nil, err
+	}
+	return &types.Space{ID: space.ID, Name: name, Subnets: space.Subnets, VLANs: space.VLANs}, nil
+}
+
+func (c *fakeNetworkClient) DeleteSpace(id int) error {
+	c.deleted = append(c.deleted, id)
+	return nil
+}
+
+func (c *fakeNetworkClient) ListIPRanges() ([]types.IPRange, error) {
+	return c.ranges, nil
+}
+
+func (c *fakeNetworkClient) GetIPRange(id int) (*types.IPRange, error) {
+	for i := range c.ranges {
+		if c.ranges[i].ID == id {
+			return &c.ranges[i], nil
+		}
+	}
+	return nil, fmt.Errorf("IP range %d not found", id)
+}
+
+func (c *fakeNetworkClient) CreateIPRange(params *entity.IPRangeParams) (*types.IPRange, error) {
+	c.rangeParams = params
+	return &types.IPRange{ID: 4, Type: params.Type, StartIP: params.StartIP, EndIP: params.EndIP, Comment: params.Comment}, nil
+}
+
+func (c *fakeNetworkClient) UpdateIPRange(id int, params *entity.IPRangeParams) (*types.IPRange, error) {
+	c.rangeParams = params
+	return &types.IPRange{ID: id, Type: params.Type, StartIP: params.StartIP, EndIP: params.EndIP, Comment: params.Comment}, nil
+}
+
+func (c *fakeNetworkClient) DeleteIPRange(id int) error {
+	c.deleted = append(c.deleted, id)
+	return nil
+}
+
+func (c *fakeNetworkClient) ListStaticRoutes() ([]types.StaticRoute, error) {
+	return c.routes, nil
+}
+
+func (c *fakeNetworkClient) CreateStaticRoute(params *entity.StaticRouteParams) (*types.StaticRoute, error) {
+	c.routeParams = params
+	return &types.StaticRoute{ID: 2, GatewayIP: params.GatewayIP, Metric: params.Metric}, nil
+}
+
+func (c *fakeNetworkClient) DeleteStaticRoute(id int) error {
+	c.deleted = append(c.deleted, id)
+	return nil
+}
+
+func TestNetworkTools_Spaces(t *testing.T) {
+	client := newFakeNetworkClient()
+	networkTools := NewNetworkTools(client)
+	ctx := context.Background()
+
+	output, err := networkTools.ListSpaces(ctx, json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assertMatchesSchema(t, ListSpacesOutputSchema, output)
+	assert.Equal(t, "2 spaces: storage, public", SummarizeSpaces(output))
+
+	output, err = networkTools.GetSpace(ctx, json.RawMessage(`{"id":1}`))
+	require.NoError(t, err)
+	assertMatchesSchema(t, SpaceOutputSchema, output)
+	var space SpaceOutput
+	require.NoError(t, json.Unmarshal(output, &space))
+	assert.Equal(t, "storage", space.Space.Name)
+	assert.Len(t, space.Space.Subnets, 1)
+
+	output, err = networkTools.CreateSpace(ctx, json.RawMessage(`{"name":"dmz"}`))
+	require.NoError(t, err)
+	assertMatchesSchema(t, SpaceOutputSchema, output)
+
+	output, err = networkTools.UpdateSpace(ctx, json.RawMessage(`{"id":1,"name":"san"}`))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(output, &space))
+	assert.Equal(t, "san", space.Space.Name)
+
+	// Space 0 is a valid ID
+	output, err = networkTools.DeleteSpace(ctx, json.RawMessage(`{"id":0}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"deleted":0}`, string(output))
+	assertMatchesSchema(t, DeletedOutputSchema, output)
+	assert.Equal(t, []int{0}, client.deleted)
+
+	_, err = networkTools.GetSpace(ctx, json.RawMessage(`{}`))
+	assert.EqualError(t, err, "space ID is required")
+	_, err = networkTools.GetSpace(ctx, json.RawMessage(`{"id":9}`))
+	assert.EqualError(t, err, "failed to get space: space 9 not found")
+	_, err = networkTools.CreateSpace(ctx, json.RawMessage(`{}`))
+	assert.EqualError(t, err, "space name is required")
+	_, err = networkTools.UpdateSpace(ctx, json.RawMessage(`{"name":"san"}`))
+	assert.EqualError(t, err, "space ID is required")
+	_, err = networkTools.UpdateSpace(ctx, json.RawMessage(`{"id":1}`))
+	assert.EqualError(t, err, "space name is required")
+
+	output, err = NewNetworkTools(&fakeNetworkClient{}).ListSpaces(ctx, json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"spaces":[]}`, string(output))
+	assert.Equal(t, "No spaces", SummarizeSpaces(output))
+}
+
+func TestNetworkTools_ListIPRanges(t *testing.T) {
+	networkTools := NewNetworkTools(newFakeNetworkClient())
+
+	tests := []struct {
+		input   string
+		wantIDs []int
+	}{
+		{`{}`, []int{1, 2, 3}},
+		{`{"subnet_id":1}`, []int{1, 2}},
+		{`{"type":"reserved"}`, []int{2, 3}},
+		{`{"subnet_id":1,"type":"dynamic"}`, []int{1}},
+		{`{"subnet_id":5,"type":"dynamic"}`, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			output, err := networkTools.ListIPRanges(context.Background(), json.RawMessage(tt.input))
+			require.NoError(t, err)
+			assertMatchesSchema(t, ListIPRangesOutputSchema, output)
+
+			var ranges ListIPRangesOutput
+			require.NoError(t, json.Unmarshal(output, &ranges))
+			ids := []int{}
+			for _, ipRange := range ranges.IPRanges {
+				ids = append(ids, ipRange.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+
+	output, err := networkTools.ListIPRanges(context.Background(), json.RawMessage(`{"subnet_id":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, "2 IP ranges: 10.0.0.100-10.0.0.200 (dynamic), 10.0.0.1-10.0.0.9 (reserved)", SummarizeIPRanges(output))
+
+	_, err = networkTools.ListIPRanges(context.Background(), json.RawMessage(`{"type":"static"}`))
+	assert.EqualError(t, err, `type must be "dynamic" or "reserved"`)
+}
+
+func TestNetworkTools_CreateIPRange(t *testing.T) {
+	client := newFakeNetworkClient()
+	networkTools := NewNetworkTools(client)
+
+	output, err := networkTools.CreateIPRange(context.Background(),
+		json.RawMessage(`{"type":"dynamic","subnet":"10.0.0.0/24","start_ip":"10.0.0.210","end_ip":"10.0.0.220","comment":"pxe"}`))
+	require.NoError(t, err)
+	assertMatchesSchema(t, IPRangeOutputSchema, output)
+	assert.Equal(t, &entity.IPRangeParams{
+		Type:    "dynamic",
+		Subnet:  "10.0.0.0/24",
+		StartIP: "10.0.0.210",
+		EndIP:   "10.0.0.220",
+		Comment: "pxe",
+	}, client.rangeParams)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"type":"dynamic","start_ip":"10.0.0.1","end_ip":"10.0.0.9"}`, "subnet is required"},
+		{`{"type":"static","subnet":"1","start_ip":"10.0.0.1","end_ip":"10.0.0.9"}`, `type must be "dynamic" or "reserved"`},
+		{`{"type":"dynamic","subnet":"1","start_ip":"10.0.0","end_ip":"10.0.0.9"}`, `start_ip "10.0.0" is not a valid IP address`},
+		{`{"type":"dynamic","subnet":"1","start_ip":"10.0.0.1"}`, `end_ip "" is not a valid IP address`},
+		{`{"type":"dynamic","subnet":"1","start_ip":"10.0.0.1","end_ip":"fd00::9"}`, "start_ip and end_ip must be of the same IP version"},
+		{`{"type":"dynamic","subnet":"1","start_ip":"10.0.0.9","end_ip":"10.0.0.1"}`, "start_ip 10.0.0.9 is after end_ip 10.0.0.1"},
+	}
+	for _, tt := range tests {
+		client.rangeParams = nil
+		_, err := networkTools.CreateIPRange(context.Background(), json.RawMessage(tt.input))
+		assert.EqualError(t, err, tt.want, tt.input)
+		assert.Nil(t, client.rangeParams, "%s reached MAAS", tt.input)
+	}
+}
+
+func TestNetworkTools_UpdateIPRange(t *testing.T) {
+	client := newFakeNetworkClient()
+	networkTools := NewNetworkTools(client)
+
+	// Fields left out keep their current values, as MAAS replaces every field
+	output, err := networkTools.UpdateIPRange(context.Background(), json.RawMessage(`{"id":2,"end_ip":"10.0.0.19"}`))
+	require.NoError(t, err)
+	assertMatchesSchema(t, IPRangeOutputSchema, output)
+	assert.Equal(t, &entity.IPRangeParams{
+		Type:    "reserved",
+		Subnet:  "1",
+		StartIP: "10.0.0.1",
+		EndIP:   "10.0.0.19",
+		Comment: "gateways",
+	}, client.rangeParams)
+
+	// An empty comment clears it
+	_, err = networkTools.UpdateIPRange(context.Background(), json.RawMessage(`{"id":2,"comment":""}`))
+	require.NoError(t, err)
+	assert.Equal(t, "", client.rangeParams.Comment)
+	assert.Equal(t, "10.0.0.9", client.rangeParams.EndIP)
+
+	_, err = networkTools.UpdateIPRange(context.Background(), json.RawMessage(`{"id":2}`))
+	assert.EqualError(t, err, "type, start_ip, end_ip or comment is required")
+	_, err = networkTools.UpdateIPRange(context.Background(), json.RawMessage(`{"end_ip":"10.0.0.19"}`))
+	assert.EqualError(t, err, "IP range ID is required")
+	_, err = networkTools.UpdateIPRange(context.Background(), json.RawMessage(`{"id":9,"end_ip":"10.0.0.19"}`))
+	assert.EqualError(t, err, "failed to get IP range: IP range 9 not found")
+	_, err = networkTools.UpdateIPRange(context.Background(), json.RawMessage(`{"id":2,"start_ip":"10.0.0.20"}`))
+	assert.EqualError(t, err, "start_ip 10.0.0.20 is after end_ip 10.0.0.9")
+
+	output, err = networkTools.DeleteIPRange(context.Background(), json.RawMessage(`{"id":2}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"deleted":2}`, string(output))
+}
+
+func TestNetworkTools_StaticRoutes(t *testing.T) {
+	client := newFakeNetworkClient()
+	networkTools := NewNetworkTools(client)
+	ctx := context.Background()
+
+	output, err := networkTools.ListStaticRoutes(ctx, json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assertMatchesSchema(t, ListStaticRoutesOutputSchema, output)
+	assert.Equal(t, "1 static route: 10.0.0.0/24 -> 10.5.0.0/24 via 10.0.0.1", SummarizeStaticRoutes(output))
+
+	output, err = networkTools.CreateStaticRoute(ctx,
+		json.RawMessage(`{"source":"1","destination":"10.5.0.0/24","gateway_ip":"10.0.0.1","metric":5}`))
+	require.NoError(t, err)
+	assertMatchesSchema(t, StaticRouteOutputSchema, output)
+	assert.Equal(t, &entity.StaticRouteParams{Source: "1", Destination: "10.5.0.0/24", GatewayIP: "10.0.0.1", Metric: 5}, client.routeParams)
+
+	_, err = networkTools.CreateStaticRoute(ctx, json.RawMessage(`{"source":"1","gateway_ip":"10.0.0.1"}`))
+	assert.EqualError(t, err, "source and destination subnets are required")
+	_, err = networkTools.CreateStaticRoute(ctx, json.RawMessage(`{"source":"1","destination":"5","gateway_ip":"gateway"}`))
+	assert.EqualError(t, err, `gateway_ip "gateway" is not a valid IP address`)
+	_, err = networkTools.CreateStaticRoute(ctx, json.RawMessage(`{"source":"1","destination":"5","gateway_ip":"10.0.0.1","metric":-1}`))
+	assert.EqualError(t, err, "metric must not be negative")
+
+	output, err = networkTools.DeleteStaticRoute(ctx, json.RawMessage(`{"id":1}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"deleted":1}`, string(output))
+	_, err = networkTools.DeleteStaticRoute(ctx, json.RawMessage(`{}`))
+	assert.EqualError(t, err, "static route ID is required")
+
+	output, err = NewNetworkTools(&fakeNetworkClient{}).ListStaticRoutes(ctx, json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, "No static routes", SummarizeStaticRoutes(output))
+}
@@ -0,0 +1,249 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/canonical/gomaasclient/entity"
+
+	"github.com/lspecian/maas-mcp-server/internal/maas/common"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// resourcePoolSchema describes a resource pool in the output of the resource pool tools
+const resourcePoolSchema = `{
+	"type": "object",
+	"required": ["name"],
+	"properties": {
+		"id": {"type": "integer"},
+		"name": {"type": "string"},
+		"description": {"type": "string"},
+		"resource_url": {"type": "string"}
+	}
+}`
+
+// ListResourcePoolsOutputSchema is the output schema of the ListResourcePools tool
+var ListResourcePoolsOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["resource_pools"],
+	"properties": {
+		"resource_pools": {"type": "array", "items": ` + resourcePoolSchema + `}
+	}
+}`)
+
+// ResourcePoolOutputSchema is the output schema of the tools that return a single resource pool:
+// GetResourcePool, CreateResourcePool and UpdateResourcePool
+var ResourcePoolOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["resource_pool"],
+	"properties": {
+		"resource_pool": ` + resourcePoolSchema + `
+	}
+}`)
+
+// DeleteResourcePoolOutputSchema is the output schema of the DeleteResourcePool tool
+var DeleteResourcePoolOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["deleted"],
+	"properties": {
+		"deleted": {"type": "string", "description": "Name of the deleted resource pool"}
+	}
+}`)
+
+// ResourcePoolTools provides MCP tools for resource pool management
+type ResourcePoolTools struct {
+	client common.ResourcePoolClient
+}
+
+// NewResourcePoolTools creates a new ResourcePoolTools instance
+func NewResourcePoolTools(client common.ResourcePoolClient) *ResourcePoolTools {
+	return &ResourcePoolTools{
+		client: client,
+	}
+}
+
+// ListResourcePoolsOutput represents the output for the ListResourcePools tool
+type ListResourcePoolsOutput struct {
+	ResourcePools []types.ResourcePool `json:"resource_pools"`
+}
+
+// ListResourcePools lists all resource pools
+func (t *ResourcePoolTools) ListResourcePools(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	pools, err := t.client.ListResourcePools()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource pools: %w", err)
+	}
+
+	// An empty list stays a list
+	if pools == nil {
+		pools = []types.ResourcePool{}
+	}
+
+	result, err := json.Marshal(ListResourcePoolsOutput{ResourcePools: pools})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+
+	return result, nil
+}
+
+// SummarizeResourcePools summarizes the output of the ListResourcePools tool, such as "2 resource pools: default, team-a"
+func SummarizeResourcePools(result json.RawMessage) string {
+	var output ListResourcePoolsOutput
+	if err := json.Unmarshal(result, &output); err != nil {
+		return ""
+	}
+	if len(output.ResourcePools) == 0 {
+		return "No resource pools"
+	}
+
+	names := make([]string, len(output.ResourcePools))
+	for i, pool := range output.ResourcePools {
+		names[i] = pool.Name
+	}
+	if len(names) == 1 {
+		return "1 resource pool: " + names[0]
+	}
+	return fmt.Sprintf("%d resource pools: %s", len(names), strings.Join(names, ", "))
+}
+
+// GetResourcePoolInput represents the input for the GetResourcePool tool
+type GetResourcePoolInput struct {
+	Name string `json:"name"`
+}
+
+// ResourcePoolOutput represents the output for the tools that return a single resource pool
+type ResourcePoolOutput struct {
+	ResourcePool *types.ResourcePool `json:"resource_pool"`
+}
+
+// GetResourcePool gets a resource pool by name
+func (t *ResourcePoolTools) GetResourcePool(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params GetResourcePoolInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.Name == "" {
+		return nil, fmt.Errorf("resource pool name is required")
+	}
+
+	pool, err := t.client.GetResourcePool(params.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource pool: %w", err)
+	}
+
+	return marshalResourcePool(pool)
+}
+
+// CreateResourcePoolInput represents the input for the CreateResourcePool tool
+type CreateResourcePoolInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// CreateResourcePool creates a resource pool
+func (t *ResourcePoolTools) CreateResourcePool(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params CreateResourcePoolInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.Name == "" {
+		return nil, fmt.Errorf("resource pool name is required")
+	}
+
+	pool, err := t.client.CreateResourcePool(&entity.ResourcePoolParams{
+		Name:        params.Name,
+		Description: params.Description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource pool: %w", err)
+	}
+
+	return marshalResourcePool(pool)
+}
+
+// UpdateResourcePoolInput represents the input for the UpdateResourcePool tool. Fields left out are not changed.
+type UpdateResourcePoolInput struct {
+	Name        string `json:"name"`
+	NewName     string `json:"new_name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// UpdateResourcePool renames a resource pool or changes its description
+func (t *ResourcePoolTools) UpdateResourcePool(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params UpdateResourcePoolInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.Name == "" {
+		return nil, fmt.Errorf("resource pool name is required")
+	}
+	if params.NewName == "" && params.Description == "" {
+		return nil, fmt.Errorf("new_name or description is required")
+	}
+
+	pool, err := t.client.UpdateResourcePool(params.Name, &entity.ResourcePoolParams{
+		Name:        params.NewName,
+		Description: params.Description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update resource pool: %w", err)
+	}
+
+	return marshalResourcePool(pool)
+}
+
+// DeleteResourcePoolInput represents the input for the DeleteResourcePool tool
+type DeleteResourcePoolInput struct {
+	Name string `json:"name"`
+}
+
+// DeleteResourcePoolOutput represents the output for the DeleteResourcePool tool
+type DeleteResourcePoolOutput struct {
+	Deleted string `json:"deleted"`
+}
+
+// DeleteResourcePool deletes a resource pool
+func (t *ResourcePoolTools) DeleteResourcePool(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params DeleteResourcePoolInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.Name == "" {
+		return nil, fmt.Errorf("resource pool name is required")
+	}
+
+	if err := t.client.DeleteResourcePool(params.Name); err != nil {
+		return nil, fmt.Errorf("failed to delete resource pool: %w", err)
+	}
+
+	result, err := json.Marshal(DeleteResourcePoolOutput{Deleted: params.Name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+
+	return result, nil
+}
+
+// marshalResourcePool marshals the output of the tools that return a single resource pool
+func marshalResourcePool(pool *types.ResourcePool) (json.RawMessage, error) {
+	result, err := json.Marshal(ResourcePoolOutput{ResourcePool: pool})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return result, nil
+}
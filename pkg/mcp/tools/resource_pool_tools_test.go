@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/canonical/gomaasclient/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// fakeResourcePoolClient keeps resource pools in memory, by name
+type fakeResourcePoolClient struct {
+	pools  map[string]*types.ResourcePool
+	nextID int
+	err    error
+}
+
+func newFakeResourcePoolClient(names ...string) *fakeResourcePoolClient {
+	client := &fakeResourcePoolClient{pools: make(map[string]*types.ResourcePool)}
+	for _, name := range names {
+		client.pools[name] = &types.ResourcePool{ID: client.nextID, Name: name}
+		client.nextID++
+	}
+	return client
+}
+
+func (c *fakeResourcePoolClient) ListResourcePools() ([]types.ResourcePool, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	var pools []types.ResourcePool
+	for id := 0; id < c.nextID; id++ {
+		for _, pool := range c.pools {
+			if pool.ID == id {
+				pools = append(pools, *pool)
+			}
+		}
+	}
+	return pools, nil
+}
+
+func (c *fakeResourcePoolClient) GetResourcePool(name string) (*types.ResourcePool, error) {
+	pool, ok := c.pools[name]
+	if !ok {
+		return nil, fmt.Errorf("resource pool %s not found", name)
+	}
+	return pool, nil
+}
+
+func (c *fakeResourcePoolClient) CreateResourcePool(params *entity.ResourcePoolParams) (*types.ResourcePool, error) {
+	if _, ok := c.pools[params.Name]; ok {
+		return nil, fmt.Errorf("resource pool %s already exists", params.Name)
+	}
+	pool := &types.ResourcePool{ID: c.nextID, Name: params.Name, Description: params.Description}
+	c.nextID++
+	c.pools[pool.Name] = pool
+	return pool, nil
+}
+
+func (c *fakeResourcePoolClient) UpdateResourcePool(name string, params *entity.ResourcePoolParams) (*types.ResourcePool, error) {
+	pool, err := c.GetResourcePool(name)
+	if err != nil {
+		return nil, err
+	}
+	if params.Name != "" {
+		delete(c.pools, name)
+		pool.Name = params.Name
+		c.pools[pool.Name] = pool
+	}
+	if params.Description != "" {
+		pool.Description = params.Description
+	}
+	return pool, nil
+}
+
+func (c *fakeResourcePoolClient) DeleteResourcePool(name string) error {
+	if _, err := c.GetResourcePool(name); err != nil {
+		return err
+	}
+	delete(c.pools, name)
+	return nil
+}
+
+func TestResourcePoolTools(t *testing.T) {
+	client := newFakeResourcePoolClient("default")
+	poolTools := NewResourcePoolTools(client)
+	ctx := context.Background()
+
+	output, err := poolTools.CreateResourcePool(ctx, json.RawMessage(`{"name":"team-a","description":"Team A machines"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"resource_pool":{"id":1,"name":"team-a","description":"Team A machines","resource_url":""}}`, string(output))
+	assertMatchesSchema(t, ResourcePoolOutputSchema, output)
+
+	output, err = poolTools.ListResourcePools(ctx, json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assertMatchesSchema(t, ListResourcePoolsOutputSchema, output)
+	assert.Equal(t, "2 resource pools: default, team-a", SummarizeResourcePools(output))
+
+	output, err = poolTools.UpdateResourcePool(ctx, json.RawMessage(`{"name":"team-a","new_name":"team-b"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"resource_pool":{"id":1,"name":"team-b","description":"Team A machines","resource_url":""}}`, string(output))
+
+	output, err = poolTools.GetResourcePool(ctx, json.RawMessage(`{"name":"team-b"}`))
+	require.NoError(t, err)
+	assertMatchesSchema(t, ResourcePoolOutputSchema, output)
+
+	output, err = poolTools.DeleteResourcePool(ctx, json.RawMessage(`{"name":"team-b"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"deleted":"team-b"}`, string(output))
+	assertMatchesSchema(t, DeleteResourcePoolOutputSchema, output)
+	assert.NotContains(t, client.pools, "team-b")
+
+	output, err = poolTools.ListResourcePools(ctx, json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, "1 resource pool: default", SummarizeResourcePools(output))
+}
+
+func TestResourcePoolTools_EmptyList(t *testing.T) {
+	output, err := NewResourcePoolTools(newFakeResourcePoolClient()).ListResourcePools(context.Background(), json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"resource_pools":[]}`, string(output))
+	assert.Equal(t, "No resource pools", SummarizeResourcePools(output))
+}
+
+func TestResourcePoolTools_Errors(t *testing.T) {
+	client := newFakeResourcePoolClient("default")
+	poolTools := NewResourcePoolTools(client)
+	ctx := context.Background()
+
+	_, err := poolTools.GetResourcePool(ctx, json.RawMessage(`{}`))
+	assert.EqualError(t, err, "resource pool name is required")
+
+	_, err = poolTools.GetResourcePool(ctx, json.RawMessage(`{"name":"missing"}`))
+	assert.EqualError(t, err, "failed to get resource pool: resource pool missing not found")
+
+	_, err = poolTools.CreateResourcePool(ctx, json.RawMessage(`{"description":"no name"}`))
+	assert.EqualError(t, err, "resource pool name is required")
+
+	_, err = poolTools.CreateResourcePool(ctx, json.RawMessage(`{"name":"default"}`))
+	assert.EqualError(t, err, "failed to create resource pool: resource pool default already exists")
+
+	_, err = poolTools.UpdateResourcePool(ctx, json.RawMessage(`{"name":"default"}`))
+	assert.EqualError(t, err, "new_name or description is required")
+
+	_, err = poolTools.DeleteResourcePool(ctx, json.RawMessage(`{"name":"missing"}`))
+	assert.EqualError(t, err, "failed to delete resource pool: resource pool missing not found")
+
+	_, err = poolTools.DeleteResourcePool(ctx, json.RawMessage(`not json`))
+	assert.ErrorContains(t, err, "failed to parse input")
+
+	client.err = fmt.Errorf("MAAS is down")
+	_, err = poolTools.ListResourcePools(ctx, json.RawMessage(`{}`))
+	assert.EqualError(t, err, "failed to list resource pools: MAAS is down")
+}
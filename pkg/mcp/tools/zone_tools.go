@@ -0,0 +1,249 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/canonical/gomaasclient/entity"
+
+	"github.com/lspecian/maas-mcp-server/internal/maas/common"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// zoneSchema describes a zone in the output of the zone tools
+const zoneSchema = `{
+	"type": "object",
+	"required": ["name"],
+	"properties": {
+		"id": {"type": "integer"},
+		"name": {"type": "string"},
+		"description": {"type": "string"},
+		"resource_url": {"type": "string"}
+	}
+}`
+
+// ListZonesOutputSchema is the output schema of the ListZones tool
+var ListZonesOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["zones"],
+	"properties": {
+		"zones": {"type": "array", "items": ` + zoneSchema + `}
+	}
+}`)
+
+// ZoneOutputSchema is the output schema of the tools that return a single zone:
+// GetZone, CreateZone and UpdateZone
+var ZoneOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["zone"],
+	"properties": {
+		"zone": ` + zoneSchema + `
+	}
+}`)
+
+// DeleteZoneOutputSchema is the output schema of the DeleteZone tool
+var DeleteZoneOutputSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["deleted"],
+	"properties": {
+		"deleted": {"type": "string", "description": "Name of the deleted zone"}
+	}
+}`)
+
+// ZoneTools provides MCP tools for zone management
+type ZoneTools struct {
+	client common.ZoneClient
+}
+
+// NewZoneTools creates a new ZoneTools instance
+func NewZoneTools(client common.ZoneClient) *ZoneTools {
+	return &ZoneTools{
+		client: client,
+	}
+}
+
+// ListZonesOutput represents the output for the ListZones tool
+type ListZonesOutput struct {
+	Zones []types.Zone `json:"zones"`
+}
+
+// ListZones lists all zones
+func (t *ZoneTools) ListZones(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	zones, err := t.client.ListZones()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list zones: %w", err)
+	}
+
+	// An empty list stays a list
+	if zones == nil {
+		zones = []types.Zone{}
+	}
+
+	result, err := json.Marshal(ListZonesOutput{Zones: zones})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+
+	return result, nil
+}
+
+// SummarizeZones summarizes the output of the ListZones tool, such as "2 zones: default, rack-2"
+func SummarizeZones(result json.RawMessage) string {
+	var output ListZonesOutput
+	if err := json.Unmarshal(result, &output); err != nil {
+		return ""
+	}
+	if len(output.Zones) == 0 {
+		return "No zones"
+	}
+
+	names := make([]string, len(output.Zones))
+	for i, zone := range output.Zones {
+		names[i] = zone.Name
+	}
+	if len(names) == 1 {
+		return "1 zone: " + names[0]
+	}
+	return fmt.Sprintf("%d zones: %s", len(names), strings.Join(names, ", "))
+}
+
+// GetZoneInput represents the input for the GetZone tool
+type GetZoneInput struct {
+	Name string `json:"name"`
+}
+
+// ZoneOutput represents the output for the tools that return a single zone
+type ZoneOutput struct {
+	Zone *types.Zone `json:"zone"`
+}
+
+// GetZone gets a zone by name
+func (t *ZoneTools) GetZone(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params GetZoneInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.Name == "" {
+		return nil, fmt.Errorf("zone name is required")
+	}
+
+	zone, err := t.client.GetZone(params.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get zone: %w", err)
+	}
+
+	return marshalZone(zone)
+}
+
+// CreateZoneInput represents the input for the CreateZone tool
+type CreateZoneInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// CreateZone creates a zone
+func (t *ZoneTools) CreateZone(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params CreateZoneInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.Name == "" {
+		return nil, fmt.Errorf("zone name is required")
+	}
+
+	zone, err := t.client.CreateZone(&entity.ZoneParams{
+		Name:        params.Name,
+		Description: params.Description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zone: %w", err)
+	}
+
+	return marshalZone(zone)
+}
+
+// UpdateZoneInput represents the input for the UpdateZone tool. Fields left out are not changed.
+type UpdateZoneInput struct {
+	Name        string `json:"name"`
+	NewName     string `json:"new_name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// UpdateZone renames a zone or changes its description
+func (t *ZoneTools) UpdateZone(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params UpdateZoneInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.Name == "" {
+		return nil, fmt.Errorf("zone name is required")
+	}
+	if params.NewName == "" && params.Description == "" {
+		return nil, fmt.Errorf("new_name or description is required")
+	}
+
+	zone, err := t.client.UpdateZone(params.Name, &entity.ZoneParams{
+		Name:        params.NewName,
+		Description: params.Description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update zone: %w", err)
+	}
+
+	return marshalZone(zone)
+}
+
+// DeleteZoneInput represents the input for the DeleteZone tool
+type DeleteZoneInput struct {
+	Name string `json:"name"`
+}
+
+// DeleteZoneOutput represents the output for the DeleteZone tool
+type DeleteZoneOutput struct {
+	Deleted string `json:"deleted"`
+}
+
+// DeleteZone deletes a zone
+func (t *ZoneTools) DeleteZone(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	// Parse input
+	var params DeleteZoneInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	// Validate input
+	if params.Name == "" {
+		return nil, fmt.Errorf("zone name is required")
+	}
+
+	if err := t.client.DeleteZone(params.Name); err != nil {
+		return nil, fmt.Errorf("failed to delete zone: %w", err)
+	}
+
+	result, err := json.Marshal(DeleteZoneOutput{Deleted: params.Name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+
+	return result, nil
+}
+
+// marshalZone marshals the output of the tools that return a single zone
+func marshalZone(zone *types.Zone) (json.RawMessage, error) {
+	result, err := json.Marshal(ZoneOutput{Zone: zone})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return result, nil
+}
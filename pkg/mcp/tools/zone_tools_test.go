@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/canonical/gomaasclient/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/maas-mcp-server/internal/jsonschema"
+	"github.com/lspecian/maas-mcp-server/internal/models/types"
+)
+
+// assertMatchesSchema checks a tool output against the output schema the tool is registered with
+func assertMatchesSchema(t *testing.T, schema json.RawMessage, output json.RawMessage) {
+	t.Helper()
+	compiled, err := jsonschema.Compile(schema)
+	require.NoError(t, err)
+	assert.NoError(t, compiled.Validate(output))
+}
+
+// fakeZoneClient keeps zones in memory, by name
+type fakeZoneClient struct {
+	zones  map[string]*types.Zone
+	nextID int
+	err    error
+}
+
+func newFakeZoneClient(names ...string) *fakeZoneClient {
+	client := &fakeZoneClient{zones: make(map[string]*types.Zone)}
+	for _, name := range names {
+		client.zones[name] = &types.Zone{ID: client.nextID, Name: name}
+		client.nextID++
+	}
+	return client
+}
+
+func (c *fakeZoneClient) ListZones() ([]types.Zone, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	var zones []types.Zone
+	for id := 0; id < c.nextID; id++ {
+		for _, zone := range c.zones {
+			if zone.ID == id {
+				zones = append(zones, *zone)
+			}
+		}
+	}
+	return zones, nil
+}
+
+func (c *fakeZoneClient) GetZone(name string) (*types.Zone, error) {
+	zone, ok := c.zones[name]
+	if !ok {
+		return nil, fmt.Errorf("zone %s not found", name)
+	}
+	return zone, nil
+}
+
+func (c *fakeZoneClient) CreateZone(params *entity.ZoneParams) (*types.Zone, error) {
+	if _, ok := c.zones[params.Name]; ok {
+		return nil, fmt.Errorf("zone %s already exists", params.Name)
+	}
+	zone := &types.Zone{ID: c.nextID, Name: params.Name, Description: params.Description}
+	c.nextID++
+	c.zones[zone.Name] = zone
+	return zone, nil
+}
+
+func (c *fakeZoneClient) UpdateZone(name string, params *entity.ZoneParams) (*types.Zone, error) {
+	zone, err := c.GetZone(name)
+	if err != nil {
+		return nil, err
+	}
+	if params.Name != "" {
+		delete(c.zones, name)
+		zone.Name = params.Name
+		c.zones[zone.Name] = zone
+	}
+	if params.Description != "" {
+		zone.Description = params.Description
+	}
+	return zone, nil
+}
+
+func (c *fakeZoneClient) DeleteZone(name string) error {
+	if _, err := c.GetZone(name); err != nil {
+		return err
+	}
+	delete(c.zones, name)
+	return nil
+}
+
+func TestZoneTools(t *testing.T) {
+	client := newFakeZoneClient("default")
+	zoneTools := NewZoneTools(client)
+	ctx := context.Background()
+
+	output, err := zoneTools.CreateZone(ctx, json.RawMessage(`{"name":"rack-2","description":"Second rack"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"zone":{"id":1,"name":"rack-2","description":"Second rack","resource_url":""}}`, string(output))
+	assertMatchesSchema(t, ZoneOutputSchema, output)
+
+	output, err = zoneTools.ListZones(ctx, json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assertMatchesSchema(t, ListZonesOutputSchema, output)
+	assert.Equal(t, "2 zones: default, rack-2", SummarizeZones(output))
+
+	output, err = zoneTools.UpdateZone(ctx, json.RawMessage(`{"name":"rack-2","new_name":"rack-3"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"zone":{"id":1,"name":"rack-3","description":"Second rack","resource_url":""}}`, string(output))
+
+	output, err = zoneTools.GetZone(ctx, json.RawMessage(`{"name":"rack-3"}`))
+	require.NoError(t, err)
+	assertMatchesSchema(t, ZoneOutputSchema, output)
+
+	output, err = zoneTools.DeleteZone(ctx, json.RawMessage(`{"name":"rack-3"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"deleted":"rack-3"}`, string(output))
+	assertMatchesSchema(t, DeleteZoneOutputSchema, output)
+	assert.NotContains(t, client.zones, "rack-3")
+
+	output, err = zoneTools.ListZones(ctx, json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, "1 zone: default", SummarizeZones(output))
+}
+
+func TestZoneTools_EmptyList(t *testing.T) {
+	output, err := NewZoneTools(newFakeZoneClient()).ListZones(context.Background(), json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"zones":[]}`, string(output))
+	assert.Equal(t, "No zones", SummarizeZones(output))
+}
+
+func TestZoneTools_Errors(t *testing.T) {
+	client := newFakeZoneClient("default")
+	zoneTools := NewZoneTools(client)
+	ctx := context.Background()
+
+	_, err := zoneTools.GetZone(ctx, json.RawMessage(`{}`))
+	assert.EqualError(t, err, "zone name is required")
+
+	_, err = zoneTools.GetZone(ctx, json.RawMessage(`{"name":"missing"}`))
+	assert.EqualError(t, err, "failed to get zone: zone missing not found")
+
+	_, err = zoneTools.CreateZone(ctx, json.RawMessage(`{"description":"no name"}`))
+	assert.EqualError(t, err, "zone name is required")
+
+	_, err = zoneTools.CreateZone(ctx, json.RawMessage(`{"name":"default"}`))
+	assert.EqualError(t, err, "failed to create zone: zone default already exists")
+
+	_, err = zoneTools.UpdateZone(ctx, json.RawMessage(`{"name":"default"}`))
+	assert.EqualError(t, err, "new_name or description is required")
+
+	_, err = zoneTools.DeleteZone(ctx, json.RawMessage(`{"name":"missing"}`))
+	assert.EqualError(t, err, "failed to delete zone: zone missing not found")
+
+	_, err = zoneTools.DeleteZone(ctx, json.RawMessage(`not json`))
+	assert.ErrorContains(t, err, "failed to parse input")
+
+	client.err = fmt.Errorf("MAAS is down")
+	_, err = zoneTools.ListZones(ctx, json.RawMessage(`{}`))
+	assert.EqualError(t, err, "failed to list zones: MAAS is down")
+}
@@ -1,31 +1,103 @@
 package mcp
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+
+	"github.com/lspecian/maas-mcp-server/internal/service/progress"
 )
 
 // ToolsCallParams represents the parameters for a tools/call request
 type ToolsCallParams struct {
 	Name      string          `json:"name"`
 	Arguments json.RawMessage `json:"arguments"`
+	Meta      *ToolsCallMeta  `json:"_meta,omitempty"`
+}
+
+// ToolsCallMeta represents the _meta field of a tools/call request
+type ToolsCallMeta struct {
+	// ProgressToken is a string or number chosen by the client; it is echoed back unchanged
+	ProgressToken json.RawMessage `json:"progressToken,omitempty"`
+}
+
+// ToolContent is a content block of a tools/call result
+type ToolContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// ToolCallResult is the result of a tools/call request. Content holds a text summary and the output
+// as JSON text, for clients that do not read StructuredContent. Failures of the tool itself are
+// results with IsError set, so the model sees them as tool errors rather than protocol errors.
+type ToolCallResult struct {
+	Content           []ToolContent   `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError,omitempty"`
+}
+
+// newToolCallResult builds the tools/call result of a finished call of tool
+func newToolCallResult(tool ToolInfo, output json.RawMessage, err error) ToolCallResult {
+	if err != nil {
+		return toolErrorResult(err.Error())
+	}
+
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		output = json.RawMessage(`{}`)
+	}
+
+	result := ToolCallResult{}
+	// MCP only allows an object as structured content
+	if output[0] == '{' {
+		if tool.outputSchema != nil {
+			if err := tool.outputSchema.Validate(output); err != nil {
+				return toolErrorResult(fmt.Sprintf("tool %s returned output that does not match its output schema: %v", tool.Name, err))
+			}
+		}
+		result.StructuredContent = output
+	}
+
+	if tool.Summarize != nil {
+		if summary := tool.Summarize(output); summary != "" {
+			result.Content = append(result.Content, ToolContent{Type: "text", Text: summary})
+		}
+	}
+	result.Content = append(result.Content, ToolContent{Type: "text", Text: string(output)})
+	return result
+}
+
+// toolErrorResult is the tools/call result of a failed call
+func toolErrorResult(message string) ToolCallResult {
+	return ToolCallResult{
+		Content: []ToolContent{{Type: "text", Text: message}},
+		IsError: true,
+	}
+}
+
+// progressToken returns the request's progress token, or nil when the client did not ask for progress
+func (p *ToolsCallParams) progressToken() json.RawMessage {
+	if p.Meta == nil || len(p.Meta.ProgressToken) == 0 || string(p.Meta.ProgressToken) == "null" {
+		return nil
+	}
+	return p.Meta.ProgressToken
 }
 
 // handleToolsCall handles the tools/call request
-func (s *StdioServer) handleToolsCall(ctx context.Context, id JSONRPCID, params json.RawMessage) error {
+func (s *StdioServer) handleToolsCall(ctx context.Context, out responseWriter, id JSONRPCID, params json.RawMessage) error {
 	// Parse params
 	var toolsCallParams ToolsCallParams
 	if err := json.Unmarshal(params, &toolsCallParams); err != nil {
 		s.logger.WithError(err).Error("Failed to parse tools/call params")
-		s.writeError(id, -32602, "Invalid params", err.Error())
+		s.writeError(out, id, -32602, "Invalid params", err.Error())
 		return err
 	}
 
 	// Validate params
 	if toolsCallParams.Name == "" {
 		s.logger.Error("Missing tool name in tools/call params")
-		s.writeError(id, -32602, "Invalid params", "tool name is required")
+		s.writeError(out, id, -32602, "Invalid params", "tool name is required")
 		return fmt.Errorf("tool name is required")
 	}
 
@@ -33,42 +105,51 @@ func (s *StdioServer) handleToolsCall(ctx context.Context, id JSONRPCID, params
 	s.logger.WithField("tool", toolsCallParams.Name).WithField("arguments", string(toolsCallParams.Arguments)).Info("Calling tool")
 
 	// Get tool
-	tool, ok := s.registry.GetTool(toolsCallParams.Name)
+	tool, ok := s.registry.GetToolFor(stdioUser, toolsCallParams.Name)
 	if !ok {
 		s.logger.WithField("tool", toolsCallParams.Name).Error("Tool not found")
-		s.writeError(id, -32601, "Method not found", fmt.Sprintf("tool %s not found", toolsCallParams.Name))
+		s.writeError(out, id, -32601, "Method not found", fmt.Sprintf("tool %s not found", toolsCallParams.Name))
 		return fmt.Errorf("tool not found: %s", toolsCallParams.Name)
 	}
 
-	// Execute tool
-	result, err := tool.Handler(ctx, toolsCallParams.Arguments)
+	// Tools find the reporter in the context; the response is written once the handler returns,
+	// so long operations send their progress notifications first
+	if token := toolsCallParams.progressToken(); token != nil {
+		ctx = progress.ContextWithReporter(ctx, newProgressNotifier(s.writeNotification, token))
+	}
+
+	// Execute tool; the client may cancel it with notifications/cancelled while it runs
+	ctx, finish := s.requests().start(ctx, id)
+	result, err := tool.Call(ctx, toolsCallParams.Arguments)
+	if finish() {
+		// A cancelled request gets no response
+		s.logger.WithField("tool", toolsCallParams.Name).WithField("id", id.String()).Info("Tool call cancelled by client")
+		return nil
+	}
 	if err != nil {
-		s.logger.WithError(err).WithField("tool", toolsCallParams.Name).Error("Tool execution failed")
-		s.writeError(id, -32000, "Server error", err.Error())
-		return err
+		// Input that does not match the schema is the client's mistake and a protocol error
+		if code, message, data := toolError(err); code == errorCodeInvalidParams {
+			s.logger.WithError(err).WithField("tool", toolsCallParams.Name).Error("Invalid tool arguments")
+			s.writeError(out, id, code, message, data)
+			return err
+		}
 	}
 
 	// Format the result according to MCP protocol requirements
-	// The MCP protocol requires a "content" array in the response
-	formattedResult := map[string]interface{}{
-		"content": []map[string]interface{}{
-			{
-				"type": "text",
-				"text": string(result),
-			},
-		},
+	callResult := newToolCallResult(tool, result, err)
+	formattedResult, marshalErr := json.Marshal(callResult)
+	if marshalErr != nil {
+		s.logger.WithError(marshalErr).WithField("tool", toolsCallParams.Name).Error("Failed to marshal formatted result")
+		s.writeError(out, id, -32000, "Server error", "Failed to format result")
+		return marshalErr
 	}
 
-	// Marshal the formatted result
-	formattedResultJSON, err := json.Marshal(formattedResult)
-	if err != nil {
-		s.logger.WithError(err).WithField("tool", toolsCallParams.Name).Error("Failed to marshal formatted result")
-		s.writeError(id, -32000, "Server error", "Failed to format result")
+	// Write result; a failed tool is reported in the result and its error is still returned
+	s.writeResult(out, id, formattedResult)
+	if callResult.IsError {
+		s.logger.WithField("tool", toolsCallParams.Name).WithField("error", callResult.Content[0].Text).Error("Tool execution failed")
 		return err
 	}
-
-	// Write result
 	s.logger.WithField("tool", toolsCallParams.Name).Info("Tool execution succeeded")
-	s.writeResult(id, formattedResultJSON)
 	return nil
 }
@@ -1,5 +1,7 @@
 package mcp
 
+import "github.com/lspecian/maas-mcp-server/internal/models"
+
 // --- Request Structs ---
 
 // ListMachinesRequest defines parameters for the maas_list_machines tool.
@@ -26,6 +28,14 @@ type AllocateMachineRequest struct {
 	Pool         string   `json:"pool,omitempty"`
 	Architecture string   `json:"architecture,omitempty"`
 	// Map directly to entity.MachineAllocateParams fields where possible [56]
+
+	// Storage is a MAAS storage constraint string, e.g. "root:100(ssd),data:500(hdd,raid)"
+	Storage string `json:"storage,omitempty"`
+	// StorageConstraints is the typed alternative to Storage; one disk per constraint (Count repeats it)
+	StorageConstraints []models.SimpleStorageConstraint `json:"storage_constraints,omitempty"`
+
+	// Async returns an operation ID straight away and streams progress over SSE
+	Async bool `json:"async,omitempty"`
 }
 
 // DeployMachineRequest defines parameters for maas_deploy_machine.
@@ -35,12 +45,25 @@ type DeployMachineRequest struct {
 	UserData     string `json:"user_data,omitempty"` // Base64 encoded cloud-init script?
 	HWEKernel    string `json:"hwe_kernel,omitempty"`
 	// Map directly to entity.MachineDeployParams fields where possible [56]
+	Async bool `json:"async,omitempty"` // Return an operation ID and stream progress over SSE
+}
+
+// CommissionMachineRequest defines parameters for maas_commission_machine.
+type CommissionMachineRequest struct {
+	SystemID             string `json:"system_id" binding:"required"`
+	EnableSSH            bool   `json:"enable_ssh,omitempty"`
+	SkipNetworking       bool   `json:"skip_networking,omitempty"`
+	SkipStorage          bool   `json:"skip_storage,omitempty"`
+	CommissioningScripts string `json:"commissioning_scripts,omitempty"` // Comma-separated script names
+	TestingScripts       string `json:"testing_scripts,omitempty"`       // Comma-separated script names
+	Async                bool   `json:"async,omitempty"`                 // Return an operation ID and stream progress over SSE
 }
 
 // ReleaseMachineRequest defines parameters for maas_release_machine.
 type ReleaseMachineRequest struct {
 	SystemID string `json:"system_id" binding:"required"`
 	Comment  string `json:"comment,omitempty"`
+	Async    bool   `json:"async,omitempty"` // Return an operation ID and stream progress over SSE
 }
 
 // GetMachinePowerStateRequest defines parameters for maas_get_machine_power_state.